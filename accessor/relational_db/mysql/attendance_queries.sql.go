@@ -155,6 +155,7 @@ SELECT attendance.id AS attendance_id, date, used_student_token_quota, duration,
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
     class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    attendance.is_substitute, attendance.original_teacher_id, user_original_teacher.username AS original_teacher_username, user_original_teacher.user_detail AS original_teacher_detail,
     -- we cannot use sqlc.embed(slt), due to ` + "`" + `Attendance` + "`" + ` may have null ` + "`" + `StudentLearningToken` + "`" + `.
     -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
     slt.id, slt.quota, slt.course_fee_quarter_value, slt.transport_fee_quarter_value, slt.created_at, slt.last_updated_at, slt.enrollment_id
@@ -172,6 +173,8 @@ FROM attendance
     LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
     LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
     LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+    LEFT JOIN teacher AS original_teacher ON attendance.original_teacher_id = original_teacher.id
+    LEFT JOIN user AS user_original_teacher ON original_teacher.user_id = user_original_teacher.id
 
     LEFT JOIN student_learning_token as slt ON attendance.token_id = slt.id
 WHERE attendance.id = ? LIMIT 1
@@ -198,6 +201,10 @@ type GetAttendanceByIdRow struct {
 	ClassTeacherID           sql.NullInt64
 	ClassTeacherUsername     sql.NullString
 	ClassTeacherDetail       []byte
+	IsSubstitute             int32
+	OriginalTeacherID        sql.NullInt64
+	OriginalTeacherUsername  sql.NullString
+	OriginalTeacherDetail    []byte
 	ID                       sql.NullInt64
 	Quota                    sql.NullFloat64
 	CourseFeeQuarterValue    sql.NullInt32
@@ -242,6 +249,10 @@ func (q *Queries) GetAttendanceById(ctx context.Context, id int64) (GetAttendanc
 		&i.ClassTeacherID,
 		&i.ClassTeacherUsername,
 		&i.ClassTeacherDetail,
+		&i.IsSubstitute,
+		&i.OriginalTeacherID,
+		&i.OriginalTeacherUsername,
+		&i.OriginalTeacherDetail,
 		&i.ID,
 		&i.Quota,
 		&i.CourseFeeQuarterValue,
@@ -327,6 +338,7 @@ SELECT attendance.id AS attendance_id, date, used_student_token_quota, duration,
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
     class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    attendance.is_substitute, attendance.original_teacher_id, user_original_teacher.username AS original_teacher_username, user_original_teacher.user_detail AS original_teacher_detail,
     -- we cannot use sqlc.embed(slt), due to ` + "`" + `Attendance` + "`" + ` may have null ` + "`" + `StudentLearningToken` + "`" + `.
     -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
     slt.id, slt.quota, slt.course_fee_quarter_value, slt.transport_fee_quarter_value, slt.created_at, slt.last_updated_at, slt.enrollment_id
@@ -344,6 +356,8 @@ FROM attendance
     LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
     LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
     LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+    LEFT JOIN teacher AS original_teacher ON attendance.original_teacher_id = original_teacher.id
+    LEFT JOIN user AS user_original_teacher ON original_teacher.user_id = user_original_teacher.id
 
     LEFT JOIN student_learning_token as slt ON attendance.token_id = slt.id
 WHERE
@@ -388,6 +402,10 @@ type GetAttendancesRow struct {
 	ClassTeacherID           sql.NullInt64
 	ClassTeacherUsername     sql.NullString
 	ClassTeacherDetail       []byte
+	IsSubstitute             int32
+	OriginalTeacherID        sql.NullInt64
+	OriginalTeacherUsername  sql.NullString
+	OriginalTeacherDetail    []byte
 	ID                       sql.NullInt64
 	Quota                    sql.NullFloat64
 	CourseFeeQuarterValue    sql.NullInt32
@@ -448,6 +466,10 @@ func (q *Queries) GetAttendances(ctx context.Context, arg GetAttendancesParams)
 			&i.ClassTeacherID,
 			&i.ClassTeacherUsername,
 			&i.ClassTeacherDetail,
+			&i.IsSubstitute,
+			&i.OriginalTeacherID,
+			&i.OriginalTeacherUsername,
+			&i.OriginalTeacherDetail,
 			&i.ID,
 			&i.Quota,
 			&i.CourseFeeQuarterValue,
@@ -475,6 +497,7 @@ SELECT attendance.id AS attendance_id, date, used_student_token_quota, duration,
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
     class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    attendance.is_substitute, attendance.original_teacher_id, user_original_teacher.username AS original_teacher_username, user_original_teacher.user_detail AS original_teacher_detail,
     -- we cannot use sqlc.embed(slt), due to ` + "`" + `Attendance` + "`" + ` may have null ` + "`" + `StudentLearningToken` + "`" + `.
     -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
     slt.id, slt.quota, slt.course_fee_quarter_value, slt.transport_fee_quarter_value, slt.created_at, slt.last_updated_at, slt.enrollment_id
@@ -492,6 +515,8 @@ FROM attendance
     LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
     LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
     LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+    LEFT JOIN teacher AS original_teacher ON attendance.original_teacher_id = original_teacher.id
+    LEFT JOIN user AS user_original_teacher ON original_teacher.user_id = user_original_teacher.id
 
     LEFT JOIN student_learning_token as slt ON attendance.token_id = slt.id
 WHERE attendance.id IN (/*SLICE:ids*/?)
@@ -518,6 +543,10 @@ type GetAttendancesByIdsRow struct {
 	ClassTeacherID           sql.NullInt64
 	ClassTeacherUsername     sql.NullString
 	ClassTeacherDetail       []byte
+	IsSubstitute             int32
+	OriginalTeacherID        sql.NullInt64
+	OriginalTeacherUsername  sql.NullString
+	OriginalTeacherDetail    []byte
 	ID                       sql.NullInt64
 	Quota                    sql.NullFloat64
 	CourseFeeQuarterValue    sql.NullInt32
@@ -578,6 +607,10 @@ func (q *Queries) GetAttendancesByIds(ctx context.Context, ids []int64) ([]GetAt
 			&i.ClassTeacherID,
 			&i.ClassTeacherUsername,
 			&i.ClassTeacherDetail,
+			&i.IsSubstitute,
+			&i.OriginalTeacherID,
+			&i.OriginalTeacherUsername,
+			&i.OriginalTeacherDetail,
 			&i.ID,
 			&i.Quota,
 			&i.CourseFeeQuarterValue,
@@ -605,6 +638,7 @@ SELECT attendance.id AS attendance_id, date, used_student_token_quota, duration,
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
     class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    attendance.is_substitute, attendance.original_teacher_id, user_original_teacher.username AS original_teacher_username, user_original_teacher.user_detail AS original_teacher_detail,
     -- we cannot use sqlc.embed(slt), due to ` + "`" + `Attendance` + "`" + ` may have null ` + "`" + `StudentLearningToken` + "`" + `.
     -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
     slt.id, slt.quota, slt.course_fee_quarter_value, slt.transport_fee_quarter_value, slt.created_at, slt.last_updated_at, slt.enrollment_id
@@ -622,6 +656,8 @@ FROM attendance
     LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
     LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
     LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+    LEFT JOIN teacher AS original_teacher ON attendance.original_teacher_id = original_teacher.id
+    LEFT JOIN user AS user_original_teacher ON original_teacher.user_id = user_original_teacher.id
 
     LEFT JOIN student_learning_token as slt ON attendance.token_id = slt.id
 WHERE
@@ -666,6 +702,10 @@ type GetAttendancesDescendingDateRow struct {
 	ClassTeacherID           sql.NullInt64
 	ClassTeacherUsername     sql.NullString
 	ClassTeacherDetail       []byte
+	IsSubstitute             int32
+	OriginalTeacherID        sql.NullInt64
+	OriginalTeacherUsername  sql.NullString
+	OriginalTeacherDetail    []byte
 	ID                       sql.NullInt64
 	Quota                    sql.NullFloat64
 	CourseFeeQuarterValue    sql.NullInt32
@@ -727,6 +767,10 @@ func (q *Queries) GetAttendancesDescendingDate(ctx context.Context, arg GetAtten
 			&i.ClassTeacherID,
 			&i.ClassTeacherUsername,
 			&i.ClassTeacherDetail,
+			&i.IsSubstitute,
+			&i.OriginalTeacherID,
+			&i.OriginalTeacherUsername,
+			&i.OriginalTeacherDetail,
 			&i.ID,
 			&i.Quota,
 			&i.CourseFeeQuarterValue,
@@ -748,12 +792,110 @@ func (q *Queries) GetAttendancesDescendingDate(ctx context.Context, arg GetAtten
 	return items, nil
 }
 
+const getPaidAttendanceIdsByTokenIdFromDate = `-- name: GetPaidAttendanceIdsByTokenIdFromDate :many
+SELECT id FROM attendance
+WHERE token_id = ? AND is_paid = 1 AND date >= ?
+ORDER BY date, id
+`
+
+type GetPaidAttendanceIdsByTokenIdFromDateParams struct {
+	TokenID sql.NullInt64
+	Date    time.Time
+}
+
+func (q *Queries) GetPaidAttendanceIdsByTokenIdFromDate(ctx context.Context, arg GetPaidAttendanceIdsByTokenIdFromDateParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getPaidAttendanceIdsByTokenIdFromDate, arg.TokenID, arg.Date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnpaidAttendanceIdsByTokenId = `-- name: GetUnpaidAttendanceIdsByTokenId :many
+SELECT id FROM attendance
+WHERE token_id = ? AND is_paid = 0
+ORDER BY date, id
+`
+
+func (q *Queries) GetUnpaidAttendanceIdsByTokenId(ctx context.Context, tokenID sql.NullInt64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getUnpaidAttendanceIdsByTokenId, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnpaidAttendanceIdsByTokenIdFromDate = `-- name: GetUnpaidAttendanceIdsByTokenIdFromDate :many
+SELECT id FROM attendance
+WHERE token_id = ? AND is_paid = 0 AND date >= ?
+ORDER BY date, id
+`
+
+type GetUnpaidAttendanceIdsByTokenIdFromDateParams struct {
+	TokenID sql.NullInt64
+	Date    time.Time
+}
+
+func (q *Queries) GetUnpaidAttendanceIdsByTokenIdFromDate(ctx context.Context, arg GetUnpaidAttendanceIdsByTokenIdFromDateParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getUnpaidAttendanceIdsByTokenIdFromDate, arg.TokenID, arg.Date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUnpaidAttendancesByTeacherId = `-- name: GetUnpaidAttendancesByTeacherId :many
 SELECT attendance.id AS attendance_id, date, used_student_token_quota, duration, note, is_paid,
     class.id, class.transport_fee, class.teacher_id, class.course_id, class.auto_owe_attendance_token, class.is_deactivated, tsf.fee AS teacher_special_fee, course.id, course.default_fee, course.default_duration_minute, course.instrument_id, course.grade_id, instrument.id, instrument.name, grade.id, grade.name,
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
     class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    attendance.is_substitute, attendance.original_teacher_id, user_original_teacher.username AS original_teacher_username, user_original_teacher.user_detail AS original_teacher_detail,
     -- we cannot use sqlc.embed(slt), due to ` + "`" + `Attendance` + "`" + ` may have null ` + "`" + `StudentLearningToken` + "`" + `.
     -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
     slt.id, slt.quota, slt.course_fee_quarter_value, slt.transport_fee_quarter_value, slt.created_at, slt.last_updated_at, slt.enrollment_id
@@ -771,6 +913,8 @@ FROM attendance
     LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
     LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
     LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+    LEFT JOIN teacher AS original_teacher ON attendance.original_teacher_id = original_teacher.id
+    LEFT JOIN user AS user_original_teacher ON original_teacher.user_id = user_original_teacher.id
 
     LEFT JOIN student_learning_token as slt ON attendance.token_id = slt.id
 WHERE
@@ -807,6 +951,10 @@ type GetUnpaidAttendancesByTeacherIdRow struct {
 	ClassTeacherID           sql.NullInt64
 	ClassTeacherUsername     sql.NullString
 	ClassTeacherDetail       []byte
+	IsSubstitute             int32
+	OriginalTeacherID        sql.NullInt64
+	OriginalTeacherUsername  sql.NullString
+	OriginalTeacherDetail    []byte
 	ID                       sql.NullInt64
 	Quota                    sql.NullFloat64
 	CourseFeeQuarterValue    sql.NullInt32
@@ -857,6 +1005,10 @@ func (q *Queries) GetUnpaidAttendancesByTeacherId(ctx context.Context, arg GetUn
 			&i.ClassTeacherID,
 			&i.ClassTeacherUsername,
 			&i.ClassTeacherDetail,
+			&i.IsSubstitute,
+			&i.OriginalTeacherID,
+			&i.OriginalTeacherUsername,
+			&i.OriginalTeacherDetail,
 			&i.ID,
 			&i.Quota,
 			&i.CourseFeeQuarterValue,
@@ -916,6 +1068,28 @@ func (q *Queries) InsertAttendance(ctx context.Context, arg InsertAttendancePara
 	return result.LastInsertId()
 }
 
+const refreshAttendancesSubstituteStatusByIds = `-- name: RefreshAttendancesSubstituteStatusByIds :exec
+UPDATE attendance
+SET is_substitute = (original_teacher_id IS NOT NULL AND teacher_id <> original_teacher_id)
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+// RefreshAttendancesSubstituteStatusByIds flags the attendances taught by another teacher than their original teacher. Attendances without original teacher are never flagged.
+func (q *Queries) RefreshAttendancesSubstituteStatusByIds(ctx context.Context, ids []int64) error {
+	query := refreshAttendancesSubstituteStatusByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
 const setAttendancesIsPaidStatusByIds = `-- name: SetAttendancesIsPaidStatusByIds :exec
 UPDATE attendance SET is_paid = ?
 WHERE id IN (/*SLICE:ids*/?)
@@ -942,6 +1116,28 @@ func (q *Queries) SetAttendancesIsPaidStatusByIds(ctx context.Context, arg SetAt
 	return err
 }
 
+const setAttendancesOriginalTeacherByIds = `-- name: SetAttendancesOriginalTeacherByIds :exec
+UPDATE attendance
+SET original_teacher_id = (SELECT class.teacher_id FROM class WHERE class.id = attendance.class_id)
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+// SetAttendancesOriginalTeacherByIds records the class' current teacher as the original teacher. It must only be used right after inserting the attendances.
+func (q *Queries) SetAttendancesOriginalTeacherByIds(ctx context.Context, ids []int64) error {
+	query := setAttendancesOriginalTeacherByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
 const updateAttendance = `-- name: UpdateAttendance :exec
 UPDATE attendance
 SET date = ?, used_student_token_quota = ?, duration = ?, note = ?, is_paid = ?, class_id = ?, teacher_id = ?, student_id = ?, token_id = ?
@@ -13,6 +13,102 @@ import (
 	"time"
 )
 
+const getAttendanceQuotaUsagesByTokenIds = `-- name: GetAttendanceQuotaUsagesByTokenIds :many
+SELECT id, date, used_student_token_quota, token_id
+FROM attendance
+WHERE token_id IN (/*SLICE:token_ids*/?)
+ORDER BY token_id, date, id
+`
+
+type GetAttendanceQuotaUsagesByTokenIdsRow struct {
+	ID                    int64
+	Date                  time.Time
+	UsedStudentTokenQuota float64
+	TokenID               sql.NullInt64
+}
+
+// GetAttendanceQuotaUsagesByTokenIds returns the attendances of the tokens in chronological order, for finding the ones which haven't been covered by any payment.
+func (q *Queries) GetAttendanceQuotaUsagesByTokenIds(ctx context.Context, tokenIds []sql.NullInt64) ([]GetAttendanceQuotaUsagesByTokenIdsRow, error) {
+	query := getAttendanceQuotaUsagesByTokenIds
+	var queryParams []interface{}
+	if len(tokenIds) > 0 {
+		for _, v := range tokenIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:token_ids*/?", strings.Repeat(",?", len(tokenIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:token_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAttendanceQuotaUsagesByTokenIdsRow
+	for rows.Next() {
+		var i GetAttendanceQuotaUsagesByTokenIdsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Date,
+			&i.UsedStudentTokenQuota,
+			&i.TokenID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDiscountMonthlySummaryGroupedByDiscountRule = `-- name: GetDiscountMonthlySummaryGroupedByDiscountRule :many
+SELECT epd.discount_rule_name, CAST(sum(epd.value) AS SIGNED) AS total_discount_value
+FROM enrollment_payment_discount AS epd
+    JOIN enrollment_payment AS ep ON epd.enrollment_payment_id = ep.id
+WHERE ep.payment_date >= ? AND ep.payment_date <= ?
+GROUP BY epd.discount_rule_name
+ORDER BY total_discount_value
+`
+
+type GetDiscountMonthlySummaryGroupedByDiscountRuleParams struct {
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+type GetDiscountMonthlySummaryGroupedByDiscountRuleRow struct {
+	DiscountRuleName   string
+	TotalDiscountValue int64
+}
+
+// ============================== DISCOUNT ==============================
+func (q *Queries) GetDiscountMonthlySummaryGroupedByDiscountRule(ctx context.Context, arg GetDiscountMonthlySummaryGroupedByDiscountRuleParams) ([]GetDiscountMonthlySummaryGroupedByDiscountRuleRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDiscountMonthlySummaryGroupedByDiscountRule, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDiscountMonthlySummaryGroupedByDiscountRuleRow
+	for rows.Next() {
+		var i GetDiscountMonthlySummaryGroupedByDiscountRuleRow
+		if err := rows.Scan(&i.DiscountRuleName, &i.TotalDiscountValue); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getExpenseMonthlySummaryGroupedByInstrument = `-- name: GetExpenseMonthlySummaryGroupedByInstrument :many
 SELECT instrument.id, instrument.name, CAST(sum(tp.paid_course_fee_value) AS SIGNED) AS total_paid_course_fee, CAST(sum(tp.paid_transport_fee_value) AS SIGNED) AS total_paid_transport_fee
 FROM teacher_payment AS tp
@@ -22,7 +118,7 @@ FROM teacher_payment AS tp
     JOIN instrument ON instrument_id = instrument.id
 WHERE
     (tp.added_at >= ? AND tp.added_at <= ?)
-    AND (class.teacher_id IN (/*SLICE:teacher_ids*/?) OR ? = false)
+    AND (attendance.teacher_id IN (/*SLICE:teacher_ids*/?) OR ? = false)
     AND (course.instrument_id IN (/*SLICE:instrument_ids*/?) OR ? = false)
 GROUP BY instrument.id
 ORDER BY total_paid_course_fee
@@ -105,7 +201,7 @@ FROM teacher_payment AS tp
     JOIN course ON class.course_id = course.id
 WHERE
     (tp.added_at >= ? AND tp.added_at <= ?)
-    AND (class.teacher_id IN (/*SLICE:teacher_ids*/?) OR ? = false)
+    AND (attendance.teacher_id IN (/*SLICE:teacher_ids*/?) OR ? = false)
     AND (course.instrument_id IN (/*SLICE:instrument_ids*/?) OR ? = false)
 GROUP BY teacher.id
 ORDER BY total_paid_course_fee
@@ -189,7 +285,7 @@ FROM teacher_payment AS tp
     JOIN course ON class.course_id = course.id
 WHERE
     (tp.added_at >= ? AND tp.added_at <= ?)
-    AND (class.teacher_id IN (/*SLICE:teacher_ids*/?) OR ? = false)
+    AND (attendance.teacher_id IN (/*SLICE:teacher_ids*/?) OR ? = false)
     AND (course.instrument_id IN (/*SLICE:instrument_ids*/?) OR ? = false)
 GROUP BY year_with_month
 ORDER BY year_with_month ASC
@@ -515,3 +611,336 @@ func (q *Queries) GetIncomeOverview(ctx context.Context, arg GetIncomeOverviewPa
 	}
 	return items, nil
 }
+
+const getNegativeQuotaSLTs = `-- name: GetNegativeQuotaSLTs :many
+SELECT slt.id, slt.quota, slt.course_fee_quarter_value, slt.transport_fee_quarter_value, slt.enrollment_id
+FROM student_learning_token AS slt
+    JOIN student_enrollment AS se ON slt.enrollment_id = se.id
+    -- we need this joins just for the filtering (teacher_id & instrument_id)
+    JOIN class ON se.class_id = class.id
+    JOIN course ON class.course_id = course.id
+WHERE slt.quota < 0
+    AND (class.teacher_id IN (/*SLICE:teacher_ids*/?) OR ? = false)
+    AND (course.instrument_id IN (/*SLICE:instrument_ids*/?) OR ? = false)
+ORDER BY slt.enrollment_id, slt.id
+`
+
+type GetNegativeQuotaSLTsParams struct {
+	TeacherIds          []sql.NullInt64
+	UseTeacherFilter    interface{}
+	InstrumentIds       []int64
+	UseInstrumentFilter interface{}
+}
+
+type GetNegativeQuotaSLTsRow struct {
+	ID                       int64
+	Quota                    float64
+	CourseFeeQuarterValue    int32
+	TransportFeeQuarterValue int32
+	EnrollmentID             int64
+}
+
+// ============================== RECEIVABLE ==============================
+func (q *Queries) GetNegativeQuotaSLTs(ctx context.Context, arg GetNegativeQuotaSLTsParams) ([]GetNegativeQuotaSLTsRow, error) {
+	query := getNegativeQuotaSLTs
+	var queryParams []interface{}
+	if len(arg.TeacherIds) > 0 {
+		for _, v := range arg.TeacherIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:teacher_ids*/?", strings.Repeat(",?", len(arg.TeacherIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:teacher_ids*/?", "NULL", 1)
+	}
+	queryParams = append(queryParams, arg.UseTeacherFilter)
+	if len(arg.InstrumentIds) > 0 {
+		for _, v := range arg.InstrumentIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:instrument_ids*/?", strings.Repeat(",?", len(arg.InstrumentIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:instrument_ids*/?", "NULL", 1)
+	}
+	queryParams = append(queryParams, arg.UseInstrumentFilter)
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetNegativeQuotaSLTsRow
+	for rows.Next() {
+		var i GetNegativeQuotaSLTsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Quota,
+			&i.CourseFeeQuarterValue,
+			&i.TransportFeeQuarterValue,
+			&i.EnrollmentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRefundMonthlySummaryGroupedByInstrument = `-- name: GetRefundMonthlySummaryGroupedByInstrument :many
+SELECT instrument.id, instrument.name, CAST(sum(epr.refunded_value) AS SIGNED) AS total_refunded_value
+FROM enrollment_payment_refund AS epr
+    JOIN enrollment_payment AS ep ON epr.enrollment_payment_id = ep.id
+    JOIN student_enrollment AS se ON ep.enrollment_id = se.id
+    JOIN class ON se.class_id = class.id
+    JOIN course ON class.course_id = course.id
+    JOIN instrument ON instrument_id = instrument.id
+WHERE
+    (epr.refund_date >= ? AND epr.refund_date <= ?)
+    AND (se.student_id IN (/*SLICE:student_ids*/?) OR ? = false)
+    AND (course.instrument_id IN (/*SLICE:instrument_ids*/?) OR ? = false)
+GROUP BY instrument.id
+ORDER BY total_refunded_value
+`
+
+type GetRefundMonthlySummaryGroupedByInstrumentParams struct {
+	StartDate           time.Time
+	EndDate             time.Time
+	StudentIds          []int64
+	UseStudentFilter    interface{}
+	InstrumentIds       []int64
+	UseInstrumentFilter interface{}
+}
+
+type GetRefundMonthlySummaryGroupedByInstrumentRow struct {
+	Instrument         Instrument
+	TotalRefundedValue int64
+}
+
+func (q *Queries) GetRefundMonthlySummaryGroupedByInstrument(ctx context.Context, arg GetRefundMonthlySummaryGroupedByInstrumentParams) ([]GetRefundMonthlySummaryGroupedByInstrumentRow, error) {
+	query := getRefundMonthlySummaryGroupedByInstrument
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.StartDate)
+	queryParams = append(queryParams, arg.EndDate)
+	if len(arg.StudentIds) > 0 {
+		for _, v := range arg.StudentIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:student_ids*/?", strings.Repeat(",?", len(arg.StudentIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:student_ids*/?", "NULL", 1)
+	}
+	queryParams = append(queryParams, arg.UseStudentFilter)
+	if len(arg.InstrumentIds) > 0 {
+		for _, v := range arg.InstrumentIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:instrument_ids*/?", strings.Repeat(",?", len(arg.InstrumentIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:instrument_ids*/?", "NULL", 1)
+	}
+	queryParams = append(queryParams, arg.UseInstrumentFilter)
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRefundMonthlySummaryGroupedByInstrumentRow
+	for rows.Next() {
+		var i GetRefundMonthlySummaryGroupedByInstrumentRow
+		if err := rows.Scan(&i.Instrument.ID, &i.Instrument.Name, &i.TotalRefundedValue); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRefundMonthlySummaryGroupedByStudent = `-- name: GetRefundMonthlySummaryGroupedByStudent :many
+SELECT student.id AS student_id, user.id AS user_id, user_detail, CAST(sum(epr.refunded_value) AS SIGNED) AS total_refunded_value
+FROM enrollment_payment_refund AS epr
+    JOIN enrollment_payment AS ep ON epr.enrollment_payment_id = ep.id
+    JOIN student_enrollment AS se ON ep.enrollment_id = se.id
+    JOIN student ON se.student_id = student.id
+    JOIN user ON student.user_id = user.id
+
+    -- we need this joins just for the filtering (student_id & instrument_id)
+    JOIN class ON se.class_id = class.id
+    JOIN course ON class.course_id = course.id
+WHERE
+    (epr.refund_date >= ? AND epr.refund_date <= ?)
+    AND (se.student_id IN (/*SLICE:student_ids*/?) OR ? = false)
+    AND (course.instrument_id IN (/*SLICE:instrument_ids*/?) OR ? = false)
+GROUP BY student.id
+ORDER BY total_refunded_value
+`
+
+type GetRefundMonthlySummaryGroupedByStudentParams struct {
+	StartDate           time.Time
+	EndDate             time.Time
+	StudentIds          []int64
+	UseStudentFilter    interface{}
+	InstrumentIds       []int64
+	UseInstrumentFilter interface{}
+}
+
+type GetRefundMonthlySummaryGroupedByStudentRow struct {
+	StudentID          int64
+	UserID             int64
+	UserDetail         json.RawMessage
+	TotalRefundedValue int64
+}
+
+func (q *Queries) GetRefundMonthlySummaryGroupedByStudent(ctx context.Context, arg GetRefundMonthlySummaryGroupedByStudentParams) ([]GetRefundMonthlySummaryGroupedByStudentRow, error) {
+	query := getRefundMonthlySummaryGroupedByStudent
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.StartDate)
+	queryParams = append(queryParams, arg.EndDate)
+	if len(arg.StudentIds) > 0 {
+		for _, v := range arg.StudentIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:student_ids*/?", strings.Repeat(",?", len(arg.StudentIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:student_ids*/?", "NULL", 1)
+	}
+	queryParams = append(queryParams, arg.UseStudentFilter)
+	if len(arg.InstrumentIds) > 0 {
+		for _, v := range arg.InstrumentIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:instrument_ids*/?", strings.Repeat(",?", len(arg.InstrumentIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:instrument_ids*/?", "NULL", 1)
+	}
+	queryParams = append(queryParams, arg.UseInstrumentFilter)
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRefundMonthlySummaryGroupedByStudentRow
+	for rows.Next() {
+		var i GetRefundMonthlySummaryGroupedByStudentRow
+		if err := rows.Scan(
+			&i.StudentID,
+			&i.UserID,
+			&i.UserDetail,
+			&i.TotalRefundedValue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRefundOverview = `-- name: GetRefundOverview :many
+SELECT DATE_FORMAT(epr.refund_date, '%Y-%m') AS year_with_month, CAST(sum(epr.refunded_value) AS SIGNED) AS total_refunded_value
+FROM enrollment_payment_refund AS epr
+    JOIN enrollment_payment AS ep ON epr.enrollment_payment_id = ep.id
+    -- we need this joins just for the filtering (student_id & instrument_id)
+    JOIN student_enrollment AS se ON ep.enrollment_id = se.id
+    JOIN class ON se.class_id = class.id
+    JOIN course ON class.course_id = course.id
+WHERE
+    (epr.refund_date >= ? AND epr.refund_date <= ?)
+    AND (se.student_id IN (/*SLICE:student_ids*/?) OR ? = false)
+    AND (course.instrument_id IN (/*SLICE:instrument_ids*/?) OR ? = false)
+GROUP BY year_with_month
+ORDER BY year_with_month ASC
+`
+
+type GetRefundOverviewParams struct {
+	StartDate           time.Time
+	EndDate             time.Time
+	StudentIds          []int64
+	UseStudentFilter    interface{}
+	InstrumentIds       []int64
+	UseInstrumentFilter interface{}
+}
+
+type GetRefundOverviewRow struct {
+	YearWithMonth      string
+	TotalRefundedValue int64
+}
+
+// Refunds are subtracted from the income on the month of the refund, check GetIncomeOverview.
+// ============================== REFUND ==============================
+func (q *Queries) GetRefundOverview(ctx context.Context, arg GetRefundOverviewParams) ([]GetRefundOverviewRow, error) {
+	query := getRefundOverview
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.StartDate)
+	queryParams = append(queryParams, arg.EndDate)
+	if len(arg.StudentIds) > 0 {
+		for _, v := range arg.StudentIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:student_ids*/?", strings.Repeat(",?", len(arg.StudentIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:student_ids*/?", "NULL", 1)
+	}
+	queryParams = append(queryParams, arg.UseStudentFilter)
+	if len(arg.InstrumentIds) > 0 {
+		for _, v := range arg.InstrumentIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:instrument_ids*/?", strings.Repeat(",?", len(arg.InstrumentIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:instrument_ids*/?", "NULL", 1)
+	}
+	queryParams = append(queryParams, arg.UseInstrumentFilter)
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRefundOverviewRow
+	for rows.Next() {
+		var i GetRefundOverviewRow
+		if err := rows.Scan(&i.YearWithMonth, &i.TotalRefundedValue); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTotalDiscountFeeValue = `-- name: GetTotalDiscountFeeValue :one
+SELECT CAST(COALESCE(sum(ep.discount_fee_value), 0) AS SIGNED) AS total_discount_fee_value
+FROM enrollment_payment AS ep
+WHERE ep.payment_date >= ? AND ep.payment_date <= ?
+`
+
+type GetTotalDiscountFeeValueParams struct {
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+func (q *Queries) GetTotalDiscountFeeValue(ctx context.Context, arg GetTotalDiscountFeeValueParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getTotalDiscountFeeValue, arg.StartDate, arg.EndDate)
+	var total_discount_fee_value int64
+	err := row.Scan(&total_discount_fee_value)
+	return total_discount_fee_value, err
+}
@@ -45,6 +45,24 @@ func (r GetStudentEnrollmentByIdRow) ToGetStudentEnrollmentsRow() GetStudentEnro
 func (r GetStudentEnrollmentsByClassIdRow) ToGetStudentEnrollmentsRow() GetStudentEnrollmentsRow {
 	return GetStudentEnrollmentsRow(r)
 }
+func (r GetStudentEnrollmentsActiveAtDateByClassIdRow) ToGetStudentEnrollmentsRow() GetStudentEnrollmentsRow {
+	return GetStudentEnrollmentsRow(r)
+}
+func (r GetStudentEnrollmentsIncludingWithdrawnByStudentIdRow) ToGetStudentEnrollmentsRow() GetStudentEnrollmentsRow {
+	return GetStudentEnrollmentsRow(r)
+}
+
+// ============================== STUDENT_ENROLLMENT_HOLD ==============================
+
+func (r GetStudentEnrollmentHoldByIdRow) ToGetStudentEnrollmentHoldsByStudentEnrollmentIdsRow() GetStudentEnrollmentHoldsByStudentEnrollmentIdsRow {
+	return GetStudentEnrollmentHoldsByStudentEnrollmentIdsRow(r)
+}
+func (r GetStudentEnrollmentHoldsByIdsRow) ToGetStudentEnrollmentHoldsByStudentEnrollmentIdsRow() GetStudentEnrollmentHoldsByStudentEnrollmentIdsRow {
+	return GetStudentEnrollmentHoldsByStudentEnrollmentIdsRow(r)
+}
+func (r GetStudentEnrollmentHoldsByClassIdRow) ToGetStudentEnrollmentHoldsByStudentEnrollmentIdsRow() GetStudentEnrollmentHoldsByStudentEnrollmentIdsRow {
+	return GetStudentEnrollmentHoldsByStudentEnrollmentIdsRow(r)
+}
 
 // ============================== TEACHER_SPECIAL_FEE ==============================
 
@@ -21,6 +21,40 @@ type Attendance struct {
 	TeacherID             int64
 	StudentID             int64
 	TokenID               sql.NullInt64
+	IsSubstitute          int32
+	OriginalTeacherID     sql.NullInt64
+}
+
+type BankStatementImport struct {
+	ID               int64
+	BankFormat       string
+	FileName         string
+	ImportedAt       time.Time
+	ImportedByUserID sql.NullInt64
+}
+
+type BankStatementLine struct {
+	ID                    int64
+	BankStatementImportID int64
+	TransactionDate       time.Time
+	Description           string
+	Reference             string
+	Amount                int64
+	Status                string
+	EnrollmentPaymentID   sql.NullInt64
+	ConfirmedAt           sql.NullTime
+	ConfirmedByUserID     sql.NullInt64
+}
+
+type CashUpDay struct {
+	ID                int64
+	Date              time.Time
+	CountedCashValue  int64
+	RecordedCashValue int64
+	DifferenceValue   int64
+	Note              string
+	ClosedAt          time.Time
+	ClosedByUserID    sql.NullInt64
 }
 
 type Class struct {
@@ -32,6 +66,24 @@ type Class struct {
 	IsDeactivated          int32
 }
 
+type ClosedPeriod struct {
+	ID              int64
+	ClosedUntil     time.Time
+	Note            string
+	CreatedAt       time.Time
+	CreatedByUserID sql.NullInt64
+}
+
+type ClosedPeriodOverride struct {
+	ID                 int64
+	Operation          string
+	Reason             string
+	ClosedUntil        time.Time
+	EarliestRecordDate time.Time
+	CreatedAt          time.Time
+	UserID             sql.NullInt64
+}
+
 type Course struct {
 	ID                    int64
 	DefaultFee            int32
@@ -40,26 +92,204 @@ type Course struct {
 	GradeID               int64
 }
 
+type CourseFeeHistory struct {
+	ID            int64
+	Fee           int32
+	EffectiveFrom time.Time
+	CourseID      int64
+}
+
+type DiscountRule struct {
+	ID            int64
+	Name          string
+	Type          string
+	IsPercentage  int32
+	Value         int32
+	MinCount      int32
+	MaxDayOfMonth int32
+	VoucherCode   sql.NullString
+	UsageLimit    sql.NullInt32
+	ValidFrom     sql.NullTime
+	ValidUntil    sql.NullTime
+	IsDeactivated int32
+}
+
 type EnrollmentPayment struct {
+	ID                       int64
+	PaymentDate              time.Time
+	BalanceTopUp             int32
+	BalanceBonus             int32
+	CourseFeeValue           int32
+	TransportFeeValue        int32
+	PenaltyFeeValue          int32
+	DiscountFeeValue         int32
+	EnrollmentID             sql.NullInt64
+	PaymentMethod            string
+	ReceivingAccount         string
+	ReferenceNumber          string
+	SnapshotStudentName      string
+	SnapshotClassDescription string
+	SnapshotCourseName       string
+	SnapshotInstrumentName   string
+	SnapshotGradeName        string
+	SnapshotTeacherName      string
+}
+
+type EnrollmentPaymentDiscount struct {
+	ID                  int64
+	EnrollmentPaymentID int64
+	DiscountRuleID      sql.NullInt64
+	DiscountRuleName    string
+	Value               int32
+}
+
+type EnrollmentPaymentReceipt struct {
+	ID                  int64
+	ReceiptNumber       int64
+	EnrollmentPaymentID sql.NullInt64
+	IssuedAt            time.Time
+	IssuedByUserID      sql.NullInt64
+}
+
+type EnrollmentPaymentRefund struct {
+	ID                  int64
+	RefundDate          time.Time
+	RefundedValue       int32
+	WithdrawnQuota      float64
+	Reason              string
+	CreatedAt           time.Time
+	CreatedByUserID     sql.NullInt64
+	EnrollmentPaymentID int64
+}
+
+type Family struct {
+	ID   int64
+	Name string
+}
+
+type FamilyGuardian struct {
+	ID       int64
+	FamilyID int64
+	UserID   int64
+}
+
+type FamilyStudent struct {
+	ID        int64
+	FamilyID  int64
+	StudentID int64
+}
+
+type Grade struct {
+	ID   int64
+	Name string
+}
+
+type Installment struct {
+	ID                  int64
+	InstallmentNumber   int32
+	DueDate             time.Time
+	BalanceTopUp        int32
+	BalanceBonus        int32
+	CourseFeeValue      int32
+	TransportFeeValue   int32
+	PenaltyFeeValue     int32
+	DiscountFeeValue    int32
+	InstallmentPlanID   int64
+	EnrollmentPaymentID sql.NullInt64
+}
+
+type InstallmentPlan struct {
 	ID                int64
-	PaymentDate       time.Time
 	BalanceTopUp      int32
 	BalanceBonus      int32
 	CourseFeeValue    int32
 	TransportFeeValue int32
 	PenaltyFeeValue   int32
 	DiscountFeeValue  int32
-	EnrollmentID      sql.NullInt64
+	InstallmentCount  int32
+	CreatedAt         time.Time
+	CreatedByUserID   sql.NullInt64
+	EnrollmentID      int64
 }
 
-type Grade struct {
+type Instrument struct {
 	ID   int64
 	Name string
 }
 
-type Instrument struct {
-	ID   int64
-	Name string
+type PaymentReminder struct {
+	ID                int64
+	ReminderType      string
+	RecipientEmail    string
+	OwedQuota         float64
+	CourseFeeValue    int32
+	TransportFeeValue int32
+	PenaltyFeeValue   int32
+	DiscountFeeValue  int32
+	SentAt            time.Time
+	EnrollmentID      int64
+}
+
+type PaymentReminderOptOut struct {
+	StudentID        int64
+	OptedOutAt       time.Time
+	OptedOutByUserID sql.NullInt64
+}
+
+type PayrollRun struct {
+	ID               int64
+	StartDate        time.Time
+	EndDate          time.Time
+	Status           string
+	Note             string
+	CreatedAt        time.Time
+	ApprovedAt       sql.NullTime
+	PaidAt           sql.NullTime
+	CreatedByUserID  sql.NullInt64
+	ApprovedByUserID sql.NullInt64
+}
+
+type PayrollRunTeacher struct {
+	PayrollRunID int64
+	TeacherID    int64
+}
+
+type PenaltyPolicy struct {
+	ID                int64
+	Name              string
+	TriggerDayOfMonth int32
+	GraceDays         int32
+	IsFlatFee         int32
+	FeeValue          int32
+	MaxFeeValue       int32
+	CourseID          sql.NullInt64
+	ClassID           sql.NullInt64
+}
+
+type ReceiptNumberSequence struct {
+	ID                int32
+	LastReceiptNumber int64
+}
+
+type SltTransaction struct {
+	ID          int64
+	QuotaChange float64
+	Reason      string
+	SourceID    sql.NullInt64
+	UserID      sql.NullInt64
+	CreatedAt   time.Time
+	TokenID     int64
+}
+
+type SltTransfer struct {
+	ID                 int64
+	TransferredQuota   float64
+	ConvertedQuota     float64
+	Note               string
+	CreatedAt          time.Time
+	UserID             sql.NullInt64
+	SourceTokenID      sql.NullInt64
+	DestinationTokenID sql.NullInt64
 }
 
 type Student struct {
@@ -74,6 +304,22 @@ type StudentEnrollment struct {
 	IsDeleted int32
 }
 
+type StudentEnrollmentHold struct {
+	ID           int64
+	StartDate    time.Time
+	EndDate      time.Time
+	Reason       string
+	EnrollmentID int64
+}
+
+type StudentEnrollmentPeriod struct {
+	ID               int64
+	StartDate        time.Time
+	EndDate          sql.NullTime
+	WithdrawalReason string
+	EnrollmentID     int64
+}
+
 type StudentLearningToken struct {
 	ID                       int64
 	Quota                    float64
@@ -89,12 +335,28 @@ type Teacher struct {
 	UserID int64
 }
 
+type TeacherFeeSharing struct {
+	ID                            int64
+	CourseFeeSharingPercentage    float64
+	TransportFeeSharingPercentage float64
+	EffectiveFrom                 time.Time
+	TeacherID                     sql.NullInt64
+	CourseID                      sql.NullInt64
+}
+
 type TeacherPayment struct {
-	ID                    int64
-	AttendanceID          int64
-	PaidCourseFeeValue    int32
-	PaidTransportFeeValue int32
-	AddedAt               time.Time
+	ID                       int64
+	AttendanceID             int64
+	PaidCourseFeeValue       int32
+	PaidTransportFeeValue    int32
+	AddedAt                  time.Time
+	PayrollRunID             sql.NullInt64
+	SnapshotStudentName      string
+	SnapshotClassDescription string
+	SnapshotCourseName       string
+	SnapshotInstrumentName   string
+	SnapshotGradeName        string
+	SnapshotTeacherName      string
 }
 
 type TeacherSpecialFee struct {
@@ -104,6 +366,14 @@ type TeacherSpecialFee struct {
 	CourseID  int64
 }
 
+type TeacherSpecialFeeHistory struct {
+	ID            int64
+	Fee           int32
+	EffectiveFrom time.Time
+	TeacherID     int64
+	CourseID      int64
+}
+
 type User struct {
 	ID            int64
 	Username      string
@@ -13,6 +13,157 @@ import (
 	"time"
 )
 
+const approvePayrollRun = `-- name: ApprovePayrollRun :exec
+UPDATE payroll_run SET status = 'APPROVED', approved_at = CURRENT_TIMESTAMP, approved_by_user_id = ?
+WHERE id = ?
+`
+
+type ApprovePayrollRunParams struct {
+	ApprovedByUserID sql.NullInt64
+	ID               int64
+}
+
+func (q *Queries) ApprovePayrollRun(ctx context.Context, arg ApprovePayrollRunParams) error {
+	_, err := q.db.ExecContext(ctx, approvePayrollRun, arg.ApprovedByUserID, arg.ID)
+	return err
+}
+
+const countActiveStudentEnrollmentsByStudentId = `-- name: CountActiveStudentEnrollmentsByStudentId :one
+SELECT Count(se.id) AS total
+FROM student_enrollment AS se
+    JOIN class ON se.class_id = class.id
+WHERE se.student_id = ? AND se.is_deleted = 0 AND class.is_deactivated = 0
+`
+
+func (q *Queries) CountActiveStudentEnrollmentsByStudentId(ctx context.Context, studentID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countActiveStudentEnrollmentsByStudentId, studentID)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countBankStatementImports = `-- name: CountBankStatementImports :one
+SELECT Count(id) AS total FROM bank_statement_import
+`
+
+func (q *Queries) CountBankStatementImports(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countBankStatementImports)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countClosedPeriodOverrides = `-- name: CountClosedPeriodOverrides :one
+SELECT Count(id) AS total FROM closed_period_override
+`
+
+func (q *Queries) CountClosedPeriodOverrides(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countClosedPeriodOverrides)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countClosedPeriods = `-- name: CountClosedPeriods :one
+SELECT Count(id) AS total FROM closed_period
+`
+
+func (q *Queries) CountClosedPeriods(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countClosedPeriods)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countDiscountRuleUsages = `-- name: CountDiscountRuleUsages :one
+SELECT Count(id) AS total FROM enrollment_payment_discount
+WHERE discount_rule_id = ?
+`
+
+func (q *Queries) CountDiscountRuleUsages(ctx context.Context, discountRuleID sql.NullInt64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDiscountRuleUsages, discountRuleID)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countDiscountRules = `-- name: CountDiscountRules :one
+SELECT Count(id) AS total FROM discount_rule
+`
+
+func (q *Queries) CountDiscountRules(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDiscountRules)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countDiscountRulesByIds = `-- name: CountDiscountRulesByIds :one
+SELECT Count(id) AS total FROM discount_rule
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) CountDiscountRulesByIds(ctx context.Context, ids []int64) (int64, error) {
+	query := countDiscountRulesByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	row := q.db.QueryRowContext(ctx, query, queryParams...)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countEnrolledSiblingsByStudentId = `-- name: CountEnrolledSiblingsByStudentId :one
+SELECT Count(DISTINCT sibling.id) AS total
+FROM family_student AS fs
+    JOIN family_student AS sibling_fs ON sibling_fs.family_id = fs.family_id
+    JOIN student AS sibling ON sibling_fs.student_id = sibling.id
+    JOIN user AS sibling_user ON sibling.user_id = sibling_user.id
+    JOIN student_enrollment AS se ON se.student_id = sibling.id
+    JOIN class ON se.class_id = class.id
+WHERE fs.student_id = ?
+    AND sibling_user.is_deactivated = 0 AND se.is_deleted = 0 AND class.is_deactivated = 0
+`
+
+// CountEnrolledSiblingsByStudentId counts the students (including the given student) which belong to the same family, and have any active student_enrollment.
+// Returns 0 when the student doesn't belong to any family.
+func (q *Queries) CountEnrolledSiblingsByStudentId(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countEnrolledSiblingsByStudentId, id)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countEnrollmentPaymentDiscountsByEnrollmentPaymentId = `-- name: CountEnrollmentPaymentDiscountsByEnrollmentPaymentId :one
+SELECT Count(id) AS total FROM enrollment_payment_discount
+WHERE enrollment_payment_id = ?
+`
+
+func (q *Queries) CountEnrollmentPaymentDiscountsByEnrollmentPaymentId(ctx context.Context, enrollmentPaymentID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countEnrollmentPaymentDiscountsByEnrollmentPaymentId, enrollmentPaymentID)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countEnrollmentPaymentRefunds = `-- name: CountEnrollmentPaymentRefunds :one
+SELECT Count(id) AS total FROM enrollment_payment_refund
+`
+
+func (q *Queries) CountEnrollmentPaymentRefunds(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countEnrollmentPaymentRefunds)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
 const countEnrollmentPayments = `-- name: CountEnrollmentPayments :one
 SELECT Count(id) AS total FROM enrollment_payment
 `
@@ -46,6 +197,98 @@ func (q *Queries) CountEnrollmentPaymentsByIds(ctx context.Context, ids []int64)
 	return total, err
 }
 
+const countLockedTeacherPaymentsByIds = `-- name: CountLockedTeacherPaymentsByIds :one
+SELECT Count(tp.id) AS total
+FROM teacher_payment AS tp
+    JOIN payroll_run AS pr ON tp.payroll_run_id = pr.id
+WHERE tp.id IN (/*SLICE:ids*/?) AND pr.status <> 'DRAFT'
+`
+
+// CountLockedTeacherPaymentsByIds counts the teacher_payments which belong to a non-draft payroll_run, thus cannot be edited nor removed.
+func (q *Queries) CountLockedTeacherPaymentsByIds(ctx context.Context, ids []int64) (int64, error) {
+	query := countLockedTeacherPaymentsByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	row := q.db.QueryRowContext(ctx, query, queryParams...)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countOverdueInstallments = `-- name: CountOverdueInstallments :one
+SELECT Count(id) AS total FROM installment
+WHERE enrollment_payment_id IS NULL AND due_date < ?
+`
+
+func (q *Queries) CountOverdueInstallments(ctx context.Context, date time.Time) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOverdueInstallments, date)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countPaymentReminders = `-- name: CountPaymentReminders :one
+SELECT Count(id) AS total FROM payment_reminder
+`
+
+func (q *Queries) CountPaymentReminders(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPaymentReminders)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countPayrollRuns = `-- name: CountPayrollRuns :one
+SELECT Count(id) AS total FROM payroll_run
+`
+
+func (q *Queries) CountPayrollRuns(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPayrollRuns)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countPenaltyPolicies = `-- name: CountPenaltyPolicies :one
+SELECT Count(id) AS total FROM penalty_policy
+`
+
+func (q *Queries) CountPenaltyPolicies(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPenaltyPolicies)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countPenaltyPoliciesByIds = `-- name: CountPenaltyPoliciesByIds :one
+SELECT Count(id) AS total FROM penalty_policy
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) CountPenaltyPoliciesByIds(ctx context.Context, ids []int64) (int64, error) {
+	query := countPenaltyPoliciesByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	row := q.db.QueryRowContext(ctx, query, queryParams...)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
 const countStudentLearningTokens = `-- name: CountStudentLearningTokens :one
 SELECT Count(id) AS total FROM student_learning_token
 `
@@ -79,6 +322,39 @@ func (q *Queries) CountStudentLearningTokensByIds(ctx context.Context, ids []int
 	return total, err
 }
 
+const countTeacherFeeSharings = `-- name: CountTeacherFeeSharings :one
+SELECT Count(id) AS total FROM teacher_fee_sharing
+`
+
+func (q *Queries) CountTeacherFeeSharings(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTeacherFeeSharings)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countTeacherFeeSharingsByIds = `-- name: CountTeacherFeeSharingsByIds :one
+SELECT Count(id) AS total FROM teacher_fee_sharing
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) CountTeacherFeeSharingsByIds(ctx context.Context, ids []int64) (int64, error) {
+	query := countTeacherFeeSharingsByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	row := q.db.QueryRowContext(ctx, query, queryParams...)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
 const countTeacherPayments = `-- name: CountTeacherPayments :one
 SELECT Count(teacher_payment.id) AS total
 FROM teacher_payment
@@ -121,6 +397,59 @@ func (q *Queries) CountTeacherPaymentsByIds(ctx context.Context, ids []int64) (i
 	return total, err
 }
 
+const deleteCashUpDayByDate = `-- name: DeleteCashUpDayByDate :execrows
+DELETE FROM cash_up_day
+WHERE date = ?
+`
+
+func (q *Queries) DeleteCashUpDayByDate(ctx context.Context, date time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteCashUpDayByDate, date)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteClosedPeriodsByIds = `-- name: DeleteClosedPeriodsByIds :exec
+DELETE FROM closed_period
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) DeleteClosedPeriodsByIds(ctx context.Context, ids []int64) error {
+	query := deleteClosedPeriodsByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
+const deleteDiscountRulesByIds = `-- name: DeleteDiscountRulesByIds :exec
+DELETE FROM discount_rule
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) DeleteDiscountRulesByIds(ctx context.Context, ids []int64) error {
+	query := deleteDiscountRulesByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
 const deleteEnrollmentPaymentById = `-- name: DeleteEnrollmentPaymentById :exec
 DELETE FROM enrollment_payment
 WHERE id = ?
@@ -151,23 +480,23 @@ func (q *Queries) DeleteEnrollmentPaymentsByIds(ctx context.Context, ids []int64
 	return err
 }
 
-const deleteStudentLearningTokenById = `-- name: DeleteStudentLearningTokenById :exec
-DELETE FROM student_learning_token
+const deletePaymentReminderById = `-- name: DeletePaymentReminderById :exec
+DELETE FROM payment_reminder
 WHERE id = ?
 `
 
-func (q *Queries) DeleteStudentLearningTokenById(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteStudentLearningTokenById, id)
+func (q *Queries) DeletePaymentReminderById(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deletePaymentReminderById, id)
 	return err
 }
 
-const deleteStudentLearningTokensByIds = `-- name: DeleteStudentLearningTokensByIds :exec
-DELETE FROM student_learning_token
-WHERE id IN (/*SLICE:ids*/?)
+const deletePaymentReminderOptOutsByStudentIds = `-- name: DeletePaymentReminderOptOutsByStudentIds :exec
+DELETE FROM payment_reminder_opt_out
+WHERE student_id IN (/*SLICE:ids*/?)
 `
 
-func (q *Queries) DeleteStudentLearningTokensByIds(ctx context.Context, ids []int64) error {
-	query := deleteStudentLearningTokensByIds
+func (q *Queries) DeletePaymentReminderOptOutsByStudentIds(ctx context.Context, ids []int64) error {
+	query := deletePaymentReminderOptOutsByStudentIds
 	var queryParams []interface{}
 	if len(ids) > 0 {
 		for _, v := range ids {
@@ -181,23 +510,13 @@ func (q *Queries) DeleteStudentLearningTokensByIds(ctx context.Context, ids []in
 	return err
 }
 
-const deleteTeacherPaymentById = `-- name: DeleteTeacherPaymentById :exec
-DELETE FROM teacher_payment
-WHERE id = ?
-`
-
-func (q *Queries) DeleteTeacherPaymentById(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteTeacherPaymentById, id)
-	return err
-}
-
-const deleteTeacherPaymentsByIds = `-- name: DeleteTeacherPaymentsByIds :exec
-DELETE FROM teacher_payment
+const deletePenaltyPoliciesByIds = `-- name: DeletePenaltyPoliciesByIds :exec
+DELETE FROM penalty_policy
 WHERE id IN (/*SLICE:ids*/?)
 `
 
-func (q *Queries) DeleteTeacherPaymentsByIds(ctx context.Context, ids []int64) error {
-	query := deleteTeacherPaymentsByIds
+func (q *Queries) DeletePenaltyPoliciesByIds(ctx context.Context, ids []int64) error {
+	query := deletePenaltyPoliciesByIds
 	var queryParams []interface{}
 	if len(ids) > 0 {
 		for _, v := range ids {
@@ -211,23 +530,873 @@ func (q *Queries) DeleteTeacherPaymentsByIds(ctx context.Context, ids []int64) e
 	return err
 }
 
-const editTeacherPayment = `-- name: EditTeacherPayment :exec
-UPDATE teacher_payment SET paid_course_fee_value = ?, paid_transport_fee_value = ?
+const deleteStudentLearningTokenById = `-- name: DeleteStudentLearningTokenById :exec
+DELETE FROM student_learning_token
 WHERE id = ?
 `
 
-type EditTeacherPaymentParams struct {
-	PaidCourseFeeValue    int32
-	PaidTransportFeeValue int32
-	ID                    int64
-}
-
-func (q *Queries) EditTeacherPayment(ctx context.Context, arg EditTeacherPaymentParams) error {
-	_, err := q.db.ExecContext(ctx, editTeacherPayment, arg.PaidCourseFeeValue, arg.PaidTransportFeeValue, arg.ID)
+func (q *Queries) DeleteStudentLearningTokenById(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteStudentLearningTokenById, id)
 	return err
 }
 
-const getEarliestAvailableSLTsByStudentEnrollmentIds = `-- name: GetEarliestAvailableSLTsByStudentEnrollmentIds :many
+const deleteStudentLearningTokensByIds = `-- name: DeleteStudentLearningTokensByIds :exec
+DELETE FROM student_learning_token
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) DeleteStudentLearningTokensByIds(ctx context.Context, ids []int64) error {
+	query := deleteStudentLearningTokensByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
+const deleteTeacherFeeSharingsByIds = `-- name: DeleteTeacherFeeSharingsByIds :exec
+DELETE FROM teacher_fee_sharing
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) DeleteTeacherFeeSharingsByIds(ctx context.Context, ids []int64) error {
+	query := deleteTeacherFeeSharingsByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
+const deleteTeacherPaymentById = `-- name: DeleteTeacherPaymentById :exec
+DELETE FROM teacher_payment
+WHERE id = ?
+`
+
+func (q *Queries) DeleteTeacherPaymentById(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteTeacherPaymentById, id)
+	return err
+}
+
+const deleteTeacherPaymentsByIds = `-- name: DeleteTeacherPaymentsByIds :exec
+DELETE FROM teacher_payment
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) DeleteTeacherPaymentsByIds(ctx context.Context, ids []int64) error {
+	query := deleteTeacherPaymentsByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
+const editTeacherPayment = `-- name: EditTeacherPayment :exec
+UPDATE teacher_payment SET paid_course_fee_value = ?, paid_transport_fee_value = ?
+WHERE id = ?
+`
+
+type EditTeacherPaymentParams struct {
+	PaidCourseFeeValue    int32
+	PaidTransportFeeValue int32
+	ID                    int64
+}
+
+func (q *Queries) EditTeacherPayment(ctx context.Context, arg EditTeacherPaymentParams) error {
+	_, err := q.db.ExecContext(ctx, editTeacherPayment, arg.PaidCourseFeeValue, arg.PaidTransportFeeValue, arg.ID)
+	return err
+}
+
+const getActiveAutomaticDiscountRules = `-- name: GetActiveAutomaticDiscountRules :many
+SELECT id, name, type, is_percentage, value, min_count, max_day_of_month, voucher_code, usage_limit, valid_from, valid_until, is_deactivated FROM discount_rule
+WHERE type <> 'VOUCHER' AND is_deactivated = 0
+ORDER BY id
+`
+
+// GetActiveAutomaticDiscountRules returns the non-voucher discount_rules, which are evaluated on every invoice.
+func (q *Queries) GetActiveAutomaticDiscountRules(ctx context.Context) ([]DiscountRule, error) {
+	rows, err := q.db.QueryContext(ctx, getActiveAutomaticDiscountRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DiscountRule
+	for rows.Next() {
+		var i DiscountRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Type,
+			&i.IsPercentage,
+			&i.Value,
+			&i.MinCount,
+			&i.MaxDayOfMonth,
+			&i.VoucherCode,
+			&i.UsageLimit,
+			&i.ValidFrom,
+			&i.ValidUntil,
+			&i.IsDeactivated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getApplicablePenaltyPolicy = `-- name: GetApplicablePenaltyPolicy :one
+SELECT id, name, trigger_day_of_month, grace_days, is_flat_fee, fee_value, max_fee_value, course_id, class_id FROM penalty_policy
+WHERE class_id = ? OR course_id = ? OR (class_id IS NULL AND course_id IS NULL)
+ORDER BY (class_id IS NOT NULL) DESC, (course_id IS NOT NULL) DESC, id
+LIMIT 1
+`
+
+type GetApplicablePenaltyPolicyParams struct {
+	ClassID  sql.NullInt64
+	CourseID sql.NullInt64
+}
+
+// GetApplicablePenaltyPolicy returns the most specific penalty_policy for a class: class policy > course policy > school-wide default.
+func (q *Queries) GetApplicablePenaltyPolicy(ctx context.Context, arg GetApplicablePenaltyPolicyParams) (PenaltyPolicy, error) {
+	row := q.db.QueryRowContext(ctx, getApplicablePenaltyPolicy, arg.ClassID, arg.CourseID)
+	var i PenaltyPolicy
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.TriggerDayOfMonth,
+		&i.GraceDays,
+		&i.IsFlatFee,
+		&i.FeeValue,
+		&i.MaxFeeValue,
+		&i.CourseID,
+		&i.ClassID,
+	)
+	return i, err
+}
+
+const getAttendanceDatesByIds = `-- name: GetAttendanceDatesByIds :many
+SELECT date FROM attendance
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) GetAttendanceDatesByIds(ctx context.Context, ids []int64) ([]time.Time, error) {
+	query := getAttendanceDatesByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []time.Time
+	for rows.Next() {
+		var date time.Time
+		if err := rows.Scan(&date); err != nil {
+			return nil, err
+		}
+		items = append(items, date)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAttendanceIdsOutsideDateRangeByIds = `-- name: GetAttendanceIdsOutsideDateRangeByIds :many
+SELECT id FROM attendance
+WHERE id IN (/*SLICE:ids*/?) AND (date < ? OR date > ?)
+`
+
+type GetAttendanceIdsOutsideDateRangeByIdsParams struct {
+	Ids       []int64
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+// GetAttendanceIdsOutsideDateRangeByIds returns the attendances which are dated outside of the given period, e.g. of a payroll_run.
+func (q *Queries) GetAttendanceIdsOutsideDateRangeByIds(ctx context.Context, arg GetAttendanceIdsOutsideDateRangeByIdsParams) ([]int64, error) {
+	query := getAttendanceIdsOutsideDateRangeByIds
+	var queryParams []interface{}
+	if len(arg.Ids) > 0 {
+		for _, v := range arg.Ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(arg.Ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	queryParams = append(queryParams, arg.StartDate)
+	queryParams = append(queryParams, arg.EndDate)
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAttendancesUsedQuotaGroupedByTokenId = `-- name: GetAttendancesUsedQuotaGroupedByTokenId :many
+SELECT token_id, CAST(SUM(used_student_token_quota) AS DOUBLE) AS total_used_quota
+FROM attendance
+WHERE token_id IS NOT NULL
+GROUP BY token_id
+`
+
+type GetAttendancesUsedQuotaGroupedByTokenIdRow struct {
+	TokenID        sql.NullInt64
+	TotalUsedQuota float64
+}
+
+// CAST(.. AS DOUBLE) is to force SQLC to generate the field type as float64. Else, it will be interface{}.
+func (q *Queries) GetAttendancesUsedQuotaGroupedByTokenId(ctx context.Context) ([]GetAttendancesUsedQuotaGroupedByTokenIdRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAttendancesUsedQuotaGroupedByTokenId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAttendancesUsedQuotaGroupedByTokenIdRow
+	for rows.Next() {
+		var i GetAttendancesUsedQuotaGroupedByTokenIdRow
+		if err := rows.Scan(&i.TokenID, &i.TotalUsedQuota); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBankStatementImportById = `-- name: GetBankStatementImportById :one
+SELECT bsi.id, bsi.bank_format, bsi.file_name, bsi.imported_at, bsi.imported_by_user_id, user.username AS imported_by_username,
+    Count(bsl.id) AS total_lines,
+    CAST(COALESCE(SUM(bsl.enrollment_payment_id IS NULL), 0) AS SIGNED) AS total_unmatched_lines
+FROM bank_statement_import AS bsi
+    LEFT JOIN user ON bsi.imported_by_user_id = user.id
+    LEFT JOIN bank_statement_line AS bsl ON bsl.bank_statement_import_id = bsi.id
+WHERE bsi.id = ?
+GROUP BY bsi.id, user.username
+`
+
+type GetBankStatementImportByIdRow struct {
+	ID                  int64
+	BankFormat          string
+	FileName            string
+	ImportedAt          time.Time
+	ImportedByUserID    sql.NullInt64
+	ImportedByUsername  sql.NullString
+	TotalLines          int64
+	TotalUnmatchedLines int64
+}
+
+func (q *Queries) GetBankStatementImportById(ctx context.Context, id int64) (GetBankStatementImportByIdRow, error) {
+	row := q.db.QueryRowContext(ctx, getBankStatementImportById, id)
+	var i GetBankStatementImportByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.BankFormat,
+		&i.FileName,
+		&i.ImportedAt,
+		&i.ImportedByUserID,
+		&i.ImportedByUsername,
+		&i.TotalLines,
+		&i.TotalUnmatchedLines,
+	)
+	return i, err
+}
+
+const getBankStatementImports = `-- name: GetBankStatementImports :many
+SELECT bsi.id, bsi.bank_format, bsi.file_name, bsi.imported_at, bsi.imported_by_user_id, user.username AS imported_by_username,
+    Count(bsl.id) AS total_lines,
+    CAST(COALESCE(SUM(bsl.enrollment_payment_id IS NULL), 0) AS SIGNED) AS total_unmatched_lines
+FROM bank_statement_import AS bsi
+    LEFT JOIN user ON bsi.imported_by_user_id = user.id
+    LEFT JOIN bank_statement_line AS bsl ON bsl.bank_statement_import_id = bsi.id
+GROUP BY bsi.id, user.username
+ORDER BY bsi.id DESC
+LIMIT ? OFFSET ?
+`
+
+type GetBankStatementImportsParams struct {
+	Limit  int32
+	Offset int32
+}
+
+type GetBankStatementImportsRow struct {
+	ID                  int64
+	BankFormat          string
+	FileName            string
+	ImportedAt          time.Time
+	ImportedByUserID    sql.NullInt64
+	ImportedByUsername  sql.NullString
+	TotalLines          int64
+	TotalUnmatchedLines int64
+}
+
+// ============================== BANK_STATEMENT ==============================
+func (q *Queries) GetBankStatementImports(ctx context.Context, arg GetBankStatementImportsParams) ([]GetBankStatementImportsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getBankStatementImports, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetBankStatementImportsRow
+	for rows.Next() {
+		var i GetBankStatementImportsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BankFormat,
+			&i.FileName,
+			&i.ImportedAt,
+			&i.ImportedByUserID,
+			&i.ImportedByUsername,
+			&i.TotalLines,
+			&i.TotalUnmatchedLines,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBankStatementLineById = `-- name: GetBankStatementLineById :one
+SELECT id, bank_statement_import_id, transaction_date, description, reference, amount, status, enrollment_payment_id, confirmed_at, confirmed_by_user_id FROM bank_statement_line
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetBankStatementLineById(ctx context.Context, id int64) (BankStatementLine, error) {
+	row := q.db.QueryRowContext(ctx, getBankStatementLineById, id)
+	var i BankStatementLine
+	err := row.Scan(
+		&i.ID,
+		&i.BankStatementImportID,
+		&i.TransactionDate,
+		&i.Description,
+		&i.Reference,
+		&i.Amount,
+		&i.Status,
+		&i.EnrollmentPaymentID,
+		&i.ConfirmedAt,
+		&i.ConfirmedByUserID,
+	)
+	return i, err
+}
+
+const getBankStatementLineByIdForUpdate = `-- name: GetBankStatementLineByIdForUpdate :one
+SELECT id, bank_statement_import_id, transaction_date, description, reference, amount, status, enrollment_payment_id, confirmed_at, confirmed_by_user_id FROM bank_statement_line
+WHERE id = ? LIMIT 1
+FOR UPDATE
+`
+
+// GetBankStatementLineByIdForUpdate locks the bank_statement_line until the transaction ends, to prevent matching it twice concurrently.
+func (q *Queries) GetBankStatementLineByIdForUpdate(ctx context.Context, id int64) (BankStatementLine, error) {
+	row := q.db.QueryRowContext(ctx, getBankStatementLineByIdForUpdate, id)
+	var i BankStatementLine
+	err := row.Scan(
+		&i.ID,
+		&i.BankStatementImportID,
+		&i.TransactionDate,
+		&i.Description,
+		&i.Reference,
+		&i.Amount,
+		&i.Status,
+		&i.EnrollmentPaymentID,
+		&i.ConfirmedAt,
+		&i.ConfirmedByUserID,
+	)
+	return i, err
+}
+
+const getBankStatementLinesByImportId = `-- name: GetBankStatementLinesByImportId :many
+SELECT id, bank_statement_import_id, transaction_date, description, reference, amount, status, enrollment_payment_id, confirmed_at, confirmed_by_user_id FROM bank_statement_line
+WHERE bank_statement_import_id = ?
+ORDER BY transaction_date, id
+`
+
+func (q *Queries) GetBankStatementLinesByImportId(ctx context.Context, bankStatementImportID int64) ([]BankStatementLine, error) {
+	rows, err := q.db.QueryContext(ctx, getBankStatementLinesByImportId, bankStatementImportID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BankStatementLine
+	for rows.Next() {
+		var i BankStatementLine
+		if err := rows.Scan(
+			&i.ID,
+			&i.BankStatementImportID,
+			&i.TransactionDate,
+			&i.Description,
+			&i.Reference,
+			&i.Amount,
+			&i.Status,
+			&i.EnrollmentPaymentID,
+			&i.ConfirmedAt,
+			&i.ConfirmedByUserID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCashUpDayByDate = `-- name: GetCashUpDayByDate :one
+SELECT cud.id, cud.date, cud.counted_cash_value, cud.recorded_cash_value, cud.difference_value, cud.note, cud.closed_at, cud.closed_by_user_id, user.username AS closed_by_username
+FROM cash_up_day AS cud
+    LEFT JOIN user ON cud.closed_by_user_id = user.id
+WHERE cud.date = ? LIMIT 1
+`
+
+type GetCashUpDayByDateRow struct {
+	ID                int64
+	Date              time.Time
+	CountedCashValue  int64
+	RecordedCashValue int64
+	DifferenceValue   int64
+	Note              string
+	ClosedAt          time.Time
+	ClosedByUserID    sql.NullInt64
+	ClosedByUsername  sql.NullString
+}
+
+func (q *Queries) GetCashUpDayByDate(ctx context.Context, date time.Time) (GetCashUpDayByDateRow, error) {
+	row := q.db.QueryRowContext(ctx, getCashUpDayByDate, date)
+	var i GetCashUpDayByDateRow
+	err := row.Scan(
+		&i.ID,
+		&i.Date,
+		&i.CountedCashValue,
+		&i.RecordedCashValue,
+		&i.DifferenceValue,
+		&i.Note,
+		&i.ClosedAt,
+		&i.ClosedByUserID,
+		&i.ClosedByUsername,
+	)
+	return i, err
+}
+
+const getClosedCashUpDatesByDates = `-- name: GetClosedCashUpDatesByDates :many
+SELECT date FROM cash_up_day
+WHERE date IN (/*SLICE:dates*/?)
+`
+
+func (q *Queries) GetClosedCashUpDatesByDates(ctx context.Context, dates []time.Time) ([]time.Time, error) {
+	query := getClosedCashUpDatesByDates
+	var queryParams []interface{}
+	if len(dates) > 0 {
+		for _, v := range dates {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:dates*/?", strings.Repeat(",?", len(dates))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:dates*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []time.Time
+	for rows.Next() {
+		var date time.Time
+		if err := rows.Scan(&date); err != nil {
+			return nil, err
+		}
+		items = append(items, date)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getClosedPeriodById = `-- name: GetClosedPeriodById :one
+SELECT cp.id, cp.closed_until, cp.note, cp.created_at, cp.created_by_user_id, user.username AS created_by_username
+FROM closed_period AS cp
+    LEFT JOIN user ON cp.created_by_user_id = user.id
+WHERE cp.id = ? LIMIT 1
+`
+
+type GetClosedPeriodByIdRow struct {
+	ID                int64
+	ClosedUntil       time.Time
+	Note              string
+	CreatedAt         time.Time
+	CreatedByUserID   sql.NullInt64
+	CreatedByUsername sql.NullString
+}
+
+func (q *Queries) GetClosedPeriodById(ctx context.Context, id int64) (GetClosedPeriodByIdRow, error) {
+	row := q.db.QueryRowContext(ctx, getClosedPeriodById, id)
+	var i GetClosedPeriodByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.ClosedUntil,
+		&i.Note,
+		&i.CreatedAt,
+		&i.CreatedByUserID,
+		&i.CreatedByUsername,
+	)
+	return i, err
+}
+
+const getClosedPeriodOverrides = `-- name: GetClosedPeriodOverrides :many
+SELECT cpo.id, cpo.operation, cpo.reason, cpo.closed_until, cpo.earliest_record_date, cpo.created_at, cpo.user_id, user.username
+FROM closed_period_override AS cpo
+    LEFT JOIN user ON cpo.user_id = user.id
+ORDER BY cpo.id DESC
+LIMIT ? OFFSET ?
+`
+
+type GetClosedPeriodOverridesParams struct {
+	Limit  int32
+	Offset int32
+}
+
+type GetClosedPeriodOverridesRow struct {
+	ID                 int64
+	Operation          string
+	Reason             string
+	ClosedUntil        time.Time
+	EarliestRecordDate time.Time
+	CreatedAt          time.Time
+	UserID             sql.NullInt64
+	Username           sql.NullString
+}
+
+func (q *Queries) GetClosedPeriodOverrides(ctx context.Context, arg GetClosedPeriodOverridesParams) ([]GetClosedPeriodOverridesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getClosedPeriodOverrides, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetClosedPeriodOverridesRow
+	for rows.Next() {
+		var i GetClosedPeriodOverridesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Operation,
+			&i.Reason,
+			&i.ClosedUntil,
+			&i.EarliestRecordDate,
+			&i.CreatedAt,
+			&i.UserID,
+			&i.Username,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getClosedPeriods = `-- name: GetClosedPeriods :many
+SELECT cp.id, cp.closed_until, cp.note, cp.created_at, cp.created_by_user_id, user.username AS created_by_username
+FROM closed_period AS cp
+    LEFT JOIN user ON cp.created_by_user_id = user.id
+ORDER BY cp.closed_until DESC, cp.id DESC
+LIMIT ? OFFSET ?
+`
+
+type GetClosedPeriodsParams struct {
+	Limit  int32
+	Offset int32
+}
+
+type GetClosedPeriodsRow struct {
+	ID                int64
+	ClosedUntil       time.Time
+	Note              string
+	CreatedAt         time.Time
+	CreatedByUserID   sql.NullInt64
+	CreatedByUsername sql.NullString
+}
+
+// ============================== CLOSED_PERIOD ==============================
+func (q *Queries) GetClosedPeriods(ctx context.Context, arg GetClosedPeriodsParams) ([]GetClosedPeriodsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getClosedPeriods, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetClosedPeriodsRow
+	for rows.Next() {
+		var i GetClosedPeriodsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ClosedUntil,
+			&i.Note,
+			&i.CreatedAt,
+			&i.CreatedByUserID,
+			&i.CreatedByUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDiscountRuleById = `-- name: GetDiscountRuleById :one
+SELECT id, name, type, is_percentage, value, min_count, max_day_of_month, voucher_code, usage_limit, valid_from, valid_until, is_deactivated FROM discount_rule
+WHERE id = ? LIMIT 1
+`
+
+// ============================== DISCOUNT_RULE ==============================
+func (q *Queries) GetDiscountRuleById(ctx context.Context, id int64) (DiscountRule, error) {
+	row := q.db.QueryRowContext(ctx, getDiscountRuleById, id)
+	var i DiscountRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Type,
+		&i.IsPercentage,
+		&i.Value,
+		&i.MinCount,
+		&i.MaxDayOfMonth,
+		&i.VoucherCode,
+		&i.UsageLimit,
+		&i.ValidFrom,
+		&i.ValidUntil,
+		&i.IsDeactivated,
+	)
+	return i, err
+}
+
+const getDiscountRuleByIdForUpdate = `-- name: GetDiscountRuleByIdForUpdate :one
+SELECT id, name, type, is_percentage, value, min_count, max_day_of_month, voucher_code, usage_limit, valid_from, valid_until, is_deactivated FROM discount_rule
+WHERE id = ? LIMIT 1
+FOR UPDATE
+`
+
+// GetDiscountRuleByIdForUpdate locks the discount_rule until the transaction ends, to prevent a voucher from being used beyond its usage_limit concurrently.
+func (q *Queries) GetDiscountRuleByIdForUpdate(ctx context.Context, id int64) (DiscountRule, error) {
+	row := q.db.QueryRowContext(ctx, getDiscountRuleByIdForUpdate, id)
+	var i DiscountRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Type,
+		&i.IsPercentage,
+		&i.Value,
+		&i.MinCount,
+		&i.MaxDayOfMonth,
+		&i.VoucherCode,
+		&i.UsageLimit,
+		&i.ValidFrom,
+		&i.ValidUntil,
+		&i.IsDeactivated,
+	)
+	return i, err
+}
+
+const getDiscountRuleByVoucherCode = `-- name: GetDiscountRuleByVoucherCode :one
+SELECT id, name, type, is_percentage, value, min_count, max_day_of_month, voucher_code, usage_limit, valid_from, valid_until, is_deactivated FROM discount_rule
+WHERE voucher_code = ? LIMIT 1
+`
+
+func (q *Queries) GetDiscountRuleByVoucherCode(ctx context.Context, voucherCode sql.NullString) (DiscountRule, error) {
+	row := q.db.QueryRowContext(ctx, getDiscountRuleByVoucherCode, voucherCode)
+	var i DiscountRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Type,
+		&i.IsPercentage,
+		&i.Value,
+		&i.MinCount,
+		&i.MaxDayOfMonth,
+		&i.VoucherCode,
+		&i.UsageLimit,
+		&i.ValidFrom,
+		&i.ValidUntil,
+		&i.IsDeactivated,
+	)
+	return i, err
+}
+
+const getDiscountRules = `-- name: GetDiscountRules :many
+SELECT id, name, type, is_percentage, value, min_count, max_day_of_month, voucher_code, usage_limit, valid_from, valid_until, is_deactivated FROM discount_rule
+ORDER BY id
+LIMIT ? OFFSET ?
+`
+
+type GetDiscountRulesParams struct {
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) GetDiscountRules(ctx context.Context, arg GetDiscountRulesParams) ([]DiscountRule, error) {
+	rows, err := q.db.QueryContext(ctx, getDiscountRules, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DiscountRule
+	for rows.Next() {
+		var i DiscountRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Type,
+			&i.IsPercentage,
+			&i.Value,
+			&i.MinCount,
+			&i.MaxDayOfMonth,
+			&i.VoucherCode,
+			&i.UsageLimit,
+			&i.ValidFrom,
+			&i.ValidUntil,
+			&i.IsDeactivated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDiscountRulesByIds = `-- name: GetDiscountRulesByIds :many
+SELECT id, name, type, is_percentage, value, min_count, max_day_of_month, voucher_code, usage_limit, valid_from, valid_until, is_deactivated FROM discount_rule
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) GetDiscountRulesByIds(ctx context.Context, ids []int64) ([]DiscountRule, error) {
+	query := getDiscountRulesByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DiscountRule
+	for rows.Next() {
+		var i DiscountRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Type,
+			&i.IsPercentage,
+			&i.Value,
+			&i.MinCount,
+			&i.MaxDayOfMonth,
+			&i.VoucherCode,
+			&i.UsageLimit,
+			&i.ValidFrom,
+			&i.ValidUntil,
+			&i.IsDeactivated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEarliestAvailableSLTsByStudentEnrollmentIds = `-- name: GetEarliestAvailableSLTsByStudentEnrollmentIds :many
 WITH slt_min_max AS (
     -- fetch earliest SLT with quota > 0
     SELECT enrollment_id, MIN(last_updated_at) AS updateDateWithNonZeroQuota_or_maxUpdateDate
@@ -244,59 +1413,1869 @@ WITH slt_min_max AS (
 SELECT slt.id AS student_learning_token_id, quota, course_fee_quarter_value, transport_fee_quarter_value, created_at, last_updated_at, slt.enrollment_id AS enrollment_id,
     se.student_id AS student_id
 FROM student_learning_token AS slt
-    JOIN (
-        -- we have 1-2 SLT option per enrollment_id from ` + "`" + `slt_min_max` + "`" + `, pick the earliest
-        SELECT enrollment_id, MIN(updateDateWithNonZeroQuota_or_maxUpdateDate) AS earliestUpdateDateWithNonZeroQuota
-        FROM slt_min_max
-        GROUP BY enrollment_id
-    ) AS slt_min ON (
-        slt.enrollment_id = slt_min.enrollment_id
-        AND last_updated_at = earliestUpdateDateWithNonZeroQuota
-    )
+    JOIN (
+        -- we have 1-2 SLT option per enrollment_id from ` + "`" + `slt_min_max` + "`" + `, pick the earliest
+        SELECT enrollment_id, MIN(updateDateWithNonZeroQuota_or_maxUpdateDate) AS earliestUpdateDateWithNonZeroQuota
+        FROM slt_min_max
+        GROUP BY enrollment_id
+    ) AS slt_min ON (
+        slt.enrollment_id = slt_min.enrollment_id
+        AND last_updated_at = earliestUpdateDateWithNonZeroQuota
+    )
+
+    JOIN student_enrollment AS se ON slt.enrollment_id = se.id
+WHERE slt.enrollment_id IN (/*SLICE:student_enrollment_ids*/?)
+`
+
+type GetEarliestAvailableSLTsByStudentEnrollmentIdsRow struct {
+	StudentLearningTokenID   int64
+	Quota                    float64
+	CourseFeeQuarterValue    int32
+	TransportFeeQuarterValue int32
+	CreatedAt                time.Time
+	LastUpdatedAt            time.Time
+	EnrollmentID             int64
+	StudentID                int64
+}
+
+func (q *Queries) GetEarliestAvailableSLTsByStudentEnrollmentIds(ctx context.Context, studentEnrollmentIds []int64) ([]GetEarliestAvailableSLTsByStudentEnrollmentIdsRow, error) {
+	query := getEarliestAvailableSLTsByStudentEnrollmentIds
+	var queryParams []interface{}
+	if len(studentEnrollmentIds) > 0 {
+		for _, v := range studentEnrollmentIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:student_enrollment_ids*/?", strings.Repeat(",?", len(studentEnrollmentIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:student_enrollment_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEarliestAvailableSLTsByStudentEnrollmentIdsRow
+	for rows.Next() {
+		var i GetEarliestAvailableSLTsByStudentEnrollmentIdsRow
+		if err := rows.Scan(
+			&i.StudentLearningTokenID,
+			&i.Quota,
+			&i.CourseFeeQuarterValue,
+			&i.TransportFeeQuarterValue,
+			&i.CreatedAt,
+			&i.LastUpdatedAt,
+			&i.EnrollmentID,
+			&i.StudentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEnrollmentPaymentById = `-- name: GetEnrollmentPaymentById :one
+SELECT ep.id AS enrollment_payment_id, payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, payment_method, receiving_account, reference_number, ep.enrollment_id AS student_enrollment_id,
+    se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
+    -- we cannot use sqlc.embed(class|course|instrument|grade), due to ` + "`" + `EnrollmentPayment` + "`" + ` may have null ` + "`" + `StudentEnrollment` + "`" + ` (deleted enrollment).
+    -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
+    class.id AS class_id, class.transport_fee AS class_transport_fee, class.auto_owe_attendance_token AS class_auto_owe_attendance_token, class.is_deactivated AS class_is_deactivated, tsf.fee AS teacher_special_fee,
+    course.id AS course_id, course.default_fee AS course_default_fee, course.default_duration_minute AS course_default_duration_minute, instrument.id AS instrument_id, instrument.name AS instrument_name, grade.id AS grade_id, grade.name AS grade_name,
+    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    ep.snapshot_student_name, ep.snapshot_class_description, ep.snapshot_course_name, ep.snapshot_instrument_name, ep.snapshot_grade_name, ep.snapshot_teacher_name
+FROM enrollment_payment AS ep
+    LEFT JOIN student_enrollment AS se ON ep.enrollment_id = se.id
+
+    LEFT JOIN student ON se.student_id = student.id
+    LEFT JOIN user AS user_student ON student.user_id = user_student.id
+    
+    LEFT JOIN class on se.class_id = class.id
+    LEFT JOIN course ON class.course_id = course.id
+    LEFT JOIN instrument ON course.instrument_id = instrument.id
+    LEFT JOIN grade ON course.grade_id = grade.id
+    
+    LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
+    LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
+    LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+WHERE ep.id = ? LIMIT 1
+`
+
+type GetEnrollmentPaymentByIdRow struct {
+	EnrollmentPaymentID         int64
+	PaymentDate                 time.Time
+	BalanceTopUp                int32
+	BalanceBonus                int32
+	CourseFeeValue              int32
+	TransportFeeValue           int32
+	PenaltyFeeValue             int32
+	DiscountFeeValue            int32
+	PaymentMethod               string
+	ReceivingAccount            string
+	ReferenceNumber             string
+	StudentEnrollmentID         sql.NullInt64
+	StudentID                   sql.NullInt64
+	StudentUsername             sql.NullString
+	StudentDetail               []byte
+	ClassID                     sql.NullInt64
+	ClassTransportFee           sql.NullInt32
+	ClassAutoOweAttendanceToken sql.NullInt32
+	ClassIsDeactivated          sql.NullInt32
+	TeacherSpecialFee           sql.NullInt32
+	CourseID                    sql.NullInt64
+	CourseDefaultFee            sql.NullInt32
+	CourseDefaultDurationMinute sql.NullInt32
+	InstrumentID                sql.NullInt64
+	InstrumentName              sql.NullString
+	GradeID                     sql.NullInt64
+	GradeName                   sql.NullString
+	ClassTeacherID              sql.NullInt64
+	ClassTeacherUsername        sql.NullString
+	ClassTeacherDetail          []byte
+	SnapshotStudentName         string
+	SnapshotClassDescription    string
+	SnapshotCourseName          string
+	SnapshotInstrumentName      string
+	SnapshotGradeName           string
+	SnapshotTeacherName         string
+}
+
+func (q *Queries) GetEnrollmentPaymentById(ctx context.Context, id int64) (GetEnrollmentPaymentByIdRow, error) {
+	row := q.db.QueryRowContext(ctx, getEnrollmentPaymentById, id)
+	var i GetEnrollmentPaymentByIdRow
+	err := row.Scan(
+		&i.EnrollmentPaymentID,
+		&i.PaymentDate,
+		&i.BalanceTopUp,
+		&i.BalanceBonus,
+		&i.CourseFeeValue,
+		&i.TransportFeeValue,
+		&i.PenaltyFeeValue,
+		&i.DiscountFeeValue,
+		&i.PaymentMethod,
+		&i.ReceivingAccount,
+		&i.ReferenceNumber,
+		&i.StudentEnrollmentID,
+		&i.StudentID,
+		&i.StudentUsername,
+		&i.StudentDetail,
+		&i.ClassID,
+		&i.ClassTransportFee,
+		&i.ClassAutoOweAttendanceToken,
+		&i.ClassIsDeactivated,
+		&i.TeacherSpecialFee,
+		&i.CourseID,
+		&i.CourseDefaultFee,
+		&i.CourseDefaultDurationMinute,
+		&i.InstrumentID,
+		&i.InstrumentName,
+		&i.GradeID,
+		&i.GradeName,
+		&i.ClassTeacherID,
+		&i.ClassTeacherUsername,
+		&i.ClassTeacherDetail,
+		&i.SnapshotStudentName,
+		&i.SnapshotClassDescription,
+		&i.SnapshotCourseName,
+		&i.SnapshotInstrumentName,
+		&i.SnapshotGradeName,
+		&i.SnapshotTeacherName,
+	)
+	return i, err
+}
+
+const getEnrollmentPaymentDateByIdForUpdate = `-- name: GetEnrollmentPaymentDateByIdForUpdate :one
+SELECT payment_date FROM enrollment_payment
+WHERE id = ? LIMIT 1
+FOR UPDATE
+`
+
+// GetEnrollmentPaymentDateByIdForUpdate locks the enrollment_payment row until the end of the transaction, to serialize the refunds of the enrollment_payment.
+func (q *Queries) GetEnrollmentPaymentDateByIdForUpdate(ctx context.Context, id int64) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getEnrollmentPaymentDateByIdForUpdate, id)
+	var payment_date time.Time
+	err := row.Scan(&payment_date)
+	return payment_date, err
+}
+
+const getEnrollmentPaymentDatesByIds = `-- name: GetEnrollmentPaymentDatesByIds :many
+SELECT payment_date FROM enrollment_payment
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) GetEnrollmentPaymentDatesByIds(ctx context.Context, ids []int64) ([]time.Time, error) {
+	query := getEnrollmentPaymentDatesByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []time.Time
+	for rows.Next() {
+		var payment_date time.Time
+		if err := rows.Scan(&payment_date); err != nil {
+			return nil, err
+		}
+		items = append(items, payment_date)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEnrollmentPaymentReceiptByEnrollmentPaymentId = `-- name: GetEnrollmentPaymentReceiptByEnrollmentPaymentId :one
+SELECT id, receipt_number, enrollment_payment_id, issued_at, issued_by_user_id FROM enrollment_payment_receipt
+WHERE enrollment_payment_id = ? LIMIT 1
+`
+
+// ============================== ENROLLMENT_PAYMENT_RECEIPT ==============================
+func (q *Queries) GetEnrollmentPaymentReceiptByEnrollmentPaymentId(ctx context.Context, enrollmentPaymentID sql.NullInt64) (EnrollmentPaymentReceipt, error) {
+	row := q.db.QueryRowContext(ctx, getEnrollmentPaymentReceiptByEnrollmentPaymentId, enrollmentPaymentID)
+	var i EnrollmentPaymentReceipt
+	err := row.Scan(
+		&i.ID,
+		&i.ReceiptNumber,
+		&i.EnrollmentPaymentID,
+		&i.IssuedAt,
+		&i.IssuedByUserID,
+	)
+	return i, err
+}
+
+const getEnrollmentPaymentRefundTotalByEnrollmentPaymentId = `-- name: GetEnrollmentPaymentRefundTotalByEnrollmentPaymentId :one
+SELECT Count(id) AS total_refunds, CAST(COALESCE(SUM(refunded_value), 0) AS SIGNED) AS total_refunded_value, CAST(COALESCE(SUM(withdrawn_quota), 0) AS DOUBLE) AS total_withdrawn_quota
+FROM enrollment_payment_refund
+WHERE enrollment_payment_id = ?
+`
+
+type GetEnrollmentPaymentRefundTotalByEnrollmentPaymentIdRow struct {
+	TotalRefunds        int64
+	TotalRefundedValue  int64
+	TotalWithdrawnQuota float64
+}
+
+func (q *Queries) GetEnrollmentPaymentRefundTotalByEnrollmentPaymentId(ctx context.Context, enrollmentPaymentID int64) (GetEnrollmentPaymentRefundTotalByEnrollmentPaymentIdRow, error) {
+	row := q.db.QueryRowContext(ctx, getEnrollmentPaymentRefundTotalByEnrollmentPaymentId, enrollmentPaymentID)
+	var i GetEnrollmentPaymentRefundTotalByEnrollmentPaymentIdRow
+	err := row.Scan(&i.TotalRefunds, &i.TotalRefundedValue, &i.TotalWithdrawnQuota)
+	return i, err
+}
+
+const getEnrollmentPaymentRefundTotalByEnrollmentPaymentIdForUpdate = `-- name: GetEnrollmentPaymentRefundTotalByEnrollmentPaymentIdForUpdate :one
+SELECT Count(id) AS total_refunds, CAST(COALESCE(SUM(refunded_value), 0) AS SIGNED) AS total_refunded_value, CAST(COALESCE(SUM(withdrawn_quota), 0) AS DOUBLE) AS total_withdrawn_quota
+FROM enrollment_payment_refund
+WHERE enrollment_payment_id = ?
+FOR UPDATE
+`
+
+type GetEnrollmentPaymentRefundTotalByEnrollmentPaymentIdForUpdateRow struct {
+	TotalRefunds        int64
+	TotalRefundedValue  int64
+	TotalWithdrawnQuota float64
+}
+
+func (q *Queries) GetEnrollmentPaymentRefundTotalByEnrollmentPaymentIdForUpdate(ctx context.Context, enrollmentPaymentID int64) (GetEnrollmentPaymentRefundTotalByEnrollmentPaymentIdForUpdateRow, error) {
+	row := q.db.QueryRowContext(ctx, getEnrollmentPaymentRefundTotalByEnrollmentPaymentIdForUpdate, enrollmentPaymentID)
+	var i GetEnrollmentPaymentRefundTotalByEnrollmentPaymentIdForUpdateRow
+	err := row.Scan(&i.TotalRefunds, &i.TotalRefundedValue, &i.TotalWithdrawnQuota)
+	return i, err
+}
+
+const getEnrollmentPaymentRefundTotalsGroupedByPaymentMethod = `-- name: GetEnrollmentPaymentRefundTotalsGroupedByPaymentMethod :many
+SELECT ep.payment_method, ep.receiving_account,
+    Count(epr.id) AS total_refunds,
+    CAST(COALESCE(SUM(epr.refunded_value), 0) AS SIGNED) AS total_refunded_value
+FROM enrollment_payment_refund AS epr
+    JOIN enrollment_payment AS ep ON epr.enrollment_payment_id = ep.id
+WHERE epr.refund_date >= ? AND epr.refund_date <= ?
+GROUP BY ep.payment_method, ep.receiving_account
+ORDER BY ep.payment_method, ep.receiving_account
+`
+
+type GetEnrollmentPaymentRefundTotalsGroupedByPaymentMethodParams struct {
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+type GetEnrollmentPaymentRefundTotalsGroupedByPaymentMethodRow struct {
+	PaymentMethod      string
+	ReceivingAccount   string
+	TotalRefunds       int64
+	TotalRefundedValue int64
+}
+
+// GetEnrollmentPaymentRefundTotalsGroupedByPaymentMethod sums up the refunded money within a date range, grouped by the refunded enrollment_payments' payment method & receiving account.
+func (q *Queries) GetEnrollmentPaymentRefundTotalsGroupedByPaymentMethod(ctx context.Context, arg GetEnrollmentPaymentRefundTotalsGroupedByPaymentMethodParams) ([]GetEnrollmentPaymentRefundTotalsGroupedByPaymentMethodRow, error) {
+	rows, err := q.db.QueryContext(ctx, getEnrollmentPaymentRefundTotalsGroupedByPaymentMethod, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEnrollmentPaymentRefundTotalsGroupedByPaymentMethodRow
+	for rows.Next() {
+		var i GetEnrollmentPaymentRefundTotalsGroupedByPaymentMethodRow
+		if err := rows.Scan(
+			&i.PaymentMethod,
+			&i.ReceivingAccount,
+			&i.TotalRefunds,
+			&i.TotalRefundedValue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEnrollmentPaymentRefunds = `-- name: GetEnrollmentPaymentRefunds :many
+SELECT epr.id, epr.refund_date, epr.refunded_value, epr.withdrawn_quota, epr.reason, epr.created_at, epr.created_by_user_id, epr.enrollment_payment_id,
+    ep.payment_date, ep.enrollment_id, se.student_id, user.username AS student_username, user.user_detail AS student_detail
+FROM enrollment_payment_refund AS epr
+    JOIN enrollment_payment AS ep ON epr.enrollment_payment_id = ep.id
+    JOIN student_enrollment AS se ON ep.enrollment_id = se.id
+    JOIN student ON se.student_id = student.id
+    JOIN user ON student.user_id = user.id
+ORDER BY epr.refund_date DESC, epr.id DESC
+LIMIT ? OFFSET ?
+`
+
+type GetEnrollmentPaymentRefundsParams struct {
+	Limit  int32
+	Offset int32
+}
+
+type GetEnrollmentPaymentRefundsRow struct {
+	ID                  int64
+	RefundDate          time.Time
+	RefundedValue       int32
+	WithdrawnQuota      float64
+	Reason              string
+	CreatedAt           time.Time
+	CreatedByUserID     sql.NullInt64
+	EnrollmentPaymentID int64
+	PaymentDate         time.Time
+	EnrollmentID        sql.NullInt64
+	StudentID           int64
+	StudentUsername     string
+	StudentDetail       json.RawMessage
+}
+
+// ============================== ENROLLMENT_PAYMENT_REFUND ==============================
+func (q *Queries) GetEnrollmentPaymentRefunds(ctx context.Context, arg GetEnrollmentPaymentRefundsParams) ([]GetEnrollmentPaymentRefundsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getEnrollmentPaymentRefunds, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEnrollmentPaymentRefundsRow
+	for rows.Next() {
+		var i GetEnrollmentPaymentRefundsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RefundDate,
+			&i.RefundedValue,
+			&i.WithdrawnQuota,
+			&i.Reason,
+			&i.CreatedAt,
+			&i.CreatedByUserID,
+			&i.EnrollmentPaymentID,
+			&i.PaymentDate,
+			&i.EnrollmentID,
+			&i.StudentID,
+			&i.StudentUsername,
+			&i.StudentDetail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEnrollmentPaymentTotalsGroupedByPaymentMethod = `-- name: GetEnrollmentPaymentTotalsGroupedByPaymentMethod :many
+SELECT payment_method, receiving_account,
+    Count(id) AS total_payments,
+    CAST(COALESCE(SUM(course_fee_value + transport_fee_value + penalty_fee_value - discount_fee_value), 0) AS SIGNED) AS total_value
+FROM enrollment_payment
+WHERE payment_date >= ? AND payment_date <= ?
+GROUP BY payment_method, receiving_account
+ORDER BY payment_method, receiving_account
+`
+
+type GetEnrollmentPaymentTotalsGroupedByPaymentMethodParams struct {
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+type GetEnrollmentPaymentTotalsGroupedByPaymentMethodRow struct {
+	PaymentMethod    string
+	ReceivingAccount string
+	TotalPayments    int64
+	TotalValue       int64
+}
+
+// GetEnrollmentPaymentTotalsGroupedByPaymentMethod sums up the received money (i.e. after discount) of enrollment_payments within a date range, grouped by payment method & receiving account.
+// ============================== CASH_UP_DAY ==============================
+func (q *Queries) GetEnrollmentPaymentTotalsGroupedByPaymentMethod(ctx context.Context, arg GetEnrollmentPaymentTotalsGroupedByPaymentMethodParams) ([]GetEnrollmentPaymentTotalsGroupedByPaymentMethodRow, error) {
+	rows, err := q.db.QueryContext(ctx, getEnrollmentPaymentTotalsGroupedByPaymentMethod, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEnrollmentPaymentTotalsGroupedByPaymentMethodRow
+	for rows.Next() {
+		var i GetEnrollmentPaymentTotalsGroupedByPaymentMethodRow
+		if err := rows.Scan(
+			&i.PaymentMethod,
+			&i.ReceivingAccount,
+			&i.TotalPayments,
+			&i.TotalValue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEnrollmentPayments = `-- name: GetEnrollmentPayments :many
+SELECT ep.id AS enrollment_payment_id, payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, payment_method, receiving_account, reference_number, ep.enrollment_id AS student_enrollment_id,
+    se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
+    -- we cannot use sqlc.embed(class|course|instrument|grade), due to ` + "`" + `EnrollmentPayment` + "`" + ` may have null ` + "`" + `StudentEnrollment` + "`" + ` (deleted enrollment).
+    -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
+    class.id AS class_id, class.transport_fee AS class_transport_fee, class.auto_owe_attendance_token AS class_auto_owe_attendance_token, class.is_deactivated AS class_is_deactivated, tsf.fee AS teacher_special_fee,
+    course.id AS course_id, course.default_fee AS course_default_fee, course.default_duration_minute AS course_default_duration_minute, instrument.id AS instrument_id, instrument.name AS instrument_name, grade.id AS grade_id, grade.name AS grade_name,
+    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    ep.snapshot_student_name, ep.snapshot_class_description, ep.snapshot_course_name, ep.snapshot_instrument_name, ep.snapshot_grade_name, ep.snapshot_teacher_name
+FROM enrollment_payment AS ep
+    LEFT JOIN student_enrollment AS se ON ep.enrollment_id = se.id
+
+    LEFT JOIN student ON se.student_id = student.id
+    LEFT JOIN user AS user_student ON student.user_id = user_student.id
+    
+    LEFT JOIN class on se.class_id = class.id
+    LEFT JOIN course ON class.course_id = course.id
+    LEFT JOIN instrument ON course.instrument_id = instrument.id
+    LEFT JOIN grade ON course.grade_id = grade.id
+    
+    LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
+    LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
+    LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+WHERE
+    ep.payment_date >= ? AND ep.payment_date <= ?
+ORDER BY ep.id
+LIMIT ? OFFSET ?
+`
+
+type GetEnrollmentPaymentsParams struct {
+	StartDate time.Time
+	EndDate   time.Time
+	Limit     int32
+	Offset    int32
+}
+
+type GetEnrollmentPaymentsRow struct {
+	EnrollmentPaymentID         int64
+	PaymentDate                 time.Time
+	BalanceTopUp                int32
+	BalanceBonus                int32
+	CourseFeeValue              int32
+	TransportFeeValue           int32
+	PenaltyFeeValue             int32
+	DiscountFeeValue            int32
+	PaymentMethod               string
+	ReceivingAccount            string
+	ReferenceNumber             string
+	StudentEnrollmentID         sql.NullInt64
+	StudentID                   sql.NullInt64
+	StudentUsername             sql.NullString
+	StudentDetail               []byte
+	ClassID                     sql.NullInt64
+	ClassTransportFee           sql.NullInt32
+	ClassAutoOweAttendanceToken sql.NullInt32
+	ClassIsDeactivated          sql.NullInt32
+	TeacherSpecialFee           sql.NullInt32
+	CourseID                    sql.NullInt64
+	CourseDefaultFee            sql.NullInt32
+	CourseDefaultDurationMinute sql.NullInt32
+	InstrumentID                sql.NullInt64
+	InstrumentName              sql.NullString
+	GradeID                     sql.NullInt64
+	GradeName                   sql.NullString
+	ClassTeacherID              sql.NullInt64
+	ClassTeacherUsername        sql.NullString
+	ClassTeacherDetail          []byte
+	SnapshotStudentName         string
+	SnapshotClassDescription    string
+	SnapshotCourseName          string
+	SnapshotInstrumentName      string
+	SnapshotGradeName           string
+	SnapshotTeacherName         string
+}
+
+func (q *Queries) GetEnrollmentPayments(ctx context.Context, arg GetEnrollmentPaymentsParams) ([]GetEnrollmentPaymentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getEnrollmentPayments,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEnrollmentPaymentsRow
+	for rows.Next() {
+		var i GetEnrollmentPaymentsRow
+		if err := rows.Scan(
+			&i.EnrollmentPaymentID,
+			&i.PaymentDate,
+			&i.BalanceTopUp,
+			&i.BalanceBonus,
+			&i.CourseFeeValue,
+			&i.TransportFeeValue,
+			&i.PenaltyFeeValue,
+			&i.DiscountFeeValue,
+			&i.PaymentMethod,
+			&i.ReceivingAccount,
+			&i.ReferenceNumber,
+			&i.StudentEnrollmentID,
+			&i.StudentID,
+			&i.StudentUsername,
+			&i.StudentDetail,
+			&i.ClassID,
+			&i.ClassTransportFee,
+			&i.ClassAutoOweAttendanceToken,
+			&i.ClassIsDeactivated,
+			&i.TeacherSpecialFee,
+			&i.CourseID,
+			&i.CourseDefaultFee,
+			&i.CourseDefaultDurationMinute,
+			&i.InstrumentID,
+			&i.InstrumentName,
+			&i.GradeID,
+			&i.GradeName,
+			&i.ClassTeacherID,
+			&i.ClassTeacherUsername,
+			&i.ClassTeacherDetail,
+			&i.SnapshotStudentName,
+			&i.SnapshotClassDescription,
+			&i.SnapshotCourseName,
+			&i.SnapshotInstrumentName,
+			&i.SnapshotGradeName,
+			&i.SnapshotTeacherName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEnrollmentPaymentsByIds = `-- name: GetEnrollmentPaymentsByIds :many
+SELECT ep.id AS enrollment_payment_id, payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, payment_method, receiving_account, reference_number, ep.enrollment_id AS student_enrollment_id,
+    se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
+    -- we cannot use sqlc.embed(class|course|instrument|grade), due to ` + "`" + `EnrollmentPayment` + "`" + ` may have null ` + "`" + `StudentEnrollment` + "`" + ` (deleted enrollment).
+    -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
+    class.id AS class_id, class.transport_fee AS class_transport_fee, class.auto_owe_attendance_token AS class_auto_owe_attendance_token, class.is_deactivated AS class_is_deactivated, tsf.fee AS teacher_special_fee,
+    course.id AS course_id, course.default_fee AS course_default_fee, course.default_duration_minute AS course_default_duration_minute, instrument.id AS instrument_id, instrument.name AS instrument_name, grade.id AS grade_id, grade.name AS grade_name,
+    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    ep.snapshot_student_name, ep.snapshot_class_description, ep.snapshot_course_name, ep.snapshot_instrument_name, ep.snapshot_grade_name, ep.snapshot_teacher_name
+FROM enrollment_payment AS ep
+    LEFT JOIN student_enrollment AS se ON ep.enrollment_id = se.id
+
+    LEFT JOIN student ON se.student_id = student.id
+    LEFT JOIN user AS user_student ON student.user_id = user_student.id
+    
+    LEFT JOIN class on se.class_id = class.id
+    LEFT JOIN course ON class.course_id = course.id
+    LEFT JOIN instrument ON course.instrument_id = instrument.id
+    LEFT JOIN grade ON course.grade_id = grade.id
+    
+    LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
+    LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
+    LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+WHERE ep.id IN (/*SLICE:ids*/?)
+`
+
+type GetEnrollmentPaymentsByIdsRow struct {
+	EnrollmentPaymentID         int64
+	PaymentDate                 time.Time
+	BalanceTopUp                int32
+	BalanceBonus                int32
+	CourseFeeValue              int32
+	TransportFeeValue           int32
+	PenaltyFeeValue             int32
+	DiscountFeeValue            int32
+	PaymentMethod               string
+	ReceivingAccount            string
+	ReferenceNumber             string
+	StudentEnrollmentID         sql.NullInt64
+	StudentID                   sql.NullInt64
+	StudentUsername             sql.NullString
+	StudentDetail               []byte
+	ClassID                     sql.NullInt64
+	ClassTransportFee           sql.NullInt32
+	ClassAutoOweAttendanceToken sql.NullInt32
+	ClassIsDeactivated          sql.NullInt32
+	TeacherSpecialFee           sql.NullInt32
+	CourseID                    sql.NullInt64
+	CourseDefaultFee            sql.NullInt32
+	CourseDefaultDurationMinute sql.NullInt32
+	InstrumentID                sql.NullInt64
+	InstrumentName              sql.NullString
+	GradeID                     sql.NullInt64
+	GradeName                   sql.NullString
+	ClassTeacherID              sql.NullInt64
+	ClassTeacherUsername        sql.NullString
+	ClassTeacherDetail          []byte
+	SnapshotStudentName         string
+	SnapshotClassDescription    string
+	SnapshotCourseName          string
+	SnapshotInstrumentName      string
+	SnapshotGradeName           string
+	SnapshotTeacherName         string
+}
+
+func (q *Queries) GetEnrollmentPaymentsByIds(ctx context.Context, ids []int64) ([]GetEnrollmentPaymentsByIdsRow, error) {
+	query := getEnrollmentPaymentsByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEnrollmentPaymentsByIdsRow
+	for rows.Next() {
+		var i GetEnrollmentPaymentsByIdsRow
+		if err := rows.Scan(
+			&i.EnrollmentPaymentID,
+			&i.PaymentDate,
+			&i.BalanceTopUp,
+			&i.BalanceBonus,
+			&i.CourseFeeValue,
+			&i.TransportFeeValue,
+			&i.PenaltyFeeValue,
+			&i.DiscountFeeValue,
+			&i.PaymentMethod,
+			&i.ReceivingAccount,
+			&i.ReferenceNumber,
+			&i.StudentEnrollmentID,
+			&i.StudentID,
+			&i.StudentUsername,
+			&i.StudentDetail,
+			&i.ClassID,
+			&i.ClassTransportFee,
+			&i.ClassAutoOweAttendanceToken,
+			&i.ClassIsDeactivated,
+			&i.TeacherSpecialFee,
+			&i.CourseID,
+			&i.CourseDefaultFee,
+			&i.CourseDefaultDurationMinute,
+			&i.InstrumentID,
+			&i.InstrumentName,
+			&i.GradeID,
+			&i.GradeName,
+			&i.ClassTeacherID,
+			&i.ClassTeacherUsername,
+			&i.ClassTeacherDetail,
+			&i.SnapshotStudentName,
+			&i.SnapshotClassDescription,
+			&i.SnapshotCourseName,
+			&i.SnapshotInstrumentName,
+			&i.SnapshotGradeName,
+			&i.SnapshotTeacherName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEnrollmentPaymentsDescendingDate = `-- name: GetEnrollmentPaymentsDescendingDate :many
+SELECT ep.id AS enrollment_payment_id, payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, payment_method, receiving_account, reference_number, ep.enrollment_id AS student_enrollment_id,
+    se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
+    -- we cannot use sqlc.embed(class|course|instrument|grade), due to ` + "`" + `EnrollmentPayment` + "`" + ` may have null ` + "`" + `StudentEnrollment` + "`" + ` (deleted enrollment).
+    -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
+    class.id AS class_id, class.transport_fee AS class_transport_fee, class.auto_owe_attendance_token AS class_auto_owe_attendance_token, class.is_deactivated AS class_is_deactivated, tsf.fee AS teacher_special_fee,
+    course.id AS course_id, course.default_fee AS course_default_fee, course.default_duration_minute AS course_default_duration_minute, instrument.id AS instrument_id, instrument.name AS instrument_name, grade.id AS grade_id, grade.name AS grade_name,
+    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    ep.snapshot_student_name, ep.snapshot_class_description, ep.snapshot_course_name, ep.snapshot_instrument_name, ep.snapshot_grade_name, ep.snapshot_teacher_name
+FROM enrollment_payment AS ep
+    LEFT JOIN student_enrollment AS se ON ep.enrollment_id = se.id
+
+    LEFT JOIN student ON se.student_id = student.id
+    LEFT JOIN user AS user_student ON student.user_id = user_student.id
+    
+    LEFT JOIN class on se.class_id = class.id
+    LEFT JOIN course ON class.course_id = course.id
+    LEFT JOIN instrument ON course.instrument_id = instrument.id
+    LEFT JOIN grade ON course.grade_id = grade.id
+    
+    LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
+    LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
+    LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+WHERE
+    ep.payment_date >= ? AND ep.payment_date <= ?
+ORDER BY ep.payment_date DESC, ep.id DESC
+LIMIT ? OFFSET ?
+`
+
+type GetEnrollmentPaymentsDescendingDateParams struct {
+	StartDate time.Time
+	EndDate   time.Time
+	Limit     int32
+	Offset    int32
+}
+
+type GetEnrollmentPaymentsDescendingDateRow struct {
+	EnrollmentPaymentID         int64
+	PaymentDate                 time.Time
+	BalanceTopUp                int32
+	BalanceBonus                int32
+	CourseFeeValue              int32
+	TransportFeeValue           int32
+	PenaltyFeeValue             int32
+	DiscountFeeValue            int32
+	PaymentMethod               string
+	ReceivingAccount            string
+	ReferenceNumber             string
+	StudentEnrollmentID         sql.NullInt64
+	StudentID                   sql.NullInt64
+	StudentUsername             sql.NullString
+	StudentDetail               []byte
+	ClassID                     sql.NullInt64
+	ClassTransportFee           sql.NullInt32
+	ClassAutoOweAttendanceToken sql.NullInt32
+	ClassIsDeactivated          sql.NullInt32
+	TeacherSpecialFee           sql.NullInt32
+	CourseID                    sql.NullInt64
+	CourseDefaultFee            sql.NullInt32
+	CourseDefaultDurationMinute sql.NullInt32
+	InstrumentID                sql.NullInt64
+	InstrumentName              sql.NullString
+	GradeID                     sql.NullInt64
+	GradeName                   sql.NullString
+	ClassTeacherID              sql.NullInt64
+	ClassTeacherUsername        sql.NullString
+	ClassTeacherDetail          []byte
+	SnapshotStudentName         string
+	SnapshotClassDescription    string
+	SnapshotCourseName          string
+	SnapshotInstrumentName      string
+	SnapshotGradeName           string
+	SnapshotTeacherName         string
+}
+
+// GetEnrollmentPaymentsDescendingDate is a copy of GetEnrollmentPayments, with additional sort by date parameter. TODO: find alternative: sqlc's dynamic query which is mature enough, so that we need to do this.
+func (q *Queries) GetEnrollmentPaymentsDescendingDate(ctx context.Context, arg GetEnrollmentPaymentsDescendingDateParams) ([]GetEnrollmentPaymentsDescendingDateRow, error) {
+	rows, err := q.db.QueryContext(ctx, getEnrollmentPaymentsDescendingDate,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEnrollmentPaymentsDescendingDateRow
+	for rows.Next() {
+		var i GetEnrollmentPaymentsDescendingDateRow
+		if err := rows.Scan(
+			&i.EnrollmentPaymentID,
+			&i.PaymentDate,
+			&i.BalanceTopUp,
+			&i.BalanceBonus,
+			&i.CourseFeeValue,
+			&i.TransportFeeValue,
+			&i.PenaltyFeeValue,
+			&i.DiscountFeeValue,
+			&i.PaymentMethod,
+			&i.ReceivingAccount,
+			&i.ReferenceNumber,
+			&i.StudentEnrollmentID,
+			&i.StudentID,
+			&i.StudentUsername,
+			&i.StudentDetail,
+			&i.ClassID,
+			&i.ClassTransportFee,
+			&i.ClassAutoOweAttendanceToken,
+			&i.ClassIsDeactivated,
+			&i.TeacherSpecialFee,
+			&i.CourseID,
+			&i.CourseDefaultFee,
+			&i.CourseDefaultDurationMinute,
+			&i.InstrumentID,
+			&i.InstrumentName,
+			&i.GradeID,
+			&i.GradeName,
+			&i.ClassTeacherID,
+			&i.ClassTeacherUsername,
+			&i.ClassTeacherDetail,
+			&i.SnapshotStudentName,
+			&i.SnapshotClassDescription,
+			&i.SnapshotCourseName,
+			&i.SnapshotInstrumentName,
+			&i.SnapshotGradeName,
+			&i.SnapshotTeacherName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEnrollmentPaymentsForBankStatementMatching = `-- name: GetEnrollmentPaymentsForBankStatementMatching :many
+SELECT ep.id, ep.payment_date, ep.reference_number,
+    CAST(ep.course_fee_value + ep.transport_fee_value + ep.penalty_fee_value - ep.discount_fee_value AS SIGNED) AS total_value
+FROM enrollment_payment AS ep
+    LEFT JOIN bank_statement_line AS bsl ON bsl.enrollment_payment_id = ep.id
+WHERE ep.payment_date >= ? AND ep.payment_date <= ?
+    AND ep.payment_method <> 'CASH' AND bsl.id IS NULL
+ORDER BY ep.payment_date, ep.id
+`
+
+type GetEnrollmentPaymentsForBankStatementMatchingParams struct {
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+type GetEnrollmentPaymentsForBankStatementMatchingRow struct {
+	ID              int64
+	PaymentDate     time.Time
+	ReferenceNumber string
+	TotalValue      int64
+}
+
+// GetEnrollmentPaymentsForBankStatementMatching returns the non-cash enrollment_payments which haven't been matched to any bank_statement_line, along with their received money (i.e. after discount).
+func (q *Queries) GetEnrollmentPaymentsForBankStatementMatching(ctx context.Context, arg GetEnrollmentPaymentsForBankStatementMatchingParams) ([]GetEnrollmentPaymentsForBankStatementMatchingRow, error) {
+	rows, err := q.db.QueryContext(ctx, getEnrollmentPaymentsForBankStatementMatching, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEnrollmentPaymentsForBankStatementMatchingRow
+	for rows.Next() {
+		var i GetEnrollmentPaymentsForBankStatementMatchingRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PaymentDate,
+			&i.ReferenceNumber,
+			&i.TotalValue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEnrollmentPaymentsForSLTReconciliation = `-- name: GetEnrollmentPaymentsForSLTReconciliation :many
+SELECT id, payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, enrollment_id, payment_method, receiving_account, reference_number, snapshot_student_name, snapshot_class_description, snapshot_course_name, snapshot_instrument_name, snapshot_grade_name, snapshot_teacher_name FROM enrollment_payment
+WHERE enrollment_id IS NOT NULL
+ORDER BY id
+`
+
+func (q *Queries) GetEnrollmentPaymentsForSLTReconciliation(ctx context.Context) ([]EnrollmentPayment, error) {
+	rows, err := q.db.QueryContext(ctx, getEnrollmentPaymentsForSLTReconciliation)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EnrollmentPayment
+	for rows.Next() {
+		var i EnrollmentPayment
+		if err := rows.Scan(
+			&i.ID,
+			&i.PaymentDate,
+			&i.BalanceTopUp,
+			&i.BalanceBonus,
+			&i.CourseFeeValue,
+			&i.TransportFeeValue,
+			&i.PenaltyFeeValue,
+			&i.DiscountFeeValue,
+			&i.EnrollmentID,
+			&i.PaymentMethod,
+			&i.ReceivingAccount,
+			&i.ReferenceNumber,
+			&i.SnapshotStudentName,
+			&i.SnapshotClassDescription,
+			&i.SnapshotCourseName,
+			&i.SnapshotInstrumentName,
+			&i.SnapshotGradeName,
+			&i.SnapshotTeacherName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getInstallmentById = `-- name: GetInstallmentById :one
+SELECT installment.id, installment.installment_number, installment.due_date, installment.balance_top_up, installment.balance_bonus, installment.course_fee_value, installment.transport_fee_value, installment.penalty_fee_value, installment.discount_fee_value, installment.installment_plan_id, installment.enrollment_payment_id, installment_plan.enrollment_id
+FROM installment
+    JOIN installment_plan ON installment.installment_plan_id = installment_plan.id
+WHERE installment.id = ? LIMIT 1
+`
+
+type GetInstallmentByIdRow struct {
+	ID                  int64
+	InstallmentNumber   int32
+	DueDate             time.Time
+	BalanceTopUp        int32
+	BalanceBonus        int32
+	CourseFeeValue      int32
+	TransportFeeValue   int32
+	PenaltyFeeValue     int32
+	DiscountFeeValue    int32
+	InstallmentPlanID   int64
+	EnrollmentPaymentID sql.NullInt64
+	EnrollmentID        int64
+}
+
+func (q *Queries) GetInstallmentById(ctx context.Context, id int64) (GetInstallmentByIdRow, error) {
+	row := q.db.QueryRowContext(ctx, getInstallmentById, id)
+	var i GetInstallmentByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.InstallmentNumber,
+		&i.DueDate,
+		&i.BalanceTopUp,
+		&i.BalanceBonus,
+		&i.CourseFeeValue,
+		&i.TransportFeeValue,
+		&i.PenaltyFeeValue,
+		&i.DiscountFeeValue,
+		&i.InstallmentPlanID,
+		&i.EnrollmentPaymentID,
+		&i.EnrollmentID,
+	)
+	return i, err
+}
+
+const getInstallmentPlanById = `-- name: GetInstallmentPlanById :one
+SELECT id, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, installment_count, created_at, created_by_user_id, enrollment_id FROM installment_plan
+WHERE id = ? LIMIT 1
+`
+
+// ============================== INSTALLMENT_PLAN ==============================
+func (q *Queries) GetInstallmentPlanById(ctx context.Context, id int64) (InstallmentPlan, error) {
+	row := q.db.QueryRowContext(ctx, getInstallmentPlanById, id)
+	var i InstallmentPlan
+	err := row.Scan(
+		&i.ID,
+		&i.BalanceTopUp,
+		&i.BalanceBonus,
+		&i.CourseFeeValue,
+		&i.TransportFeeValue,
+		&i.PenaltyFeeValue,
+		&i.DiscountFeeValue,
+		&i.InstallmentCount,
+		&i.CreatedAt,
+		&i.CreatedByUserID,
+		&i.EnrollmentID,
+	)
+	return i, err
+}
+
+const getInstallmentsByInstallmentPlanId = `-- name: GetInstallmentsByInstallmentPlanId :many
+SELECT installment.id, installment.installment_number, installment.due_date, installment.balance_top_up, installment.balance_bonus, installment.course_fee_value, installment.transport_fee_value, installment.penalty_fee_value, installment.discount_fee_value, installment.installment_plan_id, installment.enrollment_payment_id, ep.payment_date
+FROM installment
+    LEFT JOIN enrollment_payment AS ep ON installment.enrollment_payment_id = ep.id
+WHERE installment.installment_plan_id = ?
+ORDER BY installment.installment_number
+`
+
+type GetInstallmentsByInstallmentPlanIdRow struct {
+	ID                  int64
+	InstallmentNumber   int32
+	DueDate             time.Time
+	BalanceTopUp        int32
+	BalanceBonus        int32
+	CourseFeeValue      int32
+	TransportFeeValue   int32
+	PenaltyFeeValue     int32
+	DiscountFeeValue    int32
+	InstallmentPlanID   int64
+	EnrollmentPaymentID sql.NullInt64
+	PaymentDate         sql.NullTime
+}
+
+func (q *Queries) GetInstallmentsByInstallmentPlanId(ctx context.Context, installmentPlanID int64) ([]GetInstallmentsByInstallmentPlanIdRow, error) {
+	rows, err := q.db.QueryContext(ctx, getInstallmentsByInstallmentPlanId, installmentPlanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetInstallmentsByInstallmentPlanIdRow
+	for rows.Next() {
+		var i GetInstallmentsByInstallmentPlanIdRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.InstallmentNumber,
+			&i.DueDate,
+			&i.BalanceTopUp,
+			&i.BalanceBonus,
+			&i.CourseFeeValue,
+			&i.TransportFeeValue,
+			&i.PenaltyFeeValue,
+			&i.DiscountFeeValue,
+			&i.InstallmentPlanID,
+			&i.EnrollmentPaymentID,
+			&i.PaymentDate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLastReceiptNumberForUpdate = `-- name: GetLastReceiptNumberForUpdate :one
+SELECT last_receipt_number FROM receipt_number_sequence
+WHERE id = 1
+FOR UPDATE
+`
+
+// GetLastReceiptNumberForUpdate locks the receipt number counter until the transaction ends, which serializes the receipt number allocation.
+func (q *Queries) GetLastReceiptNumberForUpdate(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getLastReceiptNumberForUpdate)
+	var last_receipt_number int64
+	err := row.Scan(&last_receipt_number)
+	return last_receipt_number, err
+}
+
+const getLatestClosedPeriodClosedUntil = `-- name: GetLatestClosedPeriodClosedUntil :one
+SELECT closed_until FROM closed_period
+ORDER BY closed_until DESC
+LIMIT 1
+`
+
+// GetLatestClosedPeriodClosedUntil returns the end date of the closed accounting period, i.e. the latest `closed_until`.
+func (q *Queries) GetLatestClosedPeriodClosedUntil(ctx context.Context) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getLatestClosedPeriodClosedUntil)
+	var closed_until time.Time
+	err := row.Scan(&closed_until)
+	return closed_until, err
+}
+
+const getLatestEnrollmentPaymentDateByStudentEnrollmentId = `-- name: GetLatestEnrollmentPaymentDateByStudentEnrollmentId :one
+SELECT MAX(payment_date) AS last_payment_date
+FROM enrollment_payment
+WHERE enrollment_id = ?
+GROUP BY enrollment_id LIMIT 1
+`
+
+func (q *Queries) GetLatestEnrollmentPaymentDateByStudentEnrollmentId(ctx context.Context, enrollmentID sql.NullInt64) (interface{}, error) {
+	row := q.db.QueryRowContext(ctx, getLatestEnrollmentPaymentDateByStudentEnrollmentId, enrollmentID)
+	var last_payment_date interface{}
+	err := row.Scan(&last_payment_date)
+	return last_payment_date, err
+}
+
+const getLatestPaymentReminderSentAtByEnrollmentId = `-- name: GetLatestPaymentReminderSentAtByEnrollmentId :one
+SELECT sent_at FROM payment_reminder
+WHERE enrollment_id = ?
+ORDER BY sent_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestPaymentReminderSentAtByEnrollmentId(ctx context.Context, enrollmentID int64) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getLatestPaymentReminderSentAtByEnrollmentId, enrollmentID)
+	var sent_at time.Time
+	err := row.Scan(&sent_at)
+	return sent_at, err
+}
+
+const getLatestSLTId = `-- name: GetLatestSLTId :one
+SELECT CAST(COALESCE(MAX(id), 0) AS SIGNED) AS latest_id FROM student_learning_token
+`
+
+func (q *Queries) GetLatestSLTId(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getLatestSLTId)
+	var latest_id int64
+	err := row.Scan(&latest_id)
+	return latest_id, err
+}
+
+const getLatestSLTTransactionId = `-- name: GetLatestSLTTransactionId :one
+SELECT CAST(COALESCE(MAX(id), 0) AS SIGNED) AS latest_id FROM slt_transaction
+`
+
+// ============================== SLT_DRY_RUN ==============================
+func (q *Queries) GetLatestSLTTransactionId(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getLatestSLTTransactionId)
+	var latest_id int64
+	err := row.Scan(&latest_id)
+	return latest_id, err
+}
+
+const getOverdueInstallments = `-- name: GetOverdueInstallments :many
+SELECT installment.id, installment.installment_number, installment.due_date, installment.balance_top_up, installment.balance_bonus, installment.course_fee_value, installment.transport_fee_value, installment.penalty_fee_value, installment.discount_fee_value, installment.installment_plan_id, installment.enrollment_payment_id, installment_plan.enrollment_id, se.student_id, user.username AS student_username, user.user_detail AS student_detail
+FROM installment
+    JOIN installment_plan ON installment.installment_plan_id = installment_plan.id
+    JOIN student_enrollment AS se ON installment_plan.enrollment_id = se.id
+    JOIN student ON se.student_id = student.id
+    JOIN user ON student.user_id = user.id
+WHERE installment.enrollment_payment_id IS NULL AND installment.due_date < ?
+ORDER BY installment.due_date, installment.id
+LIMIT ? OFFSET ?
+`
+
+type GetOverdueInstallmentsParams struct {
+	Date   time.Time
+	Limit  int32
+	Offset int32
+}
+
+type GetOverdueInstallmentsRow struct {
+	ID                  int64
+	InstallmentNumber   int32
+	DueDate             time.Time
+	BalanceTopUp        int32
+	BalanceBonus        int32
+	CourseFeeValue      int32
+	TransportFeeValue   int32
+	PenaltyFeeValue     int32
+	DiscountFeeValue    int32
+	InstallmentPlanID   int64
+	EnrollmentPaymentID sql.NullInt64
+	EnrollmentID        int64
+	StudentID           int64
+	StudentUsername     string
+	StudentDetail       json.RawMessage
+}
+
+func (q *Queries) GetOverdueInstallments(ctx context.Context, arg GetOverdueInstallmentsParams) ([]GetOverdueInstallmentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOverdueInstallments, arg.Date, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOverdueInstallmentsRow
+	for rows.Next() {
+		var i GetOverdueInstallmentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.InstallmentNumber,
+			&i.DueDate,
+			&i.BalanceTopUp,
+			&i.BalanceBonus,
+			&i.CourseFeeValue,
+			&i.TransportFeeValue,
+			&i.PenaltyFeeValue,
+			&i.DiscountFeeValue,
+			&i.InstallmentPlanID,
+			&i.EnrollmentPaymentID,
+			&i.EnrollmentID,
+			&i.StudentID,
+			&i.StudentUsername,
+			&i.StudentDetail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPaymentReminderOptOuts = `-- name: GetPaymentReminderOptOuts :many
+SELECT opt_out.student_id, user.username AS student_username, user.user_detail AS student_detail, opt_out.opted_out_at, opt_out.opted_out_by_user_id
+FROM payment_reminder_opt_out AS opt_out
+    JOIN student ON opt_out.student_id = student.id
+    JOIN user ON student.user_id = user.id
+ORDER BY opt_out.student_id
+`
+
+type GetPaymentReminderOptOutsRow struct {
+	StudentID        int64
+	StudentUsername  string
+	StudentDetail    json.RawMessage
+	OptedOutAt       time.Time
+	OptedOutByUserID sql.NullInt64
+}
+
+func (q *Queries) GetPaymentReminderOptOuts(ctx context.Context) ([]GetPaymentReminderOptOutsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPaymentReminderOptOuts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPaymentReminderOptOutsRow
+	for rows.Next() {
+		var i GetPaymentReminderOptOutsRow
+		if err := rows.Scan(
+			&i.StudentID,
+			&i.StudentUsername,
+			&i.StudentDetail,
+			&i.OptedOutAt,
+			&i.OptedOutByUserID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPaymentReminders = `-- name: GetPaymentReminders :many
+SELECT pr.id, pr.reminder_type, pr.recipient_email, pr.owed_quota, pr.course_fee_value, pr.transport_fee_value, pr.penalty_fee_value, pr.discount_fee_value, pr.sent_at, pr.enrollment_id,
+    se.student_id, user.username AS student_username, user.user_detail AS student_detail
+FROM payment_reminder AS pr
+    JOIN student_enrollment AS se ON pr.enrollment_id = se.id
+    JOIN student ON se.student_id = student.id
+    JOIN user ON student.user_id = user.id
+ORDER BY pr.id DESC
+LIMIT ? OFFSET ?
+`
+
+type GetPaymentRemindersParams struct {
+	Limit  int32
+	Offset int32
+}
+
+type GetPaymentRemindersRow struct {
+	ID                int64
+	ReminderType      string
+	RecipientEmail    string
+	OwedQuota         float64
+	CourseFeeValue    int32
+	TransportFeeValue int32
+	PenaltyFeeValue   int32
+	DiscountFeeValue  int32
+	SentAt            time.Time
+	EnrollmentID      int64
+	StudentID         int64
+	StudentUsername   string
+	StudentDetail     json.RawMessage
+}
+
+func (q *Queries) GetPaymentReminders(ctx context.Context, arg GetPaymentRemindersParams) ([]GetPaymentRemindersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPaymentReminders, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPaymentRemindersRow
+	for rows.Next() {
+		var i GetPaymentRemindersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ReminderType,
+			&i.RecipientEmail,
+			&i.OwedQuota,
+			&i.CourseFeeValue,
+			&i.TransportFeeValue,
+			&i.PenaltyFeeValue,
+			&i.DiscountFeeValue,
+			&i.SentAt,
+			&i.EnrollmentID,
+			&i.StudentID,
+			&i.StudentUsername,
+			&i.StudentDetail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPayrollRunById = `-- name: GetPayrollRunById :one
+SELECT pr.id, pr.start_date, pr.end_date, pr.status, pr.note, pr.created_at, pr.approved_at, pr.paid_at,
+    pr.created_by_user_id, user_creator.username AS created_by_username,
+    pr.approved_by_user_id, user_approver.username AS approved_by_username
+FROM payroll_run AS pr
+    LEFT JOIN user AS user_creator ON pr.created_by_user_id = user_creator.id
+    LEFT JOIN user AS user_approver ON pr.approved_by_user_id = user_approver.id
+WHERE pr.id = ? LIMIT 1
+`
+
+type GetPayrollRunByIdRow struct {
+	ID                 int64
+	StartDate          time.Time
+	EndDate            time.Time
+	Status             string
+	Note               string
+	CreatedAt          time.Time
+	ApprovedAt         sql.NullTime
+	PaidAt             sql.NullTime
+	CreatedByUserID    sql.NullInt64
+	CreatedByUsername  sql.NullString
+	ApprovedByUserID   sql.NullInt64
+	ApprovedByUsername sql.NullString
+}
+
+func (q *Queries) GetPayrollRunById(ctx context.Context, id int64) (GetPayrollRunByIdRow, error) {
+	row := q.db.QueryRowContext(ctx, getPayrollRunById, id)
+	var i GetPayrollRunByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Status,
+		&i.Note,
+		&i.CreatedAt,
+		&i.ApprovedAt,
+		&i.PaidAt,
+		&i.CreatedByUserID,
+		&i.CreatedByUsername,
+		&i.ApprovedByUserID,
+		&i.ApprovedByUsername,
+	)
+	return i, err
+}
+
+const getPayrollRunStatusByIdForUpdate = `-- name: GetPayrollRunStatusByIdForUpdate :one
+SELECT status FROM payroll_run
+WHERE id = ? LIMIT 1
+FOR UPDATE
+`
+
+// GetPayrollRunStatusByIdForUpdate locks the payroll_run row until the end of the transaction, to serialize status transitions & teacher_payment submissions.
+func (q *Queries) GetPayrollRunStatusByIdForUpdate(ctx context.Context, id int64) (string, error) {
+	row := q.db.QueryRowContext(ctx, getPayrollRunStatusByIdForUpdate, id)
+	var status string
+	err := row.Scan(&status)
+	return status, err
+}
+
+const getPayrollRunTeacherTotals = `-- name: GetPayrollRunTeacherTotals :many
+SELECT teacher.id AS teacher_id, user.username, user.user_detail,
+    Count(tp.id) AS total_teacher_payments,
+    CAST(COALESCE(SUM(attendance.used_student_token_quota), 0) AS DOUBLE) AS total_attendances,
+    CAST(COALESCE(SUM(tp.paid_course_fee_value), 0) AS SIGNED) AS total_paid_course_fee_value,
+    CAST(COALESCE(SUM(tp.paid_transport_fee_value), 0) AS SIGNED) AS total_paid_transport_fee_value
+FROM payroll_run_teacher AS prt
+    JOIN teacher ON prt.teacher_id = teacher.id
+    JOIN user ON teacher.user_id = user.id
+    LEFT JOIN (teacher_payment AS tp JOIN attendance ON tp.attendance_id = attendance.id)
+        ON (tp.payroll_run_id = prt.payroll_run_id AND attendance.teacher_id = prt.teacher_id)
+WHERE prt.payroll_run_id = ?
+GROUP BY teacher.id, user.username, user.user_detail
+ORDER BY user.username
+`
+
+type GetPayrollRunTeacherTotalsRow struct {
+	TeacherID                  int64
+	Username                   string
+	UserDetail                 json.RawMessage
+	TotalTeacherPayments       int64
+	TotalAttendances           float64
+	TotalPaidCourseFeeValue    int64
+	TotalPaidTransportFeeValue int64
+}
+
+// GetPayrollRunTeacherTotals sums up the teacher_payments of a payroll_run, grouped by teacher. Teachers without any teacher_payment yet are included with zero totals.
+func (q *Queries) GetPayrollRunTeacherTotals(ctx context.Context, payrollRunID int64) ([]GetPayrollRunTeacherTotalsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPayrollRunTeacherTotals, payrollRunID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPayrollRunTeacherTotalsRow
+	for rows.Next() {
+		var i GetPayrollRunTeacherTotalsRow
+		if err := rows.Scan(
+			&i.TeacherID,
+			&i.Username,
+			&i.UserDetail,
+			&i.TotalTeacherPayments,
+			&i.TotalAttendances,
+			&i.TotalPaidCourseFeeValue,
+			&i.TotalPaidTransportFeeValue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPayrollRuns = `-- name: GetPayrollRuns :many
+SELECT pr.id, pr.start_date, pr.end_date, pr.status, pr.note, pr.created_at, pr.approved_at, pr.paid_at,
+    pr.created_by_user_id, user_creator.username AS created_by_username,
+    pr.approved_by_user_id, user_approver.username AS approved_by_username
+FROM payroll_run AS pr
+    LEFT JOIN user AS user_creator ON pr.created_by_user_id = user_creator.id
+    LEFT JOIN user AS user_approver ON pr.approved_by_user_id = user_approver.id
+ORDER BY pr.id DESC
+LIMIT ? OFFSET ?
+`
+
+type GetPayrollRunsParams struct {
+	Limit  int32
+	Offset int32
+}
+
+type GetPayrollRunsRow struct {
+	ID                 int64
+	StartDate          time.Time
+	EndDate            time.Time
+	Status             string
+	Note               string
+	CreatedAt          time.Time
+	ApprovedAt         sql.NullTime
+	PaidAt             sql.NullTime
+	CreatedByUserID    sql.NullInt64
+	CreatedByUsername  sql.NullString
+	ApprovedByUserID   sql.NullInt64
+	ApprovedByUsername sql.NullString
+}
+
+// ============================== PAYROLL_RUN ==============================
+func (q *Queries) GetPayrollRuns(ctx context.Context, arg GetPayrollRunsParams) ([]GetPayrollRunsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPayrollRuns, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPayrollRunsRow
+	for rows.Next() {
+		var i GetPayrollRunsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Status,
+			&i.Note,
+			&i.CreatedAt,
+			&i.ApprovedAt,
+			&i.PaidAt,
+			&i.CreatedByUserID,
+			&i.CreatedByUsername,
+			&i.ApprovedByUserID,
+			&i.ApprovedByUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPenaltyPolicies = `-- name: GetPenaltyPolicies :many
+SELECT id, name, trigger_day_of_month, grace_days, is_flat_fee, fee_value, max_fee_value, course_id, class_id FROM penalty_policy
+ORDER BY id
+LIMIT ? OFFSET ?
+`
+
+type GetPenaltyPoliciesParams struct {
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) GetPenaltyPolicies(ctx context.Context, arg GetPenaltyPoliciesParams) ([]PenaltyPolicy, error) {
+	rows, err := q.db.QueryContext(ctx, getPenaltyPolicies, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PenaltyPolicy
+	for rows.Next() {
+		var i PenaltyPolicy
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.TriggerDayOfMonth,
+			&i.GraceDays,
+			&i.IsFlatFee,
+			&i.FeeValue,
+			&i.MaxFeeValue,
+			&i.CourseID,
+			&i.ClassID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPenaltyPoliciesByIds = `-- name: GetPenaltyPoliciesByIds :many
+SELECT id, name, trigger_day_of_month, grace_days, is_flat_fee, fee_value, max_fee_value, course_id, class_id FROM penalty_policy
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) GetPenaltyPoliciesByIds(ctx context.Context, ids []int64) ([]PenaltyPolicy, error) {
+	query := getPenaltyPoliciesByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PenaltyPolicy
+	for rows.Next() {
+		var i PenaltyPolicy
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.TriggerDayOfMonth,
+			&i.GraceDays,
+			&i.IsFlatFee,
+			&i.FeeValue,
+			&i.MaxFeeValue,
+			&i.CourseID,
+			&i.ClassID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPenaltyPolicyById = `-- name: GetPenaltyPolicyById :one
+SELECT id, name, trigger_day_of_month, grace_days, is_flat_fee, fee_value, max_fee_value, course_id, class_id FROM penalty_policy
+WHERE id = ? LIMIT 1
+`
+
+// ============================== PENALTY_POLICY ==============================
+func (q *Queries) GetPenaltyPolicyById(ctx context.Context, id int64) (PenaltyPolicy, error) {
+	row := q.db.QueryRowContext(ctx, getPenaltyPolicyById, id)
+	var i PenaltyPolicy
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.TriggerDayOfMonth,
+		&i.GraceDays,
+		&i.IsFlatFee,
+		&i.FeeValue,
+		&i.MaxFeeValue,
+		&i.CourseID,
+		&i.ClassID,
+	)
+	return i, err
+}
+
+const getPositiveSLTsForRepricing = `-- name: GetPositiveSLTsForRepricing :many
+SELECT slt.id, slt.quota, slt.course_fee_quarter_value, slt.transport_fee_quarter_value, slt.enrollment_id, class.teacher_id AS class_teacher_id, class.course_id AS class_course_id
+FROM student_learning_token AS slt
+    JOIN student_enrollment AS se ON slt.enrollment_id = se.id
+    JOIN class ON se.class_id = class.id
+WHERE slt.quota > 0 AND class.course_id = COALESCE(?, class.course_id)
+ORDER BY slt.id
+`
+
+type GetPositiveSLTsForRepricingRow struct {
+	ID                       int64
+	Quota                    float64
+	CourseFeeQuarterValue    int32
+	TransportFeeQuarterValue int32
+	EnrollmentID             int64
+	ClassTeacherID           sql.NullInt64
+	ClassCourseID            int64
+}
+
+// ============================== SLT_REPRICING ==============================
+func (q *Queries) GetPositiveSLTsForRepricing(ctx context.Context, courseID sql.NullInt64) ([]GetPositiveSLTsForRepricingRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPositiveSLTsForRepricing, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPositiveSLTsForRepricingRow
+	for rows.Next() {
+		var i GetPositiveSLTsForRepricingRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Quota,
+			&i.CourseFeeQuarterValue,
+			&i.TransportFeeQuarterValue,
+			&i.EnrollmentID,
+			&i.ClassTeacherID,
+			&i.ClassCourseID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
 
+const getSLTByClassIdForAttendanceInfo = `-- name: GetSLTByClassIdForAttendanceInfo :many
+SELECT slt.id AS student_learning_token_id, quota, course_fee_quarter_value, transport_fee_quarter_value, created_at, last_updated_at, se.student_id AS student_id
+FROM student_learning_token AS slt
     JOIN student_enrollment AS se ON slt.enrollment_id = se.id
-WHERE slt.enrollment_id IN (/*SLICE:student_enrollment_ids*/?)
+WHERE se.class_id = ?
+ORDER BY last_updated_at DESC, slt.id DESC
 `
 
-type GetEarliestAvailableSLTsByStudentEnrollmentIdsRow struct {
+type GetSLTByClassIdForAttendanceInfoRow struct {
 	StudentLearningTokenID   int64
 	Quota                    float64
 	CourseFeeQuarterValue    int32
 	TransportFeeQuarterValue int32
 	CreatedAt                time.Time
 	LastUpdatedAt            time.Time
-	EnrollmentID             int64
 	StudentID                int64
 }
 
-func (q *Queries) GetEarliestAvailableSLTsByStudentEnrollmentIds(ctx context.Context, studentEnrollmentIds []int64) ([]GetEarliestAvailableSLTsByStudentEnrollmentIdsRow, error) {
-	query := getEarliestAvailableSLTsByStudentEnrollmentIds
-	var queryParams []interface{}
-	if len(studentEnrollmentIds) > 0 {
-		for _, v := range studentEnrollmentIds {
-			queryParams = append(queryParams, v)
+func (q *Queries) GetSLTByClassIdForAttendanceInfo(ctx context.Context, classID int64) ([]GetSLTByClassIdForAttendanceInfoRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSLTByClassIdForAttendanceInfo, classID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSLTByClassIdForAttendanceInfoRow
+	for rows.Next() {
+		var i GetSLTByClassIdForAttendanceInfoRow
+		if err := rows.Scan(
+			&i.StudentLearningTokenID,
+			&i.Quota,
+			&i.CourseFeeQuarterValue,
+			&i.TransportFeeQuarterValue,
+			&i.CreatedAt,
+			&i.LastUpdatedAt,
+			&i.StudentID,
+		); err != nil {
+			return nil, err
 		}
-		query = strings.Replace(query, "/*SLICE:student_enrollment_ids*/?", strings.Repeat(",?", len(studentEnrollmentIds))[1:], 1)
-	} else {
-		query = strings.Replace(query, "/*SLICE:student_enrollment_ids*/?", "NULL", 1)
+		items = append(items, i)
 	}
-	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter = `-- name: GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter :one
+SELECT id, quota, course_fee_quarter_value, transport_fee_quarter_value, created_at, last_updated_at, enrollment_id FROM student_learning_token
+WHERE enrollment_id = ? AND course_fee_quarter_value = ? AND transport_fee_quarter_value = ?
+`
+
+type GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarterParams struct {
+	EnrollmentID             int64
+	CourseFeeQuarterValue    int32
+	TransportFeeQuarterValue int32
+}
+
+// ============================== STUDENT_LEARNING_TOKEN ==============================
+func (q *Queries) GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter(ctx context.Context, arg GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarterParams) (StudentLearningToken, error) {
+	row := q.db.QueryRowContext(ctx, getSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter, arg.EnrollmentID, arg.CourseFeeQuarterValue, arg.TransportFeeQuarterValue)
+	var i StudentLearningToken
+	err := row.Scan(
+		&i.ID,
+		&i.Quota,
+		&i.CourseFeeQuarterValue,
+		&i.TransportFeeQuarterValue,
+		&i.CreatedAt,
+		&i.LastUpdatedAt,
+		&i.EnrollmentID,
+	)
+	return i, err
+}
+
+const getSLTIdsAfterId = `-- name: GetSLTIdsAfterId :many
+SELECT id FROM student_learning_token
+WHERE id > ?
+ORDER BY id
+`
+
+func (q *Queries) GetSLTIdsAfterId(ctx context.Context, id int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getSLTIdsAfterId, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSLTTransactionsAfterId = `-- name: GetSLTTransactionsAfterId :many
+SELECT slt_transaction.id AS slt_transaction_id, quota_change, reason, source_id, user_id, user.username AS username, slt_transaction.created_at AS created_at, token_id
+FROM slt_transaction
+    LEFT JOIN user ON user_id = user.id
+WHERE slt_transaction.id > ?
+ORDER BY slt_transaction.id
+`
+
+type GetSLTTransactionsAfterIdRow struct {
+	SltTransactionID int64
+	QuotaChange      float64
+	Reason           string
+	SourceID         sql.NullInt64
+	UserID           sql.NullInt64
+	Username         sql.NullString
+	CreatedAt        time.Time
+	TokenID          int64
+}
+
+func (q *Queries) GetSLTTransactionsAfterId(ctx context.Context, id int64) ([]GetSLTTransactionsAfterIdRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSLTTransactionsAfterId, id)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetEarliestAvailableSLTsByStudentEnrollmentIdsRow
+	var items []GetSLTTransactionsAfterIdRow
 	for rows.Next() {
-		var i GetEarliestAvailableSLTsByStudentEnrollmentIdsRow
+		var i GetSLTTransactionsAfterIdRow
 		if err := rows.Scan(
-			&i.StudentLearningTokenID,
-			&i.Quota,
-			&i.CourseFeeQuarterValue,
-			&i.TransportFeeQuarterValue,
+			&i.SltTransactionID,
+			&i.QuotaChange,
+			&i.Reason,
+			&i.SourceID,
+			&i.UserID,
+			&i.Username,
 			&i.CreatedAt,
-			&i.LastUpdatedAt,
-			&i.EnrollmentID,
-			&i.StudentID,
+			&i.TokenID,
 		); err != nil {
 			return nil, err
 		}
@@ -311,192 +3290,44 @@ func (q *Queries) GetEarliestAvailableSLTsByStudentEnrollmentIds(ctx context.Con
 	return items, nil
 }
 
-const getEnrollmentPaymentById = `-- name: GetEnrollmentPaymentById :one
-SELECT ep.id AS enrollment_payment_id, payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, se.id AS student_enrollment_id,
-    se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
-    class.id, class.transport_fee, class.teacher_id, class.course_id, class.auto_owe_attendance_token, class.is_deactivated, tsf.fee AS teacher_special_fee, course.id, course.default_fee, course.default_duration_minute, course.instrument_id, course.grade_id, instrument.id, instrument.name, grade.id, grade.name,
-    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail
-FROM enrollment_payment AS ep
-    JOIN student_enrollment AS se ON ep.enrollment_id = se.id
-
-    JOIN student ON se.student_id = student.id
-    JOIN user AS user_student ON student.user_id = user_student.id
-    
-    JOIN class on se.class_id = class.id
-    JOIN course ON class.course_id = course.id
-    JOIN instrument ON course.instrument_id = instrument.id
-    JOIN grade ON course.grade_id = grade.id
-    
-    LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
-    LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
-    LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
-WHERE ep.id = ? LIMIT 1
-`
-
-type GetEnrollmentPaymentByIdRow struct {
-	EnrollmentPaymentID  int64
-	PaymentDate          time.Time
-	BalanceTopUp         int32
-	BalanceBonus         int32
-	CourseFeeValue       int32
-	TransportFeeValue    int32
-	PenaltyFeeValue      int32
-	DiscountFeeValue     int32
-	StudentEnrollmentID  int64
-	StudentID            int64
-	StudentUsername      string
-	StudentDetail        json.RawMessage
-	Class                Class
-	TeacherSpecialFee    sql.NullInt32
-	Course               Course
-	Instrument           Instrument
-	Grade                Grade
-	ClassTeacherID       sql.NullInt64
-	ClassTeacherUsername sql.NullString
-	ClassTeacherDetail   []byte
-}
-
-// ============================== ENROLLMENT_PAYMENT ==============================
-func (q *Queries) GetEnrollmentPaymentById(ctx context.Context, id int64) (GetEnrollmentPaymentByIdRow, error) {
-	row := q.db.QueryRowContext(ctx, getEnrollmentPaymentById, id)
-	var i GetEnrollmentPaymentByIdRow
-	err := row.Scan(
-		&i.EnrollmentPaymentID,
-		&i.PaymentDate,
-		&i.BalanceTopUp,
-		&i.BalanceBonus,
-		&i.CourseFeeValue,
-		&i.TransportFeeValue,
-		&i.PenaltyFeeValue,
-		&i.DiscountFeeValue,
-		&i.StudentEnrollmentID,
-		&i.StudentID,
-		&i.StudentUsername,
-		&i.StudentDetail,
-		&i.Class.ID,
-		&i.Class.TransportFee,
-		&i.Class.TeacherID,
-		&i.Class.CourseID,
-		&i.Class.AutoOweAttendanceToken,
-		&i.Class.IsDeactivated,
-		&i.TeacherSpecialFee,
-		&i.Course.ID,
-		&i.Course.DefaultFee,
-		&i.Course.DefaultDurationMinute,
-		&i.Course.InstrumentID,
-		&i.Course.GradeID,
-		&i.Instrument.ID,
-		&i.Instrument.Name,
-		&i.Grade.ID,
-		&i.Grade.Name,
-		&i.ClassTeacherID,
-		&i.ClassTeacherUsername,
-		&i.ClassTeacherDetail,
-	)
-	return i, err
-}
-
-const getEnrollmentPayments = `-- name: GetEnrollmentPayments :many
-SELECT ep.id AS enrollment_payment_id, payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, se.id AS student_enrollment_id,
-    se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
-    class.id, class.transport_fee, class.teacher_id, class.course_id, class.auto_owe_attendance_token, class.is_deactivated, tsf.fee AS teacher_special_fee, course.id, course.default_fee, course.default_duration_minute, course.instrument_id, course.grade_id, instrument.id, instrument.name, grade.id, grade.name,
-    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail
-FROM enrollment_payment AS ep
-    JOIN student_enrollment AS se ON ep.enrollment_id = se.id
-
-    JOIN student ON se.student_id = student.id
-    JOIN user AS user_student ON student.user_id = user_student.id
-    
-    JOIN class on se.class_id = class.id
-    JOIN course ON class.course_id = course.id
-    JOIN instrument ON course.instrument_id = instrument.id
-    JOIN grade ON course.grade_id = grade.id
-    
-    LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
-    LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
-    LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
-WHERE
-    ep.payment_date >= ? AND ep.payment_date <= ?
-ORDER BY ep.id
-LIMIT ? OFFSET ?
+const getSLTTransactionsByTokenId = `-- name: GetSLTTransactionsByTokenId :many
+SELECT slt_transaction.id AS slt_transaction_id, quota_change, reason, source_id, user_id, user.username AS username, slt_transaction.created_at AS created_at, token_id
+FROM slt_transaction
+    LEFT JOIN user ON user_id = user.id
+WHERE token_id = ?
+ORDER BY slt_transaction.id
 `
 
-type GetEnrollmentPaymentsParams struct {
-	StartDate time.Time
-	EndDate   time.Time
-	Limit     int32
-	Offset    int32
-}
-
-type GetEnrollmentPaymentsRow struct {
-	EnrollmentPaymentID  int64
-	PaymentDate          time.Time
-	BalanceTopUp         int32
-	BalanceBonus         int32
-	CourseFeeValue       int32
-	TransportFeeValue    int32
-	PenaltyFeeValue      int32
-	DiscountFeeValue     int32
-	StudentEnrollmentID  int64
-	StudentID            int64
-	StudentUsername      string
-	StudentDetail        json.RawMessage
-	Class                Class
-	TeacherSpecialFee    sql.NullInt32
-	Course               Course
-	Instrument           Instrument
-	Grade                Grade
-	ClassTeacherID       sql.NullInt64
-	ClassTeacherUsername sql.NullString
-	ClassTeacherDetail   []byte
+type GetSLTTransactionsByTokenIdRow struct {
+	SltTransactionID int64
+	QuotaChange      float64
+	Reason           string
+	SourceID         sql.NullInt64
+	UserID           sql.NullInt64
+	Username         sql.NullString
+	CreatedAt        time.Time
+	TokenID          int64
 }
 
-func (q *Queries) GetEnrollmentPayments(ctx context.Context, arg GetEnrollmentPaymentsParams) ([]GetEnrollmentPaymentsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getEnrollmentPayments,
-		arg.StartDate,
-		arg.EndDate,
-		arg.Limit,
-		arg.Offset,
-	)
+// ============================== SLT_TRANSACTION ==============================
+func (q *Queries) GetSLTTransactionsByTokenId(ctx context.Context, tokenID int64) ([]GetSLTTransactionsByTokenIdRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSLTTransactionsByTokenId, tokenID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetEnrollmentPaymentsRow
+	var items []GetSLTTransactionsByTokenIdRow
 	for rows.Next() {
-		var i GetEnrollmentPaymentsRow
+		var i GetSLTTransactionsByTokenIdRow
 		if err := rows.Scan(
-			&i.EnrollmentPaymentID,
-			&i.PaymentDate,
-			&i.BalanceTopUp,
-			&i.BalanceBonus,
-			&i.CourseFeeValue,
-			&i.TransportFeeValue,
-			&i.PenaltyFeeValue,
-			&i.DiscountFeeValue,
-			&i.StudentEnrollmentID,
-			&i.StudentID,
-			&i.StudentUsername,
-			&i.StudentDetail,
-			&i.Class.ID,
-			&i.Class.TransportFee,
-			&i.Class.TeacherID,
-			&i.Class.CourseID,
-			&i.Class.AutoOweAttendanceToken,
-			&i.Class.IsDeactivated,
-			&i.TeacherSpecialFee,
-			&i.Course.ID,
-			&i.Course.DefaultFee,
-			&i.Course.DefaultDurationMinute,
-			&i.Course.InstrumentID,
-			&i.Course.GradeID,
-			&i.Instrument.ID,
-			&i.Instrument.Name,
-			&i.Grade.ID,
-			&i.Grade.Name,
-			&i.ClassTeacherID,
-			&i.ClassTeacherUsername,
-			&i.ClassTeacherDetail,
+			&i.SltTransactionID,
+			&i.QuotaChange,
+			&i.Reason,
+			&i.SourceID,
+			&i.UserID,
+			&i.Username,
+			&i.CreatedAt,
+			&i.TokenID,
 		); err != nil {
 			return nil, err
 		}
@@ -511,103 +3342,38 @@ func (q *Queries) GetEnrollmentPayments(ctx context.Context, arg GetEnrollmentPa
 	return items, nil
 }
 
-const getEnrollmentPaymentsByIds = `-- name: GetEnrollmentPaymentsByIds :many
-SELECT ep.id AS enrollment_payment_id, payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, se.id AS student_enrollment_id,
-    se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
-    class.id, class.transport_fee, class.teacher_id, class.course_id, class.auto_owe_attendance_token, class.is_deactivated, tsf.fee AS teacher_special_fee, course.id, course.default_fee, course.default_duration_minute, course.instrument_id, course.grade_id, instrument.id, instrument.name, grade.id, grade.name,
-    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail
-FROM enrollment_payment AS ep
-    JOIN student_enrollment AS se ON ep.enrollment_id = se.id
-
-    JOIN student ON se.student_id = student.id
-    JOIN user AS user_student ON student.user_id = user_student.id
-    
-    JOIN class on se.class_id = class.id
-    JOIN course ON class.course_id = course.id
-    JOIN instrument ON course.instrument_id = instrument.id
-    JOIN grade ON course.grade_id = grade.id
-    
-    LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
-    LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
-    LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
-WHERE ep.id IN (/*SLICE:ids*/?)
+const getSLTTransactionsQuotaChangeGroupedByTokenIdExcludingReasons = `-- name: GetSLTTransactionsQuotaChangeGroupedByTokenIdExcludingReasons :many
+SELECT token_id, CAST(SUM(quota_change) AS DOUBLE) AS total_quota_change
+FROM slt_transaction
+WHERE reason NOT IN (/*SLICE:excluded_reasons*/?)
+GROUP BY token_id
 `
 
-type GetEnrollmentPaymentsByIdsRow struct {
-	EnrollmentPaymentID  int64
-	PaymentDate          time.Time
-	BalanceTopUp         int32
-	BalanceBonus         int32
-	CourseFeeValue       int32
-	TransportFeeValue    int32
-	PenaltyFeeValue      int32
-	DiscountFeeValue     int32
-	StudentEnrollmentID  int64
-	StudentID            int64
-	StudentUsername      string
-	StudentDetail        json.RawMessage
-	Class                Class
-	TeacherSpecialFee    sql.NullInt32
-	Course               Course
-	Instrument           Instrument
-	Grade                Grade
-	ClassTeacherID       sql.NullInt64
-	ClassTeacherUsername sql.NullString
-	ClassTeacherDetail   []byte
+type GetSLTTransactionsQuotaChangeGroupedByTokenIdExcludingReasonsRow struct {
+	TokenID          int64
+	TotalQuotaChange float64
 }
 
-func (q *Queries) GetEnrollmentPaymentsByIds(ctx context.Context, ids []int64) ([]GetEnrollmentPaymentsByIdsRow, error) {
-	query := getEnrollmentPaymentsByIds
+func (q *Queries) GetSLTTransactionsQuotaChangeGroupedByTokenIdExcludingReasons(ctx context.Context, excludedReasons []string) ([]GetSLTTransactionsQuotaChangeGroupedByTokenIdExcludingReasonsRow, error) {
+	query := getSLTTransactionsQuotaChangeGroupedByTokenIdExcludingReasons
 	var queryParams []interface{}
-	if len(ids) > 0 {
-		for _, v := range ids {
+	if len(excludedReasons) > 0 {
+		for _, v := range excludedReasons {
 			queryParams = append(queryParams, v)
 		}
-		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+		query = strings.Replace(query, "/*SLICE:excluded_reasons*/?", strings.Repeat(",?", len(excludedReasons))[1:], 1)
 	} else {
-		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+		query = strings.Replace(query, "/*SLICE:excluded_reasons*/?", "NULL", 1)
 	}
 	rows, err := q.db.QueryContext(ctx, query, queryParams...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetEnrollmentPaymentsByIdsRow
+	var items []GetSLTTransactionsQuotaChangeGroupedByTokenIdExcludingReasonsRow
 	for rows.Next() {
-		var i GetEnrollmentPaymentsByIdsRow
-		if err := rows.Scan(
-			&i.EnrollmentPaymentID,
-			&i.PaymentDate,
-			&i.BalanceTopUp,
-			&i.BalanceBonus,
-			&i.CourseFeeValue,
-			&i.TransportFeeValue,
-			&i.PenaltyFeeValue,
-			&i.DiscountFeeValue,
-			&i.StudentEnrollmentID,
-			&i.StudentID,
-			&i.StudentUsername,
-			&i.StudentDetail,
-			&i.Class.ID,
-			&i.Class.TransportFee,
-			&i.Class.TeacherID,
-			&i.Class.CourseID,
-			&i.Class.AutoOweAttendanceToken,
-			&i.Class.IsDeactivated,
-			&i.TeacherSpecialFee,
-			&i.Course.ID,
-			&i.Course.DefaultFee,
-			&i.Course.DefaultDurationMinute,
-			&i.Course.InstrumentID,
-			&i.Course.GradeID,
-			&i.Instrument.ID,
-			&i.Instrument.Name,
-			&i.Grade.ID,
-			&i.Grade.Name,
-			&i.ClassTeacherID,
-			&i.ClassTeacherUsername,
-			&i.ClassTeacherDetail,
-		); err != nil {
+		var i GetSLTTransactionsQuotaChangeGroupedByTokenIdExcludingReasonsRow
+		if err := rows.Scan(&i.TokenID, &i.TotalQuotaChange); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -621,108 +3387,86 @@ func (q *Queries) GetEnrollmentPaymentsByIds(ctx context.Context, ids []int64) (
 	return items, nil
 }
 
-const getEnrollmentPaymentsDescendingDate = `-- name: GetEnrollmentPaymentsDescendingDate :many
-SELECT ep.id AS enrollment_payment_id, payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, se.id AS student_enrollment_id,
-    se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
-    class.id, class.transport_fee, class.teacher_id, class.course_id, class.auto_owe_attendance_token, class.is_deactivated, tsf.fee AS teacher_special_fee, course.id, course.default_fee, course.default_duration_minute, course.instrument_id, course.grade_id, instrument.id, instrument.name, grade.id, grade.name,
-    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail
-FROM enrollment_payment AS ep
-    JOIN student_enrollment AS se ON ep.enrollment_id = se.id
-
-    JOIN student ON se.student_id = student.id
-    JOIN user AS user_student ON student.user_id = user_student.id
-    
-    JOIN class on se.class_id = class.id
-    JOIN course ON class.course_id = course.id
-    JOIN instrument ON course.instrument_id = instrument.id
-    JOIN grade ON course.grade_id = grade.id
-    
-    LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
-    LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
-    LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
-WHERE
-    ep.payment_date >= ? AND ep.payment_date <= ?
-ORDER BY ep.payment_date DESC, ep.id DESC
-LIMIT ? OFFSET ?
+const getSLTsForReconciliation = `-- name: GetSLTsForReconciliation :many
+SELECT id, quota, course_fee_quarter_value, transport_fee_quarter_value, created_at, last_updated_at, enrollment_id FROM student_learning_token
+ORDER BY id
 `
 
-type GetEnrollmentPaymentsDescendingDateParams struct {
-	StartDate time.Time
-	EndDate   time.Time
-	Limit     int32
-	Offset    int32
+// ============================== SLT_RECONCILIATION ==============================
+func (q *Queries) GetSLTsForReconciliation(ctx context.Context) ([]StudentLearningToken, error) {
+	rows, err := q.db.QueryContext(ctx, getSLTsForReconciliation)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StudentLearningToken
+	for rows.Next() {
+		var i StudentLearningToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.Quota,
+			&i.CourseFeeQuarterValue,
+			&i.TransportFeeQuarterValue,
+			&i.CreatedAt,
+			&i.LastUpdatedAt,
+			&i.EnrollmentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-type GetEnrollmentPaymentsDescendingDateRow struct {
-	EnrollmentPaymentID  int64
-	PaymentDate          time.Time
-	BalanceTopUp         int32
-	BalanceBonus         int32
-	CourseFeeValue       int32
-	TransportFeeValue    int32
-	PenaltyFeeValue      int32
-	DiscountFeeValue     int32
-	StudentEnrollmentID  int64
-	StudentID            int64
-	StudentUsername      string
-	StudentDetail        json.RawMessage
-	Class                Class
-	TeacherSpecialFee    sql.NullInt32
-	Course               Course
-	Instrument           Instrument
-	Grade                Grade
-	ClassTeacherID       sql.NullInt64
-	ClassTeacherUsername sql.NullString
-	ClassTeacherDetail   []byte
+const getStudentEnrollmentsForBankStatementSuggestion = `-- name: GetStudentEnrollmentsForBankStatementSuggestion :many
+SELECT se.id AS student_enrollment_id, se.student_id, user.username AS student_username, user.user_detail AS student_detail, se.class_id,
+    latest_ep.payment_date AS last_payment_date,
+    CAST(COALESCE(latest_ep.course_fee_value + latest_ep.transport_fee_value + latest_ep.penalty_fee_value - latest_ep.discount_fee_value, 0) AS SIGNED) AS last_payment_value
+FROM student_enrollment AS se
+    JOIN student ON se.student_id = student.id
+    JOIN user ON student.user_id = user.id
+    JOIN class ON se.class_id = class.id
+    LEFT JOIN enrollment_payment AS latest_ep ON latest_ep.id = (
+        SELECT MAX(id) FROM enrollment_payment WHERE enrollment_id = se.id
+    )
+WHERE se.is_deleted = 0 AND class.is_deactivated = 0
+ORDER BY se.id
+`
+
+type GetStudentEnrollmentsForBankStatementSuggestionRow struct {
+	StudentEnrollmentID int64
+	StudentID           int64
+	StudentUsername     string
+	StudentDetail       json.RawMessage
+	ClassID             int64
+	LastPaymentDate     sql.NullTime
+	LastPaymentValue    int64
 }
 
-// GetEnrollmentPaymentsDescendingDate is a copy of GetEnrollmentPayments, with additional sort by date parameter. TODO: find alternative: sqlc's dynamic query which is mature enough, so that we need to do this.
-func (q *Queries) GetEnrollmentPaymentsDescendingDate(ctx context.Context, arg GetEnrollmentPaymentsDescendingDateParams) ([]GetEnrollmentPaymentsDescendingDateRow, error) {
-	rows, err := q.db.QueryContext(ctx, getEnrollmentPaymentsDescendingDate,
-		arg.StartDate,
-		arg.EndDate,
-		arg.Limit,
-		arg.Offset,
-	)
+// GetStudentEnrollmentsForBankStatementSuggestion returns the active student_enrollments, along with their latest enrollment_payment's received money (i.e. after discount).
+func (q *Queries) GetStudentEnrollmentsForBankStatementSuggestion(ctx context.Context) ([]GetStudentEnrollmentsForBankStatementSuggestionRow, error) {
+	rows, err := q.db.QueryContext(ctx, getStudentEnrollmentsForBankStatementSuggestion)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetEnrollmentPaymentsDescendingDateRow
+	var items []GetStudentEnrollmentsForBankStatementSuggestionRow
 	for rows.Next() {
-		var i GetEnrollmentPaymentsDescendingDateRow
+		var i GetStudentEnrollmentsForBankStatementSuggestionRow
 		if err := rows.Scan(
-			&i.EnrollmentPaymentID,
-			&i.PaymentDate,
-			&i.BalanceTopUp,
-			&i.BalanceBonus,
-			&i.CourseFeeValue,
-			&i.TransportFeeValue,
-			&i.PenaltyFeeValue,
-			&i.DiscountFeeValue,
 			&i.StudentEnrollmentID,
 			&i.StudentID,
 			&i.StudentUsername,
 			&i.StudentDetail,
-			&i.Class.ID,
-			&i.Class.TransportFee,
-			&i.Class.TeacherID,
-			&i.Class.CourseID,
-			&i.Class.AutoOweAttendanceToken,
-			&i.Class.IsDeactivated,
-			&i.TeacherSpecialFee,
-			&i.Course.ID,
-			&i.Course.DefaultFee,
-			&i.Course.DefaultDurationMinute,
-			&i.Course.InstrumentID,
-			&i.Course.GradeID,
-			&i.Instrument.ID,
-			&i.Instrument.Name,
-			&i.Grade.ID,
-			&i.Grade.Name,
-			&i.ClassTeacherID,
-			&i.ClassTeacherUsername,
-			&i.ClassTeacherDetail,
+			&i.ClassID,
+			&i.LastPaymentDate,
+			&i.LastPaymentValue,
 		); err != nil {
 			return nil, err
 		}
@@ -737,56 +3481,34 @@ func (q *Queries) GetEnrollmentPaymentsDescendingDate(ctx context.Context, arg G
 	return items, nil
 }
 
-const getLatestEnrollmentPaymentDateByStudentEnrollmentId = `-- name: GetLatestEnrollmentPaymentDateByStudentEnrollmentId :one
-SELECT MAX(payment_date) AS last_payment_date
-FROM enrollment_payment
-WHERE enrollment_id = ?
-GROUP BY enrollment_id LIMIT 1
-`
-
-func (q *Queries) GetLatestEnrollmentPaymentDateByStudentEnrollmentId(ctx context.Context, enrollmentID sql.NullInt64) (interface{}, error) {
-	row := q.db.QueryRowContext(ctx, getLatestEnrollmentPaymentDateByStudentEnrollmentId, enrollmentID)
-	var last_payment_date interface{}
-	err := row.Scan(&last_payment_date)
-	return last_payment_date, err
-}
-
-const getSLTByClassIdForAttendanceInfo = `-- name: GetSLTByClassIdForAttendanceInfo :many
-SELECT slt.id AS student_learning_token_id, quota, course_fee_quarter_value, transport_fee_quarter_value, created_at, last_updated_at, se.student_id AS student_id
-FROM student_learning_token AS slt
-    JOIN student_enrollment AS se ON slt.enrollment_id = se.id
-WHERE se.class_id = ?
-ORDER BY last_updated_at DESC, slt.id DESC
+const getStudentEnrollmentsForPaymentReminder = `-- name: GetStudentEnrollmentsForPaymentReminder :many
+SELECT se.id AS student_enrollment_id, COALESCE(SUM(LEAST(slt.quota, 0)), 0) AS owed_quota
+FROM student_enrollment AS se
+    JOIN class ON se.class_id = class.id
+    LEFT JOIN student_learning_token AS slt ON slt.enrollment_id = se.id
+    LEFT JOIN payment_reminder_opt_out AS opt_out ON opt_out.student_id = se.student_id
+WHERE se.is_deleted = 0 AND class.is_deactivated = 0 AND opt_out.student_id IS NULL
+GROUP BY se.id
+ORDER BY se.id
 `
 
-type GetSLTByClassIdForAttendanceInfoRow struct {
-	StudentLearningTokenID   int64
-	Quota                    float64
-	CourseFeeQuarterValue    int32
-	TransportFeeQuarterValue int32
-	CreatedAt                time.Time
-	LastUpdatedAt            time.Time
-	StudentID                int64
+type GetStudentEnrollmentsForPaymentReminderRow struct {
+	StudentEnrollmentID int64
+	OwedQuota           float64
 }
 
-func (q *Queries) GetSLTByClassIdForAttendanceInfo(ctx context.Context, classID int64) ([]GetSLTByClassIdForAttendanceInfoRow, error) {
-	rows, err := q.db.QueryContext(ctx, getSLTByClassIdForAttendanceInfo, classID)
+// GetStudentEnrollmentsForPaymentReminder returns the active student_enrollments of the students who haven't opted out, along with their total negative quota (i.e. owed tokens).
+// ============================== PAYMENT_REMINDER ==============================
+func (q *Queries) GetStudentEnrollmentsForPaymentReminder(ctx context.Context) ([]GetStudentEnrollmentsForPaymentReminderRow, error) {
+	rows, err := q.db.QueryContext(ctx, getStudentEnrollmentsForPaymentReminder)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetSLTByClassIdForAttendanceInfoRow
+	var items []GetStudentEnrollmentsForPaymentReminderRow
 	for rows.Next() {
-		var i GetSLTByClassIdForAttendanceInfoRow
-		if err := rows.Scan(
-			&i.StudentLearningTokenID,
-			&i.Quota,
-			&i.CourseFeeQuarterValue,
-			&i.TransportFeeQuarterValue,
-			&i.CreatedAt,
-			&i.LastUpdatedAt,
-			&i.StudentID,
-		); err != nil {
+		var i GetStudentEnrollmentsForPaymentReminderRow
+		if err := rows.Scan(&i.StudentEnrollmentID, &i.OwedQuota); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -800,33 +3522,6 @@ func (q *Queries) GetSLTByClassIdForAttendanceInfo(ctx context.Context, classID
 	return items, nil
 }
 
-const getSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter = `-- name: GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter :one
-SELECT id, quota, course_fee_quarter_value, transport_fee_quarter_value, created_at, last_updated_at, enrollment_id FROM student_learning_token
-WHERE enrollment_id = ? AND course_fee_quarter_value = ? AND transport_fee_quarter_value = ?
-`
-
-type GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarterParams struct {
-	EnrollmentID             int64
-	CourseFeeQuarterValue    int32
-	TransportFeeQuarterValue int32
-}
-
-// ============================== STUDENT_LEARNING_TOKEN ==============================
-func (q *Queries) GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter(ctx context.Context, arg GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarterParams) (StudentLearningToken, error) {
-	row := q.db.QueryRowContext(ctx, getSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter, arg.EnrollmentID, arg.CourseFeeQuarterValue, arg.TransportFeeQuarterValue)
-	var i StudentLearningToken
-	err := row.Scan(
-		&i.ID,
-		&i.Quota,
-		&i.CourseFeeQuarterValue,
-		&i.TransportFeeQuarterValue,
-		&i.CreatedAt,
-		&i.LastUpdatedAt,
-		&i.EnrollmentID,
-	)
-	return i, err
-}
-
 const getStudentLearningTokenById = `-- name: GetStudentLearningTokenById :one
 SELECT slt.id AS student_learning_token_id, quota, course_fee_quarter_value, transport_fee_quarter_value, slt.created_at, last_updated_at, slt.enrollment_id AS student_enrollment_id,
     se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
@@ -1144,8 +3839,219 @@ type GetStudentLearningTokensByIdsRow struct {
 	ClassTeacherDetail       []byte
 }
 
-func (q *Queries) GetStudentLearningTokensByIds(ctx context.Context, ids []int64) ([]GetStudentLearningTokensByIdsRow, error) {
-	query := getStudentLearningTokensByIds
+func (q *Queries) GetStudentLearningTokensByIds(ctx context.Context, ids []int64) ([]GetStudentLearningTokensByIdsRow, error) {
+	query := getStudentLearningTokensByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetStudentLearningTokensByIdsRow
+	for rows.Next() {
+		var i GetStudentLearningTokensByIdsRow
+		if err := rows.Scan(
+			&i.StudentLearningTokenID,
+			&i.Quota,
+			&i.CourseFeeQuarterValue,
+			&i.TransportFeeQuarterValue,
+			&i.CreatedAt,
+			&i.LastUpdatedAt,
+			&i.StudentEnrollmentID,
+			&i.StudentID,
+			&i.StudentUsername,
+			&i.StudentDetail,
+			&i.Class.ID,
+			&i.Class.TransportFee,
+			&i.Class.TeacherID,
+			&i.Class.CourseID,
+			&i.Class.AutoOweAttendanceToken,
+			&i.Class.IsDeactivated,
+			&i.TeacherSpecialFee,
+			&i.Course.ID,
+			&i.Course.DefaultFee,
+			&i.Course.DefaultDurationMinute,
+			&i.Course.InstrumentID,
+			&i.Course.GradeID,
+			&i.Instrument.ID,
+			&i.Instrument.Name,
+			&i.Grade.ID,
+			&i.Grade.Name,
+			&i.ClassTeacherID,
+			&i.ClassTeacherUsername,
+			&i.ClassTeacherDetail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTeacherFeeSharingById = `-- name: GetTeacherFeeSharingById :one
+SELECT id, course_fee_sharing_percentage, transport_fee_sharing_percentage, effective_from, teacher_id, course_id FROM teacher_fee_sharing
+WHERE id = ? LIMIT 1
+`
+
+// ============================== TEACHER_FEE_SHARING ==============================
+func (q *Queries) GetTeacherFeeSharingById(ctx context.Context, id int64) (TeacherFeeSharing, error) {
+	row := q.db.QueryRowContext(ctx, getTeacherFeeSharingById, id)
+	var i TeacherFeeSharing
+	err := row.Scan(
+		&i.ID,
+		&i.CourseFeeSharingPercentage,
+		&i.TransportFeeSharingPercentage,
+		&i.EffectiveFrom,
+		&i.TeacherID,
+		&i.CourseID,
+	)
+	return i, err
+}
+
+const getTeacherFeeSharings = `-- name: GetTeacherFeeSharings :many
+SELECT id, course_fee_sharing_percentage, transport_fee_sharing_percentage, effective_from, teacher_id, course_id FROM teacher_fee_sharing
+ORDER BY teacher_id, course_id, effective_from DESC, id DESC
+LIMIT ? OFFSET ?
+`
+
+type GetTeacherFeeSharingsParams struct {
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) GetTeacherFeeSharings(ctx context.Context, arg GetTeacherFeeSharingsParams) ([]TeacherFeeSharing, error) {
+	rows, err := q.db.QueryContext(ctx, getTeacherFeeSharings, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TeacherFeeSharing
+	for rows.Next() {
+		var i TeacherFeeSharing
+		if err := rows.Scan(
+			&i.ID,
+			&i.CourseFeeSharingPercentage,
+			&i.TransportFeeSharingPercentage,
+			&i.EffectiveFrom,
+			&i.TeacherID,
+			&i.CourseID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTeacherFeeSharingsByIds = `-- name: GetTeacherFeeSharingsByIds :many
+SELECT id, course_fee_sharing_percentage, transport_fee_sharing_percentage, effective_from, teacher_id, course_id FROM teacher_fee_sharing
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) GetTeacherFeeSharingsByIds(ctx context.Context, ids []int64) ([]TeacherFeeSharing, error) {
+	query := getTeacherFeeSharingsByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TeacherFeeSharing
+	for rows.Next() {
+		var i TeacherFeeSharing
+		if err := rows.Scan(
+			&i.ID,
+			&i.CourseFeeSharingPercentage,
+			&i.TransportFeeSharingPercentage,
+			&i.EffectiveFrom,
+			&i.TeacherID,
+			&i.CourseID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTeacherFeeSharingsByTeacherId = `-- name: GetTeacherFeeSharingsByTeacherId :many
+SELECT id, course_fee_sharing_percentage, transport_fee_sharing_percentage, effective_from, teacher_id, course_id FROM teacher_fee_sharing
+WHERE teacher_id = ? OR teacher_id IS NULL
+ORDER BY effective_from DESC, id DESC
+`
+
+// GetTeacherFeeSharingsByTeacherId returns all teacher_fee_sharings which may apply to the teacher's attendances, i.e. the teacher's ones, along with the course & school-wide ones.
+func (q *Queries) GetTeacherFeeSharingsByTeacherId(ctx context.Context, teacherID sql.NullInt64) ([]TeacherFeeSharing, error) {
+	rows, err := q.db.QueryContext(ctx, getTeacherFeeSharingsByTeacherId, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TeacherFeeSharing
+	for rows.Next() {
+		var i TeacherFeeSharing
+		if err := rows.Scan(
+			&i.ID,
+			&i.CourseFeeSharingPercentage,
+			&i.TransportFeeSharingPercentage,
+			&i.EffectiveFrom,
+			&i.TeacherID,
+			&i.CourseID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTeacherPaymentAddedAtsByIds = `-- name: GetTeacherPaymentAddedAtsByIds :many
+SELECT added_at FROM teacher_payment
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) GetTeacherPaymentAddedAtsByIds(ctx context.Context, ids []int64) ([]time.Time, error) {
+	query := getTeacherPaymentAddedAtsByIds
 	var queryParams []interface{}
 	if len(ids) > 0 {
 		for _, v := range ids {
@@ -1160,43 +4066,13 @@ func (q *Queries) GetStudentLearningTokensByIds(ctx context.Context, ids []int64
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetStudentLearningTokensByIdsRow
+	var items []time.Time
 	for rows.Next() {
-		var i GetStudentLearningTokensByIdsRow
-		if err := rows.Scan(
-			&i.StudentLearningTokenID,
-			&i.Quota,
-			&i.CourseFeeQuarterValue,
-			&i.TransportFeeQuarterValue,
-			&i.CreatedAt,
-			&i.LastUpdatedAt,
-			&i.StudentEnrollmentID,
-			&i.StudentID,
-			&i.StudentUsername,
-			&i.StudentDetail,
-			&i.Class.ID,
-			&i.Class.TransportFee,
-			&i.Class.TeacherID,
-			&i.Class.CourseID,
-			&i.Class.AutoOweAttendanceToken,
-			&i.Class.IsDeactivated,
-			&i.TeacherSpecialFee,
-			&i.Course.ID,
-			&i.Course.DefaultFee,
-			&i.Course.DefaultDurationMinute,
-			&i.Course.InstrumentID,
-			&i.Course.GradeID,
-			&i.Instrument.ID,
-			&i.Instrument.Name,
-			&i.Grade.ID,
-			&i.Grade.Name,
-			&i.ClassTeacherID,
-			&i.ClassTeacherUsername,
-			&i.ClassTeacherDetail,
-		); err != nil {
+		var added_at time.Time
+		if err := rows.Scan(&added_at); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, added_at)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -1248,7 +4124,8 @@ func (q *Queries) GetTeacherPaymentAttendanceIdsByIds(ctx context.Context, teach
 
 const getTeacherPaymentById = `-- name: GetTeacherPaymentById :one
 SELECT tp.id AS teacher_payment_id, paid_course_fee_value, paid_transport_fee_value, added_at,
-    attendance.id, attendance.date, attendance.used_student_token_quota, attendance.duration, attendance.note, attendance.is_paid, attendance.class_id, attendance.teacher_id, attendance.student_id, attendance.token_id,
+    tp.snapshot_student_name, tp.snapshot_class_description, tp.snapshot_course_name, tp.snapshot_instrument_name, tp.snapshot_grade_name, tp.snapshot_teacher_name,
+    attendance.id, attendance.date, attendance.used_student_token_quota, attendance.duration, attendance.note, attendance.is_paid, attendance.class_id, attendance.teacher_id, attendance.student_id, attendance.token_id, attendance.is_substitute, attendance.original_teacher_id,
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
     class.id, class.transport_fee, class.teacher_id, class.course_id, class.auto_owe_attendance_token, class.is_deactivated, tsf.fee AS teacher_special_fee, course.id, course.default_fee, course.default_duration_minute, course.instrument_id, course.grade_id, instrument.id, instrument.name, grade.id, grade.name,
@@ -1275,26 +4152,32 @@ WHERE tp.id = ? LIMIT 1
 `
 
 type GetTeacherPaymentByIdRow struct {
-	TeacherPaymentID      int64
-	PaidCourseFeeValue    int32
-	PaidTransportFeeValue int32
-	AddedAt               time.Time
-	Attendance            Attendance
-	TeacherID             int64
-	TeacherUsername       sql.NullString
-	TeacherDetail         []byte
-	StudentID             int64
-	StudentUsername       sql.NullString
-	StudentDetail         []byte
-	Class                 Class
-	TeacherSpecialFee     sql.NullInt32
-	Course                Course
-	Instrument            Instrument
-	Grade                 Grade
-	ClassTeacherID        sql.NullInt64
-	ClassTeacherUsername  sql.NullString
-	ClassTeacherDetail    []byte
-	StudentLearningToken  StudentLearningToken
+	TeacherPaymentID         int64
+	PaidCourseFeeValue       int32
+	PaidTransportFeeValue    int32
+	AddedAt                  time.Time
+	SnapshotStudentName      string
+	SnapshotClassDescription string
+	SnapshotCourseName       string
+	SnapshotInstrumentName   string
+	SnapshotGradeName        string
+	SnapshotTeacherName      string
+	Attendance               Attendance
+	TeacherID                int64
+	TeacherUsername          sql.NullString
+	TeacherDetail            []byte
+	StudentID                int64
+	StudentUsername          sql.NullString
+	StudentDetail            []byte
+	Class                    Class
+	TeacherSpecialFee        sql.NullInt32
+	Course                   Course
+	Instrument               Instrument
+	Grade                    Grade
+	ClassTeacherID           sql.NullInt64
+	ClassTeacherUsername     sql.NullString
+	ClassTeacherDetail       []byte
+	StudentLearningToken     StudentLearningToken
 }
 
 func (q *Queries) GetTeacherPaymentById(ctx context.Context, id int64) (GetTeacherPaymentByIdRow, error) {
@@ -1305,6 +4188,12 @@ func (q *Queries) GetTeacherPaymentById(ctx context.Context, id int64) (GetTeach
 		&i.PaidCourseFeeValue,
 		&i.PaidTransportFeeValue,
 		&i.AddedAt,
+		&i.SnapshotStudentName,
+		&i.SnapshotClassDescription,
+		&i.SnapshotCourseName,
+		&i.SnapshotInstrumentName,
+		&i.SnapshotGradeName,
+		&i.SnapshotTeacherName,
 		&i.Attendance.ID,
 		&i.Attendance.Date,
 		&i.Attendance.UsedStudentTokenQuota,
@@ -1315,6 +4204,8 @@ func (q *Queries) GetTeacherPaymentById(ctx context.Context, id int64) (GetTeach
 		&i.Attendance.TeacherID,
 		&i.Attendance.StudentID,
 		&i.Attendance.TokenID,
+		&i.Attendance.IsSubstitute,
+		&i.Attendance.OriginalTeacherID,
 		&i.TeacherID,
 		&i.TeacherUsername,
 		&i.TeacherDetail,
@@ -1353,7 +4244,8 @@ func (q *Queries) GetTeacherPaymentById(ctx context.Context, id int64) (GetTeach
 
 const getTeacherPayments = `-- name: GetTeacherPayments :many
 SELECT tp.id AS teacher_payment_id, paid_course_fee_value, paid_transport_fee_value, added_at,
-    attendance.id, attendance.date, attendance.used_student_token_quota, attendance.duration, attendance.note, attendance.is_paid, attendance.class_id, attendance.teacher_id, attendance.student_id, attendance.token_id,
+    tp.snapshot_student_name, tp.snapshot_class_description, tp.snapshot_course_name, tp.snapshot_instrument_name, tp.snapshot_grade_name, tp.snapshot_teacher_name,
+    attendance.id, attendance.date, attendance.used_student_token_quota, attendance.duration, attendance.note, attendance.is_paid, attendance.class_id, attendance.teacher_id, attendance.student_id, attendance.token_id, attendance.is_substitute, attendance.original_teacher_id,
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
     class.id, class.transport_fee, class.teacher_id, class.course_id, class.auto_owe_attendance_token, class.is_deactivated, tsf.fee AS teacher_special_fee, course.id, course.default_fee, course.default_duration_minute, course.instrument_id, course.grade_id, instrument.id, instrument.name, grade.id, grade.name,
@@ -1393,26 +4285,32 @@ type GetTeacherPaymentsParams struct {
 }
 
 type GetTeacherPaymentsRow struct {
-	TeacherPaymentID      int64
-	PaidCourseFeeValue    int32
-	PaidTransportFeeValue int32
-	AddedAt               time.Time
-	Attendance            Attendance
-	TeacherID             int64
-	TeacherUsername       sql.NullString
-	TeacherDetail         []byte
-	StudentID             int64
-	StudentUsername       sql.NullString
-	StudentDetail         []byte
-	Class                 Class
-	TeacherSpecialFee     sql.NullInt32
-	Course                Course
-	Instrument            Instrument
-	Grade                 Grade
-	ClassTeacherID        sql.NullInt64
-	ClassTeacherUsername  sql.NullString
-	ClassTeacherDetail    []byte
-	StudentLearningToken  StudentLearningToken
+	TeacherPaymentID         int64
+	PaidCourseFeeValue       int32
+	PaidTransportFeeValue    int32
+	AddedAt                  time.Time
+	SnapshotStudentName      string
+	SnapshotClassDescription string
+	SnapshotCourseName       string
+	SnapshotInstrumentName   string
+	SnapshotGradeName        string
+	SnapshotTeacherName      string
+	Attendance               Attendance
+	TeacherID                int64
+	TeacherUsername          sql.NullString
+	TeacherDetail            []byte
+	StudentID                int64
+	StudentUsername          sql.NullString
+	StudentDetail            []byte
+	Class                    Class
+	TeacherSpecialFee        sql.NullInt32
+	Course                   Course
+	Instrument               Instrument
+	Grade                    Grade
+	ClassTeacherID           sql.NullInt64
+	ClassTeacherUsername     sql.NullString
+	ClassTeacherDetail       []byte
+	StudentLearningToken     StudentLearningToken
 }
 
 func (q *Queries) GetTeacherPayments(ctx context.Context, arg GetTeacherPaymentsParams) ([]GetTeacherPaymentsRow, error) {
@@ -1436,6 +4334,12 @@ func (q *Queries) GetTeacherPayments(ctx context.Context, arg GetTeacherPayments
 			&i.PaidCourseFeeValue,
 			&i.PaidTransportFeeValue,
 			&i.AddedAt,
+			&i.SnapshotStudentName,
+			&i.SnapshotClassDescription,
+			&i.SnapshotCourseName,
+			&i.SnapshotInstrumentName,
+			&i.SnapshotGradeName,
+			&i.SnapshotTeacherName,
 			&i.Attendance.ID,
 			&i.Attendance.Date,
 			&i.Attendance.UsedStudentTokenQuota,
@@ -1446,6 +4350,8 @@ func (q *Queries) GetTeacherPayments(ctx context.Context, arg GetTeacherPayments
 			&i.Attendance.TeacherID,
 			&i.Attendance.StudentID,
 			&i.Attendance.TokenID,
+			&i.Attendance.IsSubstitute,
+			&i.Attendance.OriginalTeacherID,
 			&i.TeacherID,
 			&i.TeacherUsername,
 			&i.TeacherDetail,
@@ -1494,7 +4400,8 @@ func (q *Queries) GetTeacherPayments(ctx context.Context, arg GetTeacherPayments
 
 const getTeacherPaymentsByIds = `-- name: GetTeacherPaymentsByIds :many
 SELECT tp.id AS teacher_payment_id, paid_course_fee_value, paid_transport_fee_value, added_at,
-    attendance.id, attendance.date, attendance.used_student_token_quota, attendance.duration, attendance.note, attendance.is_paid, attendance.class_id, attendance.teacher_id, attendance.student_id, attendance.token_id,
+    tp.snapshot_student_name, tp.snapshot_class_description, tp.snapshot_course_name, tp.snapshot_instrument_name, tp.snapshot_grade_name, tp.snapshot_teacher_name,
+    attendance.id, attendance.date, attendance.used_student_token_quota, attendance.duration, attendance.note, attendance.is_paid, attendance.class_id, attendance.teacher_id, attendance.student_id, attendance.token_id, attendance.is_substitute, attendance.original_teacher_id,
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
     class.id, class.transport_fee, class.teacher_id, class.course_id, class.auto_owe_attendance_token, class.is_deactivated, tsf.fee AS teacher_special_fee, course.id, course.default_fee, course.default_duration_minute, course.instrument_id, course.grade_id, instrument.id, instrument.name, grade.id, grade.name,
@@ -1521,26 +4428,32 @@ WHERE tp.id IN (/*SLICE:ids*/?)
 `
 
 type GetTeacherPaymentsByIdsRow struct {
-	TeacherPaymentID      int64
-	PaidCourseFeeValue    int32
-	PaidTransportFeeValue int32
-	AddedAt               time.Time
-	Attendance            Attendance
-	TeacherID             int64
-	TeacherUsername       sql.NullString
-	TeacherDetail         []byte
-	StudentID             int64
-	StudentUsername       sql.NullString
-	StudentDetail         []byte
-	Class                 Class
-	TeacherSpecialFee     sql.NullInt32
-	Course                Course
-	Instrument            Instrument
-	Grade                 Grade
-	ClassTeacherID        sql.NullInt64
-	ClassTeacherUsername  sql.NullString
-	ClassTeacherDetail    []byte
-	StudentLearningToken  StudentLearningToken
+	TeacherPaymentID         int64
+	PaidCourseFeeValue       int32
+	PaidTransportFeeValue    int32
+	AddedAt                  time.Time
+	SnapshotStudentName      string
+	SnapshotClassDescription string
+	SnapshotCourseName       string
+	SnapshotInstrumentName   string
+	SnapshotGradeName        string
+	SnapshotTeacherName      string
+	Attendance               Attendance
+	TeacherID                int64
+	TeacherUsername          sql.NullString
+	TeacherDetail            []byte
+	StudentID                int64
+	StudentUsername          sql.NullString
+	StudentDetail            []byte
+	Class                    Class
+	TeacherSpecialFee        sql.NullInt32
+	Course                   Course
+	Instrument               Instrument
+	Grade                    Grade
+	ClassTeacherID           sql.NullInt64
+	ClassTeacherUsername     sql.NullString
+	ClassTeacherDetail       []byte
+	StudentLearningToken     StudentLearningToken
 }
 
 func (q *Queries) GetTeacherPaymentsByIds(ctx context.Context, ids []int64) ([]GetTeacherPaymentsByIdsRow, error) {
@@ -1567,6 +4480,12 @@ func (q *Queries) GetTeacherPaymentsByIds(ctx context.Context, ids []int64) ([]G
 			&i.PaidCourseFeeValue,
 			&i.PaidTransportFeeValue,
 			&i.AddedAt,
+			&i.SnapshotStudentName,
+			&i.SnapshotClassDescription,
+			&i.SnapshotCourseName,
+			&i.SnapshotInstrumentName,
+			&i.SnapshotGradeName,
+			&i.SnapshotTeacherName,
 			&i.Attendance.ID,
 			&i.Attendance.Date,
 			&i.Attendance.UsedStudentTokenQuota,
@@ -1577,6 +4496,8 @@ func (q *Queries) GetTeacherPaymentsByIds(ctx context.Context, ids []int64) ([]G
 			&i.Attendance.TeacherID,
 			&i.Attendance.StudentID,
 			&i.Attendance.TokenID,
+			&i.Attendance.IsSubstitute,
+			&i.Attendance.OriginalTeacherID,
 			&i.TeacherID,
 			&i.TeacherUsername,
 			&i.TeacherDetail,
@@ -1625,7 +4546,8 @@ func (q *Queries) GetTeacherPaymentsByIds(ctx context.Context, ids []int64) ([]G
 
 const getTeacherPaymentsByTeacherId = `-- name: GetTeacherPaymentsByTeacherId :many
 SELECT tp.id AS teacher_payment_id, paid_course_fee_value, paid_transport_fee_value, added_at,
-    attendance.id, attendance.date, attendance.used_student_token_quota, attendance.duration, attendance.note, attendance.is_paid, attendance.class_id, attendance.teacher_id, attendance.student_id, attendance.token_id,
+    tp.snapshot_student_name, tp.snapshot_class_description, tp.snapshot_course_name, tp.snapshot_instrument_name, tp.snapshot_grade_name, tp.snapshot_teacher_name,
+    attendance.id, attendance.date, attendance.used_student_token_quota, attendance.duration, attendance.note, attendance.is_paid, attendance.class_id, attendance.teacher_id, attendance.student_id, attendance.token_id, attendance.is_substitute, attendance.original_teacher_id,
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
     class.id, class.transport_fee, class.teacher_id, class.course_id, class.auto_owe_attendance_token, class.is_deactivated, tsf.fee AS teacher_special_fee, course.id, course.default_fee, course.default_duration_minute, course.instrument_id, course.grade_id, instrument.id, instrument.name, grade.id, grade.name,
@@ -1661,26 +4583,32 @@ type GetTeacherPaymentsByTeacherIdParams struct {
 }
 
 type GetTeacherPaymentsByTeacherIdRow struct {
-	TeacherPaymentID      int64
-	PaidCourseFeeValue    int32
-	PaidTransportFeeValue int32
-	AddedAt               time.Time
-	Attendance            Attendance
-	TeacherID             int64
-	TeacherUsername       sql.NullString
-	TeacherDetail         []byte
-	StudentID             int64
-	StudentUsername       sql.NullString
-	StudentDetail         []byte
-	Class                 Class
-	TeacherSpecialFee     sql.NullInt32
-	Course                Course
-	Instrument            Instrument
-	Grade                 Grade
-	ClassTeacherID        sql.NullInt64
-	ClassTeacherUsername  sql.NullString
-	ClassTeacherDetail    []byte
-	StudentLearningToken  StudentLearningToken
+	TeacherPaymentID         int64
+	PaidCourseFeeValue       int32
+	PaidTransportFeeValue    int32
+	AddedAt                  time.Time
+	SnapshotStudentName      string
+	SnapshotClassDescription string
+	SnapshotCourseName       string
+	SnapshotInstrumentName   string
+	SnapshotGradeName        string
+	SnapshotTeacherName      string
+	Attendance               Attendance
+	TeacherID                int64
+	TeacherUsername          sql.NullString
+	TeacherDetail            []byte
+	StudentID                int64
+	StudentUsername          sql.NullString
+	StudentDetail            []byte
+	Class                    Class
+	TeacherSpecialFee        sql.NullInt32
+	Course                   Course
+	Instrument               Instrument
+	Grade                    Grade
+	ClassTeacherID           sql.NullInt64
+	ClassTeacherUsername     sql.NullString
+	ClassTeacherDetail       []byte
+	StudentLearningToken     StudentLearningToken
 }
 
 func (q *Queries) GetTeacherPaymentsByTeacherId(ctx context.Context, arg GetTeacherPaymentsByTeacherIdParams) ([]GetTeacherPaymentsByTeacherIdRow, error) {
@@ -1697,6 +4625,12 @@ func (q *Queries) GetTeacherPaymentsByTeacherId(ctx context.Context, arg GetTeac
 			&i.PaidCourseFeeValue,
 			&i.PaidTransportFeeValue,
 			&i.AddedAt,
+			&i.SnapshotStudentName,
+			&i.SnapshotClassDescription,
+			&i.SnapshotCourseName,
+			&i.SnapshotInstrumentName,
+			&i.SnapshotGradeName,
+			&i.SnapshotTeacherName,
 			&i.Attendance.ID,
 			&i.Attendance.Date,
 			&i.Attendance.UsedStudentTokenQuota,
@@ -1707,6 +4641,8 @@ func (q *Queries) GetTeacherPaymentsByTeacherId(ctx context.Context, arg GetTeac
 			&i.Attendance.TeacherID,
 			&i.Attendance.StudentID,
 			&i.Attendance.TokenID,
+			&i.Attendance.IsSubstitute,
+			&i.Attendance.OriginalTeacherID,
 			&i.TeacherID,
 			&i.TeacherUsername,
 			&i.TeacherDetail,
@@ -1750,53 +4686,451 @@ func (q *Queries) GetTeacherPaymentsByTeacherId(ctx context.Context, arg GetTeac
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	return items, nil
+	return items, nil
+}
+
+const incrementSLTQuotaById = `-- name: IncrementSLTQuotaById :exec
+UPDATE student_learning_token SET quota = ROUND(quota + ?, 3), last_updated_at = ?
+WHERE id = ?
+`
+
+type IncrementSLTQuotaByIdParams struct {
+	Quota         float64
+	LastUpdatedAt time.Time
+	ID            int64
+}
+
+func (q *Queries) IncrementSLTQuotaById(ctx context.Context, arg IncrementSLTQuotaByIdParams) error {
+	_, err := q.db.ExecContext(ctx, incrementSLTQuotaById, arg.Quota, arg.LastUpdatedAt, arg.ID)
+	return err
+}
+
+const insertBankStatementImport = `-- name: InsertBankStatementImport :execlastid
+INSERT INTO bank_statement_import (
+    bank_format, file_name, imported_by_user_id
+) VALUES (
+    ?, ?, ?
+)
+`
+
+type InsertBankStatementImportParams struct {
+	BankFormat       string
+	FileName         string
+	ImportedByUserID sql.NullInt64
+}
+
+func (q *Queries) InsertBankStatementImport(ctx context.Context, arg InsertBankStatementImportParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertBankStatementImport, arg.BankFormat, arg.FileName, arg.ImportedByUserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const insertBankStatementLine = `-- name: InsertBankStatementLine :execlastid
+INSERT INTO bank_statement_line (
+    bank_statement_import_id, transaction_date, description, reference, amount, status, enrollment_payment_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type InsertBankStatementLineParams struct {
+	BankStatementImportID int64
+	TransactionDate       time.Time
+	Description           string
+	Reference             string
+	Amount                int64
+	Status                string
+	EnrollmentPaymentID   sql.NullInt64
+}
+
+func (q *Queries) InsertBankStatementLine(ctx context.Context, arg InsertBankStatementLineParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertBankStatementLine,
+		arg.BankStatementImportID,
+		arg.TransactionDate,
+		arg.Description,
+		arg.Reference,
+		arg.Amount,
+		arg.Status,
+		arg.EnrollmentPaymentID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const insertCashUpDay = `-- name: InsertCashUpDay :execlastid
+INSERT INTO cash_up_day (
+    date, counted_cash_value, recorded_cash_value, difference_value, note, closed_by_user_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+)
+`
+
+type InsertCashUpDayParams struct {
+	Date              time.Time
+	CountedCashValue  int64
+	RecordedCashValue int64
+	DifferenceValue   int64
+	Note              string
+	ClosedByUserID    sql.NullInt64
+}
+
+func (q *Queries) InsertCashUpDay(ctx context.Context, arg InsertCashUpDayParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertCashUpDay,
+		arg.Date,
+		arg.CountedCashValue,
+		arg.RecordedCashValue,
+		arg.DifferenceValue,
+		arg.Note,
+		arg.ClosedByUserID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const insertClosedPeriod = `-- name: InsertClosedPeriod :execlastid
+INSERT INTO closed_period (
+    closed_until, note, created_by_user_id
+) VALUES (
+    ?, ?, ?
+)
+`
+
+type InsertClosedPeriodParams struct {
+	ClosedUntil     time.Time
+	Note            string
+	CreatedByUserID sql.NullInt64
+}
+
+func (q *Queries) InsertClosedPeriod(ctx context.Context, arg InsertClosedPeriodParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertClosedPeriod, arg.ClosedUntil, arg.Note, arg.CreatedByUserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const insertClosedPeriodOverride = `-- name: InsertClosedPeriodOverride :execlastid
+INSERT INTO closed_period_override (
+    operation, reason, closed_until, earliest_record_date, user_id
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+`
+
+type InsertClosedPeriodOverrideParams struct {
+	Operation          string
+	Reason             string
+	ClosedUntil        time.Time
+	EarliestRecordDate time.Time
+	UserID             sql.NullInt64
+}
+
+func (q *Queries) InsertClosedPeriodOverride(ctx context.Context, arg InsertClosedPeriodOverrideParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertClosedPeriodOverride,
+		arg.Operation,
+		arg.Reason,
+		arg.ClosedUntil,
+		arg.EarliestRecordDate,
+		arg.UserID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const insertDiscountRule = `-- name: InsertDiscountRule :execlastid
+INSERT INTO discount_rule (
+    name, type, is_percentage, value, min_count, max_day_of_month, voucher_code, usage_limit, valid_from, valid_until, is_deactivated
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type InsertDiscountRuleParams struct {
+	Name          string
+	Type          string
+	IsPercentage  int32
+	Value         int32
+	MinCount      int32
+	MaxDayOfMonth int32
+	VoucherCode   sql.NullString
+	UsageLimit    sql.NullInt32
+	ValidFrom     sql.NullTime
+	ValidUntil    sql.NullTime
+	IsDeactivated int32
+}
+
+func (q *Queries) InsertDiscountRule(ctx context.Context, arg InsertDiscountRuleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertDiscountRule,
+		arg.Name,
+		arg.Type,
+		arg.IsPercentage,
+		arg.Value,
+		arg.MinCount,
+		arg.MaxDayOfMonth,
+		arg.VoucherCode,
+		arg.UsageLimit,
+		arg.ValidFrom,
+		arg.ValidUntil,
+		arg.IsDeactivated,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const insertEnrollmentPayment = `-- name: InsertEnrollmentPayment :execlastid
+INSERT INTO enrollment_payment (
+    payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, payment_method, receiving_account, reference_number, enrollment_id,
+    snapshot_student_name, snapshot_class_description, snapshot_course_name, snapshot_instrument_name, snapshot_grade_name, snapshot_teacher_name
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+    ?, ?, ?, ?, ?, ?
+)
+`
+
+type InsertEnrollmentPaymentParams struct {
+	PaymentDate              time.Time
+	BalanceTopUp             int32
+	BalanceBonus             int32
+	CourseFeeValue           int32
+	TransportFeeValue        int32
+	PenaltyFeeValue          int32
+	DiscountFeeValue         int32
+	PaymentMethod            string
+	ReceivingAccount         string
+	ReferenceNumber          string
+	EnrollmentID             sql.NullInt64
+	SnapshotStudentName      string
+	SnapshotClassDescription string
+	SnapshotCourseName       string
+	SnapshotInstrumentName   string
+	SnapshotGradeName        string
+	SnapshotTeacherName      string
+}
+
+func (q *Queries) InsertEnrollmentPayment(ctx context.Context, arg InsertEnrollmentPaymentParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertEnrollmentPayment,
+		arg.PaymentDate,
+		arg.BalanceTopUp,
+		arg.BalanceBonus,
+		arg.CourseFeeValue,
+		arg.TransportFeeValue,
+		arg.PenaltyFeeValue,
+		arg.DiscountFeeValue,
+		arg.PaymentMethod,
+		arg.ReceivingAccount,
+		arg.ReferenceNumber,
+		arg.EnrollmentID,
+		arg.SnapshotStudentName,
+		arg.SnapshotClassDescription,
+		arg.SnapshotCourseName,
+		arg.SnapshotInstrumentName,
+		arg.SnapshotGradeName,
+		arg.SnapshotTeacherName,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const insertEnrollmentPaymentDiscount = `-- name: InsertEnrollmentPaymentDiscount :execlastid
+INSERT INTO enrollment_payment_discount (
+    enrollment_payment_id, discount_rule_id, discount_rule_name, value
+) VALUES (
+    ?, ?, ?, ?
+)
+`
+
+type InsertEnrollmentPaymentDiscountParams struct {
+	EnrollmentPaymentID int64
+	DiscountRuleID      sql.NullInt64
+	DiscountRuleName    string
+	Value               int32
+}
+
+func (q *Queries) InsertEnrollmentPaymentDiscount(ctx context.Context, arg InsertEnrollmentPaymentDiscountParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertEnrollmentPaymentDiscount,
+		arg.EnrollmentPaymentID,
+		arg.DiscountRuleID,
+		arg.DiscountRuleName,
+		arg.Value,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const insertEnrollmentPaymentReceipt = `-- name: InsertEnrollmentPaymentReceipt :execlastid
+INSERT INTO enrollment_payment_receipt (
+    receipt_number, enrollment_payment_id, issued_by_user_id
+) VALUES (
+    ?, ?, ?
+)
+`
+
+type InsertEnrollmentPaymentReceiptParams struct {
+	ReceiptNumber       int64
+	EnrollmentPaymentID sql.NullInt64
+	IssuedByUserID      sql.NullInt64
+}
+
+func (q *Queries) InsertEnrollmentPaymentReceipt(ctx context.Context, arg InsertEnrollmentPaymentReceiptParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertEnrollmentPaymentReceipt, arg.ReceiptNumber, arg.EnrollmentPaymentID, arg.IssuedByUserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const insertEnrollmentPaymentRefund = `-- name: InsertEnrollmentPaymentRefund :execlastid
+INSERT INTO enrollment_payment_refund (
+    refund_date, refunded_value, withdrawn_quota, reason, created_by_user_id, enrollment_payment_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+)
+`
+
+type InsertEnrollmentPaymentRefundParams struct {
+	RefundDate          time.Time
+	RefundedValue       int32
+	WithdrawnQuota      float64
+	Reason              string
+	CreatedByUserID     sql.NullInt64
+	EnrollmentPaymentID int64
+}
+
+func (q *Queries) InsertEnrollmentPaymentRefund(ctx context.Context, arg InsertEnrollmentPaymentRefundParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertEnrollmentPaymentRefund,
+		arg.RefundDate,
+		arg.RefundedValue,
+		arg.WithdrawnQuota,
+		arg.Reason,
+		arg.CreatedByUserID,
+		arg.EnrollmentPaymentID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
 }
 
-const incrementSLTQuotaById = `-- name: IncrementSLTQuotaById :exec
-UPDATE student_learning_token SET quota = ROUND(quota + ?, 3), last_updated_at = ?
-WHERE id = ?
+const insertInstallment = `-- name: InsertInstallment :execlastid
+INSERT INTO installment (
+    installment_number, due_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, installment_plan_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
 `
 
-type IncrementSLTQuotaByIdParams struct {
-	Quota         float64
-	LastUpdatedAt time.Time
-	ID            int64
+type InsertInstallmentParams struct {
+	InstallmentNumber int32
+	DueDate           time.Time
+	BalanceTopUp      int32
+	BalanceBonus      int32
+	CourseFeeValue    int32
+	TransportFeeValue int32
+	PenaltyFeeValue   int32
+	DiscountFeeValue  int32
+	InstallmentPlanID int64
 }
 
-func (q *Queries) IncrementSLTQuotaById(ctx context.Context, arg IncrementSLTQuotaByIdParams) error {
-	_, err := q.db.ExecContext(ctx, incrementSLTQuotaById, arg.Quota, arg.LastUpdatedAt, arg.ID)
-	return err
+func (q *Queries) InsertInstallment(ctx context.Context, arg InsertInstallmentParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertInstallment,
+		arg.InstallmentNumber,
+		arg.DueDate,
+		arg.BalanceTopUp,
+		arg.BalanceBonus,
+		arg.CourseFeeValue,
+		arg.TransportFeeValue,
+		arg.PenaltyFeeValue,
+		arg.DiscountFeeValue,
+		arg.InstallmentPlanID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
 }
 
-const insertEnrollmentPayment = `-- name: InsertEnrollmentPayment :execlastid
-INSERT INTO enrollment_payment (
-    payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, enrollment_id
+const insertInstallmentPlan = `-- name: InsertInstallmentPlan :execlastid
+INSERT INTO installment_plan (
+    balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, installment_count, created_by_user_id, enrollment_id
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
 `
 
-type InsertEnrollmentPaymentParams struct {
-	PaymentDate       time.Time
+type InsertInstallmentPlanParams struct {
 	BalanceTopUp      int32
 	BalanceBonus      int32
 	CourseFeeValue    int32
 	TransportFeeValue int32
 	PenaltyFeeValue   int32
 	DiscountFeeValue  int32
-	EnrollmentID      sql.NullInt64
+	InstallmentCount  int32
+	CreatedByUserID   sql.NullInt64
+	EnrollmentID      int64
 }
 
-func (q *Queries) InsertEnrollmentPayment(ctx context.Context, arg InsertEnrollmentPaymentParams) (int64, error) {
-	result, err := q.db.ExecContext(ctx, insertEnrollmentPayment,
-		arg.PaymentDate,
+func (q *Queries) InsertInstallmentPlan(ctx context.Context, arg InsertInstallmentPlanParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertInstallmentPlan,
 		arg.BalanceTopUp,
 		arg.BalanceBonus,
 		arg.CourseFeeValue,
 		arg.TransportFeeValue,
 		arg.PenaltyFeeValue,
 		arg.DiscountFeeValue,
+		arg.InstallmentCount,
+		arg.CreatedByUserID,
+		arg.EnrollmentID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const insertPaymentReminder = `-- name: InsertPaymentReminder :execlastid
+INSERT INTO payment_reminder (
+    reminder_type, recipient_email, owed_quota, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, sent_at, enrollment_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type InsertPaymentReminderParams struct {
+	ReminderType      string
+	RecipientEmail    string
+	OwedQuota         float64
+	CourseFeeValue    int32
+	TransportFeeValue int32
+	PenaltyFeeValue   int32
+	DiscountFeeValue  int32
+	SentAt            time.Time
+	EnrollmentID      int64
+}
+
+func (q *Queries) InsertPaymentReminder(ctx context.Context, arg InsertPaymentReminderParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertPaymentReminder,
+		arg.ReminderType,
+		arg.RecipientEmail,
+		arg.OwedQuota,
+		arg.CourseFeeValue,
+		arg.TransportFeeValue,
+		arg.PenaltyFeeValue,
+		arg.DiscountFeeValue,
+		arg.SentAt,
 		arg.EnrollmentID,
 	)
 	if err != nil {
@@ -1805,6 +5139,203 @@ func (q *Queries) InsertEnrollmentPayment(ctx context.Context, arg InsertEnrollm
 	return result.LastInsertId()
 }
 
+const insertPaymentReminderOptOut = `-- name: InsertPaymentReminderOptOut :exec
+INSERT INTO payment_reminder_opt_out (
+    student_id, opted_out_by_user_id
+) VALUES (
+    ?, ?
+)
+ON DUPLICATE KEY UPDATE student_id = student_id
+`
+
+type InsertPaymentReminderOptOutParams struct {
+	StudentID        int64
+	OptedOutByUserID sql.NullInt64
+}
+
+// InsertPaymentReminderOptOut keeps the existing record (and its opted_out_at) when the student has already opted out.
+func (q *Queries) InsertPaymentReminderOptOut(ctx context.Context, arg InsertPaymentReminderOptOutParams) error {
+	_, err := q.db.ExecContext(ctx, insertPaymentReminderOptOut, arg.StudentID, arg.OptedOutByUserID)
+	return err
+}
+
+const insertPayrollRun = `-- name: InsertPayrollRun :execlastid
+INSERT INTO payroll_run (
+    start_date, end_date, note, created_by_user_id
+) VALUES (
+    ?, ?, ?, ?
+)
+`
+
+type InsertPayrollRunParams struct {
+	StartDate       time.Time
+	EndDate         time.Time
+	Note            string
+	CreatedByUserID sql.NullInt64
+}
+
+func (q *Queries) InsertPayrollRun(ctx context.Context, arg InsertPayrollRunParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertPayrollRun,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Note,
+		arg.CreatedByUserID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const insertPayrollRunTeacher = `-- name: InsertPayrollRunTeacher :exec
+INSERT INTO payroll_run_teacher (
+    payroll_run_id, teacher_id
+) VALUES (
+    ?, ?
+)
+`
+
+type InsertPayrollRunTeacherParams struct {
+	PayrollRunID int64
+	TeacherID    int64
+}
+
+func (q *Queries) InsertPayrollRunTeacher(ctx context.Context, arg InsertPayrollRunTeacherParams) error {
+	_, err := q.db.ExecContext(ctx, insertPayrollRunTeacher, arg.PayrollRunID, arg.TeacherID)
+	return err
+}
+
+const insertPayrollRunTeachersByAttendanceIds = `-- name: InsertPayrollRunTeachersByAttendanceIds :exec
+INSERT IGNORE INTO payroll_run_teacher (payroll_run_id, teacher_id)
+SELECT DISTINCT ?, teacher_id FROM attendance
+WHERE id IN (/*SLICE:attendance_ids*/?) AND teacher_id IS NOT NULL
+`
+
+type InsertPayrollRunTeachersByAttendanceIdsParams struct {
+	PayrollRunID  int64
+	AttendanceIds []int64
+}
+
+// InsertPayrollRunTeachersByAttendanceIds adds the teachers of the attendances into the payroll_run, skipping the already-added ones.
+func (q *Queries) InsertPayrollRunTeachersByAttendanceIds(ctx context.Context, arg InsertPayrollRunTeachersByAttendanceIdsParams) error {
+	query := insertPayrollRunTeachersByAttendanceIds
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.PayrollRunID)
+	if len(arg.AttendanceIds) > 0 {
+		for _, v := range arg.AttendanceIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:attendance_ids*/?", strings.Repeat(",?", len(arg.AttendanceIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:attendance_ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
+const insertPenaltyPolicy = `-- name: InsertPenaltyPolicy :execlastid
+INSERT INTO penalty_policy (
+    name, trigger_day_of_month, grace_days, is_flat_fee, fee_value, max_fee_value, course_id, class_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type InsertPenaltyPolicyParams struct {
+	Name              string
+	TriggerDayOfMonth int32
+	GraceDays         int32
+	IsFlatFee         int32
+	FeeValue          int32
+	MaxFeeValue       int32
+	CourseID          sql.NullInt64
+	ClassID           sql.NullInt64
+}
+
+func (q *Queries) InsertPenaltyPolicy(ctx context.Context, arg InsertPenaltyPolicyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertPenaltyPolicy,
+		arg.Name,
+		arg.TriggerDayOfMonth,
+		arg.GraceDays,
+		arg.IsFlatFee,
+		arg.FeeValue,
+		arg.MaxFeeValue,
+		arg.CourseID,
+		arg.ClassID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const insertSLTTransaction = `-- name: InsertSLTTransaction :execlastid
+INSERT INTO slt_transaction (
+    quota_change, reason, source_id, user_id, created_at, token_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+)
+`
+
+type InsertSLTTransactionParams struct {
+	QuotaChange float64
+	Reason      string
+	SourceID    sql.NullInt64
+	UserID      sql.NullInt64
+	CreatedAt   time.Time
+	TokenID     int64
+}
+
+func (q *Queries) InsertSLTTransaction(ctx context.Context, arg InsertSLTTransactionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertSLTTransaction,
+		arg.QuotaChange,
+		arg.Reason,
+		arg.SourceID,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.TokenID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const insertSLTTransfer = `-- name: InsertSLTTransfer :execlastid
+INSERT INTO slt_transfer (
+    transferred_quota, converted_quota, note, user_id, created_at, source_token_id, destination_token_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type InsertSLTTransferParams struct {
+	TransferredQuota   float64
+	ConvertedQuota     float64
+	Note               string
+	UserID             sql.NullInt64
+	CreatedAt          time.Time
+	SourceTokenID      sql.NullInt64
+	DestinationTokenID sql.NullInt64
+}
+
+// ============================== SLT_TRANSFER ==============================
+func (q *Queries) InsertSLTTransfer(ctx context.Context, arg InsertSLTTransferParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertSLTTransfer,
+		arg.TransferredQuota,
+		arg.ConvertedQuota,
+		arg.Note,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.SourceTokenID,
+		arg.DestinationTokenID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
 const insertStudentLearningToken = `-- name: InsertStudentLearningToken :execlastid
 INSERT INTO student_learning_token (
     quota, course_fee_quarter_value, transport_fee_quarter_value, created_at, last_updated_at, enrollment_id
@@ -1837,30 +5368,176 @@ func (q *Queries) InsertStudentLearningToken(ctx context.Context, arg InsertStud
 	return result.LastInsertId()
 }
 
+const insertTeacherFeeSharing = `-- name: InsertTeacherFeeSharing :execlastid
+INSERT INTO teacher_fee_sharing (
+    course_fee_sharing_percentage, transport_fee_sharing_percentage, effective_from, teacher_id, course_id
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+`
+
+type InsertTeacherFeeSharingParams struct {
+	CourseFeeSharingPercentage    float64
+	TransportFeeSharingPercentage float64
+	EffectiveFrom                 time.Time
+	TeacherID                     sql.NullInt64
+	CourseID                      sql.NullInt64
+}
+
+func (q *Queries) InsertTeacherFeeSharing(ctx context.Context, arg InsertTeacherFeeSharingParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertTeacherFeeSharing,
+		arg.CourseFeeSharingPercentage,
+		arg.TransportFeeSharingPercentage,
+		arg.EffectiveFrom,
+		arg.TeacherID,
+		arg.CourseID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
 const insertTeacherPayment = `-- name: InsertTeacherPayment :execlastid
 INSERT INTO teacher_payment (
-    attendance_id, paid_course_fee_value, paid_transport_fee_value
+    attendance_id, paid_course_fee_value, paid_transport_fee_value,
+    snapshot_student_name, snapshot_class_description, snapshot_course_name, snapshot_instrument_name, snapshot_grade_name, snapshot_teacher_name
 ) VALUES (
-    ?, ?, ?
+    ?, ?, ?,
+    ?, ?, ?, ?, ?, ?
 )
 `
 
 type InsertTeacherPaymentParams struct {
-	AttendanceID          int64
-	PaidCourseFeeValue    int32
-	PaidTransportFeeValue int32
+	AttendanceID             int64
+	PaidCourseFeeValue       int32
+	PaidTransportFeeValue    int32
+	SnapshotStudentName      string
+	SnapshotClassDescription string
+	SnapshotCourseName       string
+	SnapshotInstrumentName   string
+	SnapshotGradeName        string
+	SnapshotTeacherName      string
 }
 
 func (q *Queries) InsertTeacherPayment(ctx context.Context, arg InsertTeacherPaymentParams) (int64, error) {
-	result, err := q.db.ExecContext(ctx, insertTeacherPayment, arg.AttendanceID, arg.PaidCourseFeeValue, arg.PaidTransportFeeValue)
+	result, err := q.db.ExecContext(ctx, insertTeacherPayment,
+		arg.AttendanceID,
+		arg.PaidCourseFeeValue,
+		arg.PaidTransportFeeValue,
+		arg.SnapshotStudentName,
+		arg.SnapshotClassDescription,
+		arg.SnapshotCourseName,
+		arg.SnapshotInstrumentName,
+		arg.SnapshotGradeName,
+		arg.SnapshotTeacherName,
+	)
 	if err != nil {
 		return 0, err
 	}
 	return result.LastInsertId()
 }
 
+const markPayrollRunAsPaid = `-- name: MarkPayrollRunAsPaid :exec
+UPDATE payroll_run SET status = 'PAID', paid_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+func (q *Queries) MarkPayrollRunAsPaid(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, markPayrollRunAsPaid, id)
+	return err
+}
+
+const setTeacherPaymentsPayrollRunIdByIds = `-- name: SetTeacherPaymentsPayrollRunIdByIds :exec
+UPDATE teacher_payment SET payroll_run_id = ?
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+type SetTeacherPaymentsPayrollRunIdByIdsParams struct {
+	PayrollRunID sql.NullInt64
+	Ids          []int64
+}
+
+func (q *Queries) SetTeacherPaymentsPayrollRunIdByIds(ctx context.Context, arg SetTeacherPaymentsPayrollRunIdByIdsParams) error {
+	query := setTeacherPaymentsPayrollRunIdByIds
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.PayrollRunID)
+	if len(arg.Ids) > 0 {
+		for _, v := range arg.Ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(arg.Ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
+const updateBankStatementLineMatch = `-- name: UpdateBankStatementLineMatch :exec
+UPDATE bank_statement_line SET status = ?, enrollment_payment_id = ?, confirmed_at = ?, confirmed_by_user_id = ?
+WHERE id = ?
+`
+
+type UpdateBankStatementLineMatchParams struct {
+	Status              string
+	EnrollmentPaymentID sql.NullInt64
+	ConfirmedAt         sql.NullTime
+	ConfirmedByUserID   sql.NullInt64
+	ID                  int64
+}
+
+func (q *Queries) UpdateBankStatementLineMatch(ctx context.Context, arg UpdateBankStatementLineMatchParams) error {
+	_, err := q.db.ExecContext(ctx, updateBankStatementLineMatch,
+		arg.Status,
+		arg.EnrollmentPaymentID,
+		arg.ConfirmedAt,
+		arg.ConfirmedByUserID,
+		arg.ID,
+	)
+	return err
+}
+
+const updateDiscountRule = `-- name: UpdateDiscountRule :exec
+UPDATE discount_rule SET name = ?, type = ?, is_percentage = ?, value = ?, min_count = ?, max_day_of_month = ?, voucher_code = ?, usage_limit = ?, valid_from = ?, valid_until = ?, is_deactivated = ?
+WHERE id = ?
+`
+
+type UpdateDiscountRuleParams struct {
+	Name          string
+	Type          string
+	IsPercentage  int32
+	Value         int32
+	MinCount      int32
+	MaxDayOfMonth int32
+	VoucherCode   sql.NullString
+	UsageLimit    sql.NullInt32
+	ValidFrom     sql.NullTime
+	ValidUntil    sql.NullTime
+	IsDeactivated int32
+	ID            int64
+}
+
+func (q *Queries) UpdateDiscountRule(ctx context.Context, arg UpdateDiscountRuleParams) error {
+	_, err := q.db.ExecContext(ctx, updateDiscountRule,
+		arg.Name,
+		arg.Type,
+		arg.IsPercentage,
+		arg.Value,
+		arg.MinCount,
+		arg.MaxDayOfMonth,
+		arg.VoucherCode,
+		arg.UsageLimit,
+		arg.ValidFrom,
+		arg.ValidUntil,
+		arg.IsDeactivated,
+		arg.ID,
+	)
+	return err
+}
+
 const updateEnrollmentPayment = `-- name: UpdateEnrollmentPayment :exec
-UPDATE enrollment_payment SET payment_date = ?, balance_top_up = ?, balance_bonus = ?, course_fee_value = ?, transport_fee_value = ?, penalty_fee_value = ?, discount_fee_value = ?
+UPDATE enrollment_payment SET payment_date = ?, balance_top_up = ?, balance_bonus = ?, course_fee_value = ?, transport_fee_value = ?, penalty_fee_value = ?, discount_fee_value = ?, payment_method = ?, receiving_account = ?, reference_number = ?
 WHERE id = ?
 `
 
@@ -1872,6 +5549,9 @@ type UpdateEnrollmentPaymentParams struct {
 	TransportFeeValue int32
 	PenaltyFeeValue   int32
 	DiscountFeeValue  int32
+	PaymentMethod     string
+	ReceivingAccount  string
+	ReferenceNumber   string
 	ID                int64
 }
 
@@ -1884,6 +5564,9 @@ func (q *Queries) UpdateEnrollmentPayment(ctx context.Context, arg UpdateEnrollm
 		arg.TransportFeeValue,
 		arg.PenaltyFeeValue,
 		arg.DiscountFeeValue,
+		arg.PaymentMethod,
+		arg.ReceivingAccount,
+		arg.ReferenceNumber,
 		arg.ID,
 	)
 	return err
@@ -1911,6 +5594,48 @@ func (q *Queries) UpdateEnrollmentPaymentOnSafeAttributes(ctx context.Context, a
 	return err
 }
 
+const updateLastReceiptNumber = `-- name: UpdateLastReceiptNumber :exec
+UPDATE receipt_number_sequence SET last_receipt_number = ?
+WHERE id = 1
+`
+
+func (q *Queries) UpdateLastReceiptNumber(ctx context.Context, lastReceiptNumber int64) error {
+	_, err := q.db.ExecContext(ctx, updateLastReceiptNumber, lastReceiptNumber)
+	return err
+}
+
+const updatePenaltyPolicy = `-- name: UpdatePenaltyPolicy :exec
+UPDATE penalty_policy SET name = ?, trigger_day_of_month = ?, grace_days = ?, is_flat_fee = ?, fee_value = ?, max_fee_value = ?, course_id = ?, class_id = ?
+WHERE id = ?
+`
+
+type UpdatePenaltyPolicyParams struct {
+	Name              string
+	TriggerDayOfMonth int32
+	GraceDays         int32
+	IsFlatFee         int32
+	FeeValue          int32
+	MaxFeeValue       int32
+	CourseID          sql.NullInt64
+	ClassID           sql.NullInt64
+	ID                int64
+}
+
+func (q *Queries) UpdatePenaltyPolicy(ctx context.Context, arg UpdatePenaltyPolicyParams) error {
+	_, err := q.db.ExecContext(ctx, updatePenaltyPolicy,
+		arg.Name,
+		arg.TriggerDayOfMonth,
+		arg.GraceDays,
+		arg.IsFlatFee,
+		arg.FeeValue,
+		arg.MaxFeeValue,
+		arg.CourseID,
+		arg.ClassID,
+		arg.ID,
+	)
+	return err
+}
+
 const updateStudentLearningToken = `-- name: UpdateStudentLearningToken :exec
 UPDATE student_learning_token SET quota = ?, course_fee_quarter_value = ?, transport_fee_quarter_value = ?, last_updated_at = ?
 WHERE id = ?
@@ -1935,6 +5660,32 @@ func (q *Queries) UpdateStudentLearningToken(ctx context.Context, arg UpdateStud
 	return err
 }
 
+const updateTeacherFeeSharing = `-- name: UpdateTeacherFeeSharing :exec
+UPDATE teacher_fee_sharing SET course_fee_sharing_percentage = ?, transport_fee_sharing_percentage = ?, effective_from = ?, teacher_id = ?, course_id = ?
+WHERE id = ?
+`
+
+type UpdateTeacherFeeSharingParams struct {
+	CourseFeeSharingPercentage    float64
+	TransportFeeSharingPercentage float64
+	EffectiveFrom                 time.Time
+	TeacherID                     sql.NullInt64
+	CourseID                      sql.NullInt64
+	ID                            int64
+}
+
+func (q *Queries) UpdateTeacherFeeSharing(ctx context.Context, arg UpdateTeacherFeeSharingParams) error {
+	_, err := q.db.ExecContext(ctx, updateTeacherFeeSharing,
+		arg.CourseFeeSharingPercentage,
+		arg.TransportFeeSharingPercentage,
+		arg.EffectiveFrom,
+		arg.TeacherID,
+		arg.CourseID,
+		arg.ID,
+	)
+	return err
+}
+
 const updateTeacherPayment = `-- name: UpdateTeacherPayment :exec
 UPDATE teacher_payment SET attendance_id = ?, paid_course_fee_value = ?, paid_transport_fee_value = ?, added_at = ?
 WHERE id = ?
@@ -1958,3 +5709,22 @@ func (q *Queries) UpdateTeacherPayment(ctx context.Context, arg UpdateTeacherPay
 	)
 	return err
 }
+
+const updateUnpaidInstallmentEnrollmentPayment = `-- name: UpdateUnpaidInstallmentEnrollmentPayment :execrows
+UPDATE installment SET enrollment_payment_id = ?
+WHERE id = ? AND enrollment_payment_id IS NULL
+`
+
+type UpdateUnpaidInstallmentEnrollmentPaymentParams struct {
+	EnrollmentPaymentID sql.NullInt64
+	ID                  int64
+}
+
+// UpdateUnpaidInstallmentEnrollmentPayment affects no row when the installment has been paid, which prevents paying an installment twice.
+func (q *Queries) UpdateUnpaidInstallmentEnrollmentPayment(ctx context.Context, arg UpdateUnpaidInstallmentEnrollmentPaymentParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUnpaidInstallmentEnrollmentPayment, arg.EnrollmentPaymentID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -118,6 +118,39 @@ func (q *Queries) CountCoursesByIds(ctx context.Context, ids []int64) (int64, er
 	return total, err
 }
 
+const countFamilies = `-- name: CountFamilies :one
+SELECT Count(id) AS total FROM family
+`
+
+func (q *Queries) CountFamilies(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFamilies)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countFamiliesByIds = `-- name: CountFamiliesByIds :one
+SELECT Count(id) AS total FROM family
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) CountFamiliesByIds(ctx context.Context, ids []int64) (int64, error) {
+	query := countFamiliesByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	row := q.db.QueryRowContext(ctx, query, queryParams...)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
 const countGrades = `-- name: CountGrades :one
 SELECT Count(*) AS total FROM grade
 `
@@ -184,6 +217,31 @@ func (q *Queries) CountInstrumentsByIds(ctx context.Context, ids []int64) (int64
 	return total, err
 }
 
+const countOverlappingStudentEnrollmentHolds = `-- name: CountOverlappingStudentEnrollmentHolds :one
+SELECT Count(id) AS total FROM student_enrollment_hold
+WHERE enrollment_id = ? AND id != ? AND start_date <= ? AND end_date >= ?
+`
+
+type CountOverlappingStudentEnrollmentHoldsParams struct {
+	EnrollmentID int64
+	ID           int64
+	EndDate      time.Time
+	StartDate    time.Time
+}
+
+// CountOverlappingStudentEnrollmentHolds counts the other holds (excluding the given id) of the enrollment which overlap with [start_date, end_date].
+func (q *Queries) CountOverlappingStudentEnrollmentHolds(ctx context.Context, arg CountOverlappingStudentEnrollmentHoldsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOverlappingStudentEnrollmentHolds,
+		arg.EnrollmentID,
+		arg.ID,
+		arg.EndDate,
+		arg.StartDate,
+	)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
 const countPaidTeachers = `-- name: CountPaidTeachers :one
 WITH paid_teacher AS (
     SELECT attendance.teacher_id, ROUND(sum(attendance.used_student_token_quota), 3) AS total_attendances
@@ -209,6 +267,28 @@ func (q *Queries) CountPaidTeachers(ctx context.Context, arg CountPaidTeachersPa
 	return total, err
 }
 
+const countStudentEnrollmentHoldsByIds = `-- name: CountStudentEnrollmentHoldsByIds :one
+SELECT Count(id) AS total FROM student_enrollment_hold
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) CountStudentEnrollmentHoldsByIds(ctx context.Context, ids []int64) (int64, error) {
+	query := countStudentEnrollmentHoldsByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	row := q.db.QueryRowContext(ctx, query, queryParams...)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
 const countStudentEnrollments = `-- name: CountStudentEnrollments :one
 SELECT COUNT(id) FROM student_enrollment
 WHERE is_deleted = 0
@@ -385,6 +465,46 @@ func (q *Queries) DeleteCoursesByIds(ctx context.Context, ids []int64) error {
 	return err
 }
 
+const deleteFamiliesByIds = `-- name: DeleteFamiliesByIds :exec
+DELETE FROM family
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) DeleteFamiliesByIds(ctx context.Context, ids []int64) error {
+	query := deleteFamiliesByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
+const deleteFamilyGuardiansByFamilyId = `-- name: DeleteFamilyGuardiansByFamilyId :exec
+DELETE FROM family_guardian
+WHERE family_id = ?
+`
+
+func (q *Queries) DeleteFamilyGuardiansByFamilyId(ctx context.Context, familyID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteFamilyGuardiansByFamilyId, familyID)
+	return err
+}
+
+const deleteFamilyStudentsByFamilyId = `-- name: DeleteFamilyStudentsByFamilyId :exec
+DELETE FROM family_student
+WHERE family_id = ?
+`
+
+func (q *Queries) DeleteFamilyStudentsByFamilyId(ctx context.Context, familyID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteFamilyStudentsByFamilyId, familyID)
+	return err
+}
+
 const deleteGradesByIds = `-- name: DeleteGradesByIds :exec
 DELETE FROM grade
 WHERE id IN (/*SLICE:ids*/?)
@@ -475,6 +595,26 @@ func (q *Queries) DeleteStudentEnrollmentByStudentId(ctx context.Context, studen
 	return err
 }
 
+const deleteStudentEnrollmentHoldsByIds = `-- name: DeleteStudentEnrollmentHoldsByIds :exec
+DELETE FROM student_enrollment_hold
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) DeleteStudentEnrollmentHoldsByIds(ctx context.Context, ids []int64) error {
+	query := deleteStudentEnrollmentHoldsByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
 const deleteStudentEnrollmentsByIds = `-- name: DeleteStudentEnrollmentsByIds :exec
 DELETE FROM student_enrollment
 WHERE id IN (/*SLICE:ids*/?)
@@ -605,6 +745,25 @@ func (q *Queries) DisableStudentEnrollment(ctx context.Context, id int64) error
 	return err
 }
 
+const disableStudentEnrollmentsWithEndedPeriods = `-- name: DisableStudentEnrollmentsWithEndedPeriods :execrows
+UPDATE student_enrollment AS se SET se.is_deleted = 1
+WHERE se.is_deleted = 0
+    AND EXISTS (SELECT 1 FROM student_enrollment_period AS sep WHERE sep.enrollment_id = se.id)
+    AND NOT EXISTS (
+        SELECT 1 FROM student_enrollment_period AS sep
+        WHERE sep.enrollment_id = se.id AND (sep.end_date IS NULL OR sep.end_date >= ?)
+    )
+`
+
+// DisableStudentEnrollmentsWithEndedPeriods disables the student_enrollments whose periods have all ended before today, i.e. the withdrawals which have taken effect.
+func (q *Queries) DisableStudentEnrollmentsWithEndedPeriods(ctx context.Context, today time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, disableStudentEnrollmentsWithEndedPeriods, today)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const enableStudentEnrollment = `-- name: EnableStudentEnrollment :exec
 UPDATE student_enrollment SET is_deleted = 0
 WHERE id = ?
@@ -615,6 +774,152 @@ func (q *Queries) EnableStudentEnrollment(ctx context.Context, id int64) error {
 	return err
 }
 
+const endStudentEnrollmentPeriod = `-- name: EndStudentEnrollmentPeriod :exec
+UPDATE student_enrollment_period SET end_date = ?, withdrawal_reason = ?
+WHERE id = ?
+`
+
+type EndStudentEnrollmentPeriodParams struct {
+	EndDate          sql.NullTime
+	WithdrawalReason string
+	ID               int64
+}
+
+func (q *Queries) EndStudentEnrollmentPeriod(ctx context.Context, arg EndStudentEnrollmentPeriodParams) error {
+	_, err := q.db.ExecContext(ctx, endStudentEnrollmentPeriod, arg.EndDate, arg.WithdrawalReason, arg.ID)
+	return err
+}
+
+const getActiveStudentEnrollmentIdsByFamilyId = `-- name: GetActiveStudentEnrollmentIdsByFamilyId :many
+SELECT se.id
+FROM student_enrollment AS se
+    JOIN family_student AS fs ON se.student_id = fs.student_id
+    JOIN class ON se.class_id = class.id
+WHERE fs.family_id = ? AND se.is_deleted = 0 AND class.is_deactivated = 0
+ORDER BY se.student_id, se.id
+`
+
+// GetActiveStudentEnrollmentIdsByFamilyId returns the active student_enrollments of all students in the family.
+func (q *Queries) GetActiveStudentEnrollmentIdsByFamilyId(ctx context.Context, familyID int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getActiveStudentEnrollmentIdsByFamilyId, familyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAttendanceIdsByStudentEnrollmentIdFromDate = `-- name: GetAttendanceIdsByStudentEnrollmentIdFromDate :many
+SELECT attendance.id FROM attendance
+    JOIN student_enrollment AS se ON (attendance.student_id = se.student_id AND attendance.class_id = se.class_id)
+WHERE se.id = ? AND attendance.date >= ?
+ORDER BY attendance.date, attendance.id
+`
+
+type GetAttendanceIdsByStudentEnrollmentIdFromDateParams struct {
+	EnrollmentID int64
+	Date         time.Time
+}
+
+func (q *Queries) GetAttendanceIdsByStudentEnrollmentIdFromDate(ctx context.Context, arg GetAttendanceIdsByStudentEnrollmentIdFromDateParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getAttendanceIdsByStudentEnrollmentIdFromDate, arg.EnrollmentID, arg.Date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAttendancesWithoutTokenByClassId = `-- name: GetAttendancesWithoutTokenByClassId :many
+SELECT attendance.id, attendance.date, attendance.student_id, attendance.is_paid, se.id AS student_enrollment_id
+FROM attendance
+    LEFT JOIN student_enrollment AS se ON (attendance.student_id = se.student_id AND attendance.class_id = se.class_id)
+WHERE
+    attendance.token_id IS NULL
+    AND attendance.class_id = ?
+    AND attendance.student_id = COALESCE(?, attendance.student_id)
+    AND (attendance.date >= ? AND attendance.date <= ?)
+ORDER BY attendance.date, attendance.id
+`
+
+type GetAttendancesWithoutTokenByClassIdParams struct {
+	ClassID   int64
+	StudentID sql.NullInt64
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+type GetAttendancesWithoutTokenByClassIdRow struct {
+	ID                  int64
+	Date                time.Time
+	StudentID           int64
+	IsPaid              int32
+	StudentEnrollmentID sql.NullInt64
+}
+
+// ============================== ATTENDANCE_TOKEN_ASSIGNMENT ==============================
+func (q *Queries) GetAttendancesWithoutTokenByClassId(ctx context.Context, arg GetAttendancesWithoutTokenByClassIdParams) ([]GetAttendancesWithoutTokenByClassIdRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAttendancesWithoutTokenByClassId,
+		arg.ClassID,
+		arg.StudentID,
+		arg.StartDate,
+		arg.EndDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAttendancesWithoutTokenByClassIdRow
+	for rows.Next() {
+		var i GetAttendancesWithoutTokenByClassIdRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Date,
+			&i.StudentID,
+			&i.IsPaid,
+			&i.StudentEnrollmentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getClassAutoOweTokenMode = `-- name: GetClassAutoOweTokenMode :one
 SELECT class.auto_owe_attendance_token
 FROM class
@@ -1120,6 +1425,60 @@ func (q *Queries) GetCourseById(ctx context.Context, id int64) (GetCourseByIdRow
 	return i, err
 }
 
+const getCourseFeeAtDate = `-- name: GetCourseFeeAtDate :one
+SELECT fee FROM course_fee_history
+WHERE course_id = ? AND effective_from <= ?
+ORDER BY effective_from DESC, id DESC
+LIMIT 1
+`
+
+type GetCourseFeeAtDateParams struct {
+	CourseID      int64
+	EffectiveFrom time.Time
+}
+
+func (q *Queries) GetCourseFeeAtDate(ctx context.Context, arg GetCourseFeeAtDateParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getCourseFeeAtDate, arg.CourseID, arg.EffectiveFrom)
+	var fee int32
+	err := row.Scan(&fee)
+	return fee, err
+}
+
+const getCourseFeeHistoriesByCourseId = `-- name: GetCourseFeeHistoriesByCourseId :many
+SELECT id, fee, effective_from, course_id FROM course_fee_history
+WHERE course_id = ?
+ORDER BY effective_from DESC, id DESC
+`
+
+// ============================== FEE_HISTORY ==============================
+func (q *Queries) GetCourseFeeHistoriesByCourseId(ctx context.Context, courseID int64) ([]CourseFeeHistory, error) {
+	rows, err := q.db.QueryContext(ctx, getCourseFeeHistoriesByCourseId, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CourseFeeHistory
+	for rows.Next() {
+		var i CourseFeeHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.Fee,
+			&i.EffectiveFrom,
+			&i.CourseID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getCourses = `-- name: GetCourses :many
 SELECT course.id AS course_id, instrument.id, instrument.name, grade.id, grade.name, default_fee, default_duration_minute
 FROM course
@@ -1341,39 +1700,26 @@ func (q *Queries) GetCoursesByInstrumentId(ctx context.Context, arg GetCoursesBy
 	return items, nil
 }
 
-const getGradeById = `-- name: GetGradeById :one
-SELECT id, name FROM grade
-WHERE id = ? LIMIT 1
-`
-
-// ============================== GRADE ==============================
-func (q *Queries) GetGradeById(ctx context.Context, id int64) (Grade, error) {
-	row := q.db.QueryRowContext(ctx, getGradeById, id)
-	var i Grade
-	err := row.Scan(&i.ID, &i.Name)
-	return i, err
-}
-
-const getGrades = `-- name: GetGrades :many
-SELECT id, name FROM grade
+const getFamilies = `-- name: GetFamilies :many
+SELECT id, name FROM family
 ORDER BY id
 LIMIT ? OFFSET ?
 `
 
-type GetGradesParams struct {
+type GetFamiliesParams struct {
 	Limit  int32
 	Offset int32
 }
 
-func (q *Queries) GetGrades(ctx context.Context, arg GetGradesParams) ([]Grade, error) {
-	rows, err := q.db.QueryContext(ctx, getGrades, arg.Limit, arg.Offset)
+func (q *Queries) GetFamilies(ctx context.Context, arg GetFamiliesParams) ([]Family, error) {
+	rows, err := q.db.QueryContext(ctx, getFamilies, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Grade
+	var items []Family
 	for rows.Next() {
-		var i Grade
+		var i Family
 		if err := rows.Scan(&i.ID, &i.Name); err != nil {
 			return nil, err
 		}
@@ -1388,13 +1734,13 @@ func (q *Queries) GetGrades(ctx context.Context, arg GetGradesParams) ([]Grade,
 	return items, nil
 }
 
-const getGradesByIds = `-- name: GetGradesByIds :many
-SELECT id, name FROM grade
+const getFamiliesByIds = `-- name: GetFamiliesByIds :many
+SELECT id, name FROM family
 WHERE id IN (/*SLICE:ids*/?)
 `
 
-func (q *Queries) GetGradesByIds(ctx context.Context, ids []int64) ([]Grade, error) {
-	query := getGradesByIds
+func (q *Queries) GetFamiliesByIds(ctx context.Context, ids []int64) ([]Family, error) {
+	query := getFamiliesByIds
 	var queryParams []interface{}
 	if len(ids) > 0 {
 		for _, v := range ids {
@@ -1409,9 +1755,9 @@ func (q *Queries) GetGradesByIds(ctx context.Context, ids []int64) ([]Grade, err
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Grade
+	var items []Family
 	for rows.Next() {
-		var i Grade
+		var i Family
 		if err := rows.Scan(&i.ID, &i.Name); err != nil {
 			return nil, err
 		}
@@ -1426,8 +1772,213 @@ func (q *Queries) GetGradesByIds(ctx context.Context, ids []int64) ([]Grade, err
 	return items, nil
 }
 
-const getInstrumentById = `-- name: GetInstrumentById :one
-SELECT id, name FROM instrument
+const getFamilyById = `-- name: GetFamilyById :one
+SELECT id, name FROM family
+WHERE id = ? LIMIT 1
+`
+
+// ============================== FAMILY ==============================
+func (q *Queries) GetFamilyById(ctx context.Context, id int64) (Family, error) {
+	row := q.db.QueryRowContext(ctx, getFamilyById, id)
+	var i Family
+	err := row.Scan(&i.ID, &i.Name)
+	return i, err
+}
+
+const getFamilyGuardiansByFamilyIds = `-- name: GetFamilyGuardiansByFamilyIds :many
+SELECT fg.family_id, user.id AS user_id, user.username, user.user_detail
+FROM family_guardian AS fg
+    JOIN user ON fg.user_id = user.id
+WHERE fg.family_id IN (/*SLICE:ids*/?)
+ORDER BY fg.family_id, user.id
+`
+
+type GetFamilyGuardiansByFamilyIdsRow struct {
+	FamilyID   int64
+	UserID     int64
+	Username   string
+	UserDetail json.RawMessage
+}
+
+func (q *Queries) GetFamilyGuardiansByFamilyIds(ctx context.Context, ids []int64) ([]GetFamilyGuardiansByFamilyIdsRow, error) {
+	query := getFamilyGuardiansByFamilyIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFamilyGuardiansByFamilyIdsRow
+	for rows.Next() {
+		var i GetFamilyGuardiansByFamilyIdsRow
+		if err := rows.Scan(
+			&i.FamilyID,
+			&i.UserID,
+			&i.Username,
+			&i.UserDetail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFamilyStudentsByFamilyIds = `-- name: GetFamilyStudentsByFamilyIds :many
+SELECT fs.family_id, student.id AS student_id, user.username AS student_username, user.user_detail AS student_detail
+FROM family_student AS fs
+    JOIN student ON fs.student_id = student.id
+    JOIN user ON student.user_id = user.id
+WHERE fs.family_id IN (/*SLICE:ids*/?)
+ORDER BY fs.family_id, student.id
+`
+
+type GetFamilyStudentsByFamilyIdsRow struct {
+	FamilyID        int64
+	StudentID       int64
+	StudentUsername string
+	StudentDetail   json.RawMessage
+}
+
+func (q *Queries) GetFamilyStudentsByFamilyIds(ctx context.Context, ids []int64) ([]GetFamilyStudentsByFamilyIdsRow, error) {
+	query := getFamilyStudentsByFamilyIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFamilyStudentsByFamilyIdsRow
+	for rows.Next() {
+		var i GetFamilyStudentsByFamilyIdsRow
+		if err := rows.Scan(
+			&i.FamilyID,
+			&i.StudentID,
+			&i.StudentUsername,
+			&i.StudentDetail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGradeById = `-- name: GetGradeById :one
+SELECT id, name FROM grade
+WHERE id = ? LIMIT 1
+`
+
+// ============================== GRADE ==============================
+func (q *Queries) GetGradeById(ctx context.Context, id int64) (Grade, error) {
+	row := q.db.QueryRowContext(ctx, getGradeById, id)
+	var i Grade
+	err := row.Scan(&i.ID, &i.Name)
+	return i, err
+}
+
+const getGrades = `-- name: GetGrades :many
+SELECT id, name FROM grade
+ORDER BY id
+LIMIT ? OFFSET ?
+`
+
+type GetGradesParams struct {
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) GetGrades(ctx context.Context, arg GetGradesParams) ([]Grade, error) {
+	rows, err := q.db.QueryContext(ctx, getGrades, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Grade
+	for rows.Next() {
+		var i Grade
+		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGradesByIds = `-- name: GetGradesByIds :many
+SELECT id, name FROM grade
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) GetGradesByIds(ctx context.Context, ids []int64) ([]Grade, error) {
+	query := getGradesByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Grade
+	for rows.Next() {
+		var i Grade
+		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getInstrumentById = `-- name: GetInstrumentById :one
+SELECT id, name FROM instrument
 WHERE id = ? LIMIT 1
 `
 
@@ -1511,6 +2062,26 @@ func (q *Queries) GetInstrumentsByIds(ctx context.Context, ids []int64) ([]Instr
 	return items, nil
 }
 
+const getLatestStudentEnrollmentPeriod = `-- name: GetLatestStudentEnrollmentPeriod :one
+SELECT id, start_date, end_date, withdrawal_reason, enrollment_id FROM student_enrollment_period
+WHERE enrollment_id = ?
+ORDER BY start_date DESC, id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestStudentEnrollmentPeriod(ctx context.Context, enrollmentID int64) (StudentEnrollmentPeriod, error) {
+	row := q.db.QueryRowContext(ctx, getLatestStudentEnrollmentPeriod, enrollmentID)
+	var i StudentEnrollmentPeriod
+	err := row.Scan(
+		&i.ID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.WithdrawalReason,
+		&i.EnrollmentID,
+	)
+	return i, err
+}
+
 const getPaidTeachers = `-- name: GetPaidTeachers :many
 SELECT teacher.id, user.id AS user_id, username, email, user_detail, privilege_type, is_deactivated, created_at, ROUND(sum(attendance.used_student_token_quota), 3) AS total_attendances,
     ROUND(sum(CASE WHEN attendance.token_id IS NULL THEN attendance.used_student_token_quota ELSE 0 END), 3) as total_attendances_without_token
@@ -1718,6 +2289,267 @@ func (q *Queries) GetStudentEnrollmentById(ctx context.Context, id int64) (GetSt
 	return i, err
 }
 
+const getStudentEnrollmentByStudentIdAndClassId = `-- name: GetStudentEnrollmentByStudentIdAndClassId :one
+SELECT id, is_deleted FROM student_enrollment
+WHERE student_id = ? AND class_id = ?
+`
+
+type GetStudentEnrollmentByStudentIdAndClassIdParams struct {
+	StudentID int64
+	ClassID   int64
+}
+
+type GetStudentEnrollmentByStudentIdAndClassIdRow struct {
+	ID        int64
+	IsDeleted int32
+}
+
+// GetStudentEnrollmentByStudentIdAndClassId also returns the withdrawn (disabled) enrollment.
+func (q *Queries) GetStudentEnrollmentByStudentIdAndClassId(ctx context.Context, arg GetStudentEnrollmentByStudentIdAndClassIdParams) (GetStudentEnrollmentByStudentIdAndClassIdRow, error) {
+	row := q.db.QueryRowContext(ctx, getStudentEnrollmentByStudentIdAndClassId, arg.StudentID, arg.ClassID)
+	var i GetStudentEnrollmentByStudentIdAndClassIdRow
+	err := row.Scan(&i.ID, &i.IsDeleted)
+	return i, err
+}
+
+const getStudentEnrollmentHoldById = `-- name: GetStudentEnrollmentHoldById :one
+SELECT seh.id, seh.start_date, seh.end_date, seh.reason, seh.enrollment_id, se.student_id
+FROM student_enrollment_hold AS seh
+    JOIN student_enrollment AS se ON seh.enrollment_id = se.id
+WHERE seh.id = ? LIMIT 1
+`
+
+type GetStudentEnrollmentHoldByIdRow struct {
+	ID           int64
+	StartDate    time.Time
+	EndDate      time.Time
+	Reason       string
+	EnrollmentID int64
+	StudentID    int64
+}
+
+// ============================== STUDENT_ENROLLMENT_HOLD ==============================
+func (q *Queries) GetStudentEnrollmentHoldById(ctx context.Context, id int64) (GetStudentEnrollmentHoldByIdRow, error) {
+	row := q.db.QueryRowContext(ctx, getStudentEnrollmentHoldById, id)
+	var i GetStudentEnrollmentHoldByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Reason,
+		&i.EnrollmentID,
+		&i.StudentID,
+	)
+	return i, err
+}
+
+const getStudentEnrollmentHoldsByClassId = `-- name: GetStudentEnrollmentHoldsByClassId :many
+SELECT seh.id, seh.start_date, seh.end_date, seh.reason, seh.enrollment_id, se.student_id
+FROM student_enrollment_hold AS seh
+    JOIN student_enrollment AS se ON seh.enrollment_id = se.id
+WHERE se.class_id = ? AND se.is_deleted = 0
+ORDER BY seh.enrollment_id, seh.start_date
+`
+
+type GetStudentEnrollmentHoldsByClassIdRow struct {
+	ID           int64
+	StartDate    time.Time
+	EndDate      time.Time
+	Reason       string
+	EnrollmentID int64
+	StudentID    int64
+}
+
+func (q *Queries) GetStudentEnrollmentHoldsByClassId(ctx context.Context, classID int64) ([]GetStudentEnrollmentHoldsByClassIdRow, error) {
+	rows, err := q.db.QueryContext(ctx, getStudentEnrollmentHoldsByClassId, classID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetStudentEnrollmentHoldsByClassIdRow
+	for rows.Next() {
+		var i GetStudentEnrollmentHoldsByClassIdRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Reason,
+			&i.EnrollmentID,
+			&i.StudentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStudentEnrollmentHoldsByIds = `-- name: GetStudentEnrollmentHoldsByIds :many
+SELECT seh.id, seh.start_date, seh.end_date, seh.reason, seh.enrollment_id, se.student_id
+FROM student_enrollment_hold AS seh
+    JOIN student_enrollment AS se ON seh.enrollment_id = se.id
+WHERE seh.id IN (/*SLICE:ids*/?)
+ORDER BY seh.enrollment_id, seh.start_date
+`
+
+type GetStudentEnrollmentHoldsByIdsRow struct {
+	ID           int64
+	StartDate    time.Time
+	EndDate      time.Time
+	Reason       string
+	EnrollmentID int64
+	StudentID    int64
+}
+
+func (q *Queries) GetStudentEnrollmentHoldsByIds(ctx context.Context, ids []int64) ([]GetStudentEnrollmentHoldsByIdsRow, error) {
+	query := getStudentEnrollmentHoldsByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetStudentEnrollmentHoldsByIdsRow
+	for rows.Next() {
+		var i GetStudentEnrollmentHoldsByIdsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Reason,
+			&i.EnrollmentID,
+			&i.StudentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStudentEnrollmentHoldsByStudentEnrollmentIds = `-- name: GetStudentEnrollmentHoldsByStudentEnrollmentIds :many
+SELECT seh.id, seh.start_date, seh.end_date, seh.reason, seh.enrollment_id, se.student_id
+FROM student_enrollment_hold AS seh
+    JOIN student_enrollment AS se ON seh.enrollment_id = se.id
+WHERE seh.enrollment_id IN (/*SLICE:enrollmentIds*/?)
+ORDER BY seh.enrollment_id, seh.start_date
+`
+
+type GetStudentEnrollmentHoldsByStudentEnrollmentIdsRow struct {
+	ID           int64
+	StartDate    time.Time
+	EndDate      time.Time
+	Reason       string
+	EnrollmentID int64
+	StudentID    int64
+}
+
+func (q *Queries) GetStudentEnrollmentHoldsByStudentEnrollmentIds(ctx context.Context, enrollmentIds []int64) ([]GetStudentEnrollmentHoldsByStudentEnrollmentIdsRow, error) {
+	query := getStudentEnrollmentHoldsByStudentEnrollmentIds
+	var queryParams []interface{}
+	if len(enrollmentIds) > 0 {
+		for _, v := range enrollmentIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:enrollmentIds*/?", strings.Repeat(",?", len(enrollmentIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:enrollmentIds*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetStudentEnrollmentHoldsByStudentEnrollmentIdsRow
+	for rows.Next() {
+		var i GetStudentEnrollmentHoldsByStudentEnrollmentIdsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Reason,
+			&i.EnrollmentID,
+			&i.StudentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStudentEnrollmentPeriodsByStudentEnrollmentIds = `-- name: GetStudentEnrollmentPeriodsByStudentEnrollmentIds :many
+SELECT id, start_date, end_date, withdrawal_reason, enrollment_id FROM student_enrollment_period
+WHERE enrollment_id IN (/*SLICE:enrollmentIds*/?)
+ORDER BY enrollment_id, start_date
+`
+
+// ============================== STUDENT_ENROLLMENT_PERIOD ==============================
+func (q *Queries) GetStudentEnrollmentPeriodsByStudentEnrollmentIds(ctx context.Context, enrollmentIds []int64) ([]StudentEnrollmentPeriod, error) {
+	query := getStudentEnrollmentPeriodsByStudentEnrollmentIds
+	var queryParams []interface{}
+	if len(enrollmentIds) > 0 {
+		for _, v := range enrollmentIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:enrollmentIds*/?", strings.Repeat(",?", len(enrollmentIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:enrollmentIds*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StudentEnrollmentPeriod
+	for rows.Next() {
+		var i StudentEnrollmentPeriod
+		if err := rows.Scan(
+			&i.ID,
+			&i.StartDate,
+			&i.EndDate,
+			&i.WithdrawalReason,
+			&i.EnrollmentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getStudentEnrollments = `-- name: GetStudentEnrollments :many
 SELECT se.id AS student_enrollment_id,
     se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
@@ -1807,6 +2639,97 @@ func (q *Queries) GetStudentEnrollments(ctx context.Context, arg GetStudentEnrol
 	return items, nil
 }
 
+const getStudentEnrollmentsActiveAtDateByClassId = `-- name: GetStudentEnrollmentsActiveAtDateByClassId :many
+SELECT se.id AS student_enrollment_id,
+    se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
+    class.id, class.transport_fee, class.teacher_id, class.course_id, class.auto_owe_attendance_token, class.is_deactivated, tsf.fee AS teacher_special_fee, course.id, course.default_fee, course.default_duration_minute, course.instrument_id, course.grade_id, instrument.id, instrument.name, grade.id, grade.name,
+    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail
+FROM student_enrollment AS se
+    JOIN student ON se.student_id = student.id
+    JOIN user AS user_student ON student.user_id = user_student.id
+    
+    JOIN class on se.class_id = class.id
+    JOIN course ON course_id = course.id
+    JOIN instrument ON course.instrument_id = instrument.id
+    JOIN grade ON course.grade_id = grade.id
+    
+    LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
+    LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
+    LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+WHERE se.class_id = ? AND EXISTS (
+    SELECT 1 FROM student_enrollment_period AS sep
+    WHERE sep.enrollment_id = se.id AND sep.start_date <= ? AND (sep.end_date IS NULL OR sep.end_date >= ?)
+)
+`
+
+type GetStudentEnrollmentsActiveAtDateByClassIdParams struct {
+	ClassID int64
+	Date    time.Time
+}
+
+type GetStudentEnrollmentsActiveAtDateByClassIdRow struct {
+	StudentEnrollmentID  int64
+	StudentID            int64
+	StudentUsername      string
+	StudentDetail        json.RawMessage
+	Class                Class
+	TeacherSpecialFee    sql.NullInt32
+	Course               Course
+	Instrument           Instrument
+	Grade                Grade
+	ClassTeacherID       sql.NullInt64
+	ClassTeacherUsername sql.NullString
+	ClassTeacherDetail   []byte
+}
+
+// GetStudentEnrollmentsActiveAtDateByClassId returns the enrollments having a period which covers the date, including the withdrawn (disabled) ones.
+func (q *Queries) GetStudentEnrollmentsActiveAtDateByClassId(ctx context.Context, arg GetStudentEnrollmentsActiveAtDateByClassIdParams) ([]GetStudentEnrollmentsActiveAtDateByClassIdRow, error) {
+	rows, err := q.db.QueryContext(ctx, getStudentEnrollmentsActiveAtDateByClassId, arg.ClassID, arg.Date, arg.Date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetStudentEnrollmentsActiveAtDateByClassIdRow
+	for rows.Next() {
+		var i GetStudentEnrollmentsActiveAtDateByClassIdRow
+		if err := rows.Scan(
+			&i.StudentEnrollmentID,
+			&i.StudentID,
+			&i.StudentUsername,
+			&i.StudentDetail,
+			&i.Class.ID,
+			&i.Class.TransportFee,
+			&i.Class.TeacherID,
+			&i.Class.CourseID,
+			&i.Class.AutoOweAttendanceToken,
+			&i.Class.IsDeactivated,
+			&i.TeacherSpecialFee,
+			&i.Course.ID,
+			&i.Course.DefaultFee,
+			&i.Course.DefaultDurationMinute,
+			&i.Course.InstrumentID,
+			&i.Course.GradeID,
+			&i.Instrument.ID,
+			&i.Instrument.Name,
+			&i.Grade.ID,
+			&i.Grade.Name,
+			&i.ClassTeacherID,
+			&i.ClassTeacherUsername,
+			&i.ClassTeacherDetail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getStudentEnrollmentsByClassId = `-- name: GetStudentEnrollmentsByClassId :many
 SELECT se.id AS student_enrollment_id,
     se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
@@ -1848,9 +2771,101 @@ func (q *Queries) GetStudentEnrollmentsByClassId(ctx context.Context, classID in
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetStudentEnrollmentsByClassIdRow
+	var items []GetStudentEnrollmentsByClassIdRow
+	for rows.Next() {
+		var i GetStudentEnrollmentsByClassIdRow
+		if err := rows.Scan(
+			&i.StudentEnrollmentID,
+			&i.StudentID,
+			&i.StudentUsername,
+			&i.StudentDetail,
+			&i.Class.ID,
+			&i.Class.TransportFee,
+			&i.Class.TeacherID,
+			&i.Class.CourseID,
+			&i.Class.AutoOweAttendanceToken,
+			&i.Class.IsDeactivated,
+			&i.TeacherSpecialFee,
+			&i.Course.ID,
+			&i.Course.DefaultFee,
+			&i.Course.DefaultDurationMinute,
+			&i.Course.InstrumentID,
+			&i.Course.GradeID,
+			&i.Instrument.ID,
+			&i.Instrument.Name,
+			&i.Grade.ID,
+			&i.Grade.Name,
+			&i.ClassTeacherID,
+			&i.ClassTeacherUsername,
+			&i.ClassTeacherDetail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStudentEnrollmentsByIds = `-- name: GetStudentEnrollmentsByIds :many
+SELECT se.id AS student_enrollment_id,
+    se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
+    class.id, class.transport_fee, class.teacher_id, class.course_id, class.auto_owe_attendance_token, class.is_deactivated, tsf.fee AS teacher_special_fee, course.id, course.default_fee, course.default_duration_minute, course.instrument_id, course.grade_id, instrument.id, instrument.name, grade.id, grade.name,
+    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail
+FROM student_enrollment AS se
+    JOIN student ON se.student_id = student.id
+    JOIN user AS user_student ON student.user_id = user_student.id
+    
+    JOIN class on se.class_id = class.id
+    JOIN course ON course_id = course.id
+    JOIN instrument ON course.instrument_id = instrument.id
+    JOIN grade ON course.grade_id = grade.id
+    
+    LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
+    LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
+    LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+WHERE se.is_deleted = 0 AND se.id IN (/*SLICE:ids*/?)
+`
+
+type GetStudentEnrollmentsByIdsRow struct {
+	StudentEnrollmentID  int64
+	StudentID            int64
+	StudentUsername      string
+	StudentDetail        json.RawMessage
+	Class                Class
+	TeacherSpecialFee    sql.NullInt32
+	Course               Course
+	Instrument           Instrument
+	Grade                Grade
+	ClassTeacherID       sql.NullInt64
+	ClassTeacherUsername sql.NullString
+	ClassTeacherDetail   []byte
+}
+
+func (q *Queries) GetStudentEnrollmentsByIds(ctx context.Context, ids []int64) ([]GetStudentEnrollmentsByIdsRow, error) {
+	query := getStudentEnrollmentsByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetStudentEnrollmentsByIdsRow
 	for rows.Next() {
-		var i GetStudentEnrollmentsByClassIdRow
+		var i GetStudentEnrollmentsByIdsRow
 		if err := rows.Scan(
 			&i.StudentEnrollmentID,
 			&i.StudentID,
@@ -1889,7 +2904,7 @@ func (q *Queries) GetStudentEnrollmentsByClassId(ctx context.Context, classID in
 	return items, nil
 }
 
-const getStudentEnrollmentsByIds = `-- name: GetStudentEnrollmentsByIds :many
+const getStudentEnrollmentsIncludingWithdrawnByStudentId = `-- name: GetStudentEnrollmentsIncludingWithdrawnByStudentId :many
 SELECT se.id AS student_enrollment_id,
     se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
     class.id, class.transport_fee, class.teacher_id, class.course_id, class.auto_owe_attendance_token, class.is_deactivated, tsf.fee AS teacher_special_fee, course.id, course.default_fee, course.default_duration_minute, course.instrument_id, course.grade_id, instrument.id, instrument.name, grade.id, grade.name,
@@ -1906,10 +2921,11 @@ FROM student_enrollment AS se
     LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
     LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
     LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
-WHERE se.is_deleted = 0 AND se.id IN (/*SLICE:ids*/?)
+WHERE se.student_id = ?
+ORDER BY se.id
 `
 
-type GetStudentEnrollmentsByIdsRow struct {
+type GetStudentEnrollmentsIncludingWithdrawnByStudentIdRow struct {
 	StudentEnrollmentID  int64
 	StudentID            int64
 	StudentUsername      string
@@ -1924,25 +2940,15 @@ type GetStudentEnrollmentsByIdsRow struct {
 	ClassTeacherDetail   []byte
 }
 
-func (q *Queries) GetStudentEnrollmentsByIds(ctx context.Context, ids []int64) ([]GetStudentEnrollmentsByIdsRow, error) {
-	query := getStudentEnrollmentsByIds
-	var queryParams []interface{}
-	if len(ids) > 0 {
-		for _, v := range ids {
-			queryParams = append(queryParams, v)
-		}
-		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
-	} else {
-		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
-	}
-	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+func (q *Queries) GetStudentEnrollmentsIncludingWithdrawnByStudentId(ctx context.Context, studentID int64) ([]GetStudentEnrollmentsIncludingWithdrawnByStudentIdRow, error) {
+	rows, err := q.db.QueryContext(ctx, getStudentEnrollmentsIncludingWithdrawnByStudentId, studentID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetStudentEnrollmentsByIdsRow
+	var items []GetStudentEnrollmentsIncludingWithdrawnByStudentIdRow
 	for rows.Next() {
-		var i GetStudentEnrollmentsByIdsRow
+		var i GetStudentEnrollmentsIncludingWithdrawnByStudentIdRow
 		if err := rows.Scan(
 			&i.StudentEnrollmentID,
 			&i.StudentID,
@@ -2162,6 +3168,26 @@ func (q *Queries) GetTeacherByUserId(ctx context.Context, userID int64) (GetTeac
 	return i, err
 }
 
+const getTeacherSpecialFeeAtDate = `-- name: GetTeacherSpecialFeeAtDate :one
+SELECT fee FROM teacher_special_fee_history
+WHERE teacher_id = ? AND course_id = ? AND effective_from <= ?
+ORDER BY effective_from DESC, id DESC
+LIMIT 1
+`
+
+type GetTeacherSpecialFeeAtDateParams struct {
+	TeacherID     int64
+	CourseID      int64
+	EffectiveFrom time.Time
+}
+
+func (q *Queries) GetTeacherSpecialFeeAtDate(ctx context.Context, arg GetTeacherSpecialFeeAtDateParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getTeacherSpecialFeeAtDate, arg.TeacherID, arg.CourseID, arg.EffectiveFrom)
+	var fee int32
+	err := row.Scan(&fee)
+	return fee, err
+}
+
 const getTeacherSpecialFeeById = `-- name: GetTeacherSpecialFeeById :one
 SELECT teacher_special_fee.id AS teacher_special_fee_id, fee,
     teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
@@ -2208,6 +3234,41 @@ func (q *Queries) GetTeacherSpecialFeeById(ctx context.Context, id int64) (GetTe
 	return i, err
 }
 
+const getTeacherSpecialFeeHistoriesByCourseId = `-- name: GetTeacherSpecialFeeHistoriesByCourseId :many
+SELECT id, fee, effective_from, teacher_id, course_id FROM teacher_special_fee_history
+WHERE course_id = ?
+ORDER BY teacher_id, effective_from DESC, id DESC
+`
+
+func (q *Queries) GetTeacherSpecialFeeHistoriesByCourseId(ctx context.Context, courseID int64) ([]TeacherSpecialFeeHistory, error) {
+	rows, err := q.db.QueryContext(ctx, getTeacherSpecialFeeHistoriesByCourseId, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TeacherSpecialFeeHistory
+	for rows.Next() {
+		var i TeacherSpecialFeeHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.Fee,
+			&i.EffectiveFrom,
+			&i.TeacherID,
+			&i.CourseID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTeacherSpecialFees = `-- name: GetTeacherSpecialFees :many
 SELECT teacher_special_fee.id AS teacher_special_fee_id, fee,
     teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
@@ -2412,6 +3473,77 @@ func (q *Queries) GetTeacherSpecialFeesByTeacherId(ctx context.Context, teacherI
 	return items, nil
 }
 
+const getTeacherSubstitutionSummaries = `-- name: GetTeacherSubstitutionSummaries :many
+WITH substitution AS (
+    SELECT teacher_id, original_teacher_id, used_student_token_quota
+    FROM attendance
+    WHERE
+        is_substitute = 1
+        AND (attendance.date >= ? AND attendance.date <= ?)
+)
+SELECT teacher.id AS teacher_id, user.username AS teacher_username, user.user_detail AS teacher_detail,
+    COALESCE(given.total, 0) AS total_given, ROUND(COALESCE(given.total_quota, 0), 3) AS total_given_quota,
+    COALESCE(received.total, 0) AS total_received, ROUND(COALESCE(received.total_quota, 0), 3) AS total_received_quota
+FROM teacher
+    JOIN user ON teacher.user_id = user.id
+    LEFT JOIN (
+        SELECT teacher_id, Count(*) AS total, sum(used_student_token_quota) AS total_quota FROM substitution GROUP BY teacher_id
+    ) AS given ON teacher.id = given.teacher_id
+    LEFT JOIN (
+        SELECT original_teacher_id, Count(*) AS total, sum(used_student_token_quota) AS total_quota FROM substitution GROUP BY original_teacher_id
+    ) AS received ON teacher.id = received.original_teacher_id
+WHERE given.teacher_id IS NOT NULL OR received.original_teacher_id IS NOT NULL
+ORDER BY user.username, teacher.id
+`
+
+type GetTeacherSubstitutionSummariesParams struct {
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+type GetTeacherSubstitutionSummariesRow struct {
+	TeacherID          int64
+	TeacherUsername    string
+	TeacherDetail      json.RawMessage
+	TotalGiven         int64
+	TotalGivenQuota    float64
+	TotalReceived      int64
+	TotalReceivedQuota float64
+}
+
+// GetTeacherSubstitutionSummaries returns, for each teacher, the substitutions given (teaching on behalf of the original teacher) & received (being substituted by another teacher).
+// ============================== TEACHER_SUBSTITUTION ==============================
+func (q *Queries) GetTeacherSubstitutionSummaries(ctx context.Context, arg GetTeacherSubstitutionSummariesParams) ([]GetTeacherSubstitutionSummariesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTeacherSubstitutionSummaries, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTeacherSubstitutionSummariesRow
+	for rows.Next() {
+		var i GetTeacherSubstitutionSummariesRow
+		if err := rows.Scan(
+			&i.TeacherID,
+			&i.TeacherUsername,
+			&i.TeacherDetail,
+			&i.TotalGiven,
+			&i.TotalGivenQuota,
+			&i.TotalReceived,
+			&i.TotalReceivedQuota,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTeachers = `-- name: GetTeachers :many
 SELECT teacher.id, user.id AS user_id, username, email, user_detail, privilege_type, is_deactivated, created_at
 FROM teacher JOIN user ON teacher.user_id = user.id
@@ -2678,6 +3810,80 @@ func (q *Queries) InsertCourse(ctx context.Context, arg InsertCourseParams) (int
 	return result.LastInsertId()
 }
 
+const insertCourseFeeHistory = `-- name: InsertCourseFeeHistory :execlastid
+INSERT INTO course_fee_history (
+    fee, effective_from, course_id
+) VALUES (
+    ?, ?, ?
+)
+`
+
+type InsertCourseFeeHistoryParams struct {
+	Fee           int32
+	EffectiveFrom time.Time
+	CourseID      int64
+}
+
+func (q *Queries) InsertCourseFeeHistory(ctx context.Context, arg InsertCourseFeeHistoryParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertCourseFeeHistory, arg.Fee, arg.EffectiveFrom, arg.CourseID)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const insertFamily = `-- name: InsertFamily :execlastid
+INSERT INTO family (
+    name
+) VALUES (
+    ?
+)
+`
+
+func (q *Queries) InsertFamily(ctx context.Context, name string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertFamily, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const insertFamilyGuardian = `-- name: InsertFamilyGuardian :exec
+INSERT INTO family_guardian (
+    family_id, user_id
+) VALUES (
+    ?, ?
+)
+`
+
+type InsertFamilyGuardianParams struct {
+	FamilyID int64
+	UserID   int64
+}
+
+func (q *Queries) InsertFamilyGuardian(ctx context.Context, arg InsertFamilyGuardianParams) error {
+	_, err := q.db.ExecContext(ctx, insertFamilyGuardian, arg.FamilyID, arg.UserID)
+	return err
+}
+
+const insertFamilyStudent = `-- name: InsertFamilyStudent :exec
+INSERT INTO family_student (
+    family_id, student_id
+) VALUES (
+    ?, ?
+)
+`
+
+type InsertFamilyStudentParams struct {
+	FamilyID  int64
+	StudentID int64
+}
+
+func (q *Queries) InsertFamilyStudent(ctx context.Context, arg InsertFamilyStudentParams) error {
+	_, err := q.db.ExecContext(ctx, insertFamilyStudent, arg.FamilyID, arg.StudentID)
+	return err
+}
+
 const insertGrade = `-- name: InsertGrade :execlastid
 INSERT INTO grade ( name ) VALUES ( ? )
 `
@@ -2702,6 +3908,35 @@ func (q *Queries) InsertInstrument(ctx context.Context, name string) (int64, err
 	return result.LastInsertId()
 }
 
+const insertRemovedTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds = `-- name: InsertRemovedTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds :exec
+INSERT INTO teacher_special_fee_history (fee, effective_from, teacher_id, course_id)
+SELECT 0, ?, teacher_id, course_id FROM teacher_special_fee
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+type InsertRemovedTeacherSpecialFeeHistoriesByTeacherSpecialFeeIdsParams struct {
+	EffectiveFrom time.Time
+	Ids           []int64
+}
+
+// InsertRemovedTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds records the removal (fee = 0) of the teacher_special_fees into the history.
+// This must be executed before deleting the teacher_special_fees.
+func (q *Queries) InsertRemovedTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds(ctx context.Context, arg InsertRemovedTeacherSpecialFeeHistoriesByTeacherSpecialFeeIdsParams) error {
+	query := insertRemovedTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.EffectiveFrom)
+	if len(arg.Ids) > 0 {
+		for _, v := range arg.Ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(arg.Ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
 const insertStudent = `-- name: InsertStudent :execlastid
 INSERT INTO student ( user_id ) VALUES ( ? )
 `
@@ -2714,7 +3949,7 @@ func (q *Queries) InsertStudent(ctx context.Context, userID int64) (int64, error
 	return result.LastInsertId()
 }
 
-const insertStudentEnrollment = `-- name: InsertStudentEnrollment :exec
+const insertStudentEnrollment = `-- name: InsertStudentEnrollment :execlastid
 INSERT INTO student_enrollment (
     student_id, class_id
 ) VALUES (
@@ -2727,9 +3962,61 @@ type InsertStudentEnrollmentParams struct {
 	ClassID   int64
 }
 
-func (q *Queries) InsertStudentEnrollment(ctx context.Context, arg InsertStudentEnrollmentParams) error {
-	_, err := q.db.ExecContext(ctx, insertStudentEnrollment, arg.StudentID, arg.ClassID)
-	return err
+func (q *Queries) InsertStudentEnrollment(ctx context.Context, arg InsertStudentEnrollmentParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertStudentEnrollment, arg.StudentID, arg.ClassID)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const insertStudentEnrollmentHold = `-- name: InsertStudentEnrollmentHold :execlastid
+INSERT INTO student_enrollment_hold (
+    start_date, end_date, reason, enrollment_id
+) VALUES (
+    ?, ?, ?, ?
+)
+`
+
+type InsertStudentEnrollmentHoldParams struct {
+	StartDate    time.Time
+	EndDate      time.Time
+	Reason       string
+	EnrollmentID int64
+}
+
+func (q *Queries) InsertStudentEnrollmentHold(ctx context.Context, arg InsertStudentEnrollmentHoldParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertStudentEnrollmentHold,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Reason,
+		arg.EnrollmentID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const insertStudentEnrollmentPeriod = `-- name: InsertStudentEnrollmentPeriod :execlastid
+INSERT INTO student_enrollment_period (
+    start_date, enrollment_id
+) VALUES (
+    ?, ?
+)
+`
+
+type InsertStudentEnrollmentPeriodParams struct {
+	StartDate    time.Time
+	EnrollmentID int64
+}
+
+func (q *Queries) InsertStudentEnrollmentPeriod(ctx context.Context, arg InsertStudentEnrollmentPeriodParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertStudentEnrollmentPeriod, arg.StartDate, arg.EnrollmentID)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
 }
 
 const insertTeacher = `-- name: InsertTeacher :execlastid
@@ -2766,6 +4053,62 @@ func (q *Queries) InsertTeacherSpecialFee(ctx context.Context, arg InsertTeacher
 	return result.LastInsertId()
 }
 
+const insertTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds = `-- name: InsertTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds :exec
+INSERT INTO teacher_special_fee_history (fee, effective_from, teacher_id, course_id)
+SELECT fee, ?, teacher_id, course_id FROM teacher_special_fee
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+type InsertTeacherSpecialFeeHistoriesByTeacherSpecialFeeIdsParams struct {
+	EffectiveFrom time.Time
+	Ids           []int64
+}
+
+// InsertTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds records the current fee of the teacher_special_fees into the history.
+func (q *Queries) InsertTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds(ctx context.Context, arg InsertTeacherSpecialFeeHistoriesByTeacherSpecialFeeIdsParams) error {
+	query := insertTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.EffectiveFrom)
+	if len(arg.Ids) > 0 {
+		for _, v := range arg.Ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(arg.Ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
+const insertTeacherSpecialFeeHistory = `-- name: InsertTeacherSpecialFeeHistory :execlastid
+INSERT INTO teacher_special_fee_history (
+    fee, effective_from, teacher_id, course_id
+) VALUES (
+    ?, ?, ?, ?
+)
+`
+
+type InsertTeacherSpecialFeeHistoryParams struct {
+	Fee           int32
+	EffectiveFrom time.Time
+	TeacherID     int64
+	CourseID      int64
+}
+
+func (q *Queries) InsertTeacherSpecialFeeHistory(ctx context.Context, arg InsertTeacherSpecialFeeHistoryParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertTeacherSpecialFeeHistory,
+		arg.Fee,
+		arg.EffectiveFrom,
+		arg.TeacherID,
+		arg.CourseID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
 const isUserIdInvolvedInAttendanceId = `-- name: IsUserIdInvolvedInAttendanceId :one
 SELECT EXISTS(
     -- check whether the user is enrolled in the attendance's class as a student
@@ -2836,6 +4179,26 @@ func (q *Queries) IsUserIdInvolvedInClassId(ctx context.Context, arg IsUserIdInv
 	return is_involved, err
 }
 
+const syncCourseDefaultFeesWithFeeHistories = `-- name: SyncCourseDefaultFeesWithFeeHistories :execrows
+UPDATE course
+    JOIN (
+        SELECT course_id, fee, ROW_NUMBER() OVER (PARTITION BY course_id ORDER BY effective_from DESC, id DESC) AS row_num
+        FROM course_fee_history
+        WHERE effective_from <= ?
+    ) AS cfh ON (course.id = cfh.course_id AND cfh.row_num = 1)
+SET course.default_fee = cfh.fee
+WHERE course.default_fee <> cfh.fee
+`
+
+// SyncCourseDefaultFeesWithFeeHistories sets `course`.`default_fee` to the price in force at the given time, i.e. when a scheduled price takes effect.
+func (q *Queries) SyncCourseDefaultFeesWithFeeHistories(ctx context.Context, at time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, syncCourseDefaultFeesWithFeeHistories, at)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateClass = `-- name: UpdateClass :exec
 UPDATE class SET transport_fee = ?, teacher_id = ?, course_id = ?, auto_owe_attendance_token = ?, is_deactivated = ?
 WHERE id = ?
@@ -2945,6 +4308,21 @@ func (q *Queries) UpdateCourseInfo(ctx context.Context, arg UpdateCourseInfoPara
 	return err
 }
 
+const updateFamily = `-- name: UpdateFamily :exec
+UPDATE family SET name = ?
+WHERE id = ?
+`
+
+type UpdateFamilyParams struct {
+	Name string
+	ID   int64
+}
+
+func (q *Queries) UpdateFamily(ctx context.Context, arg UpdateFamilyParams) error {
+	_, err := q.db.ExecContext(ctx, updateFamily, arg.Name, arg.ID)
+	return err
+}
+
 const updateGrade = `-- name: UpdateGrade :exec
 UPDATE grade SET name = ?
 WHERE id = ?
@@ -2975,6 +4353,28 @@ func (q *Queries) UpdateInstrument(ctx context.Context, arg UpdateInstrumentPara
 	return err
 }
 
+const updateStudentEnrollmentHold = `-- name: UpdateStudentEnrollmentHold :exec
+UPDATE student_enrollment_hold SET start_date = ?, end_date = ?, reason = ?
+WHERE id = ?
+`
+
+type UpdateStudentEnrollmentHoldParams struct {
+	StartDate time.Time
+	EndDate   time.Time
+	Reason    string
+	ID        int64
+}
+
+func (q *Queries) UpdateStudentEnrollmentHold(ctx context.Context, arg UpdateStudentEnrollmentHoldParams) error {
+	_, err := q.db.ExecContext(ctx, updateStudentEnrollmentHold,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Reason,
+		arg.ID,
+	)
+	return err
+}
+
 const updateTeacherSpecialFee = `-- name: UpdateTeacherSpecialFee :exec
 UPDATE teacher_special_fee SET fee = ?
 WHERE id = ?
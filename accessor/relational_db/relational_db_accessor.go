@@ -64,6 +64,27 @@ func (q MySQLQueries) ExecuteInTransaction(ctx context.Context, wrappedFunc func
 	return nil
 }
 
+// ExecuteInDryRunTransaction is similar to ExecuteInTransaction, but the transaction is always rolled back, even when wrappedFunc succeeds.
+// This is useful for previewing the effect of write operations without persisting them.
+//
+// Unlike ExecuteInTransaction, an existing sql.Tx inside the Context can't be reused, as rolling it back would also discard the caller's changes.
+func (q MySQLQueries) ExecuteInDryRunTransaction(ctx context.Context, wrappedFunc func(context.Context, *mysql.Queries) error) error {
+	if existingTx := GetSQLTx(ctx); existingTx != nil {
+		return fmt.Errorf("dry-run transaction can't be nested inside an existing transaction")
+	}
+
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("db.BeginTx(): %w", err)
+	}
+	defer tx.Rollback()
+
+	newCtx := NewContextWithSQLTx(ctx, tx)
+	qtx := q.WithTxWrappedError(tx)
+
+	return wrappedFunc(newCtx, qtx)
+}
+
 type sqlTxKey struct{}
 
 // NewContextWithSQLTx copies a context, adds a Go's sql.Tx into it, and returns the new context.
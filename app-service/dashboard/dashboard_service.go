@@ -2,10 +2,15 @@ package dashboard
 
 import (
 	"context"
+	"time"
+
 	"sonamusica-backend/app-service/entity"
 	"sonamusica-backend/app-service/util"
 )
 
+// DiscountLabel_Manual labels the discounts typed in by the staff, i.e. not coming from any DiscountRule.
+const DiscountLabel_Manual = "Manual"
+
 type MonthyExpense_GroupBy string
 type MonthyIncome_GroupBy string
 
@@ -37,15 +42,42 @@ type MonthlySummaryResultItem struct {
 	Percentage float32 `json:"percentage"`
 }
 
+// ReceivableReport lists the StudentEnrollments which owe the school, i.e. having StudentLearningTokens with negative quota.
+// This happens when the class has AutoOweAttendanceToken enabled, and the student keeps attending without paying.
+type ReceivableReport struct {
+	Items          []ReceivableReportItem `json:"items"`
+	TotalOwedValue int64                  `json:"totalOwedValue"`
+}
+type ReceivableReportItem struct {
+	StudentEnrollment entity.StudentEnrollment `json:"studentEnrollment"`
+	// OwedQuota is the sum of the negative quota of the StudentEnrollment's StudentLearningTokens, e.g. -1.5
+	OwedQuota float64 `json:"owedQuota"`
+	// OwedValue is the sum of each StudentLearningToken's -quota * (CourseFeeQuarterValue + TransportFeeQuarterValue)
+	OwedValue int64 `json:"owedValue"`
+	// OldestUnpaidAttendanceDate is the date of the earliest attendance which isn't covered by any paid quota
+	OldestUnpaidAttendanceDate *time.Time `json:"oldestUnpaidAttendanceDate,omitempty"`
+	// DebtAgeDays is the number of days since OldestUnpaidAttendanceDate
+	DebtAgeDays     int        `json:"debtAgeDays"`
+	LastPaymentDate *time.Time `json:"lastPaymentDate,omitempty"`
+}
+
 type DashboardService interface {
 	GetExpenseOverview(ctx context.Context, spec GetExpenseOverviewSpec) (OverviewResult, error)
 	GetExpenseMonthlySummary(ctx context.Context, spec GetExpenseMontlySummarySpec) (MonthlySummaryResult, error)
 
+	// GetIncomeOverview & GetIncomeMonthlySummary subtract the refunded EnrollmentPayments on the month of the refund, not on the month of the EnrollmentPayment.
 	GetIncomeOverview(ctx context.Context, spec GetIncomeOverviewSpec) (OverviewResult, error)
 	GetIncomeMonthlySummary(ctx context.Context, spec GetIncomeMontlySummarySpec) (MonthlySummaryResult, error)
 
 	GetNetIncomeOverview(ctx context.Context, spec GetNetIncomeOverviewSpec) (OverviewResult, error)
 
+	// GetDiscountMonthlySummary returns the discount cost grouped by the applied DiscountRule (promotion).
+	// The discounts which aren't from any DiscountRule are grouped as DiscountLabel_Manual.
+	GetDiscountMonthlySummary(ctx context.Context, spec GetDiscountMonthlySummarySpec) (MonthlySummaryResult, error)
+
+	// GetReceivableReport returns the StudentEnrollments having negative StudentLearningToken quota, ordered by the largest OwedValue first.
+	GetReceivableReport(ctx context.Context, spec GetReceivableReportSpec) (ReceivableReport, error)
+
 	GetTeacherPaymentDetails(ctx context.Context)
 }
 
@@ -76,3 +108,12 @@ type GetIncomeMontlySummarySpec struct {
 type GetNetIncomeOverviewSpec struct {
 	util.TimeSpec
 }
+
+type GetDiscountMonthlySummarySpec struct {
+	util.TimeSpec
+}
+
+type GetReceivableReportSpec struct {
+	TeacherIDs    []entity.TeacherID
+	InstrumentIDs []entity.InstrumentID
+}
@@ -3,7 +3,11 @@ package impl
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"math"
+	"sort"
+	"time"
 
 	"sonamusica-backend/accessor/relational_db"
 	"sonamusica-backend/accessor/relational_db/mysql"
@@ -162,8 +166,19 @@ func (s dashboardServiceImpl) GetIncomeOverview(ctx context.Context, spec dashbo
 	if err != nil {
 		return dashboard.OverviewResult{}, fmt.Errorf("mySQLQueries.GetIncomeOverview(): %w", err)
 	}
+	refundOverviewRows, err := s.mySQLQueries.GetRefundOverview(ctx, mysql.GetRefundOverviewParams{
+		StartDate:           timeFilter.StartDatetime,
+		EndDate:             timeFilter.EndDatetime,
+		StudentIds:          studentIDs,
+		UseStudentFilter:    useStudentFilter,
+		InstrumentIds:       instrumentIDs,
+		UseInstrumentFilter: useInstrumentFilter,
+	})
+	if err != nil {
+		return dashboard.OverviewResult{}, fmt.Errorf("mySQLQueries.GetRefundOverview(): %w", err)
+	}
 
-	overviewResultItems := NewOverviewResultItems_FromMySQLIncomeOverview(incomeOverviewRows)
+	overviewResultItems := NewOverviewResultItems_FromMySQLIncomeOverview(incomeOverviewRows, refundOverviewRows)
 	CalculateOverviewResultItemsPercentage(&overviewResultItems)
 
 	return dashboard.OverviewResult{
@@ -204,7 +219,18 @@ func (s dashboardServiceImpl) GetIncomeMonthlySummary(ctx context.Context, spec
 		if err != nil {
 			return dashboard.MonthlySummaryResult{}, fmt.Errorf("mySQLQueries.GetIncomeMonthlySummaryGroupedByTeacher(): %w", err)
 		}
-		monthlySummaryResultItems = NewMSResultItems_FromMySQLIncomeMSByTeacher(monthlySummaryRows)
+		refundMonthlySummaryRows, err := s.mySQLQueries.GetRefundMonthlySummaryGroupedByStudent(ctx, mysql.GetRefundMonthlySummaryGroupedByStudentParams{
+			StartDate:           timeFilter.StartDatetime,
+			EndDate:             timeFilter.EndDatetime,
+			StudentIds:          studentIDs,
+			UseStudentFilter:    useTeacherFilter,
+			InstrumentIds:       instrumentIDs,
+			UseInstrumentFilter: useInstrumentFilter,
+		})
+		if err != nil {
+			return dashboard.MonthlySummaryResult{}, fmt.Errorf("mySQLQueries.GetRefundMonthlySummaryGroupedByStudent(): %w", err)
+		}
+		monthlySummaryResultItems = NewMSResultItems_FromMySQLIncomeMSByTeacher(monthlySummaryRows, refundMonthlySummaryRows)
 
 	case dashboard.MonthyIncome_GroupBy_Instrument:
 		monthlySummaryRows, err := s.mySQLQueries.GetIncomeMonthlySummaryGroupedByInstrument(ctx, mysql.GetIncomeMonthlySummaryGroupedByInstrumentParams{
@@ -218,7 +244,18 @@ func (s dashboardServiceImpl) GetIncomeMonthlySummary(ctx context.Context, spec
 		if err != nil {
 			return dashboard.MonthlySummaryResult{}, fmt.Errorf("mySQLQueries.GetIncomeMonthlySummaryGroupedByInstrument(): %w", err)
 		}
-		monthlySummaryResultItems = NewMSResultItems_FromMySQLIncomeMSByInstrument(monthlySummaryRows)
+		refundMonthlySummaryRows, err := s.mySQLQueries.GetRefundMonthlySummaryGroupedByInstrument(ctx, mysql.GetRefundMonthlySummaryGroupedByInstrumentParams{
+			StartDate:           timeFilter.StartDatetime,
+			EndDate:             timeFilter.EndDatetime,
+			StudentIds:          studentIDs,
+			UseStudentFilter:    useTeacherFilter,
+			InstrumentIds:       instrumentIDs,
+			UseInstrumentFilter: useInstrumentFilter,
+		})
+		if err != nil {
+			return dashboard.MonthlySummaryResult{}, fmt.Errorf("mySQLQueries.GetRefundMonthlySummaryGroupedByInstrument(): %w", err)
+		}
+		monthlySummaryResultItems = NewMSResultItems_FromMySQLIncomeMSByInstrument(monthlySummaryRows, refundMonthlySummaryRows)
 
 	default:
 		return dashboard.MonthlySummaryResult{}, fmt.Errorf("invalid 'GroupBy' option: %s", spec.GroupBy)
@@ -235,6 +272,164 @@ func (s dashboardServiceImpl) GetNetIncomeOverview(ctx context.Context, spec das
 	return dashboard.OverviewResult{}, nil
 }
 
+func (s dashboardServiceImpl) GetDiscountMonthlySummary(ctx context.Context, spec dashboard.GetDiscountMonthlySummarySpec) (dashboard.MonthlySummaryResult, error) {
+	timeFilter := spec.TimeSpec
+	err := timeFilter.ValidateZeroValues()
+	if err != nil {
+		return dashboard.MonthlySummaryResult{}, fmt.Errorf("ValidateZeroValues(): %v", err)
+	}
+
+	monthlySummaryRows, err := s.mySQLQueries.GetDiscountMonthlySummaryGroupedByDiscountRule(ctx, mysql.GetDiscountMonthlySummaryGroupedByDiscountRuleParams{
+		StartDate: timeFilter.StartDatetime,
+		EndDate:   timeFilter.EndDatetime,
+	})
+	if err != nil {
+		return dashboard.MonthlySummaryResult{}, fmt.Errorf("mySQLQueries.GetDiscountMonthlySummaryGroupedByDiscountRule(): %w", err)
+	}
+	totalDiscountFeeValue, err := s.mySQLQueries.GetTotalDiscountFeeValue(ctx, mysql.GetTotalDiscountFeeValueParams{
+		StartDate: timeFilter.StartDatetime,
+		EndDate:   timeFilter.EndDatetime,
+	})
+	if err != nil {
+		return dashboard.MonthlySummaryResult{}, fmt.Errorf("mySQLQueries.GetTotalDiscountFeeValue(): %w", err)
+	}
+
+	monthlySummaryResultItems := NewMSResultItems_FromMySQLDiscountMSByDiscountRule(monthlySummaryRows, totalDiscountFeeValue)
+
+	CalculateMonthlySummaryResultItemsPercentage(&monthlySummaryResultItems)
+
+	return dashboard.MonthlySummaryResult{
+		Data: monthlySummaryResultItems,
+	}, nil
+}
+
+func (s dashboardServiceImpl) GetReceivableReport(ctx context.Context, spec dashboard.GetReceivableReportSpec) (dashboard.ReceivableReport, error) {
+	teacherIDs := make([]sql.NullInt64, 0)
+	instrumentIDs := make([]int64, 0)
+	for _, teacherID := range spec.TeacherIDs {
+		teacherIDs = append(teacherIDs, sql.NullInt64{Int64: int64(teacherID), Valid: true})
+	}
+	for _, instrumentID := range spec.InstrumentIDs {
+		instrumentIDs = append(instrumentIDs, int64(instrumentID))
+	}
+
+	useTeacherFilter := len(teacherIDs) > 0
+	useInstrumentFilter := len(instrumentIDs) > 0
+
+	now := time.Now().UTC()
+	report := dashboard.ReceivableReport{
+		Items: make([]dashboard.ReceivableReportItem, 0),
+	}
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		sltRows, err := qtx.GetNegativeQuotaSLTs(newCtx, mysql.GetNegativeQuotaSLTsParams{
+			TeacherIds:          teacherIDs,
+			UseTeacherFilter:    useTeacherFilter,
+			InstrumentIds:       instrumentIDs,
+			UseInstrumentFilter: useInstrumentFilter,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetNegativeQuotaSLTs(): %w", err)
+		}
+		if len(sltRows) == 0 {
+			return nil
+		}
+
+		tokenIDs := make([]sql.NullInt64, 0, len(sltRows))
+		for _, sltRow := range sltRows {
+			tokenIDs = append(tokenIDs, sql.NullInt64{Int64: sltRow.ID, Valid: true})
+		}
+		attendanceRows, err := qtx.GetAttendanceQuotaUsagesByTokenIds(newCtx, tokenIDs)
+		if err != nil {
+			return fmt.Errorf("qtx.GetAttendanceQuotaUsagesByTokenIds(): %w", err)
+		}
+		tokenIDToAttendanceRows := make(map[int64][]mysql.GetAttendanceQuotaUsagesByTokenIdsRow, len(sltRows))
+		for _, attendanceRow := range attendanceRows {
+			tokenIDToAttendanceRows[attendanceRow.TokenID.Int64] = append(tokenIDToAttendanceRows[attendanceRow.TokenID.Int64], attendanceRow)
+		}
+
+		// the SLTs are ordered by enrollment_id, so we can group them by simply comparing with the previous row
+		for _, sltRow := range sltRows {
+			if len(report.Items) == 0 || int64(report.Items[len(report.Items)-1].StudentEnrollment.StudentEnrollmentID) != sltRow.EnrollmentID {
+				item, err := s.newReceivableReportItem(newCtx, qtx, entity.StudentEnrollmentID(sltRow.EnrollmentID))
+				if err != nil {
+					return fmt.Errorf("newReceivableReportItem(): %w", err)
+				}
+				report.Items = append(report.Items, item)
+			}
+			item := &report.Items[len(report.Items)-1]
+
+			item.OwedQuota += sltRow.Quota
+			item.OwedValue += int64(math.Round(-sltRow.Quota * float64(sltRow.CourseFeeQuarterValue+sltRow.TransportFeeQuarterValue)))
+
+			oldestUnpaidAttendanceDate := findOldestUnpaidAttendanceDate(sltRow.Quota, tokenIDToAttendanceRows[sltRow.ID])
+			if oldestUnpaidAttendanceDate != nil && (item.OldestUnpaidAttendanceDate == nil || oldestUnpaidAttendanceDate.Before(*item.OldestUnpaidAttendanceDate)) {
+				item.OldestUnpaidAttendanceDate = oldestUnpaidAttendanceDate
+				item.DebtAgeDays = int(now.Sub(*oldestUnpaidAttendanceDate).Hours() / 24)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return dashboard.ReceivableReport{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		return report.Items[i].OwedValue > report.Items[j].OwedValue
+	})
+	for _, item := range report.Items {
+		report.TotalOwedValue += item.OwedValue
+	}
+
+	return report, nil
+}
+
+func (s dashboardServiceImpl) newReceivableReportItem(ctx context.Context, qtx *mysql.Queries, studentEnrollmentID entity.StudentEnrollmentID) (dashboard.ReceivableReportItem, error) {
+	studentEnrollment, err := s.entityService.GetStudentEnrollmentById(ctx, studentEnrollmentID)
+	if err != nil {
+		return dashboard.ReceivableReportItem{}, fmt.Errorf("entityService.GetStudentEnrollmentById(): %w", err)
+	}
+
+	latestPaymentDate, err := qtx.GetLatestEnrollmentPaymentDateByStudentEnrollmentId(ctx, sql.NullInt64{Int64: int64(studentEnrollmentID), Valid: true})
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return dashboard.ReceivableReportItem{}, fmt.Errorf("qtx.GetLatestEnrollmentPaymentDateByStudentEnrollmentId(): %w", err)
+		}
+	}
+
+	var lastPaymentDate *time.Time = nil
+	if latestPaymentDate != nil {
+		temp := latestPaymentDate.(time.Time)
+		lastPaymentDate = &temp
+	}
+
+	return dashboard.ReceivableReportItem{
+		StudentEnrollment: studentEnrollment,
+		LastPaymentDate:   lastPaymentDate,
+	}, nil
+}
+
+// findOldestUnpaidAttendanceDate returns the date of the first attendance (in chronological order) which uses more than the paid quota of a negative-quota SLT.
+//
+// The paid quota is the SLT's current quota plus all of its used quota, e.g. an SLT with 4 paid quota & 5 attendances (1 quota each) has -1 quota left, so the 5th attendance is the oldest unpaid one.
+func findOldestUnpaidAttendanceDate(sltQuota float64, attendanceRows []mysql.GetAttendanceQuotaUsagesByTokenIdsRow) *time.Time {
+	paidQuota := sltQuota
+	for _, attendanceRow := range attendanceRows {
+		paidQuota += attendanceRow.UsedStudentTokenQuota
+	}
+
+	usedQuota := 0.0
+	for _, attendanceRow := range attendanceRows {
+		usedQuota += attendanceRow.UsedStudentTokenQuota
+		// use a small tolerance, as the quota is stored as FLOAT
+		if usedQuota > paidQuota+0.001 {
+			date := attendanceRow.Date
+			return &date
+		}
+	}
+
+	return nil
+}
+
 func (s dashboardServiceImpl) GetTeacherPaymentDetails(ctx context.Context) {
 
 }
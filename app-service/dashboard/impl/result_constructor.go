@@ -1,6 +1,8 @@
 package impl
 
 import (
+	"sort"
+
 	"sonamusica-backend/accessor/relational_db/mysql"
 	"sonamusica-backend/app-service/dashboard"
 	"sonamusica-backend/app-service/identity"
@@ -67,7 +69,8 @@ func NewMSResultItems_FromMySQLExpenseMSByInstrument(rows []mysql.GetExpenseMont
 
 // ============================== INCOME ==============================
 
-func NewOverviewResultItems_FromMySQLIncomeOverview(rows []mysql.GetIncomeOverviewRow) []dashboard.OverviewResultItem {
+// NewOverviewResultItems_FromMySQLIncomeOverview subtracts the refunds from the income of the same month. Months having only refunds are included with negative value.
+func NewOverviewResultItems_FromMySQLIncomeOverview(rows []mysql.GetIncomeOverviewRow, refundRows []mysql.GetRefundOverviewRow) []dashboard.OverviewResultItem {
 	resultItems := make([]dashboard.OverviewResultItem, 0, len(rows))
 	for _, row := range rows {
 		resultItems = append(resultItems, dashboard.OverviewResultItem{
@@ -76,30 +79,100 @@ func NewOverviewResultItems_FromMySQLIncomeOverview(rows []mysql.GetIncomeOvervi
 		})
 	}
 
+	yearWithMonthToIdx := make(map[string]int, len(resultItems))
+	for i, item := range resultItems {
+		yearWithMonthToIdx[item.Label] = i
+	}
+	for _, refundRow := range refundRows {
+		if idx, ok := yearWithMonthToIdx[refundRow.YearWithMonth]; ok {
+			resultItems[idx].Value -= refundRow.TotalRefundedValue
+			continue
+		}
+		resultItems = append(resultItems, dashboard.OverviewResultItem{
+			Label: refundRow.YearWithMonth,
+			Value: -refundRow.TotalRefundedValue,
+		})
+	}
+	// both rows are ordered by year_with_month, but the appended refund-only months may break the ordering
+	sort.SliceStable(resultItems, func(i, j int) bool {
+		return resultItems[i].Label < resultItems[j].Label
+	})
+
 	return resultItems
 }
 
-func NewMSResultItems_FromMySQLIncomeMSByTeacher(rows []mysql.GetIncomeMonthlySummaryGroupedByStudentRow) []dashboard.MonthlySummaryResultItem {
+// NewMSResultItems_FromMySQLIncomeMSByTeacher subtracts the refunds from the income of the same student.
+func NewMSResultItems_FromMySQLIncomeMSByTeacher(rows []mysql.GetIncomeMonthlySummaryGroupedByStudentRow, refundRows []mysql.GetRefundMonthlySummaryGroupedByStudentRow) []dashboard.MonthlySummaryResultItem {
 	resultItems := make([]dashboard.MonthlySummaryResultItem, 0, len(rows))
+	studentIDToIdx := make(map[int64]int, len(rows))
 	for _, row := range rows {
 		userDetail := identity.UnmarshalUserDetail(row.UserDetail, mainLog)
+		studentIDToIdx[row.StudentID] = len(resultItems)
 		resultItems = append(resultItems, dashboard.MonthlySummaryResultItem{
 			Label: userDetail.String(),
 			Value: row.TotalCourseFee + row.TotalTransportFee + row.TotalPenaltyFeeValue,
 		})
 	}
 
+	for _, refundRow := range refundRows {
+		if idx, ok := studentIDToIdx[refundRow.StudentID]; ok {
+			resultItems[idx].Value -= refundRow.TotalRefundedValue
+			continue
+		}
+		userDetail := identity.UnmarshalUserDetail(refundRow.UserDetail, mainLog)
+		resultItems = append(resultItems, dashboard.MonthlySummaryResultItem{
+			Label: userDetail.String(),
+			Value: -refundRow.TotalRefundedValue,
+		})
+	}
+
 	return resultItems
 }
 
-func NewMSResultItems_FromMySQLIncomeMSByInstrument(rows []mysql.GetIncomeMonthlySummaryGroupedByInstrumentRow) []dashboard.MonthlySummaryResultItem {
+// NewMSResultItems_FromMySQLIncomeMSByInstrument subtracts the refunds from the income of the same instrument.
+func NewMSResultItems_FromMySQLIncomeMSByInstrument(rows []mysql.GetIncomeMonthlySummaryGroupedByInstrumentRow, refundRows []mysql.GetRefundMonthlySummaryGroupedByInstrumentRow) []dashboard.MonthlySummaryResultItem {
 	resultItems := make([]dashboard.MonthlySummaryResultItem, 0, len(rows))
+	instrumentIDToIdx := make(map[int64]int, len(rows))
 	for _, row := range rows {
+		instrumentIDToIdx[row.Instrument.ID] = len(resultItems)
 		resultItems = append(resultItems, dashboard.MonthlySummaryResultItem{
 			Label: row.Instrument.Name,
 			Value: row.TotalCourseFee + row.TotalTransportFee + row.TotalPenaltyFeeValue,
 		})
 	}
 
+	for _, refundRow := range refundRows {
+		if idx, ok := instrumentIDToIdx[refundRow.Instrument.ID]; ok {
+			resultItems[idx].Value -= refundRow.TotalRefundedValue
+			continue
+		}
+		resultItems = append(resultItems, dashboard.MonthlySummaryResultItem{
+			Label: refundRow.Instrument.Name,
+			Value: -refundRow.TotalRefundedValue,
+		})
+	}
+
+	return resultItems
+}
+
+// NewMSResultItems_FromMySQLDiscountMSByDiscountRule also adds the remaining of totalDiscountFeeValue (i.e. not from any DiscountRule) as dashboard.DiscountLabel_Manual.
+func NewMSResultItems_FromMySQLDiscountMSByDiscountRule(rows []mysql.GetDiscountMonthlySummaryGroupedByDiscountRuleRow, totalDiscountFeeValue int64) []dashboard.MonthlySummaryResultItem {
+	resultItems := make([]dashboard.MonthlySummaryResultItem, 0, len(rows)+1)
+	manualDiscountValue := totalDiscountFeeValue
+	for _, row := range rows {
+		resultItems = append(resultItems, dashboard.MonthlySummaryResultItem{
+			Label: row.DiscountRuleName,
+			Value: row.TotalDiscountValue,
+		})
+		manualDiscountValue -= row.TotalDiscountValue
+	}
+
+	if manualDiscountValue > 0 {
+		resultItems = append(resultItems, dashboard.MonthlySummaryResultItem{
+			Label: dashboard.DiscountLabel_Manual,
+			Value: manualDiscountValue,
+		})
+	}
+
 	return resultItems
 }
@@ -0,0 +1,77 @@
+package dashboard
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ReceivableReportCSV_DateFormat is the date format used in the CSV, which is commonly recognized by spreadsheet applications.
+const ReceivableReportCSV_DateFormat = "2006-01-02"
+
+var receivableReportCSVHeader = []string{
+	"Student Enrollment ID", "Student", "Class ID", "Teacher", "Instrument", "Grade",
+	"Owed Quota", "Owed Value", "Oldest Unpaid Attendance", "Debt Age (Days)", "Last Payment",
+}
+
+// FileName returns the CSV file name, which contains the generation date to differentiate the exported reports.
+func (r ReceivableReport) FileName(generatedAt time.Time) string {
+	return fmt.Sprintf("receivable_report_%s.csv", generatedAt.Format(ReceivableReportCSV_DateFormat))
+}
+
+// CSV writes the report as a CSV, with one row per ReceivableReportItem, followed by a row of the total owed value.
+func (r ReceivableReport) CSV() ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write(receivableReportCSVHeader); err != nil {
+		return nil, fmt.Errorf("writer.Write(header): %w", err)
+	}
+
+	for _, item := range r.Items {
+		teacherName := ""
+		if item.StudentEnrollment.ClassInfo.TeacherInfo_Minimal != nil {
+			teacherName = item.StudentEnrollment.ClassInfo.TeacherInfo_Minimal.UserInfo_Minimal.UserDetail.String()
+		}
+
+		record := []string{
+			strconv.FormatInt(int64(item.StudentEnrollment.StudentEnrollmentID), 10),
+			item.StudentEnrollment.StudentInfo.String(),
+			strconv.FormatInt(int64(item.StudentEnrollment.ClassInfo.ClassID), 10),
+			teacherName,
+			item.StudentEnrollment.ClassInfo.Course.Instrument.Name,
+			item.StudentEnrollment.ClassInfo.Course.Grade.Name,
+			strconv.FormatFloat(item.OwedQuota, 'f', -1, 64),
+			strconv.FormatInt(item.OwedValue, 10),
+			formatCSVDate(item.OldestUnpaidAttendanceDate),
+			strconv.Itoa(item.DebtAgeDays),
+			formatCSVDate(item.LastPaymentDate),
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, fmt.Errorf("writer.Write(studentEnrollmentID='%d'): %w", item.StudentEnrollment.StudentEnrollmentID, err)
+		}
+	}
+
+	totalRecord := make([]string, len(receivableReportCSVHeader))
+	totalRecord[0] = "Total"
+	totalRecord[7] = strconv.FormatInt(r.TotalOwedValue, 10)
+	if err := writer.Write(totalRecord); err != nil {
+		return nil, fmt.Errorf("writer.Write(total): %w", err)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("writer.Flush(): %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func formatCSVDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format(ReceivableReportCSV_DateFormat)
+}
@@ -2,8 +2,13 @@ package email_composer
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/matcornic/hermes/v2"
+
+	"sonamusica-backend/app-service/entity"
+	"sonamusica-backend/app-service/teaching"
+	"sonamusica-backend/app-service/util"
 )
 
 type PasswordReset struct {
@@ -48,3 +53,151 @@ func (r *PasswordReset) Email() hermes.Email {
 		},
 	}
 }
+
+type TeacherPayslip struct {
+	Payslip     teaching.TeacherPayslip
+	CompanyName string
+}
+
+func NewTeacherPayslip(payslip teaching.TeacherPayslip) *TeacherPayslip {
+	return &TeacherPayslip{
+		Payslip:     payslip,
+		CompanyName: configObject.Email_CompanyName,
+	}
+}
+
+func (r *TeacherPayslip) Name() string {
+	return "TeacherPayslip"
+}
+
+func (r *TeacherPayslip) Subject() string {
+	return fmt.Sprintf("Payslip %s - %s on %s", r.Payslip.StartDatetime.Format("02 Jan 2006"), r.Payslip.EndDatetime.Format("02 Jan 2006"), r.CompanyName)
+}
+
+// Email uses a free markdown body instead of hermes.Body's Table, as hermes only supports a single table, while a payslip has a table per class.
+func (r *TeacherPayslip) Email() hermes.Email {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Hi %s,\n\n", escapeMarkdown(r.Payslip.Teacher.User.UserDetail.String()))
+	fmt.Fprintf(&sb, "Here is your payslip for the period of **%s - %s**.\n\n", r.Payslip.StartDatetime.Format("02 Jan 2006"), r.Payslip.EndDatetime.Format("02 Jan 2006"))
+
+	for _, payslipClass := range r.Payslip.Classes {
+		fmt.Fprintf(&sb, "### %s (Class #%d)\n\n", escapeMarkdown(payslipClass.ClassInfo_Minimal.String()), payslipClass.ClassID)
+		sb.WriteString("| Date | Student | Quota | Gross Course Fee | Gross Transport Fee | Course Sharing | Transport Sharing | Paid Course Fee | Paid Transport Fee |\n")
+		sb.WriteString("|---|---|--:|--:|--:|--:|--:|--:|--:|\n")
+		for _, line := range payslipClass.Lines {
+			fmt.Fprintf(&sb, "| %s | %s | %.2f | %s | %s | %.0f%% | %.0f%% | %s | %s |\n",
+				line.Date.Format("02 Jan 2006"), escapeMarkdown(line.StudentInfo.String()), line.UsedStudentTokenQuota,
+				util.FormatRupiah(int64(line.GrossCourseFeeValue)), util.FormatRupiah(int64(line.GrossTransportFeeValue)),
+				line.CourseFeeSharingPercentage*100, line.TransportFeeSharingPercentage*100,
+				util.FormatRupiah(int64(line.PaidCourseFeeValue)), util.FormatRupiah(int64(line.PaidTransportFeeValue)),
+			)
+		}
+		subtotal := payslipClass.Subtotal
+		fmt.Fprintf(&sb, "| **Subtotal** | | **%.2f** | **%s** | **%s** | | | **%s** | **%s** |\n\n",
+			subtotal.TotalAttendances, util.FormatRupiah(subtotal.TotalGrossCourseFeeValue), util.FormatRupiah(subtotal.TotalGrossTransportFeeValue),
+			util.FormatRupiah(subtotal.TotalPaidCourseFeeValue), util.FormatRupiah(subtotal.TotalPaidTransportFeeValue),
+		)
+	}
+
+	grandTotal := r.Payslip.GrandTotal
+	sb.WriteString("### Grand Total\n\n")
+	sb.WriteString("| | Gross | Paid |\n|---|--:|--:|\n")
+	fmt.Fprintf(&sb, "| Course Fee | %s | %s |\n", util.FormatRupiah(grandTotal.TotalGrossCourseFeeValue), util.FormatRupiah(grandTotal.TotalPaidCourseFeeValue))
+	fmt.Fprintf(&sb, "| Transport Fee | %s | %s |\n", util.FormatRupiah(grandTotal.TotalGrossTransportFeeValue), util.FormatRupiah(grandTotal.TotalPaidTransportFeeValue))
+	fmt.Fprintf(&sb, "| **Total** | **%s** | **%s** |\n\n", util.FormatRupiah(grandTotal.TotalGrossCourseFeeValue+grandTotal.TotalGrossTransportFeeValue), util.FormatRupiah(grandTotal.TotalPaidFeeValue))
+	fmt.Fprintf(&sb, "Thanks,\n\n%s\n", r.CompanyName)
+
+	return hermes.Email{
+		Body: hermes.Body{
+			FreeMarkdown: hermes.Markdown(sb.String()),
+		},
+	}
+}
+
+type PaymentReminder struct {
+	RecipientName     string
+	StudentEnrollment entity.StudentEnrollment
+	Type              teaching.PaymentReminderType
+	OwedQuota         float64
+	Invoice           teaching.StudentEnrollmentInvoice
+	CompanyName       string
+}
+
+func NewPaymentReminder(recipientName string, studentEnrollment entity.StudentEnrollment, reminderType teaching.PaymentReminderType, owedQuota float64, invoice teaching.StudentEnrollmentInvoice) *PaymentReminder {
+	return &PaymentReminder{
+		RecipientName:     recipientName,
+		StudentEnrollment: studentEnrollment,
+		Type:              reminderType,
+		OwedQuota:         owedQuota,
+		Invoice:           invoice,
+		CompanyName:       configObject.Email_CompanyName,
+	}
+}
+
+func (r *PaymentReminder) Name() string {
+	return "PaymentReminder"
+}
+
+func (r *PaymentReminder) Subject() string {
+	return fmt.Sprintf("Payment Reminder for %s (%s) on %s", r.StudentEnrollment.StudentInfo.String(), r.StudentEnrollment.ClassInfo.String(), r.CompanyName)
+}
+
+func (r *PaymentReminder) Email() hermes.Email {
+	var intro string
+	switch r.Type {
+	case teaching.PaymentReminderType_Owing:
+		intro = fmt.Sprintf("%s has attended %.2f more session(s) than what has been paid for. Please complete the payment at your earliest convenience.", r.StudentEnrollment.StudentInfo.String(), -r.OwedQuota)
+	case teaching.PaymentReminderType_Overdue:
+		intro = fmt.Sprintf("The payment for %s is %d day(s) overdue, and a late payment penalty has been applied.", r.StudentEnrollment.StudentInfo.String(), r.Invoice.DaysLate)
+	default:
+		intro = fmt.Sprintf("The payment for %s is due in %d day(s). Please complete the payment before the due date to avoid the late payment penalty.", r.StudentEnrollment.StudentInfo.String(), -r.Invoice.DaysLate)
+	}
+
+	lastPaymentDate := "-"
+	if r.Invoice.LastPaymentDate != nil {
+		lastPaymentDate = r.Invoice.LastPaymentDate.Format("02 Jan 2006")
+	}
+
+	invoiceRows := [][]hermes.Entry{
+		{{Key: "Item", Value: "Course Fee"}, {Key: "Amount", Value: util.FormatRupiah(int64(r.Invoice.CourseFeeValue))}},
+		{{Key: "Item", Value: "Transport Fee"}, {Key: "Amount", Value: util.FormatRupiah(int64(r.Invoice.TransportFeeValue))}},
+	}
+	if r.Invoice.PenaltyFeeValue > 0 {
+		invoiceRows = append(invoiceRows, []hermes.Entry{{Key: "Item", Value: "Late Payment Penalty"}, {Key: "Amount", Value: util.FormatRupiah(int64(r.Invoice.PenaltyFeeValue))}})
+	}
+	for _, discount := range r.Invoice.Discounts {
+		invoiceRows = append(invoiceRows, []hermes.Entry{{Key: "Item", Value: "Discount: " + discount.Name}, {Key: "Amount", Value: "-" + util.FormatRupiah(int64(discount.Value))}})
+	}
+	totalValue := int64(r.Invoice.CourseFeeValue) + int64(r.Invoice.TransportFeeValue) + int64(r.Invoice.PenaltyFeeValue) - int64(r.Invoice.DiscountFeeValue)
+	invoiceRows = append(invoiceRows, []hermes.Entry{{Key: "Item", Value: "Total"}, {Key: "Amount", Value: util.FormatRupiah(totalValue)}})
+
+	return hermes.Email{
+		Body: hermes.Body{
+			Name:   r.RecipientName,
+			Intros: []string{intro},
+			Dictionary: []hermes.Entry{
+				{Key: "Student", Value: r.StudentEnrollment.StudentInfo.String()},
+				{Key: "Class", Value: r.StudentEnrollment.ClassInfo.String()},
+				{Key: "Last Payment", Value: lastPaymentDate},
+			},
+			Table: hermes.Table{
+				Data: invoiceRows,
+				Columns: hermes.Columns{
+					CustomAlignment: map[string]string{
+						"Amount": "right",
+					},
+				},
+			},
+			Outros: []string{
+				"The amount above is calculated as of today, and may change when the payment is made later.",
+				"If you have already paid, please ignore this email.",
+			},
+			Signature: "Thanks",
+		},
+	}
+}
+
+// escapeMarkdown escapes user inputs, so that they don't break the markdown formatting, e.g. the table columns.
+func escapeMarkdown(text string) string {
+	return strings.NewReplacer("|", "\\|", "*", "\\*", "_", "\\_", "#", "\\#").Replace(text)
+}
@@ -3,6 +3,7 @@ package entity
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"sonamusica-backend/app-service/identity"
@@ -62,6 +63,8 @@ type Class struct {
 	TeacherSpecialFee      int32                 `json:"teacherSpecialFee,omitempty"` // this is only populated when the class' teacher has a special fee
 	AutoOweAttendanceToken bool                  `json:"autoOweAttendanceToken"`
 	IsDeactivated          bool                  `json:"isDeactivated"`
+	// ActiveHolds are the StudentEnrollmentHolds of the class' students which are active today. This is only populated by GetClassById.
+	ActiveHolds []StudentEnrollmentHold `json:"activeHolds,omitempty"`
 }
 
 // ClassInfo_Minimal is a subset of struct Class that must have the same schema.
@@ -85,6 +88,59 @@ type StudentEnrollment struct {
 	ClassInfo           ClassInfo_Minimal   `json:"class"`
 }
 
+// StudentEnrollmentPeriod is a span in which a StudentEnrollment is active, from StartDate until EndDate (both inclusive, as dates in util.DefaultTimezone).
+// A StudentEnrollment has a new period each time the student is re-enrolled into the same class.
+type StudentEnrollmentPeriod struct {
+	StudentEnrollmentPeriodID StudentEnrollmentPeriodID `json:"studentEnrollmentPeriodId"`
+	StartDate                 time.Time                 `json:"startDate"`
+	EndDate                   *time.Time                `json:"endDate,omitempty"` // nil when the period is still ongoing
+	WithdrawalReason          string                    `json:"withdrawalReason,omitempty"`
+}
+
+// IsActiveAt returns whether t's date (in util.DefaultTimezone) is within the period.
+func (p StudentEnrollmentPeriod) IsActiveAt(t time.Time) bool {
+	date := util.ToLocalDate(t)
+	return !date.Before(p.StartDate) && (p.EndDate == nil || !date.After(*p.EndDate))
+}
+
+// StudentEnrollmentHistory is a StudentEnrollment (including a withdrawn one) along with all of its periods, sorted by StartDate.
+type StudentEnrollmentHistory struct {
+	StudentEnrollment
+	IsActive bool                      `json:"isActive"`
+	Periods  []StudentEnrollmentPeriod `json:"periods"`
+}
+
+// StudentEnrollmentHold pauses a StudentEnrollment from StartDate until EndDate (both inclusive, as dates in util.DefaultTimezone), e.g. during a vacation or an exam period.
+// During a hold, the late-payment penalty days are not counted, and attendances cannot be added for the student.
+type StudentEnrollmentHold struct {
+	StudentEnrollmentHoldID StudentEnrollmentHoldID `json:"studentEnrollmentHoldId"`
+	StudentEnrollmentID     StudentEnrollmentID     `json:"studentEnrollmentId"`
+	StudentID               StudentID               `json:"studentId"`
+	StartDate               time.Time               `json:"startDate"`
+	EndDate                 time.Time               `json:"endDate"`
+	Reason                  string                  `json:"reason"`
+}
+
+// IsActiveAt returns whether t's date (in util.DefaultTimezone) is within the hold.
+func (h StudentEnrollmentHold) IsActiveAt(t time.Time) bool {
+	date := util.ToLocalDate(t)
+	return !date.Before(h.StartDate) && !date.After(h.EndDate)
+}
+
+// Family groups siblings (Students) whose StudentEnrollments are paid by the same Guardians, e.g. a parent. A Student belongs to at most one Family.
+type Family struct {
+	FamilyID  FamilyID              `json:"familyId"`
+	Name      string                `json:"name"`
+	Students  []StudentInfo_Minimal `json:"students"`
+	Guardians []FamilyGuardian      `json:"guardians"`
+}
+
+// FamilyGuardian is a user who pays for a Family, who isn't necessarily a Student or a Teacher.
+type FamilyGuardian struct {
+	UserID           identity.UserID           `json:"userId"`
+	UserInfo_Minimal identity.UserInfo_Minimal `json:"user"`
+}
+
 type TeacherSpecialFee struct {
 	TeacherSpecialFeeID TeacherSpecialFeeID `json:"teacherSpecialFeeId"`
 	TeacherInfo         TeacherInfo_Minimal `json:"teacher"`
@@ -92,6 +148,121 @@ type TeacherSpecialFee struct {
 	Fee                 int32               `json:"fee"`
 }
 
+// CourseFeeHistory is a price of a Course, which is in force since EffectiveFrom until the EffectiveFrom of the next CourseFeeHistory.
+type CourseFeeHistory struct {
+	CourseFeeHistoryID CourseFeeHistoryID `json:"courseFeeHistoryId"`
+	CourseID           CourseID           `json:"courseId"`
+	Fee                int32              `json:"fee"`
+	EffectiveFrom      time.Time          `json:"effectiveFrom"`
+}
+
+// TeacherSpecialFeeHistory is similar to CourseFeeHistory, but for TeacherSpecialFee. A zero Fee means the TeacherSpecialFee is removed since EffectiveFrom.
+type TeacherSpecialFeeHistory struct {
+	TeacherSpecialFeeHistoryID TeacherSpecialFeeHistoryID `json:"teacherSpecialFeeHistoryId"`
+	TeacherID                  TeacherID                  `json:"teacherId"`
+	CourseID                   CourseID                   `json:"courseId"`
+	Fee                        int32                      `json:"fee"`
+	EffectiveFrom              time.Time                  `json:"effectiveFrom"`
+}
+
+// PenaltyPolicy configures the late-payment penalty fee, which is applied on StudentEnrollment invoice.
+//
+// A PenaltyPolicy is assigned to either a Class, a Course, or none of them (which makes it the school-wide default). There can only be one school-wide default.
+type PenaltyPolicy struct {
+	PenaltyPolicyID PenaltyPolicyID    `json:"penaltyPolicyId"`
+	Name            string             `json:"name"`
+	Scope           PenaltyPolicyScope `json:"scope"`
+	CourseID        CourseID           `json:"courseId,omitempty"`
+	ClassID         ClassID            `json:"classId,omitempty"`
+	// Penalty starts counting after TriggerDayOfMonth of the month following the latest EnrollmentPayment, plus GraceDays.
+	TriggerDayOfMonth int32 `json:"triggerDayOfMonth"`
+	GraceDays         int32 `json:"graceDays"`
+	// IsFlatFee determines whether FeeValue is charged once, or charged for every day late.
+	IsFlatFee   bool  `json:"isFlatFee"`
+	FeeValue    int32 `json:"feeValue"`
+	MaxFeeValue int32 `json:"maxFeeValue"` // 0 means the penalty fee is not capped
+}
+
+type PenaltyPolicyScope string
+
+const (
+	PenaltyPolicyScope_Default PenaltyPolicyScope = "DEFAULT"
+	PenaltyPolicyScope_Course  PenaltyPolicyScope = "COURSE"
+	PenaltyPolicyScope_Class   PenaltyPolicyScope = "CLASS"
+)
+
+// DiscountRule configures a promotion, which is evaluated on StudentEnrollment invoice. See DiscountRuleType for the condition of each type.
+//
+// On an invoice, only the highest-valued DiscountRule of each type is applied, and the total discount never exceeds the course fee.
+type DiscountRule struct {
+	DiscountRuleID DiscountRuleID   `json:"discountRuleId"`
+	Name           string           `json:"name"`
+	Type           DiscountRuleType `json:"type"`
+	// IsPercentage determines whether Value is a percentage (1-100) of the course fee, or a fixed amount.
+	IsPercentage  bool  `json:"isPercentage"`
+	Value         int32 `json:"value"`
+	MinCount      int32 `json:"minCount,omitempty"`      // only for "SIBLING" & "MULTI_CLASS"
+	MaxDayOfMonth int32 `json:"maxDayOfMonth,omitempty"` // only for "EARLY_PAYMENT"
+	// VoucherCode & UsageLimit are only for "VOUCHER". A zero UsageLimit means the voucher can be used unlimitedly.
+	VoucherCode string `json:"voucherCode,omitempty"`
+	UsageLimit  int32  `json:"usageLimit,omitempty"`
+	// ValidFrom & ValidUntil (both inclusive) are compared against the payment date. A nil value means unbounded.
+	ValidFrom     *time.Time `json:"validFrom,omitempty"`
+	ValidUntil    *time.Time `json:"validUntil,omitempty"`
+	IsDeactivated bool       `json:"isDeactivated"`
+}
+
+type DiscountRuleType string
+
+const (
+	// DiscountRuleType_Sibling applies when at least MinCount students (including the student) of the same Family have an active StudentEnrollment.
+	DiscountRuleType_Sibling DiscountRuleType = "SIBLING"
+	// DiscountRuleType_MultiClass applies when the student has at least MinCount active StudentEnrollments.
+	DiscountRuleType_MultiClass DiscountRuleType = "MULTI_CLASS"
+	// DiscountRuleType_EarlyPayment applies when the payment is made on or before MaxDayOfMonth of the month.
+	DiscountRuleType_EarlyPayment DiscountRuleType = "EARLY_PAYMENT"
+	// DiscountRuleType_Voucher applies only when its VoucherCode is entered on the invoice.
+	DiscountRuleType_Voucher DiscountRuleType = "VOUCHER"
+)
+
+// IsValidAt returns true when the DiscountRule is active, and the given date is within its validity window.
+func (r DiscountRule) IsValidAt(date time.Time) bool {
+	if r.IsDeactivated {
+		return false
+	}
+	if r.ValidFrom != nil && date.Before(*r.ValidFrom) {
+		return false
+	}
+	if r.ValidUntil != nil && date.After(r.ValidUntil.AddDate(0, 0, 1).Add(-time.Nanosecond)) {
+		return false
+	}
+	return true
+}
+
+// TeacherFeeSharing configures the portion of an Attendance's gross course & transport fee which is paid to the teacher.
+//
+// A TeacherFeeSharing is assigned to either a Teacher, a Course, both of them, or none of them (which makes it the school-wide default),
+// and is in force since EffectiveFrom until the EffectiveFrom of the next TeacherFeeSharing with the same assignment.
+type TeacherFeeSharing struct {
+	TeacherFeeSharingID TeacherFeeSharingID    `json:"teacherFeeSharingId"`
+	Scope               TeacherFeeSharingScope `json:"scope"`
+	TeacherID           TeacherID              `json:"teacherId,omitempty"`
+	CourseID            CourseID               `json:"courseId,omitempty"`
+	// both percentages are in the range of 0 to 1
+	CourseFeeSharingPercentage    float64   `json:"courseFeeSharingPercentage"`
+	TransportFeeSharingPercentage float64   `json:"transportFeeSharingPercentage"`
+	EffectiveFrom                 time.Time `json:"effectiveFrom"`
+}
+
+type TeacherFeeSharingScope string
+
+const (
+	TeacherFeeSharingScope_Default       TeacherFeeSharingScope = "DEFAULT"
+	TeacherFeeSharingScope_Course        TeacherFeeSharingScope = "COURSE"
+	TeacherFeeSharingScope_Teacher       TeacherFeeSharingScope = "TEACHER"
+	TeacherFeeSharingScope_TeacherCourse TeacherFeeSharingScope = "TEACHER_COURSE"
+)
+
 type EnrollmentPayment struct {
 	EnrollmentPaymentID   EnrollmentPaymentID `json:"enrollmentPaymentId"`
 	StudentEnrollmentInfo StudentEnrollment   `json:"studentEnrollment"`
@@ -104,8 +275,51 @@ type EnrollmentPayment struct {
 	TransportFeeValue int32 `json:"transportFeeValue"`
 	PenaltyFeeValue   int32 `json:"penaltyFeeValue"`
 	// DiscountFeeValue is used for modifying top-upped balance, without affecting CourseFeeQuarterValue & TransportFeeQuarterValue. Check teaching/calculation_util.go for more information.
-	DiscountFeeValue int32 `json:"discountFeeValue"`
-}
+	DiscountFeeValue int32         `json:"discountFeeValue"`
+	PaymentMethod    PaymentMethod `json:"paymentMethod"`
+	// ReceivingAccount is the bank/e-wallet account which receives the payment, e.g. "BCA 1234567890". Empty for cash payments.
+	ReceivingAccount string `json:"receivingAccount"`
+	// ReferenceNumber is the transfer/transaction reference, for reconciling the payment with the bank statement.
+	ReferenceNumber string `json:"referenceNumber"`
+	// Snapshot is captured on insert, and is used in place of StudentEnrollmentInfo once the StudentEnrollment has been deleted.
+	Snapshot PaymentSnapshot `json:"snapshot"`
+}
+
+// PaymentSnapshot is an immutable copy of the student & class details of an EnrollmentPayment or a TeacherPayment, captured at insert time.
+type PaymentSnapshot struct {
+	StudentName      string `json:"studentName"`
+	ClassDescription string `json:"classDescription"`
+	CourseName       string `json:"courseName"`
+	InstrumentName   string `json:"instrumentName"`
+	GradeName        string `json:"gradeName"`
+	TeacherName      string `json:"teacherName"`
+}
+
+// NewPaymentSnapshot builds the snapshot of the given student & class. teacherInfo may be nil, e.g. for a class without teacher.
+//
+// The formats must be the same as the backfill in migration "018_payment_snapshot.sql".
+func NewPaymentSnapshot(studentInfo StudentInfo_Minimal, classInfo ClassInfo_Minimal, teacherInfo *TeacherInfo_Minimal) PaymentSnapshot {
+	snapshot := PaymentSnapshot{
+		StudentName:      strings.TrimSpace(studentInfo.String()),
+		ClassDescription: fmt.Sprintf("%s (Class #%d)", classInfo.String(), classInfo.ClassID),
+		CourseName:       classInfo.String(),
+		InstrumentName:   classInfo.Course.Instrument.Name,
+		GradeName:        classInfo.Course.Grade.Name,
+	}
+	if teacherInfo != nil {
+		snapshot.TeacherName = strings.TrimSpace(teacherInfo.UserInfo_Minimal.UserDetail.String())
+	}
+	return snapshot
+}
+
+type PaymentMethod string
+
+const (
+	PaymentMethod_Cash         PaymentMethod = "CASH"
+	PaymentMethod_BankTransfer PaymentMethod = "BANK_TRANSFER"
+	PaymentMethod_QRIS         PaymentMethod = "QRIS"
+	PaymentMethod_Other        PaymentMethod = "OTHER"
+)
 
 type StudentLearningToken struct {
 	StudentLearningTokenID StudentLearningTokenID `json:"studentLearningTokenId"`
@@ -127,6 +341,33 @@ type StudentLearningToken_Minimal struct {
 	LastUpdatedAt          time.Time              `json:"lastUpdatedAt"`
 }
 
+// SLTTransaction is a record of the StudentLearningToken ledger. Every change of a StudentLearningToken's quota is recorded as one SLTTransaction.
+type SLTTransaction struct {
+	SLTTransactionID       SLTTransactionID       `json:"sltTransactionId"`
+	StudentLearningTokenID StudentLearningTokenID `json:"studentLearningTokenId"`
+	QuotaChange            float64                `json:"quotaChange"`
+	Reason                 SLTTransactionReason   `json:"reason"`
+	// SourceID is the ID of the entity which causes the quota change, depending on Reason: EnrollmentPaymentID for "PAYMENT", AttendanceID for "ATTENDANCE", EnrollmentPaymentRefundID for "REFUND".
+	SourceID  int64           `json:"sourceId,omitempty"`
+	UserID    identity.UserID `json:"userId,omitempty"`
+	Username  string          `json:"username,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type SLTTransactionReason string
+
+const (
+	SLTTransactionReason_Payment    SLTTransactionReason = "PAYMENT"
+	SLTTransactionReason_Attendance SLTTransactionReason = "ATTENDANCE"
+	SLTTransactionReason_Transfer   SLTTransactionReason = "TRANSFER"
+	SLTTransactionReason_Manual     SLTTransactionReason = "MANUAL"
+	SLTTransactionReason_Migration  SLTTransactionReason = "MIGRATION"
+	// SLTTransactionReason_Refund is used when the quota is withdrawn by refunding an EnrollmentPayment, check TeachingService.RefundEnrollmentPayment().
+	SLTTransactionReason_Refund SLTTransactionReason = "REFUND"
+	// SLTTransactionReason_Reconciliation is used when the quota is repaired by the SLT reconciliation, check TeachingService.ReconcileSLTQuotas().
+	SLTTransactionReason_Reconciliation SLTTransactionReason = "RECONCILIATION"
+)
+
 type Attendance struct {
 	AttendanceID          AttendanceID                 `json:"attendanceId"`
 	ClassInfo             ClassInfo_Minimal            `json:"class,omitempty"`
@@ -138,6 +379,9 @@ type Attendance struct {
 	Duration              int32                        `json:"duration"`
 	Note                  string                       `json:"note"`
 	IsPaid                bool                         `json:"isPaid"`
+	// IsSubstitute is true when the attendance is taught by another teacher than the original (scheduled) teacher, i.e. the class' teacher at the time the attendance is recorded.
+	IsSubstitute        bool                 `json:"isSubstitute"`
+	OriginalTeacherInfo *TeacherInfo_Minimal `json:"originalTeacher,omitempty"`
 }
 
 // AttendanceInfo_Minimal is a subset of struct Attendance that must have the same schema.
@@ -149,6 +393,7 @@ type AttendanceInfo_Minimal struct {
 	Duration              int32               `json:"duration"`
 	Note                  string              `json:"note"`
 	IsPaid                bool                `json:"isPaid"`
+	IsSubstitute          bool                `json:"isSubstitute"`
 }
 
 type TeacherPayment struct {
@@ -157,12 +402,85 @@ type TeacherPayment struct {
 	PaidCourseFeeValue    int32            `json:"paidCourseFeeValue"`
 	PaidTransportFeeValue int32            `json:"paidTransportFeeValue"`
 	AddedAt               time.Time        `json:"addedAt"`
+	// Snapshot is captured on insert, and is used in place of the Attendance's student, teacher & class once they are no longer available.
+	Snapshot PaymentSnapshot `json:"snapshot"`
 
 	// These 2 fields value are derived from Attendance.[Course|Transport]Fee, Attendance.UsedStudentTokenQuota, and Default_OneCourseCycle
 	GrossCourseFeeValue    int32 `json:"grossCourseFeeValue"`
 	GrossTransportFeeValue int32 `json:"grossTransportFeeValue"`
 }
 
+// PayrollRun groups the TeacherPayments of a pay period, whose Status goes through: "DRAFT" -> "APPROVED" -> "PAID".
+//
+// TeacherPayments can only be submitted into a "DRAFT" PayrollRun, and are locked (cannot be edited nor removed) once the PayrollRun is approved.
+type PayrollRun struct {
+	PayrollRunID PayrollRunID     `json:"payrollRunId"`
+	StartDate    time.Time        `json:"startDate"`
+	EndDate      time.Time        `json:"endDate"`
+	Status       PayrollRunStatus `json:"status"`
+	Note         string           `json:"note"`
+
+	CreatedAt          time.Time       `json:"createdAt"`
+	CreatedByUserID    identity.UserID `json:"createdByUserId,omitempty"`
+	CreatedByUsername  string          `json:"createdByUsername,omitempty"`
+	ApprovedAt         time.Time       `json:"approvedAt,omitempty"`
+	ApprovedByUserID   identity.UserID `json:"approvedByUserId,omitempty"`
+	ApprovedByUsername string          `json:"approvedByUsername,omitempty"`
+	PaidAt             time.Time       `json:"paidAt,omitempty"`
+}
+
+type PayrollRunStatus string
+
+const (
+	PayrollRunStatus_Draft    PayrollRunStatus = "DRAFT"
+	PayrollRunStatus_Approved PayrollRunStatus = "APPROVED"
+	PayrollRunStatus_Paid     PayrollRunStatus = "PAID"
+)
+
+// ClosedPeriod closes the accounting period up to (and including) ClosedUntil.
+//
+// Dated records (Attendance.Date, EnrollmentPayment.PaymentDate, TeacherPayment.AddedAt) within the latest ClosedPeriod cannot be inserted, updated, nor deleted,
+// unless a super admin overrides it with a reason (see ClosedPeriodOverride).
+type ClosedPeriod struct {
+	ClosedPeriodID ClosedPeriodID `json:"closedPeriodId"`
+	ClosedUntil    time.Time      `json:"closedUntil"`
+	Note           string         `json:"note"`
+
+	CreatedAt         time.Time       `json:"createdAt"`
+	CreatedByUserID   identity.UserID `json:"createdByUserId,omitempty"`
+	CreatedByUsername string          `json:"createdByUsername,omitempty"`
+}
+
+// CashUpDay closes a day of EnrollmentPayments (by PaymentDate) after the daily cash-up, recording the counted-vs-recorded cash difference.
+//
+// EnrollmentPayments of a closed day cannot be inserted, updated, nor deleted, until an admin reopens the day (i.e. deletes the CashUpDay).
+type CashUpDay struct {
+	CashUpDayID CashUpDayID `json:"cashUpDayId"`
+	Date        time.Time   `json:"date"`
+	// CountedCashValue is the physically counted cash, while RecordedCashValue is the sum of the day's cash EnrollmentPayments (minus the cash refunds) on closing
+	CountedCashValue  int64 `json:"countedCashValue"`
+	RecordedCashValue int64 `json:"recordedCashValue"`
+	// DifferenceValue = CountedCashValue - RecordedCashValue, negative means missing cash
+	DifferenceValue int64  `json:"differenceValue"`
+	Note            string `json:"note"`
+
+	ClosedAt         time.Time       `json:"closedAt"`
+	ClosedByUserID   identity.UserID `json:"closedByUserId,omitempty"`
+	ClosedByUsername string          `json:"closedByUsername,omitempty"`
+}
+
+// ClosedPeriodOverride is an audit log of a write operation on a closed period, done by a super admin.
+type ClosedPeriodOverride struct {
+	ClosedPeriodOverrideID ClosedPeriodOverrideID `json:"closedPeriodOverrideId"`
+	Operation              string                 `json:"operation"`
+	Reason                 string                 `json:"reason"`
+	ClosedUntil            time.Time              `json:"closedUntil"`
+	EarliestRecordDate     time.Time              `json:"earliestRecordDate"`
+	CreatedAt              time.Time              `json:"createdAt"`
+	UserID                 identity.UserID        `json:"userId,omitempty"`
+	Username               string                 `json:"username,omitempty"`
+}
+
 type TeacherID int64
 type StudentID int64
 type InstrumentID int64
@@ -170,13 +488,27 @@ type GradeID int64
 type CourseID int64
 type ClassID int64
 type StudentEnrollmentID int64
+type StudentEnrollmentPeriodID int64
+type StudentEnrollmentHoldID int64
+type FamilyID int64
 
 type TeacherSpecialFeeID int64
+type CourseFeeHistoryID int64
+type TeacherSpecialFeeHistoryID int64
+type PenaltyPolicyID int64
+type DiscountRuleID int64
+type TeacherFeeSharingID int64
 type EnrollmentPaymentID int64
 type StudentLearningTokenID int64
+type SLTTransactionID int64
 type AttendanceID int64
 
 type TeacherPaymentID int64
+type PayrollRunID int64
+
+type ClosedPeriodID int64
+type ClosedPeriodOverrideID int64
+type CashUpDayID int64
 
 const TeacherID_None TeacherID = iota
 const StudentID_None StudentID = iota
@@ -185,13 +517,27 @@ const GradeID_None GradeID = iota
 const CourseID_None CourseID = iota
 const ClassID_None ClassID = iota
 const StudentEnrollmentID_None StudentEnrollmentID = iota
+const StudentEnrollmentPeriodID_None StudentEnrollmentPeriodID = iota
+const StudentEnrollmentHoldID_None StudentEnrollmentHoldID = iota
+const FamilyID_None FamilyID = iota
 
 const TeacherSpecialFeeID_None TeacherSpecialFeeID = iota
+const CourseFeeHistoryID_None CourseFeeHistoryID = iota
+const TeacherSpecialFeeHistoryID_None TeacherSpecialFeeHistoryID = iota
+const PenaltyPolicyID_None PenaltyPolicyID = iota
+const DiscountRuleID_None DiscountRuleID = iota
+const TeacherFeeSharingID_None TeacherFeeSharingID = iota
 const EnrollmentPaymentID_None EnrollmentPaymentID = iota
 const StudentLearningTokenID_None StudentLearningTokenID = iota
+const SLTTransactionID_None SLTTransactionID = iota
 const AttendanceID_None AttendanceID = iota
 
 const TeacherPaymentID_None TeacherPaymentID = iota
+const PayrollRunID_None PayrollRunID = iota
+
+const ClosedPeriodID_None ClosedPeriodID = iota
+const ClosedPeriodOverrideID_None ClosedPeriodOverrideID = iota
+const CashUpDayID_None CashUpDayID = iota
 
 type EntityService interface {
 	GetTeachers(ctx context.Context, pagination util.PaginationSpec) (GetTeachersResult, error)
@@ -241,6 +587,42 @@ type EntityService interface {
 	GetStudentEnrollments(ctx context.Context, pagination util.PaginationSpec) (GetStudentEnrollmentsResult, error)
 	GetStudentEnrollmentById(ctx context.Context, ids StudentEnrollmentID) (StudentEnrollment, error)
 	GetStudentEnrollmentsByClassId(ctx context.Context, classId ClassID) ([]StudentEnrollment, error)
+	// GetStudentEnrollmentsActiveAtDateByClassId returns the class' StudentEnrollments having a StudentEnrollmentPeriod which covers the date, regardless whether they have been withdrawn afterwards.
+	GetStudentEnrollmentsActiveAtDateByClassId(ctx context.Context, classId ClassID, date time.Time) ([]StudentEnrollment, error)
+	// GetStudentEnrollmentHistoriesByStudentId returns all StudentEnrollments of the student, including the withdrawn ones, along with their periods.
+	GetStudentEnrollmentHistoriesByStudentId(ctx context.Context, studentId StudentID) ([]StudentEnrollmentHistory, error)
+
+	// EnrollStudent enrolls the student into the class starting from spec.StartDate. A previously withdrawn StudentEnrollment of the same class is re-enrolled instead.
+	// Returns errs.ErrStudentEnrollmentAlreadyActive when the student is already actively enrolled in the class.
+	EnrollStudent(ctx context.Context, spec EnrollStudentSpec) (StudentEnrollmentID, error)
+	// WithdrawStudentEnrollment ends the StudentEnrollment's ongoing period at spec.EndDate, and disables the StudentEnrollment once spec.EndDate has passed (see DisableEndedStudentEnrollments()).
+	// Returns errs.ErrStudentEnrollmentNotActive when it has been withdrawn, or errs.ErrStudentEnrollmentPeriodOverlaps when spec.EndDate is before the period's start.
+	// Returns errs.ErrAttendanceAfterWithdrawal when the student has attendances in the class after spec.EndDate.
+	WithdrawStudentEnrollment(ctx context.Context, spec WithdrawStudentEnrollmentSpec) error
+	// DisableEndedStudentEnrollments disables the StudentEnrollments whose withdrawal has taken effect (i.e. all periods ended before today), and returns the number of disabled StudentEnrollments.
+	DisableEndedStudentEnrollments(ctx context.Context) (int64, error)
+	// ReEnrollStudentEnrollment starts a new period of a withdrawn StudentEnrollment from spec.StartDate, and re-enables the StudentEnrollment.
+	// Returns errs.ErrStudentEnrollmentAlreadyActive when it is still active, or errs.ErrStudentEnrollmentPeriodOverlaps when spec.StartDate is not after the previous period's end.
+	ReEnrollStudentEnrollment(ctx context.Context, spec ReEnrollStudentEnrollmentSpec) error
+
+	GetStudentEnrollmentHoldById(ctx context.Context, id StudentEnrollmentHoldID) (StudentEnrollmentHold, error)
+	GetStudentEnrollmentHoldsByIds(ctx context.Context, ids []StudentEnrollmentHoldID) ([]StudentEnrollmentHold, error)
+	GetStudentEnrollmentHoldsByStudentEnrollmentIds(ctx context.Context, studentEnrollmentIDs []StudentEnrollmentID) ([]StudentEnrollmentHold, error)
+	// InsertStudentEnrollmentHolds returns errs.ErrStudentEnrollmentHoldOverlaps when a hold overlaps with another hold of the same StudentEnrollment.
+	InsertStudentEnrollmentHolds(ctx context.Context, specs []InsertStudentEnrollmentHoldSpec) ([]StudentEnrollmentHoldID, error)
+	// UpdateStudentEnrollmentHolds returns errs.ErrStudentEnrollmentHoldOverlaps when a hold overlaps with another hold of the same StudentEnrollment.
+	UpdateStudentEnrollmentHolds(ctx context.Context, specs []UpdateStudentEnrollmentHoldSpec) ([]StudentEnrollmentHoldID, error)
+	DeleteStudentEnrollmentHolds(ctx context.Context, ids []StudentEnrollmentHoldID) error
+
+	GetFamilies(ctx context.Context, pagination util.PaginationSpec) (GetFamiliesResult, error)
+	GetFamilyById(ctx context.Context, id FamilyID) (Family, error)
+	GetFamiliesByIds(ctx context.Context, ids []FamilyID) ([]Family, error)
+	// GetActiveStudentEnrollmentIdsByFamilyId returns the IDs of the active StudentEnrollments of all Students in the Family, sorted by the student.
+	GetActiveStudentEnrollmentIdsByFamilyId(ctx context.Context, id FamilyID) ([]StudentEnrollmentID, error)
+	InsertFamilies(ctx context.Context, specs []InsertFamilySpec) ([]FamilyID, error)
+	// UpdateFamilies replaces the Families' name, Students & Guardians.
+	UpdateFamilies(ctx context.Context, specs []UpdateFamilySpec) ([]FamilyID, error)
+	DeleteFamilies(ctx context.Context, ids []FamilyID) error
 
 	GetTeacherSpecialFees(ctx context.Context, pagination util.PaginationSpec) (GetTeacherSpecialFeesResult, error)
 	GetTeacherSpecialFeeById(ctx context.Context, id TeacherSpecialFeeID) (TeacherSpecialFee, error)
@@ -249,6 +631,53 @@ type EntityService interface {
 	UpdateTeacherSpecialFees(ctx context.Context, specs []UpdateTeacherSpecialFeeSpec) ([]TeacherSpecialFeeID, error)
 	DeleteTeacherSpecialFees(ctx context.Context, ids []TeacherSpecialFeeID) error
 
+	// GetCourseFeeHistoriesByCourseId returns the price history of a Course, sorted descendingly by EffectiveFrom.
+	GetCourseFeeHistoriesByCourseId(ctx context.Context, courseID CourseID) ([]CourseFeeHistory, error)
+	// InsertCourseFeeHistories schedules (or backdates) Course prices. Course.DefaultFee follows the price in force: a backdated price is applied immediately,
+	// while a scheduled price is applied by SyncCourseDefaultFees() once it takes effect.
+	InsertCourseFeeHistories(ctx context.Context, specs []InsertCourseFeeHistorySpec) ([]CourseFeeHistoryID, error)
+	// SyncCourseDefaultFees sets each Course.DefaultFee to the price in force now, and returns the number of updated Courses.
+	SyncCourseDefaultFees(ctx context.Context) (int64, error)
+	// GetTeacherSpecialFeeHistoriesByCourseId returns the TeacherSpecialFee price history of a Course, sorted by TeacherID, then descendingly by EffectiveFrom.
+	GetTeacherSpecialFeeHistoriesByCourseId(ctx context.Context, courseID CourseID) ([]TeacherSpecialFeeHistory, error)
+	// InsertTeacherSpecialFeeHistories is similar to InsertCourseFeeHistories(), but for TeacherSpecialFee.
+	InsertTeacherSpecialFeeHistories(ctx context.Context, specs []InsertTeacherSpecialFeeHistorySpec) ([]TeacherSpecialFeeHistoryID, error)
+	// GetCourseFeeValueAt returns the course fee in force at the given time: TeacherSpecialFee (when teacherID is not None) > Course fee.
+	//
+	// Falls back to the latest Course.DefaultFee when there's no price history in force at the given time.
+	GetCourseFeeValueAt(ctx context.Context, courseID CourseID, teacherID TeacherID, at time.Time) (int32, error)
+
+	GetPenaltyPolicies(ctx context.Context, pagination util.PaginationSpec) (GetPenaltyPoliciesResult, error)
+	GetPenaltyPolicyById(ctx context.Context, id PenaltyPolicyID) (PenaltyPolicy, error)
+	GetPenaltyPoliciesByIds(ctx context.Context, ids []PenaltyPolicyID) ([]PenaltyPolicy, error)
+	// GetApplicablePenaltyPolicy returns the most specific PenaltyPolicy for the given class & course: class policy > course policy > school-wide default.
+	//
+	// Returns sql.ErrNoRows when there's no applicable policy at all (i.e. the school-wide default has been deleted).
+	GetApplicablePenaltyPolicy(ctx context.Context, classID ClassID, courseID CourseID) (PenaltyPolicy, error)
+	InsertPenaltyPolicies(ctx context.Context, specs []InsertPenaltyPolicySpec) ([]PenaltyPolicyID, error)
+	UpdatePenaltyPolicies(ctx context.Context, specs []UpdatePenaltyPolicySpec) ([]PenaltyPolicyID, error)
+	DeletePenaltyPolicies(ctx context.Context, ids []PenaltyPolicyID) error
+
+	GetDiscountRules(ctx context.Context, pagination util.PaginationSpec) (GetDiscountRulesResult, error)
+	GetDiscountRuleById(ctx context.Context, id DiscountRuleID) (DiscountRule, error)
+	GetDiscountRulesByIds(ctx context.Context, ids []DiscountRuleID) ([]DiscountRule, error)
+	// GetActiveAutomaticDiscountRules returns the non-voucher DiscountRules which are not deactivated. The validity window is not checked.
+	GetActiveAutomaticDiscountRules(ctx context.Context) ([]DiscountRule, error)
+	// GetDiscountRuleByVoucherCode returns sql.ErrNoRows when there's no "VOUCHER" DiscountRule with the given code.
+	GetDiscountRuleByVoucherCode(ctx context.Context, voucherCode string) (DiscountRule, error)
+	InsertDiscountRules(ctx context.Context, specs []InsertDiscountRuleSpec) ([]DiscountRuleID, error)
+	UpdateDiscountRules(ctx context.Context, specs []UpdateDiscountRuleSpec) ([]DiscountRuleID, error)
+	DeleteDiscountRules(ctx context.Context, ids []DiscountRuleID) error
+
+	GetTeacherFeeSharings(ctx context.Context, pagination util.PaginationSpec) (GetTeacherFeeSharingsResult, error)
+	GetTeacherFeeSharingById(ctx context.Context, id TeacherFeeSharingID) (TeacherFeeSharing, error)
+	GetTeacherFeeSharingsByIds(ctx context.Context, ids []TeacherFeeSharingID) ([]TeacherFeeSharing, error)
+	// GetTeacherFeeSharingsByTeacherId returns all TeacherFeeSharings which may apply to the teacher's attendances (i.e. including the course & school-wide ones), sorted descendingly by EffectiveFrom.
+	GetTeacherFeeSharingsByTeacherId(ctx context.Context, teacherID TeacherID) ([]TeacherFeeSharing, error)
+	InsertTeacherFeeSharings(ctx context.Context, specs []InsertTeacherFeeSharingSpec) ([]TeacherFeeSharingID, error)
+	UpdateTeacherFeeSharings(ctx context.Context, specs []UpdateTeacherFeeSharingSpec) ([]TeacherFeeSharingID, error)
+	DeleteTeacherFeeSharings(ctx context.Context, ids []TeacherFeeSharingID) error
+
 	GetEnrollmentPayments(ctx context.Context, pagination util.PaginationSpec, timeFilter util.TimeSpec, sortRecent bool) (GetEnrollmentPaymentsResult, error)
 	GetEnrollmentPaymentById(ctx context.Context, id EnrollmentPaymentID) (EnrollmentPayment, error)
 	GetEnrollmentPaymentsByIds(ctx context.Context, ids []EnrollmentPaymentID) ([]EnrollmentPayment, error)
@@ -263,6 +692,12 @@ type EntityService interface {
 	UpdateStudentLearningTokens(ctx context.Context, specs []UpdateStudentLearningTokenSpec) ([]StudentLearningTokenID, error)
 	DeleteStudentLearningTokens(ctx context.Context, ids []StudentLearningTokenID) error
 
+	// GetSLTTransactionsByStudentLearningTokenId returns the ledger of a StudentLearningToken, sorted ascendingly by its insertion order.
+	GetSLTTransactionsByStudentLearningTokenId(ctx context.Context, id StudentLearningTokenID) ([]SLTTransaction, error)
+	// InsertSLTTransactions records StudentLearningToken quota changes into the ledger, without modifying the quota itself.
+	// The acting user is taken from the context's AuthInfo.
+	InsertSLTTransactions(ctx context.Context, specs []InsertSLTTransactionSpec) ([]SLTTransactionID, error)
+
 	GetAttendances(ctx context.Context, pagination util.PaginationSpec, spec GetAttendancesSpec, sortRecent bool) (GetAttendancesResult, error)
 	// GetUnpaidAttendancesByTeacherId is specifically used for creating TeacherPaymentInvoice, thus have different filtering & sorting rule.
 	GetUnpaidAttendancesByTeacherId(ctx context.Context, spec GetUnpaidAttendancesByTeacherIdSpec) ([]Attendance, error)
@@ -280,6 +715,28 @@ type EntityService interface {
 	InsertTeacherPayments(ctx context.Context, specs []InsertTeacherPaymentSpec) ([]TeacherPaymentID, error)
 	UpdateTeacherPayments(ctx context.Context, specs []UpdateTeacherPaymentSpec) ([]TeacherPaymentID, error)
 	DeleteTeacherPayments(ctx context.Context, ids []TeacherPaymentID) error
+
+	// GetClosedPeriods returns ClosedPeriods sorted descendingly by ClosedUntil. Only the first one (the latest ClosedUntil) is in effect.
+	GetClosedPeriods(ctx context.Context, pagination util.PaginationSpec) (GetClosedPeriodsResult, error)
+	GetClosedPeriodById(ctx context.Context, id ClosedPeriodID) (ClosedPeriod, error)
+	// InsertClosedPeriods closes the accounting periods. The creator is taken from the context's AuthInfo.
+	InsertClosedPeriods(ctx context.Context, specs []InsertClosedPeriodSpec) ([]ClosedPeriodID, error)
+	// DeleteClosedPeriods reopens the accounting periods, back to the next latest ClosedPeriod (if any).
+	DeleteClosedPeriods(ctx context.Context, ids []ClosedPeriodID) error
+	GetClosedPeriodOverrides(ctx context.Context, pagination util.PaginationSpec) (GetClosedPeriodOverridesResult, error)
+	// EnsureDatesNotInClosedPeriod returns errs.ErrDateInClosedPeriod when any of spec.Dates is within the closed accounting period.
+	//
+	// A super admin may override it by providing a reason (see network.RequestContext.ClosedPeriodOverrideReason), which is then recorded as a ClosedPeriodOverride.
+	EnsureDatesNotInClosedPeriod(ctx context.Context, spec EnsureDatesNotInClosedPeriodSpec) error
+
+	// GetCashUpDayByDate returns the CashUpDay of a date (i.e. only its year, month & day are used), or sql.ErrNoRows when the day has not been closed.
+	GetCashUpDayByDate(ctx context.Context, date time.Time) (CashUpDay, error)
+	// InsertCashUpDay closes a day of EnrollmentPayments. The closer is taken from the context's AuthInfo.
+	InsertCashUpDay(ctx context.Context, spec InsertCashUpDaySpec) (CashUpDayID, error)
+	// DeleteCashUpDayByDate reopens a closed day. It returns sql.ErrNoRows when the day has not been closed.
+	DeleteCashUpDayByDate(ctx context.Context, date time.Time) error
+	// EnsurePaymentDatesNotInClosedCashUpDay returns errs.ErrCashUpDayClosed when any of paymentDates is on a closed CashUpDay.
+	EnsurePaymentDatesNotInClosedCashUpDay(ctx context.Context, paymentDates []time.Time) error
 }
 
 // ============================== STUDENT & TEACHER ==============================
@@ -404,6 +861,67 @@ type GetStudentEnrollmentsResult struct {
 	PaginationResult   util.PaginationResult
 }
 
+type EnrollStudentSpec struct {
+	StudentID StudentID
+	ClassID   ClassID
+	StartDate time.Time
+}
+
+type WithdrawStudentEnrollmentSpec struct {
+	StudentEnrollmentID StudentEnrollmentID
+	EndDate             time.Time
+	Reason              string
+}
+
+type ReEnrollStudentEnrollmentSpec struct {
+	StudentEnrollmentID StudentEnrollmentID
+	StartDate           time.Time
+}
+
+// ============================== STUDENT_ENROLLMENT_HOLD ==============================
+
+type InsertStudentEnrollmentHoldSpec struct {
+	StudentEnrollmentID StudentEnrollmentID
+	StartDate           time.Time
+	EndDate             time.Time
+	Reason              string
+}
+
+type UpdateStudentEnrollmentHoldSpec struct {
+	StudentEnrollmentHoldID StudentEnrollmentHoldID
+	StartDate               time.Time
+	EndDate                 time.Time
+	Reason                  string
+}
+
+func (s UpdateStudentEnrollmentHoldSpec) GetInt64ID() int64 {
+	return int64(s.StudentEnrollmentHoldID)
+}
+
+// ============================== FAMILY ==============================
+
+type GetFamiliesResult struct {
+	Families         []Family
+	PaginationResult util.PaginationResult
+}
+
+type InsertFamilySpec struct {
+	Name            string
+	StudentIDs      []StudentID
+	GuardianUserIDs []identity.UserID
+}
+
+type UpdateFamilySpec struct {
+	FamilyID        FamilyID
+	Name            string
+	StudentIDs      []StudentID
+	GuardianUserIDs []identity.UserID
+}
+
+func (s UpdateFamilySpec) GetInt64ID() int64 {
+	return int64(s.FamilyID)
+}
+
 // ============================== TEACHER_SPECIAL_FEE ==============================
 
 type GetTeacherSpecialFeesResult struct {
@@ -426,6 +944,123 @@ func (s UpdateTeacherSpecialFeeSpec) GetInt64ID() int64 {
 	return int64(s.TeacherSpecialFeeID)
 }
 
+// ============================== FEE_HISTORY ==============================
+
+type InsertCourseFeeHistorySpec struct {
+	CourseID      CourseID
+	Fee           int32
+	EffectiveFrom time.Time
+}
+
+type InsertTeacherSpecialFeeHistorySpec struct {
+	TeacherID     TeacherID
+	CourseID      CourseID
+	Fee           int32
+	EffectiveFrom time.Time
+}
+
+// ============================== PENALTY_POLICY ==============================
+
+type GetPenaltyPoliciesResult struct {
+	PenaltyPolicies  []PenaltyPolicy
+	PaginationResult util.PaginationResult
+}
+
+type InsertPenaltyPolicySpec struct {
+	Name              string
+	CourseID          CourseID
+	ClassID           ClassID
+	TriggerDayOfMonth int32
+	GraceDays         int32
+	IsFlatFee         bool
+	FeeValue          int32
+	MaxFeeValue       int32
+}
+
+type UpdatePenaltyPolicySpec struct {
+	PenaltyPolicyID   PenaltyPolicyID
+	Name              string
+	CourseID          CourseID
+	ClassID           ClassID
+	TriggerDayOfMonth int32
+	GraceDays         int32
+	IsFlatFee         bool
+	FeeValue          int32
+	MaxFeeValue       int32
+}
+
+func (s UpdatePenaltyPolicySpec) GetInt64ID() int64 {
+	return int64(s.PenaltyPolicyID)
+}
+
+// ============================== DISCOUNT_RULE ==============================
+
+type GetDiscountRulesResult struct {
+	DiscountRules    []DiscountRule
+	PaginationResult util.PaginationResult
+}
+
+type InsertDiscountRuleSpec struct {
+	Name          string
+	Type          DiscountRuleType
+	IsPercentage  bool
+	Value         int32
+	MinCount      int32
+	MaxDayOfMonth int32
+	VoucherCode   string
+	UsageLimit    int32
+	ValidFrom     *time.Time
+	ValidUntil    *time.Time
+	IsDeactivated bool
+}
+
+type UpdateDiscountRuleSpec struct {
+	DiscountRuleID DiscountRuleID
+	Name           string
+	Type           DiscountRuleType
+	IsPercentage   bool
+	Value          int32
+	MinCount       int32
+	MaxDayOfMonth  int32
+	VoucherCode    string
+	UsageLimit     int32
+	ValidFrom      *time.Time
+	ValidUntil     *time.Time
+	IsDeactivated  bool
+}
+
+func (s UpdateDiscountRuleSpec) GetInt64ID() int64 {
+	return int64(s.DiscountRuleID)
+}
+
+// ============================== TEACHER_FEE_SHARING ==============================
+
+type GetTeacherFeeSharingsResult struct {
+	TeacherFeeSharings []TeacherFeeSharing
+	PaginationResult   util.PaginationResult
+}
+
+type InsertTeacherFeeSharingSpec struct {
+	TeacherID                     TeacherID
+	CourseID                      CourseID
+	CourseFeeSharingPercentage    float64
+	TransportFeeSharingPercentage float64
+	EffectiveFrom                 time.Time
+}
+
+type UpdateTeacherFeeSharingSpec struct {
+	TeacherFeeSharingID           TeacherFeeSharingID
+	TeacherID                     TeacherID
+	CourseID                      CourseID
+	CourseFeeSharingPercentage    float64
+	TransportFeeSharingPercentage float64
+	EffectiveFrom                 time.Time
+}
+
+func (s UpdateTeacherFeeSharingSpec) GetInt64ID() int64 {
+	return int64(s.TeacherFeeSharingID)
+}
+
 // ============================== ENROLLMENT_PAYMENT ==============================
 
 type GetEnrollmentPaymentsResult struct {
@@ -442,6 +1077,9 @@ type InsertEnrollmentPaymentSpec struct {
 	TransportFeeValue   int32
 	PenaltyFeeValue     int32
 	DiscountFeeValue    int32
+	PaymentMethod       PaymentMethod
+	ReceivingAccount    string
+	ReferenceNumber     string
 }
 
 type UpdateEnrollmentPaymentSpec struct {
@@ -453,6 +1091,9 @@ type UpdateEnrollmentPaymentSpec struct {
 	TransportFeeValue   int32
 	PenaltyFeeValue     int32
 	DiscountFeeValue    int32
+	PaymentMethod       PaymentMethod
+	ReceivingAccount    string
+	ReferenceNumber     string
 }
 
 func (s UpdateEnrollmentPaymentSpec) GetInt64ID() int64 {
@@ -471,6 +1112,10 @@ type InsertStudentLearningTokenSpec struct {
 	Quota                    float64
 	CourseFeeQuarterValue    int32
 	TransportFeeQuarterValue int32
+
+	// QuotaChangeReason & QuotaChangeSourceID are recorded into the SLT ledger for the initial (non-zero) Quota. Defaults to "MANUAL" when empty.
+	QuotaChangeReason   SLTTransactionReason
+	QuotaChangeSourceID int64
 }
 
 type UpdateStudentLearningTokenSpec struct {
@@ -484,6 +1129,13 @@ func (s UpdateStudentLearningTokenSpec) GetInt64ID() int64 {
 	return int64(s.StudentLearningTokenID)
 }
 
+type InsertSLTTransactionSpec struct {
+	StudentLearningTokenID StudentLearningTokenID
+	QuotaChange            float64
+	Reason                 SLTTransactionReason
+	SourceID               int64
+}
+
 // ============================== ATTENDANCE ==============================
 
 type GetAttendancesSpec struct {
@@ -565,3 +1217,35 @@ type UpdateTeacherPaymentSpec struct {
 func (s UpdateTeacherPaymentSpec) GetInt64ID() int64 {
 	return int64(s.TeacherPaymentID)
 }
+
+// ============================== CLOSED_PERIOD ==============================
+
+type GetClosedPeriodsResult struct {
+	ClosedPeriods    []ClosedPeriod
+	PaginationResult util.PaginationResult
+}
+
+type InsertClosedPeriodSpec struct {
+	ClosedUntil time.Time
+	Note        string
+}
+
+type GetClosedPeriodOverridesResult struct {
+	ClosedPeriodOverrides []ClosedPeriodOverride
+	PaginationResult      util.PaginationResult
+}
+
+type EnsureDatesNotInClosedPeriodSpec struct {
+	// Operation is the name of the write operation, recorded on ClosedPeriodOverride (e.g. "UpdateAttendances")
+	Operation string
+	Dates     []time.Time
+}
+
+// ============================== CASH_UP_DAY ==============================
+
+type InsertCashUpDaySpec struct {
+	Date              time.Time
+	CountedCashValue  int64
+	RecordedCashValue int64
+	Note              string
+}
@@ -3,6 +3,7 @@ package impl
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"sort"
 	"time"
@@ -13,7 +14,9 @@ import (
 	"sonamusica-backend/app-service/identity"
 	"sonamusica-backend/app-service/util"
 	"sonamusica-backend/config"
+	"sonamusica-backend/errs"
 	"sonamusica-backend/logging"
+	"sonamusica-backend/network"
 )
 
 var (
@@ -722,6 +725,15 @@ func (s entityServiceImpl) InsertCourses(ctx context.Context, specs []entity.Ins
 			if err != nil {
 				return fmt.Errorf("qtx.InsertCourse(): %w", err)
 			}
+
+			_, err = qtx.InsertCourseFeeHistory(newCtx, mysql.InsertCourseFeeHistoryParams{
+				Fee:           spec.DefaultFee,
+				EffectiveFrom: util.ToLocalDate(time.Now()),
+				CourseID:      courseID,
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.InsertCourseFeeHistory(): %w", err)
+			}
 			courseIDs = append(courseIDs, entity.CourseID(courseID))
 		}
 		return nil
@@ -743,7 +755,22 @@ func (s entityServiceImpl) UpdateCourses(ctx context.Context, specs []entity.Upd
 
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
 		for _, spec := range specs {
-			err := qtx.UpdateCourseInfo(newCtx, mysql.UpdateCourseInfoParams{
+			prevCourse, err := qtx.GetCourseById(newCtx, int64(spec.CourseID))
+			if err != nil {
+				return fmt.Errorf("qtx.GetCourseById(): %w", err)
+			}
+			if prevCourse.DefaultFee != spec.DefaultFee {
+				_, err = qtx.InsertCourseFeeHistory(newCtx, mysql.InsertCourseFeeHistoryParams{
+					Fee:           spec.DefaultFee,
+					EffectiveFrom: util.ToLocalDate(time.Now()),
+					CourseID:      int64(spec.CourseID),
+				})
+				if err != nil {
+					return fmt.Errorf("qtx.InsertCourseFeeHistory(): %w", err)
+				}
+			}
+
+			err = qtx.UpdateCourseInfo(newCtx, mysql.UpdateCourseInfoParams{
 				DefaultFee:            spec.DefaultFee,
 				GradeID:               int64(spec.GradeID),
 				DefaultDurationMinute: spec.DefaultDurationMinute,
@@ -914,12 +941,18 @@ func (s entityServiceImpl) GetClassesWithoutToken(ctx context.Context, timeSpec
 
 func (s entityServiceImpl) GetClassById(ctx context.Context, id entity.ClassID) (entity.Class, error) {
 	var classRows = make([]mysql.GetClassByIdRow, 0)
+	var holdRows = make([]mysql.GetStudentEnrollmentHoldsByClassIdRow, 0)
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
 		var err error
 		classRows, err = qtx.GetClassById(newCtx, int64(id))
 		if err != nil {
 			return fmt.Errorf("qtx.GetClassById(): %w", err)
 		}
+
+		holdRows, err = qtx.GetStudentEnrollmentHoldsByClassId(newCtx, int64(id))
+		if err != nil {
+			return fmt.Errorf("qtx.GetStudentEnrollmentHoldsByClassId(): %w", err)
+		}
 		return nil
 	})
 	if err != nil {
@@ -937,6 +970,17 @@ func (s entityServiceImpl) GetClassById(ctx context.Context, id entity.ClassID)
 
 	class := NewClassesFromGetClassesRow(classRowsConverted)[0]
 
+	holdRowsConverted := make([]mysql.GetStudentEnrollmentHoldsByStudentEnrollmentIdsRow, 0, len(holdRows))
+	for _, row := range holdRows {
+		holdRowsConverted = append(holdRowsConverted, row.ToGetStudentEnrollmentHoldsByStudentEnrollmentIdsRow())
+	}
+	now := time.Now()
+	for _, hold := range NewStudentEnrollmentHoldsFromGetStudentEnrollmentHoldsByStudentEnrollmentIdsRow(holdRowsConverted) {
+		if hold.IsActiveAt(now) {
+			class.ActiveHolds = append(class.ActiveHolds, hold)
+		}
+	}
+
 	return class, nil
 }
 
@@ -973,6 +1017,7 @@ func (s entityServiceImpl) InsertClasses(ctx context.Context, specs []entity.Ins
 	classIDs := make([]entity.ClassID, 0, len(specs))
 
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		today := util.ToLocalDate(time.Now())
 		for _, spec := range specs {
 			classID, err := qtx.InsertClass(newCtx, mysql.InsertClassParams{
 				TransportFee:           spec.TransportFee,
@@ -987,7 +1032,7 @@ func (s entityServiceImpl) InsertClasses(ctx context.Context, specs []entity.Ins
 			classIDs = append(classIDs, entity.ClassID(classID))
 
 			for _, studentId := range spec.StudentIDs {
-				err := qtx.InsertStudentEnrollment(newCtx, mysql.InsertStudentEnrollmentParams{
+				studentEnrollmentID, err := qtx.InsertStudentEnrollment(newCtx, mysql.InsertStudentEnrollmentParams{
 					StudentID: int64(studentId),
 					ClassID:   classID,
 				})
@@ -995,6 +1040,14 @@ func (s entityServiceImpl) InsertClasses(ctx context.Context, specs []entity.Ins
 
 					return fmt.Errorf("qtx.InsertStudentEnrollment(): %w", err)
 				}
+
+				_, err = qtx.InsertStudentEnrollmentPeriod(newCtx, mysql.InsertStudentEnrollmentPeriodParams{
+					StartDate:    today,
+					EnrollmentID: studentEnrollmentID,
+				})
+				if err != nil {
+					return fmt.Errorf("qtx.InsertStudentEnrollmentPeriod(): %w", err)
+				}
 			}
 		}
 		return nil
@@ -1015,6 +1068,7 @@ func (s entityServiceImpl) UpdateClasses(ctx context.Context, specs []entity.Upd
 	classIDs := make([]entity.ClassID, 0, len(specs))
 
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		today := util.ToLocalDate(time.Now())
 		for _, spec := range specs {
 			classId := int64(spec.ClassID)
 			// Updated class
@@ -1040,7 +1094,7 @@ func (s entityServiceImpl) UpdateClasses(ctx context.Context, specs []entity.Upd
 
 			// Added students
 			for _, studentId := range studentDifference.addedStudentIDs {
-				err = qtx.InsertStudentEnrollment(newCtx, mysql.InsertStudentEnrollmentParams{
+				studentEnrollmentID, err := qtx.InsertStudentEnrollment(newCtx, mysql.InsertStudentEnrollmentParams{
 					StudentID: int64(studentId),
 					ClassID:   classId,
 				})
@@ -1048,14 +1102,27 @@ func (s entityServiceImpl) UpdateClasses(ctx context.Context, specs []entity.Upd
 
 					return fmt.Errorf("qtx.InsertStudentEnrollment(): %w", err)
 				}
+
+				_, err = qtx.InsertStudentEnrollmentPeriod(newCtx, mysql.InsertStudentEnrollmentPeriodParams{
+					StartDate:    today,
+					EnrollmentID: studentEnrollmentID,
+				})
+				if err != nil {
+					return fmt.Errorf("qtx.InsertStudentEnrollmentPeriod(): %w", err)
+				}
 			}
 
 			// Updated (re-enabled) enrollments
 			for _, updatedEnrollmentID := range studentDifference.enabledStudentEnrollmentIDs {
-				err = qtx.EnableStudentEnrollment(newCtx, int64(updatedEnrollmentID))
+				err = reEnrollStudentEnrollment(newCtx, qtx, updatedEnrollmentID, today)
+				if errors.Is(err, errs.ErrStudentEnrollmentPeriodOverlaps) {
+					// the student was withdrawn today (or later), thus the previous period simply continues
+					err = reopenLatestStudentEnrollmentPeriod(newCtx, qtx, updatedEnrollmentID)
+				} else if errors.Is(err, errs.ErrStudentEnrollmentAlreadyActive) {
+					err = qtx.EnableStudentEnrollment(newCtx, int64(updatedEnrollmentID))
+				}
 				if err != nil {
-
-					return fmt.Errorf("qtx.EnableStudentEnrollment(): %w", err)
+					return fmt.Errorf("re-enabling studentEnrollmentID '%d': %w", updatedEnrollmentID, err)
 				}
 			}
 
@@ -1071,6 +1138,11 @@ func (s entityServiceImpl) UpdateClasses(ctx context.Context, specs []entity.Upd
 				if err != nil {
 					return fmt.Errorf("qtx.DisableStudentEnrollment(): %w", err)
 				}
+
+				err = endOngoingStudentEnrollmentPeriod(newCtx, qtx, disabledEnrollmentID, today)
+				if err != nil {
+					return fmt.Errorf("endOngoingStudentEnrollmentPeriod(): %w", err)
+				}
 			}
 		}
 		return nil
@@ -1232,152 +1304,181 @@ func (s entityServiceImpl) GetStudentEnrollmentsByClassId(ctx context.Context, c
 	return studentEnrollments, nil
 }
 
-func (s entityServiceImpl) GetTeacherSpecialFees(ctx context.Context, pagination util.PaginationSpec) (entity.GetTeacherSpecialFeesResult, error) {
-	pagination.SetDefaultOnInvalidValues()
-	limit, offset := pagination.GetLimitAndOffset()
-
-	var teacherSpecialFeeRows = make([]mysql.GetTeacherSpecialFeesRow, 0)
-	var totalResults int64 = 0
+func (s entityServiceImpl) GetStudentEnrollmentsActiveAtDateByClassId(ctx context.Context, classId entity.ClassID, date time.Time) ([]entity.StudentEnrollment, error) {
+	var studentEnrollmentRows = make([]mysql.GetStudentEnrollmentsActiveAtDateByClassIdRow, 0)
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
 		var err error
-		teacherSpecialFeeRows, err = qtx.GetTeacherSpecialFees(newCtx, mysql.GetTeacherSpecialFeesParams{
-			Limit:  int32(limit),
-			Offset: int32(offset),
+		studentEnrollmentRows, err = qtx.GetStudentEnrollmentsActiveAtDateByClassId(newCtx, mysql.GetStudentEnrollmentsActiveAtDateByClassIdParams{
+			ClassID: int64(classId),
+			Date:    util.ToLocalDate(date),
 		})
 		if err != nil {
-			return fmt.Errorf("qtx.GetTeacherSpecialFees(): %w", err)
-		}
-
-		totalResults, err = qtx.CountTeacherSpecialFees(newCtx)
-		if err != nil {
-			return fmt.Errorf("qtx.CountTeacherSpecialFees(): %w", err)
+			return fmt.Errorf("qtx.GetStudentEnrollmentsActiveAtDateByClassId(): %w", err)
 		}
 		return nil
 	})
 	if err != nil {
-		return entity.GetTeacherSpecialFeesResult{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+		return []entity.StudentEnrollment{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
 	}
 
-	teacherSpecialFees := NewTeacherSpecialFeesFromGetTeacherSpecialFeesRow(teacherSpecialFeeRows)
+	studentEnrollmentRowsConverted := make([]mysql.GetStudentEnrollmentsRow, 0, len(studentEnrollmentRows))
+	for _, row := range studentEnrollmentRows {
+		studentEnrollmentRowsConverted = append(studentEnrollmentRowsConverted, row.ToGetStudentEnrollmentsRow())
+	}
 
-	return entity.GetTeacherSpecialFeesResult{
-		TeacherSpecialFees: teacherSpecialFees,
-		PaginationResult:   *util.NewPaginationResult(int(totalResults), pagination.ResultsPerPage, pagination.Page),
-	}, nil
+	studentEnrollments := NewStudentEnrollmentsFromGetStudentEnrollmentsRow(studentEnrollmentRowsConverted)
+
+	return studentEnrollments, nil
 }
 
-func (s entityServiceImpl) GetTeacherSpecialFeeById(ctx context.Context, id entity.TeacherSpecialFeeID) (entity.TeacherSpecialFee, error) {
-	var teacherSpecialFeeRow mysql.GetTeacherSpecialFeeByIdRow
+func (s entityServiceImpl) GetStudentEnrollmentHistoriesByStudentId(ctx context.Context, studentId entity.StudentID) ([]entity.StudentEnrollmentHistory, error) {
+	var studentEnrollmentRows = make([]mysql.GetStudentEnrollmentsIncludingWithdrawnByStudentIdRow, 0)
+	var periodRows = make([]mysql.StudentEnrollmentPeriod, 0)
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
 		var err error
-		teacherSpecialFeeRow, err = qtx.GetTeacherSpecialFeeById(newCtx, int64(id))
+		studentEnrollmentRows, err = qtx.GetStudentEnrollmentsIncludingWithdrawnByStudentId(newCtx, int64(studentId))
 		if err != nil {
-			return fmt.Errorf("qtx.GetTeacherSpecialFeeById(): %w", err)
+			return fmt.Errorf("qtx.GetStudentEnrollmentsIncludingWithdrawnByStudentId(): %w", err)
+		}
+		if len(studentEnrollmentRows) == 0 {
+			return nil
 		}
-		return nil
-	})
-	if err != nil {
-		return entity.TeacherSpecialFee{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
-	}
-
-	teacherSpecialFee := NewTeacherSpecialFeesFromGetTeacherSpecialFeesRow([]mysql.GetTeacherSpecialFeesRow{teacherSpecialFeeRow.ToGetTeacherSpecialFeesRow()})[0]
-
-	return teacherSpecialFee, nil
-}
-
-func (s entityServiceImpl) GetTeacherSpecialFeesByIds(ctx context.Context, ids []entity.TeacherSpecialFeeID) ([]entity.TeacherSpecialFee, error) {
-	idsInt := make([]int64, 0, len(ids))
-	for _, id := range ids {
-		idsInt = append(idsInt, int64(id))
-	}
 
-	var teacherSpecialFeeRows = make([]mysql.GetTeacherSpecialFeesByIdsRow, 0)
-	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
-		var err error
-		teacherSpecialFeeRows, err = qtx.GetTeacherSpecialFeesByIds(newCtx, idsInt)
+		studentEnrollmentIDs := make([]int64, 0, len(studentEnrollmentRows))
+		for _, row := range studentEnrollmentRows {
+			studentEnrollmentIDs = append(studentEnrollmentIDs, row.StudentEnrollmentID)
+		}
+		periodRows, err = qtx.GetStudentEnrollmentPeriodsByStudentEnrollmentIds(newCtx, studentEnrollmentIDs)
 		if err != nil {
-			return fmt.Errorf("qtx.GetTeacherSpecialFeesByIds(): %w", err)
+			return fmt.Errorf("qtx.GetStudentEnrollmentPeriodsByStudentEnrollmentIds(): %w", err)
 		}
 		return nil
 	})
 	if err != nil {
-		return []entity.TeacherSpecialFee{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+		return []entity.StudentEnrollmentHistory{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
 	}
 
-	teacherSpecialFeeRowsConverted := make([]mysql.GetTeacherSpecialFeesRow, 0, len(teacherSpecialFeeRows))
-	for _, row := range teacherSpecialFeeRows {
-		teacherSpecialFeeRowsConverted = append(teacherSpecialFeeRowsConverted, row.ToGetTeacherSpecialFeesRow())
+	studentEnrollmentRowsConverted := make([]mysql.GetStudentEnrollmentsRow, 0, len(studentEnrollmentRows))
+	for _, row := range studentEnrollmentRows {
+		studentEnrollmentRowsConverted = append(studentEnrollmentRowsConverted, row.ToGetStudentEnrollmentsRow())
 	}
 
-	teacherSpecialFees := NewTeacherSpecialFeesFromGetTeacherSpecialFeesRow(teacherSpecialFeeRowsConverted)
+	studentEnrollments := NewStudentEnrollmentsFromGetStudentEnrollmentsRow(studentEnrollmentRowsConverted)
 
-	return teacherSpecialFees, nil
+	return NewStudentEnrollmentHistories(studentEnrollments, periodRows), nil
 }
 
-func (s entityServiceImpl) InsertTeacherSpecialFees(ctx context.Context, specs []entity.InsertTeacherSpecialFeeSpec) ([]entity.TeacherSpecialFeeID, error) {
-	teacherSpecialFeeIDs := make([]entity.TeacherSpecialFeeID, 0, len(specs))
-
+func (s entityServiceImpl) EnrollStudent(ctx context.Context, spec entity.EnrollStudentSpec) (entity.StudentEnrollmentID, error) {
+	var studentEnrollmentID entity.StudentEnrollmentID
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
-		for _, spec := range specs {
-			teacherSpecialFeeID, err := qtx.InsertTeacherSpecialFee(newCtx, mysql.InsertTeacherSpecialFeeParams{
-				Fee:       spec.Fee,
-				TeacherID: int64(spec.TeacherID),
-				CourseID:  int64(spec.CourseID),
-			})
-			if err != nil {
+		startDate := util.ToLocalDate(spec.StartDate)
 
-				return fmt.Errorf("qtx.InsertTeacherSpecialFee(): %w", err)
+		studentEnrollmentRow, err := qtx.GetStudentEnrollmentByStudentIdAndClassId(newCtx, mysql.GetStudentEnrollmentByStudentIdAndClassIdParams{
+			StudentID: int64(spec.StudentID),
+			ClassID:   int64(spec.ClassID),
+		})
+		if err == nil {
+			// the student has been enrolled into the class before (the student enrollment is unique per student & class), thus we re-enroll it
+			studentEnrollmentID = entity.StudentEnrollmentID(studentEnrollmentRow.ID)
+			err = reEnrollStudentEnrollment(newCtx, qtx, studentEnrollmentID, startDate)
+			if err != nil {
+				return fmt.Errorf("reEnrollStudentEnrollment(): %w", err)
 			}
-			teacherSpecialFeeIDs = append(teacherSpecialFeeIDs, entity.TeacherSpecialFeeID(teacherSpecialFeeID))
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("qtx.GetStudentEnrollmentByStudentIdAndClassId(): %w", err)
+		}
+
+		newStudentEnrollmentID, err := qtx.InsertStudentEnrollment(newCtx, mysql.InsertStudentEnrollmentParams{
+			StudentID: int64(spec.StudentID),
+			ClassID:   int64(spec.ClassID),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.InsertStudentEnrollment(): %w", err)
+		}
+		studentEnrollmentID = entity.StudentEnrollmentID(newStudentEnrollmentID)
+
+		_, err = qtx.InsertStudentEnrollmentPeriod(newCtx, mysql.InsertStudentEnrollmentPeriodParams{
+			StartDate:    startDate,
+			EnrollmentID: newStudentEnrollmentID,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.InsertStudentEnrollmentPeriod(): %w", err)
 		}
 		return nil
 	})
 	if err != nil {
-		return []entity.TeacherSpecialFeeID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+		return entity.StudentEnrollmentID_None, fmt.Errorf("ExecuteInTransaction(): %w", err)
 	}
 
-	return teacherSpecialFeeIDs, nil
+	return studentEnrollmentID, nil
 }
 
-func (s entityServiceImpl) UpdateTeacherSpecialFees(ctx context.Context, specs []entity.UpdateTeacherSpecialFeeSpec) ([]entity.TeacherSpecialFeeID, error) {
-	errV := util.ValidateUpdateSpecs(ctx, specs, s.mySQLQueries.CountTeacherSpecialFeesByIds)
-	if errV != nil {
-		return []entity.TeacherSpecialFeeID{}, errV
-	}
+func (s entityServiceImpl) WithdrawStudentEnrollment(ctx context.Context, spec entity.WithdrawStudentEnrollmentSpec) error {
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		endDate := util.ToLocalDate(spec.EndDate)
 
-	teacherSpecialFeeIDs := make([]entity.TeacherSpecialFeeID, 0, len(specs))
+		latestPeriod, err := qtx.GetLatestStudentEnrollmentPeriod(newCtx, int64(spec.StudentEnrollmentID))
+		if err != nil {
+			return fmt.Errorf("qtx.GetLatestStudentEnrollmentPeriod(): %w", err)
+		}
+		if latestPeriod.EndDate.Valid {
+			return fmt.Errorf("studentEnrollmentID '%d': %w", spec.StudentEnrollmentID, errs.ErrStudentEnrollmentNotActive)
+		}
+		if endDate.Before(latestPeriod.StartDate) {
+			return fmt.Errorf("endDate '%s' is before the period's startDate '%s': %w", endDate.Format("2006-01-02"), latestPeriod.StartDate.Format("2006-01-02"), errs.ErrStudentEnrollmentPeriodOverlaps)
+		}
 
-	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
-		for _, spec := range specs {
-			err := qtx.UpdateTeacherSpecialFee(newCtx, mysql.UpdateTeacherSpecialFeeParams{
-				Fee: spec.Fee,
-				ID:  int64(spec.TeacherSpecialFeeID),
-			})
-			if err != nil {
+		attendanceIDs, err := qtx.GetAttendanceIdsByStudentEnrollmentIdFromDate(newCtx, mysql.GetAttendanceIdsByStudentEnrollmentIdFromDateParams{
+			EnrollmentID: int64(spec.StudentEnrollmentID),
+			Date:         endDate.AddDate(0, 0, 1),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetAttendanceIdsByStudentEnrollmentIdFromDate(): %w", err)
+		}
+		if len(attendanceIDs) > 0 {
+			return fmt.Errorf("attendanceIDs '%v' are after endDate '%s': %w", attendanceIDs, endDate.Format("2006-01-02"), errs.ErrAttendanceAfterWithdrawal)
+		}
 
-				return fmt.Errorf("qtx.UpdateTeacherSpecialFee(): %w", err)
+		err = qtx.EndStudentEnrollmentPeriod(newCtx, mysql.EndStudentEnrollmentPeriodParams{
+			EndDate:          sql.NullTime{Time: endDate, Valid: true},
+			WithdrawalReason: spec.Reason,
+			ID:               latestPeriod.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.EndStudentEnrollmentPeriod(): %w", err)
+		}
+
+		// a future withdrawal keeps the StudentEnrollment enabled (e.g. in the class roster) until the endDate has passed, check DisableEndedStudentEnrollments()
+		if endDate.Before(util.ToLocalDate(time.Now())) {
+			err = qtx.DisableStudentEnrollment(newCtx, int64(spec.StudentEnrollmentID))
+			if err != nil {
+				return fmt.Errorf("qtx.DisableStudentEnrollment(): %w", err)
 			}
-			teacherSpecialFeeIDs = append(teacherSpecialFeeIDs, spec.TeacherSpecialFeeID)
 		}
 		return nil
 	})
 	if err != nil {
-		return []entity.TeacherSpecialFeeID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
 	}
 
-	return teacherSpecialFeeIDs, nil
+	return nil
 }
 
-func (s entityServiceImpl) DeleteTeacherSpecialFees(ctx context.Context, ids []entity.TeacherSpecialFeeID) error {
-	teacherSpecialFeeIdsInt64 := make([]int64, 0, len(ids))
-	for _, id := range ids {
-		teacherSpecialFeeIdsInt64 = append(teacherSpecialFeeIdsInt64, int64(id))
+func (s entityServiceImpl) DisableEndedStudentEnrollments(ctx context.Context) (int64, error) {
+	affectedStudentEnrollments, err := s.mySQLQueries.DisableStudentEnrollmentsWithEndedPeriods(ctx, util.ToLocalDate(time.Now()))
+	if err != nil {
+		return 0, fmt.Errorf("mySQLQueries.DisableStudentEnrollmentsWithEndedPeriods(): %w", err)
 	}
+	return affectedStudentEnrollments, nil
+}
 
+func (s entityServiceImpl) ReEnrollStudentEnrollment(ctx context.Context, spec entity.ReEnrollStudentEnrollmentSpec) error {
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
-		err := qtx.DeleteTeacherSpecialFeesByIds(newCtx, teacherSpecialFeeIdsInt64)
+		err := reEnrollStudentEnrollment(newCtx, qtx, spec.StudentEnrollmentID, util.ToLocalDate(spec.StartDate))
 		if err != nil {
-			return fmt.Errorf("qtx.DeleteTeacherSpecialFeeByIds(): %w", err)
+			return fmt.Errorf("reEnrollStudentEnrollment(): %w", err)
 		}
 		return nil
 	})
@@ -1388,127 +1489,1549 @@ func (s entityServiceImpl) DeleteTeacherSpecialFees(ctx context.Context, ids []e
 	return nil
 }
 
-func (s entityServiceImpl) GetEnrollmentPayments(ctx context.Context, pagination util.PaginationSpec, timeFilter util.TimeSpec, sortRecent bool) (entity.GetEnrollmentPaymentsResult, error) {
-	pagination.SetDefaultOnInvalidValues()
-	limit, offset := pagination.GetLimitAndOffset()
-
-	timeFilter.SetDefaultForZeroValues()
-
-	var enrollmentPaymentRows = make([]mysql.GetEnrollmentPaymentsRow, 0)
-	var totalResults int64 = 0
-	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
-		var err error
-		if !sortRecent { // TODO: find alternative: currently sqlc dynamic query is so bad that we need to do this :(
-			enrollmentPaymentRows, err = qtx.GetEnrollmentPayments(newCtx, mysql.GetEnrollmentPaymentsParams{
-				StartDate: timeFilter.StartDatetime,
-				EndDate:   timeFilter.EndDatetime,
-				Limit:     int32(limit),
-				Offset:    int32(offset),
-			})
-			if err != nil {
-				return fmt.Errorf("qtx.GetEnrollmentPayments(): %w", err)
-			}
+// reEnrollStudentEnrollment starts a new period of a withdrawn StudentEnrollment from startDate (a local date), then re-enables the StudentEnrollment.
+func reEnrollStudentEnrollment(ctx context.Context, qtx *mysql.Queries, studentEnrollmentID entity.StudentEnrollmentID, startDate time.Time) error {
+	latestPeriod, err := qtx.GetLatestStudentEnrollmentPeriod(ctx, int64(studentEnrollmentID))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("qtx.GetLatestStudentEnrollmentPeriod(): %w", err)
+	}
+	if err == nil {
+		if !latestPeriod.EndDate.Valid {
+			return fmt.Errorf("studentEnrollmentID '%d': %w", studentEnrollmentID, errs.ErrStudentEnrollmentAlreadyActive)
+		}
+		if !startDate.After(latestPeriod.EndDate.Time) {
+			return fmt.Errorf("startDate '%s' is not after the previous period's endDate '%s': %w", startDate.Format("2006-01-02"), latestPeriod.EndDate.Time.Format("2006-01-02"), errs.ErrStudentEnrollmentPeriodOverlaps)
+		}
+	}
 
-		} else {
-			enrollmentPaymentDescendingDateRows, err := qtx.GetEnrollmentPaymentsDescendingDate(newCtx, mysql.GetEnrollmentPaymentsDescendingDateParams{
-				StartDate: timeFilter.StartDatetime,
-				EndDate:   timeFilter.EndDatetime,
-				Limit:     int32(limit),
-				Offset:    int32(offset),
-			})
-			if err != nil {
-				return fmt.Errorf("qtx.GetEnrollmentPaymentsDescendingDate(): %w", err)
-			}
+	_, err = qtx.InsertStudentEnrollmentPeriod(ctx, mysql.InsertStudentEnrollmentPeriodParams{
+		StartDate:    startDate,
+		EnrollmentID: int64(studentEnrollmentID),
+	})
+	if err != nil {
+		return fmt.Errorf("qtx.InsertStudentEnrollmentPeriod(): %w", err)
+	}
 
-			for _, row := range enrollmentPaymentDescendingDateRows {
-				enrollmentPaymentRows = append(enrollmentPaymentRows, row.ToGetEnrollmentPaymentsRow())
-			}
-		}
+	err = qtx.EnableStudentEnrollment(ctx, int64(studentEnrollmentID))
+	if err != nil {
+		return fmt.Errorf("qtx.EnableStudentEnrollment(): %w", err)
+	}
+	return nil
+}
 
-		totalResults, err = qtx.CountEnrollmentPayments(newCtx)
-		if err != nil {
-			return fmt.Errorf("qtx.CountEnrollmentPayments(): %w", err)
-		}
+// endOngoingStudentEnrollmentPeriod ends the StudentEnrollment's ongoing period (if any) at endDate, or at the period's start when it starts after endDate.
+func endOngoingStudentEnrollmentPeriod(ctx context.Context, qtx *mysql.Queries, studentEnrollmentID entity.StudentEnrollmentID, endDate time.Time) error {
+	latestPeriod, err := qtx.GetLatestStudentEnrollmentPeriod(ctx, int64(studentEnrollmentID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("qtx.GetLatestStudentEnrollmentPeriod(): %w", err)
+	}
+	if latestPeriod.EndDate.Valid {
 		return nil
+	}
+
+	if endDate.Before(latestPeriod.StartDate) {
+		endDate = latestPeriod.StartDate
+	}
+	err = qtx.EndStudentEnrollmentPeriod(ctx, mysql.EndStudentEnrollmentPeriodParams{
+		EndDate: sql.NullTime{Time: endDate, Valid: true},
+		ID:      latestPeriod.ID,
 	})
 	if err != nil {
-		return entity.GetEnrollmentPaymentsResult{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+		return fmt.Errorf("qtx.EndStudentEnrollmentPeriod(): %w", err)
 	}
+	return nil
+}
 
-	enrollmentPayments := NewEnrollmentPaymentsFromGetEnrollmentPaymentsRow(enrollmentPaymentRows)
+// reopenLatestStudentEnrollmentPeriod removes the end of the StudentEnrollment's latest period, then re-enables the StudentEnrollment.
+func reopenLatestStudentEnrollmentPeriod(ctx context.Context, qtx *mysql.Queries, studentEnrollmentID entity.StudentEnrollmentID) error {
+	latestPeriod, err := qtx.GetLatestStudentEnrollmentPeriod(ctx, int64(studentEnrollmentID))
+	if err != nil {
+		return fmt.Errorf("qtx.GetLatestStudentEnrollmentPeriod(): %w", err)
+	}
 
-	return entity.GetEnrollmentPaymentsResult{
-		EnrollmentPayments: enrollmentPayments,
-		PaginationResult:   *util.NewPaginationResult(int(totalResults), pagination.ResultsPerPage, pagination.Page),
-	}, nil
+	err = qtx.EndStudentEnrollmentPeriod(ctx, mysql.EndStudentEnrollmentPeriodParams{
+		EndDate: sql.NullTime{Valid: false},
+		ID:      latestPeriod.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("qtx.EndStudentEnrollmentPeriod(): %w", err)
+	}
+
+	err = qtx.EnableStudentEnrollment(ctx, int64(studentEnrollmentID))
+	if err != nil {
+		return fmt.Errorf("qtx.EnableStudentEnrollment(): %w", err)
+	}
+	return nil
 }
 
-func (s entityServiceImpl) GetEnrollmentPaymentById(ctx context.Context, id entity.EnrollmentPaymentID) (entity.EnrollmentPayment, error) {
-	var enrollmentPaymentRow mysql.GetEnrollmentPaymentByIdRow
+func (s entityServiceImpl) GetStudentEnrollmentHoldById(ctx context.Context, id entity.StudentEnrollmentHoldID) (entity.StudentEnrollmentHold, error) {
+	var holdRow mysql.GetStudentEnrollmentHoldByIdRow
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
 		var err error
-		enrollmentPaymentRow, err = qtx.GetEnrollmentPaymentById(newCtx, int64(id))
+		holdRow, err = qtx.GetStudentEnrollmentHoldById(newCtx, int64(id))
 		if err != nil {
-			return fmt.Errorf("qtx.GetEnrollmentPaymentById(): %w", err)
+			return fmt.Errorf("qtx.GetStudentEnrollmentHoldById(): %w", err)
 		}
 		return nil
 	})
 	if err != nil {
-		return entity.EnrollmentPayment{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+		return entity.StudentEnrollmentHold{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
 	}
 
-	enrollmentPayment := NewEnrollmentPaymentsFromGetEnrollmentPaymentsRow([]mysql.GetEnrollmentPaymentsRow{enrollmentPaymentRow.ToGetEnrollmentPaymentsRow()})[0]
+	holds := NewStudentEnrollmentHoldsFromGetStudentEnrollmentHoldsByStudentEnrollmentIdsRow([]mysql.GetStudentEnrollmentHoldsByStudentEnrollmentIdsRow{holdRow.ToGetStudentEnrollmentHoldsByStudentEnrollmentIdsRow()})
 
-	return enrollmentPayment, nil
+	return holds[0], nil
 }
 
-func (s entityServiceImpl) GetEnrollmentPaymentsByIds(ctx context.Context, ids []entity.EnrollmentPaymentID) ([]entity.EnrollmentPayment, error) {
+func (s entityServiceImpl) GetStudentEnrollmentHoldsByIds(ctx context.Context, ids []entity.StudentEnrollmentHoldID) ([]entity.StudentEnrollmentHold, error) {
 	idsInt := make([]int64, 0, len(ids))
 	for _, id := range ids {
 		idsInt = append(idsInt, int64(id))
 	}
 
-	var enrollmentPaymentRows = make([]mysql.GetEnrollmentPaymentsByIdsRow, 0)
+	var holdRows = make([]mysql.GetStudentEnrollmentHoldsByIdsRow, 0)
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
 		var err error
-		enrollmentPaymentRows, err = qtx.GetEnrollmentPaymentsByIds(newCtx, idsInt)
+		holdRows, err = qtx.GetStudentEnrollmentHoldsByIds(newCtx, idsInt)
 		if err != nil {
-			return fmt.Errorf("qtx.GetEnrollmentPaymentsByIds(): %w", err)
+			return fmt.Errorf("qtx.GetStudentEnrollmentHoldsByIds(): %w", err)
 		}
 		return nil
 	})
 	if err != nil {
-		return []entity.EnrollmentPayment{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+		return []entity.StudentEnrollmentHold{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
 	}
 
-	enrollmentPaymentRowsConverted := make([]mysql.GetEnrollmentPaymentsRow, 0, len(enrollmentPaymentRows))
-	for _, row := range enrollmentPaymentRows {
-		enrollmentPaymentRowsConverted = append(enrollmentPaymentRowsConverted, row.ToGetEnrollmentPaymentsRow())
+	holdRowsConverted := make([]mysql.GetStudentEnrollmentHoldsByStudentEnrollmentIdsRow, 0, len(holdRows))
+	for _, row := range holdRows {
+		holdRowsConverted = append(holdRowsConverted, row.ToGetStudentEnrollmentHoldsByStudentEnrollmentIdsRow())
 	}
 
-	enrollmentPayments := NewEnrollmentPaymentsFromGetEnrollmentPaymentsRow(enrollmentPaymentRowsConverted)
-
-	return enrollmentPayments, nil
+	return NewStudentEnrollmentHoldsFromGetStudentEnrollmentHoldsByStudentEnrollmentIdsRow(holdRowsConverted), nil
 }
 
-func (s entityServiceImpl) InsertEnrollmentPayments(ctx context.Context, specs []entity.InsertEnrollmentPaymentSpec) ([]entity.EnrollmentPaymentID, error) {
-	enrollmentPaymentIDs := make([]entity.EnrollmentPaymentID, 0, len(specs))
+func (s entityServiceImpl) GetStudentEnrollmentHoldsByStudentEnrollmentIds(ctx context.Context, studentEnrollmentIDs []entity.StudentEnrollmentID) ([]entity.StudentEnrollmentHold, error) {
+	idsInt := make([]int64, 0, len(studentEnrollmentIDs))
+	for _, id := range studentEnrollmentIDs {
+		idsInt = append(idsInt, int64(id))
+	}
 
+	var holdRows = make([]mysql.GetStudentEnrollmentHoldsByStudentEnrollmentIdsRow, 0)
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		holdRows, err = qtx.GetStudentEnrollmentHoldsByStudentEnrollmentIds(newCtx, idsInt)
+		if err != nil {
+			return fmt.Errorf("qtx.GetStudentEnrollmentHoldsByStudentEnrollmentIds(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.StudentEnrollmentHold{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return NewStudentEnrollmentHoldsFromGetStudentEnrollmentHoldsByStudentEnrollmentIdsRow(holdRows), nil
+}
+
+func (s entityServiceImpl) InsertStudentEnrollmentHolds(ctx context.Context, specs []entity.InsertStudentEnrollmentHoldSpec) ([]entity.StudentEnrollmentHoldID, error) {
+	holdIDs := make([]entity.StudentEnrollmentHoldID, 0, len(specs))
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		for _, spec := range specs {
+			startDate, endDate := util.ToLocalDate(spec.StartDate), util.ToLocalDate(spec.EndDate)
+			err := validateStudentEnrollmentHoldNotOverlapping(newCtx, qtx, spec.StudentEnrollmentID, entity.StudentEnrollmentHoldID_None, startDate, endDate)
+			if err != nil {
+				return fmt.Errorf("validateStudentEnrollmentHoldNotOverlapping(): %w", err)
+			}
+
+			holdID, err := qtx.InsertStudentEnrollmentHold(newCtx, mysql.InsertStudentEnrollmentHoldParams{
+				StartDate:    startDate,
+				EndDate:      endDate,
+				Reason:       spec.Reason,
+				EnrollmentID: int64(spec.StudentEnrollmentID),
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.InsertStudentEnrollmentHold(): %w", err)
+			}
+			holdIDs = append(holdIDs, entity.StudentEnrollmentHoldID(holdID))
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.StudentEnrollmentHoldID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return holdIDs, nil
+}
+
+func (s entityServiceImpl) UpdateStudentEnrollmentHolds(ctx context.Context, specs []entity.UpdateStudentEnrollmentHoldSpec) ([]entity.StudentEnrollmentHoldID, error) {
+	errV := util.ValidateUpdateSpecs(ctx, specs, s.mySQLQueries.CountStudentEnrollmentHoldsByIds)
+	if errV != nil {
+		return []entity.StudentEnrollmentHoldID{}, errV
+	}
+
+	holdIDs := make([]entity.StudentEnrollmentHoldID, 0, len(specs))
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		for _, spec := range specs {
+			holdRow, err := qtx.GetStudentEnrollmentHoldById(newCtx, int64(spec.StudentEnrollmentHoldID))
+			if err != nil {
+				return fmt.Errorf("qtx.GetStudentEnrollmentHoldById(): %w", err)
+			}
+
+			startDate, endDate := util.ToLocalDate(spec.StartDate), util.ToLocalDate(spec.EndDate)
+			err = validateStudentEnrollmentHoldNotOverlapping(newCtx, qtx, entity.StudentEnrollmentID(holdRow.EnrollmentID), spec.StudentEnrollmentHoldID, startDate, endDate)
+			if err != nil {
+				return fmt.Errorf("validateStudentEnrollmentHoldNotOverlapping(): %w", err)
+			}
+
+			err = qtx.UpdateStudentEnrollmentHold(newCtx, mysql.UpdateStudentEnrollmentHoldParams{
+				StartDate: startDate,
+				EndDate:   endDate,
+				Reason:    spec.Reason,
+				ID:        int64(spec.StudentEnrollmentHoldID),
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.UpdateStudentEnrollmentHold(): %w", err)
+			}
+			holdIDs = append(holdIDs, spec.StudentEnrollmentHoldID)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.StudentEnrollmentHoldID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return holdIDs, nil
+}
+
+// validateStudentEnrollmentHoldNotOverlapping returns errs.ErrStudentEnrollmentHoldOverlaps when [startDate, endDate] overlaps with the other holds (excluding excludedHoldID) of the StudentEnrollment.
+func validateStudentEnrollmentHoldNotOverlapping(ctx context.Context, qtx *mysql.Queries, studentEnrollmentID entity.StudentEnrollmentID, excludedHoldID entity.StudentEnrollmentHoldID, startDate time.Time, endDate time.Time) error {
+	overlappingCount, err := qtx.CountOverlappingStudentEnrollmentHolds(ctx, mysql.CountOverlappingStudentEnrollmentHoldsParams{
+		EnrollmentID: int64(studentEnrollmentID),
+		ID:           int64(excludedHoldID),
+		EndDate:      endDate,
+		StartDate:    startDate,
+	})
+	if err != nil {
+		return fmt.Errorf("qtx.CountOverlappingStudentEnrollmentHolds(): %w", err)
+	}
+	if overlappingCount > 0 {
+		return fmt.Errorf("studentEnrollmentID '%d' from '%s' until '%s': %w", studentEnrollmentID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), errs.ErrStudentEnrollmentHoldOverlaps)
+	}
+	return nil
+}
+
+func (s entityServiceImpl) DeleteStudentEnrollmentHolds(ctx context.Context, ids []entity.StudentEnrollmentHoldID) error {
+	holdIdsInt64 := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		holdIdsInt64 = append(holdIdsInt64, int64(id))
+	}
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		err := qtx.DeleteStudentEnrollmentHoldsByIds(newCtx, holdIdsInt64)
+		if err != nil {
+			return fmt.Errorf("qtx.DeleteStudentEnrollmentHoldsByIds(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
+
+func (s entityServiceImpl) GetFamilies(ctx context.Context, pagination util.PaginationSpec) (entity.GetFamiliesResult, error) {
+	pagination.SetDefaultOnInvalidValues()
+	limit, offset := pagination.GetLimitAndOffset()
+
+	var families = make([]entity.Family, 0)
+	var totalResults int64 = 0
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		familyRows, err := qtx.GetFamilies(newCtx, mysql.GetFamiliesParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetFamilies(): %w", err)
+		}
+
+		families, err = getFamiliesWithMembers(newCtx, qtx, familyRows)
+		if err != nil {
+			return fmt.Errorf("getFamiliesWithMembers(): %w", err)
+		}
+
+		totalResults, err = qtx.CountFamilies(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.CountFamilies(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.GetFamiliesResult{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return entity.GetFamiliesResult{
+		Families:         families,
+		PaginationResult: *util.NewPaginationResult(int(totalResults), pagination.ResultsPerPage, pagination.Page),
+	}, nil
+}
+
+func (s entityServiceImpl) GetFamilyById(ctx context.Context, id entity.FamilyID) (entity.Family, error) {
+	var family entity.Family
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		familyRow, err := qtx.GetFamilyById(newCtx, int64(id))
+		if err != nil {
+			return fmt.Errorf("qtx.GetFamilyById(): %w", err)
+		}
+
+		families, err := getFamiliesWithMembers(newCtx, qtx, []mysql.Family{familyRow})
+		if err != nil {
+			return fmt.Errorf("getFamiliesWithMembers(): %w", err)
+		}
+		family = families[0]
+		return nil
+	})
+	if err != nil {
+		return entity.Family{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return family, nil
+}
+
+func (s entityServiceImpl) GetFamiliesByIds(ctx context.Context, ids []entity.FamilyID) ([]entity.Family, error) {
+	idsInt := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		idsInt = append(idsInt, int64(id))
+	}
+
+	var families = make([]entity.Family, 0)
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		familyRows, err := qtx.GetFamiliesByIds(newCtx, idsInt)
+		if err != nil {
+			return fmt.Errorf("qtx.GetFamiliesByIds(): %w", err)
+		}
+
+		families, err = getFamiliesWithMembers(newCtx, qtx, familyRows)
+		if err != nil {
+			return fmt.Errorf("getFamiliesWithMembers(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.Family{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return families, nil
+}
+
+// getFamiliesWithMembers fetches the students & guardians of the families, then constructs the entity.Family.
+func getFamiliesWithMembers(ctx context.Context, qtx *mysql.Queries, familyRows []mysql.Family) ([]entity.Family, error) {
+	familyIDs := make([]int64, 0, len(familyRows))
+	for _, familyRow := range familyRows {
+		familyIDs = append(familyIDs, familyRow.ID)
+	}
+
+	familyStudentRows, err := qtx.GetFamilyStudentsByFamilyIds(ctx, familyIDs)
+	if err != nil {
+		return []entity.Family{}, fmt.Errorf("qtx.GetFamilyStudentsByFamilyIds(): %w", err)
+	}
+	familyGuardianRows, err := qtx.GetFamilyGuardiansByFamilyIds(ctx, familyIDs)
+	if err != nil {
+		return []entity.Family{}, fmt.Errorf("qtx.GetFamilyGuardiansByFamilyIds(): %w", err)
+	}
+
+	return NewFamiliesFromMySQLFamilies(familyRows, familyStudentRows, familyGuardianRows), nil
+}
+
+func (s entityServiceImpl) GetActiveStudentEnrollmentIdsByFamilyId(ctx context.Context, id entity.FamilyID) ([]entity.StudentEnrollmentID, error) {
+	studentEnrollmentIDs := make([]entity.StudentEnrollmentID, 0)
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		idsInt, err := qtx.GetActiveStudentEnrollmentIdsByFamilyId(newCtx, int64(id))
+		if err != nil {
+			return fmt.Errorf("qtx.GetActiveStudentEnrollmentIdsByFamilyId(): %w", err)
+		}
+		for _, idInt := range idsInt {
+			studentEnrollmentIDs = append(studentEnrollmentIDs, entity.StudentEnrollmentID(idInt))
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.StudentEnrollmentID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return studentEnrollmentIDs, nil
+}
+
+func (s entityServiceImpl) InsertFamilies(ctx context.Context, specs []entity.InsertFamilySpec) ([]entity.FamilyID, error) {
+	familyIDs := make([]entity.FamilyID, 0, len(specs))
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		for _, spec := range specs {
+			familyID, err := qtx.InsertFamily(newCtx, spec.Name)
+			if err != nil {
+				return fmt.Errorf("qtx.InsertFamily(): %w", err)
+			}
+
+			err = insertFamilyMembers(newCtx, qtx, entity.FamilyID(familyID), spec.StudentIDs, spec.GuardianUserIDs)
+			if err != nil {
+				return fmt.Errorf("insertFamilyMembers(): %w", err)
+			}
+			familyIDs = append(familyIDs, entity.FamilyID(familyID))
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.FamilyID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return familyIDs, nil
+}
+
+func (s entityServiceImpl) UpdateFamilies(ctx context.Context, specs []entity.UpdateFamilySpec) ([]entity.FamilyID, error) {
+	errV := util.ValidateUpdateSpecs(ctx, specs, s.mySQLQueries.CountFamiliesByIds)
+	if errV != nil {
+		return []entity.FamilyID{}, errV
+	}
+
+	familyIDs := make([]entity.FamilyID, 0, len(specs))
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		for _, spec := range specs {
+			err := qtx.UpdateFamily(newCtx, mysql.UpdateFamilyParams{
+				Name: spec.Name,
+				ID:   int64(spec.FamilyID),
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.UpdateFamily(): %w", err)
+			}
+
+			// replace the members, as it's simpler than diffing them
+			err = qtx.DeleteFamilyStudentsByFamilyId(newCtx, int64(spec.FamilyID))
+			if err != nil {
+				return fmt.Errorf("qtx.DeleteFamilyStudentsByFamilyId(): %w", err)
+			}
+			err = qtx.DeleteFamilyGuardiansByFamilyId(newCtx, int64(spec.FamilyID))
+			if err != nil {
+				return fmt.Errorf("qtx.DeleteFamilyGuardiansByFamilyId(): %w", err)
+			}
+			err = insertFamilyMembers(newCtx, qtx, spec.FamilyID, spec.StudentIDs, spec.GuardianUserIDs)
+			if err != nil {
+				return fmt.Errorf("insertFamilyMembers(): %w", err)
+			}
+			familyIDs = append(familyIDs, spec.FamilyID)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.FamilyID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return familyIDs, nil
+}
+
+func insertFamilyMembers(ctx context.Context, qtx *mysql.Queries, familyID entity.FamilyID, studentIDs []entity.StudentID, guardianUserIDs []identity.UserID) error {
+	for _, studentID := range studentIDs {
+		err := qtx.InsertFamilyStudent(ctx, mysql.InsertFamilyStudentParams{
+			FamilyID:  int64(familyID),
+			StudentID: int64(studentID),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.InsertFamilyStudent(): %w", err)
+		}
+	}
+	for _, userID := range guardianUserIDs {
+		err := qtx.InsertFamilyGuardian(ctx, mysql.InsertFamilyGuardianParams{
+			FamilyID: int64(familyID),
+			UserID:   int64(userID),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.InsertFamilyGuardian(): %w", err)
+		}
+	}
+	return nil
+}
+
+func (s entityServiceImpl) DeleteFamilies(ctx context.Context, ids []entity.FamilyID) error {
+	familyIdsInt64 := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		familyIdsInt64 = append(familyIdsInt64, int64(id))
+	}
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		err := qtx.DeleteFamiliesByIds(newCtx, familyIdsInt64)
+		if err != nil {
+			return fmt.Errorf("qtx.DeleteFamiliesByIds(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
+
+func (s entityServiceImpl) GetTeacherSpecialFees(ctx context.Context, pagination util.PaginationSpec) (entity.GetTeacherSpecialFeesResult, error) {
+	pagination.SetDefaultOnInvalidValues()
+	limit, offset := pagination.GetLimitAndOffset()
+
+	var teacherSpecialFeeRows = make([]mysql.GetTeacherSpecialFeesRow, 0)
+	var totalResults int64 = 0
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		teacherSpecialFeeRows, err = qtx.GetTeacherSpecialFees(newCtx, mysql.GetTeacherSpecialFeesParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetTeacherSpecialFees(): %w", err)
+		}
+
+		totalResults, err = qtx.CountTeacherSpecialFees(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.CountTeacherSpecialFees(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.GetTeacherSpecialFeesResult{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	teacherSpecialFees := NewTeacherSpecialFeesFromGetTeacherSpecialFeesRow(teacherSpecialFeeRows)
+
+	return entity.GetTeacherSpecialFeesResult{
+		TeacherSpecialFees: teacherSpecialFees,
+		PaginationResult:   *util.NewPaginationResult(int(totalResults), pagination.ResultsPerPage, pagination.Page),
+	}, nil
+}
+
+func (s entityServiceImpl) GetTeacherSpecialFeeById(ctx context.Context, id entity.TeacherSpecialFeeID) (entity.TeacherSpecialFee, error) {
+	var teacherSpecialFeeRow mysql.GetTeacherSpecialFeeByIdRow
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		teacherSpecialFeeRow, err = qtx.GetTeacherSpecialFeeById(newCtx, int64(id))
+		if err != nil {
+			return fmt.Errorf("qtx.GetTeacherSpecialFeeById(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.TeacherSpecialFee{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	teacherSpecialFee := NewTeacherSpecialFeesFromGetTeacherSpecialFeesRow([]mysql.GetTeacherSpecialFeesRow{teacherSpecialFeeRow.ToGetTeacherSpecialFeesRow()})[0]
+
+	return teacherSpecialFee, nil
+}
+
+func (s entityServiceImpl) GetTeacherSpecialFeesByIds(ctx context.Context, ids []entity.TeacherSpecialFeeID) ([]entity.TeacherSpecialFee, error) {
+	idsInt := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		idsInt = append(idsInt, int64(id))
+	}
+
+	var teacherSpecialFeeRows = make([]mysql.GetTeacherSpecialFeesByIdsRow, 0)
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		teacherSpecialFeeRows, err = qtx.GetTeacherSpecialFeesByIds(newCtx, idsInt)
+		if err != nil {
+			return fmt.Errorf("qtx.GetTeacherSpecialFeesByIds(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.TeacherSpecialFee{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	teacherSpecialFeeRowsConverted := make([]mysql.GetTeacherSpecialFeesRow, 0, len(teacherSpecialFeeRows))
+	for _, row := range teacherSpecialFeeRows {
+		teacherSpecialFeeRowsConverted = append(teacherSpecialFeeRowsConverted, row.ToGetTeacherSpecialFeesRow())
+	}
+
+	teacherSpecialFees := NewTeacherSpecialFeesFromGetTeacherSpecialFeesRow(teacherSpecialFeeRowsConverted)
+
+	return teacherSpecialFees, nil
+}
+
+func (s entityServiceImpl) InsertTeacherSpecialFees(ctx context.Context, specs []entity.InsertTeacherSpecialFeeSpec) ([]entity.TeacherSpecialFeeID, error) {
+	teacherSpecialFeeIDs := make([]entity.TeacherSpecialFeeID, 0, len(specs))
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		for _, spec := range specs {
+			teacherSpecialFeeID, err := qtx.InsertTeacherSpecialFee(newCtx, mysql.InsertTeacherSpecialFeeParams{
+				Fee:       spec.Fee,
+				TeacherID: int64(spec.TeacherID),
+				CourseID:  int64(spec.CourseID),
+			})
+			if err != nil {
+
+				return fmt.Errorf("qtx.InsertTeacherSpecialFee(): %w", err)
+			}
+
+			_, err = qtx.InsertTeacherSpecialFeeHistory(newCtx, mysql.InsertTeacherSpecialFeeHistoryParams{
+				Fee:           spec.Fee,
+				EffectiveFrom: util.ToLocalDate(time.Now()),
+				TeacherID:     int64(spec.TeacherID),
+				CourseID:      int64(spec.CourseID),
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.InsertTeacherSpecialFeeHistory(): %w", err)
+			}
+			teacherSpecialFeeIDs = append(teacherSpecialFeeIDs, entity.TeacherSpecialFeeID(teacherSpecialFeeID))
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.TeacherSpecialFeeID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return teacherSpecialFeeIDs, nil
+}
+
+func (s entityServiceImpl) UpdateTeacherSpecialFees(ctx context.Context, specs []entity.UpdateTeacherSpecialFeeSpec) ([]entity.TeacherSpecialFeeID, error) {
+	errV := util.ValidateUpdateSpecs(ctx, specs, s.mySQLQueries.CountTeacherSpecialFeesByIds)
+	if errV != nil {
+		return []entity.TeacherSpecialFeeID{}, errV
+	}
+
+	teacherSpecialFeeIDs := make([]entity.TeacherSpecialFeeID, 0, len(specs))
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		// only the changed fees are recorded into the history
+		changedTeacherSpecialFeeIdsInt64 := make([]int64, 0, len(specs))
+		for _, spec := range specs {
+			prevTeacherSpecialFee, err := qtx.GetTeacherSpecialFeeById(newCtx, int64(spec.TeacherSpecialFeeID))
+			if err != nil {
+				return fmt.Errorf("qtx.GetTeacherSpecialFeeById(): %w", err)
+			}
+			if prevTeacherSpecialFee.Fee != spec.Fee {
+				changedTeacherSpecialFeeIdsInt64 = append(changedTeacherSpecialFeeIdsInt64, int64(spec.TeacherSpecialFeeID))
+			}
+
+			err = qtx.UpdateTeacherSpecialFee(newCtx, mysql.UpdateTeacherSpecialFeeParams{
+				Fee: spec.Fee,
+				ID:  int64(spec.TeacherSpecialFeeID),
+			})
+			if err != nil {
+
+				return fmt.Errorf("qtx.UpdateTeacherSpecialFee(): %w", err)
+			}
+			teacherSpecialFeeIDs = append(teacherSpecialFeeIDs, spec.TeacherSpecialFeeID)
+		}
+
+		if len(changedTeacherSpecialFeeIdsInt64) == 0 {
+			return nil
+		}
+		err := qtx.InsertTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds(newCtx, mysql.InsertTeacherSpecialFeeHistoriesByTeacherSpecialFeeIdsParams{
+			EffectiveFrom: util.ToLocalDate(time.Now()),
+			Ids:           changedTeacherSpecialFeeIdsInt64,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.InsertTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.TeacherSpecialFeeID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return teacherSpecialFeeIDs, nil
+}
+
+func (s entityServiceImpl) DeleteTeacherSpecialFees(ctx context.Context, ids []entity.TeacherSpecialFeeID) error {
+	teacherSpecialFeeIdsInt64 := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		teacherSpecialFeeIdsInt64 = append(teacherSpecialFeeIdsInt64, int64(id))
+	}
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		err := qtx.InsertRemovedTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds(newCtx, mysql.InsertRemovedTeacherSpecialFeeHistoriesByTeacherSpecialFeeIdsParams{
+			EffectiveFrom: util.ToLocalDate(time.Now()),
+			Ids:           teacherSpecialFeeIdsInt64,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.InsertRemovedTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds(): %w", err)
+		}
+
+		err = qtx.DeleteTeacherSpecialFeesByIds(newCtx, teacherSpecialFeeIdsInt64)
+		if err != nil {
+			return fmt.Errorf("qtx.DeleteTeacherSpecialFeeByIds(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
+
+func (s entityServiceImpl) GetCourseFeeHistoriesByCourseId(ctx context.Context, courseID entity.CourseID) ([]entity.CourseFeeHistory, error) {
+	courseFeeHistoryRows, err := s.mySQLQueries.GetCourseFeeHistoriesByCourseId(ctx, int64(courseID))
+	if err != nil {
+		return []entity.CourseFeeHistory{}, fmt.Errorf("mySQLQueries.GetCourseFeeHistoriesByCourseId(): %w", err)
+	}
+
+	courseFeeHistories := NewCourseFeeHistoriesFromCourseFeeHistoryRows(courseFeeHistoryRows)
+
+	return courseFeeHistories, nil
+}
+
+func (s entityServiceImpl) InsertCourseFeeHistories(ctx context.Context, specs []entity.InsertCourseFeeHistorySpec) ([]entity.CourseFeeHistoryID, error) {
+	courseFeeHistoryIDs := make([]entity.CourseFeeHistoryID, 0, len(specs))
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		for _, spec := range specs {
+			courseFeeHistoryID, err := qtx.InsertCourseFeeHistory(newCtx, mysql.InsertCourseFeeHistoryParams{
+				Fee:           spec.Fee,
+				EffectiveFrom: spec.EffectiveFrom,
+				CourseID:      int64(spec.CourseID),
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.InsertCourseFeeHistory(): %w", err)
+			}
+			courseFeeHistoryIDs = append(courseFeeHistoryIDs, entity.CourseFeeHistoryID(courseFeeHistoryID))
+		}
+
+		// a backdated price may already be in force
+		_, err := qtx.SyncCourseDefaultFeesWithFeeHistories(newCtx, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("qtx.SyncCourseDefaultFeesWithFeeHistories(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.CourseFeeHistoryID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return courseFeeHistoryIDs, nil
+}
+
+func (s entityServiceImpl) SyncCourseDefaultFees(ctx context.Context) (int64, error) {
+	affectedCourses, err := s.mySQLQueries.SyncCourseDefaultFeesWithFeeHistories(ctx, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("mySQLQueries.SyncCourseDefaultFeesWithFeeHistories(): %w", err)
+	}
+	return affectedCourses, nil
+}
+
+func (s entityServiceImpl) GetTeacherSpecialFeeHistoriesByCourseId(ctx context.Context, courseID entity.CourseID) ([]entity.TeacherSpecialFeeHistory, error) {
+	teacherSpecialFeeHistoryRows, err := s.mySQLQueries.GetTeacherSpecialFeeHistoriesByCourseId(ctx, int64(courseID))
+	if err != nil {
+		return []entity.TeacherSpecialFeeHistory{}, fmt.Errorf("mySQLQueries.GetTeacherSpecialFeeHistoriesByCourseId(): %w", err)
+	}
+
+	teacherSpecialFeeHistories := NewTeacherSpecialFeeHistoriesFromTeacherSpecialFeeHistoryRows(teacherSpecialFeeHistoryRows)
+
+	return teacherSpecialFeeHistories, nil
+}
+
+func (s entityServiceImpl) InsertTeacherSpecialFeeHistories(ctx context.Context, specs []entity.InsertTeacherSpecialFeeHistorySpec) ([]entity.TeacherSpecialFeeHistoryID, error) {
+	teacherSpecialFeeHistoryIDs := make([]entity.TeacherSpecialFeeHistoryID, 0, len(specs))
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		for _, spec := range specs {
+			teacherSpecialFeeHistoryID, err := qtx.InsertTeacherSpecialFeeHistory(newCtx, mysql.InsertTeacherSpecialFeeHistoryParams{
+				Fee:           spec.Fee,
+				EffectiveFrom: spec.EffectiveFrom,
+				TeacherID:     int64(spec.TeacherID),
+				CourseID:      int64(spec.CourseID),
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.InsertTeacherSpecialFeeHistory(): %w", err)
+			}
+			teacherSpecialFeeHistoryIDs = append(teacherSpecialFeeHistoryIDs, entity.TeacherSpecialFeeHistoryID(teacherSpecialFeeHistoryID))
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.TeacherSpecialFeeHistoryID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return teacherSpecialFeeHistoryIDs, nil
+}
+
+func (s entityServiceImpl) GetCourseFeeValueAt(ctx context.Context, courseID entity.CourseID, teacherID entity.TeacherID, at time.Time) (int32, error) {
+	var courseFeeValue int32
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		if teacherID != entity.TeacherID_None {
+			teacherSpecialFee, err := qtx.GetTeacherSpecialFeeAtDate(newCtx, mysql.GetTeacherSpecialFeeAtDateParams{
+				TeacherID:     int64(teacherID),
+				CourseID:      int64(courseID),
+				EffectiveFrom: at,
+			})
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("qtx.GetTeacherSpecialFeeAtDate(): %w", err)
+			}
+			// a zero fee means the teacher special fee has been removed at the given time
+			if teacherSpecialFee > 0 {
+				courseFeeValue = teacherSpecialFee
+				return nil
+			}
+		}
+
+		courseFee, err := qtx.GetCourseFeeAtDate(newCtx, mysql.GetCourseFeeAtDateParams{
+			CourseID:      int64(courseID),
+			EffectiveFrom: at,
+		})
+		if err == nil {
+			courseFeeValue = courseFee
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("qtx.GetCourseFeeAtDate(): %w", err)
+		}
+
+		// no price history is in force at the given time (e.g. the time precedes the course creation), thus use the latest price
+		course, err := qtx.GetCourseById(newCtx, int64(courseID))
+		if err != nil {
+			return fmt.Errorf("qtx.GetCourseById(): %w", err)
+		}
+		courseFeeValue = course.DefaultFee
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return courseFeeValue, nil
+}
+
+func (s entityServiceImpl) GetPenaltyPolicies(ctx context.Context, pagination util.PaginationSpec) (entity.GetPenaltyPoliciesResult, error) {
+	pagination.SetDefaultOnInvalidValues()
+	limit, offset := pagination.GetLimitAndOffset()
+
+	var penaltyPolicyRows = make([]mysql.PenaltyPolicy, 0)
+	var totalResults int64 = 0
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		penaltyPolicyRows, err = qtx.GetPenaltyPolicies(newCtx, mysql.GetPenaltyPoliciesParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetPenaltyPolicies(): %w", err)
+		}
+
+		totalResults, err = qtx.CountPenaltyPolicies(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.CountPenaltyPolicies(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.GetPenaltyPoliciesResult{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	penaltyPolicies := NewPenaltyPoliciesFromMySQLPenaltyPolicies(penaltyPolicyRows)
+
+	return entity.GetPenaltyPoliciesResult{
+		PenaltyPolicies:  penaltyPolicies,
+		PaginationResult: *util.NewPaginationResult(int(totalResults), pagination.ResultsPerPage, pagination.Page),
+	}, nil
+}
+
+func (s entityServiceImpl) GetPenaltyPolicyById(ctx context.Context, id entity.PenaltyPolicyID) (entity.PenaltyPolicy, error) {
+	var penaltyPolicyRow mysql.PenaltyPolicy
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		penaltyPolicyRow, err = qtx.GetPenaltyPolicyById(newCtx, int64(id))
+		if err != nil {
+			return fmt.Errorf("qtx.GetPenaltyPolicyById(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.PenaltyPolicy{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	penaltyPolicy := NewPenaltyPoliciesFromMySQLPenaltyPolicies([]mysql.PenaltyPolicy{penaltyPolicyRow})[0]
+
+	return penaltyPolicy, nil
+}
+
+func (s entityServiceImpl) GetPenaltyPoliciesByIds(ctx context.Context, ids []entity.PenaltyPolicyID) ([]entity.PenaltyPolicy, error) {
+	idsInt := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		idsInt = append(idsInt, int64(id))
+	}
+
+	var penaltyPolicyRows = make([]mysql.PenaltyPolicy, 0)
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		penaltyPolicyRows, err = qtx.GetPenaltyPoliciesByIds(newCtx, idsInt)
+		if err != nil {
+			return fmt.Errorf("qtx.GetPenaltyPoliciesByIds(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.PenaltyPolicy{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	penaltyPolicies := NewPenaltyPoliciesFromMySQLPenaltyPolicies(penaltyPolicyRows)
+
+	return penaltyPolicies, nil
+}
+
+func (s entityServiceImpl) GetApplicablePenaltyPolicy(ctx context.Context, classID entity.ClassID, courseID entity.CourseID) (entity.PenaltyPolicy, error) {
+	var penaltyPolicyRow mysql.PenaltyPolicy
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		penaltyPolicyRow, err = qtx.GetApplicablePenaltyPolicy(newCtx, mysql.GetApplicablePenaltyPolicyParams{
+			ClassID:  sql.NullInt64{Int64: int64(classID), Valid: classID != entity.ClassID_None},
+			CourseID: sql.NullInt64{Int64: int64(courseID), Valid: courseID != entity.CourseID_None},
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetApplicablePenaltyPolicy(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.PenaltyPolicy{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	penaltyPolicy := NewPenaltyPoliciesFromMySQLPenaltyPolicies([]mysql.PenaltyPolicy{penaltyPolicyRow})[0]
+
+	return penaltyPolicy, nil
+}
+
+func (s entityServiceImpl) InsertPenaltyPolicies(ctx context.Context, specs []entity.InsertPenaltyPolicySpec) ([]entity.PenaltyPolicyID, error) {
+	penaltyPolicyIDs := make([]entity.PenaltyPolicyID, 0, len(specs))
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		for _, spec := range specs {
+			penaltyPolicyID, err := qtx.InsertPenaltyPolicy(newCtx, mysql.InsertPenaltyPolicyParams{
+				Name:              spec.Name,
+				TriggerDayOfMonth: spec.TriggerDayOfMonth,
+				GraceDays:         spec.GraceDays,
+				IsFlatFee:         util.BoolToInt32(spec.IsFlatFee),
+				FeeValue:          spec.FeeValue,
+				MaxFeeValue:       spec.MaxFeeValue,
+				CourseID:          sql.NullInt64{Int64: int64(spec.CourseID), Valid: spec.CourseID != entity.CourseID_None},
+				ClassID:           sql.NullInt64{Int64: int64(spec.ClassID), Valid: spec.ClassID != entity.ClassID_None},
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.InsertPenaltyPolicy(): %w", err)
+			}
+			penaltyPolicyIDs = append(penaltyPolicyIDs, entity.PenaltyPolicyID(penaltyPolicyID))
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.PenaltyPolicyID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return penaltyPolicyIDs, nil
+}
+
+func (s entityServiceImpl) UpdatePenaltyPolicies(ctx context.Context, specs []entity.UpdatePenaltyPolicySpec) ([]entity.PenaltyPolicyID, error) {
+	errV := util.ValidateUpdateSpecs(ctx, specs, s.mySQLQueries.CountPenaltyPoliciesByIds)
+	if errV != nil {
+		return []entity.PenaltyPolicyID{}, errV
+	}
+
+	penaltyPolicyIDs := make([]entity.PenaltyPolicyID, 0, len(specs))
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		for _, spec := range specs {
+			err := qtx.UpdatePenaltyPolicy(newCtx, mysql.UpdatePenaltyPolicyParams{
+				Name:              spec.Name,
+				TriggerDayOfMonth: spec.TriggerDayOfMonth,
+				GraceDays:         spec.GraceDays,
+				IsFlatFee:         util.BoolToInt32(spec.IsFlatFee),
+				FeeValue:          spec.FeeValue,
+				MaxFeeValue:       spec.MaxFeeValue,
+				CourseID:          sql.NullInt64{Int64: int64(spec.CourseID), Valid: spec.CourseID != entity.CourseID_None},
+				ClassID:           sql.NullInt64{Int64: int64(spec.ClassID), Valid: spec.ClassID != entity.ClassID_None},
+				ID:                int64(spec.PenaltyPolicyID),
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.UpdatePenaltyPolicy(): %w", err)
+			}
+			penaltyPolicyIDs = append(penaltyPolicyIDs, spec.PenaltyPolicyID)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.PenaltyPolicyID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return penaltyPolicyIDs, nil
+}
+
+func (s entityServiceImpl) DeletePenaltyPolicies(ctx context.Context, ids []entity.PenaltyPolicyID) error {
+	penaltyPolicyIdsInt64 := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		penaltyPolicyIdsInt64 = append(penaltyPolicyIdsInt64, int64(id))
+	}
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		err := qtx.DeletePenaltyPoliciesByIds(newCtx, penaltyPolicyIdsInt64)
+		if err != nil {
+			return fmt.Errorf("qtx.DeletePenaltyPoliciesByIds(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
+
+func (s entityServiceImpl) GetDiscountRules(ctx context.Context, pagination util.PaginationSpec) (entity.GetDiscountRulesResult, error) {
+	pagination.SetDefaultOnInvalidValues()
+	limit, offset := pagination.GetLimitAndOffset()
+
+	var discountRuleRows = make([]mysql.DiscountRule, 0)
+	var totalResults int64 = 0
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		discountRuleRows, err = qtx.GetDiscountRules(newCtx, mysql.GetDiscountRulesParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetDiscountRules(): %w", err)
+		}
+
+		totalResults, err = qtx.CountDiscountRules(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.CountDiscountRules(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.GetDiscountRulesResult{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	discountRules := NewDiscountRulesFromMySQLDiscountRules(discountRuleRows)
+
+	return entity.GetDiscountRulesResult{
+		DiscountRules:    discountRules,
+		PaginationResult: *util.NewPaginationResult(int(totalResults), pagination.ResultsPerPage, pagination.Page),
+	}, nil
+}
+
+func (s entityServiceImpl) GetDiscountRuleById(ctx context.Context, id entity.DiscountRuleID) (entity.DiscountRule, error) {
+	var discountRuleRow mysql.DiscountRule
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		discountRuleRow, err = qtx.GetDiscountRuleById(newCtx, int64(id))
+		if err != nil {
+			return fmt.Errorf("qtx.GetDiscountRuleById(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.DiscountRule{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	discountRule := NewDiscountRulesFromMySQLDiscountRules([]mysql.DiscountRule{discountRuleRow})[0]
+
+	return discountRule, nil
+}
+
+func (s entityServiceImpl) GetDiscountRulesByIds(ctx context.Context, ids []entity.DiscountRuleID) ([]entity.DiscountRule, error) {
+	idsInt := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		idsInt = append(idsInt, int64(id))
+	}
+
+	var discountRuleRows = make([]mysql.DiscountRule, 0)
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		discountRuleRows, err = qtx.GetDiscountRulesByIds(newCtx, idsInt)
+		if err != nil {
+			return fmt.Errorf("qtx.GetDiscountRulesByIds(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.DiscountRule{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	discountRules := NewDiscountRulesFromMySQLDiscountRules(discountRuleRows)
+
+	return discountRules, nil
+}
+
+func (s entityServiceImpl) GetActiveAutomaticDiscountRules(ctx context.Context) ([]entity.DiscountRule, error) {
+	var discountRuleRows = make([]mysql.DiscountRule, 0)
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		discountRuleRows, err = qtx.GetActiveAutomaticDiscountRules(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.GetActiveAutomaticDiscountRules(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.DiscountRule{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	discountRules := NewDiscountRulesFromMySQLDiscountRules(discountRuleRows)
+
+	return discountRules, nil
+}
+
+func (s entityServiceImpl) GetDiscountRuleByVoucherCode(ctx context.Context, voucherCode string) (entity.DiscountRule, error) {
+	var discountRuleRow mysql.DiscountRule
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		discountRuleRow, err = qtx.GetDiscountRuleByVoucherCode(newCtx, sql.NullString{String: voucherCode, Valid: true})
+		if err != nil {
+			return fmt.Errorf("qtx.GetDiscountRuleByVoucherCode(): %w", err)
+		}
+		if discountRuleRow.Type != string(entity.DiscountRuleType_Voucher) {
+			return fmt.Errorf("discountRule '%d' is not a voucher: %w", discountRuleRow.ID, sql.ErrNoRows)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.DiscountRule{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	discountRule := NewDiscountRulesFromMySQLDiscountRules([]mysql.DiscountRule{discountRuleRow})[0]
+
+	return discountRule, nil
+}
+
+func (s entityServiceImpl) InsertDiscountRules(ctx context.Context, specs []entity.InsertDiscountRuleSpec) ([]entity.DiscountRuleID, error) {
+	discountRuleIDs := make([]entity.DiscountRuleID, 0, len(specs))
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		for _, spec := range specs {
+			discountRuleID, err := qtx.InsertDiscountRule(newCtx, mysql.InsertDiscountRuleParams{
+				Name:          spec.Name,
+				Type:          string(spec.Type),
+				IsPercentage:  util.BoolToInt32(spec.IsPercentage),
+				Value:         spec.Value,
+				MinCount:      spec.MinCount,
+				MaxDayOfMonth: spec.MaxDayOfMonth,
+				VoucherCode:   sql.NullString{String: spec.VoucherCode, Valid: spec.VoucherCode != ""},
+				UsageLimit:    sql.NullInt32{Int32: spec.UsageLimit, Valid: spec.UsageLimit > 0},
+				ValidFrom:     newSQLNullTimeFromPointer(spec.ValidFrom),
+				ValidUntil:    newSQLNullTimeFromPointer(spec.ValidUntil),
+				IsDeactivated: util.BoolToInt32(spec.IsDeactivated),
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.InsertDiscountRule(): %w", err)
+			}
+			discountRuleIDs = append(discountRuleIDs, entity.DiscountRuleID(discountRuleID))
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.DiscountRuleID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return discountRuleIDs, nil
+}
+
+func (s entityServiceImpl) UpdateDiscountRules(ctx context.Context, specs []entity.UpdateDiscountRuleSpec) ([]entity.DiscountRuleID, error) {
+	errV := util.ValidateUpdateSpecs(ctx, specs, s.mySQLQueries.CountDiscountRulesByIds)
+	if errV != nil {
+		return []entity.DiscountRuleID{}, errV
+	}
+
+	discountRuleIDs := make([]entity.DiscountRuleID, 0, len(specs))
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		for _, spec := range specs {
+			err := qtx.UpdateDiscountRule(newCtx, mysql.UpdateDiscountRuleParams{
+				Name:          spec.Name,
+				Type:          string(spec.Type),
+				IsPercentage:  util.BoolToInt32(spec.IsPercentage),
+				Value:         spec.Value,
+				MinCount:      spec.MinCount,
+				MaxDayOfMonth: spec.MaxDayOfMonth,
+				VoucherCode:   sql.NullString{String: spec.VoucherCode, Valid: spec.VoucherCode != ""},
+				UsageLimit:    sql.NullInt32{Int32: spec.UsageLimit, Valid: spec.UsageLimit > 0},
+				ValidFrom:     newSQLNullTimeFromPointer(spec.ValidFrom),
+				ValidUntil:    newSQLNullTimeFromPointer(spec.ValidUntil),
+				IsDeactivated: util.BoolToInt32(spec.IsDeactivated),
+				ID:            int64(spec.DiscountRuleID),
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.UpdateDiscountRule(): %w", err)
+			}
+			discountRuleIDs = append(discountRuleIDs, spec.DiscountRuleID)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.DiscountRuleID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return discountRuleIDs, nil
+}
+
+func (s entityServiceImpl) DeleteDiscountRules(ctx context.Context, ids []entity.DiscountRuleID) error {
+	discountRuleIdsInt64 := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		discountRuleIdsInt64 = append(discountRuleIdsInt64, int64(id))
+	}
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		err := qtx.DeleteDiscountRulesByIds(newCtx, discountRuleIdsInt64)
+		if err != nil {
+			return fmt.Errorf("qtx.DeleteDiscountRulesByIds(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
+
+func newSQLNullTimeFromPointer(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+func (s entityServiceImpl) GetTeacherFeeSharings(ctx context.Context, pagination util.PaginationSpec) (entity.GetTeacherFeeSharingsResult, error) {
+	pagination.SetDefaultOnInvalidValues()
+	limit, offset := pagination.GetLimitAndOffset()
+
+	var teacherFeeSharingRows = make([]mysql.TeacherFeeSharing, 0)
+	var totalResults int64 = 0
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		teacherFeeSharingRows, err = qtx.GetTeacherFeeSharings(newCtx, mysql.GetTeacherFeeSharingsParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetTeacherFeeSharings(): %w", err)
+		}
+
+		totalResults, err = qtx.CountTeacherFeeSharings(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.CountTeacherFeeSharings(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.GetTeacherFeeSharingsResult{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	teacherFeeSharings := NewTeacherFeeSharingsFromMySQLTeacherFeeSharings(teacherFeeSharingRows)
+
+	return entity.GetTeacherFeeSharingsResult{
+		TeacherFeeSharings: teacherFeeSharings,
+		PaginationResult:   *util.NewPaginationResult(int(totalResults), pagination.ResultsPerPage, pagination.Page),
+	}, nil
+}
+
+func (s entityServiceImpl) GetTeacherFeeSharingById(ctx context.Context, id entity.TeacherFeeSharingID) (entity.TeacherFeeSharing, error) {
+	var teacherFeeSharingRow mysql.TeacherFeeSharing
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		teacherFeeSharingRow, err = qtx.GetTeacherFeeSharingById(newCtx, int64(id))
+		if err != nil {
+			return fmt.Errorf("qtx.GetTeacherFeeSharingById(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.TeacherFeeSharing{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	teacherFeeSharing := NewTeacherFeeSharingsFromMySQLTeacherFeeSharings([]mysql.TeacherFeeSharing{teacherFeeSharingRow})[0]
+
+	return teacherFeeSharing, nil
+}
+
+func (s entityServiceImpl) GetTeacherFeeSharingsByIds(ctx context.Context, ids []entity.TeacherFeeSharingID) ([]entity.TeacherFeeSharing, error) {
+	idsInt := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		idsInt = append(idsInt, int64(id))
+	}
+
+	var teacherFeeSharingRows = make([]mysql.TeacherFeeSharing, 0)
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		teacherFeeSharingRows, err = qtx.GetTeacherFeeSharingsByIds(newCtx, idsInt)
+		if err != nil {
+			return fmt.Errorf("qtx.GetTeacherFeeSharingsByIds(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.TeacherFeeSharing{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	teacherFeeSharings := NewTeacherFeeSharingsFromMySQLTeacherFeeSharings(teacherFeeSharingRows)
+
+	return teacherFeeSharings, nil
+}
+
+func (s entityServiceImpl) GetTeacherFeeSharingsByTeacherId(ctx context.Context, teacherID entity.TeacherID) ([]entity.TeacherFeeSharing, error) {
+	var teacherFeeSharingRows = make([]mysql.TeacherFeeSharing, 0)
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		teacherFeeSharingRows, err = qtx.GetTeacherFeeSharingsByTeacherId(newCtx, sql.NullInt64{Int64: int64(teacherID), Valid: teacherID != entity.TeacherID_None})
+		if err != nil {
+			return fmt.Errorf("qtx.GetTeacherFeeSharingsByTeacherId(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.TeacherFeeSharing{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	teacherFeeSharings := NewTeacherFeeSharingsFromMySQLTeacherFeeSharings(teacherFeeSharingRows)
+
+	return teacherFeeSharings, nil
+}
+
+func (s entityServiceImpl) InsertTeacherFeeSharings(ctx context.Context, specs []entity.InsertTeacherFeeSharingSpec) ([]entity.TeacherFeeSharingID, error) {
+	teacherFeeSharingIDs := make([]entity.TeacherFeeSharingID, 0, len(specs))
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		for _, spec := range specs {
+			teacherFeeSharingID, err := qtx.InsertTeacherFeeSharing(newCtx, mysql.InsertTeacherFeeSharingParams{
+				CourseFeeSharingPercentage:    spec.CourseFeeSharingPercentage,
+				TransportFeeSharingPercentage: spec.TransportFeeSharingPercentage,
+				EffectiveFrom:                 spec.EffectiveFrom,
+				TeacherID:                     sql.NullInt64{Int64: int64(spec.TeacherID), Valid: spec.TeacherID != entity.TeacherID_None},
+				CourseID:                      sql.NullInt64{Int64: int64(spec.CourseID), Valid: spec.CourseID != entity.CourseID_None},
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.InsertTeacherFeeSharing(): %w", err)
+			}
+			teacherFeeSharingIDs = append(teacherFeeSharingIDs, entity.TeacherFeeSharingID(teacherFeeSharingID))
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.TeacherFeeSharingID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return teacherFeeSharingIDs, nil
+}
+
+func (s entityServiceImpl) UpdateTeacherFeeSharings(ctx context.Context, specs []entity.UpdateTeacherFeeSharingSpec) ([]entity.TeacherFeeSharingID, error) {
+	errV := util.ValidateUpdateSpecs(ctx, specs, s.mySQLQueries.CountTeacherFeeSharingsByIds)
+	if errV != nil {
+		return []entity.TeacherFeeSharingID{}, errV
+	}
+
+	teacherFeeSharingIDs := make([]entity.TeacherFeeSharingID, 0, len(specs))
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		for _, spec := range specs {
+			err := qtx.UpdateTeacherFeeSharing(newCtx, mysql.UpdateTeacherFeeSharingParams{
+				CourseFeeSharingPercentage:    spec.CourseFeeSharingPercentage,
+				TransportFeeSharingPercentage: spec.TransportFeeSharingPercentage,
+				EffectiveFrom:                 spec.EffectiveFrom,
+				TeacherID:                     sql.NullInt64{Int64: int64(spec.TeacherID), Valid: spec.TeacherID != entity.TeacherID_None},
+				CourseID:                      sql.NullInt64{Int64: int64(spec.CourseID), Valid: spec.CourseID != entity.CourseID_None},
+				ID:                            int64(spec.TeacherFeeSharingID),
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.UpdateTeacherFeeSharing(): %w", err)
+			}
+			teacherFeeSharingIDs = append(teacherFeeSharingIDs, spec.TeacherFeeSharingID)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.TeacherFeeSharingID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return teacherFeeSharingIDs, nil
+}
+
+func (s entityServiceImpl) DeleteTeacherFeeSharings(ctx context.Context, ids []entity.TeacherFeeSharingID) error {
+	teacherFeeSharingIdsInt64 := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		teacherFeeSharingIdsInt64 = append(teacherFeeSharingIdsInt64, int64(id))
+	}
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		err := qtx.DeleteTeacherFeeSharingsByIds(newCtx, teacherFeeSharingIdsInt64)
+		if err != nil {
+			return fmt.Errorf("qtx.DeleteTeacherFeeSharingsByIds(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
+
+func (s entityServiceImpl) GetEnrollmentPayments(ctx context.Context, pagination util.PaginationSpec, timeFilter util.TimeSpec, sortRecent bool) (entity.GetEnrollmentPaymentsResult, error) {
+	pagination.SetDefaultOnInvalidValues()
+	limit, offset := pagination.GetLimitAndOffset()
+
+	timeFilter.SetDefaultForZeroValues()
+
+	var enrollmentPaymentRows = make([]mysql.GetEnrollmentPaymentsRow, 0)
+	var totalResults int64 = 0
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		if !sortRecent { // TODO: find alternative: currently sqlc dynamic query is so bad that we need to do this :(
+			enrollmentPaymentRows, err = qtx.GetEnrollmentPayments(newCtx, mysql.GetEnrollmentPaymentsParams{
+				StartDate: timeFilter.StartDatetime,
+				EndDate:   timeFilter.EndDatetime,
+				Limit:     int32(limit),
+				Offset:    int32(offset),
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.GetEnrollmentPayments(): %w", err)
+			}
+
+		} else {
+			enrollmentPaymentDescendingDateRows, err := qtx.GetEnrollmentPaymentsDescendingDate(newCtx, mysql.GetEnrollmentPaymentsDescendingDateParams{
+				StartDate: timeFilter.StartDatetime,
+				EndDate:   timeFilter.EndDatetime,
+				Limit:     int32(limit),
+				Offset:    int32(offset),
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.GetEnrollmentPaymentsDescendingDate(): %w", err)
+			}
+
+			for _, row := range enrollmentPaymentDescendingDateRows {
+				enrollmentPaymentRows = append(enrollmentPaymentRows, row.ToGetEnrollmentPaymentsRow())
+			}
+		}
+
+		totalResults, err = qtx.CountEnrollmentPayments(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.CountEnrollmentPayments(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.GetEnrollmentPaymentsResult{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	enrollmentPayments := NewEnrollmentPaymentsFromGetEnrollmentPaymentsRow(enrollmentPaymentRows)
+
+	return entity.GetEnrollmentPaymentsResult{
+		EnrollmentPayments: enrollmentPayments,
+		PaginationResult:   *util.NewPaginationResult(int(totalResults), pagination.ResultsPerPage, pagination.Page),
+	}, nil
+}
+
+func (s entityServiceImpl) GetEnrollmentPaymentById(ctx context.Context, id entity.EnrollmentPaymentID) (entity.EnrollmentPayment, error) {
+	var enrollmentPaymentRow mysql.GetEnrollmentPaymentByIdRow
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		enrollmentPaymentRow, err = qtx.GetEnrollmentPaymentById(newCtx, int64(id))
+		if err != nil {
+			return fmt.Errorf("qtx.GetEnrollmentPaymentById(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.EnrollmentPayment{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	enrollmentPayment := NewEnrollmentPaymentsFromGetEnrollmentPaymentsRow([]mysql.GetEnrollmentPaymentsRow{enrollmentPaymentRow.ToGetEnrollmentPaymentsRow()})[0]
+
+	return enrollmentPayment, nil
+}
+
+func (s entityServiceImpl) GetEnrollmentPaymentsByIds(ctx context.Context, ids []entity.EnrollmentPaymentID) ([]entity.EnrollmentPayment, error) {
+	idsInt := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		idsInt = append(idsInt, int64(id))
+	}
+
+	var enrollmentPaymentRows = make([]mysql.GetEnrollmentPaymentsByIdsRow, 0)
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		enrollmentPaymentRows, err = qtx.GetEnrollmentPaymentsByIds(newCtx, idsInt)
+		if err != nil {
+			return fmt.Errorf("qtx.GetEnrollmentPaymentsByIds(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.EnrollmentPayment{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	enrollmentPaymentRowsConverted := make([]mysql.GetEnrollmentPaymentsRow, 0, len(enrollmentPaymentRows))
+	for _, row := range enrollmentPaymentRows {
+		enrollmentPaymentRowsConverted = append(enrollmentPaymentRowsConverted, row.ToGetEnrollmentPaymentsRow())
+	}
+
+	enrollmentPayments := NewEnrollmentPaymentsFromGetEnrollmentPaymentsRow(enrollmentPaymentRowsConverted)
+
+	return enrollmentPayments, nil
+}
+
+func (s entityServiceImpl) InsertEnrollmentPayments(ctx context.Context, specs []entity.InsertEnrollmentPaymentSpec) ([]entity.EnrollmentPaymentID, error) {
+	enrollmentPaymentIDs := make([]entity.EnrollmentPaymentID, 0, len(specs))
+
+	paymentDates := make([]time.Time, 0, len(specs))
+	for _, spec := range specs {
+		paymentDates = append(paymentDates, spec.PaymentDate)
+	}
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		err := s.EnsureDatesNotInClosedPeriod(newCtx, entity.EnsureDatesNotInClosedPeriodSpec{
+			Operation: "InsertEnrollmentPayments",
+			Dates:     paymentDates,
+		})
+		if err != nil {
+			return fmt.Errorf("EnsureDatesNotInClosedPeriod(): %w", err)
+		}
+		err = s.EnsurePaymentDatesNotInClosedCashUpDay(newCtx, paymentDates)
+		if err != nil {
+			return fmt.Errorf("EnsurePaymentDatesNotInClosedCashUpDay(): %w", err)
+		}
+
 		for _, spec := range specs {
+			// the snapshot keeps the payment's details readable, even after the StudentEnrollment is deleted
+			studentEnrollment, err := s.GetStudentEnrollmentById(newCtx, spec.StudentEnrollmentID)
+			if err != nil {
+				return fmt.Errorf("GetStudentEnrollmentById(): %w", err)
+			}
+			snapshot := entity.NewPaymentSnapshot(studentEnrollment.StudentInfo, studentEnrollment.ClassInfo, studentEnrollment.ClassInfo.TeacherInfo_Minimal)
+
 			enrollmentPaymentID, err := qtx.InsertEnrollmentPayment(newCtx, mysql.InsertEnrollmentPaymentParams{
-				PaymentDate:       spec.PaymentDate,
-				BalanceTopUp:      spec.BalanceTopUp,
-				BalanceBonus:      spec.BalanceBonus,
-				CourseFeeValue:    spec.CourseFeeValue,
-				TransportFeeValue: spec.TransportFeeValue,
-				PenaltyFeeValue:   spec.PenaltyFeeValue,
-				DiscountFeeValue:  spec.DiscountFeeValue,
-				EnrollmentID:      sql.NullInt64{Int64: int64(spec.StudentEnrollmentID), Valid: true},
+				PaymentDate:              spec.PaymentDate,
+				BalanceTopUp:             spec.BalanceTopUp,
+				BalanceBonus:             spec.BalanceBonus,
+				CourseFeeValue:           spec.CourseFeeValue,
+				TransportFeeValue:        spec.TransportFeeValue,
+				PenaltyFeeValue:          spec.PenaltyFeeValue,
+				DiscountFeeValue:         spec.DiscountFeeValue,
+				PaymentMethod:            string(paymentMethodOrDefault(spec.PaymentMethod)),
+				ReceivingAccount:         spec.ReceivingAccount,
+				ReferenceNumber:          spec.ReferenceNumber,
+				EnrollmentID:             sql.NullInt64{Int64: int64(spec.StudentEnrollmentID), Valid: true},
+				SnapshotStudentName:      snapshot.StudentName,
+				SnapshotClassDescription: snapshot.ClassDescription,
+				SnapshotCourseName:       snapshot.CourseName,
+				SnapshotInstrumentName:   snapshot.InstrumentName,
+				SnapshotGradeName:        snapshot.GradeName,
+				SnapshotTeacherName:      snapshot.TeacherName,
 			})
 			if err != nil {
 				return fmt.Errorf("qtx.InsertEnrollmentPayment(): %w", err)
 			}
+
+			err = issueEnrollmentPaymentReceipt(newCtx, qtx, enrollmentPaymentID)
+			if err != nil {
+				return fmt.Errorf("issueEnrollmentPaymentReceipt(): %w", err)
+			}
 			enrollmentPaymentIDs = append(enrollmentPaymentIDs, entity.EnrollmentPaymentID(enrollmentPaymentID))
 		}
 		return nil
@@ -1519,6 +3042,34 @@ func (s entityServiceImpl) InsertEnrollmentPayments(ctx context.Context, specs [
 	return enrollmentPaymentIDs, nil
 }
 
+// issueEnrollmentPaymentReceipt issues the receipt of a newly inserted EnrollmentPayment, with the next receipt number.
+//
+// It must be called within the transaction which inserts the EnrollmentPayment, so that a rolled back insertion also rolls back the taken receipt number.
+func issueEnrollmentPaymentReceipt(ctx context.Context, qtx *mysql.Queries, enrollmentPaymentID int64) error {
+	lastReceiptNumber, err := qtx.GetLastReceiptNumberForUpdate(ctx)
+	if err != nil {
+		return fmt.Errorf("qtx.GetLastReceiptNumberForUpdate(): %w", err)
+	}
+
+	receiptNumber := lastReceiptNumber + 1
+	err = qtx.UpdateLastReceiptNumber(ctx, receiptNumber)
+	if err != nil {
+		return fmt.Errorf("qtx.UpdateLastReceiptNumber(): %w", err)
+	}
+
+	authInfo := network.GetAuthInfo(ctx)
+	_, err = qtx.InsertEnrollmentPaymentReceipt(ctx, mysql.InsertEnrollmentPaymentReceiptParams{
+		ReceiptNumber:       receiptNumber,
+		EnrollmentPaymentID: sql.NullInt64{Int64: enrollmentPaymentID, Valid: true},
+		IssuedByUserID:      sql.NullInt64{Int64: int64(authInfo.UserID), Valid: authInfo.UserID != identity.UserID_None},
+	})
+	if err != nil {
+		return fmt.Errorf("qtx.InsertEnrollmentPaymentReceipt(): %w", err)
+	}
+
+	return nil
+}
+
 func (s entityServiceImpl) UpdateEnrollmentPayments(ctx context.Context, specs []entity.UpdateEnrollmentPaymentSpec) ([]entity.EnrollmentPaymentID, error) {
 	errV := util.ValidateUpdateSpecs(ctx, specs, s.mySQLQueries.CountEnrollmentPaymentsByIds)
 	if errV != nil {
@@ -1527,7 +3078,32 @@ func (s entityServiceImpl) UpdateEnrollmentPayments(ctx context.Context, specs [
 
 	enrollmentPaymentIDs := make([]entity.EnrollmentPaymentID, 0, len(specs))
 
+	idsInt64 := make([]int64, 0, len(specs))
+	paymentDates := make([]time.Time, 0, len(specs))
+	for _, spec := range specs {
+		idsInt64 = append(idsInt64, int64(spec.EnrollmentPaymentID))
+		paymentDates = append(paymentDates, spec.PaymentDate)
+	}
+
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		// both the previous & the new dates must be outside of the closed period & closed cash-up days
+		prevPaymentDates, err := qtx.GetEnrollmentPaymentDatesByIds(newCtx, idsInt64)
+		if err != nil {
+			return fmt.Errorf("qtx.GetEnrollmentPaymentDatesByIds(): %w", err)
+		}
+		allPaymentDates := append(prevPaymentDates, paymentDates...)
+		err = s.EnsureDatesNotInClosedPeriod(newCtx, entity.EnsureDatesNotInClosedPeriodSpec{
+			Operation: "UpdateEnrollmentPayments",
+			Dates:     allPaymentDates,
+		})
+		if err != nil {
+			return fmt.Errorf("EnsureDatesNotInClosedPeriod(): %w", err)
+		}
+		err = s.EnsurePaymentDatesNotInClosedCashUpDay(newCtx, allPaymentDates)
+		if err != nil {
+			return fmt.Errorf("EnsurePaymentDatesNotInClosedCashUpDay(): %w", err)
+		}
+
 		for _, spec := range specs {
 			err := qtx.UpdateEnrollmentPayment(newCtx, mysql.UpdateEnrollmentPaymentParams{
 				PaymentDate:       spec.PaymentDate,
@@ -1537,6 +3113,9 @@ func (s entityServiceImpl) UpdateEnrollmentPayments(ctx context.Context, specs [
 				TransportFeeValue: spec.TransportFeeValue,
 				PenaltyFeeValue:   spec.PenaltyFeeValue,
 				DiscountFeeValue:  spec.DiscountFeeValue,
+				PaymentMethod:     string(paymentMethodOrDefault(spec.PaymentMethod)),
+				ReceivingAccount:  spec.ReceivingAccount,
+				ReferenceNumber:   spec.ReferenceNumber,
 				ID:                int64(spec.EnrollmentPaymentID),
 			})
 			if err != nil {
@@ -1560,7 +3139,23 @@ func (s entityServiceImpl) DeleteEnrollmentPayments(ctx context.Context, ids []e
 	}
 
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
-		err := qtx.DeleteEnrollmentPaymentsByIds(newCtx, enrollmentPaymentIdsInt64)
+		prevDates, err := qtx.GetEnrollmentPaymentDatesByIds(newCtx, enrollmentPaymentIdsInt64)
+		if err != nil {
+			return fmt.Errorf("qtx.GetEnrollmentPaymentDatesByIds(): %w", err)
+		}
+		err = s.EnsureDatesNotInClosedPeriod(newCtx, entity.EnsureDatesNotInClosedPeriodSpec{
+			Operation: "DeleteEnrollmentPayments",
+			Dates:     prevDates,
+		})
+		if err != nil {
+			return fmt.Errorf("EnsureDatesNotInClosedPeriod(): %w", err)
+		}
+		err = s.EnsurePaymentDatesNotInClosedCashUpDay(newCtx, prevDates)
+		if err != nil {
+			return fmt.Errorf("EnsurePaymentDatesNotInClosedCashUpDay(): %w", err)
+		}
+
+		err = qtx.DeleteEnrollmentPaymentsByIds(newCtx, enrollmentPaymentIdsInt64)
 		if err != nil {
 			return fmt.Errorf("qtx.DeleteEnrollmentPaymentByIds(): %w", err)
 		}
@@ -1672,6 +3267,24 @@ func (s entityServiceImpl) InsertStudentLearningTokens(ctx context.Context, spec
 				return fmt.Errorf("qtx.InsertStudentLearningToken(): %w", err)
 			}
 			studentLearningTokenIDs = append(studentLearningTokenIDs, entity.StudentLearningTokenID(studentLearningTokenID))
+
+			if spec.Quota != 0 {
+				reason := spec.QuotaChangeReason
+				if reason == "" {
+					reason = entity.SLTTransactionReason_Manual
+				}
+				_, err = s.InsertSLTTransactions(newCtx, []entity.InsertSLTTransactionSpec{
+					{
+						StudentLearningTokenID: entity.StudentLearningTokenID(studentLearningTokenID),
+						QuotaChange:            spec.Quota,
+						Reason:                 reason,
+						SourceID:               spec.QuotaChangeSourceID,
+					},
+				})
+				if err != nil {
+					return fmt.Errorf("InsertSLTTransactions(): %w", err)
+				}
+			}
 		}
 		return nil
 	})
@@ -1692,7 +3305,12 @@ func (s entityServiceImpl) UpdateStudentLearningTokens(ctx context.Context, spec
 
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
 		for _, spec := range specs {
-			err := qtx.UpdateStudentLearningToken(newCtx, mysql.UpdateStudentLearningTokenParams{
+			prevSLT, err := qtx.GetStudentLearningTokenById(newCtx, int64(spec.StudentLearningTokenID))
+			if err != nil {
+				return fmt.Errorf("qtx.GetStudentLearningTokenById(): %w", err)
+			}
+
+			err = qtx.UpdateStudentLearningToken(newCtx, mysql.UpdateStudentLearningTokenParams{
 				Quota:                    spec.Quota,
 				CourseFeeQuarterValue:    spec.CourseFeeQuarterValue,
 				TransportFeeQuarterValue: spec.TransportFeeQuarterValue,
@@ -1702,6 +3320,19 @@ func (s entityServiceImpl) UpdateStudentLearningTokens(ctx context.Context, spec
 			if err != nil {
 				return fmt.Errorf("qtx.UpdateStudentLearningToken(): %w", err)
 			}
+
+			if quotaChange := spec.Quota - prevSLT.Quota; quotaChange != 0 {
+				_, err = s.InsertSLTTransactions(newCtx, []entity.InsertSLTTransactionSpec{
+					{
+						StudentLearningTokenID: spec.StudentLearningTokenID,
+						QuotaChange:            quotaChange,
+						Reason:                 entity.SLTTransactionReason_Manual,
+					},
+				})
+				if err != nil {
+					return fmt.Errorf("InsertSLTTransactions(): %w", err)
+				}
+			}
 			studentLearningTokenIDs = append(studentLearningTokenIDs, spec.StudentLearningTokenID)
 		}
 		return nil
@@ -1733,6 +3364,45 @@ func (s entityServiceImpl) DeleteStudentLearningTokens(ctx context.Context, ids
 	return nil
 }
 
+func (s entityServiceImpl) GetSLTTransactionsByStudentLearningTokenId(ctx context.Context, id entity.StudentLearningTokenID) ([]entity.SLTTransaction, error) {
+	sltTransactionRows, err := s.mySQLQueries.GetSLTTransactionsByTokenId(ctx, int64(id))
+	if err != nil {
+		return []entity.SLTTransaction{}, fmt.Errorf("mySQLQueries.GetSLTTransactionsByTokenId(): %w", err)
+	}
+
+	sltTransactions := NewSLTTransactionsFromGetSLTTransactionsByTokenIdRow(sltTransactionRows)
+
+	return sltTransactions, nil
+}
+
+func (s entityServiceImpl) InsertSLTTransactions(ctx context.Context, specs []entity.InsertSLTTransactionSpec) ([]entity.SLTTransactionID, error) {
+	sltTransactionIDs := make([]entity.SLTTransactionID, 0, len(specs))
+	authInfo := network.GetAuthInfo(ctx)
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		for _, spec := range specs {
+			sltTransactionID, err := qtx.InsertSLTTransaction(newCtx, mysql.InsertSLTTransactionParams{
+				QuotaChange: spec.QuotaChange,
+				Reason:      string(spec.Reason),
+				SourceID:    sql.NullInt64{Int64: spec.SourceID, Valid: spec.SourceID != 0},
+				UserID:      sql.NullInt64{Int64: int64(authInfo.UserID), Valid: authInfo.UserID != identity.UserID_None},
+				CreatedAt:   time.Now().UTC(),
+				TokenID:     int64(spec.StudentLearningTokenID),
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.InsertSLTTransaction(): %w", err)
+			}
+			sltTransactionIDs = append(sltTransactionIDs, entity.SLTTransactionID(sltTransactionID))
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.SLTTransactionID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return sltTransactionIDs, nil
+}
+
 func (s entityServiceImpl) GetAttendances(ctx context.Context, pagination util.PaginationSpec, spec entity.GetAttendancesSpec, sortRecent bool) (entity.GetAttendancesResult, error) {
 	pagination.SetDefaultOnInvalidValues()
 	limit, offset := pagination.GetLimitAndOffset()
@@ -1896,7 +3566,20 @@ func (s entityServiceImpl) GetAttendancesByIds(ctx context.Context, ids []entity
 func (s entityServiceImpl) InsertAttendances(ctx context.Context, specs []entity.InsertAttendanceSpec) ([]entity.AttendanceID, error) {
 	attendanceIDs := make([]entity.AttendanceID, 0, len(specs))
 
+	dates := make([]time.Time, 0, len(specs))
+	for _, spec := range specs {
+		dates = append(dates, spec.Date)
+	}
+
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		err := s.EnsureDatesNotInClosedPeriod(newCtx, entity.EnsureDatesNotInClosedPeriodSpec{
+			Operation: "InsertAttendances",
+			Dates:     dates,
+		})
+		if err != nil {
+			return fmt.Errorf("EnsureDatesNotInClosedPeriod(): %w", err)
+		}
+
 		for _, spec := range specs {
 			attendanceID, err := qtx.InsertAttendance(newCtx, mysql.InsertAttendanceParams{
 				Date:                  spec.Date,
@@ -1916,6 +3599,15 @@ func (s entityServiceImpl) InsertAttendances(ctx context.Context, specs []entity
 			}
 			attendanceIDs = append(attendanceIDs, entity.AttendanceID(attendanceID))
 		}
+
+		err = qtx.SetAttendancesOriginalTeacherByIds(newCtx, attendanceIDsToInt64s(attendanceIDs))
+		if err != nil {
+			return fmt.Errorf("qtx.SetAttendancesOriginalTeacherByIds(): %w", err)
+		}
+		err = refreshAttendancesSubstituteStatus(newCtx, qtx, attendanceIDs)
+		if err != nil {
+			return fmt.Errorf("refreshAttendancesSubstituteStatus(): %w", err)
+		}
 		return nil
 	})
 	if err != nil {
@@ -1932,7 +3624,27 @@ func (s entityServiceImpl) UpdateAttendances(ctx context.Context, specs []entity
 
 	attendanceIDs := make([]entity.AttendanceID, 0, len(specs))
 
+	idsInt64 := make([]int64, 0, len(specs))
+	dates := make([]time.Time, 0, len(specs))
+	for _, spec := range specs {
+		idsInt64 = append(idsInt64, int64(spec.AttendanceID))
+		dates = append(dates, spec.Date)
+	}
+
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		// both the previous & the new dates must be outside of the closed period
+		prevDates, err := qtx.GetAttendanceDatesByIds(newCtx, idsInt64)
+		if err != nil {
+			return fmt.Errorf("qtx.GetAttendanceDatesByIds(): %w", err)
+		}
+		err = s.EnsureDatesNotInClosedPeriod(newCtx, entity.EnsureDatesNotInClosedPeriodSpec{
+			Operation: "UpdateAttendances",
+			Dates:     append(prevDates, dates...),
+		})
+		if err != nil {
+			return fmt.Errorf("EnsureDatesNotInClosedPeriod(): %w", err)
+		}
+
 		for _, spec := range specs {
 			err := qtx.UpdateAttendance(newCtx, mysql.UpdateAttendanceParams{
 				Date:                  spec.Date,
@@ -1951,6 +3663,11 @@ func (s entityServiceImpl) UpdateAttendances(ctx context.Context, specs []entity
 			}
 			attendanceIDs = append(attendanceIDs, spec.AttendanceID)
 		}
+
+		err = refreshAttendancesSubstituteStatus(newCtx, qtx, attendanceIDs)
+		if err != nil {
+			return fmt.Errorf("refreshAttendancesSubstituteStatus(): %w", err)
+		}
 		return nil
 	})
 	if err != nil {
@@ -1960,6 +3677,23 @@ func (s entityServiceImpl) UpdateAttendances(ctx context.Context, specs []entity
 	return attendanceIDs, nil
 }
 
+func attendanceIDsToInt64s(ids []entity.AttendanceID) []int64 {
+	idsInt64 := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		idsInt64 = append(idsInt64, int64(id))
+	}
+	return idsInt64
+}
+
+// refreshAttendancesSubstituteStatus flags the attendances taught by another teacher than their original teacher (the class' teacher at insertion) as substitutions.
+func refreshAttendancesSubstituteStatus(ctx context.Context, qtx *mysql.Queries, ids []entity.AttendanceID) error {
+	err := qtx.RefreshAttendancesSubstituteStatusByIds(ctx, attendanceIDsToInt64s(ids))
+	if err != nil {
+		return fmt.Errorf("qtx.RefreshAttendancesSubstituteStatusByIds(): %w", err)
+	}
+	return nil
+}
+
 func (s entityServiceImpl) DeleteAttendances(ctx context.Context, ids []entity.AttendanceID) error {
 	attendanceIdsInt64 := make([]int64, 0, len(ids))
 	for _, id := range ids {
@@ -1967,7 +3701,19 @@ func (s entityServiceImpl) DeleteAttendances(ctx context.Context, ids []entity.A
 	}
 
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
-		err := qtx.DeleteAttendancesByIds(newCtx, attendanceIdsInt64)
+		prevDates, err := qtx.GetAttendanceDatesByIds(newCtx, attendanceIdsInt64)
+		if err != nil {
+			return fmt.Errorf("qtx.GetAttendanceDatesByIds(): %w", err)
+		}
+		err = s.EnsureDatesNotInClosedPeriod(newCtx, entity.EnsureDatesNotInClosedPeriodSpec{
+			Operation: "DeleteAttendances",
+			Dates:     prevDates,
+		})
+		if err != nil {
+			return fmt.Errorf("EnsureDatesNotInClosedPeriod(): %w", err)
+		}
+
+		err = qtx.DeleteAttendancesByIds(newCtx, attendanceIdsInt64)
 		if err != nil {
 			return fmt.Errorf("qtx.DeleteAttendanceByIds(): %w", err)
 		}
@@ -2112,11 +3858,32 @@ func (s entityServiceImpl) InsertTeacherPayments(ctx context.Context, specs []en
 	teacherPaymentIDs := make([]entity.TeacherPaymentID, 0, len(specs))
 
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		err := s.EnsureDatesNotInClosedPeriod(newCtx, entity.EnsureDatesNotInClosedPeriodSpec{
+			Operation: "InsertTeacherPayments",
+			Dates:     []time.Time{time.Now()},
+		})
+		if err != nil {
+			return fmt.Errorf("EnsureDatesNotInClosedPeriod(): %w", err)
+		}
+
 		for _, spec := range specs {
+			// the snapshot's teacher is the attendance's teacher (who actually taught), instead of the class' teacher
+			attendance, err := s.GetAttendanceById(newCtx, spec.AttendanceID)
+			if err != nil {
+				return fmt.Errorf("GetAttendanceById(): %w", err)
+			}
+			snapshot := entity.NewPaymentSnapshot(attendance.StudentInfo, attendance.ClassInfo, &attendance.TeacherInfo)
+
 			teacherPaymentID, err := qtx.InsertTeacherPayment(newCtx, mysql.InsertTeacherPaymentParams{
-				AttendanceID:          int64(spec.AttendanceID),
-				PaidCourseFeeValue:    spec.PaidCourseFeeValue,
-				PaidTransportFeeValue: spec.PaidTransportFeeValue,
+				AttendanceID:             int64(spec.AttendanceID),
+				PaidCourseFeeValue:       spec.PaidCourseFeeValue,
+				PaidTransportFeeValue:    spec.PaidTransportFeeValue,
+				SnapshotStudentName:      snapshot.StudentName,
+				SnapshotClassDescription: snapshot.ClassDescription,
+				SnapshotCourseName:       snapshot.CourseName,
+				SnapshotInstrumentName:   snapshot.InstrumentName,
+				SnapshotGradeName:        snapshot.GradeName,
+				SnapshotTeacherName:      snapshot.TeacherName,
 			})
 			if err != nil {
 				return fmt.Errorf("qtx.InsertTeacherPayment(): %w", err)
@@ -2140,7 +3907,27 @@ func (s entityServiceImpl) UpdateTeacherPayments(ctx context.Context, specs []en
 
 	teacherPaymentIDs := make([]entity.TeacherPaymentID, 0, len(specs))
 
+	idsInt64 := make([]int64, 0, len(specs))
+	addedAts := make([]time.Time, 0, len(specs))
+	for _, spec := range specs {
+		idsInt64 = append(idsInt64, int64(spec.TeacherPaymentID))
+		addedAts = append(addedAts, spec.AddedAt)
+	}
+
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		// both the previous & the new dates must be outside of the closed period
+		prevAddedAts, err := qtx.GetTeacherPaymentAddedAtsByIds(newCtx, idsInt64)
+		if err != nil {
+			return fmt.Errorf("qtx.GetTeacherPaymentAddedAtsByIds(): %w", err)
+		}
+		err = s.EnsureDatesNotInClosedPeriod(newCtx, entity.EnsureDatesNotInClosedPeriodSpec{
+			Operation: "UpdateTeacherPayments",
+			Dates:     append(prevAddedAts, addedAts...),
+		})
+		if err != nil {
+			return fmt.Errorf("EnsureDatesNotInClosedPeriod(): %w", err)
+		}
+
 		for _, spec := range specs {
 			err := qtx.UpdateTeacherPayment(newCtx, mysql.UpdateTeacherPaymentParams{
 				AttendanceID:          int64(spec.AttendanceID),
@@ -2170,7 +3957,19 @@ func (s entityServiceImpl) DeleteTeacherPayments(ctx context.Context, ids []enti
 	}
 
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
-		err := qtx.DeleteTeacherPaymentsByIds(newCtx, teacherPaymentIdsInt64)
+		prevDates, err := qtx.GetTeacherPaymentAddedAtsByIds(newCtx, teacherPaymentIdsInt64)
+		if err != nil {
+			return fmt.Errorf("qtx.GetTeacherPaymentAddedAtsByIds(): %w", err)
+		}
+		err = s.EnsureDatesNotInClosedPeriod(newCtx, entity.EnsureDatesNotInClosedPeriodSpec{
+			Operation: "DeleteTeacherPayments",
+			Dates:     prevDates,
+		})
+		if err != nil {
+			return fmt.Errorf("EnsureDatesNotInClosedPeriod(): %w", err)
+		}
+
+		err = qtx.DeleteTeacherPaymentsByIds(newCtx, teacherPaymentIdsInt64)
 		if err != nil {
 			return fmt.Errorf("qtx.DeleteTeacherPaymentsByIds(): %w", err)
 		}
@@ -2182,3 +3981,282 @@ func (s entityServiceImpl) DeleteTeacherPayments(ctx context.Context, ids []enti
 
 	return nil
 }
+
+func (s entityServiceImpl) GetClosedPeriods(ctx context.Context, pagination util.PaginationSpec) (entity.GetClosedPeriodsResult, error) {
+	pagination.SetDefaultOnInvalidValues()
+	limit, offset := pagination.GetLimitAndOffset()
+
+	var closedPeriodRows = make([]mysql.GetClosedPeriodsRow, 0)
+	var totalResults int64 = 0
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		closedPeriodRows, err = qtx.GetClosedPeriods(newCtx, mysql.GetClosedPeriodsParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetClosedPeriods(): %w", err)
+		}
+
+		totalResults, err = qtx.CountClosedPeriods(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.CountClosedPeriods(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.GetClosedPeriodsResult{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	closedPeriods := NewClosedPeriodsFromGetClosedPeriodsRow(closedPeriodRows)
+
+	return entity.GetClosedPeriodsResult{
+		ClosedPeriods:    closedPeriods,
+		PaginationResult: *util.NewPaginationResult(int(totalResults), pagination.ResultsPerPage, pagination.Page),
+	}, nil
+}
+
+func (s entityServiceImpl) GetClosedPeriodById(ctx context.Context, id entity.ClosedPeriodID) (entity.ClosedPeriod, error) {
+	closedPeriodRow, err := s.mySQLQueries.GetClosedPeriodById(ctx, int64(id))
+	if err != nil {
+		return entity.ClosedPeriod{}, fmt.Errorf("mySQLQueries.GetClosedPeriodById(): %w", err)
+	}
+
+	closedPeriod := NewClosedPeriodsFromGetClosedPeriodByIdRow([]mysql.GetClosedPeriodByIdRow{closedPeriodRow})[0]
+
+	return closedPeriod, nil
+}
+
+func (s entityServiceImpl) InsertClosedPeriods(ctx context.Context, specs []entity.InsertClosedPeriodSpec) ([]entity.ClosedPeriodID, error) {
+	closedPeriodIDs := make([]entity.ClosedPeriodID, 0, len(specs))
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		authInfo := network.GetAuthInfo(newCtx)
+		for _, spec := range specs {
+			closedPeriodID, err := qtx.InsertClosedPeriod(newCtx, mysql.InsertClosedPeriodParams{
+				ClosedUntil:     spec.ClosedUntil,
+				Note:            spec.Note,
+				CreatedByUserID: sql.NullInt64{Int64: int64(authInfo.UserID), Valid: authInfo.UserID != identity.UserID_None},
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.InsertClosedPeriod(): %w", err)
+			}
+			closedPeriodIDs = append(closedPeriodIDs, entity.ClosedPeriodID(closedPeriodID))
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.ClosedPeriodID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return closedPeriodIDs, nil
+}
+
+func (s entityServiceImpl) DeleteClosedPeriods(ctx context.Context, ids []entity.ClosedPeriodID) error {
+	closedPeriodIdsInt64 := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		closedPeriodIdsInt64 = append(closedPeriodIdsInt64, int64(id))
+	}
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		err := qtx.DeleteClosedPeriodsByIds(newCtx, closedPeriodIdsInt64)
+		if err != nil {
+			return fmt.Errorf("qtx.DeleteClosedPeriodsByIds(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
+
+func (s entityServiceImpl) GetClosedPeriodOverrides(ctx context.Context, pagination util.PaginationSpec) (entity.GetClosedPeriodOverridesResult, error) {
+	pagination.SetDefaultOnInvalidValues()
+	limit, offset := pagination.GetLimitAndOffset()
+
+	var closedPeriodOverrideRows = make([]mysql.GetClosedPeriodOverridesRow, 0)
+	var totalResults int64 = 0
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		closedPeriodOverrideRows, err = qtx.GetClosedPeriodOverrides(newCtx, mysql.GetClosedPeriodOverridesParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetClosedPeriodOverrides(): %w", err)
+		}
+
+		totalResults, err = qtx.CountClosedPeriodOverrides(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.CountClosedPeriodOverrides(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.GetClosedPeriodOverridesResult{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	closedPeriodOverrides := NewClosedPeriodOverridesFromGetClosedPeriodOverridesRow(closedPeriodOverrideRows)
+
+	return entity.GetClosedPeriodOverridesResult{
+		ClosedPeriodOverrides: closedPeriodOverrides,
+		PaginationResult:      *util.NewPaginationResult(int(totalResults), pagination.ResultsPerPage, pagination.Page),
+	}, nil
+}
+
+func (s entityServiceImpl) EnsureDatesNotInClosedPeriod(ctx context.Context, spec entity.EnsureDatesNotInClosedPeriodSpec) error {
+	if len(spec.Dates) == 0 {
+		return nil
+	}
+
+	earliestDate := spec.Dates[0]
+	for _, date := range spec.Dates {
+		if date.Before(earliestDate) {
+			earliestDate = date
+		}
+	}
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		closedUntil, err := qtx.GetLatestClosedPeriodClosedUntil(newCtx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) { // no period has been closed yet
+				return nil
+			}
+			return fmt.Errorf("qtx.GetLatestClosedPeriodClosedUntil(): %w", err)
+		}
+
+		// closedUntil is inclusive, i.e. the whole day of closedUntil is also closed
+		if !earliestDate.Before(closedUntil.AddDate(0, 0, 1)) {
+			return nil
+		}
+
+		authInfo := network.GetAuthInfo(newCtx)
+		overrideReason := network.GetRequestContext(newCtx).ClosedPeriodOverrideReason
+		if authInfo.PrivilegeType < identity.UserPrivilegeType_Super_Admin || overrideReason == "" {
+			return fmt.Errorf("operation='%s' affects a record dated '%s', which is closed until '%s': %w", spec.Operation, earliestDate.Format("2006-01-02"), closedUntil.Format("2006-01-02"), errs.ErrDateInClosedPeriod)
+		}
+
+		_, err = qtx.InsertClosedPeriodOverride(newCtx, mysql.InsertClosedPeriodOverrideParams{
+			Operation:          spec.Operation,
+			Reason:             overrideReason,
+			ClosedUntil:        closedUntil,
+			EarliestRecordDate: earliestDate,
+			UserID:             sql.NullInt64{Int64: int64(authInfo.UserID), Valid: authInfo.UserID != identity.UserID_None},
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.InsertClosedPeriodOverride(): %w", err)
+		}
+		mainLog.Warn("Closed period is overridden: operation='%s', earliestRecordDate='%s', closedUntil='%s', userID='%d', reason='%s'",
+			spec.Operation, earliestDate.Format("2006-01-02"), closedUntil.Format("2006-01-02"), authInfo.UserID, overrideReason)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
+
+func (s entityServiceImpl) GetCashUpDayByDate(ctx context.Context, date time.Time) (entity.CashUpDay, error) {
+	var cashUpDayRow mysql.GetCashUpDayByDateRow
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		cashUpDayRow, err = qtx.GetCashUpDayByDate(newCtx, toCashUpDate(date))
+		if err != nil {
+			return fmt.Errorf("qtx.GetCashUpDayByDate(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.CashUpDay{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return NewCashUpDayFromGetCashUpDayByDateRow(cashUpDayRow), nil
+}
+
+func (s entityServiceImpl) InsertCashUpDay(ctx context.Context, spec entity.InsertCashUpDaySpec) (entity.CashUpDayID, error) {
+	authInfo := network.GetAuthInfo(ctx)
+
+	var cashUpDayID int64
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		cashUpDayID, err = qtx.InsertCashUpDay(newCtx, mysql.InsertCashUpDayParams{
+			Date:              toCashUpDate(spec.Date),
+			CountedCashValue:  spec.CountedCashValue,
+			RecordedCashValue: spec.RecordedCashValue,
+			DifferenceValue:   spec.CountedCashValue - spec.RecordedCashValue,
+			Note:              spec.Note,
+			ClosedByUserID:    sql.NullInt64{Int64: int64(authInfo.UserID), Valid: authInfo.UserID != identity.UserID_None},
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.InsertCashUpDay(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.CashUpDayID_None, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return entity.CashUpDayID(cashUpDayID), nil
+}
+
+func (s entityServiceImpl) DeleteCashUpDayByDate(ctx context.Context, date time.Time) error {
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		affectedRows, err := qtx.DeleteCashUpDayByDate(newCtx, toCashUpDate(date))
+		if err != nil {
+			return fmt.Errorf("qtx.DeleteCashUpDayByDate(): %w", err)
+		}
+		if affectedRows == 0 {
+			return fmt.Errorf("cashUpDay with date='%s' is not found: %w", toCashUpDate(date).Format("2006-01-02"), sql.ErrNoRows)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
+
+func (s entityServiceImpl) EnsurePaymentDatesNotInClosedCashUpDay(ctx context.Context, paymentDates []time.Time) error {
+	if len(paymentDates) == 0 {
+		return nil
+	}
+
+	cashUpDates := make([]time.Time, 0, len(paymentDates))
+	for _, paymentDate := range paymentDates {
+		cashUpDates = append(cashUpDates, toCashUpDate(paymentDate))
+	}
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		closedDates, err := qtx.GetClosedCashUpDatesByDates(newCtx, cashUpDates)
+		if err != nil {
+			return fmt.Errorf("qtx.GetClosedCashUpDatesByDates(): %w", err)
+		}
+		if len(closedDates) > 0 {
+			return fmt.Errorf("cash-up day of '%s' has been closed: %w", closedDates[0].Format("2006-01-02"), errs.ErrCashUpDayClosed)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
+
+// paymentMethodOrDefault treats an empty PaymentMethod as cash, which is how payments were received before the payment method is recorded.
+func paymentMethodOrDefault(paymentMethod entity.PaymentMethod) entity.PaymentMethod {
+	if paymentMethod == "" {
+		return entity.PaymentMethod_Cash
+	}
+	return paymentMethod
+}
+
+// toCashUpDate converts t into the DATE of its day in util.DefaultTimezone, as cash-up days follow the school's local days.
+func toCashUpDate(t time.Time) time.Time {
+	return util.ToLocalDate(t)
+}
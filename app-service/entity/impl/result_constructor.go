@@ -6,6 +6,7 @@ import (
 	"sonamusica-backend/app-service/entity"
 	"sonamusica-backend/app-service/identity"
 	"sonamusica-backend/app-service/util"
+	"time"
 )
 
 func NewTeachersFromGetTeachersRow(teacherRows []mysql.GetTeachersRow) []entity.Teacher {
@@ -205,6 +206,50 @@ func NewStudentEnrollmentsFromGetStudentEnrollmentsRow(studentEnrollmentRows []m
 	return studentEnrollments
 }
 
+func NewFamiliesFromMySQLFamilies(familyRows []mysql.Family, familyStudentRows []mysql.GetFamilyStudentsByFamilyIdsRow, familyGuardianRows []mysql.GetFamilyGuardiansByFamilyIdsRow) []entity.Family {
+	familyIdToStudents := make(map[int64][]entity.StudentInfo_Minimal, 0)
+	for _, row := range familyStudentRows {
+		familyIdToStudents[row.FamilyID] = append(familyIdToStudents[row.FamilyID], entity.StudentInfo_Minimal{
+			StudentID: entity.StudentID(row.StudentID),
+			UserInfo_Minimal: identity.UserInfo_Minimal{
+				Username:   row.StudentUsername,
+				UserDetail: identity.UnmarshalUserDetail(row.StudentDetail, mainLog),
+			},
+		})
+	}
+	familyIdToGuardians := make(map[int64][]entity.FamilyGuardian, 0)
+	for _, row := range familyGuardianRows {
+		familyIdToGuardians[row.FamilyID] = append(familyIdToGuardians[row.FamilyID], entity.FamilyGuardian{
+			UserID: identity.UserID(row.UserID),
+			UserInfo_Minimal: identity.UserInfo_Minimal{
+				Username:   row.Username,
+				UserDetail: identity.UnmarshalUserDetail(row.UserDetail, mainLog),
+			},
+		})
+	}
+
+	families := make([]entity.Family, 0, len(familyRows))
+	for _, familyRow := range familyRows {
+		students := familyIdToStudents[familyRow.ID]
+		if students == nil {
+			students = make([]entity.StudentInfo_Minimal, 0)
+		}
+		guardians := familyIdToGuardians[familyRow.ID]
+		if guardians == nil {
+			guardians = make([]entity.FamilyGuardian, 0)
+		}
+
+		families = append(families, entity.Family{
+			FamilyID:  entity.FamilyID(familyRow.ID),
+			Name:      familyRow.Name,
+			Students:  students,
+			Guardians: guardians,
+		})
+	}
+
+	return families
+}
+
 func NewTeacherSpecialFeesFromGetTeacherSpecialFeesRow(teacherSpecialFeeRows []mysql.GetTeacherSpecialFeesRow) []entity.TeacherSpecialFee {
 	teacherSpecialFees := make([]entity.TeacherSpecialFee, 0, len(teacherSpecialFeeRows))
 	for _, teacherSpecialFeeRow := range teacherSpecialFeeRows {
@@ -233,63 +278,195 @@ func NewTeacherSpecialFeesFromGetTeacherSpecialFeesRow(teacherSpecialFeeRows []m
 	return teacherSpecialFees
 }
 
+func NewPenaltyPoliciesFromMySQLPenaltyPolicies(penaltyPolicyRows []mysql.PenaltyPolicy) []entity.PenaltyPolicy {
+	penaltyPolicies := make([]entity.PenaltyPolicy, 0, len(penaltyPolicyRows))
+	for _, penaltyPolicyRow := range penaltyPolicyRows {
+		scope := entity.PenaltyPolicyScope_Default
+		if penaltyPolicyRow.ClassID.Valid {
+			scope = entity.PenaltyPolicyScope_Class
+		} else if penaltyPolicyRow.CourseID.Valid {
+			scope = entity.PenaltyPolicyScope_Course
+		}
+
+		penaltyPolicies = append(penaltyPolicies, entity.PenaltyPolicy{
+			PenaltyPolicyID:   entity.PenaltyPolicyID(penaltyPolicyRow.ID),
+			Name:              penaltyPolicyRow.Name,
+			Scope:             scope,
+			CourseID:          entity.CourseID(penaltyPolicyRow.CourseID.Int64),
+			ClassID:           entity.ClassID(penaltyPolicyRow.ClassID.Int64),
+			TriggerDayOfMonth: penaltyPolicyRow.TriggerDayOfMonth,
+			GraceDays:         penaltyPolicyRow.GraceDays,
+			IsFlatFee:         util.Int32ToBool(penaltyPolicyRow.IsFlatFee),
+			FeeValue:          penaltyPolicyRow.FeeValue,
+			MaxFeeValue:       penaltyPolicyRow.MaxFeeValue,
+		})
+	}
+
+	return penaltyPolicies
+}
+
+func NewDiscountRulesFromMySQLDiscountRules(discountRuleRows []mysql.DiscountRule) []entity.DiscountRule {
+	discountRules := make([]entity.DiscountRule, 0, len(discountRuleRows))
+	for _, discountRuleRow := range discountRuleRows {
+		var validFrom, validUntil *time.Time
+		if discountRuleRow.ValidFrom.Valid {
+			validFrom = &discountRuleRow.ValidFrom.Time
+		}
+		if discountRuleRow.ValidUntil.Valid {
+			validUntil = &discountRuleRow.ValidUntil.Time
+		}
+
+		discountRules = append(discountRules, entity.DiscountRule{
+			DiscountRuleID: entity.DiscountRuleID(discountRuleRow.ID),
+			Name:           discountRuleRow.Name,
+			Type:           entity.DiscountRuleType(discountRuleRow.Type),
+			IsPercentage:   util.Int32ToBool(discountRuleRow.IsPercentage),
+			Value:          discountRuleRow.Value,
+			MinCount:       discountRuleRow.MinCount,
+			MaxDayOfMonth:  discountRuleRow.MaxDayOfMonth,
+			VoucherCode:    discountRuleRow.VoucherCode.String,
+			UsageLimit:     discountRuleRow.UsageLimit.Int32,
+			ValidFrom:      validFrom,
+			ValidUntil:     validUntil,
+			IsDeactivated:  util.Int32ToBool(discountRuleRow.IsDeactivated),
+		})
+	}
+
+	return discountRules
+}
+
+func NewTeacherFeeSharingsFromMySQLTeacherFeeSharings(teacherFeeSharingRows []mysql.TeacherFeeSharing) []entity.TeacherFeeSharing {
+	teacherFeeSharings := make([]entity.TeacherFeeSharing, 0, len(teacherFeeSharingRows))
+	for _, teacherFeeSharingRow := range teacherFeeSharingRows {
+		scope := entity.TeacherFeeSharingScope_Default
+		if teacherFeeSharingRow.TeacherID.Valid && teacherFeeSharingRow.CourseID.Valid {
+			scope = entity.TeacherFeeSharingScope_TeacherCourse
+		} else if teacherFeeSharingRow.TeacherID.Valid {
+			scope = entity.TeacherFeeSharingScope_Teacher
+		} else if teacherFeeSharingRow.CourseID.Valid {
+			scope = entity.TeacherFeeSharingScope_Course
+		}
+
+		teacherFeeSharings = append(teacherFeeSharings, entity.TeacherFeeSharing{
+			TeacherFeeSharingID:           entity.TeacherFeeSharingID(teacherFeeSharingRow.ID),
+			Scope:                         scope,
+			TeacherID:                     entity.TeacherID(teacherFeeSharingRow.TeacherID.Int64),
+			CourseID:                      entity.CourseID(teacherFeeSharingRow.CourseID.Int64),
+			CourseFeeSharingPercentage:    teacherFeeSharingRow.CourseFeeSharingPercentage,
+			TransportFeeSharingPercentage: teacherFeeSharingRow.TransportFeeSharingPercentage,
+			EffectiveFrom:                 teacherFeeSharingRow.EffectiveFrom,
+		})
+	}
+
+	return teacherFeeSharings
+}
+
 func NewEnrollmentPaymentsFromGetEnrollmentPaymentsRow(enrollmentPaymentRows []mysql.GetEnrollmentPaymentsRow) []entity.EnrollmentPayment {
 	enrollmentPayments := make([]entity.EnrollmentPayment, 0, len(enrollmentPaymentRows))
 	for _, enrollmentPaymentRow := range enrollmentPaymentRows {
-		var classTeacherInfo *entity.TeacherInfo_Minimal
-		teacherId := entity.TeacherID(enrollmentPaymentRow.ClassTeacherID.Int64)
-		if enrollmentPaymentRow.ClassTeacherID.Valid && teacherId != entity.TeacherID_None {
-			classTeacherInfo = &entity.TeacherInfo_Minimal{
-				TeacherID: teacherId,
-				UserInfo_Minimal: identity.UserInfo_Minimal{
-					Username:   enrollmentPaymentRow.ClassTeacherUsername.String,
-					UserDetail: identity.UnmarshalUserDetail(enrollmentPaymentRow.ClassTeacherDetail, mainLog),
-				},
-			}
+		snapshot := entity.PaymentSnapshot{
+			StudentName:      enrollmentPaymentRow.SnapshotStudentName,
+			ClassDescription: enrollmentPaymentRow.SnapshotClassDescription,
+			CourseName:       enrollmentPaymentRow.SnapshotCourseName,
+			InstrumentName:   enrollmentPaymentRow.SnapshotInstrumentName,
+			GradeName:        enrollmentPaymentRow.SnapshotGradeName,
+			TeacherName:      enrollmentPaymentRow.SnapshotTeacherName,
 		}
 
-		enrollmentPayments = append(enrollmentPayments, entity.EnrollmentPayment{
-			EnrollmentPaymentID: entity.EnrollmentPaymentID(enrollmentPaymentRow.EnrollmentPaymentID),
-			StudentEnrollmentInfo: entity.StudentEnrollment{
-				StudentEnrollmentID: entity.StudentEnrollmentID(enrollmentPaymentRow.StudentEnrollmentID),
+		var studentEnrollmentInfo entity.StudentEnrollment
+		// the StudentEnrollment has been deleted (enrollment_payment.enrollment_id is set to NULL), thus we fall back to the snapshot
+		if !enrollmentPaymentRow.StudentEnrollmentID.Valid || !enrollmentPaymentRow.ClassID.Valid {
+			studentEnrollmentInfo = newStudentEnrollmentFromPaymentSnapshot(snapshot)
+		} else {
+			var classTeacherInfo *entity.TeacherInfo_Minimal
+			teacherId := entity.TeacherID(enrollmentPaymentRow.ClassTeacherID.Int64)
+			if enrollmentPaymentRow.ClassTeacherID.Valid && teacherId != entity.TeacherID_None {
+				classTeacherInfo = &entity.TeacherInfo_Minimal{
+					TeacherID: teacherId,
+					UserInfo_Minimal: identity.UserInfo_Minimal{
+						Username:   enrollmentPaymentRow.ClassTeacherUsername.String,
+						UserDetail: identity.UnmarshalUserDetail(enrollmentPaymentRow.ClassTeacherDetail, mainLog),
+					},
+				}
+			}
+
+			studentEnrollmentInfo = entity.StudentEnrollment{
+				StudentEnrollmentID: entity.StudentEnrollmentID(enrollmentPaymentRow.StudentEnrollmentID.Int64),
 				StudentInfo: entity.StudentInfo_Minimal{
-					StudentID: entity.StudentID(enrollmentPaymentRow.StudentID),
+					StudentID: entity.StudentID(enrollmentPaymentRow.StudentID.Int64),
 					UserInfo_Minimal: identity.UserInfo_Minimal{
-						Username:   enrollmentPaymentRow.StudentUsername,
+						Username:   enrollmentPaymentRow.StudentUsername.String,
 						UserDetail: identity.UnmarshalUserDetail(enrollmentPaymentRow.StudentDetail, mainLog),
 					},
 				},
 				ClassInfo: entity.ClassInfo_Minimal{
-					ClassID:             entity.ClassID(enrollmentPaymentRow.Class.ID),
+					ClassID:             entity.ClassID(enrollmentPaymentRow.ClassID.Int64),
 					TeacherInfo_Minimal: classTeacherInfo,
 					Course: NewCoursesFromGetCoursesRow([]mysql.GetCoursesRow{
 						{
-							CourseID:              enrollmentPaymentRow.Course.ID,
-							Instrument:            enrollmentPaymentRow.Instrument,
-							Grade:                 enrollmentPaymentRow.Grade,
-							DefaultFee:            enrollmentPaymentRow.Course.DefaultFee,
-							DefaultDurationMinute: enrollmentPaymentRow.Course.DefaultDurationMinute,
+							CourseID:              enrollmentPaymentRow.CourseID.Int64,
+							Instrument:            mysql.Instrument{ID: enrollmentPaymentRow.InstrumentID.Int64, Name: enrollmentPaymentRow.InstrumentName.String},
+							Grade:                 mysql.Grade{ID: enrollmentPaymentRow.GradeID.Int64, Name: enrollmentPaymentRow.GradeName.String},
+							DefaultFee:            enrollmentPaymentRow.CourseDefaultFee.Int32,
+							DefaultDurationMinute: enrollmentPaymentRow.CourseDefaultDurationMinute.Int32,
 						},
 					})[0],
-					TransportFee:           enrollmentPaymentRow.Class.TransportFee,
+					TransportFee:           enrollmentPaymentRow.ClassTransportFee.Int32,
 					TeacherSpecialFee:      enrollmentPaymentRow.TeacherSpecialFee.Int32,
-					AutoOweAttendanceToken: util.Int32ToBool(enrollmentPaymentRow.Class.AutoOweAttendanceToken),
-					IsDeactivated:          util.Int32ToBool(enrollmentPaymentRow.Class.IsDeactivated),
+					AutoOweAttendanceToken: util.Int32ToBool(enrollmentPaymentRow.ClassAutoOweAttendanceToken.Int32),
+					IsDeactivated:          util.Int32ToBool(enrollmentPaymentRow.ClassIsDeactivated.Int32),
 				},
-			},
-			PaymentDate:       enrollmentPaymentRow.PaymentDate,
-			BalanceTopUp:      enrollmentPaymentRow.BalanceTopUp,
-			BalanceBonus:      enrollmentPaymentRow.BalanceBonus,
-			CourseFeeValue:    enrollmentPaymentRow.CourseFeeValue,
-			TransportFeeValue: enrollmentPaymentRow.TransportFeeValue,
-			PenaltyFeeValue:   enrollmentPaymentRow.PenaltyFeeValue,
-			DiscountFeeValue:  enrollmentPaymentRow.DiscountFeeValue,
+			}
+		}
+
+		enrollmentPayments = append(enrollmentPayments, entity.EnrollmentPayment{
+			EnrollmentPaymentID:   entity.EnrollmentPaymentID(enrollmentPaymentRow.EnrollmentPaymentID),
+			StudentEnrollmentInfo: studentEnrollmentInfo,
+			PaymentDate:           enrollmentPaymentRow.PaymentDate,
+			BalanceTopUp:          enrollmentPaymentRow.BalanceTopUp,
+			BalanceBonus:          enrollmentPaymentRow.BalanceBonus,
+			CourseFeeValue:        enrollmentPaymentRow.CourseFeeValue,
+			TransportFeeValue:     enrollmentPaymentRow.TransportFeeValue,
+			PenaltyFeeValue:       enrollmentPaymentRow.PenaltyFeeValue,
+			DiscountFeeValue:      enrollmentPaymentRow.DiscountFeeValue,
+			PaymentMethod:         entity.PaymentMethod(enrollmentPaymentRow.PaymentMethod),
+			ReceivingAccount:      enrollmentPaymentRow.ReceivingAccount,
+			ReferenceNumber:       enrollmentPaymentRow.ReferenceNumber,
+			Snapshot:              snapshot,
 		})
 	}
 
 	return enrollmentPayments
 }
 
+// newStudentEnrollmentFromPaymentSnapshot builds a StudentEnrollment without any IDs, whose names are taken from the snapshot.
+// The full names are put into UserDetail.FirstName, as the snapshot doesn't separate the first & last name.
+func newStudentEnrollmentFromPaymentSnapshot(snapshot entity.PaymentSnapshot) entity.StudentEnrollment {
+	var teacherInfo *entity.TeacherInfo_Minimal
+	if snapshot.TeacherName != "" {
+		teacherInfo = &entity.TeacherInfo_Minimal{
+			UserInfo_Minimal: identity.UserInfo_Minimal{
+				UserDetail: identity.UserDetail{FirstName: snapshot.TeacherName},
+			},
+		}
+	}
+
+	return entity.StudentEnrollment{
+		StudentInfo: entity.StudentInfo_Minimal{
+			UserInfo_Minimal: identity.UserInfo_Minimal{
+				UserDetail: identity.UserDetail{FirstName: snapshot.StudentName},
+			},
+		},
+		ClassInfo: entity.ClassInfo_Minimal{
+			TeacherInfo_Minimal: teacherInfo,
+			Course: entity.Course{
+				Instrument: entity.Instrument{Name: snapshot.InstrumentName},
+				Grade:      entity.Grade{Name: snapshot.GradeName},
+			},
+		},
+	}
+}
+
 func NewStudentLearningTokensFromGetStudentLearningTokensRow(studentLearningTokenRows []mysql.GetStudentLearningTokensRow) []entity.StudentLearningToken {
 	studentLearningTokens := make([]entity.StudentLearningToken, 0, len(studentLearningTokenRows))
 	for _, sltRow := range studentLearningTokenRows {
@@ -345,6 +522,24 @@ func NewStudentLearningTokensFromGetStudentLearningTokensRow(studentLearningToke
 	return studentLearningTokens
 }
 
+func NewSLTTransactionsFromGetSLTTransactionsByTokenIdRow(sltTransactionRows []mysql.GetSLTTransactionsByTokenIdRow) []entity.SLTTransaction {
+	sltTransactions := make([]entity.SLTTransaction, 0, len(sltTransactionRows))
+	for _, sltTransactionRow := range sltTransactionRows {
+		sltTransactions = append(sltTransactions, entity.SLTTransaction{
+			SLTTransactionID:       entity.SLTTransactionID(sltTransactionRow.SltTransactionID),
+			StudentLearningTokenID: entity.StudentLearningTokenID(sltTransactionRow.TokenID),
+			QuotaChange:            sltTransactionRow.QuotaChange,
+			Reason:                 entity.SLTTransactionReason(sltTransactionRow.Reason),
+			SourceID:               sltTransactionRow.SourceID.Int64,
+			UserID:                 identity.UserID(sltTransactionRow.UserID.Int64),
+			Username:               sltTransactionRow.Username.String,
+			CreatedAt:              sltTransactionRow.CreatedAt,
+		})
+	}
+
+	return sltTransactions
+}
+
 func NewAttendancesFromGetAttendancesRow(attendanceRows []mysql.GetAttendancesRow) []entity.Attendance {
 	attendances := make([]entity.Attendance, 0, len(attendanceRows))
 	for _, attendanceRow := range attendanceRows {
@@ -408,6 +603,19 @@ func NewAttendancesFromGetAttendancesRow(attendanceRows []mysql.GetAttendancesRo
 			}
 		}
 
+		// the original teacher is only relevant for a substitution
+		var originalTeacherInfo *entity.TeacherInfo_Minimal
+		originalTeacherId := entity.TeacherID(attendanceRow.OriginalTeacherID.Int64)
+		if util.Int32ToBool(attendanceRow.IsSubstitute) && attendanceRow.OriginalTeacherID.Valid && originalTeacherId != entity.TeacherID_None {
+			originalTeacherInfo = &entity.TeacherInfo_Minimal{
+				TeacherID: originalTeacherId,
+				UserInfo_Minimal: identity.UserInfo_Minimal{
+					Username:   attendanceRow.OriginalTeacherUsername.String,
+					UserDetail: identity.UnmarshalUserDetail(attendanceRow.OriginalTeacherDetail, mainLog),
+				},
+			}
+		}
+
 		studentLearningToken := mysql.StudentLearningToken{
 			ID:                       attendanceRow.ID.Int64,
 			Quota:                    attendanceRow.Quota.Float64,
@@ -435,6 +643,8 @@ func NewAttendancesFromGetAttendancesRow(attendanceRows []mysql.GetAttendancesRo
 			Duration:              attendanceRow.Duration,
 			Note:                  attendanceRow.Note,
 			IsPaid:                util.Int32ToBool(attendanceRow.IsPaid),
+			IsSubstitute:          util.Int32ToBool(attendanceRow.IsSubstitute),
+			OriginalTeacherInfo:   originalTeacherInfo,
 		})
 	}
 
@@ -444,43 +654,70 @@ func NewAttendancesFromGetAttendancesRow(attendanceRows []mysql.GetAttendancesRo
 func NewTeacherPaymentsFromGetTeacherPaymentsRow(teacherPaymentRows []mysql.GetTeacherPaymentsRow) []entity.TeacherPayment {
 	teacherPayments := make([]entity.TeacherPayment, 0, len(teacherPaymentRows))
 	for _, tpRow := range teacherPaymentRows {
+		attendance := NewAttendancesFromGetAttendancesRow([]mysql.GetAttendancesRow{
+			{
+				AttendanceID:          tpRow.Attendance.ID,
+				Date:                  tpRow.Attendance.Date,
+				UsedStudentTokenQuota: tpRow.Attendance.UsedStudentTokenQuota,
+				Duration:              tpRow.Attendance.Duration,
+				Note:                  tpRow.Attendance.Note,
+				IsPaid:                tpRow.Attendance.IsPaid,
+				IsSubstitute:          tpRow.Attendance.IsSubstitute,
+				OriginalTeacherID:     tpRow.Attendance.OriginalTeacherID,
+				Class:                 tpRow.Class,
+				Course:                tpRow.Course,
+				Instrument:            tpRow.Instrument,
+				Grade:                 tpRow.Grade,
+				TeacherID:             tpRow.TeacherID,
+				TeacherUsername:       tpRow.TeacherUsername,
+				TeacherDetail:         tpRow.TeacherDetail,
+				StudentID:             tpRow.StudentID,
+				StudentUsername:       tpRow.StudentUsername,
+				StudentDetail:         tpRow.StudentDetail,
+				ClassTeacherID:        tpRow.ClassTeacherID,
+				ClassTeacherUsername:  tpRow.ClassTeacherUsername,
+				ClassTeacherDetail:    tpRow.ClassTeacherDetail,
+				// an `Attendance` may have a null `StudentLearningToken`. BUT, it is ensured that `TeacherPayment` will only have `Attendance` with non-null `StudentLearningToken`.
+				// Thus, we can set all the "Valid" below to be true.
+				ID:                       sql.NullInt64{Int64: tpRow.StudentLearningToken.ID, Valid: true},
+				Quota:                    sql.NullFloat64{Float64: tpRow.StudentLearningToken.Quota, Valid: true},
+				CourseFeeQuarterValue:    sql.NullInt32{Int32: tpRow.StudentLearningToken.CourseFeeQuarterValue, Valid: true},
+				TransportFeeQuarterValue: sql.NullInt32{Int32: tpRow.StudentLearningToken.TransportFeeQuarterValue, Valid: true},
+				CreatedAt:                sql.NullTime{Time: tpRow.StudentLearningToken.CreatedAt, Valid: true},
+				LastUpdatedAt:            sql.NullTime{Time: tpRow.StudentLearningToken.LastUpdatedAt, Valid: true},
+				EnrollmentID:             sql.NullInt64{Int64: tpRow.StudentLearningToken.EnrollmentID, Valid: true},
+			},
+		})[0]
+
+		snapshot := entity.PaymentSnapshot{
+			StudentName:      tpRow.SnapshotStudentName,
+			ClassDescription: tpRow.SnapshotClassDescription,
+			CourseName:       tpRow.SnapshotCourseName,
+			InstrumentName:   tpRow.SnapshotInstrumentName,
+			GradeName:        tpRow.SnapshotGradeName,
+			TeacherName:      tpRow.SnapshotTeacherName,
+		}
+		// fall back to the snapshot for any of the attendance's student, teacher & class which is no longer available.
+		// Unlike EnrollmentPayment, the snapshot's teacher is the attendance's teacher, not the class' teacher.
+		snapshotInfo := newStudentEnrollmentFromPaymentSnapshot(snapshot)
+		if attendance.StudentInfo.StudentID == entity.StudentID_None {
+			attendance.StudentInfo = snapshotInfo.StudentInfo
+		}
+		if attendance.TeacherInfo.TeacherID == entity.TeacherID_None && snapshotInfo.ClassInfo.TeacherInfo_Minimal != nil {
+			attendance.TeacherInfo = *snapshotInfo.ClassInfo.TeacherInfo_Minimal
+		}
+		if attendance.ClassInfo.ClassID == entity.ClassID_None {
+			attendance.ClassInfo = snapshotInfo.ClassInfo
+			attendance.ClassInfo.TeacherInfo_Minimal = nil
+		}
+
 		teacherPayments = append(teacherPayments, entity.TeacherPayment{
-			TeacherPaymentID: entity.TeacherPaymentID(tpRow.TeacherPaymentID),
-			Attendance: NewAttendancesFromGetAttendancesRow([]mysql.GetAttendancesRow{
-				{
-					AttendanceID:          tpRow.Attendance.ID,
-					Date:                  tpRow.Attendance.Date,
-					UsedStudentTokenQuota: tpRow.Attendance.UsedStudentTokenQuota,
-					Duration:              tpRow.Attendance.Duration,
-					Note:                  tpRow.Attendance.Note,
-					IsPaid:                tpRow.Attendance.IsPaid,
-					Class:                 tpRow.Class,
-					Course:                tpRow.Course,
-					Instrument:            tpRow.Instrument,
-					Grade:                 tpRow.Grade,
-					TeacherID:             tpRow.TeacherID,
-					TeacherUsername:       tpRow.TeacherUsername,
-					TeacherDetail:         tpRow.TeacherDetail,
-					StudentID:             tpRow.StudentID,
-					StudentUsername:       tpRow.StudentUsername,
-					StudentDetail:         tpRow.StudentDetail,
-					ClassTeacherID:        tpRow.ClassTeacherID,
-					ClassTeacherUsername:  tpRow.ClassTeacherUsername,
-					ClassTeacherDetail:    tpRow.ClassTeacherDetail,
-					// an `Attendance` may have a null `StudentLearningToken`. BUT, it is ensured that `TeacherPayment` will only have `Attendance` with non-null `StudentLearningToken`.
-					// Thus, we can set all the "Valid" below to be true.
-					ID:                       sql.NullInt64{Int64: tpRow.StudentLearningToken.ID, Valid: true},
-					Quota:                    sql.NullFloat64{Float64: tpRow.StudentLearningToken.Quota, Valid: true},
-					CourseFeeQuarterValue:    sql.NullInt32{Int32: tpRow.StudentLearningToken.CourseFeeQuarterValue, Valid: true},
-					TransportFeeQuarterValue: sql.NullInt32{Int32: tpRow.StudentLearningToken.TransportFeeQuarterValue, Valid: true},
-					CreatedAt:                sql.NullTime{Time: tpRow.StudentLearningToken.CreatedAt, Valid: true},
-					LastUpdatedAt:            sql.NullTime{Time: tpRow.StudentLearningToken.LastUpdatedAt, Valid: true},
-					EnrollmentID:             sql.NullInt64{Int64: tpRow.StudentLearningToken.EnrollmentID, Valid: true},
-				},
-			})[0],
+			TeacherPaymentID:       entity.TeacherPaymentID(tpRow.TeacherPaymentID),
+			Attendance:             attendance,
 			PaidCourseFeeValue:     tpRow.PaidCourseFeeValue,
 			PaidTransportFeeValue:  tpRow.PaidTransportFeeValue,
 			AddedAt:                tpRow.AddedAt,
+			Snapshot:               snapshot,
 			GrossCourseFeeValue:    int32(float64(tpRow.StudentLearningToken.CourseFeeQuarterValue) * tpRow.Attendance.UsedStudentTokenQuota),
 			GrossTransportFeeValue: int32(float64(tpRow.StudentLearningToken.TransportFeeQuarterValue) * tpRow.Attendance.UsedStudentTokenQuota),
 		})
@@ -488,3 +725,139 @@ func NewTeacherPaymentsFromGetTeacherPaymentsRow(teacherPaymentRows []mysql.GetT
 
 	return teacherPayments
 }
+
+func NewCourseFeeHistoriesFromCourseFeeHistoryRows(courseFeeHistoryRows []mysql.CourseFeeHistory) []entity.CourseFeeHistory {
+	courseFeeHistories := make([]entity.CourseFeeHistory, 0, len(courseFeeHistoryRows))
+	for _, courseFeeHistoryRow := range courseFeeHistoryRows {
+		courseFeeHistories = append(courseFeeHistories, entity.CourseFeeHistory{
+			CourseFeeHistoryID: entity.CourseFeeHistoryID(courseFeeHistoryRow.ID),
+			CourseID:           entity.CourseID(courseFeeHistoryRow.CourseID),
+			Fee:                courseFeeHistoryRow.Fee,
+			EffectiveFrom:      courseFeeHistoryRow.EffectiveFrom,
+		})
+	}
+
+	return courseFeeHistories
+}
+
+func NewTeacherSpecialFeeHistoriesFromTeacherSpecialFeeHistoryRows(teacherSpecialFeeHistoryRows []mysql.TeacherSpecialFeeHistory) []entity.TeacherSpecialFeeHistory {
+	teacherSpecialFeeHistories := make([]entity.TeacherSpecialFeeHistory, 0, len(teacherSpecialFeeHistoryRows))
+	for _, teacherSpecialFeeHistoryRow := range teacherSpecialFeeHistoryRows {
+		teacherSpecialFeeHistories = append(teacherSpecialFeeHistories, entity.TeacherSpecialFeeHistory{
+			TeacherSpecialFeeHistoryID: entity.TeacherSpecialFeeHistoryID(teacherSpecialFeeHistoryRow.ID),
+			TeacherID:                  entity.TeacherID(teacherSpecialFeeHistoryRow.TeacherID),
+			CourseID:                   entity.CourseID(teacherSpecialFeeHistoryRow.CourseID),
+			Fee:                        teacherSpecialFeeHistoryRow.Fee,
+			EffectiveFrom:              teacherSpecialFeeHistoryRow.EffectiveFrom,
+		})
+	}
+
+	return teacherSpecialFeeHistories
+}
+
+func NewClosedPeriodsFromGetClosedPeriodsRow(closedPeriodRows []mysql.GetClosedPeriodsRow) []entity.ClosedPeriod {
+	closedPeriods := make([]entity.ClosedPeriod, 0, len(closedPeriodRows))
+	for _, closedPeriodRow := range closedPeriodRows {
+		closedPeriods = append(closedPeriods, entity.ClosedPeriod{
+			ClosedPeriodID:    entity.ClosedPeriodID(closedPeriodRow.ID),
+			ClosedUntil:       closedPeriodRow.ClosedUntil,
+			Note:              closedPeriodRow.Note,
+			CreatedAt:         closedPeriodRow.CreatedAt,
+			CreatedByUserID:   identity.UserID(closedPeriodRow.CreatedByUserID.Int64),
+			CreatedByUsername: closedPeriodRow.CreatedByUsername.String,
+		})
+	}
+
+	return closedPeriods
+}
+
+func NewClosedPeriodsFromGetClosedPeriodByIdRow(closedPeriodRows []mysql.GetClosedPeriodByIdRow) []entity.ClosedPeriod {
+	// `GetClosedPeriodByIdRow` shares the same struct as `GetClosedPeriodsRow`.
+	temp := make([]mysql.GetClosedPeriodsRow, 0, len(closedPeriodRows))
+	for _, closedPeriodRow := range closedPeriodRows {
+		temp = append(temp, mysql.GetClosedPeriodsRow(closedPeriodRow))
+	}
+
+	return NewClosedPeriodsFromGetClosedPeriodsRow(temp)
+}
+
+func NewClosedPeriodOverridesFromGetClosedPeriodOverridesRow(closedPeriodOverrideRows []mysql.GetClosedPeriodOverridesRow) []entity.ClosedPeriodOverride {
+	closedPeriodOverrides := make([]entity.ClosedPeriodOverride, 0, len(closedPeriodOverrideRows))
+	for _, closedPeriodOverrideRow := range closedPeriodOverrideRows {
+		closedPeriodOverrides = append(closedPeriodOverrides, entity.ClosedPeriodOverride{
+			ClosedPeriodOverrideID: entity.ClosedPeriodOverrideID(closedPeriodOverrideRow.ID),
+			Operation:              closedPeriodOverrideRow.Operation,
+			Reason:                 closedPeriodOverrideRow.Reason,
+			ClosedUntil:            closedPeriodOverrideRow.ClosedUntil,
+			EarliestRecordDate:     closedPeriodOverrideRow.EarliestRecordDate,
+			CreatedAt:              closedPeriodOverrideRow.CreatedAt,
+			UserID:                 identity.UserID(closedPeriodOverrideRow.UserID.Int64),
+			Username:               closedPeriodOverrideRow.Username.String,
+		})
+	}
+
+	return closedPeriodOverrides
+}
+
+func NewCashUpDayFromGetCashUpDayByDateRow(cashUpDayRow mysql.GetCashUpDayByDateRow) entity.CashUpDay {
+	return entity.CashUpDay{
+		CashUpDayID:       entity.CashUpDayID(cashUpDayRow.ID),
+		Date:              cashUpDayRow.Date,
+		CountedCashValue:  cashUpDayRow.CountedCashValue,
+		RecordedCashValue: cashUpDayRow.RecordedCashValue,
+		DifferenceValue:   cashUpDayRow.DifferenceValue,
+		Note:              cashUpDayRow.Note,
+		ClosedAt:          cashUpDayRow.ClosedAt,
+		ClosedByUserID:    identity.UserID(cashUpDayRow.ClosedByUserID.Int64),
+		ClosedByUsername:  cashUpDayRow.ClosedByUsername.String,
+	}
+}
+
+func NewStudentEnrollmentHoldsFromGetStudentEnrollmentHoldsByStudentEnrollmentIdsRow(holdRows []mysql.GetStudentEnrollmentHoldsByStudentEnrollmentIdsRow) []entity.StudentEnrollmentHold {
+	holds := make([]entity.StudentEnrollmentHold, 0, len(holdRows))
+	for _, holdRow := range holdRows {
+		holds = append(holds, entity.StudentEnrollmentHold{
+			StudentEnrollmentHoldID: entity.StudentEnrollmentHoldID(holdRow.ID),
+			StudentEnrollmentID:     entity.StudentEnrollmentID(holdRow.EnrollmentID),
+			StudentID:               entity.StudentID(holdRow.StudentID),
+			StartDate:               holdRow.StartDate,
+			EndDate:                 holdRow.EndDate,
+			Reason:                  holdRow.Reason,
+		})
+	}
+
+	return holds
+}
+
+// NewStudentEnrollmentHistories pairs each StudentEnrollment with its periods. periodRows must be sorted by start_date.
+func NewStudentEnrollmentHistories(studentEnrollments []entity.StudentEnrollment, periodRows []mysql.StudentEnrollmentPeriod) []entity.StudentEnrollmentHistory {
+	studentEnrollmentIdToPeriods := make(map[entity.StudentEnrollmentID][]entity.StudentEnrollmentPeriod, len(studentEnrollments))
+	for _, periodRow := range periodRows {
+		period := entity.StudentEnrollmentPeriod{
+			StudentEnrollmentPeriodID: entity.StudentEnrollmentPeriodID(periodRow.ID),
+			StartDate:                 periodRow.StartDate,
+			WithdrawalReason:          periodRow.WithdrawalReason,
+		}
+		if periodRow.EndDate.Valid {
+			endDate := periodRow.EndDate.Time
+			period.EndDate = &endDate
+		}
+		studentEnrollmentID := entity.StudentEnrollmentID(periodRow.EnrollmentID)
+		studentEnrollmentIdToPeriods[studentEnrollmentID] = append(studentEnrollmentIdToPeriods[studentEnrollmentID], period)
+	}
+
+	histories := make([]entity.StudentEnrollmentHistory, 0, len(studentEnrollments))
+	for _, studentEnrollment := range studentEnrollments {
+		periods := studentEnrollmentIdToPeriods[studentEnrollment.StudentEnrollmentID]
+		if periods == nil {
+			periods = make([]entity.StudentEnrollmentPeriod, 0)
+		}
+		histories = append(histories, entity.StudentEnrollmentHistory{
+			StudentEnrollment: studentEnrollment,
+			IsActive:          len(periods) > 0 && periods[len(periods)-1].EndDate == nil,
+			Periods:           periods,
+		})
+	}
+
+	return histories
+}
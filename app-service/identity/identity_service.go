@@ -45,6 +45,7 @@ type UserDetail struct {
 	TwitterAccount    string     `json:"twitterAccount,omitempty"`
 	ParentName        string     `json:"parentName,omitempty"`
 	ParentPhoneNumber string     `json:"parentPhoneNumber,omitempty"`
+	ParentEmail       string     `json:"parentEmail,omitempty"`
 }
 
 func (u UserDetail) String() string {
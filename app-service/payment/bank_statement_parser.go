@@ -0,0 +1,331 @@
+package payment
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"sonamusica-backend/app-service/util"
+	"sonamusica-backend/errs"
+)
+
+type BankFormat string
+
+const (
+	BankFormat_BCA     BankFormat = "BCA"
+	BankFormat_Mandiri BankFormat = "MANDIRI"
+	// BankFormat_Generic is for banks without a dedicated parser. The CSV must have the header: "date,description,reference,amount",
+	// with "date" in "2006-01-02" or "02/01/2006" format, and negative "amount" for debits.
+	BankFormat_Generic BankFormat = "GENERIC"
+)
+
+// BankStatementTransaction is a parsed transaction (a row) of a bank statement file.
+type BankStatementTransaction struct {
+	TransactionDate time.Time
+	Description     string
+	Reference       string
+	// Amount is positive for credits (incoming money), and negative for debits
+	Amount int64
+}
+
+// BankStatementParser parses a bank statement (mutation) file, which is exported from a bank's internet banking.
+//
+// A new bank format is supported by implementing this interface, then registering it via RegisterBankStatementParser().
+type BankStatementParser interface {
+	Parse(content []byte) ([]BankStatementTransaction, error)
+}
+
+var bankStatementParsers = map[BankFormat]BankStatementParser{
+	BankFormat_BCA:     bcaParser{},
+	BankFormat_Mandiri: mandiriParser{},
+	BankFormat_Generic: genericParser{},
+}
+
+// RegisterBankStatementParser adds (or replaces) the parser of a bank format. It is expected to be called on initialization, as it is not goroutine-safe.
+func RegisterBankStatementParser(bankFormat BankFormat, parser BankStatementParser) {
+	bankStatementParsers[bankFormat] = parser
+}
+
+// GetBankStatementParser returns errs.ErrUnsupportedBankFormat when there's no parser for bankFormat.
+func GetBankStatementParser(bankFormat BankFormat) (BankStatementParser, error) {
+	parser, ok := bankStatementParsers[bankFormat]
+	if !ok {
+		return nil, fmt.Errorf("bankFormat='%s': %w", bankFormat, errs.ErrUnsupportedBankFormat)
+	}
+	return parser, nil
+}
+
+// bcaParser parses KlikBCA's "Mutasi Rekening" CSV, whose transactions are listed under the header:
+// "Tanggal Transaksi,Keterangan,Cabang,Jumlah,,Saldo", with "Jumlah" followed by "CR"/"DB" (either in the next column, or in the same column).
+//
+// The transaction date only has the day & month ("dd/mm"), thus the year is taken from the "Periode" row above the header ("dd/mm/yyyy - dd/mm/yyyy").
+// Pending transactions (dated "PEND") are skipped.
+type bcaParser struct{}
+
+func (p bcaParser) Parse(content []byte) ([]BankStatementTransaction, error) {
+	records, err := readCSVRecords(content)
+	if err != nil {
+		return nil, fmt.Errorf("readCSVRecords(): %w", err)
+	}
+
+	periodEnd := time.Time{}
+	headerIdx, columns := -1, map[string]int{}
+	for i, record := range records {
+		if len(record) >= 2 && strings.HasPrefix(normalizeCSVCell(record[0]), "periode") {
+			periodEnd, err = parseBCAPeriodEnd(record[1])
+			if err != nil {
+				return nil, fmt.Errorf("parseBCAPeriodEnd(): %w", err)
+			}
+		}
+		if columns = findCSVColumns(record, "tanggal transaksi", "keterangan", "jumlah"); columns != nil {
+			headerIdx = i
+			break
+		}
+	}
+	if headerIdx < 0 {
+		return nil, fmt.Errorf("header row is not found: %w", errs.ErrInvalidBankStatementFile)
+	}
+	if periodEnd.IsZero() {
+		return nil, fmt.Errorf("'Periode' row is not found: %w", errs.ErrInvalidBankStatementFile)
+	}
+
+	transactions := make([]BankStatementTransaction, 0)
+	for _, record := range records[headerIdx+1:] {
+		dateStr := strings.Trim(getCSVCell(record, columns["tanggal transaksi"]), "' ")
+		if strings.EqualFold(dateStr, "PEND") {
+			continue
+		}
+		date, err := time.ParseInLocation("02/01", dateStr, util.DefaultTimezone)
+		if err != nil { // footer rows (e.g. "Saldo Awal") are not transactions
+			continue
+		}
+		// a statement period may span over a new year, e.g. "15/12/2023 - 15/01/2024"
+		date = date.AddDate(periodEnd.Year(), 0, 0)
+		if date.After(periodEnd) {
+			date = date.AddDate(-1, 0, 0)
+		}
+
+		amountStr := getCSVCell(record, columns["jumlah"])
+		creditDebit := strings.ToUpper(strings.TrimSpace(getCSVCell(record, columns["jumlah"]+1)))
+		if fields := strings.Fields(amountStr); len(fields) == 2 {
+			amountStr, creditDebit = fields[0], strings.ToUpper(fields[1])
+		}
+		amount, err := parseAmount(amountStr)
+		if err != nil {
+			return nil, fmt.Errorf("parseAmount(): %w", err)
+		}
+		if creditDebit == "DB" {
+			amount = -amount
+		}
+
+		description := strings.Join(strings.Fields(getCSVCell(record, columns["keterangan"])), " ")
+		transactions = append(transactions, BankStatementTransaction{
+			TransactionDate: date,
+			Description:     description,
+			Reference:       description, // BCA doesn't have a separate reference column, the transfer reference is a part of the description
+			Amount:          amount,
+		})
+	}
+
+	return transactions, nil
+}
+
+func parseBCAPeriodEnd(period string) (time.Time, error) {
+	dates := strings.Split(period, "-")
+	periodEnd, err := time.ParseInLocation("02/01/2006", strings.Trim(dates[len(dates)-1], "' "), util.DefaultTimezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid period='%s': %w", period, errs.ErrInvalidBankStatementFile)
+	}
+	return periodEnd, nil
+}
+
+// mandiriParser parses Mandiri's (MCM / Livin') account statement CSV, whose transactions are listed under the header:
+// "Account No,Date,Val. Date,Transaction Code,Description,Description,Reference No.,Debit,Credit", with "Date" in "dd/mm/yy" format.
+type mandiriParser struct{}
+
+func (p mandiriParser) Parse(content []byte) ([]BankStatementTransaction, error) {
+	records, err := readCSVRecords(content)
+	if err != nil {
+		return nil, fmt.Errorf("readCSVRecords(): %w", err)
+	}
+
+	headerIdx, columns := -1, map[string]int{}
+	for i, record := range records {
+		if columns = findCSVColumns(record, "date", "description", "reference no.", "debit", "credit"); columns != nil {
+			headerIdx = i
+			break
+		}
+	}
+	if headerIdx < 0 {
+		return nil, fmt.Errorf("header row is not found: %w", errs.ErrInvalidBankStatementFile)
+	}
+
+	// Mandiri splits the description into 2 columns with the same name
+	descriptionColumns := make([]int, 0, 2)
+	for i, cell := range records[headerIdx] {
+		if normalizeCSVCell(cell) == "description" {
+			descriptionColumns = append(descriptionColumns, i)
+		}
+	}
+
+	transactions := make([]BankStatementTransaction, 0)
+	for _, record := range records[headerIdx+1:] {
+		date, err := parseDate(getCSVCell(record, columns["date"]), "02/01/06", "02/01/2006")
+		if err != nil { // footer rows (e.g. totals) are not transactions
+			continue
+		}
+
+		credit, err := parseAmount(getCSVCell(record, columns["credit"]))
+		if err != nil {
+			return nil, fmt.Errorf("parseAmount(credit): %w", err)
+		}
+		debit, err := parseAmount(getCSVCell(record, columns["debit"]))
+		if err != nil {
+			return nil, fmt.Errorf("parseAmount(debit): %w", err)
+		}
+
+		descriptions := make([]string, 0, len(descriptionColumns))
+		for _, idx := range descriptionColumns {
+			if description := strings.TrimSpace(getCSVCell(record, idx)); description != "" {
+				descriptions = append(descriptions, description)
+			}
+		}
+		transactions = append(transactions, BankStatementTransaction{
+			TransactionDate: date,
+			Description:     strings.Join(descriptions, " "),
+			Reference:       strings.TrimSpace(getCSVCell(record, columns["reference no."])),
+			Amount:          credit - debit,
+		})
+	}
+
+	return transactions, nil
+}
+
+// genericParser parses the CSV described in BankFormat_Generic.
+type genericParser struct{}
+
+func (p genericParser) Parse(content []byte) ([]BankStatementTransaction, error) {
+	records, err := readCSVRecords(content)
+	if err != nil {
+		return nil, fmt.Errorf("readCSVRecords(): %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("file is empty: %w", errs.ErrInvalidBankStatementFile)
+	}
+
+	columns := findCSVColumns(records[0], "date", "description", "reference", "amount")
+	if columns == nil {
+		return nil, fmt.Errorf("header row must be 'date,description,reference,amount': %w", errs.ErrInvalidBankStatementFile)
+	}
+
+	transactions := make([]BankStatementTransaction, 0, len(records)-1)
+	for i, record := range records[1:] {
+		date, err := parseDate(getCSVCell(record, columns["date"]), "2006-01-02", "02/01/2006")
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+2, err)
+		}
+		amount, err := parseAmount(getCSVCell(record, columns["amount"]))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+2, err)
+		}
+
+		transactions = append(transactions, BankStatementTransaction{
+			TransactionDate: date,
+			Description:     strings.TrimSpace(getCSVCell(record, columns["description"])),
+			Reference:       strings.TrimSpace(getCSVCell(record, columns["reference"])),
+			Amount:          amount,
+		})
+	}
+
+	return transactions, nil
+}
+
+func readCSVRecords(content []byte) ([][]string, error) {
+	// some banks export the file with a UTF-8 BOM
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1 // the rows above & below the transactions (account info, totals, etc.) have different number of columns
+	reader.LazyQuotes = true
+
+	records := make([][]string, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reader.Read(): %v: %w", err, errs.ErrInvalidBankStatementFile)
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// findCSVColumns returns the indexes of the columns (by their normalized names) when record contains all of them, i.e. record is the header row. Else, returns nil.
+func findCSVColumns(record []string, columnNames ...string) map[string]int {
+	columns := make(map[string]int, len(columnNames))
+	for i, cell := range record {
+		name := normalizeCSVCell(cell)
+		if _, ok := columns[name]; !ok { // keep the first one on duplicated column names
+			columns[name] = i
+		}
+	}
+
+	result := make(map[string]int, len(columnNames))
+	for _, columnName := range columnNames {
+		idx, ok := columns[columnName]
+		if !ok {
+			return nil
+		}
+		result[columnName] = idx
+	}
+	return result
+}
+
+func normalizeCSVCell(cell string) string {
+	return strings.ToLower(strings.TrimSpace(cell))
+}
+
+func getCSVCell(record []string, idx int) string {
+	if idx < 0 || idx >= len(record) {
+		return ""
+	}
+	return record[idx]
+}
+
+func parseDate(value string, layouts ...string) (time.Time, error) {
+	value = strings.Trim(value, "' ")
+	for _, layout := range layouts {
+		if date, err := time.ParseInLocation(layout, value, util.DefaultTimezone); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date='%s': %w", value, errs.ErrInvalidBankStatementFile)
+}
+
+// parseAmount parses amounts in either "1,500,000.00" or "1.500.000,00" format into a whole rupiah value. An empty value is parsed as 0.
+func parseAmount(value string) (int64, error) {
+	cleaned := strings.NewReplacer(" ", "", "'", "", "Rp", "", "IDR", "").Replace(strings.TrimSpace(value))
+	if cleaned == "" {
+		return 0, nil
+	}
+
+	// a separator followed by exactly 2 digits at the end is the decimal separator
+	integerPart := cleaned
+	if n := len(cleaned); n > 3 && (cleaned[n-3] == '.' || cleaned[n-3] == ',') {
+		integerPart = cleaned[:n-3]
+	}
+	integerPart = strings.NewReplacer(",", "", ".", "").Replace(integerPart)
+
+	amount, err := strconv.ParseInt(integerPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount='%s': %w", value, errs.ErrInvalidBankStatementFile)
+	}
+	return amount, nil
+}
@@ -1 +1,477 @@
 package impl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"sonamusica-backend/accessor/relational_db"
+	"sonamusica-backend/accessor/relational_db/mysql"
+	"sonamusica-backend/app-service/entity"
+	"sonamusica-backend/app-service/identity"
+	"sonamusica-backend/app-service/payment"
+	"sonamusica-backend/app-service/teaching"
+	"sonamusica-backend/app-service/util"
+	"sonamusica-backend/config"
+	"sonamusica-backend/errs"
+	"sonamusica-backend/logging"
+	"sonamusica-backend/network"
+)
+
+var (
+	configObject = config.Get()
+	mainLog      = logging.NewGoLogger("PaymentService", logging.GetLevel(configObject.LogLevel))
+)
+
+type paymentServiceImpl struct {
+	mySQLQueries *relational_db.MySQLQueries
+
+	entityService   entity.EntityService
+	teachingService teaching.TeachingService
+}
+
+var _ payment.PaymentService = (*paymentServiceImpl)(nil)
+
+func NewPaymentServiceImpl(mySQLQueries *relational_db.MySQLQueries, entityService entity.EntityService, teachingService teaching.TeachingService) *paymentServiceImpl {
+	return &paymentServiceImpl{
+		mySQLQueries:    mySQLQueries,
+		entityService:   entityService,
+		teachingService: teachingService,
+	}
+}
+
+func (s paymentServiceImpl) ImportBankStatement(ctx context.Context, spec payment.ImportBankStatementSpec) (payment.BankStatementImportID, error) {
+	parser, err := payment.GetBankStatementParser(spec.BankFormat)
+	if err != nil {
+		return payment.BankStatementImportID_None, fmt.Errorf("GetBankStatementParser(): %w", err)
+	}
+	transactions, err := parser.Parse(spec.Content)
+	if err != nil {
+		return payment.BankStatementImportID_None, fmt.Errorf("parser.Parse(): %w", err)
+	}
+
+	// debits (outgoing money) can never be enrollment payments
+	credits := make([]payment.BankStatementTransaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		if transaction.Amount > 0 {
+			credits = append(credits, transaction)
+		}
+	}
+
+	authInfo := network.GetAuthInfo(ctx)
+
+	var importID int64
+	err = s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		importID, err = qtx.InsertBankStatementImport(newCtx, mysql.InsertBankStatementImportParams{
+			BankFormat:       string(spec.BankFormat),
+			FileName:         spec.FileName,
+			ImportedByUserID: sql.NullInt64{Int64: int64(authInfo.UserID), Valid: authInfo.UserID != identity.UserID_None},
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.InsertBankStatementImport(): %w", err)
+		}
+
+		if len(credits) == 0 {
+			return nil
+		}
+
+		candidates, err := s.getEnrollmentPaymentsForMatching(newCtx, qtx, credits)
+		if err != nil {
+			return fmt.Errorf("getEnrollmentPaymentsForMatching(): %w", err)
+		}
+
+		for _, credit := range credits {
+			status := payment.BankStatementLineStatus_Unmatched
+			enrollmentPaymentID := sql.NullInt64{}
+
+			if idx := findBestMatchingEnrollmentPayment(credit, candidates); idx >= 0 {
+				status = payment.BankStatementLineStatus_AutoMatched
+				enrollmentPaymentID = sql.NullInt64{Int64: candidates[idx].ID, Valid: true}
+				// an enrollmentPayment can only be matched to a single line
+				candidates = append(candidates[:idx], candidates[idx+1:]...)
+			}
+
+			_, err := qtx.InsertBankStatementLine(newCtx, mysql.InsertBankStatementLineParams{
+				BankStatementImportID: importID,
+				TransactionDate:       credit.TransactionDate,
+				Description:           truncateString(credit.Description, 255),
+				Reference:             truncateString(credit.Reference, 128),
+				Amount:                credit.Amount,
+				Status:                string(status),
+				EnrollmentPaymentID:   enrollmentPaymentID,
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.InsertBankStatementLine(): %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return payment.BankStatementImportID_None, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return payment.BankStatementImportID(importID), nil
+}
+
+// getEnrollmentPaymentsForMatching returns the unmatched enrollmentPayments which are dated within the credits' dates (extended by BankStatementMatching_DayWindow days).
+func (s paymentServiceImpl) getEnrollmentPaymentsForMatching(ctx context.Context, qtx *mysql.Queries, credits []payment.BankStatementTransaction) ([]mysql.GetEnrollmentPaymentsForBankStatementMatchingRow, error) {
+	startDate, endDate := credits[0].TransactionDate, credits[0].TransactionDate
+	for _, credit := range credits {
+		if credit.TransactionDate.Before(startDate) {
+			startDate = credit.TransactionDate
+		}
+		if credit.TransactionDate.After(endDate) {
+			endDate = credit.TransactionDate
+		}
+	}
+
+	candidates, err := qtx.GetEnrollmentPaymentsForBankStatementMatching(ctx, mysql.GetEnrollmentPaymentsForBankStatementMatchingParams{
+		StartDate: startDate.AddDate(0, 0, -payment.BankStatementMatching_DayWindow),
+		EndDate:   endDate.AddDate(0, 0, payment.BankStatementMatching_DayWindow+1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("qtx.GetEnrollmentPaymentsForBankStatementMatching(): %w", err)
+	}
+
+	return candidates, nil
+}
+
+// findBestMatchingEnrollmentPayment returns the index of the candidate having the same value as the credit, within BankStatementMatching_DayWindow days.
+// Candidates whose ReferenceNumber appears in the credit's reference or description are preferred, then the closest PaymentDate.
+//
+// Returns -1 when there's no matching candidate.
+func findBestMatchingEnrollmentPayment(credit payment.BankStatementTransaction, candidates []mysql.GetEnrollmentPaymentsForBankStatementMatchingRow) int {
+	window := time.Duration(payment.BankStatementMatching_DayWindow) * 24 * time.Hour
+
+	bestIdx := -1
+	bestHasReference := false
+	var bestDistance time.Duration
+	for i, candidate := range candidates {
+		if candidate.TotalValue != credit.Amount {
+			continue
+		}
+		distance := absDuration(credit.TransactionDate.Sub(candidate.PaymentDate))
+		if distance > window {
+			continue
+		}
+
+		hasReference := candidate.ReferenceNumber != "" &&
+			(strings.Contains(strings.ToUpper(credit.Reference), strings.ToUpper(candidate.ReferenceNumber)) ||
+				strings.Contains(strings.ToUpper(credit.Description), strings.ToUpper(candidate.ReferenceNumber)))
+
+		isBetter := bestIdx < 0 ||
+			(hasReference && !bestHasReference) ||
+			(hasReference == bestHasReference && distance < bestDistance)
+		if isBetter {
+			bestIdx, bestHasReference, bestDistance = i, hasReference, distance
+		}
+	}
+
+	return bestIdx
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func (s paymentServiceImpl) GetBankStatementImports(ctx context.Context, pagination util.PaginationSpec) (payment.GetBankStatementImportsResult, error) {
+	pagination.SetDefaultOnInvalidValues()
+	limit, offset := pagination.GetLimitAndOffset()
+
+	var importRows = make([]mysql.GetBankStatementImportsRow, 0)
+	var totalResults int64 = 0
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		importRows, err = qtx.GetBankStatementImports(newCtx, mysql.GetBankStatementImportsParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetBankStatementImports(): %w", err)
+		}
+
+		totalResults, err = qtx.CountBankStatementImports(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.CountBankStatementImports(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return payment.GetBankStatementImportsResult{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return payment.GetBankStatementImportsResult{
+		BankStatementImports: NewBankStatementImportsFromGetBankStatementImportsRow(importRows),
+		PaginationResult:     *util.NewPaginationResult(int(totalResults), pagination.ResultsPerPage, pagination.Page),
+	}, nil
+}
+
+func (s paymentServiceImpl) GetBankStatementImportById(ctx context.Context, id payment.BankStatementImportID) (payment.GetBankStatementImportByIdResult, error) {
+	var importRow mysql.GetBankStatementImportByIdRow
+	var lineRows = make([]mysql.BankStatementLine, 0)
+	var enrollmentRows = make([]mysql.GetStudentEnrollmentsForBankStatementSuggestionRow, 0)
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		importRow, err = qtx.GetBankStatementImportById(newCtx, int64(id))
+		if err != nil {
+			return fmt.Errorf("qtx.GetBankStatementImportById(): %w", err)
+		}
+
+		lineRows, err = qtx.GetBankStatementLinesByImportId(newCtx, int64(id))
+		if err != nil {
+			return fmt.Errorf("qtx.GetBankStatementLinesByImportId(): %w", err)
+		}
+
+		if importRow.TotalUnmatchedLines > 0 {
+			enrollmentRows, err = qtx.GetStudentEnrollmentsForBankStatementSuggestion(newCtx)
+			if err != nil {
+				return fmt.Errorf("qtx.GetStudentEnrollmentsForBankStatementSuggestion(): %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return payment.GetBankStatementImportByIdResult{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	enrollmentPaymentIDs := make([]entity.EnrollmentPaymentID, 0, len(lineRows))
+	for _, lineRow := range lineRows {
+		if lineRow.EnrollmentPaymentID.Valid {
+			enrollmentPaymentIDs = append(enrollmentPaymentIDs, entity.EnrollmentPaymentID(lineRow.EnrollmentPaymentID.Int64))
+		}
+	}
+	enrollmentPayments := make([]entity.EnrollmentPayment, 0)
+	if len(enrollmentPaymentIDs) > 0 {
+		enrollmentPayments, err = s.entityService.GetEnrollmentPaymentsByIds(ctx, enrollmentPaymentIDs)
+		if err != nil {
+			return payment.GetBankStatementImportByIdResult{}, fmt.Errorf("entityService.GetEnrollmentPaymentsByIds(): %w", err)
+		}
+	}
+
+	lines := NewBankStatementLinesFromBankStatementLineRows(lineRows, enrollmentPayments)
+	suggestionCandidates := NewBankStatementSuggestionsFromGetStudentEnrollmentsForBankStatementSuggestionRow(enrollmentRows)
+	for i, line := range lines {
+		if line.Status == payment.BankStatementLineStatus_Unmatched {
+			lines[i].SuggestedStudentEnrollments = suggestStudentEnrollments(line, suggestionCandidates)
+		}
+	}
+
+	return payment.GetBankStatementImportByIdResult{
+		BankStatementImport: NewBankStatementImportsFromGetBankStatementImportByIdRow([]mysql.GetBankStatementImportByIdRow{importRow})[0],
+		BankStatementLines:  lines,
+	}, nil
+}
+
+// suggestStudentEnrollments returns up to BankStatementSuggestion_MaxCount candidates which may have paid the line, ordered by the number of match reasons.
+func suggestStudentEnrollments(line payment.BankStatementLine, candidates []payment.BankStatementSuggestion) []payment.BankStatementSuggestion {
+	descriptionWords := toNameWords(line.Description + " " + line.Reference)
+
+	suggestions := make([]payment.BankStatementSuggestion, 0)
+	for _, candidate := range candidates {
+		reasons := make([]payment.BankStatementMatchReason, 0)
+
+		userDetail := candidate.StudentInfo.UserInfo_Minimal.UserDetail
+		if containsName(descriptionWords, userDetail.FirstName+" "+userDetail.LastName) {
+			reasons = append(reasons, payment.BankStatementMatchReason_StudentName)
+		}
+		if containsName(descriptionWords, userDetail.ParentName) {
+			reasons = append(reasons, payment.BankStatementMatchReason_ParentName)
+		}
+		if candidate.LastPaymentValue == line.Amount {
+			reasons = append(reasons, payment.BankStatementMatchReason_LastPaymentAmount)
+		}
+
+		if len(reasons) > 0 {
+			candidate.MatchReasons = reasons
+			suggestions = append(suggestions, candidate)
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return len(suggestions[i].MatchReasons) > len(suggestions[j].MatchReasons)
+	})
+	if len(suggestions) > payment.BankStatementSuggestion_MaxCount {
+		suggestions = suggestions[:payment.BankStatementSuggestion_MaxCount]
+	}
+
+	return suggestions
+}
+
+// toNameWords splits s into uppercased words, ignoring non-letter characters (e.g. "TRSF E-BANKING CR 0301/FTSCY/WS95031 JOHN DOE" -> [TRSF, E, BANKING, CR, FTSCY, WS, JOHN, DOE]).
+func toNameWords(s string) map[string]bool {
+	words := strings.FieldsFunc(strings.ToUpper(s), func(r rune) bool {
+		return r < 'A' || r > 'Z'
+	})
+
+	result := make(map[string]bool, len(words))
+	for _, word := range words {
+		result[word] = true
+	}
+	return result
+}
+
+// containsName returns true when all words of name (ignoring initials, e.g. "J.") exist in words.
+func containsName(words map[string]bool, name string) bool {
+	matchedWords := 0
+	for word := range toNameWords(name) {
+		if len(word) < 2 {
+			continue
+		}
+		if !words[word] {
+			return false
+		}
+		matchedWords++
+	}
+	return matchedWords > 0
+}
+
+func (s paymentServiceImpl) ConfirmBankStatementLineMatch(ctx context.Context, spec payment.ConfirmBankStatementLineMatchSpec) error {
+	authInfo := network.GetAuthInfo(ctx)
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		line, err := qtx.GetBankStatementLineByIdForUpdate(newCtx, int64(spec.BankStatementLineID))
+		if err != nil {
+			return fmt.Errorf("qtx.GetBankStatementLineByIdForUpdate(): %w", err)
+		}
+
+		enrollmentPaymentID := line.EnrollmentPaymentID
+		if spec.EnrollmentPaymentID != entity.EnrollmentPaymentID_None {
+			enrollmentPaymentID = sql.NullInt64{Int64: int64(spec.EnrollmentPaymentID), Valid: true}
+		}
+		if !enrollmentPaymentID.Valid {
+			return fmt.Errorf("enrollmentPaymentId is required for an unmatched line: %w", errs.ErrBankStatementLineNotMatched)
+		}
+		if line.Status == string(payment.BankStatementLineStatus_Confirmed) {
+			return errs.ErrBankStatementLineAlreadyMatched
+		}
+
+		// validate that the enrollmentPayment exists, and that it's the one paid by the line (both the auto-matched & the manually chosen one)
+		enrollmentPayment, err := qtx.GetEnrollmentPaymentById(newCtx, enrollmentPaymentID.Int64)
+		if err != nil {
+			return fmt.Errorf("qtx.GetEnrollmentPaymentById(): %w", err)
+		}
+		receivedValue := int64(enrollmentPayment.CourseFeeValue) + int64(enrollmentPayment.TransportFeeValue) + int64(enrollmentPayment.PenaltyFeeValue) - int64(enrollmentPayment.DiscountFeeValue)
+		if receivedValue != line.Amount {
+			return fmt.Errorf("receivedValue='%d', amount='%d': %w", receivedValue, line.Amount, errs.ErrBankStatementAmountMismatch)
+		}
+
+		err = qtx.UpdateBankStatementLineMatch(newCtx, mysql.UpdateBankStatementLineMatchParams{
+			Status:              string(payment.BankStatementLineStatus_Confirmed),
+			EnrollmentPaymentID: enrollmentPaymentID,
+			ConfirmedAt:         sql.NullTime{Time: time.Now().UTC(), Valid: true},
+			ConfirmedByUserID:   sql.NullInt64{Int64: int64(authInfo.UserID), Valid: authInfo.UserID != identity.UserID_None},
+			ID:                  line.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.UpdateBankStatementLineMatch(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
+
+func (s paymentServiceImpl) UnmatchBankStatementLine(ctx context.Context, id payment.BankStatementLineID) error {
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		line, err := qtx.GetBankStatementLineByIdForUpdate(newCtx, int64(id))
+		if err != nil {
+			return fmt.Errorf("qtx.GetBankStatementLineByIdForUpdate(): %w", err)
+		}
+		if !line.EnrollmentPaymentID.Valid {
+			return errs.ErrBankStatementLineNotMatched
+		}
+
+		err = qtx.UpdateBankStatementLineMatch(newCtx, mysql.UpdateBankStatementLineMatchParams{
+			Status:              string(payment.BankStatementLineStatus_Unmatched),
+			EnrollmentPaymentID: sql.NullInt64{},
+			ConfirmedAt:         sql.NullTime{},
+			ConfirmedByUserID:   sql.NullInt64{},
+			ID:                  line.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.UpdateBankStatementLineMatch(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
+
+func (s paymentServiceImpl) CreateEnrollmentPaymentFromBankStatementLine(ctx context.Context, spec payment.CreateEnrollmentPaymentFromBankStatementLineSpec) (entity.EnrollmentPaymentID, error) {
+	authInfo := network.GetAuthInfo(ctx)
+
+	receivedValue := int64(spec.CourseFeeValue) + int64(spec.TransportFeeValue) + int64(spec.PenaltyFeeValue) - int64(spec.DiscountFeeValue)
+
+	enrollmentPaymentID := entity.EnrollmentPaymentID_None
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		line, err := qtx.GetBankStatementLineByIdForUpdate(newCtx, int64(spec.BankStatementLineID))
+		if err != nil {
+			return fmt.Errorf("qtx.GetBankStatementLineByIdForUpdate(): %w", err)
+		}
+		if line.EnrollmentPaymentID.Valid {
+			return errs.ErrBankStatementLineAlreadyMatched
+		}
+		if receivedValue != line.Amount {
+			return fmt.Errorf("receivedValue='%d', amount='%d': %w", receivedValue, line.Amount, errs.ErrBankStatementAmountMismatch)
+		}
+
+		// the nested ExecuteInTransaction() of SubmitEnrollmentPayment() reuses this transaction
+		enrollmentPaymentID, err = s.teachingService.SubmitEnrollmentPayment(newCtx, teaching.SubmitStudentEnrollmentPaymentSpec{
+			StudentEnrollmentID: spec.StudentEnrollmentID,
+			PaymentDate:         line.TransactionDate,
+			BalanceTopUp:        spec.BalanceTopUp,
+			BalanceBonus:        spec.BalanceBonus,
+			CourseFeeValue:      spec.CourseFeeValue,
+			TransportFeeValue:   spec.TransportFeeValue,
+			PenaltyFeeValue:     spec.PenaltyFeeValue,
+			DiscountFeeValue:    spec.DiscountFeeValue,
+			PaymentMethod:       entity.PaymentMethod_BankTransfer,
+			ReceivingAccount:    spec.ReceivingAccount,
+			ReferenceNumber:     truncateString(line.Reference, 64),
+		})
+		if err != nil {
+			return fmt.Errorf("teachingService.SubmitEnrollmentPayment(): %w", err)
+		}
+
+		err = qtx.UpdateBankStatementLineMatch(newCtx, mysql.UpdateBankStatementLineMatchParams{
+			Status:              string(payment.BankStatementLineStatus_Confirmed),
+			EnrollmentPaymentID: sql.NullInt64{Int64: int64(enrollmentPaymentID), Valid: true},
+			ConfirmedAt:         sql.NullTime{Time: time.Now().UTC(), Valid: true},
+			ConfirmedByUserID:   sql.NullInt64{Int64: int64(authInfo.UserID), Valid: authInfo.UserID != identity.UserID_None},
+			ID:                  line.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.UpdateBankStatementLineMatch(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.EnrollmentPaymentID_None, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return enrollmentPaymentID, nil
+}
+
+// truncateString cuts s to at most maxLength characters, as bank statement texts may exceed the database columns' length.
+//
+// s is cut by runes (not bytes), as the columns' length counts characters, and a multi-byte character must not be split.
+func truncateString(s string, maxLength int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLength {
+		return s
+	}
+	return string(runes[:maxLength])
+}
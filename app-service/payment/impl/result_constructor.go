@@ -0,0 +1,97 @@
+package impl
+
+import (
+	"time"
+
+	"sonamusica-backend/accessor/relational_db/mysql"
+	"sonamusica-backend/app-service/entity"
+	"sonamusica-backend/app-service/identity"
+	"sonamusica-backend/app-service/payment"
+)
+
+func NewBankStatementImportsFromGetBankStatementImportsRow(importRows []mysql.GetBankStatementImportsRow) []payment.BankStatementImport {
+	imports := make([]payment.BankStatementImport, 0, len(importRows))
+	for _, importRow := range importRows {
+		imports = append(imports, payment.BankStatementImport{
+			BankStatementImportID: payment.BankStatementImportID(importRow.ID),
+			BankFormat:            payment.BankFormat(importRow.BankFormat),
+			FileName:              importRow.FileName,
+			TotalLines:            importRow.TotalLines,
+			TotalUnmatchedLines:   importRow.TotalUnmatchedLines,
+			ImportedAt:            importRow.ImportedAt,
+			ImportedByUserID:      identity.UserID(importRow.ImportedByUserID.Int64),
+			ImportedByUsername:    importRow.ImportedByUsername.String,
+		})
+	}
+
+	return imports
+}
+
+func NewBankStatementImportsFromGetBankStatementImportByIdRow(importRows []mysql.GetBankStatementImportByIdRow) []payment.BankStatementImport {
+	// `GetBankStatementImportByIdRow` shares the same struct as `GetBankStatementImportsRow`.
+	temp := make([]mysql.GetBankStatementImportsRow, 0, len(importRows))
+	for _, importRow := range importRows {
+		temp = append(temp, mysql.GetBankStatementImportsRow(importRow))
+	}
+
+	return NewBankStatementImportsFromGetBankStatementImportsRow(temp)
+}
+
+// NewBankStatementLinesFromBankStatementLineRows requires enrollmentPayments to contain the lines' matched EnrollmentPayments.
+func NewBankStatementLinesFromBankStatementLineRows(lineRows []mysql.BankStatementLine, enrollmentPayments []entity.EnrollmentPayment) []payment.BankStatementLine {
+	enrollmentPaymentById := make(map[entity.EnrollmentPaymentID]entity.EnrollmentPayment, len(enrollmentPayments))
+	for _, enrollmentPayment := range enrollmentPayments {
+		enrollmentPaymentById[enrollmentPayment.EnrollmentPaymentID] = enrollmentPayment
+	}
+
+	lines := make([]payment.BankStatementLine, 0, len(lineRows))
+	for _, lineRow := range lineRows {
+		var enrollmentPayment *entity.EnrollmentPayment
+		if lineRow.EnrollmentPaymentID.Valid {
+			if ep, ok := enrollmentPaymentById[entity.EnrollmentPaymentID(lineRow.EnrollmentPaymentID.Int64)]; ok {
+				enrollmentPayment = &ep
+			}
+		}
+
+		lines = append(lines, payment.BankStatementLine{
+			BankStatementLineID:   payment.BankStatementLineID(lineRow.ID),
+			BankStatementImportID: payment.BankStatementImportID(lineRow.BankStatementImportID),
+			TransactionDate:       lineRow.TransactionDate,
+			Description:           lineRow.Description,
+			Reference:             lineRow.Reference,
+			Amount:                lineRow.Amount,
+			Status:                payment.BankStatementLineStatus(lineRow.Status),
+			EnrollmentPayment:     enrollmentPayment,
+			ConfirmedAt:           lineRow.ConfirmedAt.Time,
+			ConfirmedByUserID:     identity.UserID(lineRow.ConfirmedByUserID.Int64),
+		})
+	}
+
+	return lines
+}
+
+func NewBankStatementSuggestionsFromGetStudentEnrollmentsForBankStatementSuggestionRow(enrollmentRows []mysql.GetStudentEnrollmentsForBankStatementSuggestionRow) []payment.BankStatementSuggestion {
+	suggestions := make([]payment.BankStatementSuggestion, 0, len(enrollmentRows))
+	for _, enrollmentRow := range enrollmentRows {
+		var lastPaymentDate *time.Time
+		if enrollmentRow.LastPaymentDate.Valid {
+			lastPaymentDate = &enrollmentRow.LastPaymentDate.Time
+		}
+
+		suggestions = append(suggestions, payment.BankStatementSuggestion{
+			StudentEnrollmentID: entity.StudentEnrollmentID(enrollmentRow.StudentEnrollmentID),
+			StudentInfo: entity.StudentInfo_Minimal{
+				StudentID: entity.StudentID(enrollmentRow.StudentID),
+				UserInfo_Minimal: identity.UserInfo_Minimal{
+					Username:   enrollmentRow.StudentUsername,
+					UserDetail: identity.UnmarshalUserDetail(enrollmentRow.StudentDetail, mainLog),
+				},
+			},
+			ClassID:          entity.ClassID(enrollmentRow.ClassID),
+			LastPaymentDate:  lastPaymentDate,
+			LastPaymentValue: enrollmentRow.LastPaymentValue,
+		})
+	}
+
+	return suggestions
+}
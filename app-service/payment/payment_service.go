@@ -1,4 +1,142 @@
 package payment
 
+import (
+	"context"
+	"time"
+
+	"sonamusica-backend/app-service/entity"
+	"sonamusica-backend/app-service/identity"
+	"sonamusica-backend/app-service/util"
+)
+
+// BankStatementMatching_DayWindow is the max number of days between a BankStatementLine's TransactionDate & an EnrollmentPayment's PaymentDate to be auto-matched.
+const BankStatementMatching_DayWindow = 3
+
+// BankStatementSuggestion_MaxCount is the max number of suggested StudentEnrollments for each unmatched BankStatementLine.
+const BankStatementSuggestion_MaxCount = 5
+
+type BankStatementImportID int64
+type BankStatementLineID int64
+
+const (
+	BankStatementImportID_None BankStatementImportID = iota
+)
+const (
+	BankStatementLineID_None BankStatementLineID = iota
+)
+
+type BankStatementLineStatus string
+
+const (
+	BankStatementLineStatus_Unmatched   BankStatementLineStatus = "UNMATCHED"
+	BankStatementLineStatus_AutoMatched BankStatementLineStatus = "AUTO_MATCHED"
+	BankStatementLineStatus_Confirmed   BankStatementLineStatus = "CONFIRMED"
+)
+
+type BankStatementMatchReason string
+
+const (
+	BankStatementMatchReason_StudentName       BankStatementMatchReason = "STUDENT_NAME"
+	BankStatementMatchReason_ParentName        BankStatementMatchReason = "PARENT_NAME"
+	BankStatementMatchReason_LastPaymentAmount BankStatementMatchReason = "LAST_PAYMENT_AMOUNT"
+)
+
+// BankStatementImport is an uploaded bank statement file. Only the credit (incoming money) transactions are imported, as BankStatementLines.
+type BankStatementImport struct {
+	BankStatementImportID BankStatementImportID `json:"bankStatementImportId"`
+	BankFormat            BankFormat            `json:"bankFormat"`
+	FileName              string                `json:"fileName"`
+	TotalLines            int64                 `json:"totalLines"`
+	TotalUnmatchedLines   int64                 `json:"totalUnmatchedLines"`
+
+	ImportedAt         time.Time       `json:"importedAt"`
+	ImportedByUserID   identity.UserID `json:"importedByUserId,omitempty"`
+	ImportedByUsername string          `json:"importedByUsername,omitempty"`
+}
+
+// BankStatementLine is a credit transaction of a BankStatementImport, whose Status goes through: "UNMATCHED" -> "AUTO_MATCHED" (on import) -> "CONFIRMED".
+//
+// A line can also be confirmed directly from "UNMATCHED", by choosing the EnrollmentPayment manually, or by creating a new EnrollmentPayment from the line.
+type BankStatementLine struct {
+	BankStatementLineID   BankStatementLineID     `json:"bankStatementLineId"`
+	BankStatementImportID BankStatementImportID   `json:"bankStatementImportId"`
+	TransactionDate       time.Time               `json:"transactionDate"`
+	Description           string                  `json:"description"`
+	Reference             string                  `json:"reference"`
+	Amount                int64                   `json:"amount"`
+	Status                BankStatementLineStatus `json:"status"`
+	// EnrollmentPayment is nil when the line is "UNMATCHED"
+	EnrollmentPayment *entity.EnrollmentPayment `json:"enrollmentPayment,omitempty"`
+
+	ConfirmedAt       time.Time       `json:"confirmedAt,omitempty"`
+	ConfirmedByUserID identity.UserID `json:"confirmedByUserId,omitempty"`
+
+	// SuggestedStudentEnrollments is only populated for "UNMATCHED" lines, ordered by the most relevant first.
+	SuggestedStudentEnrollments []BankStatementSuggestion `json:"suggestedStudentEnrollments,omitempty"`
+}
+
+// BankStatementSuggestion is a StudentEnrollment which may have paid an unmatched BankStatementLine, e.g. when the student's name appears in the transfer description.
+type BankStatementSuggestion struct {
+	StudentEnrollmentID entity.StudentEnrollmentID `json:"studentEnrollmentId"`
+	StudentInfo         entity.StudentInfo_Minimal `json:"student"`
+	ClassID             entity.ClassID             `json:"classId"`
+	LastPaymentDate     *time.Time                 `json:"lastPaymentDate,omitempty"`
+	LastPaymentValue    int64                      `json:"lastPaymentValue"`
+	MatchReasons        []BankStatementMatchReason `json:"matchReasons"`
+}
+
 type PaymentService interface {
+	// ImportBankStatement parses a bank statement file, then inserts its credit transactions as BankStatementLines.
+	// Each line is auto-matched to an unmatched non-cash EnrollmentPayment having the same received value, within BankStatementMatching_DayWindow days.
+	// When multiple EnrollmentPayments qualify, the one whose ReferenceNumber appears in the line is preferred, then the closest PaymentDate.
+	//
+	// Returns errs.ErrUnsupportedBankFormat or errs.ErrInvalidBankStatementFile when the file cannot be parsed.
+	ImportBankStatement(ctx context.Context, spec ImportBankStatementSpec) (BankStatementImportID, error)
+	GetBankStatementImports(ctx context.Context, pagination util.PaginationSpec) (GetBankStatementImportsResult, error)
+	// GetBankStatementImportById returns the import along with its lines, including the suggested StudentEnrollments for the unmatched lines.
+	GetBankStatementImportById(ctx context.Context, id BankStatementImportID) (GetBankStatementImportByIdResult, error)
+
+	// ConfirmBankStatementLineMatch confirms the line's (auto-)matched EnrollmentPayment, or matches it to spec.EnrollmentPaymentID when set.
+	// The EnrollmentPayment's received value (course + transport + penalty - discount) must equal the line's amount, else returns errs.ErrBankStatementAmountMismatch.
+	ConfirmBankStatementLineMatch(ctx context.Context, spec ConfirmBankStatementLineMatchSpec) error
+	// UnmatchBankStatementLine moves the line back to "UNMATCHED". Returns errs.ErrBankStatementLineNotMatched when the line is already unmatched.
+	UnmatchBankStatementLine(ctx context.Context, id BankStatementLineID) error
+	// CreateEnrollmentPaymentFromBankStatementLine submits a new EnrollmentPayment (via teaching.TeachingService's SubmitEnrollmentPayment) for an unmatched line, then confirms the line's match to it.
+	// The EnrollmentPayment's received value (course + transport + penalty - discount) must equal the line's amount, else returns errs.ErrBankStatementAmountMismatch.
+	CreateEnrollmentPaymentFromBankStatementLine(ctx context.Context, spec CreateEnrollmentPaymentFromBankStatementLineSpec) (entity.EnrollmentPaymentID, error)
+}
+
+type ImportBankStatementSpec struct {
+	BankFormat BankFormat
+	FileName   string
+	Content    []byte
+}
+
+type GetBankStatementImportsResult struct {
+	BankStatementImports []BankStatementImport
+	PaginationResult     util.PaginationResult
+}
+
+type GetBankStatementImportByIdResult struct {
+	BankStatementImport BankStatementImport
+	BankStatementLines  []BankStatementLine
+}
+
+type ConfirmBankStatementLineMatchSpec struct {
+	BankStatementLineID BankStatementLineID
+	EnrollmentPaymentID entity.EnrollmentPaymentID // optional
+}
+
+type CreateEnrollmentPaymentFromBankStatementLineSpec struct {
+	BankStatementLineID BankStatementLineID
+	StudentEnrollmentID entity.StudentEnrollmentID
+
+	BalanceTopUp      int32
+	BalanceBonus      int32
+	CourseFeeValue    int32
+	TransportFeeValue int32
+	PenaltyFeeValue   int32
+	DiscountFeeValue  int32
+
+	ReceivingAccount string
 }
@@ -0,0 +1,79 @@
+package pdf_composer
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"sync"
+	"time"
+
+	"sonamusica-backend/config"
+	"sonamusica-backend/logging"
+)
+
+var (
+	configObject = config.Get()
+	mainLog      = logging.NewGoLogger("PDFComposer", logging.GetLevel(configObject.LogLevel))
+)
+
+const (
+	logoFetchTimeout    = 10 * time.Second
+	logoRefetchInterval = 1 * time.Hour
+)
+
+var (
+	logoMutex             sync.Mutex
+	cachedLogo            image.Image
+	lastFailedLogoFetchAt time.Time
+)
+
+// getLogo downloads & decodes the image (PNG or JPEG) at config's LogoURL, and caches it for the subsequent documents.
+//
+// Documents must still be renderable when the logo is unavailable, so a failed download only returns nil.
+// The download is retried only after logoRefetchInterval, so that an unreachable LogoURL doesn't slow down every document rendering.
+func getLogo() image.Image {
+	if configObject.LogoURL == "" {
+		return nil
+	}
+
+	logoMutex.Lock()
+	defer logoMutex.Unlock()
+	if cachedLogo != nil {
+		return cachedLogo
+	}
+	if !lastFailedLogoFetchAt.IsZero() && time.Since(lastFailedLogoFetchAt) < logoRefetchInterval {
+		return nil
+	}
+
+	logo, err := fetchImage(configObject.LogoURL)
+	if err != nil {
+		lastFailedLogoFetchAt = time.Now()
+		mainLog.Warn("Unable to fetch the logo, rendering the documents without logo for the next %s: %v", logoRefetchInterval, err)
+		return nil
+	}
+	cachedLogo = logo
+
+	return cachedLogo
+}
+
+func fetchImage(url string) (image.Image, error) {
+	client := http.Client{Timeout: logoFetchTimeout}
+	res, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("client.Get(): %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code='%d'", res.StatusCode)
+	}
+
+	img, _, err := image.Decode(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("image.Decode(): %w", err)
+	}
+
+	return img, nil
+}
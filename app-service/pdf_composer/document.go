@@ -0,0 +1,210 @@
+package pdf_composer
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"strings"
+)
+
+// A4 page size, in PDF points (1/72 inch)
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type Font int
+
+const (
+	Font_Regular Font = iota
+	Font_Bold
+)
+
+// Document is a minimal PDF writer, which only supports what our documents (e.g. receipts) need: texts in Helvetica, lines, and images.
+// We write the PDF ourselves instead of using a 3rd party library, as the required features are very limited.
+//
+// All coordinates are in PDF points, with the origin at the top-left corner of the page (unlike PDF's bottom-left), to ease the layouting.
+type Document struct {
+	pageContents []*bytes.Buffer
+	images       []image.Image
+}
+
+func NewDocument() *Document {
+	document := &Document{}
+	document.AddPage()
+	return document
+}
+
+func (d *Document) AddPage() {
+	d.pageContents = append(d.pageContents, &bytes.Buffer{})
+}
+
+func (d *Document) currentPage() *bytes.Buffer {
+	return d.pageContents[len(d.pageContents)-1]
+}
+
+// Text draws text with its baseline starting at (x, y).
+func (d *Document) Text(x float64, y float64, font Font, size float64, text string) {
+	fmt.Fprintf(d.currentPage(), "BT /F%d %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font+1, size, x, PageHeight-y, escapeText(text))
+}
+
+// TextRight draws text with its baseline ending at (xRight, y), i.e. right-aligned text.
+func (d *Document) TextRight(xRight float64, y float64, font Font, size float64, text string) {
+	d.Text(xRight-TextWidth(font, size, text), y, font, size, text)
+}
+
+// Line draws a black line from (x1, y1) to (x2, y2).
+func (d *Document) Line(x1 float64, y1 float64, x2 float64, y2 float64, width float64) {
+	fmt.Fprintf(d.currentPage(), "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// Image draws img with its top-left corner at (x, y), scaled into width x height.
+func (d *Document) Image(img image.Image, x float64, y float64, width float64, height float64) {
+	d.images = append(d.images, img)
+	fmt.Fprintf(d.currentPage(), "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n", width, height, x, PageHeight-y-height, len(d.images))
+}
+
+// Bytes serializes the document into a PDF file.
+func (d *Document) Bytes() ([]byte, error) {
+	// object numbers: 1=catalog, 2=pages, 3..4=fonts, then images, then (page, content) pairs
+	const firstImageObjectNumber = 5
+	firstPageObjectNumber := firstImageObjectNumber + len(d.images)
+
+	objects := make([][]byte, 0, firstPageObjectNumber-1+2*len(d.pageContents))
+	objects = append(objects, []byte("<< /Type /Catalog /Pages 2 0 R >>"))
+
+	pageRefs := make([]string, 0, len(d.pageContents))
+	for i := range d.pageContents {
+		pageRefs = append(pageRefs, fmt.Sprintf("%d 0 R", firstPageObjectNumber+2*i))
+	}
+	objects = append(objects, []byte(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(pageRefs, " "), len(d.pageContents))))
+
+	objects = append(objects, []byte("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"))
+	objects = append(objects, []byte("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>"))
+
+	xObjects := make([]string, 0, len(d.images))
+	for i, img := range d.images {
+		imageObject, err := encodeImageObject(img)
+		if err != nil {
+			return nil, fmt.Errorf("encodeImageObject(): %w", err)
+		}
+		objects = append(objects, imageObject)
+		xObjects = append(xObjects, fmt.Sprintf("/Im%d %d 0 R", i+1, firstImageObjectNumber+i))
+	}
+	resources := "<< /Font << /F1 3 0 R /F2 4 0 R >> >>"
+	if len(xObjects) > 0 {
+		resources = fmt.Sprintf("<< /Font << /F1 3 0 R /F2 4 0 R >> /XObject << %s >> >>", strings.Join(xObjects, " "))
+	}
+
+	for i, content := range d.pageContents {
+		objects = append(objects, []byte(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources %s /Contents %d 0 R >>", PageWidth, PageHeight, resources, firstPageObjectNumber+2*i+1)))
+		objects = append(objects, streamObject("", content.Bytes()))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, 0, len(objects))
+	for i, object := range objects {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", i+1)
+		buf.Write(object)
+		buf.WriteString("\nendobj\n")
+	}
+
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset)
+
+	return buf.Bytes(), nil
+}
+
+func streamObject(dictEntries string, data []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<< %s/Length %d >>\nstream\n", dictEntries, len(data))
+	buf.Write(data)
+	buf.WriteString("\nendstream")
+	return buf.Bytes()
+}
+
+// encodeImageObject converts img into a compressed RGB image XObject. Transparent pixels are blended into a white background, as the PDF has no alpha channel here.
+func encodeImageObject(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	rgb := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// RGBA() returns alpha-premultiplied 16-bit values
+			r, g, b, a := img.At(x, y).RGBA()
+			white := 0xffff - a
+			rgb = append(rgb, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+	}
+
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	if _, err := writer.Write(rgb); err != nil {
+		return nil, fmt.Errorf("zlib.Writer.Write(): %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("zlib.Writer.Close(): %w", err)
+	}
+
+	dictEntries := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode ", bounds.Dx(), bounds.Dy())
+	return streamObject(dictEntries, compressed.Bytes()), nil
+}
+
+// escapeText escapes PDF string delimiters, and replaces non-printable-ASCII characters with '?', as we only use the standard fonts without embedding them.
+func escapeText(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r < ' ' || r > '~':
+			sb.WriteByte('?')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// TextWidth returns the width of text in PDF points, based on the standard Helvetica font metrics.
+func TextWidth(font Font, size float64, text string) float64 {
+	widths := helveticaWidths
+	if font == Font_Bold {
+		widths = helveticaBoldWidths
+	}
+
+	total := 0
+	for _, r := range text {
+		if r < ' ' || r > '~' {
+			r = '?'
+		}
+		total += widths[r-' ']
+	}
+	return float64(total) * size / 1000
+}
+
+// helveticaWidths & helveticaBoldWidths are the glyph widths (per 1000 units of font size) of the printable ASCII characters, i.e. ' ' until '~'.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
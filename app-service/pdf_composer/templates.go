@@ -0,0 +1,308 @@
+package pdf_composer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sonamusica-backend/app-service/entity"
+	"sonamusica-backend/app-service/teaching"
+	"sonamusica-backend/app-service/util"
+)
+
+const (
+	marginLeft  = 50.0
+	marginRight = PageWidth - 50.0
+	valueLeft   = 160.0
+
+	logoMaxWidth  = 120.0
+	logoMaxHeight = 48.0
+)
+
+type EnrollmentPaymentReceipt struct {
+	Receipt     teaching.EnrollmentPaymentReceipt
+	CompanyName string
+}
+
+func NewEnrollmentPaymentReceipt(receipt teaching.EnrollmentPaymentReceipt) *EnrollmentPaymentReceipt {
+	return &EnrollmentPaymentReceipt{
+		Receipt:     receipt,
+		CompanyName: configObject.Email_CompanyName,
+	}
+}
+
+func (r *EnrollmentPaymentReceipt) ReceiptNumber() string {
+	return fmt.Sprintf("%06d", r.Receipt.ReceiptNumber)
+}
+
+func (r *EnrollmentPaymentReceipt) FileName() string {
+	return fmt.Sprintf("receipt-%s.pdf", r.ReceiptNumber())
+}
+
+func (r *EnrollmentPaymentReceipt) PDF() ([]byte, error) {
+	ep := r.Receipt.EnrollmentPayment
+	classInfo := ep.StudentEnrollmentInfo.ClassInfo
+
+	document := NewDocument()
+	y := drawHeader(document, r.CompanyName, "PAYMENT RECEIPT")
+
+	teacherName := "-"
+	if classInfo.TeacherInfo_Minimal != nil {
+		teacherName = classInfo.TeacherInfo_Minimal.UserInfo_Minimal.UserDetail.String()
+	}
+	infos := [][2]string{
+		{"Receipt No.", r.ReceiptNumber()},
+		{"Payment Date", formatDate(ep.PaymentDate)},
+		{"Issued Date", formatDate(r.Receipt.IssuedAt)},
+		{"Student", ep.StudentEnrollmentInfo.StudentInfo.String()},
+		{"Class", fmt.Sprintf("#%d (Teacher: %s)", classInfo.ClassID, teacherName)},
+		{"Course", fmt.Sprintf("%s - %s", classInfo.Course.Instrument.Name, classInfo.Course.Grade.Name)},
+		{"Payment Method", formatPaymentMethod(ep)},
+	}
+	for _, info := range infos {
+		document.Text(marginLeft, y, Font_Bold, 10, info[0])
+		document.Text(valueLeft, y, Font_Regular, 10, info[1])
+		y += 18
+	}
+
+	y += 12
+	document.Text(marginLeft, y, Font_Bold, 10, "Description")
+	document.TextRight(marginRight, y, Font_Bold, 10, "Amount")
+	y += 8
+	document.Line(marginLeft, y, marginRight, y, 0.5)
+	y += 18
+
+	// the paid amount excludes the discount, while balance top-up & bonus only affect the learning token quota
+	fees := [][2]string{
+		{fmt.Sprintf("Course Fee (%d lesson(s))", ep.BalanceTopUp), util.FormatRupiah(int64(ep.CourseFeeValue))},
+		{"Transport Fee", util.FormatRupiah(int64(ep.TransportFeeValue))},
+		{"Penalty Fee", util.FormatRupiah(int64(ep.PenaltyFeeValue))},
+		{"Discount", util.FormatRupiah(-int64(ep.DiscountFeeValue))},
+	}
+	for _, fee := range fees {
+		document.Text(marginLeft, y, Font_Regular, 10, fee[0])
+		document.TextRight(marginRight, y, Font_Regular, 10, fee[1])
+		y += 18
+	}
+
+	y -= 8
+	document.Line(marginLeft, y, marginRight, y, 0.5)
+	y += 18
+	total := ep.CourseFeeValue + ep.TransportFeeValue + ep.PenaltyFeeValue - ep.DiscountFeeValue
+	document.Text(marginLeft, y, Font_Bold, 11, "Total")
+	document.TextRight(marginRight, y, Font_Bold, 11, util.FormatRupiah(int64(total)))
+	y += 36
+
+	document.Text(marginLeft, y, Font_Bold, 10, "Balance Top-up")
+	document.Text(valueLeft, y, Font_Regular, 10, fmt.Sprintf("%d lesson(s)", ep.BalanceTopUp))
+	y += 18
+	document.Text(marginLeft, y, Font_Bold, 10, "Balance Bonus")
+	document.Text(valueLeft, y, Font_Regular, 10, fmt.Sprintf("%d lesson(s)", ep.BalanceBonus))
+
+	document.Text(marginLeft, PageHeight-50, Font_Regular, 8, fmt.Sprintf("This receipt is issued by %s, and is valid without signature.", r.CompanyName))
+
+	return document.Bytes()
+}
+
+// drawHeader draws the company logo & name on the left side, and the document title on the right side. It returns the y position below the header.
+func drawHeader(document *Document, companyName string, title string) float64 {
+	y := 50.0
+	textLeft := marginLeft
+	if logo := getLogo(); logo != nil {
+		bounds := logo.Bounds()
+		width, height := logoMaxHeight*float64(bounds.Dx())/float64(bounds.Dy()), logoMaxHeight
+		if width > logoMaxWidth {
+			width, height = logoMaxWidth, logoMaxWidth*float64(bounds.Dy())/float64(bounds.Dx())
+		}
+		document.Image(logo, marginLeft, y, width, height)
+		textLeft += width + 12
+	}
+
+	document.Text(textLeft, y+logoMaxHeight/2+6, Font_Bold, 16, companyName)
+	document.TextRight(marginRight, y+logoMaxHeight/2+6, Font_Bold, 14, title)
+
+	y += logoMaxHeight + 16
+	document.Line(marginLeft, y, marginRight, y, 1)
+
+	return y + 30
+}
+
+// formatPaymentMethod returns the payment method, followed by the receiving account & reference number (if any), e.g. "BANK_TRANSFER (BCA 123456, Ref: TRX001)".
+func formatPaymentMethod(ep entity.EnrollmentPayment) string {
+	details := make([]string, 0, 2)
+	if ep.ReceivingAccount != "" {
+		details = append(details, ep.ReceivingAccount)
+	}
+	if ep.ReferenceNumber != "" {
+		details = append(details, "Ref: "+ep.ReferenceNumber)
+	}
+
+	if len(details) == 0 {
+		return string(ep.PaymentMethod)
+	}
+	return fmt.Sprintf("%s (%s)", ep.PaymentMethod, strings.Join(details, ", "))
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("02 Jan 2006")
+}
+
+type TeacherPayslip struct {
+	Payslip     teaching.TeacherPayslip
+	CompanyName string
+}
+
+func NewTeacherPayslip(payslip teaching.TeacherPayslip) *TeacherPayslip {
+	return &TeacherPayslip{
+		Payslip:     payslip,
+		CompanyName: configObject.Email_CompanyName,
+	}
+}
+
+func (r *TeacherPayslip) FileName() string {
+	return fmt.Sprintf("payslip-%s-%s.pdf", r.Payslip.Teacher.User.Username, r.Payslip.StartDatetime.Format("2006-01"))
+}
+
+// payslipColumn is a column of the payslip table. Numeric columns are right-aligned, thus x is their right edge.
+type payslipColumn struct {
+	title        string
+	x            float64
+	isRightAlign bool
+}
+
+var payslipColumns = []payslipColumn{
+	{"Date", marginLeft, false},
+	{"Student", 105, false},
+	{"Quota", 230, true},
+	{"Gross Course", 295, true},
+	{"Gross Transp.", 355, true},
+	{"Course %", 390, true},
+	{"Transp. %", 425, true},
+	{"Paid Course", 485, true},
+	{"Paid Transp.", marginRight, true},
+}
+
+const (
+	payslipFontSize    = 7.5
+	payslipLineHeight  = 13.0
+	payslipBottomLimit = PageHeight - 70
+)
+
+func (r *TeacherPayslip) PDF() ([]byte, error) {
+	payslip := r.Payslip
+
+	document := NewDocument()
+	y := drawHeader(document, r.CompanyName, "TEACHER PAYSLIP")
+
+	infos := [][2]string{
+		{"Teacher", payslip.Teacher.User.UserDetail.String()},
+		{"Period", fmt.Sprintf("%s - %s", formatDate(payslip.StartDatetime), formatDate(payslip.EndDatetime))},
+	}
+	for _, info := range infos {
+		document.Text(marginLeft, y, Font_Bold, 10, info[0])
+		document.Text(valueLeft, y, Font_Regular, 10, info[1])
+		y += 18
+	}
+	y += 10
+
+	// ensureSpace moves to a new page when the next n lines don't fit into the current page
+	ensureSpace := func(n int) {
+		if y+float64(n)*payslipLineHeight > payslipBottomLimit {
+			document.AddPage()
+			y = 60
+		}
+	}
+
+	for _, payslipClass := range payslip.Classes {
+		ensureSpace(4)
+		document.Text(marginLeft, y, Font_Bold, 10, fmt.Sprintf("%s (Class #%d)", payslipClass.ClassInfo_Minimal.String(), payslipClass.ClassID))
+		y += 16
+		y = drawPayslipRow(document, y, Font_Bold, columnTitles())
+		document.Line(marginLeft, y-payslipLineHeight+3, marginRight, y-payslipLineHeight+3, 0.5)
+
+		for _, line := range payslipClass.Lines {
+			ensureSpace(1)
+			y = drawPayslipRow(document, y, Font_Regular, []string{
+				formatDate(line.Date),
+				truncateText(Font_Regular, payslipFontSize, line.StudentInfo.String(), payslipColumns[2].x-payslipColumns[1].x-30),
+				fmt.Sprintf("%.2f", line.UsedStudentTokenQuota),
+				util.FormatRupiah(int64(line.GrossCourseFeeValue)),
+				util.FormatRupiah(int64(line.GrossTransportFeeValue)),
+				fmt.Sprintf("%.0f%%", line.CourseFeeSharingPercentage*100),
+				fmt.Sprintf("%.0f%%", line.TransportFeeSharingPercentage*100),
+				util.FormatRupiah(int64(line.PaidCourseFeeValue)),
+				util.FormatRupiah(int64(line.PaidTransportFeeValue)),
+			})
+		}
+
+		ensureSpace(1)
+		subtotal := payslipClass.Subtotal
+		document.Line(marginLeft, y-payslipLineHeight+3, marginRight, y-payslipLineHeight+3, 0.5)
+		y = drawPayslipRow(document, y, Font_Bold, []string{
+			"Subtotal", "",
+			fmt.Sprintf("%.2f", subtotal.TotalAttendances),
+			util.FormatRupiah(subtotal.TotalGrossCourseFeeValue),
+			util.FormatRupiah(subtotal.TotalGrossTransportFeeValue),
+			"", "",
+			util.FormatRupiah(subtotal.TotalPaidCourseFeeValue),
+			util.FormatRupiah(subtotal.TotalPaidTransportFeeValue),
+		})
+		y += 14
+	}
+
+	ensureSpace(6)
+	grandTotal := payslip.GrandTotal
+	document.Line(marginLeft, y-10, marginRight, y-10, 1)
+	y += 6
+	totals := [][3]string{
+		{"", "Gross", "Paid"},
+		{"Course Fee", util.FormatRupiah(grandTotal.TotalGrossCourseFeeValue), util.FormatRupiah(grandTotal.TotalPaidCourseFeeValue)},
+		{"Transport Fee", util.FormatRupiah(grandTotal.TotalGrossTransportFeeValue), util.FormatRupiah(grandTotal.TotalPaidTransportFeeValue)},
+		{"Grand Total", util.FormatRupiah(grandTotal.TotalGrossCourseFeeValue + grandTotal.TotalGrossTransportFeeValue), util.FormatRupiah(grandTotal.TotalPaidFeeValue)},
+	}
+	for i, total := range totals {
+		font := Font_Regular
+		if i == 0 || i == len(totals)-1 {
+			font = Font_Bold
+		}
+		document.Text(marginLeft, y, Font_Bold, 10, total[0])
+		document.TextRight(425, y, font, 10, total[1])
+		document.TextRight(marginRight, y, font, 10, total[2])
+		y += 16
+	}
+
+	return document.Bytes()
+}
+
+func columnTitles() []string {
+	titles := make([]string, 0, len(payslipColumns))
+	for _, column := range payslipColumns {
+		titles = append(titles, column.title)
+	}
+	return titles
+}
+
+// drawPayslipRow draws a row of the payslip table, and returns the y position of the next row.
+func drawPayslipRow(document *Document, y float64, font Font, values []string) float64 {
+	for i, column := range payslipColumns {
+		if column.isRightAlign {
+			document.TextRight(column.x, y, font, payslipFontSize, values[i])
+		} else {
+			document.Text(column.x, y, font, payslipFontSize, values[i])
+		}
+	}
+	return y + payslipLineHeight
+}
+
+// truncateText cuts text (and appends "...") until it fits into maxWidth.
+func truncateText(font Font, size float64, text string, maxWidth float64) string {
+	if TextWidth(font, size, text) <= maxWidth {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && TextWidth(font, size, string(runes)+"...") > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
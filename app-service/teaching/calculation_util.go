@@ -1,5 +1,16 @@
 package teaching
 
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"sonamusica-backend/app-service/entity"
+	"sonamusica-backend/app-service/util"
+	"sonamusica-backend/errs"
+)
+
 // CalculateSLTFeeQuarterFromEP calculates the fee of a single SLT (StudentLearningToken) quota, which is a quarter of the course price.
 //
 // The submitted values from EP (EnrollmentPayment) (which are fees & balanceTopUp) are sometimes 1/4, 2/4, 3/4, 4/4, or even 5/4.
@@ -20,3 +31,211 @@ func CalculateSLTFeeQuarterFromEP(fee int32, balanceTopUp int32) int32 {
 	}
 	return fee / balanceTopUp
 }
+
+// CalculateConvertedSLTQuota converts an SLT quota into another SLT with different course fee quarter value, while preserving the quota's value.
+// E.g. 2 quota of 100k/quarter is converted into 1 quota of 200k/quarter.
+//
+// The quota is returned as is when any of the fee quarter values is invalid (<= 0). The result is rounded to 3 decimal places, following the SLT quota precision.
+func CalculateConvertedSLTQuota(quota float64, fromCourseFeeQuarterValue int32, toCourseFeeQuarterValue int32) float64 {
+	if fromCourseFeeQuarterValue <= 0 || toCourseFeeQuarterValue <= 0 || fromCourseFeeQuarterValue == toCourseFeeQuarterValue {
+		return quota
+	}
+	return math.Round(quota*float64(fromCourseFeeQuarterValue)/float64(toCourseFeeQuarterValue)*1000) / 1000
+}
+
+// CalculatePenaltyFee calculates the late payment penalty of an enrollment, based on its latest payment date & the applicable PenaltyPolicy.
+//
+// Penalty starts counting after the policy's TriggerDayOfMonth (of the month following lastPaymentDate) plus the policy's GraceDays.
+// A flat fee policy charges FeeValue once, otherwise FeeValue is charged per day late. The result is capped by MaxFeeValue, when it is positive.
+//
+// The days within the enrollment's holds are not counted as late.
+//
+// Returns the penalty fee & the days late. A nil lastPaymentDate means the enrollment has never been paid, which yields no penalty.
+func CalculatePenaltyFee(policy entity.PenaltyPolicy, lastPaymentDate *time.Time, now time.Time, holds []entity.StudentEnrollmentHold) (int32, int32) {
+	if lastPaymentDate == nil {
+		return 0, 0
+	}
+
+	firstDayOfNextMonth := time.Date(lastPaymentDate.Year(), lastPaymentDate.Month(), 1, 0, 0, 0, 0, util.DefaultTimezone).AddDate(0, 1, 0)
+	lastDateBeforePenalty := firstDayOfNextMonth.AddDate(0, 0, int(policy.TriggerDayOfMonth-1+policy.GraceDays))
+
+	daysLate := int32(now.Sub(lastDateBeforePenalty).Hours() / 24)
+	if daysLate > 0 {
+		daysLate -= countHeldDays(holds, lastDateBeforePenalty.AddDate(0, 0, 1), now)
+	}
+	if daysLate <= 0 {
+		return 0, daysLate
+	}
+
+	penaltyFeeValue := policy.FeeValue
+	if !policy.IsFlatFee {
+		penaltyFeeValue = policy.FeeValue * daysLate
+	}
+	if policy.MaxFeeValue > 0 && penaltyFeeValue > policy.MaxFeeValue {
+		penaltyFeeValue = policy.MaxFeeValue
+	}
+
+	return penaltyFeeValue, daysLate
+}
+
+// countHeldDays counts the dates from startDate until endDate (both inclusive, as dates in util.DefaultTimezone) which are within any of the holds.
+//
+// The holds of an enrollment never overlap, thus each date is counted at most once.
+func countHeldDays(holds []entity.StudentEnrollmentHold, startDate time.Time, endDate time.Time) int32 {
+	start, end := util.ToLocalDate(startDate), util.ToLocalDate(endDate)
+
+	var heldDays int32 = 0
+	for _, hold := range holds {
+		overlapStart, overlapEnd := hold.StartDate, hold.EndDate
+		if overlapStart.Before(start) {
+			overlapStart = start
+		}
+		if overlapEnd.After(end) {
+			overlapEnd = end
+		}
+		if !overlapEnd.Before(overlapStart) {
+			heldDays += int32(overlapEnd.Sub(overlapStart).Hours()/24) + 1
+		}
+	}
+
+	return heldDays
+}
+
+// CalculateDiscountValue calculates the discount of a DiscountRule on the given course fee. A percentage discount is rounded down.
+func CalculateDiscountValue(rule entity.DiscountRule, courseFeeValue int32) int32 {
+	if !rule.IsPercentage {
+		return rule.Value
+	}
+	return int32(int64(courseFeeValue) * int64(rule.Value) / 100)
+}
+
+// SelectAppliedDiscounts picks the discounts to be applied on an invoice from the applicable DiscountRules: the highest-valued rule of each type.
+//
+// The discounts are ordered by the highest value first, and their total is capped by courseFeeValue, i.e. the last discounts may be reduced or dropped.
+func SelectAppliedDiscounts(applicableRules []entity.DiscountRule, courseFeeValue int32) []AppliedDiscount {
+	typeToBestDiscount := make(map[entity.DiscountRuleType]AppliedDiscount, 0)
+	for _, rule := range applicableRules {
+		value := CalculateDiscountValue(rule, courseFeeValue)
+		if best, ok := typeToBestDiscount[rule.Type]; ok && best.Value >= value {
+			continue
+		}
+		typeToBestDiscount[rule.Type] = AppliedDiscount{
+			DiscountRuleID: rule.DiscountRuleID,
+			Name:           rule.Name,
+			Type:           rule.Type,
+			Value:          value,
+		}
+	}
+
+	discounts := make([]AppliedDiscount, 0, len(typeToBestDiscount))
+	for _, discount := range typeToBestDiscount {
+		discounts = append(discounts, discount)
+	}
+	sort.SliceStable(discounts, func(i, j int) bool {
+		if discounts[i].Value != discounts[j].Value {
+			return discounts[i].Value > discounts[j].Value
+		}
+		return discounts[i].DiscountRuleID < discounts[j].DiscountRuleID
+	})
+
+	appliedDiscounts := make([]AppliedDiscount, 0, len(discounts))
+	remainingFeeValue := courseFeeValue
+	for _, discount := range discounts {
+		if discount.Value > remainingFeeValue {
+			discount.Value = remainingFeeValue
+		}
+		if discount.Value <= 0 {
+			continue
+		}
+		remainingFeeValue -= discount.Value
+		appliedDiscounts = append(appliedDiscounts, discount)
+	}
+
+	return appliedDiscounts
+}
+
+// CalculateInstallments splits an InstallmentPlan's values into len(dueDates) Installments, numbered from 1.
+//
+// BalanceTopUp is split as evenly as possible (the earlier installments get the remainder). The course & transport fees follow each installment's BalanceTopUp,
+// so that every installment's EnrollmentPayment tops up the same StudentLearningToken (check CalculateSLTFeeQuarterFromEP()).
+// PenaltyFeeValue & DiscountFeeValue are charged on the first installment, while BalanceBonus is only released by the last installment.
+//
+// The plan's BalanceTopUp must be >= len(dueDates), so that each installment releases at least 1 quota.
+// Returns errs.ErrInstallmentFeeNotSplittable when a fee cannot be split without changing an installment's fee quarter (check splitFeeByBalanceTopUps()).
+func CalculateInstallments(plan InstallmentPlan, dueDates []time.Time) ([]Installment, error) {
+	installmentCount := int32(len(dueDates))
+	if installmentCount == 0 {
+		return []Installment{}, nil
+	}
+
+	balanceTopUps := make([]int32, 0, installmentCount)
+	for i := int32(0); i < installmentCount; i++ {
+		balanceTopUp := plan.BalanceTopUp / installmentCount
+		if i < plan.BalanceTopUp%installmentCount {
+			balanceTopUp++
+		}
+		balanceTopUps = append(balanceTopUps, balanceTopUp)
+	}
+	courseFeeValues, ok := splitFeeByBalanceTopUps(plan.CourseFeeValue, plan.BalanceTopUp, balanceTopUps)
+	if !ok {
+		return []Installment{}, fmt.Errorf("courseFeeValue '%d' with balanceTopUps %v: %w", plan.CourseFeeValue, balanceTopUps, errs.ErrInstallmentFeeNotSplittable)
+	}
+	transportFeeValues, ok := splitFeeByBalanceTopUps(plan.TransportFeeValue, plan.BalanceTopUp, balanceTopUps)
+	if !ok {
+		return []Installment{}, fmt.Errorf("transportFeeValue '%d' with balanceTopUps %v: %w", plan.TransportFeeValue, balanceTopUps, errs.ErrInstallmentFeeNotSplittable)
+	}
+
+	installments := make([]Installment, 0, installmentCount)
+	for i, dueDate := range dueDates {
+		installment := Installment{
+			InstallmentPlanID: plan.InstallmentPlanID,
+			InstallmentNumber: int32(i + 1),
+			DueDate:           dueDate,
+			BalanceTopUp:      balanceTopUps[i],
+			CourseFeeValue:    courseFeeValues[i],
+			TransportFeeValue: transportFeeValues[i],
+		}
+		if i == 0 {
+			installment.PenaltyFeeValue = plan.PenaltyFeeValue
+			installment.DiscountFeeValue = plan.DiscountFeeValue
+		}
+		if i == len(dueDates)-1 {
+			installment.BalanceBonus = plan.BalanceBonus
+		}
+		installment.Value = installment.CourseFeeValue + installment.TransportFeeValue + installment.PenaltyFeeValue - installment.DiscountFeeValue
+		installments = append(installments, installment)
+	}
+
+	return installments, nil
+}
+
+// splitFeeByBalanceTopUps splits fee proportionally to balanceTopUps, whose sum is totalBalanceTopUp.
+//
+// Each part gets the fee quarter (of the whole fee) times its balanceTopUp, so that every part tops up the same SLT as the whole fee would.
+// The rounding remainder is spread only without changing each part's fee quarter. Returns false when the remainder cannot be spread completely.
+func splitFeeByBalanceTopUps(fee int32, totalBalanceTopUp int32, balanceTopUps []int32) ([]int32, bool) {
+	feeQuarter := CalculateSLTFeeQuarterFromEP(fee, totalBalanceTopUp)
+
+	fees := make([]int32, 0, len(balanceTopUps))
+	remainder := fee
+	for _, balanceTopUp := range balanceTopUps {
+		fees = append(fees, feeQuarter*balanceTopUp)
+		remainder -= feeQuarter * balanceTopUp
+	}
+	for i, balanceTopUp := range balanceTopUps {
+		// adding less than balanceTopUp keeps the part's fee quarter, as CalculateSLTFeeQuarterFromEP() rounds down
+		extra := balanceTopUp - 1
+		if extra > remainder {
+			extra = remainder
+		}
+		if extra > 0 {
+			fees[i] += extra
+			remainder -= extra
+		}
+	}
+	if remainder > 0 {
+		return []int32{}, false
+	}
+
+	return fees, true
+}
@@ -1,6 +1,8 @@
 package teaching
 
 import (
+	"time"
+
 	"sonamusica-backend/accessor/relational_db/mysql"
 	"sonamusica-backend/app-service/entity"
 	"sonamusica-backend/app-service/identity"
@@ -37,3 +39,259 @@ func NewTeacherForPaymentsFromGetPaidTeachersRow(teacherRows []mysql.GetPaidTeac
 
 	return teachers
 }
+
+func NewSLTTransactionsFromGetSLTTransactionsAfterIdRow(sltTransactionRows []mysql.GetSLTTransactionsAfterIdRow) []entity.SLTTransaction {
+	sltTransactions := make([]entity.SLTTransaction, 0, len(sltTransactionRows))
+	for _, sltTransactionRow := range sltTransactionRows {
+		sltTransactions = append(sltTransactions, entity.SLTTransaction{
+			SLTTransactionID:       entity.SLTTransactionID(sltTransactionRow.SltTransactionID),
+			StudentLearningTokenID: entity.StudentLearningTokenID(sltTransactionRow.TokenID),
+			QuotaChange:            sltTransactionRow.QuotaChange,
+			Reason:                 entity.SLTTransactionReason(sltTransactionRow.Reason),
+			SourceID:               sltTransactionRow.SourceID.Int64,
+			UserID:                 identity.UserID(sltTransactionRow.UserID.Int64),
+			Username:               sltTransactionRow.Username.String,
+			CreatedAt:              sltTransactionRow.CreatedAt,
+		})
+	}
+
+	return sltTransactions
+}
+
+func NewPayrollRunsFromGetPayrollRunsRow(payrollRunRows []mysql.GetPayrollRunsRow) []entity.PayrollRun {
+	payrollRuns := make([]entity.PayrollRun, 0, len(payrollRunRows))
+	for _, payrollRunRow := range payrollRunRows {
+		payrollRuns = append(payrollRuns, entity.PayrollRun{
+			PayrollRunID:       entity.PayrollRunID(payrollRunRow.ID),
+			StartDate:          payrollRunRow.StartDate,
+			EndDate:            payrollRunRow.EndDate,
+			Status:             entity.PayrollRunStatus(payrollRunRow.Status),
+			Note:               payrollRunRow.Note,
+			CreatedAt:          payrollRunRow.CreatedAt,
+			CreatedByUserID:    identity.UserID(payrollRunRow.CreatedByUserID.Int64),
+			CreatedByUsername:  payrollRunRow.CreatedByUsername.String,
+			ApprovedAt:         payrollRunRow.ApprovedAt.Time,
+			ApprovedByUserID:   identity.UserID(payrollRunRow.ApprovedByUserID.Int64),
+			ApprovedByUsername: payrollRunRow.ApprovedByUsername.String,
+			PaidAt:             payrollRunRow.PaidAt.Time,
+		})
+	}
+
+	return payrollRuns
+}
+
+func NewPayrollRunsFromGetPayrollRunByIdRow(payrollRunRows []mysql.GetPayrollRunByIdRow) []entity.PayrollRun {
+	// `GetPayrollRunByIdRow` shares the same struct as `GetPayrollRunsRow`.
+	temp := make([]mysql.GetPayrollRunsRow, 0, len(payrollRunRows))
+	for _, payrollRunRow := range payrollRunRows {
+		temp = append(temp, mysql.GetPayrollRunsRow(payrollRunRow))
+	}
+
+	return NewPayrollRunsFromGetPayrollRunsRow(temp)
+}
+
+func NewPayrollRunTeacherTotalsFromGetPayrollRunTeacherTotalsRow(teacherTotalRows []mysql.GetPayrollRunTeacherTotalsRow) []teaching.PayrollRunTeacherTotal {
+	teacherTotals := make([]teaching.PayrollRunTeacherTotal, 0, len(teacherTotalRows))
+	for _, teacherTotalRow := range teacherTotalRows {
+		teacherTotals = append(teacherTotals, teaching.PayrollRunTeacherTotal{
+			TeacherInfo_Minimal: entity.TeacherInfo_Minimal{
+				TeacherID: entity.TeacherID(teacherTotalRow.TeacherID),
+				UserInfo_Minimal: identity.UserInfo_Minimal{
+					Username:   teacherTotalRow.Username,
+					UserDetail: identity.UnmarshalUserDetail(teacherTotalRow.UserDetail, mainLog),
+				},
+			},
+			TotalTeacherPayments:       teacherTotalRow.TotalTeacherPayments,
+			TotalAttendances:           teacherTotalRow.TotalAttendances,
+			TotalPaidCourseFeeValue:    teacherTotalRow.TotalPaidCourseFeeValue,
+			TotalPaidTransportFeeValue: teacherTotalRow.TotalPaidTransportFeeValue,
+			TotalPaidFeeValue:          teacherTotalRow.TotalPaidCourseFeeValue + teacherTotalRow.TotalPaidTransportFeeValue,
+		})
+	}
+
+	return teacherTotals
+}
+
+func NewTeacherSubstitutionSummariesFromGetTeacherSubstitutionSummariesRow(summaryRows []mysql.GetTeacherSubstitutionSummariesRow) []teaching.TeacherSubstitutionSummary {
+	summaries := make([]teaching.TeacherSubstitutionSummary, 0, len(summaryRows))
+	for _, summaryRow := range summaryRows {
+		summaries = append(summaries, teaching.TeacherSubstitutionSummary{
+			TeacherInfo_Minimal: entity.TeacherInfo_Minimal{
+				TeacherID: entity.TeacherID(summaryRow.TeacherID),
+				UserInfo_Minimal: identity.UserInfo_Minimal{
+					Username:   summaryRow.TeacherUsername,
+					UserDetail: identity.UnmarshalUserDetail(summaryRow.TeacherDetail, mainLog),
+				},
+			},
+			TotalGiven:         summaryRow.TotalGiven,
+			TotalGivenQuota:    summaryRow.TotalGivenQuota,
+			TotalReceived:      summaryRow.TotalReceived,
+			TotalReceivedQuota: summaryRow.TotalReceivedQuota,
+		})
+	}
+
+	return summaries
+}
+
+func NewEnrollmentPaymentRefundsFromGetEnrollmentPaymentRefundsRow(refundRows []mysql.GetEnrollmentPaymentRefundsRow) []teaching.EnrollmentPaymentRefund {
+	enrollmentPaymentRefunds := make([]teaching.EnrollmentPaymentRefund, 0, len(refundRows))
+	for _, refundRow := range refundRows {
+		enrollmentPaymentRefunds = append(enrollmentPaymentRefunds, teaching.EnrollmentPaymentRefund{
+			EnrollmentPaymentRefundID: teaching.EnrollmentPaymentRefundID(refundRow.ID),
+			EnrollmentPaymentID:       entity.EnrollmentPaymentID(refundRow.EnrollmentPaymentID),
+			PaymentDate:               refundRow.PaymentDate,
+			StudentEnrollmentID:       entity.StudentEnrollmentID(refundRow.EnrollmentID.Int64),
+			StudentInfo: entity.StudentInfo_Minimal{
+				StudentID: entity.StudentID(refundRow.StudentID),
+				UserInfo_Minimal: identity.UserInfo_Minimal{
+					Username:   refundRow.StudentUsername,
+					UserDetail: identity.UnmarshalUserDetail(refundRow.StudentDetail, mainLog),
+				},
+			},
+			RefundDate:      refundRow.RefundDate,
+			RefundedValue:   refundRow.RefundedValue,
+			WithdrawnQuota:  refundRow.WithdrawnQuota,
+			Reason:          refundRow.Reason,
+			CreatedAt:       refundRow.CreatedAt,
+			CreatedByUserID: identity.UserID(refundRow.CreatedByUserID.Int64),
+		})
+	}
+
+	return enrollmentPaymentRefunds
+}
+
+func NewPaymentRemindersFromGetPaymentRemindersRow(paymentReminderRows []mysql.GetPaymentRemindersRow) []teaching.PaymentReminder {
+	paymentReminders := make([]teaching.PaymentReminder, 0, len(paymentReminderRows))
+	for _, paymentReminderRow := range paymentReminderRows {
+		paymentReminders = append(paymentReminders, teaching.PaymentReminder{
+			PaymentReminderID:   teaching.PaymentReminderID(paymentReminderRow.ID),
+			StudentEnrollmentID: entity.StudentEnrollmentID(paymentReminderRow.EnrollmentID),
+			StudentInfo: entity.StudentInfo_Minimal{
+				StudentID: entity.StudentID(paymentReminderRow.StudentID),
+				UserInfo_Minimal: identity.UserInfo_Minimal{
+					Username:   paymentReminderRow.StudentUsername,
+					UserDetail: identity.UnmarshalUserDetail(paymentReminderRow.StudentDetail, mainLog),
+				},
+			},
+			Type:              teaching.PaymentReminderType(paymentReminderRow.ReminderType),
+			RecipientEmail:    paymentReminderRow.RecipientEmail,
+			OwedQuota:         paymentReminderRow.OwedQuota,
+			CourseFeeValue:    paymentReminderRow.CourseFeeValue,
+			TransportFeeValue: paymentReminderRow.TransportFeeValue,
+			PenaltyFeeValue:   paymentReminderRow.PenaltyFeeValue,
+			DiscountFeeValue:  paymentReminderRow.DiscountFeeValue,
+			SentAt:            paymentReminderRow.SentAt,
+		})
+	}
+
+	return paymentReminders
+}
+
+func NewPaymentReminderOptOutsFromGetPaymentReminderOptOutsRow(optOutRows []mysql.GetPaymentReminderOptOutsRow) []teaching.PaymentReminderOptOut {
+	optOuts := make([]teaching.PaymentReminderOptOut, 0, len(optOutRows))
+	for _, optOutRow := range optOutRows {
+		optOuts = append(optOuts, teaching.PaymentReminderOptOut{
+			StudentInfo: entity.StudentInfo_Minimal{
+				StudentID: entity.StudentID(optOutRow.StudentID),
+				UserInfo_Minimal: identity.UserInfo_Minimal{
+					Username:   optOutRow.StudentUsername,
+					UserDetail: identity.UnmarshalUserDetail(optOutRow.StudentDetail, mainLog),
+				},
+			},
+			OptedOutAt:       optOutRow.OptedOutAt,
+			OptedOutByUserID: identity.UserID(optOutRow.OptedOutByUserID.Int64),
+		})
+	}
+
+	return optOuts
+}
+
+func NewInstallmentPlan(installmentPlanRow mysql.InstallmentPlan, installmentRows []mysql.GetInstallmentsByInstallmentPlanIdRow, studentEnrollment entity.StudentEnrollment) teaching.InstallmentPlan {
+	installments := make([]teaching.Installment, 0, len(installmentRows))
+	for _, installmentRow := range installmentRows {
+		// `GetInstallmentsByInstallmentPlanIdRow` shares the same columns as `Installment`, plus the paid EnrollmentPayment's date.
+		installment := newInstallment(mysql.Installment{
+			ID:                  installmentRow.ID,
+			InstallmentNumber:   installmentRow.InstallmentNumber,
+			DueDate:             installmentRow.DueDate,
+			BalanceTopUp:        installmentRow.BalanceTopUp,
+			BalanceBonus:        installmentRow.BalanceBonus,
+			CourseFeeValue:      installmentRow.CourseFeeValue,
+			TransportFeeValue:   installmentRow.TransportFeeValue,
+			PenaltyFeeValue:     installmentRow.PenaltyFeeValue,
+			DiscountFeeValue:    installmentRow.DiscountFeeValue,
+			InstallmentPlanID:   installmentRow.InstallmentPlanID,
+			EnrollmentPaymentID: installmentRow.EnrollmentPaymentID,
+		})
+		if installmentRow.PaymentDate.Valid {
+			paymentDate := installmentRow.PaymentDate.Time
+			installment.PaymentDate = &paymentDate
+		}
+		installments = append(installments, installment)
+	}
+
+	return teaching.InstallmentPlan{
+		InstallmentPlanID: teaching.InstallmentPlanID(installmentPlanRow.ID),
+		StudentEnrollment: studentEnrollment,
+		BalanceTopUp:      installmentPlanRow.BalanceTopUp,
+		BalanceBonus:      installmentPlanRow.BalanceBonus,
+		CourseFeeValue:    installmentPlanRow.CourseFeeValue,
+		TransportFeeValue: installmentPlanRow.TransportFeeValue,
+		PenaltyFeeValue:   installmentPlanRow.PenaltyFeeValue,
+		DiscountFeeValue:  installmentPlanRow.DiscountFeeValue,
+		TotalValue:        int64(installmentPlanRow.CourseFeeValue) + int64(installmentPlanRow.TransportFeeValue) + int64(installmentPlanRow.PenaltyFeeValue) - int64(installmentPlanRow.DiscountFeeValue),
+		InstallmentCount:  installmentPlanRow.InstallmentCount,
+		Installments:      installments,
+		CreatedAt:         installmentPlanRow.CreatedAt,
+		CreatedByUserID:   identity.UserID(installmentPlanRow.CreatedByUserID.Int64),
+	}
+}
+
+func NewOverdueInstallmentsFromGetOverdueInstallmentsRow(overdueInstallmentRows []mysql.GetOverdueInstallmentsRow, date time.Time) []teaching.OverdueInstallment {
+	overdueInstallments := make([]teaching.OverdueInstallment, 0, len(overdueInstallmentRows))
+	for _, overdueInstallmentRow := range overdueInstallmentRows {
+		overdueInstallments = append(overdueInstallments, teaching.OverdueInstallment{
+			Installment: newInstallment(mysql.Installment{
+				ID:                  overdueInstallmentRow.ID,
+				InstallmentNumber:   overdueInstallmentRow.InstallmentNumber,
+				DueDate:             overdueInstallmentRow.DueDate,
+				BalanceTopUp:        overdueInstallmentRow.BalanceTopUp,
+				BalanceBonus:        overdueInstallmentRow.BalanceBonus,
+				CourseFeeValue:      overdueInstallmentRow.CourseFeeValue,
+				TransportFeeValue:   overdueInstallmentRow.TransportFeeValue,
+				PenaltyFeeValue:     overdueInstallmentRow.PenaltyFeeValue,
+				DiscountFeeValue:    overdueInstallmentRow.DiscountFeeValue,
+				InstallmentPlanID:   overdueInstallmentRow.InstallmentPlanID,
+				EnrollmentPaymentID: overdueInstallmentRow.EnrollmentPaymentID,
+			}),
+			StudentEnrollmentID: entity.StudentEnrollmentID(overdueInstallmentRow.EnrollmentID),
+			StudentInfo: entity.StudentInfo_Minimal{
+				StudentID: entity.StudentID(overdueInstallmentRow.StudentID),
+				UserInfo_Minimal: identity.UserInfo_Minimal{
+					Username:   overdueInstallmentRow.StudentUsername,
+					UserDetail: identity.UnmarshalUserDetail(overdueInstallmentRow.StudentDetail, mainLog),
+				},
+			},
+			DaysOverdue: int32(date.Sub(overdueInstallmentRow.DueDate).Hours() / 24),
+		})
+	}
+
+	return overdueInstallments
+}
+
+func newInstallment(installmentRow mysql.Installment) teaching.Installment {
+	return teaching.Installment{
+		InstallmentID:       teaching.InstallmentID(installmentRow.ID),
+		InstallmentPlanID:   teaching.InstallmentPlanID(installmentRow.InstallmentPlanID),
+		InstallmentNumber:   installmentRow.InstallmentNumber,
+		DueDate:             installmentRow.DueDate,
+		BalanceTopUp:        installmentRow.BalanceTopUp,
+		BalanceBonus:        installmentRow.BalanceBonus,
+		CourseFeeValue:      installmentRow.CourseFeeValue,
+		TransportFeeValue:   installmentRow.TransportFeeValue,
+		PenaltyFeeValue:     installmentRow.PenaltyFeeValue,
+		DiscountFeeValue:    installmentRow.DiscountFeeValue,
+		Value:               installmentRow.CourseFeeValue + installmentRow.TransportFeeValue + installmentRow.PenaltyFeeValue - installmentRow.DiscountFeeValue,
+		EnrollmentPaymentID: entity.EnrollmentPaymentID(installmentRow.EnrollmentPaymentID.Int64),
+	}
+}
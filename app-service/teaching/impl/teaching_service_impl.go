@@ -3,19 +3,29 @@ package teaching
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"net/http"
+	"sort"
 	"time"
 
+	"github.com/matcornic/hermes/v2"
+
+	"sonamusica-backend/accessor/email"
 	"sonamusica-backend/accessor/relational_db"
 	"sonamusica-backend/accessor/relational_db/mysql"
+	"sonamusica-backend/app-service/email_composer"
 	"sonamusica-backend/app-service/entity"
 	"sonamusica-backend/app-service/identity"
 	"sonamusica-backend/app-service/teaching"
+	"sonamusica-backend/app-service/user_action_log"
 	"sonamusica-backend/app-service/util"
 	"sonamusica-backend/config"
 	"sonamusica-backend/errs"
 	"sonamusica-backend/logging"
+	"sonamusica-backend/network"
 )
 
 var (
@@ -26,20 +36,33 @@ var (
 const (
 	pagination_FirstPage = 1
 	pagination_FetchAll  = 10000
+
+	// sltQuotaTolerance is used for comparing SLT quota values (float), which are stored with 3 decimal places
+	sltQuotaTolerance = 0.001
+
+	// these values are used for the UserActionLog of ReconcileSLTQuotas() repair mode
+	sltReconciliation_LogEndpoint = "SLTReconciliation"
+	sltReconciliation_LogMethod   = "REPAIR"
 )
 
 type teachingServiceImpl struct {
-	mySQLQueries *relational_db.MySQLQueries
+	mySQLQueries  *relational_db.MySQLQueries
+	smtpAccessor  email.SMTPAccessor
+	emailComposer *hermes.Hermes
 
-	entityService entity.EntityService
+	entityService        entity.EntityService
+	userActionLogService user_action_log.UserActionLogService
 }
 
 var _ teaching.TeachingService = (*teachingServiceImpl)(nil)
 
-func NewTeachingServiceImpl(mySQLQueries *relational_db.MySQLQueries, entityService entity.EntityService) *teachingServiceImpl {
+func NewTeachingServiceImpl(mySQLQueries *relational_db.MySQLQueries, smtpAccessor email.SMTPAccessor, emailComposer *hermes.Hermes, entityService entity.EntityService, userActionLogService user_action_log.UserActionLogService) *teachingServiceImpl {
 	return &teachingServiceImpl{
-		mySQLQueries:  mySQLQueries,
-		entityService: entityService,
+		mySQLQueries:         mySQLQueries,
+		smtpAccessor:         smtpAccessor,
+		emailComposer:        emailComposer,
+		entityService:        entityService,
+		userActionLogService: userActionLogService,
 	}
 }
 
@@ -97,12 +120,18 @@ func (s teachingServiceImpl) SearchEnrollmentPayment(ctx context.Context, timeFi
 	return getEnrollmentPaymentsResult.EnrollmentPayments, nil
 }
 
-func (s teachingServiceImpl) GetEnrollmentPaymentInvoice(ctx context.Context, studentEnrollmentID entity.StudentEnrollmentID) (teaching.StudentEnrollmentInvoice, error) {
+func (s teachingServiceImpl) GetEnrollmentPaymentInvoice(ctx context.Context, studentEnrollmentID entity.StudentEnrollmentID, paymentDate time.Time, voucherCode string) (teaching.StudentEnrollmentInvoice, error) {
+	if paymentDate.IsZero() {
+		paymentDate = time.Now()
+	}
+
 	var courseFeeValueFinal int32
 	var splittedTransportFeeFinal int32
 	var penaltyFeeValueFinal int32
 	var lastPaymentDateFinal *time.Time
 	var daysLateFinal int32
+	var penaltyPolicyFinal *entity.PenaltyPolicy
+	var discountsFinal []teaching.AppliedDiscount
 
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
 		studentEnrollment, err := s.entityService.GetStudentEnrollmentById(ctx, studentEnrollmentID)
@@ -110,9 +139,13 @@ func (s teachingServiceImpl) GetEnrollmentPaymentInvoice(ctx context.Context, st
 			return fmt.Errorf("entityService.GetStudentEnrollmentById(): %w", err)
 		}
 
-		courseFeeValue := studentEnrollment.ClassInfo.Course.DefaultFee
-		if studentEnrollment.ClassInfo.TeacherSpecialFee != 0 {
-			courseFeeValue = studentEnrollment.ClassInfo.TeacherSpecialFee
+		teacherID := entity.TeacherID_None
+		if studentEnrollment.ClassInfo.TeacherInfo_Minimal != nil {
+			teacherID = studentEnrollment.ClassInfo.TeacherInfo_Minimal.TeacherID
+		}
+		courseFeeValue, err := s.entityService.GetCourseFeeValueAt(newCtx, studentEnrollment.ClassInfo.Course.CourseID, teacherID, paymentDate)
+		if err != nil {
+			return fmt.Errorf("entityService.GetCourseFeeValueAt(): %w", err)
 		}
 
 		// calculate Course Fee Penalty (e.g. due to late payment)
@@ -124,17 +157,30 @@ func (s teachingServiceImpl) GetEnrollmentPaymentInvoice(ctx context.Context, st
 		}
 
 		var lastPaymentDate *time.Time = nil
-		lastDateBeforePenalty := util.MaxDateTime
 		if latestPaymentDate != nil {
 			temp := latestPaymentDate.(time.Time)
 			lastPaymentDate = &temp
-			lastDateBeforePenalty = time.Date(lastPaymentDate.Year(), lastPaymentDate.AddDate(0, 1, 0).Month(), 10, 0, 0, 0, 0, util.DefaultTimezone)
 		}
-		daysLate := int32(time.Since(lastDateBeforePenalty).Hours() / 24)
-		var penaltyFeeValue int32 = 0
-		if daysLate > 0 {
-			penaltyFeeValue = teaching.Default_PenaltyFeeValue * daysLate
+
+		var penaltyPolicy *entity.PenaltyPolicy = nil
+		appliedPenaltyPolicy := entity.PenaltyPolicy{
+			TriggerDayOfMonth: teaching.Default_PenaltyTriggerDayOfMonth,
+			FeeValue:          teaching.Default_PenaltyFeeValue,
+		}
+		applicablePenaltyPolicy, err := s.entityService.GetApplicablePenaltyPolicy(newCtx, studentEnrollment.ClassInfo.ClassID, studentEnrollment.ClassInfo.Course.CourseID)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("entityService.GetApplicablePenaltyPolicy(): %w", err)
+			}
+		} else {
+			penaltyPolicy = &applicablePenaltyPolicy
+			appliedPenaltyPolicy = applicablePenaltyPolicy
+		}
+		holds, err := s.entityService.GetStudentEnrollmentHoldsByStudentEnrollmentIds(newCtx, []entity.StudentEnrollmentID{studentEnrollmentID})
+		if err != nil {
+			return fmt.Errorf("entityService.GetStudentEnrollmentHoldsByStudentEnrollmentIds(): %w", err)
 		}
+		penaltyFeeValue, daysLate := teaching.CalculatePenaltyFee(appliedPenaltyPolicy, lastPaymentDate, paymentDate, holds)
 
 		// calculate transport fee (splitted unionly across all class students)
 		splittedTransportFee := studentEnrollment.ClassInfo.TransportFee
@@ -146,12 +192,50 @@ func (s teachingServiceImpl) GetEnrollmentPaymentInvoice(ctx context.Context, st
 			splittedTransportFee /= int32(classIdToTotalStudents[0].TotalStudents)
 		}
 
+		// calculate discount fee (based on the automatic DiscountRules, plus the voucher if any)
+		discountRules, err := s.entityService.GetActiveAutomaticDiscountRules(newCtx)
+		if err != nil {
+			return fmt.Errorf("entityService.GetActiveAutomaticDiscountRules(): %w", err)
+		}
+		applicableDiscountRules := make([]entity.DiscountRule, 0, len(discountRules))
+		for _, discountRule := range discountRules {
+			isApplicable, err := s.isDiscountRuleApplicable(newCtx, qtx, discountRule, studentEnrollment.StudentInfo.StudentID, paymentDate)
+			if err != nil {
+				return fmt.Errorf("isDiscountRuleApplicable(): %w", err)
+			}
+			if isApplicable {
+				applicableDiscountRules = append(applicableDiscountRules, discountRule)
+			}
+		}
+
+		if voucherCode != "" {
+			voucher, err := s.entityService.GetDiscountRuleByVoucherCode(newCtx, voucherCode)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return errs.ErrVoucherNotFound
+				}
+				return fmt.Errorf("entityService.GetDiscountRuleByVoucherCode(): %w", err)
+			}
+			isApplicable, err := s.isDiscountRuleApplicable(newCtx, qtx, voucher, studentEnrollment.StudentInfo.StudentID, paymentDate)
+			if err != nil {
+				return fmt.Errorf("isDiscountRuleApplicable(): %w", err)
+			}
+			if !isApplicable {
+				return fmt.Errorf("voucher '%s': %w", voucherCode, errs.ErrDiscountRuleNotApplicable)
+			}
+			applicableDiscountRules = append(applicableDiscountRules, voucher)
+		}
+
+		discounts := teaching.SelectAppliedDiscounts(applicableDiscountRules, courseFeeValue)
+
 		// assign to the top level variable, to be used outside logic block of the ExecuteInTransaction()
 		courseFeeValueFinal = courseFeeValue
 		splittedTransportFeeFinal = splittedTransportFee
 		penaltyFeeValueFinal = penaltyFeeValue
 		lastPaymentDateFinal = lastPaymentDate
 		daysLateFinal = daysLate
+		penaltyPolicyFinal = penaltyPolicy
+		discountsFinal = discounts
 
 		return nil
 	})
@@ -159,21 +243,67 @@ func (s teachingServiceImpl) GetEnrollmentPaymentInvoice(ctx context.Context, st
 		return teaching.StudentEnrollmentInvoice{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
 	}
 
+	var discountFeeValue int32 = 0
+	for _, discount := range discountsFinal {
+		discountFeeValue += discount.Value
+	}
+
 	return teaching.StudentEnrollmentInvoice{
 		BalanceTopUp:      teaching.Default_BalanceTopUp,
 		BalanceBonus:      0,
 		CourseFeeValue:    courseFeeValueFinal,
 		TransportFeeValue: splittedTransportFeeFinal,
 		PenaltyFeeValue:   penaltyFeeValueFinal,
-		DiscountFeeValue:  0,
+		DiscountFeeValue:  discountFeeValue,
 		LastPaymentDate:   lastPaymentDateFinal,
 		DaysLate:          daysLateFinal,
+		PenaltyPolicy:     penaltyPolicyFinal,
+		Discounts:         discountsFinal,
 	}, nil
 }
 
-func (s teachingServiceImpl) SubmitEnrollmentPayment(ctx context.Context, spec teaching.SubmitStudentEnrollmentPaymentSpec) error {
+// isDiscountRuleApplicable checks whether the DiscountRule's condition is fulfilled by the student's payment on paymentDate. Check entity.DiscountRuleType for the condition of each type.
+func (s teachingServiceImpl) isDiscountRuleApplicable(ctx context.Context, qtx *mysql.Queries, discountRule entity.DiscountRule, studentID entity.StudentID, paymentDate time.Time) (bool, error) {
+	if !discountRule.IsValidAt(paymentDate) {
+		return false, nil
+	}
+
+	switch discountRule.Type {
+	case entity.DiscountRuleType_Sibling:
+		totalSiblings, err := qtx.CountEnrolledSiblingsByStudentId(ctx, int64(studentID))
+		if err != nil {
+			return false, fmt.Errorf("qtx.CountEnrolledSiblingsByStudentId(): %w", err)
+		}
+		return totalSiblings >= int64(discountRule.MinCount), nil
+
+	case entity.DiscountRuleType_MultiClass:
+		totalEnrollments, err := qtx.CountActiveStudentEnrollmentsByStudentId(ctx, int64(studentID))
+		if err != nil {
+			return false, fmt.Errorf("qtx.CountActiveStudentEnrollmentsByStudentId(): %w", err)
+		}
+		return totalEnrollments >= int64(discountRule.MinCount), nil
+
+	case entity.DiscountRuleType_EarlyPayment:
+		return int32(paymentDate.In(util.DefaultTimezone).Day()) <= discountRule.MaxDayOfMonth, nil
+
+	case entity.DiscountRuleType_Voucher:
+		if discountRule.UsageLimit <= 0 {
+			return true, nil
+		}
+		totalUsages, err := qtx.CountDiscountRuleUsages(ctx, sql.NullInt64{Int64: int64(discountRule.DiscountRuleID), Valid: true})
+		if err != nil {
+			return false, fmt.Errorf("qtx.CountDiscountRuleUsages(): %w", err)
+		}
+		return totalUsages < int64(discountRule.UsageLimit), nil
+	}
+
+	return false, nil
+}
+
+func (s teachingServiceImpl) SubmitEnrollmentPayment(ctx context.Context, spec teaching.SubmitStudentEnrollmentPaymentSpec) (entity.EnrollmentPaymentID, error) {
+	var enrollmentPaymentID entity.EnrollmentPaymentID
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
-		_, err := s.entityService.InsertEnrollmentPayments(newCtx, []entity.InsertEnrollmentPaymentSpec{
+		enrollmentPaymentIDs, err := s.entityService.InsertEnrollmentPayments(newCtx, []entity.InsertEnrollmentPaymentSpec{
 			{
 				StudentEnrollmentID: spec.StudentEnrollmentID,
 				PaymentDate:         spec.PaymentDate,
@@ -183,11 +313,22 @@ func (s teachingServiceImpl) SubmitEnrollmentPayment(ctx context.Context, spec t
 				TransportFeeValue:   spec.TransportFeeValue,
 				PenaltyFeeValue:     spec.PenaltyFeeValue,
 				DiscountFeeValue:    spec.DiscountFeeValue,
+				PaymentMethod:       spec.PaymentMethod,
+				ReceivingAccount:    spec.ReceivingAccount,
+				ReferenceNumber:     spec.ReferenceNumber,
 			},
 		})
 		if err != nil {
 			return fmt.Errorf("entityService.InsertEnrollmentPayments(): %w", err)
 		}
+		enrollmentPaymentID = enrollmentPaymentIDs[0]
+
+		if len(spec.DiscountRuleIDs) > 0 {
+			err = s.insertEnrollmentPaymentDiscounts(newCtx, qtx, enrollmentPaymentID, spec)
+			if err != nil {
+				return fmt.Errorf("insertEnrollmentPaymentDiscounts(): %w", err)
+			}
+		}
 
 		// Upsert StudentLearningTokens
 		var totalBalanceTopUp = float64(spec.BalanceTopUp + spec.BalanceBonus)
@@ -213,40 +354,222 @@ func (s teachingServiceImpl) SubmitEnrollmentPayment(ctx context.Context, spec t
 					Quota:                    totalBalanceTopUp,
 					CourseFeeQuarterValue:    courseFeeQuarterValue,
 					TransportFeeQuarterValue: transportFeeQuarterValue,
+					QuotaChangeReason:        entity.SLTTransactionReason_Payment,
+					QuotaChangeSourceID:      int64(enrollmentPaymentIDs[0]),
 				},
 			})
 			if err != nil {
 				return fmt.Errorf("entityService.InsertStudentLearningTokens(): %w", err)
 			}
 		} else {
-			err := qtx.IncrementSLTQuotaById(newCtx, mysql.IncrementSLTQuotaByIdParams{
-				Quota:         totalBalanceTopUp,
-				LastUpdatedAt: time.Now().UTC(),
-				ID:            existingSLT.ID,
+			err := s.incrementSLTQuota(newCtx, entity.InsertSLTTransactionSpec{
+				StudentLearningTokenID: entity.StudentLearningTokenID(existingSLT.ID),
+				QuotaChange:            totalBalanceTopUp,
+				Reason:                 entity.SLTTransactionReason_Payment,
+				SourceID:               int64(enrollmentPaymentIDs[0]),
 			})
 			if err != nil {
-				return fmt.Errorf("qtx.IncrementSLTQuotaById(): %w", err)
+				return fmt.Errorf("incrementSLTQuota(): %w", err)
 			}
 		}
 
 		return nil
 	})
 	if err != nil {
-		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+		return entity.EnrollmentPaymentID_None, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return enrollmentPaymentID, nil
+}
+
+// insertEnrollmentPaymentDiscounts re-evaluates spec.DiscountRuleIDs, then records them as the discounts of the EnrollmentPayment.
+//
+// The DiscountRules are locked until the transaction ends, so that concurrent payments cannot use a voucher beyond its usage limit.
+func (s teachingServiceImpl) insertEnrollmentPaymentDiscounts(ctx context.Context, qtx *mysql.Queries, enrollmentPaymentID entity.EnrollmentPaymentID, spec teaching.SubmitStudentEnrollmentPaymentSpec) error {
+	studentEnrollment, err := s.entityService.GetStudentEnrollmentById(ctx, spec.StudentEnrollmentID)
+	if err != nil {
+		return fmt.Errorf("entityService.GetStudentEnrollmentById(): %w", err)
+	}
+
+	discountRules := make([]entity.DiscountRule, 0, len(spec.DiscountRuleIDs))
+	for _, discountRuleID := range spec.DiscountRuleIDs {
+		_, err := qtx.GetDiscountRuleByIdForUpdate(ctx, int64(discountRuleID))
+		if err != nil {
+			return fmt.Errorf("qtx.GetDiscountRuleByIdForUpdate(): %w", err)
+		}
+		discountRule, err := s.entityService.GetDiscountRuleById(ctx, discountRuleID)
+		if err != nil {
+			return fmt.Errorf("entityService.GetDiscountRuleById(): %w", err)
+		}
+
+		isApplicable, err := s.isDiscountRuleApplicable(ctx, qtx, discountRule, studentEnrollment.StudentInfo.StudentID, spec.PaymentDate)
+		if err != nil {
+			return fmt.Errorf("isDiscountRuleApplicable(): %w", err)
+		}
+		if !isApplicable {
+			return fmt.Errorf("discountRule '%s': %w", discountRule.Name, errs.ErrDiscountRuleNotApplicable)
+		}
+		discountRules = append(discountRules, discountRule)
+	}
+
+	// a rule is dropped when another rule of the same type is better, or when the course fee has been fully discounted
+	discounts := teaching.SelectAppliedDiscounts(discountRules, spec.CourseFeeValue)
+	if len(discounts) != len(discountRules) {
+		return fmt.Errorf("discountRules cannot be combined: %w", errs.ErrDiscountRuleNotApplicable)
+	}
+
+	var totalDiscountValue int32 = 0
+	for _, discount := range discounts {
+		totalDiscountValue += discount.Value
+	}
+	if totalDiscountValue > spec.DiscountFeeValue {
+		return fmt.Errorf("total discount '%d' > discountFeeValue '%d': %w", totalDiscountValue, spec.DiscountFeeValue, errs.ErrDiscountExceedsDiscountFee)
+	}
+
+	for _, discount := range discounts {
+		_, err := qtx.InsertEnrollmentPaymentDiscount(ctx, mysql.InsertEnrollmentPaymentDiscountParams{
+			EnrollmentPaymentID: int64(enrollmentPaymentID),
+			DiscountRuleID:      sql.NullInt64{Int64: int64(discount.DiscountRuleID), Valid: true},
+			DiscountRuleName:    discount.Name,
+			Value:               discount.Value,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.InsertEnrollmentPaymentDiscount(): %w", err)
+		}
 	}
 
 	return nil
 }
 
+func (s teachingServiceImpl) PreviewSubmitEnrollmentPayment(ctx context.Context, spec teaching.SubmitStudentEnrollmentPaymentSpec) (teaching.SLTChangesPreview, error) {
+	return s.previewSLTChanges(ctx, func(newCtx context.Context) error {
+		_, err := s.SubmitEnrollmentPayment(newCtx, spec)
+		return err
+	})
+}
+
+func (s teachingServiceImpl) GetFamilyInvoice(ctx context.Context, familyID entity.FamilyID, paymentDate time.Time) (teaching.FamilyInvoice, error) {
+	var familyInvoice teaching.FamilyInvoice
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		family, err := s.entityService.GetFamilyById(newCtx, familyID)
+		if err != nil {
+			return fmt.Errorf("entityService.GetFamilyById(): %w", err)
+		}
+		studentEnrollmentIDs, err := s.entityService.GetActiveStudentEnrollmentIdsByFamilyId(newCtx, familyID)
+		if err != nil {
+			return fmt.Errorf("entityService.GetActiveStudentEnrollmentIdsByFamilyId(): %w", err)
+		}
+
+		items := make([]teaching.FamilyInvoiceItem, 0, len(studentEnrollmentIDs))
+		var totalValue int64 = 0
+		for _, studentEnrollmentID := range studentEnrollmentIDs {
+			studentEnrollment, err := s.entityService.GetStudentEnrollmentById(newCtx, studentEnrollmentID)
+			if err != nil {
+				return fmt.Errorf("entityService.GetStudentEnrollmentById(): %w", err)
+			}
+			invoice, err := s.GetEnrollmentPaymentInvoice(newCtx, studentEnrollmentID, paymentDate, "")
+			if err != nil {
+				return fmt.Errorf("GetEnrollmentPaymentInvoice(): %w", err)
+			}
+
+			items = append(items, teaching.FamilyInvoiceItem{
+				StudentEnrollment: studentEnrollment,
+				Invoice:           invoice,
+			})
+			totalValue += int64(invoice.CourseFeeValue + invoice.TransportFeeValue + invoice.PenaltyFeeValue - invoice.DiscountFeeValue)
+		}
+
+		familyInvoice = teaching.FamilyInvoice{
+			Family:     family,
+			Items:      items,
+			TotalValue: totalValue,
+		}
+		return nil
+	})
+	if err != nil {
+		return teaching.FamilyInvoice{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return familyInvoice, nil
+}
+
+func (s teachingServiceImpl) SubmitFamilyPayment(ctx context.Context, spec teaching.SubmitFamilyPaymentSpec) ([]entity.EnrollmentPaymentID, error) {
+	enrollmentPaymentIDs := make([]entity.EnrollmentPaymentID, 0, len(spec.Items))
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		familyStudentEnrollmentIDs, err := s.entityService.GetActiveStudentEnrollmentIdsByFamilyId(newCtx, spec.FamilyID)
+		if err != nil {
+			return fmt.Errorf("entityService.GetActiveStudentEnrollmentIdsByFamilyId(): %w", err)
+		}
+		isFamilyStudentEnrollment := make(map[entity.StudentEnrollmentID]bool, len(familyStudentEnrollmentIDs))
+		for _, studentEnrollmentID := range familyStudentEnrollmentIDs {
+			isFamilyStudentEnrollment[studentEnrollmentID] = true
+		}
+
+		for _, item := range spec.Items {
+			if !isFamilyStudentEnrollment[item.StudentEnrollmentID] {
+				return fmt.Errorf("studentEnrollmentID '%d': %w", item.StudentEnrollmentID, errs.ErrStudentEnrollmentNotInFamily)
+			}
+
+			// the nested ExecuteInTransaction() of SubmitEnrollmentPayment() reuses this transaction
+			enrollmentPaymentID, err := s.SubmitEnrollmentPayment(newCtx, teaching.SubmitStudentEnrollmentPaymentSpec{
+				StudentEnrollmentID: item.StudentEnrollmentID,
+				PaymentDate:         spec.PaymentDate,
+				BalanceTopUp:        item.BalanceTopUp,
+				BalanceBonus:        item.BalanceBonus,
+				CourseFeeValue:      item.CourseFeeValue,
+				TransportFeeValue:   item.TransportFeeValue,
+				PenaltyFeeValue:     item.PenaltyFeeValue,
+				DiscountFeeValue:    item.DiscountFeeValue,
+				DiscountRuleIDs:     item.DiscountRuleIDs,
+				PaymentMethod:       spec.PaymentMethod,
+				ReceivingAccount:    spec.ReceivingAccount,
+				ReferenceNumber:     spec.ReferenceNumber,
+			})
+			if err != nil {
+				return fmt.Errorf("SubmitEnrollmentPayment(studentEnrollmentID='%d'): %w", item.StudentEnrollmentID, err)
+			}
+			enrollmentPaymentIDs = append(enrollmentPaymentIDs, enrollmentPaymentID)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.EnrollmentPaymentID{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return enrollmentPaymentIDs, nil
+}
+
 func (s teachingServiceImpl) EditEnrollmentPayment(ctx context.Context, spec teaching.EditStudentEnrollmentPaymentSpec) (entity.EnrollmentPaymentID, error) {
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
 		prevEP, err := qtx.GetEnrollmentPaymentById(newCtx, int64(spec.EnrollmentPaymentID))
 		if err != nil {
 			return fmt.Errorf("qtx.GetEnrollmentPaymentById(): %w", err)
 		}
+		err = s.entityService.EnsureDatesNotInClosedPeriod(newCtx, entity.EnsureDatesNotInClosedPeriodSpec{
+			Operation: "EditEnrollmentPayment",
+			Dates:     []time.Time{prevEP.PaymentDate, spec.PaymentDate},
+		})
+		if err != nil {
+			return fmt.Errorf("entityService.EnsureDatesNotInClosedPeriod(): %w", err)
+		}
+		err = s.entityService.EnsurePaymentDatesNotInClosedCashUpDay(newCtx, []time.Time{prevEP.PaymentDate, spec.PaymentDate})
+		if err != nil {
+			return fmt.Errorf("entityService.EnsurePaymentDatesNotInClosedCashUpDay(): %w", err)
+		}
+
+		// the applied discountRules (& their usage counts) are recorded along with the discountFeeValue on submission, thus changing it would make them inconsistent
+		if spec.DiscountFeeValue != prevEP.DiscountFeeValue {
+			discountCount, err := qtx.CountEnrollmentPaymentDiscountsByEnrollmentPaymentId(newCtx, int64(spec.EnrollmentPaymentID))
+			if err != nil {
+				return fmt.Errorf("qtx.CountEnrollmentPaymentDiscountsByEnrollmentPaymentId(): %w", err)
+			}
+			if discountCount > 0 {
+				return errs.ErrDiscountFeeLocked
+			}
+		}
 
 		updatedSLT, err := qtx.GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter(newCtx, mysql.GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarterParams{
-			EnrollmentID:             prevEP.StudentEnrollmentID,
+			EnrollmentID:             prevEP.StudentEnrollmentID.Int64,
 			CourseFeeQuarterValue:    teaching.CalculateSLTFeeQuarterFromEP(prevEP.CourseFeeValue, prevEP.BalanceTopUp),
 			TransportFeeQuarterValue: teaching.CalculateSLTFeeQuarterFromEP(prevEP.TransportFeeValue, prevEP.BalanceTopUp),
 		})
@@ -261,13 +584,14 @@ func (s teachingServiceImpl) EditEnrollmentPayment(ctx context.Context, spec tea
 
 		if !skipSLTUpdate {
 			quotaChange := float64(spec.BalanceBonus - prevEP.BalanceBonus)
-			err = qtx.IncrementSLTQuotaById(newCtx, mysql.IncrementSLTQuotaByIdParams{
-				Quota:         quotaChange,
-				LastUpdatedAt: time.Now().UTC(),
-				ID:            updatedSLT.ID,
+			err = s.incrementSLTQuota(newCtx, entity.InsertSLTTransactionSpec{
+				StudentLearningTokenID: entity.StudentLearningTokenID(updatedSLT.ID),
+				QuotaChange:            quotaChange,
+				Reason:                 entity.SLTTransactionReason_Payment,
+				SourceID:               prevEP.EnrollmentPaymentID,
 			})
 			if err != nil {
-				return fmt.Errorf("qtx.IncrementSLTQuotaById(): %w", err)
+				return fmt.Errorf("incrementSLTQuota(): %w", err)
 			}
 		}
 
@@ -302,8 +626,16 @@ func (s teachingServiceImpl) RemoveEnrollmentPayment(ctx context.Context, enroll
 			return fmt.Errorf("qtx.GetEnrollmentPaymentById(): %w", err)
 		}
 
+		refundTotal, err := qtx.GetEnrollmentPaymentRefundTotalByEnrollmentPaymentId(newCtx, prevEP.EnrollmentPaymentID)
+		if err != nil {
+			return fmt.Errorf("qtx.GetEnrollmentPaymentRefundTotalByEnrollmentPaymentId(): %w", err)
+		}
+		if refundTotal.TotalRefunds > 0 {
+			return fmt.Errorf("enrollmentPaymentID '%d': %w", prevEP.EnrollmentPaymentID, errs.ErrEnrollmentPaymentRefunded)
+		}
+
 		updatedSLT, err := qtx.GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter(newCtx, mysql.GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarterParams{
-			EnrollmentID:             prevEP.StudentEnrollmentID,
+			EnrollmentID:             prevEP.StudentEnrollmentID.Int64,
 			CourseFeeQuarterValue:    teaching.CalculateSLTFeeQuarterFromEP(prevEP.CourseFeeValue, prevEP.BalanceTopUp),
 			TransportFeeQuarterValue: teaching.CalculateSLTFeeQuarterFromEP(prevEP.TransportFeeValue, prevEP.BalanceTopUp),
 		})
@@ -318,13 +650,14 @@ func (s teachingServiceImpl) RemoveEnrollmentPayment(ctx context.Context, enroll
 
 		if !skipSLTUpdate {
 			quotaChange := -1 * (prevEP.BalanceTopUp + prevEP.BalanceBonus)
-			err = qtx.IncrementSLTQuotaById(newCtx, mysql.IncrementSLTQuotaByIdParams{
-				Quota:         float64(quotaChange),
-				LastUpdatedAt: time.Now().UTC(),
-				ID:            updatedSLT.ID,
+			err = s.incrementSLTQuota(newCtx, entity.InsertSLTTransactionSpec{
+				StudentLearningTokenID: entity.StudentLearningTokenID(updatedSLT.ID),
+				QuotaChange:            float64(quotaChange),
+				Reason:                 entity.SLTTransactionReason_Payment,
+				SourceID:               prevEP.EnrollmentPaymentID,
 			})
 			if err != nil {
-				return fmt.Errorf("qtx.IncrementSLTQuotaById(): %w", err)
+				return fmt.Errorf("incrementSLTQuota(): %w", err)
 			}
 		}
 
@@ -344,173 +677,1346 @@ func (s teachingServiceImpl) RemoveEnrollmentPayment(ctx context.Context, enroll
 	return nil
 }
 
-func (s teachingServiceImpl) SearchClass(ctx context.Context, spec teaching.SearchClassSpec) ([]entity.Class, error) {
-	paginationSpec := util.PaginationSpec{
-		Page:           pagination_FirstPage,
-		ResultsPerPage: pagination_FetchAll,
-	}
-	getClassesSpec := entity.GetClassesSpec{
-		IncludeDeactivated: true,
-		TeacherID:          spec.TeacherID,
-		StudentID:          spec.StudentID,
-		CourseID:           spec.CourseID,
-		PrettySorting:      true,
-	}
-	getClassResult, err := s.entityService.GetClasses(ctx, paginationSpec, getClassesSpec)
+func (s teachingServiceImpl) RefundEnrollmentPayment(ctx context.Context, spec teaching.RefundEnrollmentPaymentSpec) (teaching.EnrollmentPaymentRefundID, error) {
+	authInfo := network.GetAuthInfo(ctx)
+
+	var enrollmentPaymentRefundID teaching.EnrollmentPaymentRefundID
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		// the EnrollmentPayment is locked first, so that concurrent refunds of the same EnrollmentPayment cannot both pass the refund limits below
+		paymentDate, err := qtx.GetEnrollmentPaymentDateByIdForUpdate(newCtx, int64(spec.EnrollmentPaymentID))
+		if err != nil {
+			return fmt.Errorf("qtx.GetEnrollmentPaymentDateByIdForUpdate(): %w", err)
+		}
+		if util.ToLocalDate(spec.RefundDate).Before(util.ToLocalDate(paymentDate)) {
+			return fmt.Errorf("refundDate '%v' is before paymentDate '%v': %w", spec.RefundDate, paymentDate, errs.ErrRefundBeforeEnrollmentPayment)
+		}
+
+		prevEP, err := qtx.GetEnrollmentPaymentById(newCtx, int64(spec.EnrollmentPaymentID))
+		if err != nil {
+			return fmt.Errorf("qtx.GetEnrollmentPaymentById(): %w", err)
+		}
+		err = s.entityService.EnsureDatesNotInClosedPeriod(newCtx, entity.EnsureDatesNotInClosedPeriodSpec{
+			Operation: "RefundEnrollmentPayment",
+			Dates:     []time.Time{spec.RefundDate},
+		})
+		if err != nil {
+			return fmt.Errorf("entityService.EnsureDatesNotInClosedPeriod(): %w", err)
+		}
+		// the refunded money is paid out on the refund date, which is part of that day's cash-up
+		err = s.entityService.EnsurePaymentDatesNotInClosedCashUpDay(newCtx, []time.Time{spec.RefundDate})
+		if err != nil {
+			return fmt.Errorf("entityService.EnsurePaymentDatesNotInClosedCashUpDay(): %w", err)
+		}
+
+		// the previous refunds are included, so that an EnrollmentPayment cannot be refunded more than what has been received
+		refundTotal, err := qtx.GetEnrollmentPaymentRefundTotalByEnrollmentPaymentIdForUpdate(newCtx, prevEP.EnrollmentPaymentID)
+		if err != nil {
+			return fmt.Errorf("qtx.GetEnrollmentPaymentRefundTotalByEnrollmentPaymentIdForUpdate(): %w", err)
+		}
+		receivedValue := int64(prevEP.CourseFeeValue + prevEP.TransportFeeValue + prevEP.PenaltyFeeValue - prevEP.DiscountFeeValue)
+		if refundTotal.TotalRefundedValue+int64(spec.RefundedValue) > receivedValue {
+			return fmt.Errorf("refundedValue '%d' (previously refunded '%d') exceeds the received value '%d': %w", spec.RefundedValue, refundTotal.TotalRefundedValue, receivedValue, errs.ErrRefundExceedsEnrollmentPayment)
+		}
+		toppedUpQuota := float64(prevEP.BalanceTopUp + prevEP.BalanceBonus)
+		if refundTotal.TotalWithdrawnQuota+spec.WithdrawnQuota > toppedUpQuota+sltQuotaTolerance {
+			return fmt.Errorf("withdrawnQuota '%.2f' (previously withdrawn '%.2f') exceeds the topped-up quota '%.2f': %w", spec.WithdrawnQuota, refundTotal.TotalWithdrawnQuota, toppedUpQuota, errs.ErrRefundExceedsEnrollmentPayment)
+		}
+
+		newRefundID, err := qtx.InsertEnrollmentPaymentRefund(newCtx, mysql.InsertEnrollmentPaymentRefundParams{
+			RefundDate:          spec.RefundDate,
+			RefundedValue:       spec.RefundedValue,
+			WithdrawnQuota:      spec.WithdrawnQuota,
+			Reason:              spec.Reason,
+			CreatedByUserID:     sql.NullInt64{Int64: int64(authInfo.UserID), Valid: authInfo.UserID != identity.UserID_None},
+			EnrollmentPaymentID: prevEP.EnrollmentPaymentID,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.InsertEnrollmentPaymentRefund(): %w", err)
+		}
+		enrollmentPaymentRefundID = teaching.EnrollmentPaymentRefundID(newRefundID)
+
+		if spec.WithdrawnQuota == 0 {
+			return nil
+		}
+
+		updatedSLT, err := qtx.GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter(newCtx, mysql.GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarterParams{
+			EnrollmentID:             prevEP.StudentEnrollmentID.Int64,
+			CourseFeeQuarterValue:    teaching.CalculateSLTFeeQuarterFromEP(prevEP.CourseFeeValue, prevEP.BalanceTopUp),
+			TransportFeeQuarterValue: teaching.CalculateSLTFeeQuarterFromEP(prevEP.TransportFeeValue, prevEP.BalanceTopUp),
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				mainLog.Warn("EnrollmentPayment with ID='%d' doesn't have studentLearningToken, check for bad data possibility. Skipping to withdraw the refunded quota.", prevEP.EnrollmentPaymentID)
+				return nil
+			}
+			return fmt.Errorf("qtx.GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter(): %w", err)
+		}
+
+		// the quota may become negative when the refunded tokens have been used, which is then owed by the student
+		err = s.incrementSLTQuota(newCtx, entity.InsertSLTTransactionSpec{
+			StudentLearningTokenID: entity.StudentLearningTokenID(updatedSLT.ID),
+			QuotaChange:            -1 * spec.WithdrawnQuota,
+			Reason:                 entity.SLTTransactionReason_Refund,
+			SourceID:               newRefundID,
+		})
+		if err != nil {
+			return fmt.Errorf("incrementSLTQuota(): %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return []entity.Class{}, fmt.Errorf("entityService.GetClasses(): %v", err)
+		return teaching.EnrollmentPaymentRefundID_None, fmt.Errorf("ExecuteInTransaction(): %w", err)
 	}
 
-	return getClassResult.Classes, nil
+	return enrollmentPaymentRefundID, nil
 }
 
-func (s teachingServiceImpl) EditClassesConfigs(ctx context.Context, specs []teaching.EditClassConfigSpec) error {
+func (s teachingServiceImpl) GetEnrollmentPaymentRefunds(ctx context.Context, pagination util.PaginationSpec) (teaching.GetEnrollmentPaymentRefundsResult, error) {
+	pagination.SetDefaultOnInvalidValues()
+	limit, offset := pagination.GetLimitAndOffset()
+
+	var refundRows = make([]mysql.GetEnrollmentPaymentRefundsRow, 0)
+	var totalResults int64 = 0
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
-		for _, spec := range specs {
-			// no changes, skip the spec
-			if spec.IsDeactivated == nil && spec.AutoOweAttendanceToken == nil {
-				continue
-			}
-			var err error
-			classId := int64(spec.ClassID)
+		var err error
+		refundRows, err = qtx.GetEnrollmentPaymentRefunds(newCtx, mysql.GetEnrollmentPaymentRefundsParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetEnrollmentPaymentRefunds(): %w", err)
+		}
 
-			// route to corresponding SQL update query, based on available fields in the spec
-			if spec.IsDeactivated != nil && spec.AutoOweAttendanceToken != nil {
-				err = qtx.UpdateClassConfig(newCtx, mysql.UpdateClassConfigParams{
-					AutoOweAttendanceToken: util.BoolToInt32(*spec.AutoOweAttendanceToken),
-					IsDeactivated:          util.BoolToInt32(*spec.IsDeactivated),
-					ID:                     classId,
-				})
-				if err != nil {
-					return fmt.Errorf("qtx.UpdateClassConfig(): %w", err)
-				}
-			} else if spec.IsDeactivated != nil {
-				err = qtx.UpdateClassActivation(newCtx, mysql.UpdateClassActivationParams{
-					IsDeactivated: util.BoolToInt32(*spec.IsDeactivated),
-					ID:            classId,
-				})
-				if err != nil {
-					return fmt.Errorf("qtx.UpdateClassActivation(): %w", err)
-				}
-			} else if spec.AutoOweAttendanceToken != nil {
-				err = qtx.UpdateClassAutoOweToken(newCtx, mysql.UpdateClassAutoOweTokenParams{
-					AutoOweAttendanceToken: util.BoolToInt32(*spec.AutoOweAttendanceToken),
-					ID:                     classId,
-				})
-				if err != nil {
-					return fmt.Errorf("qtx.UpdateClassAutoOweToken(): %w", err)
-				}
-			}
+		totalResults, err = qtx.CountEnrollmentPaymentRefunds(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.CountEnrollmentPaymentRefunds(): %w", err)
 		}
 		return nil
 	})
 	if err != nil {
-		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+		return teaching.GetEnrollmentPaymentRefundsResult{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
 	}
 
-	return nil
+	enrollmentPaymentRefunds := NewEnrollmentPaymentRefundsFromGetEnrollmentPaymentRefundsRow(refundRows)
+
+	return teaching.GetEnrollmentPaymentRefundsResult{
+		EnrollmentPaymentRefunds: enrollmentPaymentRefunds,
+		PaginationResult:         *util.NewPaginationResult(int(totalResults), pagination.ResultsPerPage, pagination.Page),
+	}, nil
 }
-func (s teachingServiceImpl) EditClassesCourses(ctx context.Context, specs []teaching.EditClassCourseSpec) error {
-	// TODO(FerdiantJoshua): also automatically add new SLT, which correspond to the new course price.
-	// if it already exists, use IncrementSLTQuotaById(), with incrementedQuota==0, so that it becomes the latest SLT.
-	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
-		for _, spec := range specs {
-			err := qtx.UpdateClassCourse(newCtx, mysql.UpdateClassCourseParams{
-				CourseID: int64(spec.CourseID),
-				ID:       int64(spec.ClassID),
-			})
-			if err != nil {
-				return fmt.Errorf("qtx.UpdateClassCourse(): %w", err)
-			}
 
-			enrollments, err := s.entityService.GetStudentEnrollmentsByClassId(newCtx, spec.ClassID)
+func (s teachingServiceImpl) CreateInstallmentPlan(ctx context.Context, spec teaching.CreateInstallmentPlanSpec) (teaching.InstallmentPlanID, error) {
+	authInfo := network.GetAuthInfo(ctx)
+
+	courseCycles := spec.CourseCycles
+	if courseCycles <= 0 {
+		courseCycles = 1
+	}
+
+	var installmentPlanID teaching.InstallmentPlanID
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		invoice, err := s.GetEnrollmentPaymentInvoice(newCtx, spec.StudentEnrollmentID, spec.InvoiceDate, "")
+		if err != nil {
+			return fmt.Errorf("GetEnrollmentPaymentInvoice(): %w", err)
+		}
+
+		installmentPlan := teaching.InstallmentPlan{
+			BalanceTopUp:      invoice.BalanceTopUp * courseCycles,
+			BalanceBonus:      invoice.BalanceBonus * courseCycles,
+			CourseFeeValue:    invoice.CourseFeeValue * courseCycles,
+			TransportFeeValue: invoice.TransportFeeValue * courseCycles,
+			PenaltyFeeValue:   invoice.PenaltyFeeValue,
+			DiscountFeeValue:  invoice.DiscountFeeValue,
+			InstallmentCount:  int32(len(spec.DueDates)),
+		}
+		if installmentPlan.InstallmentCount > installmentPlan.BalanceTopUp {
+			return fmt.Errorf("installmentCount '%d' exceeds balanceTopUp '%d': %w", installmentPlan.InstallmentCount, installmentPlan.BalanceTopUp, errs.ErrInstallmentCountExceedsQuota)
+		}
+
+		newInstallmentPlanID, err := qtx.InsertInstallmentPlan(newCtx, mysql.InsertInstallmentPlanParams{
+			BalanceTopUp:      installmentPlan.BalanceTopUp,
+			BalanceBonus:      installmentPlan.BalanceBonus,
+			CourseFeeValue:    installmentPlan.CourseFeeValue,
+			TransportFeeValue: installmentPlan.TransportFeeValue,
+			PenaltyFeeValue:   installmentPlan.PenaltyFeeValue,
+			DiscountFeeValue:  installmentPlan.DiscountFeeValue,
+			InstallmentCount:  installmentPlan.InstallmentCount,
+			CreatedByUserID:   sql.NullInt64{Int64: int64(authInfo.UserID), Valid: authInfo.UserID != identity.UserID_None},
+			EnrollmentID:      int64(spec.StudentEnrollmentID),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.InsertInstallmentPlan(): %w", err)
+		}
+		installmentPlanID = teaching.InstallmentPlanID(newInstallmentPlanID)
+		installmentPlan.InstallmentPlanID = installmentPlanID
+
+		installments, err := teaching.CalculateInstallments(installmentPlan, spec.DueDates)
+		if err != nil {
+			return fmt.Errorf("CalculateInstallments(): %w", err)
+		}
+		for _, installment := range installments {
+			_, err := qtx.InsertInstallment(newCtx, mysql.InsertInstallmentParams{
+				InstallmentNumber: installment.InstallmentNumber,
+				DueDate:           installment.DueDate,
+				BalanceTopUp:      installment.BalanceTopUp,
+				BalanceBonus:      installment.BalanceBonus,
+				CourseFeeValue:    installment.CourseFeeValue,
+				TransportFeeValue: installment.TransportFeeValue,
+				PenaltyFeeValue:   installment.PenaltyFeeValue,
+				DiscountFeeValue:  installment.DiscountFeeValue,
+				InstallmentPlanID: newInstallmentPlanID,
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.InsertInstallment(installmentNumber='%d'): %w", installment.InstallmentNumber, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return teaching.InstallmentPlanID_None, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return installmentPlanID, nil
+}
+
+func (s teachingServiceImpl) GetInstallmentPlanById(ctx context.Context, id teaching.InstallmentPlanID) (teaching.InstallmentPlan, error) {
+	var installmentPlanRow mysql.InstallmentPlan
+	var installmentRows = make([]mysql.GetInstallmentsByInstallmentPlanIdRow, 0)
+	var studentEnrollment entity.StudentEnrollment
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		installmentPlanRow, err = qtx.GetInstallmentPlanById(newCtx, int64(id))
+		if err != nil {
+			return fmt.Errorf("qtx.GetInstallmentPlanById(): %w", err)
+		}
+
+		installmentRows, err = qtx.GetInstallmentsByInstallmentPlanId(newCtx, installmentPlanRow.ID)
+		if err != nil {
+			return fmt.Errorf("qtx.GetInstallmentsByInstallmentPlanId(): %w", err)
+		}
+
+		studentEnrollment, err = s.entityService.GetStudentEnrollmentById(newCtx, entity.StudentEnrollmentID(installmentPlanRow.EnrollmentID))
+		if err != nil {
+			return fmt.Errorf("entityService.GetStudentEnrollmentById(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return teaching.InstallmentPlan{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return NewInstallmentPlan(installmentPlanRow, installmentRows, studentEnrollment), nil
+}
+
+func (s teachingServiceImpl) PayInstallment(ctx context.Context, spec teaching.PayInstallmentSpec) (entity.EnrollmentPaymentID, error) {
+	var enrollmentPaymentID entity.EnrollmentPaymentID
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		installmentRow, err := qtx.GetInstallmentById(newCtx, int64(spec.InstallmentID))
+		if err != nil {
+			return fmt.Errorf("qtx.GetInstallmentById(): %w", err)
+		}
+		if installmentRow.EnrollmentPaymentID.Valid {
+			return fmt.Errorf("installmentID '%d' is paid by enrollmentPaymentID '%d': %w", installmentRow.ID, installmentRow.EnrollmentPaymentID.Int64, errs.ErrInstallmentAlreadyPaid)
+		}
+
+		// the nested ExecuteInTransaction() of SubmitEnrollmentPayment() reuses this transaction
+		enrollmentPaymentID, err = s.SubmitEnrollmentPayment(newCtx, teaching.SubmitStudentEnrollmentPaymentSpec{
+			StudentEnrollmentID: entity.StudentEnrollmentID(installmentRow.EnrollmentID),
+			PaymentDate:         spec.PaymentDate,
+			BalanceTopUp:        installmentRow.BalanceTopUp,
+			BalanceBonus:        installmentRow.BalanceBonus,
+			CourseFeeValue:      installmentRow.CourseFeeValue,
+			TransportFeeValue:   installmentRow.TransportFeeValue,
+			PenaltyFeeValue:     installmentRow.PenaltyFeeValue,
+			DiscountFeeValue:    installmentRow.DiscountFeeValue,
+			PaymentMethod:       spec.PaymentMethod,
+			ReceivingAccount:    spec.ReceivingAccount,
+			ReferenceNumber:     spec.ReferenceNumber,
+		})
+		if err != nil {
+			return fmt.Errorf("SubmitEnrollmentPayment(): %w", err)
+		}
+
+		affectedRows, err := qtx.UpdateUnpaidInstallmentEnrollmentPayment(newCtx, mysql.UpdateUnpaidInstallmentEnrollmentPaymentParams{
+			EnrollmentPaymentID: sql.NullInt64{Int64: int64(enrollmentPaymentID), Valid: true},
+			ID:                  installmentRow.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.UpdateUnpaidInstallmentEnrollmentPayment(): %w", err)
+		}
+		// the installment may have been paid by a concurrent request, after we checked it above
+		if affectedRows == 0 {
+			return fmt.Errorf("installmentID '%d': %w", installmentRow.ID, errs.ErrInstallmentAlreadyPaid)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.EnrollmentPaymentID_None, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return enrollmentPaymentID, nil
+}
+
+func (s teachingServiceImpl) GetOverdueInstallments(ctx context.Context, date time.Time, pagination util.PaginationSpec) (teaching.GetOverdueInstallmentsResult, error) {
+	if date.IsZero() {
+		date = time.Now()
+	}
+	pagination.SetDefaultOnInvalidValues()
+	limit, offset := pagination.GetLimitAndOffset()
+
+	var overdueInstallmentRows = make([]mysql.GetOverdueInstallmentsRow, 0)
+	var totalResults int64 = 0
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		overdueInstallmentRows, err = qtx.GetOverdueInstallments(newCtx, mysql.GetOverdueInstallmentsParams{
+			Date:   date,
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetOverdueInstallments(): %w", err)
+		}
+
+		totalResults, err = qtx.CountOverdueInstallments(newCtx, date)
+		if err != nil {
+			return fmt.Errorf("qtx.CountOverdueInstallments(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return teaching.GetOverdueInstallmentsResult{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	overdueInstallments := NewOverdueInstallmentsFromGetOverdueInstallmentsRow(overdueInstallmentRows, date)
+
+	return teaching.GetOverdueInstallmentsResult{
+		OverdueInstallments: overdueInstallments,
+		PaginationResult:    *util.NewPaginationResult(int(totalResults), pagination.ResultsPerPage, pagination.Page),
+	}, nil
+}
+
+func (s teachingServiceImpl) GetEnrollmentPaymentReceipt(ctx context.Context, enrollmentPaymentID entity.EnrollmentPaymentID) (teaching.EnrollmentPaymentReceipt, error) {
+	enrollmentPayment, err := s.entityService.GetEnrollmentPaymentById(ctx, enrollmentPaymentID)
+	if err != nil {
+		return teaching.EnrollmentPaymentReceipt{}, fmt.Errorf("entityService.GetEnrollmentPaymentById(): %w", err)
+	}
+	// the receipt is issued along with the EnrollmentPayment insertion, thus printing the receipt never takes a new receipt number
+	receipt, err := s.mySQLQueries.GetEnrollmentPaymentReceiptByEnrollmentPaymentId(ctx, sql.NullInt64{Int64: int64(enrollmentPaymentID), Valid: true})
+	if err != nil {
+		return teaching.EnrollmentPaymentReceipt{}, fmt.Errorf("mySQLQueries.GetEnrollmentPaymentReceiptByEnrollmentPaymentId(): %w", err)
+	}
+
+	return teaching.EnrollmentPaymentReceipt{
+		ReceiptNumber:     receipt.ReceiptNumber,
+		IssuedAt:          receipt.IssuedAt,
+		EnrollmentPayment: enrollmentPayment,
+	}, nil
+}
+
+func (s teachingServiceImpl) GetCashUpReport(ctx context.Context, date time.Time) (teaching.CashUpReport, error) {
+	if date.IsZero() {
+		date = time.Now()
+	}
+	localDate := date.In(util.DefaultTimezone)
+	startDatetime := time.Date(localDate.Year(), localDate.Month(), localDate.Day(), 0, 0, 0, 0, util.DefaultTimezone)
+	endDatetime := startDatetime.AddDate(0, 0, 1).Add(-time.Second)
+
+	report := teaching.CashUpReport{
+		Date:   startDatetime,
+		Groups: make([]teaching.CashUpReportGroup, 0),
+	}
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		totalRows, err := qtx.GetEnrollmentPaymentTotalsGroupedByPaymentMethod(newCtx, mysql.GetEnrollmentPaymentTotalsGroupedByPaymentMethodParams{
+			StartDate: startDatetime,
+			EndDate:   endDatetime,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetEnrollmentPaymentTotalsGroupedByPaymentMethod(): %w", err)
+		}
+		type groupKey struct {
+			paymentMethod    string
+			receivingAccount string
+		}
+		groupKeyToIdx := make(map[groupKey]int, 0)
+		for _, row := range totalRows {
+			groupKeyToIdx[groupKey{row.PaymentMethod, row.ReceivingAccount}] = len(report.Groups)
+			report.Groups = append(report.Groups, teaching.CashUpReportGroup{
+				PaymentMethod:    entity.PaymentMethod(row.PaymentMethod),
+				ReceivingAccount: row.ReceivingAccount,
+				TotalPayments:    row.TotalPayments,
+				TotalValue:       row.TotalValue,
+			})
+		}
+
+		refundTotalRows, err := qtx.GetEnrollmentPaymentRefundTotalsGroupedByPaymentMethod(newCtx, mysql.GetEnrollmentPaymentRefundTotalsGroupedByPaymentMethodParams{
+			StartDate: startDatetime,
+			EndDate:   endDatetime,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetEnrollmentPaymentRefundTotalsGroupedByPaymentMethod(): %w", err)
+		}
+		for _, row := range refundTotalRows {
+			idx, ok := groupKeyToIdx[groupKey{row.PaymentMethod, row.ReceivingAccount}]
+			if !ok {
+				report.Groups = append(report.Groups, teaching.CashUpReportGroup{
+					PaymentMethod:    entity.PaymentMethod(row.PaymentMethod),
+					ReceivingAccount: row.ReceivingAccount,
+				})
+				idx = len(report.Groups) - 1
+			}
+			report.Groups[idx].TotalRefunds = row.TotalRefunds
+			report.Groups[idx].TotalRefundedValue = row.TotalRefundedValue
+		}
+		sort.SliceStable(report.Groups, func(i, j int) bool {
+			if report.Groups[i].PaymentMethod != report.Groups[j].PaymentMethod {
+				return report.Groups[i].PaymentMethod < report.Groups[j].PaymentMethod
+			}
+			return report.Groups[i].ReceivingAccount < report.Groups[j].ReceivingAccount
+		})
+
+		for _, group := range report.Groups {
+			if group.PaymentMethod == entity.PaymentMethod_Cash {
+				report.RecordedCashValue += group.TotalValue - group.TotalRefundedValue
+			}
+		}
+
+		cashUpDay, err := s.entityService.GetCashUpDayByDate(newCtx, startDatetime)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("entityService.GetCashUpDayByDate(): %w", err)
+		}
+		if err == nil {
+			report.CashUpDay = &cashUpDay
+		}
+
+		return nil
+	})
+	if err != nil {
+		return teaching.CashUpReport{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return report, nil
+}
+
+func (s teachingServiceImpl) CloseCashUpDay(ctx context.Context, spec teaching.CloseCashUpDaySpec) (entity.CashUpDay, error) {
+	var cashUpDay entity.CashUpDay
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		report, err := s.GetCashUpReport(newCtx, spec.Date)
+		if err != nil {
+			return fmt.Errorf("GetCashUpReport(): %w", err)
+		}
+
+		_, err = s.entityService.InsertCashUpDay(newCtx, entity.InsertCashUpDaySpec{
+			Date:              report.Date,
+			CountedCashValue:  spec.CountedCashValue,
+			RecordedCashValue: report.RecordedCashValue,
+			Note:              spec.Note,
+		})
+		if err != nil {
+			return fmt.Errorf("entityService.InsertCashUpDay(): %w", err)
+		}
+
+		cashUpDay, err = s.entityService.GetCashUpDayByDate(newCtx, report.Date)
+		if err != nil {
+			return fmt.Errorf("entityService.GetCashUpDayByDate(): %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return entity.CashUpDay{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return cashUpDay, nil
+}
+
+func (s teachingServiceImpl) ReopenCashUpDay(ctx context.Context, date time.Time) error {
+	err := s.entityService.DeleteCashUpDayByDate(ctx, date)
+	if err != nil {
+		return fmt.Errorf("entityService.DeleteCashUpDayByDate(): %w", err)
+	}
+
+	return nil
+}
+
+func (s teachingServiceImpl) SendPaymentReminders(ctx context.Context, spec teaching.SendPaymentRemindersSpec) (teaching.SendPaymentRemindersResult, error) {
+	studentEnrollmentRows, err := s.mySQLQueries.GetStudentEnrollmentsForPaymentReminder(ctx)
+	if err != nil {
+		return teaching.SendPaymentRemindersResult{}, fmt.Errorf("mySQLQueries.GetStudentEnrollmentsForPaymentReminder(): %w", err)
+	}
+
+	now := time.Now().UTC()
+	result := teaching.SendPaymentRemindersResult{
+		Reminders: make([]teaching.PaymentReminder, 0),
+		Failures:  make([]teaching.PaymentReminderFailure, 0),
+	}
+	// each reminder is sent in its own transaction, so that a failing email doesn't cancel the records of the other sent emails
+	for _, studentEnrollmentRow := range studentEnrollmentRows {
+		studentEnrollmentID := entity.StudentEnrollmentID(studentEnrollmentRow.StudentEnrollmentID)
+		reminder, err := s.sendPaymentReminder(ctx, studentEnrollmentID, studentEnrollmentRow.OwedQuota, spec, now)
+		if err != nil {
+			mainLog.Warn("Failed to send payment reminder of studentEnrollmentID='%d': %v", studentEnrollmentID, err)
+			reason := "Failed to send the email"
+			if errors.Is(err, errs.ErrUserEmailNotSet) {
+				reason = "Neither the student nor the parent has an email"
+			}
+			result.Failures = append(result.Failures, teaching.PaymentReminderFailure{
+				StudentEnrollmentID: studentEnrollmentID,
+				Reason:              reason,
+			})
+			continue
+		}
+		if reminder != nil {
+			result.Reminders = append(result.Reminders, *reminder)
+		}
+	}
+
+	return result, nil
+}
+
+// sendPaymentReminder returns nil PaymentReminder when the StudentEnrollment doesn't need to be reminded (yet).
+func (s teachingServiceImpl) sendPaymentReminder(ctx context.Context, studentEnrollmentID entity.StudentEnrollmentID, owedQuota float64, spec teaching.SendPaymentRemindersSpec, now time.Time) (*teaching.PaymentReminder, error) {
+	var reminder *teaching.PaymentReminder = nil
+	var subject, body string
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		latestSentAt, err := qtx.GetLatestPaymentReminderSentAtByEnrollmentId(newCtx, int64(studentEnrollmentID))
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("qtx.GetLatestPaymentReminderSentAtByEnrollmentId(): %w", err)
+			}
+		} else if now.Sub(latestSentAt) < spec.Cadence {
+			return nil
+		}
+
+		invoice, err := s.GetEnrollmentPaymentInvoice(newCtx, studentEnrollmentID, now, "")
+		if err != nil {
+			return fmt.Errorf("GetEnrollmentPaymentInvoice(): %w", err)
+		}
+
+		// a nil LastPaymentDate means the enrollment has never been paid, which has no due date
+		var reminderType teaching.PaymentReminderType
+		switch {
+		case owedQuota < 0:
+			reminderType = teaching.PaymentReminderType_Owing
+		case invoice.LastPaymentDate != nil && invoice.DaysLate > 0:
+			reminderType = teaching.PaymentReminderType_Overdue
+		case invoice.LastPaymentDate != nil && invoice.DaysLate >= -spec.DaysBeforePenalty:
+			reminderType = teaching.PaymentReminderType_PenaltyApproaching
+		default:
+			return nil
+		}
+
+		studentEnrollment, err := s.entityService.GetStudentEnrollmentById(newCtx, studentEnrollmentID)
+		if err != nil {
+			return fmt.Errorf("entityService.GetStudentEnrollmentById(): %w", err)
+		}
+		student, err := s.entityService.GetStudentById(newCtx, studentEnrollment.StudentInfo.StudentID)
+		if err != nil {
+			return fmt.Errorf("entityService.GetStudentById(): %w", err)
+		}
+
+		recipientName := student.User.UserDetail.String()
+		recipientEmail := student.User.Email
+		if student.User.UserDetail.ParentEmail != "" {
+			recipientEmail = student.User.UserDetail.ParentEmail
+			if student.User.UserDetail.ParentName != "" {
+				recipientName = student.User.UserDetail.ParentName
+			}
+		}
+		if recipientEmail == "" {
+			return fmt.Errorf("studentId='%d': %w", student.StudentID, errs.ErrUserEmailNotSet)
+		}
+
+		reminder = &teaching.PaymentReminder{
+			StudentEnrollmentID: studentEnrollmentID,
+			StudentInfo:         studentEnrollment.StudentInfo,
+			Type:                reminderType,
+			RecipientEmail:      recipientEmail,
+			OwedQuota:           owedQuota,
+			CourseFeeValue:      invoice.CourseFeeValue,
+			TransportFeeValue:   invoice.TransportFeeValue,
+			PenaltyFeeValue:     invoice.PenaltyFeeValue,
+			DiscountFeeValue:    invoice.DiscountFeeValue,
+			SentAt:              now,
+		}
+		if spec.DryRun {
+			return nil
+		}
+
+		reminderTemplate := email_composer.NewPaymentReminder(recipientName, studentEnrollment, reminderType, owedQuota, invoice)
+		subject = reminderTemplate.Subject()
+		body, err = s.emailComposer.GenerateHTML(reminderTemplate.Email())
+		if err != nil {
+			return fmt.Errorf("emailComposer.GenerateHTML(): %w", err)
+		}
+
+		// the record is committed before sending the email, so that a concurrent run sees it & doesn't send a duplicate reminder
+		paymentReminderID, err := qtx.InsertPaymentReminder(newCtx, mysql.InsertPaymentReminderParams{
+			ReminderType:      string(reminder.Type),
+			RecipientEmail:    reminder.RecipientEmail,
+			OwedQuota:         reminder.OwedQuota,
+			CourseFeeValue:    reminder.CourseFeeValue,
+			TransportFeeValue: reminder.TransportFeeValue,
+			PenaltyFeeValue:   reminder.PenaltyFeeValue,
+			DiscountFeeValue:  reminder.DiscountFeeValue,
+			SentAt:            reminder.SentAt,
+			EnrollmentID:      int64(studentEnrollmentID),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.InsertPaymentReminder(): %w", err)
+		}
+		reminder.PaymentReminderID = teaching.PaymentReminderID(paymentReminderID)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+	if reminder == nil || spec.DryRun {
+		return reminder, nil
+	}
+
+	err = s.smtpAccessor.SendEmail(true, "", []string{reminder.RecipientEmail}, subject, body)
+	if err != nil {
+		// the record is removed, so that the reminder is retried on the next run instead of waiting for the next cadence
+		errDelete := s.mySQLQueries.DeletePaymentReminderById(ctx, int64(reminder.PaymentReminderID))
+		if errDelete != nil {
+			mainLog.Error("Failed to delete the unsent payment reminder of paymentReminderID='%d': %v", reminder.PaymentReminderID, errDelete)
+		}
+		return nil, fmt.Errorf("SendEmail(): %w", err)
+	}
+
+	return reminder, nil
+}
+
+func (s teachingServiceImpl) GetPaymentReminders(ctx context.Context, pagination util.PaginationSpec) (teaching.GetPaymentRemindersResult, error) {
+	pagination.SetDefaultOnInvalidValues()
+	limit, offset := pagination.GetLimitAndOffset()
+
+	var paymentReminderRows = make([]mysql.GetPaymentRemindersRow, 0)
+	var totalResults int64 = 0
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		paymentReminderRows, err = qtx.GetPaymentReminders(newCtx, mysql.GetPaymentRemindersParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetPaymentReminders(): %w", err)
+		}
+
+		totalResults, err = qtx.CountPaymentReminders(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.CountPaymentReminders(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return teaching.GetPaymentRemindersResult{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	paymentReminders := NewPaymentRemindersFromGetPaymentRemindersRow(paymentReminderRows)
+
+	return teaching.GetPaymentRemindersResult{
+		PaymentReminders: paymentReminders,
+		PaginationResult: *util.NewPaginationResult(int(totalResults), pagination.ResultsPerPage, pagination.Page),
+	}, nil
+}
+
+func (s teachingServiceImpl) GetPaymentReminderOptOuts(ctx context.Context) ([]teaching.PaymentReminderOptOut, error) {
+	optOutRows, err := s.mySQLQueries.GetPaymentReminderOptOuts(ctx)
+	if err != nil {
+		return []teaching.PaymentReminderOptOut{}, fmt.Errorf("mySQLQueries.GetPaymentReminderOptOuts(): %w", err)
+	}
+
+	return NewPaymentReminderOptOutsFromGetPaymentReminderOptOutsRow(optOutRows), nil
+}
+
+func (s teachingServiceImpl) SetPaymentReminderOptOut(ctx context.Context, studentIDs []entity.StudentID, isOptedOut bool) error {
+	authInfo := network.GetAuthInfo(ctx)
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		if !isOptedOut {
+			studentIDsInt64 := make([]int64, 0, len(studentIDs))
+			for _, studentID := range studentIDs {
+				studentIDsInt64 = append(studentIDsInt64, int64(studentID))
+			}
+			err := qtx.DeletePaymentReminderOptOutsByStudentIds(newCtx, studentIDsInt64)
+			if err != nil {
+				return fmt.Errorf("qtx.DeletePaymentReminderOptOutsByStudentIds(): %w", err)
+			}
+			return nil
+		}
+
+		for _, studentID := range studentIDs {
+			err := qtx.InsertPaymentReminderOptOut(newCtx, mysql.InsertPaymentReminderOptOutParams{
+				StudentID:        int64(studentID),
+				OptedOutByUserID: sql.NullInt64{Int64: int64(authInfo.UserID), Valid: authInfo.UserID != identity.UserID_None},
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.InsertPaymentReminderOptOut(): %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
+
+func (s teachingServiceImpl) SearchClass(ctx context.Context, spec teaching.SearchClassSpec) ([]entity.Class, error) {
+	paginationSpec := util.PaginationSpec{
+		Page:           pagination_FirstPage,
+		ResultsPerPage: pagination_FetchAll,
+	}
+	getClassesSpec := entity.GetClassesSpec{
+		IncludeDeactivated: true,
+		TeacherID:          spec.TeacherID,
+		StudentID:          spec.StudentID,
+		CourseID:           spec.CourseID,
+		PrettySorting:      true,
+	}
+	getClassResult, err := s.entityService.GetClasses(ctx, paginationSpec, getClassesSpec)
+	if err != nil {
+		return []entity.Class{}, fmt.Errorf("entityService.GetClasses(): %v", err)
+	}
+
+	return getClassResult.Classes, nil
+}
+
+func (s teachingServiceImpl) EditClassesConfigs(ctx context.Context, specs []teaching.EditClassConfigSpec) error {
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		for _, spec := range specs {
+			// no changes, skip the spec
+			if spec.IsDeactivated == nil && spec.AutoOweAttendanceToken == nil {
+				continue
+			}
+			var err error
+			classId := int64(spec.ClassID)
+
+			// route to corresponding SQL update query, based on available fields in the spec
+			if spec.IsDeactivated != nil && spec.AutoOweAttendanceToken != nil {
+				err = qtx.UpdateClassConfig(newCtx, mysql.UpdateClassConfigParams{
+					AutoOweAttendanceToken: util.BoolToInt32(*spec.AutoOweAttendanceToken),
+					IsDeactivated:          util.BoolToInt32(*spec.IsDeactivated),
+					ID:                     classId,
+				})
+				if err != nil {
+					return fmt.Errorf("qtx.UpdateClassConfig(): %w", err)
+				}
+			} else if spec.IsDeactivated != nil {
+				err = qtx.UpdateClassActivation(newCtx, mysql.UpdateClassActivationParams{
+					IsDeactivated: util.BoolToInt32(*spec.IsDeactivated),
+					ID:            classId,
+				})
+				if err != nil {
+					return fmt.Errorf("qtx.UpdateClassActivation(): %w", err)
+				}
+			} else if spec.AutoOweAttendanceToken != nil {
+				err = qtx.UpdateClassAutoOweToken(newCtx, mysql.UpdateClassAutoOweTokenParams{
+					AutoOweAttendanceToken: util.BoolToInt32(*spec.AutoOweAttendanceToken),
+					ID:                     classId,
+				})
+				if err != nil {
+					return fmt.Errorf("qtx.UpdateClassAutoOweToken(): %w", err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
+func (s teachingServiceImpl) EditClassesCourses(ctx context.Context, specs []teaching.EditClassCourseSpec) error {
+	// TODO(FerdiantJoshua): also automatically add new SLT, which correspond to the new course price.
+	// if it already exists, use IncrementSLTQuotaById(), with incrementedQuota==0, so that it becomes the latest SLT.
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		for _, spec := range specs {
+			err := qtx.UpdateClassCourse(newCtx, mysql.UpdateClassCourseParams{
+				CourseID: int64(spec.CourseID),
+				ID:       int64(spec.ClassID),
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.UpdateClassCourse(): %w", err)
+			}
+
+			enrollments, err := s.entityService.GetStudentEnrollmentsByClassId(newCtx, spec.ClassID)
+			if err != nil {
+				return fmt.Errorf("entityService.GetStudentEnrollmentsByClassId(): %w", err)
+			}
+
+			for _, enrollment := range enrollments {
+				fmt.Printf("enrollmentClassInfo: %#v\n", enrollment.ClassInfo)
+				courseFee := enrollment.ClassInfo.Course.DefaultFee
+				if enrollment.ClassInfo.TeacherSpecialFee != 0 {
+					courseFee = enrollment.ClassInfo.TeacherSpecialFee
+				}
+				existingSLT, err := qtx.GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter(newCtx, mysql.GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarterParams{
+					EnrollmentID:             int64(enrollment.StudentEnrollmentID),
+					CourseFeeQuarterValue:    courseFee / teaching.Default_OneCourseCycle,
+					TransportFeeQuarterValue: enrollment.ClassInfo.TransportFee / teaching.Default_OneCourseCycle,
+				})
+				isNeedInsert := false
+				if errors.Is(err, sql.ErrNoRows) {
+					isNeedInsert = true
+					err = nil
+				} else if err != nil {
+					return fmt.Errorf("qtx.GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter(): %w", err)
+				}
+
+				if isNeedInsert {
+					// we don't need the newly added SLT ID, so we can safely ignore it
+					_, err := s.autoRegisterSLT(newCtx, entity.StudentEnrollmentID(enrollment.StudentEnrollmentID), 0)
+					if err != nil {
+						return fmt.Errorf("autoRegisterSLT(): %w", err)
+					}
+				} else {
+					// the goal is to set token's LastUpdatedAt to current date
+					err := s.incrementSLTQuota(newCtx, entity.InsertSLTTransactionSpec{
+						StudentLearningTokenID: entity.StudentLearningTokenID(existingSLT.ID),
+						QuotaChange:            0,
+						Reason:                 entity.SLTTransactionReason_Manual,
+					})
+					if err != nil {
+						return fmt.Errorf("incrementSLTQuota(): %w", err)
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
+
+func (s teachingServiceImpl) LevelUpClass(ctx context.Context, spec teaching.LevelUpClassSpec) (teaching.ClassLevelUp, error) {
+	effectiveDate := util.ToLocalDate(spec.EffectiveDate)
+	// the old-price quota is moved into the new token right away, thus a future level-up would charge the new price before it takes effect
+	if effectiveDate.After(util.ToLocalDate(time.Now())) {
+		return teaching.ClassLevelUp{}, fmt.Errorf("effectiveDate '%s': %w", effectiveDate.Format("2006-01-02"), errs.ErrLevelUpEffectiveDateInFuture)
+	}
+
+	classLevelUp := teaching.ClassLevelUp{
+		ClassID:       spec.ClassID,
+		NewCourseID:   spec.CourseID,
+		EffectiveDate: effectiveDate,
+		Students:      make([]teaching.StudentLevelUp, 0),
+	}
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		class, err := s.entityService.GetClassById(newCtx, spec.ClassID)
+		if err != nil {
+			return fmt.Errorf("entityService.GetClassById(): %w", err)
+		}
+		if class.Course.CourseID == spec.CourseID {
+			return fmt.Errorf("classID='%d', courseID='%d': %w", spec.ClassID, spec.CourseID, errs.ErrClassAlreadyInCourse)
+		}
+		classLevelUp.PrevCourseID = class.Course.CourseID
+
+		err = qtx.UpdateClassCourse(newCtx, mysql.UpdateClassCourseParams{
+			CourseID: int64(spec.CourseID),
+			ID:       int64(spec.ClassID),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.UpdateClassCourse(): %w", err)
+		}
+
+		teacherID := entity.TeacherID_None
+		if class.TeacherInfo_Minimal != nil {
+			teacherID = class.TeacherInfo_Minimal.TeacherID
+		}
+		newCourseFeeValue, err := s.entityService.GetCourseFeeValueAt(newCtx, spec.CourseID, teacherID, effectiveDate)
+		if err != nil {
+			return fmt.Errorf("entityService.GetCourseFeeValueAt(): %w", err)
+		}
+		newCourseFeeQuarterValue := teaching.CalculateSLTFeeQuarterFromEP(newCourseFeeValue, teaching.Default_BalanceTopUp)
+		transportFeeQuarterValue := teaching.CalculateSLTFeeQuarterFromEP(class.TransportFee, teaching.Default_BalanceTopUp)
+
+		enrollments, err := s.entityService.GetStudentEnrollmentsByClassId(newCtx, spec.ClassID)
+		if err != nil {
+			return fmt.Errorf("entityService.GetStudentEnrollmentsByClassId(): %w", err)
+		}
+
+		note := fmt.Sprintf("level-up of class #%d from %s", spec.ClassID, effectiveDate.Format("2006-01-02"))
+		for _, enrollment := range enrollments {
+			studentLevelUp := teaching.StudentLevelUp{
+				StudentEnrollmentID:      enrollment.StudentEnrollmentID,
+				StudentID:                enrollment.StudentInfo.StudentID,
+				NewCourseFeeQuarterValue: newCourseFeeQuarterValue,
+				SLTRepricings:            make([]teaching.SLTRepricing, 0),
+			}
+
+			studentLevelUp.NewStudentLearningTokenID, err = s.getOrInsertEmptySLT(newCtx, enrollment.StudentEnrollmentID, newCourseFeeQuarterValue, transportFeeQuarterValue)
+			if err != nil {
+				return fmt.Errorf("getOrInsertEmptySLT(): %w", err)
+			}
+
+			sltRows, err := qtx.GetStudentLearningTokensByEnrollmentId(newCtx, int64(enrollment.StudentEnrollmentID))
+			if err != nil {
+				return fmt.Errorf("qtx.GetStudentLearningTokensByEnrollmentId(): %w", err)
+			}
+			for _, sltRow := range sltRows {
+				if sltRow.StudentLearningTokenID == int64(studentLevelUp.NewStudentLearningTokenID) {
+					continue
+				}
+
+				// paid attendances can't be relinked by splitSLTAtDate(), which would leave them on the old price
+				paidAttendanceIDs, err := qtx.GetPaidAttendanceIdsByTokenIdFromDate(newCtx, mysql.GetPaidAttendanceIdsByTokenIdFromDateParams{
+					TokenID: sql.NullInt64{Int64: sltRow.StudentLearningTokenID, Valid: true},
+					Date:    effectiveDate,
+				})
+				if err != nil {
+					return fmt.Errorf("qtx.GetPaidAttendanceIdsByTokenIdFromDate(): %w", err)
+				}
+				if len(paidAttendanceIDs) > 0 {
+					return fmt.Errorf("attendanceIDs '%v' since effectiveDate '%s': %w", paidAttendanceIDs, effectiveDate.Format("2006-01-02"), errs.ErrModifyingPaidAttendance)
+				}
+
+				sltRepricing, err := s.splitSLTAtDate(newCtx, teaching.SLTRepricing{
+					SLTTransfer: teaching.SLTTransfer{
+						SourceStudentLearningTokenID:      entity.StudentLearningTokenID(sltRow.StudentLearningTokenID),
+						DestinationStudentLearningTokenID: studentLevelUp.NewStudentLearningTokenID,
+					},
+					StudentEnrollmentID:       enrollment.StudentEnrollmentID,
+					PrevCourseFeeQuarterValue: sltRow.CourseFeeQuarterValue,
+					NewCourseFeeQuarterValue:  newCourseFeeQuarterValue,
+				}, effectiveDate, spec.IsQuotaConverted, note)
+				if err != nil {
+					return fmt.Errorf("splitSLTAtDate(): %w", err)
+				}
+				if sltRepricing.SLTTransferID == 0 && len(sltRepricing.RelinkedAttendanceIDs) == 0 {
+					continue
+				}
+				studentLevelUp.SLTRepricings = append(studentLevelUp.SLTRepricings, sltRepricing)
+			}
+
+			// the goal is to set the new token's LastUpdatedAt to current date, so that it becomes the latest SLT
+			err = s.incrementSLTQuota(newCtx, entity.InsertSLTTransactionSpec{
+				StudentLearningTokenID: studentLevelUp.NewStudentLearningTokenID,
+				QuotaChange:            0,
+				Reason:                 entity.SLTTransactionReason_Manual,
+			})
+			if err != nil {
+				return fmt.Errorf("incrementSLTQuota(): %w", err)
+			}
+
+			classLevelUp.Students = append(classLevelUp.Students, studentLevelUp)
+		}
+		return nil
+	})
+	if err != nil {
+		return teaching.ClassLevelUp{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return classLevelUp, nil
+}
+
+func (s teachingServiceImpl) PreviewLevelUpClass(ctx context.Context, spec teaching.LevelUpClassSpec) (teaching.ClassLevelUp, error) {
+	var classLevelUp teaching.ClassLevelUp
+	err := s.mySQLQueries.ExecuteInDryRunTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		classLevelUp, err = s.LevelUpClass(newCtx, spec)
+		return err
+	})
+	if err != nil {
+		return teaching.ClassLevelUp{}, fmt.Errorf("ExecuteInDryRunTransaction(): %w", err)
+	}
+
+	return classLevelUp, nil
+}
+
+func (s teachingServiceImpl) GetSLTsByClassID(ctx context.Context, classID entity.ClassID) ([]teaching.StudentIDToSLTs, error) {
+	studentLearningTokenRows, err := s.mySQLQueries.GetSLTByClassIdForAttendanceInfo(ctx, int64(classID))
+	if err != nil {
+		return []teaching.StudentIDToSLTs{}, fmt.Errorf("mySQLQueries.GetSLTByClassIdForAttendanceInfo(): %w", err)
+	}
+
+	studentIdToSlts := make(map[entity.StudentID][]entity.StudentLearningToken_Minimal, 1)
+	for _, sltRow := range studentLearningTokenRows {
+		studentId := entity.StudentID(sltRow.StudentID)
+		// TODO: refactor this, as this in a non-standard way of converting mysql's rows into Entity's struct. Check existing result_constructor.go(s) for reference.
+		slt := entity.StudentLearningToken_Minimal{
+			StudentLearningTokenID: entity.StudentLearningTokenID(sltRow.StudentLearningTokenID),
+			Quota:                  sltRow.Quota,
+			CourseFeeValue:         sltRow.CourseFeeQuarterValue * 4,
+			TransportFeeValue:      sltRow.TransportFeeQuarterValue * 4,
+			CreatedAt:              sltRow.CreatedAt,
+			LastUpdatedAt:          sltRow.LastUpdatedAt,
+		}
+
+		currSLTs, ok := studentIdToSlts[studentId]
+		if !ok {
+			studentIdToSlts[studentId] = []entity.StudentLearningToken_Minimal{slt}
+		} else {
+			studentIdToSlts[studentId] = append(currSLTs, slt)
+		}
+	}
+
+	getSLTsByClassIDResults := make([]teaching.StudentIDToSLTs, 0, len(studentIdToSlts))
+	for studentId, slts := range studentIdToSlts {
+		getSLTsByClassIDResults = append(getSLTsByClassIDResults, teaching.StudentIDToSLTs{
+			StudentID:             studentId,
+			StudentLearningTokens: slts,
+		})
+	}
+
+	return getSLTsByClassIDResults, nil
+}
+
+func (s teachingServiceImpl) GetSLTHistory(ctx context.Context, sltID entity.StudentLearningTokenID) (teaching.StudentLearningTokenHistory, error) {
+	slt, err := s.entityService.GetStudentLearningTokenById(ctx, sltID)
+	if err != nil {
+		return teaching.StudentLearningTokenHistory{}, fmt.Errorf("entityService.GetStudentLearningTokenById(): %w", err)
+	}
+
+	sltTransactions, err := s.entityService.GetSLTTransactionsByStudentLearningTokenId(ctx, sltID)
+	if err != nil {
+		return teaching.StudentLearningTokenHistory{}, fmt.Errorf("entityService.GetSLTTransactionsByStudentLearningTokenId(): %w", err)
+	}
+
+	// the quota is stored with 3 decimal places (see query "IncrementSLTQuotaById"), so we round the running balance the same way
+	var balance float64 = 0
+	transactions := make([]teaching.SLTTransactionWithBalance, 0, len(sltTransactions))
+	for _, sltTransaction := range sltTransactions {
+		balance = math.Round((balance+sltTransaction.QuotaChange)*1000) / 1000
+		transactions = append(transactions, teaching.SLTTransactionWithBalance{
+			SLTTransaction: sltTransaction,
+			Balance:        balance,
+		})
+	}
+
+	return teaching.StudentLearningTokenHistory{
+		StudentLearningToken: slt,
+		Transactions:         transactions,
+		LedgerQuota:          balance,
+		IsConsistent:         math.Abs(slt.Quota-balance) < sltQuotaTolerance,
+	}, nil
+}
+
+func (s teachingServiceImpl) ReconcileSLTQuotas(ctx context.Context, spec teaching.ReconcileSLTQuotasSpec) (teaching.SLTReconciliationReport, error) {
+	report := teaching.SLTReconciliationReport{
+		Drifts:                        make([]teaching.SLTQuotaDrift, 0),
+		UnmatchedEnrollmentPaymentIDs: make([]entity.EnrollmentPaymentID, 0),
+	}
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		sltRows, err := qtx.GetSLTsForReconciliation(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.GetSLTsForReconciliation(): %w", err)
+		}
+		enrollmentPaymentRows, err := qtx.GetEnrollmentPaymentsForSLTReconciliation(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.GetEnrollmentPaymentsForSLTReconciliation(): %w", err)
+		}
+		usedQuotaRows, err := qtx.GetAttendancesUsedQuotaGroupedByTokenId(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.GetAttendancesUsedQuotaGroupedByTokenId(): %w", err)
+		}
+
+		// an EnrollmentPayment tops up the SLT with the same enrollment & fee quarter values, check SubmitEnrollmentPayment() for more information.
+		type sltKey struct {
+			enrollmentID             int64
+			courseFeeQuarterValue    int32
+			transportFeeQuarterValue int32
+		}
+		sltKeyToSLTID := make(map[sltKey]int64, len(sltRows))
+		for _, sltRow := range sltRows {
+			key := sltKey{sltRow.EnrollmentID, sltRow.CourseFeeQuarterValue, sltRow.TransportFeeQuarterValue}
+			// on duplicated keys, pick the earliest SLT, similar to qtx.GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter()
+			if _, ok := sltKeyToSLTID[key]; !ok {
+				sltKeyToSLTID[key] = sltRow.ID
+			}
+		}
+
+		sltIDToPaidQuota := make(map[int64]float64, len(sltRows))
+		for _, epRow := range enrollmentPaymentRows {
+			key := sltKey{
+				enrollmentID:             epRow.EnrollmentID.Int64,
+				courseFeeQuarterValue:    teaching.CalculateSLTFeeQuarterFromEP(epRow.CourseFeeValue, epRow.BalanceTopUp),
+				transportFeeQuarterValue: teaching.CalculateSLTFeeQuarterFromEP(epRow.TransportFeeValue, epRow.BalanceTopUp),
+			}
+			sltID, ok := sltKeyToSLTID[key]
+			if !ok {
+				report.UnmatchedEnrollmentPaymentIDs = append(report.UnmatchedEnrollmentPaymentIDs, entity.EnrollmentPaymentID(epRow.ID))
+				continue
+			}
+			sltIDToPaidQuota[sltID] += float64(epRow.BalanceTopUp + epRow.BalanceBonus)
+		}
+
+		sltIDToUsedQuota := make(map[int64]float64, len(usedQuotaRows))
+		for _, usedQuotaRow := range usedQuotaRows {
+			sltIDToUsedQuota[usedQuotaRow.TokenID.Int64] = usedQuotaRow.TotalUsedQuota
+		}
+
+		// quota which is changed without EnrollmentPayment nor Attendance (e.g. transferred via TransferStudentLearningTokens(), withdrawn via RefundEnrollmentPayment(),
+		// or corrected manually via UpdateStudentLearningTokens()) can only be recomputed from the ledger. Thus, we include every ledger reason, except the ones recomputed above:
+		//   - PAYMENT & ATTENDANCE are recomputed from the EnrollmentPayments & Attendances
+		//   - MIGRATION is the opening balance, which was calculated from the EnrollmentPayments & Attendances prior to the ledger
+		//   - RECONCILIATION is the previous repairs of the drift itself
+		excludedLedgerReasons := []string{
+			string(entity.SLTTransactionReason_Payment),
+			string(entity.SLTTransactionReason_Attendance),
+			string(entity.SLTTransactionReason_Migration),
+			string(entity.SLTTransactionReason_Reconciliation),
+		}
+		ledgerQuotaRows, err := qtx.GetSLTTransactionsQuotaChangeGroupedByTokenIdExcludingReasons(newCtx, excludedLedgerReasons)
+		if err != nil {
+			return fmt.Errorf("qtx.GetSLTTransactionsQuotaChangeGroupedByTokenIdExcludingReasons(): %w", err)
+		}
+		for _, ledgerQuotaRow := range ledgerQuotaRows {
+			sltIDToPaidQuota[ledgerQuotaRow.TokenID] += ledgerQuotaRow.TotalQuotaChange
+		}
+
+		for _, sltRow := range sltRows {
+			paidQuota := sltIDToPaidQuota[sltRow.ID]
+			usedQuota := sltIDToUsedQuota[sltRow.ID]
+			expectedQuota := math.Round((paidQuota-usedQuota)*1000) / 1000
+			if math.Abs(sltRow.Quota-expectedQuota) < sltQuotaTolerance {
+				continue
+			}
+			report.Drifts = append(report.Drifts, teaching.SLTQuotaDrift{
+				StudentLearningTokenID: entity.StudentLearningTokenID(sltRow.ID),
+				StudentEnrollmentID:    entity.StudentEnrollmentID(sltRow.EnrollmentID),
+				StoredQuota:            sltRow.Quota,
+				ExpectedQuota:          expectedQuota,
+				PaidQuota:              paidQuota,
+				UsedQuota:              usedQuota,
+			})
+		}
+		report.TotalCheckedTokens = len(sltRows)
+
+		if !spec.Repair || len(report.Drifts) == 0 {
+			return nil
+		}
+
+		for _, drift := range report.Drifts {
+			err = s.incrementSLTQuota(newCtx, entity.InsertSLTTransactionSpec{
+				StudentLearningTokenID: drift.StudentLearningTokenID,
+				QuotaChange:            drift.ExpectedQuota - drift.StoredQuota,
+				Reason:                 entity.SLTTransactionReason_Reconciliation,
+			})
+			if err != nil {
+				return fmt.Errorf("incrementSLTQuota(): %w", err)
+			}
+		}
+
+		repairedDrifts, err := json.Marshal(report.Drifts)
+		if err != nil {
+			return fmt.Errorf("json.Marshal(): %w", err)
+		}
+		authInfo := network.GetAuthInfo(newCtx)
+		_, err = s.userActionLogService.InsertUserActionLogs(newCtx, []user_action_log.InsertUserActionLogSpec{
+			{
+				Date:          time.Now(),
+				UserID:        authInfo.UserID,
+				PrivilegeType: authInfo.PrivilegeType,
+				Endpoint:      sltReconciliation_LogEndpoint,
+				Method:        sltReconciliation_LogMethod,
+				StatusCode:    http.StatusOK,
+				RequestBody:   string(repairedDrifts),
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("userActionLogService.InsertUserActionLogs(): %w", err)
+		}
+
+		report.IsRepaired = true
+		return nil
+	})
+	if err != nil {
+		return teaching.SLTReconciliationReport{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return report, nil
+}
+
+func (s teachingServiceImpl) TransferStudentLearningTokens(ctx context.Context, spec teaching.TransferStudentLearningTokensSpec) (teaching.SLTTransfer, error) {
+	sltTransfer := teaching.SLTTransfer{
+		SourceStudentLearningTokenID: spec.SourceStudentLearningTokenID,
+		RelinkedAttendanceIDs:        make([]entity.AttendanceID, 0),
+	}
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		sourceSLT, err := qtx.GetStudentLearningTokenById(newCtx, int64(spec.SourceStudentLearningTokenID))
+		if err != nil {
+			return fmt.Errorf("qtx.GetStudentLearningTokenById(): %w", err)
+		}
+		if sourceSLT.StudentEnrollmentID == int64(spec.DestinationStudentEnrollmentID) {
+			return errs.ErrSLTTransferToSameEnrollment
+		}
+
+		// get or create the destination token, whose fee values follow the destination enrollment's current price
+		invoice, err := s.GetEnrollmentPaymentInvoice(newCtx, spec.DestinationStudentEnrollmentID, time.Time{}, "")
+		if err != nil {
+			return fmt.Errorf("GetEnrollmentPaymentInvoice(): %w", err)
+		}
+		courseFeeQuarterValue := teaching.CalculateSLTFeeQuarterFromEP(invoice.CourseFeeValue, invoice.BalanceTopUp)
+		transportFeeQuarterValue := teaching.CalculateSLTFeeQuarterFromEP(invoice.TransportFeeValue, invoice.BalanceTopUp)
+		destinationSLTID, err := s.getOrInsertEmptySLT(newCtx, spec.DestinationStudentEnrollmentID, courseFeeQuarterValue, transportFeeQuarterValue)
+		if err != nil {
+			return fmt.Errorf("getOrInsertEmptySLT(): %w", err)
+		}
+		sltTransfer.DestinationStudentLearningTokenID = destinationSLTID
+
+		transferredQuota := spec.Quota
+		if spec.Quota == 0 {
+			// relink the unpaid attendances first, so that their used quota is returned to the source token, and is transferred as well
+			attendanceIDsInt, err := qtx.GetUnpaidAttendanceIdsByTokenId(newCtx, sql.NullInt64{Int64: int64(spec.SourceStudentLearningTokenID), Valid: true})
+			if err != nil {
+				return fmt.Errorf("qtx.GetUnpaidAttendanceIdsByTokenId(): %w", err)
+			}
+			sltTransfer.RelinkedAttendanceIDs, err = s.relinkAttendances(newCtx, attendanceIDsInt, destinationSLTID)
+			if err != nil {
+				return fmt.Errorf("relinkAttendances(): %w", err)
+			}
+
+			sourceSLT, err = qtx.GetStudentLearningTokenById(newCtx, int64(spec.SourceStudentLearningTokenID))
+			if err != nil {
+				return fmt.Errorf("qtx.GetStudentLearningTokenById(): %w", err)
+			}
+			transferredQuota = sourceSLT.Quota
+		}
+		// a fully-owed token (negative quota) is emptied by relinking its attendances only, thus having no quota left to transfer
+		isRelinkOnly := spec.Quota == 0 && len(sltTransfer.RelinkedAttendanceIDs) > 0 && math.Abs(transferredQuota) < sltQuotaTolerance
+		if isRelinkOnly {
+			transferredQuota = 0
+		} else if transferredQuota < sltQuotaTolerance || transferredQuota-sourceSLT.Quota >= sltQuotaTolerance {
+			return fmt.Errorf("sltID='%d', quota='%v', requestedQuota='%v': %w", spec.SourceStudentLearningTokenID, sourceSLT.Quota, transferredQuota, errs.ErrInsufficientSLTQuota)
+		}
+
+		// preserve the transferred value, e.g. 2 quota of 100k/quarter becomes 1 quota of 200k/quarter
+		sltTransfer.TransferredQuota = transferredQuota
+		sltTransfer.ConvertedQuota = teaching.CalculateConvertedSLTQuota(transferredQuota, sourceSLT.CourseFeeQuarterValue, courseFeeQuarterValue)
+
+		sltTransfer.SLTTransferID, err = s.moveSLTQuota(newCtx, sltTransfer, spec.Note)
+		if err != nil {
+			return fmt.Errorf("moveSLTQuota(): %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return teaching.SLTTransfer{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return sltTransfer, nil
+}
+
+func (s teachingServiceImpl) RepriceStudentLearningTokens(ctx context.Context, spec teaching.RepriceStudentLearningTokensSpec) ([]teaching.SLTRepricing, error) {
+	sltRepricings := make([]teaching.SLTRepricing, 0)
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		sltRows, err := qtx.GetPositiveSLTsForRepricing(newCtx, sql.NullInt64{Int64: int64(spec.CourseID), Valid: spec.CourseID != entity.CourseID_None})
+		if err != nil {
+			return fmt.Errorf("qtx.GetPositiveSLTsForRepricing(): %w", err)
+		}
+
+		note := fmt.Sprintf("repricing from %s", spec.CutoverDate.Format("2006-01-02"))
+		for _, sltRow := range sltRows {
+			newCourseFeeValue, err := s.entityService.GetCourseFeeValueAt(newCtx, entity.CourseID(sltRow.ClassCourseID), entity.TeacherID(sltRow.ClassTeacherID.Int64), spec.CutoverDate)
 			if err != nil {
-				return fmt.Errorf("entityService.GetStudentEnrollmentsByClassId(): %w", err)
+				return fmt.Errorf("entityService.GetCourseFeeValueAt(): %w", err)
+			}
+			newCourseFeeQuarterValue := teaching.CalculateSLTFeeQuarterFromEP(newCourseFeeValue, teaching.Default_BalanceTopUp)
+			if newCourseFeeQuarterValue == sltRow.CourseFeeQuarterValue {
+				continue
 			}
 
-			for _, enrollment := range enrollments {
-				fmt.Printf("enrollmentClassInfo: %#v\n", enrollment.ClassInfo)
-				courseFee := enrollment.ClassInfo.Course.DefaultFee
-				if enrollment.ClassInfo.TeacherSpecialFee != 0 {
-					courseFee = enrollment.ClassInfo.TeacherSpecialFee
-				}
-				existingSLT, err := qtx.GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter(newCtx, mysql.GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarterParams{
-					EnrollmentID:             int64(enrollment.StudentEnrollmentID),
-					CourseFeeQuarterValue:    courseFee / teaching.Default_OneCourseCycle,
-					TransportFeeQuarterValue: enrollment.ClassInfo.TransportFee / teaching.Default_OneCourseCycle,
-				})
-				isNeedInsert := false
-				if errors.Is(err, sql.ErrNoRows) {
-					isNeedInsert = true
-					err = nil
-				} else if err != nil {
-					return fmt.Errorf("qtx.GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter(): %w", err)
-				}
+			sltRepricing := teaching.SLTRepricing{
+				SLTTransfer: teaching.SLTTransfer{
+					SourceStudentLearningTokenID: entity.StudentLearningTokenID(sltRow.ID),
+				},
+				StudentEnrollmentID:       entity.StudentEnrollmentID(sltRow.EnrollmentID),
+				PrevCourseFeeQuarterValue: sltRow.CourseFeeQuarterValue,
+				NewCourseFeeQuarterValue:  newCourseFeeQuarterValue,
+			}
 
-				if isNeedInsert {
-					// we don't need the newly added SLT ID, so we can safely ignore it
-					_, err := s.autoRegisterSLT(newCtx, entity.StudentEnrollmentID(enrollment.StudentEnrollmentID), 0)
-					if err != nil {
-						return fmt.Errorf("autoRegisterSLT(): %w", err)
-					}
-				} else {
-					// the goal is to set token's LastUpdatedAt to current date
-					err := qtx.IncrementSLTQuotaById(newCtx, mysql.IncrementSLTQuotaByIdParams{
-						Quota:         0,
-						LastUpdatedAt: time.Now().UTC(),
-						ID:            existingSLT.ID,
-					})
-					if err != nil {
-						return fmt.Errorf("qtx.IncrementSLTQuotaById(): %w", err)
-					}
-				}
+			// the transport fee has no price history, thus it's kept as is
+			sltRepricing.DestinationStudentLearningTokenID, err = s.getOrInsertEmptySLT(newCtx, sltRepricing.StudentEnrollmentID, newCourseFeeQuarterValue, sltRow.TransportFeeQuarterValue)
+			if err != nil {
+				return fmt.Errorf("getOrInsertEmptySLT(): %w", err)
+			}
+
+			sltRepricing, err = s.splitSLTAtDate(newCtx, sltRepricing, spec.CutoverDate, spec.IsQuotaConverted, note)
+			if err != nil {
+				return fmt.Errorf("splitSLTAtDate(): %w", err)
 			}
+
+			sltRepricings = append(sltRepricings, sltRepricing)
 		}
+
 		return nil
 	})
 	if err != nil {
-		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+		return []teaching.SLTRepricing{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
 	}
 
-	return nil
+	return sltRepricings, nil
 }
 
-func (s teachingServiceImpl) GetSLTsByClassID(ctx context.Context, classID entity.ClassID) ([]teaching.StudentIDToSLTs, error) {
-	studentLearningTokenRows, err := s.mySQLQueries.GetSLTByClassIdForAttendanceInfo(ctx, int64(classID))
-	if err != nil {
-		return []teaching.StudentIDToSLTs{}, fmt.Errorf("mySQLQueries.GetSLTByClassIdForAttendanceInfo(): %w", err)
-	}
+// splitSLTAtDate relinks the unpaid attendances since date from the source token into the destination token of sltRepricing (as they use the new price),
+// then moves the source token's remaining quota into the destination token. Only a positive remaining quota is moved, i.e. SLTTransferID is 0 when nothing is moved.
+func (s teachingServiceImpl) splitSLTAtDate(ctx context.Context, sltRepricing teaching.SLTRepricing, date time.Time, isQuotaConverted bool, note string) (teaching.SLTRepricing, error) {
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		// attendances since the date use the new price, thus their used quota is returned to the old token before moving its remaining quota
+		attendanceIDsInt, err := qtx.GetUnpaidAttendanceIdsByTokenIdFromDate(newCtx, mysql.GetUnpaidAttendanceIdsByTokenIdFromDateParams{
+			TokenID: sql.NullInt64{Int64: int64(sltRepricing.SourceStudentLearningTokenID), Valid: true},
+			Date:    date,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetUnpaidAttendanceIdsByTokenIdFromDate(): %w", err)
+		}
+		sltRepricing.RelinkedAttendanceIDs, err = s.relinkAttendances(newCtx, attendanceIDsInt, sltRepricing.DestinationStudentLearningTokenID)
+		if err != nil {
+			return fmt.Errorf("relinkAttendances(): %w", err)
+		}
 
-	studentIdToSlts := make(map[entity.StudentID][]entity.StudentLearningToken_Minimal, 1)
-	for _, sltRow := range studentLearningTokenRows {
-		studentId := entity.StudentID(sltRow.StudentID)
-		// TODO: refactor this, as this in a non-standard way of converting mysql's rows into Entity's struct. Check existing result_constructor.go(s) for reference.
-		slt := entity.StudentLearningToken_Minimal{
-			StudentLearningTokenID: entity.StudentLearningTokenID(sltRow.StudentLearningTokenID),
-			Quota:                  sltRow.Quota,
-			CourseFeeValue:         sltRow.CourseFeeQuarterValue * 4,
-			TransportFeeValue:      sltRow.TransportFeeQuarterValue * 4,
-			CreatedAt:              sltRow.CreatedAt,
-			LastUpdatedAt:          sltRow.LastUpdatedAt,
+		sourceSLT, err := qtx.GetStudentLearningTokenById(newCtx, int64(sltRepricing.SourceStudentLearningTokenID))
+		if err != nil {
+			return fmt.Errorf("qtx.GetStudentLearningTokenById(): %w", err)
+		}
+		if sourceSLT.Quota < sltQuotaTolerance {
+			return nil
+		}
+		sltRepricing.TransferredQuota = sourceSLT.Quota
+		sltRepricing.ConvertedQuota = sourceSLT.Quota
+		if isQuotaConverted {
+			sltRepricing.ConvertedQuota = teaching.CalculateConvertedSLTQuota(sourceSLT.Quota, sltRepricing.PrevCourseFeeQuarterValue, sltRepricing.NewCourseFeeQuarterValue)
 		}
 
-		currSLTs, ok := studentIdToSlts[studentId]
-		if !ok {
-			studentIdToSlts[studentId] = []entity.StudentLearningToken_Minimal{slt}
-		} else {
-			studentIdToSlts[studentId] = append(currSLTs, slt)
+		sltRepricing.SLTTransferID, err = s.moveSLTQuota(newCtx, sltRepricing.SLTTransfer, note)
+		if err != nil {
+			return fmt.Errorf("moveSLTQuota(): %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		return teaching.SLTRepricing{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
 	}
 
-	getSLTsByClassIDResults := make([]teaching.StudentIDToSLTs, 0, len(studentIdToSlts))
-	for studentId, slts := range studentIdToSlts {
-		getSLTsByClassIDResults = append(getSLTsByClassIDResults, teaching.StudentIDToSLTs{
-			StudentID:             studentId,
-			StudentLearningTokens: slts,
-		})
+	return sltRepricing, nil
+}
+
+func (s teachingServiceImpl) PreviewRepriceStudentLearningTokens(ctx context.Context, spec teaching.RepriceStudentLearningTokensSpec) ([]teaching.SLTRepricing, error) {
+	var sltRepricings []teaching.SLTRepricing
+	err := s.mySQLQueries.ExecuteInDryRunTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		sltRepricings, err = s.RepriceStudentLearningTokens(newCtx, spec)
+		return err
+	})
+	if err != nil {
+		return []teaching.SLTRepricing{}, fmt.Errorf("ExecuteInDryRunTransaction(): %w", err)
 	}
 
-	return getSLTsByClassIDResults, nil
+	return sltRepricings, nil
 }
 
 func (s teachingServiceImpl) GetAttendancesByClassID(ctx context.Context, spec teaching.GetAttendancesByClassIDSpec) (teaching.GetAttendancesByClassIDResult, error) {
@@ -561,12 +2067,20 @@ func (s teachingServiceImpl) AddAttendance(ctx context.Context, spec teaching.Ad
 		}
 		autoOweSLT := util.Int32ToBool(autoOweTokenMode)
 
-		studentEnrollments, err := s.entityService.GetStudentEnrollmentsByClassId(newCtx, spec.ClassID)
+		studentEnrollments, err := s.entityService.GetStudentEnrollmentsActiveAtDateByClassId(newCtx, spec.ClassID, spec.Date)
 		if err != nil {
-			return fmt.Errorf("entityService.GetStudentEnrollmentsByClassId(): %w", err)
+			return fmt.Errorf("entityService.GetStudentEnrollmentsActiveAtDateByClassId(): %w", err)
+		}
+		if len(studentEnrollments) == 0 {
+			return fmt.Errorf("classID='%d', date='%s': %w", spec.ClassID, spec.Date.Format("2006-01-02"), errs.ErrClassHaveNoStudent)
+		}
+
+		studentEnrollments, err = s.excludeStudentEnrollmentsOnHold(newCtx, studentEnrollments, spec.Date)
+		if err != nil {
+			return fmt.Errorf("excludeStudentEnrollmentsOnHold(): %w", err)
 		}
 		if len(studentEnrollments) == 0 {
-			return fmt.Errorf("classID='%d': %w", spec.ClassID, errs.ErrClassHaveNoStudent)
+			return fmt.Errorf("classID='%d', date='%s': %w", spec.ClassID, spec.Date.Format("2006-01-02"), errs.ErrAllStudentsOnHold)
 		}
 
 		studentEnrollmentIDsInt64 := make([]int64, 0, len(studentEnrollments))
@@ -590,15 +2104,6 @@ func (s teachingServiceImpl) AddAttendance(ctx context.Context, spec teaching.Ad
 			}
 			enrollmentID := entity.StudentEnrollmentID(earliestAvailableSLT.EnrollmentID)
 			enrollmentIDToEarliestSLTID[enrollmentID] = entity.StudentLearningTokenID(earliestAvailableSLT.StudentLearningTokenID)
-
-			err = qtx.IncrementSLTQuotaById(newCtx, mysql.IncrementSLTQuotaByIdParams{
-				Quota:         spec.UsedStudentTokenQuota * -1,
-				LastUpdatedAt: time.Now().UTC(),
-				ID:            earliestAvailableSLT.StudentLearningTokenID,
-			})
-			if err != nil {
-				return fmt.Errorf("qtx.IncrementSLTQuotaById(): %w", err)
-			}
 		}
 
 		// Insert attendances
@@ -610,7 +2115,8 @@ func (s teachingServiceImpl) AddAttendance(ctx context.Context, spec teaching.Ad
 				// else, we let the `Attendance` to have no SLT. We expect admin to assign the SLT manually.
 				if autoOweSLT {
 					mainLog.Warn("studentEnrollment='%d' doesn't have any studentLearningToken (SLT). Creating a new negative quota SLT as 'autoCreateSLT' is true.", studentEnrollment.StudentEnrollmentID)
-					newSLTID, err := s.autoRegisterSLT(newCtx, entity.StudentEnrollmentID(studentEnrollment.StudentEnrollmentID), spec.UsedStudentTokenQuota*-1)
+					// the SLT is registered with 0 quota, which will be decremented below (after the attendances are inserted) to keep the ledger's source ID
+					newSLTID, err := s.autoRegisterSLT(newCtx, entity.StudentEnrollmentID(studentEnrollment.StudentEnrollmentID), 0)
 					if err != nil {
 						return fmt.Errorf("autoRegisterSLT(): %w", err)
 					}
@@ -635,6 +2141,21 @@ func (s teachingServiceImpl) AddAttendance(ctx context.Context, spec teaching.Ad
 			return fmt.Errorf("entityService.InsertAttendances(): %w", err)
 		}
 
+		for i, attendanceSpec := range specs {
+			if attendanceSpec.StudentLearningTokenID == entity.StudentLearningTokenID_None {
+				continue
+			}
+			err = s.incrementSLTQuota(newCtx, entity.InsertSLTTransactionSpec{
+				StudentLearningTokenID: attendanceSpec.StudentLearningTokenID,
+				QuotaChange:            spec.UsedStudentTokenQuota * -1,
+				Reason:                 entity.SLTTransactionReason_Attendance,
+				SourceID:               int64(attendanceIDs[i]),
+			})
+			if err != nil {
+				return fmt.Errorf("incrementSLTQuota(): %w", err)
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -647,7 +2168,7 @@ func (s teachingServiceImpl) AddAttendance(ctx context.Context, spec teaching.Ad
 func (s teachingServiceImpl) autoRegisterSLT(ctx context.Context, studentEnrollmentID entity.StudentEnrollmentID, quota float64) (entity.StudentLearningTokenID, error) {
 	var newSLTID entity.StudentLearningTokenID
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
-		invoice, err := s.GetEnrollmentPaymentInvoice(ctx, studentEnrollmentID)
+		invoice, err := s.GetEnrollmentPaymentInvoice(ctx, studentEnrollmentID, time.Time{}, "")
 		if err != nil {
 			return fmt.Errorf("GetEnrollmentPaymentInvoice(): %w", err)
 		}
@@ -674,6 +2195,270 @@ func (s teachingServiceImpl) autoRegisterSLT(ctx context.Context, studentEnrollm
 	return newSLTID, nil
 }
 
+// incrementSLTQuota increments the quota of a StudentLearningToken by spec.QuotaChange, and records the change into the SLT ledger.
+//
+// A zero QuotaChange only refreshes the token's LastUpdatedAt, and is not recorded into the ledger.
+func (s teachingServiceImpl) incrementSLTQuota(ctx context.Context, spec entity.InsertSLTTransactionSpec) error {
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		err := qtx.IncrementSLTQuotaById(newCtx, mysql.IncrementSLTQuotaByIdParams{
+			Quota:         spec.QuotaChange,
+			LastUpdatedAt: time.Now().UTC(),
+			ID:            int64(spec.StudentLearningTokenID),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.IncrementSLTQuotaById(): %w", err)
+		}
+
+		if spec.QuotaChange == 0 {
+			return nil
+		}
+		_, err = s.entityService.InsertSLTTransactions(newCtx, []entity.InsertSLTTransactionSpec{spec})
+		if err != nil {
+			return fmt.Errorf("entityService.InsertSLTTransactions(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
+
+// getOrInsertEmptySLT returns the StudentLearningToken of a StudentEnrollment with the given fee quarter values, and inserts a new one with 0 quota if it doesn't exist.
+func (s teachingServiceImpl) getOrInsertEmptySLT(ctx context.Context, studentEnrollmentID entity.StudentEnrollmentID, courseFeeQuarterValue int32, transportFeeQuarterValue int32) (entity.StudentLearningTokenID, error) {
+	var sltID entity.StudentLearningTokenID
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		existingSLT, err := qtx.GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter(newCtx, mysql.GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarterParams{
+			EnrollmentID:             int64(studentEnrollmentID),
+			CourseFeeQuarterValue:    courseFeeQuarterValue,
+			TransportFeeQuarterValue: transportFeeQuarterValue,
+		})
+		if err == nil {
+			sltID = entity.StudentLearningTokenID(existingSLT.ID)
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("qtx.GetSLTByEnrollmentIdAndCourseFeeQuarterAndTransportFeeQuarter(): %w", err)
+		}
+
+		newSLTIDs, err := s.entityService.InsertStudentLearningTokens(newCtx, []entity.InsertStudentLearningTokenSpec{
+			{
+				StudentEnrollmentID:      studentEnrollmentID,
+				Quota:                    0,
+				CourseFeeQuarterValue:    courseFeeQuarterValue,
+				TransportFeeQuarterValue: transportFeeQuarterValue,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("entityService.InsertStudentLearningTokens(): %w", err)
+		}
+		sltID = newSLTIDs[0]
+		return nil
+	})
+	if err != nil {
+		return entity.StudentLearningTokenID_None, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return sltID, nil
+}
+
+// relinkAttendances assigns the destination StudentLearningToken to the attendances, check AssignAttendanceToken() for more information.
+func (s teachingServiceImpl) relinkAttendances(ctx context.Context, attendanceIDsInt []int64, destinationSLTID entity.StudentLearningTokenID) ([]entity.AttendanceID, error) {
+	relinkedAttendanceIDs := make([]entity.AttendanceID, 0, len(attendanceIDsInt))
+	for _, attendanceIDInt := range attendanceIDsInt {
+		err := s.AssignAttendanceToken(ctx, teaching.AssignAttendanceTokenSpec{
+			AttendanceID:           entity.AttendanceID(attendanceIDInt),
+			StudentLearningTokenID: destinationSLTID,
+		})
+		if err != nil {
+			return []entity.AttendanceID{}, fmt.Errorf("AssignAttendanceToken(): %w", err)
+		}
+		relinkedAttendanceIDs = append(relinkedAttendanceIDs, entity.AttendanceID(attendanceIDInt))
+	}
+
+	return relinkedAttendanceIDs, nil
+}
+
+// moveSLTQuota records an SLTTransfer, then deducts sltTransfer.TransferredQuota from the source token, and adds sltTransfer.ConvertedQuota into the destination token.
+func (s teachingServiceImpl) moveSLTQuota(ctx context.Context, sltTransfer teaching.SLTTransfer, note string) (int64, error) {
+	var sltTransferID int64
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		authInfo := network.GetAuthInfo(newCtx)
+		var err error
+		sltTransferID, err = qtx.InsertSLTTransfer(newCtx, mysql.InsertSLTTransferParams{
+			TransferredQuota:   sltTransfer.TransferredQuota,
+			ConvertedQuota:     sltTransfer.ConvertedQuota,
+			Note:               note,
+			UserID:             sql.NullInt64{Int64: int64(authInfo.UserID), Valid: authInfo.UserID != identity.UserID_None},
+			CreatedAt:          time.Now().UTC(),
+			SourceTokenID:      sql.NullInt64{Int64: int64(sltTransfer.SourceStudentLearningTokenID), Valid: true},
+			DestinationTokenID: sql.NullInt64{Int64: int64(sltTransfer.DestinationStudentLearningTokenID), Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.InsertSLTTransfer(): %w", err)
+		}
+
+		err = s.incrementSLTQuota(newCtx, entity.InsertSLTTransactionSpec{
+			StudentLearningTokenID: sltTransfer.SourceStudentLearningTokenID,
+			QuotaChange:            -1 * sltTransfer.TransferredQuota,
+			Reason:                 entity.SLTTransactionReason_Transfer,
+			SourceID:               sltTransferID,
+		})
+		if err != nil {
+			return fmt.Errorf("incrementSLTQuota(): %w", err)
+		}
+		err = s.incrementSLTQuota(newCtx, entity.InsertSLTTransactionSpec{
+			StudentLearningTokenID: sltTransfer.DestinationStudentLearningTokenID,
+			QuotaChange:            sltTransfer.ConvertedQuota,
+			Reason:                 entity.SLTTransactionReason_Transfer,
+			SourceID:               sltTransferID,
+		})
+		if err != nil {
+			return fmt.Errorf("incrementSLTQuota(): %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return sltTransferID, nil
+}
+
+// previewSLTChanges runs wrappedFunc inside a dry-run transaction, and collects every StudentLearningToken which is created, or whose quota is changed by wrappedFunc.
+//
+// The changes are detected via the SLTTransaction ledger & StudentLearningToken IDs which are inserted after wrappedFunc starts, so wrappedFunc must record its quota changes using incrementSLTQuota().
+func (s teachingServiceImpl) previewSLTChanges(ctx context.Context, wrappedFunc func(context.Context) error) (teaching.SLTChangesPreview, error) {
+	preview := teaching.SLTChangesPreview{
+		Changes: make([]teaching.SLTChange, 0),
+	}
+
+	err := s.mySQLQueries.ExecuteInDryRunTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		latestSLTTransactionID, err := qtx.GetLatestSLTTransactionId(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.GetLatestSLTTransactionId(): %w", err)
+		}
+		latestSLTID, err := qtx.GetLatestSLTId(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.GetLatestSLTId(): %w", err)
+		}
+
+		err = wrappedFunc(newCtx)
+		if err != nil {
+			return err
+		}
+
+		sltTransactionRows, err := qtx.GetSLTTransactionsAfterId(newCtx, latestSLTTransactionID)
+		if err != nil {
+			return fmt.Errorf("qtx.GetSLTTransactionsAfterId(): %w", err)
+		}
+		newSLTIDsInt, err := qtx.GetSLTIdsAfterId(newCtx, latestSLTID)
+		if err != nil {
+			return fmt.Errorf("qtx.GetSLTIdsAfterId(): %w", err)
+		}
+
+		sltIDToTransactions := make(map[entity.StudentLearningTokenID][]entity.SLTTransaction, 0)
+		changedSLTIDs := make([]entity.StudentLearningTokenID, 0)
+		for _, sltTransaction := range NewSLTTransactionsFromGetSLTTransactionsAfterIdRow(sltTransactionRows) {
+			sltID := sltTransaction.StudentLearningTokenID
+			if _, ok := sltIDToTransactions[sltID]; !ok {
+				changedSLTIDs = append(changedSLTIDs, sltID)
+			}
+			sltIDToTransactions[sltID] = append(sltIDToTransactions[sltID], sltTransaction)
+		}
+		isNewSLTID := make(map[entity.StudentLearningTokenID]bool, len(newSLTIDsInt))
+		for _, newSLTIDInt := range newSLTIDsInt {
+			sltID := entity.StudentLearningTokenID(newSLTIDInt)
+			isNewSLTID[sltID] = true
+			if _, ok := sltIDToTransactions[sltID]; !ok {
+				changedSLTIDs = append(changedSLTIDs, sltID)
+				sltIDToTransactions[sltID] = make([]entity.SLTTransaction, 0)
+			}
+		}
+		if len(changedSLTIDs) == 0 {
+			return nil
+		}
+
+		changedSLTs, err := s.entityService.GetStudentLearningTokensByIds(newCtx, changedSLTIDs)
+		if err != nil {
+			return fmt.Errorf("entityService.GetStudentLearningTokensByIds(): %w", err)
+		}
+
+		for _, slt := range changedSLTs {
+			sltTransactions := sltIDToTransactions[slt.StudentLearningTokenID]
+			quotaChange := 0.0
+			for _, sltTransaction := range sltTransactions {
+				quotaChange += sltTransaction.QuotaChange
+			}
+
+			quotaBefore := 0.0
+			if !isNewSLTID[slt.StudentLearningTokenID] {
+				quotaBefore = math.Round((slt.Quota-quotaChange)*1000) / 1000
+			}
+			preview.Changes = append(preview.Changes, teaching.SLTChange{
+				StudentLearningToken: slt,
+				IsNew:                isNewSLTID[slt.StudentLearningTokenID],
+				QuotaBefore:          quotaBefore,
+				QuotaAfter:           slt.Quota,
+				Transactions:         sltTransactions,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return teaching.SLTChangesPreview{}, fmt.Errorf("ExecuteInDryRunTransaction(): %w", err)
+	}
+
+	return preview, nil
+}
+
+// excludeStudentEnrollmentsOnHold returns the studentEnrollments which are not on hold at date.
+func (s teachingServiceImpl) excludeStudentEnrollmentsOnHold(ctx context.Context, studentEnrollments []entity.StudentEnrollment, date time.Time) ([]entity.StudentEnrollment, error) {
+	studentEnrollmentIDs := make([]entity.StudentEnrollmentID, 0, len(studentEnrollments))
+	for _, studentEnrollment := range studentEnrollments {
+		studentEnrollmentIDs = append(studentEnrollmentIDs, studentEnrollment.StudentEnrollmentID)
+	}
+	holds, err := s.entityService.GetStudentEnrollmentHoldsByStudentEnrollmentIds(ctx, studentEnrollmentIDs)
+	if err != nil {
+		return []entity.StudentEnrollment{}, fmt.Errorf("entityService.GetStudentEnrollmentHoldsByStudentEnrollmentIds(): %w", err)
+	}
+
+	onHoldEnrollmentIDs := make(map[entity.StudentEnrollmentID]bool, 0)
+	for _, hold := range holds {
+		if hold.IsActiveAt(date) {
+			onHoldEnrollmentIDs[hold.StudentEnrollmentID] = true
+		}
+	}
+
+	activeStudentEnrollments := make([]entity.StudentEnrollment, 0, len(studentEnrollments))
+	for _, studentEnrollment := range studentEnrollments {
+		if onHoldEnrollmentIDs[studentEnrollment.StudentEnrollmentID] {
+			mainLog.Info("Skipping attendance of studentEnrollment='%d' as it is on hold at '%s'", studentEnrollment.StudentEnrollmentID, date.Format("2006-01-02"))
+			continue
+		}
+		activeStudentEnrollments = append(activeStudentEnrollments, studentEnrollment)
+	}
+
+	return activeStudentEnrollments, nil
+}
+
+func (s teachingServiceImpl) PreviewAddAttendancesBatch(ctx context.Context, specs []teaching.AddAttendanceSpec) (teaching.SLTChangesPreview, error) {
+	return s.previewSLTChanges(ctx, func(newCtx context.Context) error {
+		_, err := s.AddAttendancesBatch(newCtx, specs)
+		return err
+	})
+}
+
+func (s teachingServiceImpl) PreviewAddAttendance(ctx context.Context, spec teaching.AddAttendanceSpec) (teaching.SLTChangesPreview, error) {
+	return s.previewSLTChanges(ctx, func(newCtx context.Context) error {
+		_, err := s.AddAttendance(newCtx, spec)
+		return err
+	})
+}
+
 func (s teachingServiceImpl) AssignAttendanceToken(ctx context.Context, spec teaching.AssignAttendanceTokenSpec) error {
 	errV := util.ValidateUpdateSpecs(ctx, []teaching.AssignAttendanceTokenSpec{spec}, s.mySQLQueries.CountAttendancesByIds)
 	if errV != nil {
@@ -689,24 +2474,38 @@ func (s teachingServiceImpl) AssignAttendanceToken(ctx context.Context, spec tea
 			return errs.ErrModifyingPaidAttendance
 		}
 
+		prevDates, err := qtx.GetAttendanceDatesByIds(newCtx, []int64{spec.GetInt64ID()})
+		if err != nil {
+			return fmt.Errorf("qtx.GetAttendanceDatesByIds(): %w", err)
+		}
+		err = s.entityService.EnsureDatesNotInClosedPeriod(newCtx, entity.EnsureDatesNotInClosedPeriodSpec{
+			Operation: "AssignAttendanceToken",
+			Dates:     prevDates,
+		})
+		if err != nil {
+			return fmt.Errorf("entityService.EnsureDatesNotInClosedPeriod(): %w", err)
+		}
+
 		// update (1) previous token's quota, and (2) new token's quota
 		if rowResult.TokenID.Valid {
-			err = qtx.IncrementSLTQuotaById(newCtx, mysql.IncrementSLTQuotaByIdParams{
-				Quota:         rowResult.UsedStudentTokenQuota,
-				LastUpdatedAt: time.Now().UTC(),
-				ID:            rowResult.TokenID.Int64,
+			err = s.incrementSLTQuota(newCtx, entity.InsertSLTTransactionSpec{
+				StudentLearningTokenID: entity.StudentLearningTokenID(rowResult.TokenID.Int64),
+				QuotaChange:            rowResult.UsedStudentTokenQuota,
+				Reason:                 entity.SLTTransactionReason_Attendance,
+				SourceID:               spec.GetInt64ID(),
 			})
 			if err != nil {
-				return fmt.Errorf("IncrementSLTQuotaById(): %w", err)
+				return fmt.Errorf("incrementSLTQuota(): %w", err)
 			}
 		}
-		err = qtx.IncrementSLTQuotaById(newCtx, mysql.IncrementSLTQuotaByIdParams{
-			Quota:         -1 * rowResult.UsedStudentTokenQuota,
-			LastUpdatedAt: time.Now().UTC(),
-			ID:            int64(spec.StudentLearningTokenID),
+		err = s.incrementSLTQuota(newCtx, entity.InsertSLTTransactionSpec{
+			StudentLearningTokenID: spec.StudentLearningTokenID,
+			QuotaChange:            -1 * rowResult.UsedStudentTokenQuota,
+			Reason:                 entity.SLTTransactionReason_Attendance,
+			SourceID:               spec.GetInt64ID(),
 		})
 		if err != nil {
-			return fmt.Errorf("IncrementSLTQuotaById(): %w", err)
+			return fmt.Errorf("incrementSLTQuota(): %w", err)
 		}
 
 		// assign the new token to the attendance
@@ -727,6 +2526,105 @@ func (s teachingServiceImpl) AssignAttendanceToken(ctx context.Context, spec tea
 	return nil
 }
 
+func (s teachingServiceImpl) AssignAttendancesTokens(ctx context.Context, spec teaching.AssignAttendancesTokensSpec) (teaching.AttendanceTokenAssignments, error) {
+	spec.TimeSpec.SetDefaultForZeroValues()
+
+	result := teaching.AttendanceTokenAssignments{
+		Assignments: make([]teaching.AttendanceTokenAssignment, 0),
+		Failures:    make([]teaching.AttendanceTokenAssignmentFailure, 0),
+	}
+
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var explicitSLT mysql.GetStudentLearningTokenByIdRow
+		if spec.Strategy == teaching.AttendanceTokenAssignmentStrategy_ExplicitSLT {
+			var err error
+			explicitSLT, err = qtx.GetStudentLearningTokenById(newCtx, int64(spec.StudentLearningTokenID))
+			if err != nil {
+				return fmt.Errorf("qtx.GetStudentLearningTokenById(): %w", err)
+			}
+		}
+
+		attendanceRows, err := qtx.GetAttendancesWithoutTokenByClassId(newCtx, mysql.GetAttendancesWithoutTokenByClassIdParams{
+			ClassID:   int64(spec.ClassID),
+			StudentID: sql.NullInt64{Int64: int64(spec.StudentID), Valid: spec.StudentID != entity.StudentID_None},
+			StartDate: spec.StartDatetime,
+			EndDate:   spec.EndDatetime,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetAttendancesWithoutTokenByClassId(): %w", err)
+		}
+
+		for _, attendanceRow := range attendanceRows {
+			failure := teaching.AttendanceTokenAssignmentFailure{
+				AttendanceID: entity.AttendanceID(attendanceRow.ID),
+				StudentID:    entity.StudentID(attendanceRow.StudentID),
+				Date:         attendanceRow.Date,
+			}
+			if !attendanceRow.StudentEnrollmentID.Valid {
+				failure.Reason = "the student is no longer enrolled in the class"
+				result.Failures = append(result.Failures, failure)
+				continue
+			}
+			if util.Int32ToBool(attendanceRow.IsPaid) {
+				failure.Reason = "the attendance is already paid"
+				result.Failures = append(result.Failures, failure)
+				continue
+			}
+
+			var sltID entity.StudentLearningTokenID
+			switch spec.Strategy {
+			case teaching.AttendanceTokenAssignmentStrategy_ExplicitSLT:
+				if explicitSLT.StudentEnrollmentID != attendanceRow.StudentEnrollmentID.Int64 {
+					failure.Reason = "the studentLearningToken belongs to another studentEnrollment"
+					result.Failures = append(result.Failures, failure)
+					continue
+				}
+				sltID = spec.StudentLearningTokenID
+			case teaching.AttendanceTokenAssignmentStrategy_EarliestAvailable:
+				// the earliest available token may change after each assignment (e.g. its quota runs out), thus it's fetched for each attendance
+				sltRows, err := qtx.GetEarliestAvailableSLTsByStudentEnrollmentIds(newCtx, []int64{attendanceRow.StudentEnrollmentID.Int64})
+				if err != nil {
+					return fmt.Errorf("qtx.GetEarliestAvailableSLTsByStudentEnrollmentIds(): %w", err)
+				}
+				if len(sltRows) == 0 {
+					failure.Reason = "the student has no studentLearningToken"
+					result.Failures = append(result.Failures, failure)
+					continue
+				}
+				sltID = entity.StudentLearningTokenID(sltRows[0].StudentLearningTokenID)
+			default:
+				return fmt.Errorf("unknown strategy: '%s'", spec.Strategy)
+			}
+
+			err = s.AssignAttendanceToken(newCtx, teaching.AssignAttendanceTokenSpec{
+				AttendanceID:           entity.AttendanceID(attendanceRow.ID),
+				StudentLearningTokenID: sltID,
+			})
+			if errors.Is(err, errs.ErrDateInClosedPeriod) {
+				// AssignAttendanceToken() checks the closed period before modifying anything, thus the transaction can safely continue
+				failure.Reason = "the attendance is dated within a closed accounting period"
+				result.Failures = append(result.Failures, failure)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("AssignAttendanceToken(): %w", err)
+			}
+
+			result.Assignments = append(result.Assignments, teaching.AttendanceTokenAssignment{
+				AttendanceID:           entity.AttendanceID(attendanceRow.ID),
+				StudentID:              entity.StudentID(attendanceRow.StudentID),
+				StudentLearningTokenID: sltID,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return teaching.AttendanceTokenAssignments{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return result, nil
+}
+
 func (s teachingServiceImpl) EditAttendance(ctx context.Context, spec teaching.EditAttendanceSpec) ([]entity.AttendanceID, error) {
 	errV := util.ValidateUpdateSpecs(ctx, []teaching.EditAttendanceSpec{spec}, s.mySQLQueries.CountAttendancesByIds)
 	if errV != nil {
@@ -743,6 +2641,7 @@ func (s teachingServiceImpl) EditAttendance(ctx context.Context, spec teaching.E
 
 		attendanceIDsInt := make([]int64, 0, len(rowResults))
 		sltIDIntToUsedQuota := make(map[int64]float64, len(rowResults))
+		sltIDIntToAttendanceIDInt := make(map[int64]int64, len(rowResults))
 		for _, rowResult := range rowResults {
 			if util.Int32ToBool(rowResult.IsPaid) {
 				return errs.ErrModifyingPaidAttendance
@@ -752,9 +2651,22 @@ func (s teachingServiceImpl) EditAttendance(ctx context.Context, spec teaching.E
 
 			if rowResult.TokenID.Valid {
 				sltIDIntToUsedQuota[rowResult.TokenID.Int64] = rowResult.UsedStudentTokenQuota
+				sltIDIntToAttendanceIDInt[rowResult.TokenID.Int64] = rowResult.ID
 			}
 		}
 
+		prevDates, err := qtx.GetAttendanceDatesByIds(newCtx, attendanceIDsInt)
+		if err != nil {
+			return fmt.Errorf("qtx.GetAttendanceDatesByIds(): %w", err)
+		}
+		err = s.entityService.EnsureDatesNotInClosedPeriod(newCtx, entity.EnsureDatesNotInClosedPeriodSpec{
+			Operation: "EditAttendance",
+			Dates:     append(prevDates, spec.Date),
+		})
+		if err != nil {
+			return fmt.Errorf("entityService.EnsureDatesNotInClosedPeriod(): %w", err)
+		}
+
 		err = qtx.EditAttendances(newCtx, mysql.EditAttendancesParams{
 			TeacherID:             int64(spec.TeacherID),
 			Date:                  spec.Date,
@@ -766,16 +2678,22 @@ func (s teachingServiceImpl) EditAttendance(ctx context.Context, spec teaching.E
 		if err != nil {
 			return fmt.Errorf("qtx.EditAttendances(): %w", err)
 		}
+		// the teacher may have been changed, from or into a substitute teacher
+		err = qtx.RefreshAttendancesSubstituteStatusByIds(newCtx, attendanceIDsInt)
+		if err != nil {
+			return fmt.Errorf("qtx.RefreshAttendancesSubstituteStatusByIds(): %w", err)
+		}
 
 		for sltIDInt, usedQuota := range sltIDIntToUsedQuota {
 			quotaChange := float64(usedQuota - spec.UsedStudentTokenQuota)
-			err = qtx.IncrementSLTQuotaById(newCtx, mysql.IncrementSLTQuotaByIdParams{
-				Quota:         quotaChange,
-				LastUpdatedAt: time.Now().UTC(),
-				ID:            sltIDInt,
+			err = s.incrementSLTQuota(newCtx, entity.InsertSLTTransactionSpec{
+				StudentLearningTokenID: entity.StudentLearningTokenID(sltIDInt),
+				QuotaChange:            quotaChange,
+				Reason:                 entity.SLTTransactionReason_Attendance,
+				SourceID:               sltIDIntToAttendanceIDInt[sltIDInt],
 			})
 			if err != nil {
-				return fmt.Errorf("qtx.IncrementSLTQuotaById(): %w", err)
+				return fmt.Errorf("incrementSLTQuota(): %w", err)
 			}
 		}
 
@@ -799,6 +2717,7 @@ func (s teachingServiceImpl) RemoveAttendance(ctx context.Context, attendanceID
 
 		attendanceIDsInt := make([]int64, 0, len(rowResults))
 		sltIDIntToUsedQuota := make(map[int64]float64, len(rowResults))
+		sltIDIntToAttendanceIDInt := make(map[int64]int64, len(rowResults))
 		for _, rowResult := range rowResults {
 			if util.Int32ToBool(rowResult.IsPaid) {
 				return errs.ErrModifyingPaidAttendance
@@ -808,9 +2727,22 @@ func (s teachingServiceImpl) RemoveAttendance(ctx context.Context, attendanceID
 
 			if rowResult.TokenID.Valid {
 				sltIDIntToUsedQuota[rowResult.TokenID.Int64] = rowResult.UsedStudentTokenQuota
+				sltIDIntToAttendanceIDInt[rowResult.TokenID.Int64] = rowResult.ID
 			}
 		}
 
+		prevDates, err := qtx.GetAttendanceDatesByIds(newCtx, attendanceIDsInt)
+		if err != nil {
+			return fmt.Errorf("qtx.GetAttendanceDatesByIds(): %w", err)
+		}
+		err = s.entityService.EnsureDatesNotInClosedPeriod(newCtx, entity.EnsureDatesNotInClosedPeriodSpec{
+			Operation: "RemoveAttendance",
+			Dates:     prevDates,
+		})
+		if err != nil {
+			return fmt.Errorf("entityService.EnsureDatesNotInClosedPeriod(): %w", err)
+		}
+
 		err = qtx.DeleteAttendancesByIds(newCtx, attendanceIDsInt)
 		if err != nil {
 			return fmt.Errorf("qtx.DeleteAttendancesByIds(): %w", err)
@@ -818,13 +2750,14 @@ func (s teachingServiceImpl) RemoveAttendance(ctx context.Context, attendanceID
 
 		for sltIDInt, usedQuota := range sltIDIntToUsedQuota {
 			quotaChange := usedQuota
-			err = qtx.IncrementSLTQuotaById(newCtx, mysql.IncrementSLTQuotaByIdParams{
-				Quota:         quotaChange,
-				LastUpdatedAt: time.Now().UTC(),
-				ID:            sltIDInt,
+			err = s.incrementSLTQuota(newCtx, entity.InsertSLTTransactionSpec{
+				StudentLearningTokenID: entity.StudentLearningTokenID(sltIDInt),
+				QuotaChange:            quotaChange,
+				Reason:                 entity.SLTTransactionReason_Attendance,
+				SourceID:               sltIDIntToAttendanceIDInt[sltIDInt],
 			})
 			if err != nil {
-				return fmt.Errorf("qtx.IncrementSLTQuotaById(): %w", err)
+				return fmt.Errorf("incrementSLTQuota(): %w", err)
 			}
 		}
 
@@ -916,6 +2849,20 @@ func (s teachingServiceImpl) GetTeachersForPayment(ctx context.Context, spec tea
 	}, nil
 }
 
+func (s teachingServiceImpl) GetTeacherSubstitutionSummaries(ctx context.Context, spec teaching.GetTeacherSubstitutionSummariesSpec) ([]teaching.TeacherSubstitutionSummary, error) {
+	spec.TimeSpec.SetDefaultForZeroValues()
+
+	summaryRows, err := s.mySQLQueries.GetTeacherSubstitutionSummaries(ctx, mysql.GetTeacherSubstitutionSummariesParams{
+		StartDate: spec.StartDatetime,
+		EndDate:   spec.EndDatetime,
+	})
+	if err != nil {
+		return []teaching.TeacherSubstitutionSummary{}, fmt.Errorf("mySQLQueries.GetTeacherSubstitutionSummaries(): %w", err)
+	}
+
+	return NewTeacherSubstitutionSummariesFromGetTeacherSubstitutionSummariesRow(summaryRows), nil
+}
+
 func assignClassesNeedTokenToTeachersForPayments(teachersForPayments []teaching.TeacherForPayment, classesWithoutToken []entity.Class) {
 	var teacherIdToClasses = make(map[entity.TeacherID][]entity.Class, 0)
 	for _, class := range classesWithoutToken {
@@ -942,8 +2889,19 @@ func (s teachingServiceImpl) GetTeacherPaymentInvoiceItems(ctx context.Context,
 		return []teaching.TeacherPaymentInvoiceItem{}, fmt.Errorf("entityService.GetUnpaidAttendancesByTeacherId(): %v", err)
 	}
 
+	teacherFeeSharings, err := s.entityService.GetTeacherFeeSharingsByTeacherId(ctx, spec.TeacherID)
+	if err != nil {
+		return []teaching.TeacherPaymentInvoiceItem{}, fmt.Errorf("entityService.GetTeacherFeeSharingsByTeacherId(): %v", err)
+	}
+
+	substituteCourseFeeValues, err := s.getSubstituteCourseFeeValues(ctx, attendances)
+	if err != nil {
+		return []teaching.TeacherPaymentInvoiceItem{}, fmt.Errorf("getSubstituteCourseFeeValues(): %v", err)
+	}
+
 	tpiiBuilder := teaching.NewTeacherPaymentInvoiceItemBuilder()
-	tpiiBuilder.AddAttendances(attendances)
+	tpiiBuilder.AddAttendances(attendances, teacherFeeSharings)
+	tpiiBuilder.ApplySubstituteCourseFeeValues(substituteCourseFeeValues)
 	teacherPaymentInvoiceItems := tpiiBuilder.Build()
 
 	return teacherPaymentInvoiceItems, nil
@@ -960,6 +2918,7 @@ func (s teachingServiceImpl) GetExistingTeacherPaymentInvoiceItems(ctx context.C
 		return []teaching.TeacherPaymentInvoiceItem{}, fmt.Errorf("entityService.GetTeacherPaymentsByTeacherId(): %v", err)
 	}
 
+	// the existing TeacherPayments are left as they were recorded. The substitute teacher's own rate is only applied on the unpaid attendances (see GetTeacherPaymentInvoiceItems()).
 	tpiiBuilder := teaching.NewTeacherPaymentInvoiceItemBuilder()
 	tpiiBuilder.AddTeacherPayments(teacherPayments)
 	teacherPaymentInvoiceItems := tpiiBuilder.Build()
@@ -967,6 +2926,70 @@ func (s teachingServiceImpl) GetExistingTeacherPaymentInvoiceItems(ctx context.C
 	return teacherPaymentInvoiceItems, nil
 }
 
+// getSubstituteCourseFeeValues returns the course fee value of each substitution, at the substitute teacher's own rate (TeacherSpecialFee > Course fee) in force at the attendance date.
+func (s teachingServiceImpl) getSubstituteCourseFeeValues(ctx context.Context, attendances []entity.Attendance) (map[entity.AttendanceID]int32, error) {
+	attendanceIdToCourseFeeValue := make(map[entity.AttendanceID]int32, 0)
+	for _, attendance := range attendances {
+		courseID := attendance.ClassInfo.Course.CourseID
+		teacherID := attendance.TeacherInfo.TeacherID
+		if !attendance.IsSubstitute || courseID == entity.CourseID_None || teacherID == entity.TeacherID_None {
+			continue
+		}
+
+		courseFeeValue, err := s.entityService.GetCourseFeeValueAt(ctx, courseID, teacherID, attendance.Date)
+		if err != nil {
+			return map[entity.AttendanceID]int32{}, fmt.Errorf("entityService.GetCourseFeeValueAt(): %w", err)
+		}
+		attendanceIdToCourseFeeValue[attendance.AttendanceID] = courseFeeValue
+	}
+
+	return attendanceIdToCourseFeeValue, nil
+}
+
+func (s teachingServiceImpl) GetTeacherPayslip(ctx context.Context, spec teaching.GetExistingTeacherPaymentInvoiceItemsSpec) (teaching.TeacherPayslip, error) {
+	teacher, err := s.entityService.GetTeacherById(ctx, spec.TeacherID)
+	if err != nil {
+		return teaching.TeacherPayslip{}, fmt.Errorf("entityService.GetTeacherById(): %w", err)
+	}
+
+	invoiceItems, err := s.GetExistingTeacherPaymentInvoiceItems(ctx, spec)
+	if err != nil {
+		return teaching.TeacherPayslip{}, fmt.Errorf("GetExistingTeacherPaymentInvoiceItems(): %w", err)
+	}
+
+	return teaching.NewTeacherPayslip(teacher, spec.TimeSpec, invoiceItems), nil
+}
+
+func (s teachingServiceImpl) EmailTeacherPayslip(ctx context.Context, spec teaching.GetExistingTeacherPaymentInvoiceItemsSpec) error {
+	payslip, err := s.GetTeacherPayslip(ctx, spec)
+	if err != nil {
+		return fmt.Errorf("GetTeacherPayslip(): %w", err)
+	}
+
+	recipientEmail := payslip.Teacher.User.Email
+	if recipientEmail == "" {
+		return fmt.Errorf("teacherId='%d': %w", spec.TeacherID, errs.ErrUserEmailNotSet)
+	}
+
+	payslipTemplate := email_composer.NewTeacherPayslip(payslip)
+	body, err := s.emailComposer.GenerateHTML(payslipTemplate.Email())
+	if err != nil {
+		return fmt.Errorf("emailComposer.GenerateHTML(): %w", err)
+	}
+	err = s.smtpAccessor.SendEmail(
+		true,
+		"",
+		[]string{recipientEmail},
+		payslipTemplate.Subject(),
+		body,
+	)
+	if err != nil {
+		return fmt.Errorf("SendEmail(): %w", err)
+	}
+
+	return nil
+}
+
 func (s teachingServiceImpl) SubmitTeacherPayments(ctx context.Context, specs []teaching.SubmitTeacherPaymentsSpec) error {
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
 		insertSpecs := make([]entity.InsertTeacherPaymentSpec, 0, len(specs))
@@ -980,11 +3003,16 @@ func (s teachingServiceImpl) SubmitTeacherPayments(ctx context.Context, specs []
 			affectedAttendancedIDsInt64 = append(affectedAttendancedIDsInt64, int64(spec.AttendanceID))
 		}
 
-		_, err := s.entityService.InsertTeacherPayments(newCtx, insertSpecs)
+		teacherPaymentIDs, err := s.entityService.InsertTeacherPayments(newCtx, insertSpecs)
 		if err != nil {
 			return fmt.Errorf("entityService.InsertTeacherPayments(): %w", err)
 		}
 
+		err = addTeacherPaymentsIntoPayrollRuns(newCtx, qtx, specs, teacherPaymentIDs)
+		if err != nil {
+			return fmt.Errorf("addTeacherPaymentsIntoPayrollRuns(): %w", err)
+		}
+
 		err = qtx.SetAttendancesIsPaidStatusByIds(newCtx, mysql.SetAttendancesIsPaidStatusByIdsParams{
 			IsPaid: 1,
 			Ids:    affectedAttendancedIDsInt64,
@@ -1055,10 +3083,31 @@ func (s teachingServiceImpl) EditTeacherPayments(ctx context.Context, specs []te
 	}
 
 	teacherPaymentIDs := make([]entity.TeacherPaymentID, 0, len(specs))
+	teacherPaymentIDsInt64 := make([]int64, 0, len(specs))
+	for _, spec := range specs {
+		teacherPaymentIDs = append(teacherPaymentIDs, spec.TeacherPaymentID)
+		teacherPaymentIDsInt64 = append(teacherPaymentIDsInt64, int64(spec.TeacherPaymentID))
+	}
 
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		err := ensureTeacherPaymentsNotLocked(newCtx, qtx, teacherPaymentIDs)
+		if err != nil {
+			return fmt.Errorf("ensureTeacherPaymentsNotLocked(): %w", err)
+		}
+
+		prevAddedAts, err := qtx.GetTeacherPaymentAddedAtsByIds(newCtx, teacherPaymentIDsInt64)
+		if err != nil {
+			return fmt.Errorf("qtx.GetTeacherPaymentAddedAtsByIds(): %w", err)
+		}
+		err = s.entityService.EnsureDatesNotInClosedPeriod(newCtx, entity.EnsureDatesNotInClosedPeriodSpec{
+			Operation: "EditTeacherPayments",
+			Dates:     prevAddedAts,
+		})
+		if err != nil {
+			return fmt.Errorf("entityService.EnsureDatesNotInClosedPeriod(): %w", err)
+		}
+
 		for _, spec := range specs {
-			teacherPaymentIDs = append(teacherPaymentIDs, spec.TeacherPaymentID)
 			err := qtx.EditTeacherPayment(newCtx, mysql.EditTeacherPaymentParams{
 				PaidCourseFeeValue:    spec.PaidCourseFeeValue,
 				PaidTransportFeeValue: spec.PaidTransportFeeValue,
@@ -1080,6 +3129,11 @@ func (s teachingServiceImpl) EditTeacherPayments(ctx context.Context, specs []te
 
 func (s teachingServiceImpl) RemoveTeacherPayments(ctx context.Context, teacherPaymentIDs []entity.TeacherPaymentID) error {
 	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		err := ensureTeacherPaymentsNotLocked(newCtx, qtx, teacherPaymentIDs)
+		if err != nil {
+			return fmt.Errorf("ensureTeacherPaymentsNotLocked(): %w", err)
+		}
+
 		teacherPaymentIDsint64 := make([]int64, 0, len(teacherPaymentIDs))
 		for _, teacherPaymentID := range teacherPaymentIDs {
 			teacherPaymentIDsint64 = append(teacherPaymentIDsint64, int64(teacherPaymentID))
@@ -1110,3 +3164,258 @@ func (s teachingServiceImpl) RemoveTeacherPayments(ctx context.Context, teacherP
 
 	return nil
 }
+
+// addTeacherPaymentsIntoPayrollRuns adds the newly submitted TeacherPayments into their PayrollRun, along with the teachers of the TeacherPayments.
+// Every TeacherPayment must belong to a draft PayrollRun, whose period covers the paid attendance.
+//
+// teacherPaymentIDs must be in the same order as specs.
+func addTeacherPaymentsIntoPayrollRuns(ctx context.Context, qtx *mysql.Queries, specs []teaching.SubmitTeacherPaymentsSpec, teacherPaymentIDs []entity.TeacherPaymentID) error {
+	payrollRunIdToTeacherPaymentIDs := make(map[entity.PayrollRunID][]int64, 0)
+	payrollRunIdToAttendanceIDs := make(map[entity.PayrollRunID][]int64, 0)
+	for i, spec := range specs {
+		if spec.PayrollRunID == entity.PayrollRunID_None {
+			return fmt.Errorf("attendanceId='%d' is not submitted into any payrollRun: %w", spec.AttendanceID, errs.ErrInvalidRequest)
+		}
+		payrollRunIdToTeacherPaymentIDs[spec.PayrollRunID] = append(payrollRunIdToTeacherPaymentIDs[spec.PayrollRunID], int64(teacherPaymentIDs[i]))
+		payrollRunIdToAttendanceIDs[spec.PayrollRunID] = append(payrollRunIdToAttendanceIDs[spec.PayrollRunID], int64(spec.AttendanceID))
+	}
+
+	for payrollRunID, teacherPaymentIDsInt64 := range payrollRunIdToTeacherPaymentIDs {
+		status, err := qtx.GetPayrollRunStatusByIdForUpdate(ctx, int64(payrollRunID))
+		if err != nil {
+			return fmt.Errorf("qtx.GetPayrollRunStatusByIdForUpdate(): %w", err)
+		}
+		if entity.PayrollRunStatus(status) != entity.PayrollRunStatus_Draft {
+			return fmt.Errorf("payrollRunId='%d' has status='%s': %w", payrollRunID, status, errs.ErrPayrollRunNotDraft)
+		}
+
+		payrollRun, err := qtx.GetPayrollRunById(ctx, int64(payrollRunID))
+		if err != nil {
+			return fmt.Errorf("qtx.GetPayrollRunById(): %w", err)
+		}
+		outsideAttendanceIDs, err := qtx.GetAttendanceIdsOutsideDateRangeByIds(ctx, mysql.GetAttendanceIdsOutsideDateRangeByIdsParams{
+			Ids:       payrollRunIdToAttendanceIDs[payrollRunID],
+			StartDate: payrollRun.StartDate,
+			EndDate:   payrollRun.EndDate,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetAttendanceIdsOutsideDateRangeByIds(): %w", err)
+		}
+		if len(outsideAttendanceIDs) > 0 {
+			return fmt.Errorf("attendanceIds=%v are outside of payrollRunId='%d' period: %w", outsideAttendanceIDs, payrollRunID, errs.ErrAttendanceOutsidePayrollRunPeriod)
+		}
+
+		err = qtx.SetTeacherPaymentsPayrollRunIdByIds(ctx, mysql.SetTeacherPaymentsPayrollRunIdByIdsParams{
+			PayrollRunID: sql.NullInt64{Int64: int64(payrollRunID), Valid: true},
+			Ids:          teacherPaymentIDsInt64,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.SetTeacherPaymentsPayrollRunIdByIds(): %w", err)
+		}
+
+		err = qtx.InsertPayrollRunTeachersByAttendanceIds(ctx, mysql.InsertPayrollRunTeachersByAttendanceIdsParams{
+			PayrollRunID:  int64(payrollRunID),
+			AttendanceIds: payrollRunIdToAttendanceIDs[payrollRunID],
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.InsertPayrollRunTeachersByAttendanceIds(): %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureTeacherPaymentsNotLocked returns errs.ErrTeacherPaymentLocked when any of the TeacherPayments belongs to a non-draft PayrollRun.
+func ensureTeacherPaymentsNotLocked(ctx context.Context, qtx *mysql.Queries, teacherPaymentIDs []entity.TeacherPaymentID) error {
+	if len(teacherPaymentIDs) == 0 {
+		return nil
+	}
+
+	teacherPaymentIDsInt64 := make([]int64, 0, len(teacherPaymentIDs))
+	for _, teacherPaymentID := range teacherPaymentIDs {
+		teacherPaymentIDsInt64 = append(teacherPaymentIDsInt64, int64(teacherPaymentID))
+	}
+
+	totalLocked, err := qtx.CountLockedTeacherPaymentsByIds(ctx, teacherPaymentIDsInt64)
+	if err != nil {
+		return fmt.Errorf("qtx.CountLockedTeacherPaymentsByIds(): %w", err)
+	}
+	if totalLocked > 0 {
+		return fmt.Errorf("found %d locked teacherPayment(s): %w", totalLocked, errs.ErrTeacherPaymentLocked)
+	}
+
+	return nil
+}
+
+func (s teachingServiceImpl) GetPayrollRuns(ctx context.Context, pagination util.PaginationSpec) (teaching.GetPayrollRunsResult, error) {
+	pagination.SetDefaultOnInvalidValues()
+	limit, offset := pagination.GetLimitAndOffset()
+
+	var payrollRunRows = make([]mysql.GetPayrollRunsRow, 0)
+	var totalResults int64 = 0
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		var err error
+		payrollRunRows, err = qtx.GetPayrollRuns(newCtx, mysql.GetPayrollRunsParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetPayrollRuns(): %w", err)
+		}
+
+		totalResults, err = qtx.CountPayrollRuns(newCtx)
+		if err != nil {
+			return fmt.Errorf("qtx.CountPayrollRuns(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return teaching.GetPayrollRunsResult{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	payrollRuns := NewPayrollRunsFromGetPayrollRunsRow(payrollRunRows)
+
+	return teaching.GetPayrollRunsResult{
+		PayrollRuns:      payrollRuns,
+		PaginationResult: *util.NewPaginationResult(int(totalResults), pagination.ResultsPerPage, pagination.Page),
+	}, nil
+}
+
+func (s teachingServiceImpl) GetPayrollRunById(ctx context.Context, id entity.PayrollRunID) (entity.PayrollRun, error) {
+	payrollRunRow, err := s.mySQLQueries.GetPayrollRunById(ctx, int64(id))
+	if err != nil {
+		return entity.PayrollRun{}, fmt.Errorf("mySQLQueries.GetPayrollRunById(): %w", err)
+	}
+
+	payrollRun := NewPayrollRunsFromGetPayrollRunByIdRow([]mysql.GetPayrollRunByIdRow{payrollRunRow})[0]
+
+	return payrollRun, nil
+}
+
+func (s teachingServiceImpl) CreatePayrollRun(ctx context.Context, spec teaching.CreatePayrollRunSpec) (entity.PayrollRunID, error) {
+	spec.TimeSpec.SetDefaultForZeroValues()
+
+	var payrollRunID int64
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		authInfo := network.GetAuthInfo(newCtx)
+		var err error
+		payrollRunID, err = qtx.InsertPayrollRun(newCtx, mysql.InsertPayrollRunParams{
+			StartDate:       spec.StartDatetime,
+			EndDate:         spec.EndDatetime,
+			Note:            spec.Note,
+			CreatedByUserID: sql.NullInt64{Int64: int64(authInfo.UserID), Valid: authInfo.UserID != identity.UserID_None},
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.InsertPayrollRun(): %w", err)
+		}
+
+		totalUnpaidTeachers, err := qtx.CountUnpaidTeachers(newCtx, mysql.CountUnpaidTeachersParams{
+			StartDate: spec.StartDatetime,
+			EndDate:   spec.EndDatetime,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.CountUnpaidTeachers(): %w", err)
+		}
+
+		teacherRows, err := qtx.GetUnpaidTeachers(newCtx, mysql.GetUnpaidTeachersParams{
+			StartDate: spec.StartDatetime,
+			EndDate:   spec.EndDatetime,
+			Limit:     int32(totalUnpaidTeachers),
+			Offset:    0,
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.GetUnpaidTeachers(): %w", err)
+		}
+
+		for _, teacherRow := range teacherRows {
+			err = qtx.InsertPayrollRunTeacher(newCtx, mysql.InsertPayrollRunTeacherParams{
+				PayrollRunID: payrollRunID,
+				TeacherID:    teacherRow.ID,
+			})
+			if err != nil {
+				return fmt.Errorf("qtx.InsertPayrollRunTeacher(): %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return entity.PayrollRunID_None, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return entity.PayrollRunID(payrollRunID), nil
+}
+
+func (s teachingServiceImpl) GetPayrollRunTeacherTotals(ctx context.Context, id entity.PayrollRunID) ([]teaching.PayrollRunTeacherTotal, error) {
+	var teacherTotalRows = make([]mysql.GetPayrollRunTeacherTotalsRow, 0)
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		// to return sql.ErrNoRows when the PayrollRun doesn't exist, instead of an empty result
+		_, err := qtx.GetPayrollRunById(newCtx, int64(id))
+		if err != nil {
+			return fmt.Errorf("qtx.GetPayrollRunById(): %w", err)
+		}
+
+		teacherTotalRows, err = qtx.GetPayrollRunTeacherTotals(newCtx, int64(id))
+		if err != nil {
+			return fmt.Errorf("qtx.GetPayrollRunTeacherTotals(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return []teaching.PayrollRunTeacherTotal{}, fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	teacherTotals := NewPayrollRunTeacherTotalsFromGetPayrollRunTeacherTotalsRow(teacherTotalRows)
+
+	return teacherTotals, nil
+}
+
+func (s teachingServiceImpl) ApprovePayrollRun(ctx context.Context, id entity.PayrollRunID) error {
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		status, err := qtx.GetPayrollRunStatusByIdForUpdate(newCtx, int64(id))
+		if err != nil {
+			return fmt.Errorf("qtx.GetPayrollRunStatusByIdForUpdate(): %w", err)
+		}
+		if entity.PayrollRunStatus(status) != entity.PayrollRunStatus_Draft {
+			return fmt.Errorf("payrollRunId='%d' has status='%s': %w", id, status, errs.ErrInvalidPayrollRunStatusTransition)
+		}
+
+		authInfo := network.GetAuthInfo(newCtx)
+		err = qtx.ApprovePayrollRun(newCtx, mysql.ApprovePayrollRunParams{
+			ApprovedByUserID: sql.NullInt64{Int64: int64(authInfo.UserID), Valid: authInfo.UserID != identity.UserID_None},
+			ID:               int64(id),
+		})
+		if err != nil {
+			return fmt.Errorf("qtx.ApprovePayrollRun(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
+
+func (s teachingServiceImpl) MarkPayrollRunAsPaid(ctx context.Context, id entity.PayrollRunID) error {
+	err := s.mySQLQueries.ExecuteInTransaction(ctx, func(newCtx context.Context, qtx *mysql.Queries) error {
+		status, err := qtx.GetPayrollRunStatusByIdForUpdate(newCtx, int64(id))
+		if err != nil {
+			return fmt.Errorf("qtx.GetPayrollRunStatusByIdForUpdate(): %w", err)
+		}
+		if entity.PayrollRunStatus(status) != entity.PayrollRunStatus_Approved {
+			return fmt.Errorf("payrollRunId='%d' has status='%s': %w", id, status, errs.ErrInvalidPayrollRunStatusTransition)
+		}
+
+		err = qtx.MarkPayrollRunAsPaid(newCtx, int64(id))
+		if err != nil {
+			return fmt.Errorf("qtx.MarkPayrollRunAsPaid(): %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ExecuteInTransaction(): %w", err)
+	}
+
+	return nil
+}
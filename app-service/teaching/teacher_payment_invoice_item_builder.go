@@ -18,18 +18,80 @@ type teacherPaymentInvoiceItemRaw struct {
 	PaidCourseFeeValue    int32
 	PaidTransportFeeValue int32
 	AddedAt               time.Time
+
+	// this field is resolved from "TeacherFeeSharing" if this struct is constructed from "Attendance", otherwise it is derived from the paid values on "TeacherPayment"
+	FeeSharingRate feeSharingRate
+}
+
+type feeSharingRate struct {
+	CourseFeeSharingPercentage    float64
+	TransportFeeSharingPercentage float64
+	Source                        FeeSharingSource
+	TeacherFeeSharingID           entity.TeacherFeeSharingID
+}
+
+var teacherFeeSharingScopePriority = map[entity.TeacherFeeSharingScope]int{
+	entity.TeacherFeeSharingScope_Default:       0,
+	entity.TeacherFeeSharingScope_Course:        1,
+	entity.TeacherFeeSharingScope_Teacher:       2,
+	entity.TeacherFeeSharingScope_TeacherCourse: 3,
+}
+
+// resolveFeeSharingRate returns the rate of the most specific TeacherFeeSharing in force at the attendance's date: teacher+course > teacher > course > school-wide default.
+// Among TeacherFeeSharings with the same scope, the one with the latest EffectiveFrom is applied.
+//
+// Falls back to Default_CourseFeeSharingPercentage & Default_TransportFeeSharingPercentage when there's no TeacherFeeSharing in force.
+func resolveFeeSharingRate(teacherFeeSharings []entity.TeacherFeeSharing, attendance entity.Attendance) feeSharingRate {
+	teacherID := attendance.TeacherInfo.TeacherID
+	courseID := attendance.ClassInfo.Course.CourseID
+
+	var applied *entity.TeacherFeeSharing
+	for i, teacherFeeSharing := range teacherFeeSharings {
+		if teacherFeeSharing.TeacherID != entity.TeacherID_None && teacherFeeSharing.TeacherID != teacherID {
+			continue
+		}
+		if teacherFeeSharing.CourseID != entity.CourseID_None && teacherFeeSharing.CourseID != courseID {
+			continue
+		}
+		if teacherFeeSharing.EffectiveFrom.After(attendance.Date) {
+			continue
+		}
+
+		if applied == nil {
+			applied = &teacherFeeSharings[i]
+			continue
+		}
+		priority, appliedPriority := teacherFeeSharingScopePriority[teacherFeeSharing.Scope], teacherFeeSharingScopePriority[applied.Scope]
+		if priority > appliedPriority || (priority == appliedPriority && teacherFeeSharing.EffectiveFrom.After(applied.EffectiveFrom)) {
+			applied = &teacherFeeSharings[i]
+		}
+	}
+
+	if applied == nil {
+		return feeSharingRate{
+			CourseFeeSharingPercentage:    Default_CourseFeeSharingPercentage,
+			TransportFeeSharingPercentage: Default_TransportFeeSharingPercentage,
+			Source:                        FeeSharingSource_Fallback,
+		}
+	}
+	return feeSharingRate{
+		CourseFeeSharingPercentage:    applied.CourseFeeSharingPercentage,
+		TransportFeeSharingPercentage: applied.TransportFeeSharingPercentage,
+		Source:                        FeeSharingSource(applied.Scope),
+		TeacherFeeSharingID:           applied.TeacherFeeSharingID,
+	}
 }
 
 func (t teacherPaymentInvoiceItemRaw) toTPIIAttendanceWithTeacherPayment() tpii_AttendanceWithTeacherPayment {
 	attendance := t.Attendance
 
-	var courseFeeSharingPercentage float64 = Default_CourseFeeSharingPercentage
-	var transportFeeSharingPercentage float64 = Default_TransportFeeSharingPercentage
+	var courseFeeSharingPercentage float64 = t.FeeSharingRate.CourseFeeSharingPercentage
+	var transportFeeSharingPercentage float64 = t.FeeSharingRate.TransportFeeSharingPercentage
 	if t.PaidCourseFeeValue > 0 && t.GrossCourseFeeValue > 0 {
 		courseFeeSharingPercentage = float64(t.PaidCourseFeeValue) / float64(t.GrossCourseFeeValue)
 	}
 	if t.PaidTransportFeeValue > 0 && t.GrossTransportFeeValue > 0 {
-		courseFeeSharingPercentage = float64(t.PaidTransportFeeValue) / float64(t.GrossTransportFeeValue)
+		transportFeeSharingPercentage = float64(t.PaidTransportFeeValue) / float64(t.GrossTransportFeeValue)
 	}
 
 	return tpii_AttendanceWithTeacherPayment{
@@ -41,11 +103,14 @@ func (t teacherPaymentInvoiceItemRaw) toTPIIAttendanceWithTeacherPayment() tpii_
 			Duration:              attendance.Duration,
 			Note:                  attendance.Note,
 			IsPaid:                attendance.IsPaid,
+			IsSubstitute:          attendance.IsSubstitute,
 		},
 		GrossCourseFeeValue:           t.GrossCourseFeeValue,
 		GrossTransportFeeValue:        t.GrossTransportFeeValue,
 		CourseFeeSharingPercentage:    courseFeeSharingPercentage,
 		TransportFeeSharingPercentage: transportFeeSharingPercentage,
+		FeeSharingSource:              t.FeeSharingRate.Source,
+		TeacherFeeSharingID:           t.FeeSharingRate.TeacherFeeSharingID,
 
 		TeacherPaymentID:      t.TeacherPaymentID,
 		PaidCourseFeeValue:    t.PaidCourseFeeValue,
@@ -76,7 +141,8 @@ func NewTeacherPaymentInvoiceItemBuilder() *teacherPaymentInvoiceItemBuilder {
 	}
 }
 
-func (b *teacherPaymentInvoiceItemBuilder) AddAttendances(attendances []entity.Attendance) {
+// AddAttendances adds unpaid attendances, whose fee sharing percentages are resolved from teacherFeeSharings. Check resolveFeeSharingRate() for more information.
+func (b *teacherPaymentInvoiceItemBuilder) AddAttendances(attendances []entity.Attendance, teacherFeeSharings []entity.TeacherFeeSharing) {
 	for _, attendance := range attendances {
 		// THIS IS A CODE GUARD, THIS NORMALLY SHOULD NOT HAPPEN.
 		//
@@ -100,6 +166,8 @@ func (b *teacherPaymentInvoiceItemBuilder) AddAttendances(attendances []entity.A
 			PaidCourseFeeValue:    0,
 			PaidTransportFeeValue: 0,
 			AddedAt:               time.Time{},
+
+			FeeSharingRate: resolveFeeSharingRate(teacherFeeSharings, attendance),
 		})
 	}
 }
@@ -125,10 +193,30 @@ func (b *teacherPaymentInvoiceItemBuilder) AddTeacherPayments(teacherPayments []
 			PaidCourseFeeValue:    teacherPayment.PaidCourseFeeValue,
 			PaidTransportFeeValue: teacherPayment.PaidTransportFeeValue,
 			AddedAt:               teacherPayment.AddedAt,
+
+			FeeSharingRate: feeSharingRate{
+				CourseFeeSharingPercentage:    Default_CourseFeeSharingPercentage,
+				TransportFeeSharingPercentage: Default_TransportFeeSharingPercentage,
+				Source:                        FeeSharingSource_TeacherPayment,
+			},
 		})
 	}
 }
 
+// ApplySubstituteCourseFeeValues recalculates the gross course fee of the added unpaid substitutions, as a substitute teacher is paid at their own course fee rate,
+// instead of the StudentLearningToken's rate (which follows the original teacher's rate). attendanceIdToCourseFeeValue must contain the course fee value (not the quarter value) of each substitution.
+//
+// Existing TeacherPayments are never recalculated, to keep the recorded payments (and payslips) unchanged.
+func (b *teacherPaymentInvoiceItemBuilder) ApplySubstituteCourseFeeValues(attendanceIdToCourseFeeValue map[entity.AttendanceID]int32) {
+	for i, rawItem := range b.rawItems {
+		courseFeeValue, ok := attendanceIdToCourseFeeValue[rawItem.Attendance.AttendanceID]
+		if !ok || !rawItem.Attendance.IsSubstitute || rawItem.TeacherPaymentID != entity.TeacherPaymentID_None {
+			continue
+		}
+		b.rawItems[i].GrossCourseFeeValue = int32(float64(courseFeeValue/Default_OneCourseCycle) * rawItem.Attendance.UsedStudentTokenQuota)
+	}
+}
+
 func (b *teacherPaymentInvoiceItemBuilder) Build() []TeacherPaymentInvoiceItem {
 	teacherPaymentInvoiceItems := make([]TeacherPaymentInvoiceItem, 0, 1)
 
@@ -0,0 +1,60 @@
+package teaching
+
+import (
+	"sonamusica-backend/app-service/entity"
+	"sonamusica-backend/app-service/util"
+)
+
+// NewTeacherPayslip flattens the TeacherPaymentInvoiceItems (which are grouped by class > student > StudentLearningToken > attendance) into per-class lines, and sums up each class' subtotal & the grand total.
+//
+// The invoice items must be built from existing TeacherPayments (i.e. GetExistingTeacherPaymentInvoiceItems()), as only they have the paid fee values.
+func NewTeacherPayslip(teacher entity.Teacher, timeSpec util.TimeSpec, invoiceItems []TeacherPaymentInvoiceItem) TeacherPayslip {
+	payslip := TeacherPayslip{
+		Teacher:       teacher,
+		StartDatetime: timeSpec.StartDatetime,
+		EndDatetime:   timeSpec.EndDatetime,
+		Classes:       make([]TeacherPayslipClass, 0, len(invoiceItems)),
+	}
+
+	for _, invoiceItem := range invoiceItems {
+		payslipClass := TeacherPayslipClass{
+			ClassInfo_Minimal: invoiceItem.ClassInfo_Minimal,
+			Lines:             make([]TeacherPayslipLine, 0),
+		}
+		for _, student := range invoiceItem.Students {
+			for _, slt := range student.StudentLearningTokens {
+				for _, attendance := range slt.Attendances {
+					payslipClass.Lines = append(payslipClass.Lines, TeacherPayslipLine{
+						TeacherPaymentID:              attendance.TeacherPaymentID,
+						Date:                          attendance.Date,
+						StudentInfo:                   student.StudentInfo_Minimal,
+						UsedStudentTokenQuota:         attendance.UsedStudentTokenQuota,
+						GrossCourseFeeValue:           attendance.GrossCourseFeeValue,
+						GrossTransportFeeValue:        attendance.GrossTransportFeeValue,
+						CourseFeeSharingPercentage:    attendance.CourseFeeSharingPercentage,
+						TransportFeeSharingPercentage: attendance.TransportFeeSharingPercentage,
+						PaidCourseFeeValue:            attendance.PaidCourseFeeValue,
+						PaidTransportFeeValue:         attendance.PaidTransportFeeValue,
+					})
+				}
+			}
+		}
+
+		for _, line := range payslipClass.Lines {
+			payslipClass.Subtotal.add(line)
+			payslip.GrandTotal.add(line)
+		}
+		payslip.Classes = append(payslip.Classes, payslipClass)
+	}
+
+	return payslip
+}
+
+func (t *TeacherPayslipTotal) add(line TeacherPayslipLine) {
+	t.TotalAttendances += line.UsedStudentTokenQuota
+	t.TotalGrossCourseFeeValue += int64(line.GrossCourseFeeValue)
+	t.TotalGrossTransportFeeValue += int64(line.GrossTransportFeeValue)
+	t.TotalPaidCourseFeeValue += int64(line.PaidCourseFeeValue)
+	t.TotalPaidTransportFeeValue += int64(line.PaidTransportFeeValue)
+	t.TotalPaidFeeValue += int64(line.PaidCourseFeeValue) + int64(line.PaidTransportFeeValue)
+}
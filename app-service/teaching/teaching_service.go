@@ -13,6 +13,7 @@ const (
 	Default_OneCourseCycle                = 4
 	Default_BalanceTopUp                  = Default_OneCourseCycle
 	Default_PenaltyFeeValue               = 10000
+	Default_PenaltyTriggerDayOfMonth      = 10
 	Default_CourseFeeSharingPercentage    = 0.5
 	Default_TransportFeeSharingPercentage = 1.0
 )
@@ -33,6 +34,187 @@ type StudentEnrollmentInvoice struct {
 	DiscountFeeValue  int32      `json:"discountFeeValue"`
 	LastPaymentDate   *time.Time `json:"lastPaymentDate,omitempty"`
 	DaysLate          int32      `json:"daysLate"`
+	// PenaltyPolicy is the policy which produces PenaltyFeeValue & DaysLate. Nil when there's no applicable policy, and the default constants are used instead.
+	PenaltyPolicy *entity.PenaltyPolicy `json:"penaltyPolicy,omitempty"`
+	// Discounts are the applied DiscountRules, whose values sum up to DiscountFeeValue.
+	Discounts []AppliedDiscount `json:"discounts"`
+}
+
+// FamilyInvoice combines the StudentEnrollmentInvoices of all active StudentEnrollments in a Family, so that they can be paid at once.
+type FamilyInvoice struct {
+	Family entity.Family       `json:"family"`
+	Items  []FamilyInvoiceItem `json:"items"`
+	// TotalValue is the sum of the items' course, transport & penalty fee, minus their discount fee.
+	TotalValue int64 `json:"totalValue"`
+}
+
+type FamilyInvoiceItem struct {
+	StudentEnrollment entity.StudentEnrollment `json:"studentEnrollment"`
+	Invoice           StudentEnrollmentInvoice `json:"invoice"`
+}
+
+// AppliedDiscount is a DiscountRule applied on an invoice, along with its calculated discount value.
+type AppliedDiscount struct {
+	DiscountRuleID entity.DiscountRuleID   `json:"discountRuleId"`
+	Name           string                  `json:"name"`
+	Type           entity.DiscountRuleType `json:"type"`
+	Value          int32                   `json:"value"`
+}
+
+// EnrollmentPaymentReceipt is the issued receipt of an EnrollmentPayment. ReceiptNumber is sequential & gap-free, and never changes once issued.
+type EnrollmentPaymentReceipt struct {
+	ReceiptNumber     int64                    `json:"receiptNumber"`
+	IssuedAt          time.Time                `json:"issuedAt"`
+	EnrollmentPayment entity.EnrollmentPayment `json:"enrollmentPayment"`
+}
+
+// CashUpReport summarizes the EnrollmentPayments received & the EnrollmentPaymentRefunds paid out on a day, for reconciling them with the counted cash & the bank statements.
+type CashUpReport struct {
+	Date   time.Time           `json:"date"`
+	Groups []CashUpReportGroup `json:"groups"`
+	// RecordedCashValue is the total of the "CASH" groups after their refunds, i.e. the cash which should be in the drawer
+	RecordedCashValue int64 `json:"recordedCashValue"`
+	// CashUpDay is nil when the day has not been closed yet
+	CashUpDay *entity.CashUpDay `json:"cashUpDay,omitempty"`
+}
+
+// CashUpReportGroup is the total received money (i.e. after discount) of a payment method & receiving account.
+//
+// A refund is paid out through the same payment method & receiving account as its EnrollmentPayment, thus is grouped by the refunded EnrollmentPayment's.
+type CashUpReportGroup struct {
+	PaymentMethod      entity.PaymentMethod `json:"paymentMethod"`
+	ReceivingAccount   string               `json:"receivingAccount"`
+	TotalPayments      int64                `json:"totalPayments"`
+	TotalValue         int64                `json:"totalValue"`
+	TotalRefunds       int64                `json:"totalRefunds"`
+	TotalRefundedValue int64                `json:"totalRefundedValue"`
+}
+
+type EnrollmentPaymentRefundID int64
+
+const (
+	EnrollmentPaymentRefundID_None EnrollmentPaymentRefundID = iota
+)
+
+// EnrollmentPaymentRefund is a refund (credit note) of an EnrollmentPayment, which is kept as is for history.
+// RefundedValue is subtracted from the income on the month of RefundDate, and WithdrawnQuota is subtracted from the EnrollmentPayment's StudentLearningToken.
+type EnrollmentPaymentRefund struct {
+	EnrollmentPaymentRefundID EnrollmentPaymentRefundID  `json:"enrollmentPaymentRefundId"`
+	EnrollmentPaymentID       entity.EnrollmentPaymentID `json:"enrollmentPaymentId"`
+	PaymentDate               time.Time                  `json:"paymentDate"`
+	StudentEnrollmentID       entity.StudentEnrollmentID `json:"studentEnrollmentId"`
+	StudentInfo               entity.StudentInfo_Minimal `json:"student"`
+	RefundDate                time.Time                  `json:"refundDate"`
+	RefundedValue             int32                      `json:"refundedValue"`
+	WithdrawnQuota            float64                    `json:"withdrawnQuota"`
+	Reason                    string                     `json:"reason"`
+	CreatedAt                 time.Time                  `json:"createdAt"`
+	CreatedByUserID           identity.UserID            `json:"createdByUserId,omitempty"`
+}
+
+type InstallmentPlanID int64
+type InstallmentID int64
+
+const (
+	InstallmentPlanID_None InstallmentPlanID = iota
+)
+const (
+	InstallmentID_None InstallmentID = iota
+)
+
+// InstallmentPlan splits a StudentEnrollmentInvoice into several Installments, which are paid separately via SubmitEnrollmentPayment.
+// Each paid Installment releases its BalanceTopUp as StudentLearningToken quota, thus the student can only attend what has been paid.
+type InstallmentPlan struct {
+	InstallmentPlanID InstallmentPlanID        `json:"installmentPlanId"`
+	StudentEnrollment entity.StudentEnrollment `json:"studentEnrollment"`
+	BalanceTopUp      int32                    `json:"balanceTopUp"`
+	BalanceBonus      int32                    `json:"balanceBonus"`
+	CourseFeeValue    int32                    `json:"courseFeeValue"`
+	TransportFeeValue int32                    `json:"transportFeeValue"`
+	PenaltyFeeValue   int32                    `json:"penaltyFeeValue"`
+	DiscountFeeValue  int32                    `json:"discountFeeValue"`
+	// TotalValue is the course, transport & penalty fee, minus the discount fee.
+	TotalValue       int64           `json:"totalValue"`
+	InstallmentCount int32           `json:"installmentCount"`
+	Installments     []Installment   `json:"installments"`
+	CreatedAt        time.Time       `json:"createdAt"`
+	CreatedByUserID  identity.UserID `json:"createdByUserId,omitempty"`
+}
+
+// Installment is a part of an InstallmentPlan. It is unpaid until an EnrollmentPayment is submitted for it, and becomes unpaid again when the EnrollmentPayment is removed.
+type Installment struct {
+	InstallmentID     InstallmentID     `json:"installmentId"`
+	InstallmentPlanID InstallmentPlanID `json:"installmentPlanId"`
+	// InstallmentNumber starts from 1
+	InstallmentNumber int32     `json:"installmentNumber"`
+	DueDate           time.Time `json:"dueDate"`
+	// BalanceTopUp is the StudentLearningToken quota released by paying this installment.
+	BalanceTopUp      int32 `json:"balanceTopUp"`
+	BalanceBonus      int32 `json:"balanceBonus"`
+	CourseFeeValue    int32 `json:"courseFeeValue"`
+	TransportFeeValue int32 `json:"transportFeeValue"`
+	PenaltyFeeValue   int32 `json:"penaltyFeeValue"`
+	DiscountFeeValue  int32 `json:"discountFeeValue"`
+	// Value is the course, transport & penalty fee, minus the discount fee.
+	Value int32 `json:"value"`
+
+	// EnrollmentPaymentID & PaymentDate are only populated when the installment has been paid.
+	EnrollmentPaymentID entity.EnrollmentPaymentID `json:"enrollmentPaymentId,omitempty"`
+	PaymentDate         *time.Time                 `json:"paymentDate,omitempty"`
+}
+
+// OverdueInstallment is an unpaid Installment whose DueDate has passed.
+type OverdueInstallment struct {
+	Installment
+	StudentEnrollmentID entity.StudentEnrollmentID `json:"studentEnrollmentId"`
+	StudentInfo         entity.StudentInfo_Minimal `json:"student"`
+	DaysOverdue         int32                      `json:"daysOverdue"`
+}
+
+type PaymentReminderID int64
+
+const (
+	PaymentReminderID_None PaymentReminderID = iota
+)
+
+// PaymentReminderType is the reason of a PaymentReminder. When multiple reasons apply, the first one in this order is used: "OWING", "OVERDUE", "PENALTY_APPROACHING".
+type PaymentReminderType string
+
+const (
+	// PaymentReminderType_Owing is for StudentEnrollments having negative StudentLearningToken quota, i.e. they have attended more than what they've paid for.
+	PaymentReminderType_Owing PaymentReminderType = "OWING"
+	// PaymentReminderType_Overdue is for StudentEnrollments whose invoice is being charged with penalty fee.
+	PaymentReminderType_Overdue PaymentReminderType = "OVERDUE"
+	// PaymentReminderType_PenaltyApproaching is for StudentEnrollments whose penalty starts within the configured days.
+	PaymentReminderType_PenaltyApproaching PaymentReminderType = "PENALTY_APPROACHING"
+)
+
+// PaymentReminder is a sent reminder email, along with the invoice values shown in the email.
+type PaymentReminder struct {
+	PaymentReminderID   PaymentReminderID          `json:"paymentReminderId"`
+	StudentEnrollmentID entity.StudentEnrollmentID `json:"studentEnrollmentId"`
+	StudentInfo         entity.StudentInfo_Minimal `json:"student"`
+	Type                PaymentReminderType        `json:"type"`
+	RecipientEmail      string                     `json:"recipientEmail"`
+	OwedQuota           float64                    `json:"owedQuota"`
+	CourseFeeValue      int32                      `json:"courseFeeValue"`
+	TransportFeeValue   int32                      `json:"transportFeeValue"`
+	PenaltyFeeValue     int32                      `json:"penaltyFeeValue"`
+	DiscountFeeValue    int32                      `json:"discountFeeValue"`
+	SentAt              time.Time                  `json:"sentAt"`
+}
+
+// PaymentReminderFailure is a StudentEnrollment which should be reminded, but the email cannot be sent, e.g. the student has no email.
+type PaymentReminderFailure struct {
+	StudentEnrollmentID entity.StudentEnrollmentID `json:"studentEnrollmentId"`
+	Reason              string                     `json:"reason"`
+}
+
+// PaymentReminderOptOut is a student who doesn't receive any payment reminder.
+type PaymentReminderOptOut struct {
+	StudentInfo      entity.StudentInfo_Minimal `json:"student"`
+	OptedOutAt       time.Time                  `json:"optedOutAt"`
+	OptedOutByUserID identity.UserID            `json:"optedOutByUserId,omitempty"`
 }
 
 type StudentIDToSLTs struct {
@@ -40,6 +222,127 @@ type StudentIDToSLTs struct {
 	StudentLearningTokens []entity.StudentLearningToken_Minimal `json:"studentLearningTokens"`
 }
 
+// StudentLearningTokenHistory is the ledger of a StudentLearningToken, along with the running balance after each transaction.
+type StudentLearningTokenHistory struct {
+	StudentLearningToken entity.StudentLearningToken `json:"studentLearningToken"`
+	Transactions         []SLTTransactionWithBalance `json:"transactions"`
+	// LedgerQuota is the sum of all transactions' QuotaChange. It must equal StudentLearningToken.Quota, else IsConsistent is false.
+	LedgerQuota  float64 `json:"ledgerQuota"`
+	IsConsistent bool    `json:"isConsistent"`
+}
+
+type SLTTransactionWithBalance struct {
+	entity.SLTTransaction
+	Balance float64 `json:"balance"`
+}
+
+// SLTReconciliationReport lists every StudentLearningToken whose stored quota differs from its expected quota.
+//
+// The expected quota is the sum of all matching EnrollmentPayments' (BalanceTopUp + BalanceBonus), subtracted by the used quota of all Attendances which use the token.
+// The quota moved by transfers & withdrawn by refunds are taken from the StudentLearningToken ledger.
+type SLTReconciliationReport struct {
+	TotalCheckedTokens int             `json:"totalCheckedTokens"`
+	Drifts             []SLTQuotaDrift `json:"drifts"`
+	// UnmatchedEnrollmentPaymentIDs are EnrollmentPayments which have no StudentLearningToken with the same fee quarter values, which indicates bad data.
+	UnmatchedEnrollmentPaymentIDs []entity.EnrollmentPaymentID `json:"unmatchedEnrollmentPaymentIds"`
+	IsRepaired                    bool                         `json:"isRepaired"`
+}
+
+type SLTQuotaDrift struct {
+	StudentLearningTokenID entity.StudentLearningTokenID `json:"studentLearningTokenId"`
+	StudentEnrollmentID    entity.StudentEnrollmentID    `json:"studentEnrollmentId"`
+	StoredQuota            float64                       `json:"storedQuota"`
+	ExpectedQuota          float64                       `json:"expectedQuota"`
+	PaidQuota              float64                       `json:"paidQuota"`
+	UsedQuota              float64                       `json:"usedQuota"`
+}
+
+// SLTChangesPreview is the result of a dry-run, which lists every StudentLearningToken that would be created, or whose quota would be changed.
+//
+// As the dry-run transaction is rolled back, IDs of newly created entities (e.g. new StudentLearningToken, SLTTransaction.SourceID of new Attendance) are only placeholders.
+type SLTChangesPreview struct {
+	Changes []SLTChange `json:"changes"`
+}
+
+type SLTChange struct {
+	StudentLearningToken entity.StudentLearningToken `json:"studentLearningToken"`
+	// IsNew is true when the token would be newly created, e.g. by SubmitEnrollmentPayment() on new fee values, or by AddAttendance() for students without any token.
+	IsNew       bool    `json:"isNew"`
+	QuotaBefore float64 `json:"quotaBefore"`
+	QuotaAfter  float64 `json:"quotaAfter"`
+	// Transactions are the SLTTransactions which cause the quota change, sorted ascendingly by their insertion order.
+	Transactions []entity.SLTTransaction `json:"transactions"`
+}
+
+// SLTTransfer is the result of TransferStudentLearningTokens, which is also recorded for auditing purpose.
+type SLTTransfer struct {
+	SLTTransferID                     int64                         `json:"sltTransferId"`
+	SourceStudentLearningTokenID      entity.StudentLearningTokenID `json:"sourceStudentLearningTokenId"`
+	DestinationStudentLearningTokenID entity.StudentLearningTokenID `json:"destinationStudentLearningTokenId"`
+	// TransferredQuota is deducted from the source token, while ConvertedQuota is added into the destination token.
+	TransferredQuota float64 `json:"transferredQuota"`
+	ConvertedQuota   float64 `json:"convertedQuota"`
+	// RelinkedAttendanceIDs are the unpaid attendances which are moved from the source token into the destination token.
+	RelinkedAttendanceIDs []entity.AttendanceID `json:"relinkedAttendanceIds"`
+}
+
+// SLTRepricing is the result of RepriceStudentLearningTokens for a single StudentLearningToken, whose remaining quota is moved into a token with the new price.
+type SLTRepricing struct {
+	SLTTransfer
+	StudentEnrollmentID       entity.StudentEnrollmentID `json:"studentEnrollmentId"`
+	PrevCourseFeeQuarterValue int32                      `json:"prevCourseFeeQuarterValue"`
+	NewCourseFeeQuarterValue  int32                      `json:"newCourseFeeQuarterValue"`
+}
+
+// ClassLevelUp is the result of LevelUpClass, which moves a class into a new course (e.g. Piano Grade 2 -> Piano Grade 3) from EffectiveDate.
+type ClassLevelUp struct {
+	ClassID       entity.ClassID   `json:"classId"`
+	PrevCourseID  entity.CourseID  `json:"prevCourseId"`
+	NewCourseID   entity.CourseID  `json:"newCourseId"`
+	EffectiveDate time.Time        `json:"effectiveDate"`
+	Students      []StudentLevelUp `json:"students"`
+}
+
+// StudentLevelUp is the StudentLearningToken changes of a single student of a ClassLevelUp.
+type StudentLevelUp struct {
+	StudentEnrollmentID entity.StudentEnrollmentID `json:"studentEnrollmentId"`
+	StudentID           entity.StudentID           `json:"studentId"`
+	// NewStudentLearningTokenID is the token with the new course's price, which is used by the attendances since the effective date.
+	NewStudentLearningTokenID entity.StudentLearningTokenID `json:"newStudentLearningTokenId"`
+	NewCourseFeeQuarterValue  int32                         `json:"newCourseFeeQuarterValue"`
+	// SLTRepricings are the old-price tokens whose unpaid attendances (since the effective date) and remaining quota are moved into the new token.
+	SLTRepricings []SLTRepricing `json:"sltRepricings"`
+}
+
+type AttendanceTokenAssignmentStrategy string
+
+const (
+	// AttendanceTokenAssignmentStrategy_ExplicitSLT assigns the given StudentLearningToken to every attendance of its StudentEnrollment.
+	AttendanceTokenAssignmentStrategy_ExplicitSLT AttendanceTokenAssignmentStrategy = "EXPLICIT_SLT"
+	// AttendanceTokenAssignmentStrategy_EarliestAvailable assigns each attendance to its student's earliest token with positive quota, or the latest token when none has positive quota (the same token as AddAttendance()).
+	AttendanceTokenAssignmentStrategy_EarliestAvailable AttendanceTokenAssignmentStrategy = "EARLIEST_AVAILABLE"
+)
+
+// AttendanceTokenAssignments is the result of AssignAttendancesTokens.
+type AttendanceTokenAssignments struct {
+	Assignments []AttendanceTokenAssignment        `json:"assignments"`
+	Failures    []AttendanceTokenAssignmentFailure `json:"failures"`
+}
+
+type AttendanceTokenAssignment struct {
+	AttendanceID           entity.AttendanceID           `json:"attendanceId"`
+	StudentID              entity.StudentID              `json:"studentId"`
+	StudentLearningTokenID entity.StudentLearningTokenID `json:"studentLearningTokenId"`
+}
+
+// AttendanceTokenAssignmentFailure is a token-less attendance which cannot be assigned, e.g. the student has no StudentLearningToken.
+type AttendanceTokenAssignmentFailure struct {
+	AttendanceID entity.AttendanceID `json:"attendanceId"`
+	StudentID    entity.StudentID    `json:"studentId"`
+	Date         time.Time           `json:"date"`
+	Reason       string              `json:"reason"`
+}
+
 type TeacherForPayment struct {
 	entity.TeacherInfo_Minimal
 	TotalAttendances float64 `json:"totalAttendances"`
@@ -49,6 +352,25 @@ type TeacherForPayment struct {
 	ClassesNeedTokenAssignment   []entity.Class `json:"classesNeedTokenAssignment,omitempty"` // this is useful in FE for displaying direct link to the classes that contain the attendances-without-SLT
 }
 
+// TeacherSubstitutionSummary sums up the substitutions of a teacher: given (teaching on behalf of the original teacher) & received (being substituted by another teacher).
+type TeacherSubstitutionSummary struct {
+	entity.TeacherInfo_Minimal
+	TotalGiven         int64   `json:"totalGiven"`
+	TotalGivenQuota    float64 `json:"totalGivenQuota"`
+	TotalReceived      int64   `json:"totalReceived"`
+	TotalReceivedQuota float64 `json:"totalReceivedQuota"`
+}
+
+// PayrollRunTeacherTotal sums up the TeacherPayments of a teacher in a PayrollRun.
+type PayrollRunTeacherTotal struct {
+	entity.TeacherInfo_Minimal
+	TotalTeacherPayments       int64   `json:"totalTeacherPayments"`
+	TotalAttendances           float64 `json:"totalAttendances"`
+	TotalPaidCourseFeeValue    int64   `json:"totalPaidCourseFeeValue"`
+	TotalPaidTransportFeeValue int64   `json:"totalPaidTransportFeeValue"`
+	TotalPaidFeeValue          int64   `json:"totalPaidFeeValue"`
+}
+
 // TeacherPaymentInvoiceItem is an "Attendance"/"TeacherPayment" which is reshaped (grouped-by in multi-level) for a rendering requirement in FE's TeacherPayment page.
 //
 // All information inside this nested struct is literally extracted from "Attendance"/"TeacherPayment".
@@ -74,6 +396,9 @@ type tpii_AttendanceWithTeacherPayment struct {
 	GrossTransportFeeValue        int32   `json:"grossTransportFeeValue"`
 	CourseFeeSharingPercentage    float64 `json:"courseFeeSharingPercentage"`
 	TransportFeeSharingPercentage float64 `json:"transportFeeSharingPercentage"`
+	// FeeSharingSource tells where the 2 sharing percentages above come from. TeacherFeeSharingID is only populated when they come from an entity.TeacherFeeSharing.
+	FeeSharingSource    FeeSharingSource           `json:"feeSharingSource"`
+	TeacherFeeSharingID entity.TeacherFeeSharingID `json:"teacherFeeSharingId,omitempty"`
 
 	// we allow ",omitempty", as these fields are only populated when this struct is created from "TeacherPayment" instead of "Attendance"
 	// please refer to struct "teacherPaymentInvoiceItemRaw" for more information.
@@ -83,6 +408,60 @@ type tpii_AttendanceWithTeacherPayment struct {
 	AddedAt               time.Time               `json:"addedAt,omitempty"`
 }
 
+// FeeSharingSource describes where the fee sharing percentages of a TeacherPaymentInvoiceItem's attendance come from.
+//
+// The first 4 values are identical with entity.TeacherFeeSharingScope, i.e. the scope of the applied entity.TeacherFeeSharing.
+type FeeSharingSource string
+
+const (
+	FeeSharingSource_Default       FeeSharingSource = FeeSharingSource(entity.TeacherFeeSharingScope_Default)
+	FeeSharingSource_Course        FeeSharingSource = FeeSharingSource(entity.TeacherFeeSharingScope_Course)
+	FeeSharingSource_Teacher       FeeSharingSource = FeeSharingSource(entity.TeacherFeeSharingScope_Teacher)
+	FeeSharingSource_TeacherCourse FeeSharingSource = FeeSharingSource(entity.TeacherFeeSharingScope_TeacherCourse)
+	// FeeSharingSource_Fallback is used when there's no entity.TeacherFeeSharing in force, thus Default_CourseFeeSharingPercentage & Default_TransportFeeSharingPercentage apply.
+	FeeSharingSource_Fallback FeeSharingSource = "FALLBACK"
+	// FeeSharingSource_TeacherPayment is used when the percentages are derived from the paid values of an existing TeacherPayment.
+	FeeSharingSource_TeacherPayment FeeSharingSource = "TEACHER_PAYMENT"
+)
+
+// TeacherPayslip summarizes the TeacherPayments of a teacher within a period, grouped by class. Check NewTeacherPayslip() for more information.
+type TeacherPayslip struct {
+	Teacher       entity.Teacher        `json:"teacher"`
+	StartDatetime time.Time             `json:"startDatetime"`
+	EndDatetime   time.Time             `json:"endDatetime"`
+	Classes       []TeacherPayslipClass `json:"classes"`
+	GrandTotal    TeacherPayslipTotal   `json:"grandTotal"`
+}
+
+type TeacherPayslipClass struct {
+	entity.ClassInfo_Minimal
+	Lines    []TeacherPayslipLine `json:"lines"`
+	Subtotal TeacherPayslipTotal  `json:"subtotal"`
+}
+
+// TeacherPayslipLine is a paid attendance, i.e. a TeacherPayment.
+type TeacherPayslipLine struct {
+	TeacherPaymentID              entity.TeacherPaymentID    `json:"teacherPaymentId"`
+	Date                          time.Time                  `json:"date"`
+	StudentInfo                   entity.StudentInfo_Minimal `json:"student"`
+	UsedStudentTokenQuota         float64                    `json:"usedStudentTokenQuota"`
+	GrossCourseFeeValue           int32                      `json:"grossCourseFeeValue"`
+	GrossTransportFeeValue        int32                      `json:"grossTransportFeeValue"`
+	CourseFeeSharingPercentage    float64                    `json:"courseFeeSharingPercentage"`
+	TransportFeeSharingPercentage float64                    `json:"transportFeeSharingPercentage"`
+	PaidCourseFeeValue            int32                      `json:"paidCourseFeeValue"`
+	PaidTransportFeeValue         int32                      `json:"paidTransportFeeValue"`
+}
+
+type TeacherPayslipTotal struct {
+	TotalAttendances            float64 `json:"totalAttendances"`
+	TotalGrossCourseFeeValue    int64   `json:"totalGrossCourseFeeValue"`
+	TotalGrossTransportFeeValue int64   `json:"totalGrossTransportFeeValue"`
+	TotalPaidCourseFeeValue     int64   `json:"totalPaidCourseFeeValue"`
+	TotalPaidTransportFeeValue  int64   `json:"totalPaidTransportFeeValue"`
+	TotalPaidFeeValue           int64   `json:"totalPaidFeeValue"`
+}
+
 type TeachingService interface {
 	GetUserTeachingInfo(ctx context.Context, id identity.UserID) (UserTeachingInfo, error)
 	IsUserInvolvedInClass(ctx context.Context, userId identity.UserID, classId entity.ClassID) (bool, error)
@@ -90,40 +469,154 @@ type TeachingService interface {
 
 	SearchEnrollmentPayment(ctx context.Context, timeFilter util.TimeSpec) ([]entity.EnrollmentPayment, error)
 	// GetEnrollmentPaymentInvoice returns values for used by SubmitEnrollmentPayment.
-	// This includes calculating teacherSpecialFee, and penaltyFee.
-	GetEnrollmentPaymentInvoice(ctx context.Context, studentEnrollmentID entity.StudentEnrollmentID) (StudentEnrollmentInvoice, error)
+	// This includes calculating teacherSpecialFee, penaltyFee (based on the applicable PenaltyPolicy), and discountFee (based on the applicable DiscountRules).
+	//
+	// All fees are calculated as of paymentDate (or now, when it is zero), i.e. the course fee follows the price in force on paymentDate.
+	// voucherCode is optional, returns errs.ErrVoucherNotFound when it doesn't exist, or errs.ErrDiscountRuleNotApplicable when it is expired or used up.
+	GetEnrollmentPaymentInvoice(ctx context.Context, studentEnrollmentID entity.StudentEnrollmentID, paymentDate time.Time, voucherCode string) (StudentEnrollmentInvoice, error)
 	// SubmitEnrollmentPayment adds new enrollmentPayment, then upsert StudentLearningToken (insert new, or update quota).
 	// The SLT update will sum up spec.BalanceTopUp with all negative quota, set them to 0, and set the summed quota for the earliest available SLT.
-	SubmitEnrollmentPayment(ctx context.Context, spec SubmitStudentEnrollmentPaymentSpec) error
+	// It returns the ID of the new enrollmentPayment.
+	//
+	// spec.DiscountRuleIDs are re-evaluated (similar to GetEnrollmentPaymentInvoice()), and recorded for reporting the discount cost by promotion.
+	// Returns errs.ErrDiscountRuleNotApplicable when any of them doesn't apply anymore, or errs.ErrDiscountExceedsDiscountFee when their total exceeds spec.DiscountFeeValue.
+	// The remaining of spec.DiscountFeeValue is considered as a manual discount.
+	SubmitEnrollmentPayment(ctx context.Context, spec SubmitStudentEnrollmentPaymentSpec) (entity.EnrollmentPaymentID, error)
+	// PreviewSubmitEnrollmentPayment runs SubmitEnrollmentPayment() in a dry-run transaction, and returns the StudentLearningToken changes without persisting them.
+	PreviewSubmitEnrollmentPayment(ctx context.Context, spec SubmitStudentEnrollmentPaymentSpec) (SLTChangesPreview, error)
+	// GetFamilyInvoice returns the GetEnrollmentPaymentInvoice() of every active StudentEnrollment in the Family.
+	GetFamilyInvoice(ctx context.Context, familyID entity.FamilyID, paymentDate time.Time) (FamilyInvoice, error)
+	// SubmitFamilyPayment splits a single payment of a Family into SubmitEnrollmentPayment() of each item, in a single transaction.
+	// Returns errs.ErrStudentEnrollmentNotInFamily when any item's StudentEnrollment doesn't belong to the Family's Students.
+	SubmitFamilyPayment(ctx context.Context, spec SubmitFamilyPaymentSpec) ([]entity.EnrollmentPaymentID, error)
+	// EditEnrollmentPayment updates the safe attributes of an EnrollmentPayment, and adjusts its StudentLearningToken quota by the BalanceBonus change.
+	// Returns errs.ErrDiscountFeeLocked when the DiscountFeeValue is changed on an EnrollmentPayment which has applied DiscountRules.
 	EditEnrollmentPayment(ctx context.Context, spec EditStudentEnrollmentPaymentSpec) (entity.EnrollmentPaymentID, error)
+	// RemoveEnrollmentPayment deletes the EnrollmentPayment as if it never happened. Returns errs.ErrEnrollmentPaymentRefunded when the EnrollmentPayment has been refunded.
 	RemoveEnrollmentPayment(ctx context.Context, enrollmentPaymentID entity.EnrollmentPaymentID) error
+	// RefundEnrollmentPayment records a refund of an EnrollmentPayment, and withdraws spec.WithdrawnQuota from the EnrollmentPayment's StudentLearningToken.
+	// Unlike RemoveEnrollmentPayment(), the EnrollmentPayment is kept, and the refund is subtracted from the income on the month of spec.RefundDate.
+	//
+	// Returns errs.ErrRefundExceedsEnrollmentPayment when the total refunds exceed the EnrollmentPayment's received value (course + transport + penalty - discount), or its topped-up quota (BalanceTopUp + BalanceBonus).
+	// Returns errs.ErrRefundBeforeEnrollmentPayment when spec.RefundDate is before the EnrollmentPayment's PaymentDate, and errs.ErrCashUpDayClosed when spec.RefundDate is on a closed CashUpDay.
+	RefundEnrollmentPayment(ctx context.Context, spec RefundEnrollmentPaymentSpec) (EnrollmentPaymentRefundID, error)
+	GetEnrollmentPaymentRefunds(ctx context.Context, pagination util.PaginationSpec) (GetEnrollmentPaymentRefundsResult, error)
+	// CreateInstallmentPlan splits the invoice (from GetEnrollmentPaymentInvoice()) of spec.CourseCycles course cycles into len(spec.DueDates) installments.
+	// Check CalculateInstallments() for how the values are split. The invoice's discounts are charged as a manual discount, as the DiscountRules are not re-evaluated on each installment.
+	//
+	// Returns errs.ErrInstallmentCountExceedsQuota when there are more installments than the invoice's BalanceTopUp, as each installment must release at least 1 quota,
+	// and errs.ErrInstallmentFeeNotSplittable when the fees cannot be split without changing the installments' StudentLearningToken.
+	CreateInstallmentPlan(ctx context.Context, spec CreateInstallmentPlanSpec) (InstallmentPlanID, error)
+	GetInstallmentPlanById(ctx context.Context, id InstallmentPlanID) (InstallmentPlan, error)
+	// PayInstallment submits an EnrollmentPayment (via SubmitEnrollmentPayment()) with the installment's values, which releases the installment's quota.
+	// Returns errs.ErrInstallmentAlreadyPaid when the installment has been paid.
+	PayInstallment(ctx context.Context, spec PayInstallmentSpec) (entity.EnrollmentPaymentID, error)
+	// GetOverdueInstallments returns the unpaid installments whose due date is before the given date (defaults to now), sorted by the earliest due date.
+	GetOverdueInstallments(ctx context.Context, date time.Time, pagination util.PaginationSpec) (GetOverdueInstallmentsResult, error)
+	// GetEnrollmentPaymentReceipt returns the receipt of an EnrollmentPayment, which has been issued (with its receipt number) along with the EnrollmentPayment insertion.
+	GetEnrollmentPaymentReceipt(ctx context.Context, enrollmentPaymentID entity.EnrollmentPaymentID) (EnrollmentPaymentReceipt, error)
+	// GetCashUpReport returns the EnrollmentPayments' totals of a day (defaults to today), grouped by payment method & receiving account.
+	GetCashUpReport(ctx context.Context, date time.Time) (CashUpReport, error)
+	// CloseCashUpDay closes a day after the cash-up, which locks the day's EnrollmentPayments, and records the counted-vs-recorded cash difference.
+	CloseCashUpDay(ctx context.Context, spec CloseCashUpDaySpec) (entity.CashUpDay, error)
+	// ReopenCashUpDay unlocks the EnrollmentPayments of a closed day, by removing its CashUpDay.
+	ReopenCashUpDay(ctx context.Context, date time.Time) error
+
+	// SendPaymentReminders emails a reminder with the current invoice (from GetEnrollmentPaymentInvoice()) to every active StudentEnrollment which owes quota, is overdue, or whose penalty starts within spec.DaysBeforePenalty days.
+	// The email is sent to the parent's email in the student's UserDetail, or the student's email when it is empty.
+	//
+	// A StudentEnrollment is skipped when its student has opted out, or it has been reminded within spec.Cadence. Every sent reminder is recorded as a PaymentReminder.
+	// When spec.DryRun is true, no email is sent & nothing is recorded, and the returned PaymentReminders are the ones which would be sent.
+	SendPaymentReminders(ctx context.Context, spec SendPaymentRemindersSpec) (SendPaymentRemindersResult, error)
+	GetPaymentReminders(ctx context.Context, pagination util.PaginationSpec) (GetPaymentRemindersResult, error)
+	GetPaymentReminderOptOuts(ctx context.Context) ([]PaymentReminderOptOut, error)
+	// SetPaymentReminderOptOut opts the students out from (or back into) the payment reminders. The opting out is recorded as done by the requesting user.
+	SetPaymentReminderOptOut(ctx context.Context, studentIDs []entity.StudentID, isOptedOut bool) error
 
 	SearchClass(ctx context.Context, spec SearchClassSpec) ([]entity.Class, error)
 	EditClassesConfigs(ctx context.Context, specs []EditClassConfigSpec) error
 	EditClassesCourses(ctx context.Context, specs []EditClassCourseSpec) error
+	// LevelUpClass moves the class into spec.CourseID, and splits every student's StudentLearningTokens at spec.EffectiveDate:
+	// the unpaid attendances since spec.EffectiveDate are relinked into a token with the new course's price, then the remaining (positive) quota of the old-price tokens is moved into it as well.
+	// The attendances before spec.EffectiveDate stay on the old price.
+	//
+	// A level-up cannot be scheduled ahead: spec.EffectiveDate must be today or earlier, as the old-price quota is moved into the new-price token right away.
+	// Every moved quota is recorded as an SLTTransfer, check RepriceStudentLearningTokens() for more information.
+	// Returns errs.ErrClassAlreadyInCourse when the class is already in spec.CourseID, errs.ErrLevelUpEffectiveDateInFuture when spec.EffectiveDate is after today,
+	// or errs.ErrModifyingPaidAttendance when any of the attendances since spec.EffectiveDate is already paid.
+	LevelUpClass(ctx context.Context, spec LevelUpClassSpec) (ClassLevelUp, error)
+	// PreviewLevelUpClass runs LevelUpClass() in a dry-run transaction, without persisting the changes.
+	PreviewLevelUpClass(ctx context.Context, spec LevelUpClassSpec) (ClassLevelUp, error)
 
 	GetSLTsByClassID(ctx context.Context, classID entity.ClassID) ([]StudentIDToSLTs, error)
+	// GetSLTHistory returns the ledger of a StudentLearningToken with its running balance, and checks the token's stored quota against the ledger.
+	GetSLTHistory(ctx context.Context, sltID entity.StudentLearningTokenID) (StudentLearningTokenHistory, error)
+	// ReconcileSLTQuotas recomputes the expected quota of every StudentLearningToken, and reports the tokens whose stored quota drifts.
+	//
+	// When spec.Repair is true, all drifts are fixed in a single transaction, which is also recorded as a UserActionLog of the acting user.
+	ReconcileSLTQuotas(ctx context.Context, spec ReconcileSLTQuotasSpec) (SLTReconciliationReport, error)
+	// TransferStudentLearningTokens moves the remaining quota of a StudentLearningToken into a token of another StudentEnrollment, whose fee values follow the destination enrollment's invoice.
+	//
+	// The quota is converted by the ratio of both tokens' CourseFeeQuarterValue, so that the transferred value (in currency) is preserved.
+	// When spec.Quota is 0, all unpaid attendances of the source token are relinked to the destination token first, then all of the remaining quota is transferred.
+	// This empties the source token, which is required before deleting its StudentEnrollment.
+	TransferStudentLearningTokens(ctx context.Context, spec TransferStudentLearningTokensSpec) (SLTTransfer, error)
+	// RepriceStudentLearningTokens moves the remaining quota of every positive-quota StudentLearningToken whose price differs from the price in force on spec.CutoverDate, into a token with the new price.
+	// Unpaid attendances since spec.CutoverDate are relinked to the new token as well, while the earlier attendances stay on the old price.
+	//
+	// Every moved quota is recorded as an SLTTransfer, check TransferStudentLearningTokens() for more information.
+	RepriceStudentLearningTokens(ctx context.Context, spec RepriceStudentLearningTokensSpec) ([]SLTRepricing, error)
+	// PreviewRepriceStudentLearningTokens runs RepriceStudentLearningTokens() in a dry-run transaction, without persisting the changes.
+	PreviewRepriceStudentLearningTokens(ctx context.Context, spec RepriceStudentLearningTokensSpec) ([]SLTRepricing, error)
 	GetAttendancesByClassID(ctx context.Context, spec GetAttendancesByClassIDSpec) (GetAttendancesByClassIDResult, error)
 	// AddAttendancesBatch is the batch version of AddAttendance().
 	AddAttendancesBatch(ctx context.Context, specs []AddAttendanceSpec) ([]entity.AttendanceID, error)
-	// AddAttendance creates attendance(s) based on spec, duplicated for every students who enroll in the class.
+	// AddAttendance creates attendance(s) based on spec, duplicated for every students whose StudentEnrollment is active (see entity.StudentEnrollmentPeriod) at spec.Date.
+	// Returns errs.ErrClassHaveNoStudent when none of them is active. Students whose StudentEnrollment is on hold at spec.Date are skipped. Returns errs.ErrAllStudentsOnHold when all of them are on hold.
 	//
 	// Depend on the `Class` setting ("autoOweAttendanceToken"), by default this will automatically create StudentLearningToken (SLT) with negative quota when any of the class' students have no SLT (due to no payment yet).
 	AddAttendance(ctx context.Context, spec AddAttendanceSpec) ([]entity.AttendanceID, error)
+	// PreviewAddAttendancesBatch & PreviewAddAttendance run their non-preview counterparts in a dry-run transaction, and return the StudentLearningToken changes without persisting them.
+	PreviewAddAttendancesBatch(ctx context.Context, specs []AddAttendanceSpec) (SLTChangesPreview, error)
+	PreviewAddAttendance(ctx context.Context, spec AddAttendanceSpec) (SLTChangesPreview, error)
 	AssignAttendanceToken(ctx context.Context, spec AssignAttendanceTokenSpec) error
+	// AssignAttendancesTokens assigns a StudentLearningToken to every token-less attendance of the class (see TeacherForPayment.ClassesNeedTokenAssignment) in a single transaction, following spec.Strategy.
+	// Each assignment updates the token's quota the same way as AssignAttendanceToken().
+	//
+	// Attendances which cannot be assigned (e.g. dated within a closed period) are skipped, and reported as AttendanceTokenAssignmentFailures.
+	AssignAttendancesTokens(ctx context.Context, spec AssignAttendancesTokensSpec) (AttendanceTokenAssignments, error)
 	EditAttendance(ctx context.Context, spec EditAttendanceSpec) ([]entity.AttendanceID, error)
 	RemoveAttendance(ctx context.Context, attendanceID entity.AttendanceID) ([]entity.AttendanceID, error)
 
 	GetTeachersForPayment(ctx context.Context, spec GetTeachersForPaymentSpec) (GetTeachersForPaymentResult, error)
+	// GetTeacherSubstitutionSummaries returns the substitutions given & received by each teacher, of the attendances within the period.
+	GetTeacherSubstitutionSummaries(ctx context.Context, spec GetTeacherSubstitutionSummariesSpec) ([]TeacherSubstitutionSummary, error)
 	// GetTeacherPaymentInvoiceItems returns list of Attendance, sort ascendingly by date, grouped by StudentLearningToken, then by Student, and finally by Class.
 	//
-	// The result will be used for SubmitTeacherPayments spec.
+	// The result will be used for SubmitTeacherPayments spec. A substitution's gross course fee follows the substitute teacher's own rate, instead of the StudentLearningToken's.
 	GetTeacherPaymentInvoiceItems(ctx context.Context, spec GetTeacherPaymentInvoiceItemsSpec) ([]TeacherPaymentInvoiceItem, error)
 	GetExistingTeacherPaymentInvoiceItems(ctx context.Context, spec GetExistingTeacherPaymentInvoiceItemsSpec) ([]TeacherPaymentInvoiceItem, error)
+	// GetTeacherPayslip returns the TeacherPayslip of the existing TeacherPayments, built from GetExistingTeacherPaymentInvoiceItems() result.
+	GetTeacherPayslip(ctx context.Context, spec GetExistingTeacherPaymentInvoiceItemsSpec) (TeacherPayslip, error)
+	// EmailTeacherPayslip sends the TeacherPayslip to the teacher's email. It returns errs.ErrUserEmailNotSet when the teacher has no email.
+	EmailTeacherPayslip(ctx context.Context, spec GetExistingTeacherPaymentInvoiceItemsSpec) error
+	// SubmitTeacherPayments adds new TeacherPayments. When spec.PayrollRunID is set, the TeacherPayment is added into the PayrollRun, which must be a draft.
 	SubmitTeacherPayments(ctx context.Context, specs []SubmitTeacherPaymentsSpec) error
+	// ModifyTeacherPayments, EditTeacherPayments & RemoveTeacherPayments return errs.ErrTeacherPaymentLocked when any of the TeacherPayments belongs to a non-draft PayrollRun.
 	ModifyTeacherPayments(ctx context.Context, specs []ModifyTeacherPaymentsSpec) (ModifyTeacherPaymentsResult, error)
 	EditTeacherPayments(ctx context.Context, specs []EditTeacherPaymentsSpec) ([]entity.TeacherPaymentID, error)
 	RemoveTeacherPayments(ctx context.Context, teacherPaymentIDs []entity.TeacherPaymentID) error
+
+	GetPayrollRuns(ctx context.Context, pagination util.PaginationSpec) (GetPayrollRunsResult, error)
+	GetPayrollRunById(ctx context.Context, id entity.PayrollRunID) (entity.PayrollRun, error)
+	// CreatePayrollRun creates a draft PayrollRun, which includes all teachers having unpaid attendances within the period (similar to GetTeachersForPayment(IsPaid=false)).
+	CreatePayrollRun(ctx context.Context, spec CreatePayrollRunSpec) (entity.PayrollRunID, error)
+	// GetPayrollRunTeacherTotals returns the sum of the PayrollRun's TeacherPayments, grouped by teacher.
+	GetPayrollRunTeacherTotals(ctx context.Context, id entity.PayrollRunID) ([]PayrollRunTeacherTotal, error)
+	// ApprovePayrollRun moves a draft PayrollRun into "APPROVED", which locks its TeacherPayments. The approver is the requesting user.
+	ApprovePayrollRun(ctx context.Context, id entity.PayrollRunID) error
+	// MarkPayrollRunAsPaid moves an approved PayrollRun into "PAID".
+	MarkPayrollRunAsPaid(ctx context.Context, id entity.PayrollRunID) error
 }
 
 type SubmitStudentEnrollmentPaymentSpec struct {
@@ -136,7 +629,34 @@ type SubmitStudentEnrollmentPaymentSpec struct {
 	TransportFeeValue int32
 	PenaltyFeeValue   int32
 	DiscountFeeValue  int32
+	DiscountRuleIDs   []entity.DiscountRuleID // optional, usually taken from StudentEnrollmentInvoice.Discounts
+
+	PaymentMethod    entity.PaymentMethod
+	ReceivingAccount string
+	ReferenceNumber  string
+}
+type SubmitFamilyPaymentSpec struct {
+	FamilyID    entity.FamilyID
+	PaymentDate time.Time
+	Items       []SubmitFamilyPaymentItemSpec
+
+	PaymentMethod    entity.PaymentMethod
+	ReceivingAccount string
+	ReferenceNumber  string
 }
+
+type SubmitFamilyPaymentItemSpec struct {
+	StudentEnrollmentID entity.StudentEnrollmentID
+
+	BalanceTopUp      int32
+	BalanceBonus      int32
+	CourseFeeValue    int32
+	TransportFeeValue int32
+	PenaltyFeeValue   int32
+	DiscountFeeValue  int32
+	DiscountRuleIDs   []entity.DiscountRuleID
+}
+
 type EditStudentEnrollmentPaymentSpec struct {
 	EnrollmentPaymentID entity.EnrollmentPaymentID
 	PaymentDate         time.Time
@@ -144,6 +664,87 @@ type EditStudentEnrollmentPaymentSpec struct {
 	DiscountFeeValue    int32
 }
 
+type RefundEnrollmentPaymentSpec struct {
+	EnrollmentPaymentID entity.EnrollmentPaymentID
+	RefundDate          time.Time
+	RefundedValue       int32
+	WithdrawnQuota      float64
+	Reason              string
+}
+
+type GetEnrollmentPaymentRefundsResult struct {
+	EnrollmentPaymentRefunds []EnrollmentPaymentRefund
+	PaginationResult         util.PaginationResult
+}
+
+type CreateInstallmentPlanSpec struct {
+	StudentEnrollmentID entity.StudentEnrollmentID
+	// InvoiceDate is passed to GetEnrollmentPaymentInvoice(), defaults to now
+	InvoiceDate time.Time
+	// CourseCycles is the number of course cycles (usually months) covered by the plan, defaults to 1. The penalty & discount fees are only charged once.
+	CourseCycles int32
+	// DueDates must be sorted ascendingly, one for each installment
+	DueDates []time.Time
+}
+
+type PayInstallmentSpec struct {
+	InstallmentID InstallmentID
+	PaymentDate   time.Time
+
+	PaymentMethod    entity.PaymentMethod
+	ReceivingAccount string
+	ReferenceNumber  string
+}
+
+type GetOverdueInstallmentsResult struct {
+	OverdueInstallments []OverdueInstallment
+	PaginationResult    util.PaginationResult
+}
+
+type CloseCashUpDaySpec struct {
+	Date             time.Time
+	CountedCashValue int64
+	Note             string
+}
+
+type SendPaymentRemindersSpec struct {
+	// Cadence is the minimum duration between 2 reminders of the same StudentEnrollment
+	Cadence           time.Duration
+	DaysBeforePenalty int32
+	DryRun            bool
+}
+
+type SendPaymentRemindersResult struct {
+	Reminders []PaymentReminder        `json:"reminders"`
+	Failures  []PaymentReminderFailure `json:"failures"`
+}
+
+type GetPaymentRemindersResult struct {
+	PaymentReminders []PaymentReminder
+	PaginationResult util.PaginationResult
+}
+
+type ReconcileSLTQuotasSpec struct {
+	Repair bool
+}
+
+type TransferStudentLearningTokensSpec struct {
+	SourceStudentLearningTokenID   entity.StudentLearningTokenID
+	DestinationStudentEnrollmentID entity.StudentEnrollmentID
+	// Quota=0 transfers all of the remaining quota, along with relinking the unpaid attendances
+	Quota float64
+	Note  string
+}
+
+type RepriceStudentLearningTokensSpec struct {
+	CutoverDate time.Time
+	// CourseID limits the repricing to the StudentLearningTokens of the course's classes, CourseID_None reprices all courses.
+	CourseID entity.CourseID
+	// IsQuotaConverted=true converts the remaining quota to preserve its value (similar to TransferStudentLearningTokens()).
+	// Otherwise, the remaining quota is carried over as is, i.e. the prepaid quota is honored at the new price.
+	IsQuotaConverted bool
+}
+
 type SearchClassSpec struct {
 	TeacherID entity.TeacherID
 	StudentID entity.StudentID
@@ -155,6 +756,14 @@ type EditClassCourseSpec struct {
 	CourseID entity.CourseID
 }
 
+type LevelUpClassSpec struct {
+	ClassID       entity.ClassID
+	CourseID      entity.CourseID
+	EffectiveDate time.Time
+	// IsQuotaConverted=true converts the remaining quota to preserve its value, check RepriceStudentLearningTokensSpec for more information.
+	IsQuotaConverted bool
+}
+
 type EditClassConfigSpec struct {
 	ClassID                entity.ClassID
 	IsDeactivated          *bool
@@ -204,6 +813,15 @@ func (s EditAttendanceSpec) GetInt64ID() int64 {
 	return int64(s.AttendanceID)
 }
 
+type AssignAttendancesTokensSpec struct {
+	ClassID   entity.ClassID
+	StudentID entity.StudentID // optional
+	util.TimeSpec
+	Strategy AttendanceTokenAssignmentStrategy
+	// StudentLearningTokenID is only used by AttendanceTokenAssignmentStrategy_ExplicitSLT
+	StudentLearningTokenID entity.StudentLearningTokenID
+}
+
 type GetTeachersForPaymentSpec struct {
 	IsPaid     bool
 	Pagination util.PaginationSpec
@@ -215,6 +833,10 @@ type GetTeachersForPaymentResult struct {
 	PaginationResult   util.PaginationResult
 }
 
+type GetTeacherSubstitutionSummariesSpec struct {
+	util.TimeSpec
+}
+
 type GetTeacherPaymentInvoiceItemsSpec struct {
 	TeacherID entity.TeacherID
 	util.TimeSpec
@@ -229,6 +851,7 @@ type SubmitTeacherPaymentsSpec struct {
 	AttendanceID          entity.AttendanceID
 	PaidCourseFeeValue    int32
 	PaidTransportFeeValue int32
+	PayrollRunID          entity.PayrollRunID
 }
 
 type ModifyTeacherPaymentsSpec struct {
@@ -256,3 +879,13 @@ type EditTeacherPaymentsSpec struct {
 func (s EditTeacherPaymentsSpec) GetInt64ID() int64 {
 	return int64(s.TeacherPaymentID)
 }
+
+type GetPayrollRunsResult struct {
+	PayrollRuns      []entity.PayrollRun
+	PaginationResult util.PaginationResult
+}
+
+type CreatePayrollRunSpec struct {
+	util.TimeSpec
+	Note string
+}
@@ -1,5 +1,10 @@
 package util
 
+import (
+	"fmt"
+	"strconv"
+)
+
 func BoolToInt32(b bool) int32 {
 	if b {
 		return 1
@@ -11,3 +16,21 @@ func BoolToInt32(b bool) int32 {
 func Int32ToBool(i int32) bool {
 	return i > 0
 }
+
+// FormatRupiah formats value using the Indonesian thousand separator, e.g. "Rp 1.250.000".
+func FormatRupiah(value int64) string {
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+
+	digits := strconv.FormatInt(value, 10)
+	formatted := ""
+	for len(digits) > 3 {
+		formatted = "." + digits[len(digits)-3:] + formatted
+		digits = digits[:len(digits)-3]
+	}
+
+	return fmt.Sprintf("%sRp %s%s", sign, digits, formatted)
+}
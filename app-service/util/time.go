@@ -28,6 +28,12 @@ var (
 // 	return
 // }
 
+// ToLocalDate truncates t into the date of its day in DefaultTimezone, in the same form as a scanned MySQL DATE (midnight UTC).
+func ToLocalDate(t time.Time) time.Time {
+	localTime := t.In(DefaultTimezone)
+	return time.Date(localTime.Year(), localTime.Month(), localTime.Day(), 0, 0, 0, 0, time.UTC)
+}
+
 type TimeSpec struct {
 	StartDatetime time.Time
 	EndDatetime   time.Time
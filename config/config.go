@@ -40,6 +40,22 @@ type Config struct {
 	DBMaxOpenConnection int    `envconfig:"DB_MAX_OPEN_CONNECTION" default:"3"`
 
 	LogLevel string `envconfig:"LOG_LEVEL" default:"WARN"`
+
+	// SLTReconciliationInterval=0 disables the periodic StudentLearningToken quota reconciliation job
+	SLTReconciliationInterval time.Duration `envconfig:"SLT_RECONCILIATION_INTERVAL" default:"24h"`
+
+	// CourseFeeSyncInterval=0 disables the periodic job which applies the scheduled Course prices (course_fee_history) into course.default_fee once they take effect
+	CourseFeeSyncInterval time.Duration `envconfig:"COURSE_FEE_SYNC_INTERVAL" default:"1h"`
+
+	// StudentEnrollmentSyncInterval=0 disables the periodic job which disables the withdrawn student_enrollments once their period's end_date has passed
+	StudentEnrollmentSyncInterval time.Duration `envconfig:"STUDENT_ENROLLMENT_SYNC_INTERVAL" default:"1h"`
+
+	// PaymentReminderInterval=0 disables the periodic payment reminder job, which emails the owing or (nearly) overdue students
+	PaymentReminderInterval time.Duration `envconfig:"PAYMENT_REMINDER_INTERVAL" default:"0"`
+	// PaymentReminderCadence is the minimum duration between 2 reminders of the same student enrollment
+	PaymentReminderCadence time.Duration `envconfig:"PAYMENT_REMINDER_CADENCE" default:"168h"`
+	// PaymentReminderDaysBeforePenalty is the number of days before the penalty starts, from which the students are reminded
+	PaymentReminderDaysBeforePenalty int32 `envconfig:"PAYMENT_REMINDER_DAYS_BEFORE_PENALTY" default:"3"`
 }
 
 var doOnce sync.Once
@@ -423,3 +423,14 @@ INSERT INTO attendance ( date, used_student_token_quota, duration, note, is_paid
 INSERT INTO attendance ( date, used_student_token_quota, duration, note, is_paid, class_id, teacher_id, student_id, token_id ) VALUES ( '2023-11-16 06:00:00', 1, 30, 'triad 2 & cadence', 0, 5, 4, 2, 6 );
 INSERT INTO attendance ( date, used_student_token_quota, duration, note, is_paid, class_id, teacher_id, student_id, token_id ) VALUES ( '2023-11-16 06:00:00', 1, 30, 'triad 2 & cadence', 0, 5, 4, 3, 7 );
 INSERT INTO attendance ( date, used_student_token_quota, duration, note, is_paid, class_id, teacher_id, student_id, token_id ) VALUES ( '2023-11-16 06:00:00', 1, 30, 'triad 2 & cadence', 0, 5, 4, 4, 8 );
+
+-- opening balance of the seeded `student_learning_token`s, see migration "005_slt_transaction.sql"
+INSERT INTO slt_transaction (quota_change, reason, created_at, token_id)
+SELECT quota, 'MIGRATION', last_updated_at, id FROM student_learning_token;
+
+-- opening prices of the seeded `course`s & `teacher_special_fee`s, see migration "007_fee_history.sql"
+INSERT INTO course_fee_history (fee, effective_from, course_id)
+SELECT default_fee, '1970-01-01 00:00:00', id FROM course;
+
+INSERT INTO teacher_special_fee_history (fee, effective_from, teacher_id, course_id)
+SELECT fee, '1970-01-01 00:00:00', teacher_id, course_id FROM teacher_special_fee;
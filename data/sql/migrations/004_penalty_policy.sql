@@ -0,0 +1,25 @@
+CREATE TABLE penalty_policy
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  name VARCHAR(64) NOT NULL,
+  -- penalty starts counting after `trigger_day_of_month` of the month following the latest `enrollment_payment`, plus `grace_days`
+  trigger_day_of_month INT NOT NULL DEFAULT 10,
+  grace_days INT NOT NULL DEFAULT 0,
+  -- when `is_flat_fee` = 1, `fee_value` is charged once regardless of the days late. Otherwise, `fee_value` is charged per day late.
+  is_flat_fee TINYINT NOT NULL DEFAULT 0,
+  fee_value INT NOT NULL,
+  -- `max_fee_value` caps the total penalty fee, 0 means no cap
+  max_fee_value INT NOT NULL DEFAULT 0,
+  -- a `penalty_policy` is assigned to either a `class`, a `course`, or nothing (which makes it the school-wide default).
+  -- on resolving the applied policy, `class` policy takes precedence over `course` policy, which takes precedence over the default one.
+  course_id BIGINT unsigned,
+  class_id BIGINT unsigned,
+  -- `penalty_policy` acts as an additional configuration for `course` & `class`. We can simply delete this record by CASCADE
+  FOREIGN KEY (course_id) REFERENCES course(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  FOREIGN KEY (class_id) REFERENCES class(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  UNIQUE KEY `course_id` (`course_id`),
+  UNIQUE KEY `class_id` (`class_id`)
+);
+
+-- school-wide default, which follows the previously hard-coded penalty rule: 10000 per day, after the 10th of the month following the latest payment
+INSERT INTO penalty_policy (name, trigger_day_of_month, grace_days, is_flat_fee, fee_value, max_fee_value) VALUES ('Default', 10, 0, 0, 10000, 0);
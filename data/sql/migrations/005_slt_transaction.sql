@@ -0,0 +1,23 @@
+CREATE TABLE slt_transaction
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  -- `quota_change` is the (signed) delta applied to `student_learning_token`.`quota`.
+  -- `student_learning_token`.`quota` must always equal the sum of its `quota_change`s.
+  quota_change FLOAT NOT NULL,
+  -- one of: 'PAYMENT', 'ATTENDANCE', 'TRANSFER', 'MANUAL', 'MIGRATION', 'RECONCILIATION'
+  reason VARCHAR(16) NOT NULL,
+  -- `source_id` is the ID of the entity which causes the change, depending on the `reason`: `enrollment_payment` for 'PAYMENT', `attendance` for 'ATTENDANCE', `slt_transfer` for 'TRANSFER'.
+  -- We don't use foreign key here, as the ledger must outlive its source entity (e.g. a removed `attendance`).
+  source_id BIGINT unsigned,
+  user_id BIGINT unsigned,
+  FOREIGN KEY (user_id) REFERENCES user(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  token_id BIGINT unsigned NOT NULL,
+  -- the ledger has no meaning without its `student_learning_token`. We can simply delete this record by CASCADE
+  FOREIGN KEY (token_id) REFERENCES student_learning_token(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  INDEX `token_id_id` (`token_id`, `id`)
+);
+
+-- opening balance of the existing `student_learning_token`s
+INSERT INTO slt_transaction (quota_change, reason, created_at, token_id)
+SELECT quota, 'MIGRATION', last_updated_at, id FROM student_learning_token;
@@ -0,0 +1,20 @@
+-- `slt_transfer` records every movement of quota from a `student_learning_token` to another token of a different `student_enrollment`,
+-- e.g. before a `student_enrollment` is deleted, or when a student moves to another class.
+-- Both tokens' quota changes are also recorded in `slt_transaction` with reason 'TRANSFER', whose `source_id` refers to this table.
+CREATE TABLE slt_transfer
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  -- `transferred_quota` is deducted from the source token, while `converted_quota` is added into the destination token.
+  -- both values differ when the tokens have different `course_fee_quarter_value`, as the transferred value (in currency) is preserved.
+  transferred_quota FLOAT NOT NULL,
+  converted_quota FLOAT NOT NULL,
+  note VARCHAR(255) NOT NULL DEFAULT '',
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  user_id BIGINT unsigned,
+  source_token_id BIGINT unsigned,
+  destination_token_id BIGINT unsigned,
+  -- `slt_transfer` stores historical records, which must persist after the user/tokens are deleted
+  FOREIGN KEY (user_id) REFERENCES user(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  FOREIGN KEY (source_token_id) REFERENCES student_learning_token(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  FOREIGN KEY (destination_token_id) REFERENCES student_learning_token(id) ON UPDATE CASCADE ON DELETE SET NULL
+);
@@ -0,0 +1,34 @@
+-- `course_fee_history` & `teacher_special_fee_history` record every price, along with the datetime since when the price is in force.
+-- `course`.`default_fee` & `teacher_special_fee`.`fee` still hold the latest price, and every change of them is also recorded here.
+-- This allows pricing an `enrollment_payment` with the price in force on its `payment_date`, instead of the latest price.
+CREATE TABLE course_fee_history
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  fee INT NOT NULL,
+  effective_from DATETIME NOT NULL,
+  course_id BIGINT unsigned NOT NULL,
+  -- `course_fee_history` has no meaning without its `course`. We can simply delete this record by CASCADE
+  FOREIGN KEY (course_id) REFERENCES course(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  INDEX `course_id--effective_from` (`course_id`, `effective_from`)
+);
+
+CREATE TABLE teacher_special_fee_history
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  -- `fee` = 0 means the `teacher_special_fee` is removed since `effective_from`, thus `course_fee_history` applies instead
+  fee INT NOT NULL,
+  effective_from DATETIME NOT NULL,
+  teacher_id BIGINT unsigned NOT NULL,
+  course_id BIGINT unsigned NOT NULL,
+  -- similar to `teacher_special_fee`, we can simply delete this record by CASCADE
+  FOREIGN KEY (teacher_id) REFERENCES teacher(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  FOREIGN KEY (course_id) REFERENCES course(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  INDEX `teacher_id--course_id--effective_from` (`teacher_id`, `course_id`, `effective_from`)
+);
+
+-- opening prices of the existing `course`s & `teacher_special_fee`s, which have been in force since an unknown date
+INSERT INTO course_fee_history (fee, effective_from, course_id)
+SELECT default_fee, '1970-01-01 00:00:00', id FROM course;
+
+INSERT INTO teacher_special_fee_history (fee, effective_from, teacher_id, course_id)
+SELECT fee, '1970-01-01 00:00:00', teacher_id, course_id FROM teacher_special_fee;
@@ -0,0 +1,20 @@
+CREATE TABLE teacher_fee_sharing
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  -- the portion (0 to 1) of the gross course & transport fee of an `attendance` which is paid to the teacher
+  course_fee_sharing_percentage FLOAT NOT NULL,
+  transport_fee_sharing_percentage FLOAT NOT NULL,
+  effective_from DATETIME NOT NULL,
+  -- a `teacher_fee_sharing` is assigned to either a `teacher`, a `course`, both of them, or nothing (which makes it the school-wide default).
+  -- on resolving the applied rate of an `attendance`, `teacher`+`course` rate takes precedence over `teacher` rate, then `course` rate, then the default one.
+  -- among the rates of the same assignment, the latest one whose `effective_from` <= `attendance`.`date` is applied.
+  teacher_id BIGINT unsigned,
+  course_id BIGINT unsigned,
+  -- `teacher_fee_sharing` acts as an additional configuration for `teacher` & `course`. We can simply delete this record by CASCADE
+  FOREIGN KEY (teacher_id) REFERENCES teacher(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  FOREIGN KEY (course_id) REFERENCES course(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  INDEX `teacher_id--course_id--effective_from` (`teacher_id`, `course_id`, `effective_from`)
+);
+
+-- school-wide default, which follows the previously hard-coded rates: 50% of course fee, 100% of transport fee
+INSERT INTO teacher_fee_sharing (course_fee_sharing_percentage, transport_fee_sharing_percentage, effective_from) VALUES (0.5, 1.0, '1970-01-01 00:00:00');
@@ -0,0 +1,35 @@
+-- `payroll_run` groups the `teacher_payment`s of a pay period, which goes through: 'DRAFT' -> 'APPROVED' -> 'PAID'.
+-- Once a `payroll_run` is no longer 'DRAFT', its `teacher_payment`s are locked, i.e. cannot be edited nor removed.
+CREATE TABLE payroll_run
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  -- the period of the `attendance`s to be paid
+  start_date DATETIME NOT NULL,
+  end_date DATETIME NOT NULL,
+  -- one of: 'DRAFT', 'APPROVED', 'PAID'
+  status VARCHAR(16) NOT NULL DEFAULT 'DRAFT',
+  note VARCHAR(255) NOT NULL DEFAULT '',
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  approved_at DATETIME,
+  paid_at DATETIME,
+  created_by_user_id BIGINT unsigned,
+  approved_by_user_id BIGINT unsigned,
+  -- `payroll_run` stores historical records, which must persist after the users are deleted
+  FOREIGN KEY (created_by_user_id) REFERENCES user(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  FOREIGN KEY (approved_by_user_id) REFERENCES user(id) ON UPDATE CASCADE ON DELETE SET NULL
+);
+
+-- `payroll_run_teacher` lists the `teacher`s to be paid in a `payroll_run`, i.e. the unpaid teachers within the period on the `payroll_run` creation,
+-- and the teachers whose `teacher_payment`s are submitted later into the `payroll_run`.
+CREATE TABLE payroll_run_teacher
+(
+  payroll_run_id BIGINT unsigned NOT NULL,
+  teacher_id BIGINT unsigned NOT NULL,
+  PRIMARY KEY (payroll_run_id, teacher_id),
+  FOREIGN KEY (payroll_run_id) REFERENCES payroll_run(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  FOREIGN KEY (teacher_id) REFERENCES teacher(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+-- `teacher_payment`s submitted before this migration don't belong to any `payroll_run`
+ALTER TABLE teacher_payment ADD COLUMN payroll_run_id BIGINT unsigned;
+ALTER TABLE teacher_payment ADD FOREIGN KEY (payroll_run_id) REFERENCES payroll_run(id) ON UPDATE CASCADE ON DELETE RESTRICT;
@@ -0,0 +1,29 @@
+-- `closed_period` closes the accounting period up to (and including) `closed_until`.
+-- Dated records (`attendance`.date, `enrollment_payment`.payment_date, `teacher_payment`.added_at) within the latest closed period
+-- cannot be inserted, updated, nor deleted anymore, to keep the already-reported dashboard numbers intact.
+CREATE TABLE closed_period
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  closed_until DATE NOT NULL,
+  note VARCHAR(255) NOT NULL DEFAULT '',
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  created_by_user_id BIGINT unsigned,
+  FOREIGN KEY (created_by_user_id) REFERENCES user(id) ON UPDATE CASCADE ON DELETE SET NULL
+);
+
+-- `closed_period_override` records every write on a closed period, which is only allowed for super admins with an explicit reason.
+CREATE TABLE closed_period_override
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  -- the name of the overriding write operation, e.g. 'UpdateAttendances'
+  operation VARCHAR(64) NOT NULL,
+  reason VARCHAR(255) NOT NULL,
+  -- the `closed_period`.closed_until at the time of the override
+  closed_until DATE NOT NULL,
+  -- the earliest date of the records affected by the write operation
+  earliest_record_date DATETIME NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  user_id BIGINT unsigned,
+  -- `closed_period_override` is an audit log, which must persist after the user is deleted
+  FOREIGN KEY (user_id) REFERENCES user(id) ON UPDATE CASCADE ON DELETE SET NULL
+);
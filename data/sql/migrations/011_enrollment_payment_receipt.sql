@@ -0,0 +1,26 @@
+-- `receipt_number_sequence` holds the last issued receipt number, as a single-row counter.
+-- A new receipt number is taken by locking this row (SELECT ... FOR UPDATE) within the same transaction which inserts the `enrollment_payment_receipt`,
+-- so that receipt numbers are sequential & gap-free (a rolled back transaction also rolls back the counter), unlike AUTO_INCREMENT.
+CREATE TABLE receipt_number_sequence
+(
+  id TINYINT unsigned NOT NULL PRIMARY KEY,
+  last_receipt_number BIGINT unsigned NOT NULL DEFAULT 0
+);
+
+INSERT INTO receipt_number_sequence (id, last_receipt_number) VALUES (1, 0);
+
+-- `enrollment_payment_receipt` is issued once per `enrollment_payment`, within the same transaction which inserts the `enrollment_payment`.
+-- The payments recorded before that are backfilled by migration 023, ordered by payment_date.
+CREATE TABLE enrollment_payment_receipt
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  receipt_number BIGINT unsigned NOT NULL,
+  enrollment_payment_id BIGINT unsigned,
+  issued_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  issued_by_user_id BIGINT unsigned,
+  -- issued receipt numbers must persist after the `enrollment_payment` is deleted, to keep the receipt numbers gap-free
+  FOREIGN KEY (enrollment_payment_id) REFERENCES enrollment_payment(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  FOREIGN KEY (issued_by_user_id) REFERENCES user(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  UNIQUE KEY `receipt_number` (`receipt_number`),
+  UNIQUE KEY `enrollment_payment_id` (`enrollment_payment_id`)
+);
@@ -0,0 +1,23 @@
+-- `payment_method` is one of: 'CASH', 'BANK_TRANSFER', 'QRIS', 'OTHER'. Existing payments were received in cash.
+-- `receiving_account` is the bank/e-wallet account which receives the money (empty for cash), and `reference_number` is the transfer/transaction reference.
+ALTER TABLE enrollment_payment ADD COLUMN payment_method VARCHAR(16) NOT NULL DEFAULT 'CASH';
+ALTER TABLE enrollment_payment ADD COLUMN receiving_account VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE enrollment_payment ADD COLUMN reference_number VARCHAR(64) NOT NULL DEFAULT '';
+
+-- `cash_up_day` closes a day of `enrollment_payment`s (by `payment_date`, in GMT+7) after the daily cash-up.
+-- Payments of a closed day cannot be inserted, updated, nor deleted anymore, until the `cash_up_day` is deleted (reopened) by an admin.
+CREATE TABLE cash_up_day
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  date DATE NOT NULL,
+  -- `counted_cash_value` is the physically counted cash, while `recorded_cash_value` is the sum of the day's 'CASH' `enrollment_payment`s on closing
+  counted_cash_value BIGINT NOT NULL,
+  recorded_cash_value BIGINT NOT NULL,
+  -- `difference_value` = `counted_cash_value` - `recorded_cash_value`, negative means missing cash
+  difference_value BIGINT NOT NULL,
+  note VARCHAR(255) NOT NULL DEFAULT '',
+  closed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  closed_by_user_id BIGINT unsigned,
+  FOREIGN KEY (closed_by_user_id) REFERENCES user(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  UNIQUE KEY `date` (`date`)
+);
@@ -0,0 +1,32 @@
+-- `bank_statement_import` is an uploaded bank statement (mutation) CSV file, parsed by the parser of `bank_format` (e.g. 'BCA', 'MANDIRI').
+CREATE TABLE bank_statement_import
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  bank_format VARCHAR(32) NOT NULL,
+  file_name VARCHAR(255) NOT NULL DEFAULT '',
+  imported_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  imported_by_user_id BIGINT unsigned,
+  FOREIGN KEY (imported_by_user_id) REFERENCES user(id) ON UPDATE CASCADE ON DELETE SET NULL
+);
+
+-- `bank_statement_line` is a credit (incoming money) transaction of a `bank_statement_import`. Debit transactions are not imported.
+CREATE TABLE bank_statement_line
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  bank_statement_import_id BIGINT unsigned NOT NULL,
+  transaction_date DATETIME NOT NULL,
+  description VARCHAR(255) NOT NULL DEFAULT '',
+  reference VARCHAR(128) NOT NULL DEFAULT '',
+  amount BIGINT NOT NULL,
+  -- one of: 'UNMATCHED', 'AUTO_MATCHED' (matched on import, waiting for an admin's confirmation), 'CONFIRMED'
+  status VARCHAR(16) NOT NULL DEFAULT 'UNMATCHED',
+  enrollment_payment_id BIGINT unsigned,
+  confirmed_at DATETIME,
+  confirmed_by_user_id BIGINT unsigned,
+  FOREIGN KEY (bank_statement_import_id) REFERENCES bank_statement_import(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  -- a deleted `enrollment_payment` unmatches its `bank_statement_line`, i.e. a line without `enrollment_payment_id` is treated as 'UNMATCHED' regardless of its `status`
+  FOREIGN KEY (enrollment_payment_id) REFERENCES enrollment_payment(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  FOREIGN KEY (confirmed_by_user_id) REFERENCES user(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  -- an `enrollment_payment` can only be matched to one `bank_statement_line`
+  UNIQUE KEY `enrollment_payment_id` (`enrollment_payment_id`)
+);
@@ -0,0 +1,39 @@
+-- `discount_rule` is a promotion which is evaluated on `enrollment_payment` invoices. `type` is one of:
+--   - 'SIBLING': the student has at least `min_count` enrolled siblings (students of the same `family`, including the student)
+--   - 'MULTI_CLASS': the student has at least `min_count` active `student_enrollment`s
+--   - 'EARLY_PAYMENT': the payment is made on or before `max_day_of_month` of the month
+--   - 'VOUCHER': the staff enters `voucher_code` on the invoice, which can be used for at most `usage_limit` payments (NULL means unlimited)
+-- on an invoice, only the best (highest value) rule of each type is applied, and at most a single voucher.
+CREATE TABLE discount_rule
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  name VARCHAR(64) NOT NULL,
+  type VARCHAR(16) NOT NULL,
+  -- when `is_percentage` = 1, `value` is the percentage (1-100) of the course fee. Otherwise, `value` is a fixed amount.
+  is_percentage TINYINT NOT NULL DEFAULT 0,
+  value INT NOT NULL,
+  min_count INT NOT NULL DEFAULT 0,
+  max_day_of_month INT NOT NULL DEFAULT 0,
+  voucher_code VARCHAR(32),
+  usage_limit INT,
+  -- the rule is applicable to payments dated within `valid_from` & `valid_until` (both inclusive), NULL means unbounded
+  valid_from DATE,
+  valid_until DATE,
+  is_deactivated TINYINT NOT NULL DEFAULT 0,
+  UNIQUE KEY `voucher_code` (`voucher_code`)
+);
+
+-- `enrollment_payment_discount` records the `discount_rule`s applied on an `enrollment_payment`, for reporting the discount cost by promotion.
+-- The sum of `value` never exceeds the payment's `discount_fee_value`, the remaining is a manual discount by the staff.
+CREATE TABLE enrollment_payment_discount
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  enrollment_payment_id BIGINT unsigned NOT NULL,
+  discount_rule_id BIGINT unsigned,
+  -- the rule's name is copied, so that the report is still readable after the `discount_rule` is deleted
+  discount_rule_name VARCHAR(64) NOT NULL,
+  value INT NOT NULL,
+  FOREIGN KEY (enrollment_payment_id) REFERENCES enrollment_payment(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  FOREIGN KEY (discount_rule_id) REFERENCES discount_rule(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  UNIQUE KEY `enrollment_payment_id--discount_rule_id` (`enrollment_payment_id`, `discount_rule_id`)
+);
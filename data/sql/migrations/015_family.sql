@@ -0,0 +1,28 @@
+-- `family` groups siblings (students) whose enrollments are paid by the same guardian(s), e.g. a parent.
+CREATE TABLE family
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  name VARCHAR(128) NOT NULL
+);
+
+-- a student belongs to at most one `family`
+CREATE TABLE family_student
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  family_id BIGINT unsigned NOT NULL,
+  student_id BIGINT unsigned NOT NULL,
+  FOREIGN KEY (family_id) REFERENCES family(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  FOREIGN KEY (student_id) REFERENCES student(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  UNIQUE KEY `student_id` (`student_id`)
+);
+
+-- `family_guardian` links a `family` to the users who pay for it. A user can be the guardian of multiple families.
+CREATE TABLE family_guardian
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  family_id BIGINT unsigned NOT NULL,
+  user_id BIGINT unsigned NOT NULL,
+  FOREIGN KEY (family_id) REFERENCES family(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  FOREIGN KEY (user_id) REFERENCES user(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  UNIQUE KEY `family_id--user_id` (`family_id`, `user_id`)
+);
@@ -0,0 +1,29 @@
+-- `payment_reminder` records every sent payment reminder email, so that the reminder job doesn't send another one to the same `student_enrollment` within the configured cadence.
+-- The invoice values are stored as they were shown in the email.
+CREATE TABLE payment_reminder
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  -- one of: 'OWING' (negative `student_learning_token` quota), 'OVERDUE' (penalty is being charged), 'PENALTY_APPROACHING'
+  reminder_type VARCHAR(32) NOT NULL,
+  recipient_email VARCHAR(255) NOT NULL,
+  owed_quota FLOAT NOT NULL DEFAULT 0,
+  course_fee_value INT NOT NULL,
+  transport_fee_value INT NOT NULL,
+  penalty_fee_value INT NOT NULL,
+  discount_fee_value INT NOT NULL,
+  sent_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  enrollment_id BIGINT unsigned NOT NULL,
+  -- `payment_reminder` is only a log of sent emails, it's safe to be deleted along with the `student_enrollment`
+  FOREIGN KEY (enrollment_id) REFERENCES student_enrollment(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  INDEX (enrollment_id, sent_at)
+);
+
+-- `payment_reminder_opt_out` lists the students who must not receive any payment reminder email.
+CREATE TABLE payment_reminder_opt_out
+(
+  student_id BIGINT unsigned NOT NULL PRIMARY KEY,
+  opted_out_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  opted_out_by_user_id BIGINT unsigned,
+  FOREIGN KEY (student_id) REFERENCES student(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  FOREIGN KEY (opted_out_by_user_id) REFERENCES user(id) ON UPDATE CASCADE ON DELETE SET NULL
+);
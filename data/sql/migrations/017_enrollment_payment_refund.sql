@@ -0,0 +1,18 @@
+-- `enrollment_payment_refund` is a refund (credit note) of an `enrollment_payment`. The refunded `enrollment_payment` is kept as is, for history.
+-- The income dashboard subtracts the `refunded_value` on the month of the `refund_date`, instead of the month of the `enrollment_payment`.
+CREATE TABLE enrollment_payment_refund
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  refund_date DATETIME NOT NULL,
+  refunded_value INT NOT NULL,
+  -- `withdrawn_quota` is subtracted from the `student_learning_token` which was topped up by the `enrollment_payment`, recorded as an `slt_transaction` with reason 'REFUND'
+  withdrawn_quota FLOAT NOT NULL DEFAULT 0,
+  reason VARCHAR(255) NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  created_by_user_id BIGINT unsigned,
+  enrollment_payment_id BIGINT unsigned NOT NULL,
+  -- a refunded `enrollment_payment` must not be deleted, as the `student_learning_token` quota & the income would be reverted twice
+  FOREIGN KEY (enrollment_payment_id) REFERENCES enrollment_payment(id) ON UPDATE CASCADE ON DELETE RESTRICT,
+  FOREIGN KEY (created_by_user_id) REFERENCES user(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  INDEX (refund_date)
+);
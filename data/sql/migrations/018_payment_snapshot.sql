@@ -0,0 +1,54 @@
+-- `enrollment_payment` & `teacher_payment` store a snapshot of the student & class details at insert time, which never changes afterwards.
+-- `enrollment_payment.enrollment_id` is set to NULL when the `student_enrollment` is deleted, so the snapshot is the only remaining record of who paid and for what.
+ALTER TABLE enrollment_payment ADD COLUMN snapshot_student_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE enrollment_payment ADD COLUMN snapshot_class_description VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE enrollment_payment ADD COLUMN snapshot_course_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE enrollment_payment ADD COLUMN snapshot_instrument_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE enrollment_payment ADD COLUMN snapshot_grade_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE enrollment_payment ADD COLUMN snapshot_teacher_name VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE teacher_payment ADD COLUMN snapshot_student_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE teacher_payment ADD COLUMN snapshot_class_description VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE teacher_payment ADD COLUMN snapshot_course_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE teacher_payment ADD COLUMN snapshot_instrument_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE teacher_payment ADD COLUMN snapshot_grade_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE teacher_payment ADD COLUMN snapshot_teacher_name VARCHAR(255) NOT NULL DEFAULT '';
+
+-- backfill the existing records from their current relations. Records whose `student_enrollment` has been deleted keep the empty snapshot.
+-- the formats must be the same as entity.NewPaymentSnapshot()
+UPDATE enrollment_payment AS ep
+    JOIN student_enrollment AS se ON ep.enrollment_id = se.id
+    JOIN student ON se.student_id = student.id
+    JOIN user AS user_student ON student.user_id = user_student.id
+    JOIN class ON se.class_id = class.id
+    JOIN course ON class.course_id = course.id
+    JOIN instrument ON course.instrument_id = instrument.id
+    JOIN grade ON course.grade_id = grade.id
+    LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
+    LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
+SET
+    ep.snapshot_student_name = TRIM(CONCAT(COALESCE(user_student.user_detail->>'$.firstName', ''), ' ', COALESCE(user_student.user_detail->>'$.lastName', ''))),
+    ep.snapshot_class_description = CONCAT(instrument.name, ' - ', grade.name, ' (Class #', class.id, ')'),
+    ep.snapshot_course_name = CONCAT(instrument.name, ' - ', grade.name),
+    ep.snapshot_instrument_name = instrument.name,
+    ep.snapshot_grade_name = grade.name,
+    ep.snapshot_teacher_name = TRIM(CONCAT(COALESCE(user_class_teacher.user_detail->>'$.firstName', ''), ' ', COALESCE(user_class_teacher.user_detail->>'$.lastName', '')));
+
+-- `teacher_payment`'s teacher is the attendance's teacher (who actually taught), which may differ from the class' teacher
+UPDATE teacher_payment AS tp
+    JOIN attendance ON tp.attendance_id = attendance.id
+    JOIN teacher ON attendance.teacher_id = teacher.id
+    JOIN user AS user_teacher ON teacher.user_id = user_teacher.id
+    JOIN student ON attendance.student_id = student.id
+    JOIN user AS user_student ON student.user_id = user_student.id
+    JOIN class ON attendance.class_id = class.id
+    JOIN course ON class.course_id = course.id
+    JOIN instrument ON course.instrument_id = instrument.id
+    JOIN grade ON course.grade_id = grade.id
+SET
+    tp.snapshot_student_name = TRIM(CONCAT(COALESCE(user_student.user_detail->>'$.firstName', ''), ' ', COALESCE(user_student.user_detail->>'$.lastName', ''))),
+    tp.snapshot_class_description = CONCAT(instrument.name, ' - ', grade.name, ' (Class #', class.id, ')'),
+    tp.snapshot_course_name = CONCAT(instrument.name, ' - ', grade.name),
+    tp.snapshot_instrument_name = instrument.name,
+    tp.snapshot_grade_name = grade.name,
+    tp.snapshot_teacher_name = TRIM(CONCAT(COALESCE(user_teacher.user_detail->>'$.firstName', ''), ' ', COALESCE(user_teacher.user_detail->>'$.lastName', '')));
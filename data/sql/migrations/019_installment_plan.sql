@@ -0,0 +1,40 @@
+-- `installment_plan` splits the invoice of a `student_enrollment` into several `installment`s, which are paid separately.
+-- The values are copied from the invoice when the plan is created, and never change afterwards.
+CREATE TABLE installment_plan
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  balance_top_up INT NOT NULL,
+  balance_bonus INT NOT NULL DEFAULT 0,
+  course_fee_value INT NOT NULL,
+  transport_fee_value INT NOT NULL,
+  penalty_fee_value INT NOT NULL DEFAULT 0,
+  discount_fee_value INT NOT NULL DEFAULT 0,
+  installment_count INT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  created_by_user_id BIGINT unsigned,
+  enrollment_id BIGINT unsigned NOT NULL,
+  FOREIGN KEY (enrollment_id) REFERENCES student_enrollment(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  FOREIGN KEY (created_by_user_id) REFERENCES user(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  INDEX (enrollment_id)
+);
+
+-- `installment` is a part of an `installment_plan`. Its `balance_top_up` is the `student_learning_token` quota released once it is paid.
+CREATE TABLE installment
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  installment_number INT NOT NULL,
+  due_date DATETIME NOT NULL,
+  balance_top_up INT NOT NULL,
+  balance_bonus INT NOT NULL DEFAULT 0,
+  course_fee_value INT NOT NULL,
+  transport_fee_value INT NOT NULL,
+  penalty_fee_value INT NOT NULL DEFAULT 0,
+  discount_fee_value INT NOT NULL DEFAULT 0,
+  installment_plan_id BIGINT unsigned NOT NULL,
+  -- an `installment` is paid by an `enrollment_payment`. Removing the `enrollment_payment` makes the `installment` unpaid again.
+  enrollment_payment_id BIGINT unsigned UNIQUE,
+  FOREIGN KEY (installment_plan_id) REFERENCES installment_plan(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  FOREIGN KEY (enrollment_payment_id) REFERENCES enrollment_payment(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  UNIQUE (installment_plan_id, installment_number),
+  INDEX (due_date)
+);
@@ -0,0 +1,12 @@
+-- `student_enrollment_hold` pauses a `student_enrollment` from `start_date` until `end_date` (both inclusive), e.g. during a vacation or an exam period.
+-- During a hold, the late-payment penalty days are not counted, and attendances cannot be added for the student.
+CREATE TABLE student_enrollment_hold
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  start_date DATE NOT NULL,
+  end_date DATE NOT NULL,
+  reason VARCHAR(255) NOT NULL,
+  enrollment_id BIGINT unsigned NOT NULL,
+  FOREIGN KEY (enrollment_id) REFERENCES student_enrollment(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  INDEX `enrollment_id--start_date` (`enrollment_id`, `start_date`)
+);
@@ -0,0 +1,32 @@
+-- `student_enrollment_period` records each period of a `student_enrollment` being active, from `start_date` until `end_date` (both inclusive).
+-- Withdrawing a student ends the latest period, and disables the `student_enrollment` (is_deleted=1) once `end_date` has passed. Re-enrolling the student into the same class
+-- re-enables the same `student_enrollment` (keeping its tokens & payments), and starts a new period. Thus, the periods are the enrollment history.
+CREATE TABLE student_enrollment_period
+(
+  id BIGINT unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  start_date DATE NOT NULL,
+  -- `end_date` is NULL while the period is ongoing
+  end_date DATE,
+  withdrawal_reason VARCHAR(255) NOT NULL DEFAULT '',
+  enrollment_id BIGINT unsigned NOT NULL,
+  FOREIGN KEY (enrollment_id) REFERENCES student_enrollment(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  INDEX `enrollment_id--start_date` (`enrollment_id`, `start_date`)
+);
+
+-- backfill a period for each existing `student_enrollment`, on a best-effort basis: it starts from the enrollment's earliest attendance or payment (or today),
+-- and the disabled enrollments end on their latest attendance (or payment).
+-- A disabled enrollment without any attendance or payment gets a period of yesterday, so that it's not active on today's attendances.
+INSERT INTO student_enrollment_period (start_date, end_date, enrollment_id)
+SELECT
+    DATE(LEAST(
+        COALESCE((SELECT MIN(attendance.date) FROM attendance WHERE attendance.student_id = se.student_id AND attendance.class_id = se.class_id), CURRENT_DATE),
+        COALESCE((SELECT MIN(ep.payment_date) FROM enrollment_payment AS ep WHERE ep.enrollment_id = se.id), CURRENT_DATE),
+        IF(se.is_deleted = 1, CURRENT_DATE - INTERVAL 1 DAY, CURRENT_DATE)
+    )),
+    IF(se.is_deleted = 1, DATE(COALESCE(
+        (SELECT MAX(attendance.date) FROM attendance WHERE attendance.student_id = se.student_id AND attendance.class_id = se.class_id),
+        (SELECT MAX(ep.payment_date) FROM enrollment_payment AS ep WHERE ep.enrollment_id = se.id),
+        CURRENT_DATE - INTERVAL 1 DAY
+    )), NULL),
+    se.id
+FROM student_enrollment AS se;
@@ -0,0 +1,10 @@
+-- `attendance.original_teacher_id` is the class' teacher at the time the `attendance` is recorded, i.e. the teacher who was scheduled to teach.
+-- When another teacher teaches instead (`attendance.teacher_id` differs), the `attendance` is a substitution, and is flagged with `is_substitute`.
+-- Both columns are maintained by the app on every attendance insertion & modification. A substitute teacher is paid at their own course fee rate.
+ALTER TABLE attendance ADD COLUMN is_substitute TINYINT NOT NULL DEFAULT 0;
+ALTER TABLE attendance ADD COLUMN original_teacher_id BIGINT unsigned;
+ALTER TABLE attendance ADD FOREIGN KEY (original_teacher_id) REFERENCES teacher(id) ON UPDATE CASCADE ON DELETE SET NULL;
+ALTER TABLE attendance ADD INDEX `original_teacher_id--date` (`original_teacher_id`, `date`);
+
+-- the existing records are NOT backfilled: the class' teacher at the time they were recorded is unknown (the class' teacher may have been replaced since).
+-- Thus, they keep a null `original_teacher_id` & are not flagged as substitutions, instead of being guessed from the class' current teacher.
@@ -0,0 +1,13 @@
+-- `enrollment_payment_receipt` is now issued along with the `enrollment_payment` insertion (instead of on its first receipt printing),
+-- so that printing a receipt never takes a receipt number. The existing `enrollment_payment`s without any receipt are issued theirs here,
+-- numbered after the last issued receipt number, in the order of their payment date.
+INSERT INTO enrollment_payment_receipt (receipt_number, enrollment_payment_id)
+SELECT
+    (SELECT last_receipt_number FROM receipt_number_sequence WHERE id = 1) + ROW_NUMBER() OVER (ORDER BY ep.payment_date, ep.id),
+    ep.id
+FROM enrollment_payment AS ep
+    LEFT JOIN enrollment_payment_receipt AS epr ON ep.id = epr.enrollment_payment_id
+WHERE epr.id IS NULL;
+
+UPDATE receipt_number_sequence SET last_receipt_number = (SELECT COALESCE(MAX(receipt_number), 0) FROM enrollment_payment_receipt)
+WHERE id = 1;
@@ -0,0 +1,9 @@
+-- there can only be one school-wide default `penalty_policy` (both `course_id` & `class_id` are null), as GetApplicablePenaltyPolicy resolves the default by the lowest id,
+-- and any other default would never be applied.
+-- the extra defaults (which have never been applied) are removed first, keeping the oldest one which is the one being applied.
+DELETE extra_default FROM penalty_policy AS extra_default
+JOIN penalty_policy AS applied_default ON applied_default.course_id IS NULL AND applied_default.class_id IS NULL AND applied_default.id < extra_default.id
+WHERE extra_default.course_id IS NULL AND extra_default.class_id IS NULL;
+
+-- the functional key part evaluates to NULL for `course` & `class` policies, thus only the default ones collide with each other.
+ALTER TABLE penalty_policy ADD UNIQUE KEY `is_default` ((IF(course_id IS NULL AND class_id IS NULL, 1, NULL)));
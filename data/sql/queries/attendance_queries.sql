@@ -20,6 +20,7 @@ SELECT attendance.id AS attendance_id, date, used_student_token_quota, duration,
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
     class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    attendance.is_substitute, attendance.original_teacher_id, user_original_teacher.username AS original_teacher_username, user_original_teacher.user_detail AS original_teacher_detail,
     -- we cannot use sqlc.embed(slt), due to `Attendance` may have null `StudentLearningToken`.
     -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
     slt.id, slt.quota, slt.course_fee_quarter_value, slt.transport_fee_quarter_value, slt.created_at, slt.last_updated_at, slt.enrollment_id
@@ -37,6 +38,8 @@ FROM attendance
     LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
     LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
     LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+    LEFT JOIN teacher AS original_teacher ON attendance.original_teacher_id = original_teacher.id
+    LEFT JOIN user AS user_original_teacher ON original_teacher.user_id = user_original_teacher.id
 
     LEFT JOIN student_learning_token as slt ON attendance.token_id = slt.id
 WHERE
@@ -51,6 +54,7 @@ SELECT attendance.id AS attendance_id, date, used_student_token_quota, duration,
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
     class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    attendance.is_substitute, attendance.original_teacher_id, user_original_teacher.username AS original_teacher_username, user_original_teacher.user_detail AS original_teacher_detail,
     -- we cannot use sqlc.embed(slt), due to `Attendance` may have null `StudentLearningToken`.
     -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
     slt.id, slt.quota, slt.course_fee_quarter_value, slt.transport_fee_quarter_value, slt.created_at, slt.last_updated_at, slt.enrollment_id
@@ -68,6 +72,8 @@ FROM attendance
     LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
     LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
     LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+    LEFT JOIN teacher AS original_teacher ON attendance.original_teacher_id = original_teacher.id
+    LEFT JOIN user AS user_original_teacher ON original_teacher.user_id = user_original_teacher.id
 
     LEFT JOIN student_learning_token as slt ON attendance.token_id = slt.id
 WHERE attendance.id = ? LIMIT 1;
@@ -78,6 +84,7 @@ SELECT attendance.id AS attendance_id, date, used_student_token_quota, duration,
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
     class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    attendance.is_substitute, attendance.original_teacher_id, user_original_teacher.username AS original_teacher_username, user_original_teacher.user_detail AS original_teacher_detail,
     -- we cannot use sqlc.embed(slt), due to `Attendance` may have null `StudentLearningToken`.
     -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
     slt.id, slt.quota, slt.course_fee_quarter_value, slt.transport_fee_quarter_value, slt.created_at, slt.last_updated_at, slt.enrollment_id
@@ -95,6 +102,8 @@ FROM attendance
     LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
     LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
     LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+    LEFT JOIN teacher AS original_teacher ON attendance.original_teacher_id = original_teacher.id
+    LEFT JOIN user AS user_original_teacher ON original_teacher.user_id = user_original_teacher.id
 
     LEFT JOIN student_learning_token as slt ON attendance.token_id = slt.id
 WHERE attendance.id IN (sqlc.slice('ids'));
@@ -105,6 +114,7 @@ SELECT attendance.id AS attendance_id, date, used_student_token_quota, duration,
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
     class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    attendance.is_substitute, attendance.original_teacher_id, user_original_teacher.username AS original_teacher_username, user_original_teacher.user_detail AS original_teacher_detail,
     -- we cannot use sqlc.embed(slt), due to `Attendance` may have null `StudentLearningToken`.
     -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
     slt.id, slt.quota, slt.course_fee_quarter_value, slt.transport_fee_quarter_value, slt.created_at, slt.last_updated_at, slt.enrollment_id
@@ -122,6 +132,8 @@ FROM attendance
     LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
     LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
     LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+    LEFT JOIN teacher AS original_teacher ON attendance.original_teacher_id = original_teacher.id
+    LEFT JOIN user AS user_original_teacher ON original_teacher.user_id = user_original_teacher.id
 
     LEFT JOIN student_learning_token as slt ON attendance.token_id = slt.id
 WHERE
@@ -139,6 +151,7 @@ SELECT attendance.id AS attendance_id, date, used_student_token_quota, duration,
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
     class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    attendance.is_substitute, attendance.original_teacher_id, user_original_teacher.username AS original_teacher_username, user_original_teacher.user_detail AS original_teacher_detail,
     -- we cannot use sqlc.embed(slt), due to `Attendance` may have null `StudentLearningToken`.
     -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
     slt.id, slt.quota, slt.course_fee_quarter_value, slt.transport_fee_quarter_value, slt.created_at, slt.last_updated_at, slt.enrollment_id
@@ -156,6 +169,8 @@ FROM attendance
     LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
     LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
     LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+    LEFT JOIN teacher AS original_teacher ON attendance.original_teacher_id = original_teacher.id
+    LEFT JOIN user AS user_original_teacher ON original_teacher.user_id = user_original_teacher.id
 
     LEFT JOIN student_learning_token as slt ON attendance.token_id = slt.id
 WHERE
@@ -213,3 +228,30 @@ WHERE id = ?;
 -- name: DeleteAttendancesByIds :exec
 DELETE FROM attendance
 WHERE id IN (sqlc.slice('ids'));
+
+-- name: GetUnpaidAttendanceIdsByTokenId :many
+SELECT id FROM attendance
+WHERE token_id = ? AND is_paid = 0
+ORDER BY date, id;
+
+-- name: GetUnpaidAttendanceIdsByTokenIdFromDate :many
+SELECT id FROM attendance
+WHERE token_id = ? AND is_paid = 0 AND date >= ?
+ORDER BY date, id;
+
+-- name: GetPaidAttendanceIdsByTokenIdFromDate :many
+SELECT id FROM attendance
+WHERE token_id = ? AND is_paid = 1 AND date >= ?
+ORDER BY date, id;
+
+-- name: SetAttendancesOriginalTeacherByIds :exec
+-- SetAttendancesOriginalTeacherByIds records the class' current teacher as the original teacher. It must only be used right after inserting the attendances.
+UPDATE attendance
+SET original_teacher_id = (SELECT class.teacher_id FROM class WHERE class.id = attendance.class_id)
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: RefreshAttendancesSubstituteStatusByIds :exec
+-- RefreshAttendancesSubstituteStatusByIds flags the attendances taught by another teacher than their original teacher. Attendances without original teacher are never flagged.
+UPDATE attendance
+SET is_substitute = (original_teacher_id IS NOT NULL AND teacher_id <> original_teacher_id)
+WHERE id IN (sqlc.slice('ids'));
@@ -2,6 +2,7 @@
 -- To make things even worse: as MySQL's "SUM() of INT" type is decimal(32,0), Go's 'row.Scan(&fieldName)' will read the value as string (i.e. []uint8) instead of int!
 
 /* ============================== EXPENSE ============================== */
+-- The teacher filter applies to `attendance.teacher_id`, the teacher being paid. Thus, a substitution is the expense of the substitute teacher, not the class' teacher.
 -- name: GetExpenseOverview :many
 SELECT DATE_FORMAT(tp.added_at, '%Y-%m') AS year_with_month, CAST(sum(tp.paid_course_fee_value) AS SIGNED) AS total_paid_course_fee, CAST(sum(tp.paid_transport_fee_value) AS SIGNED) AS total_paid_transport_fee
 FROM teacher_payment AS tp
@@ -11,7 +12,7 @@ FROM teacher_payment AS tp
     JOIN course ON class.course_id = course.id
 WHERE
     (tp.added_at >= sqlc.arg('startDate') AND tp.added_at <= sqlc.arg('endDate'))
-    AND (class.teacher_id IN (sqlc.slice('teacher_ids')) OR sqlc.arg('use_teacher_filter') = false)
+    AND (attendance.teacher_id IN (sqlc.slice('teacher_ids')) OR sqlc.arg('use_teacher_filter') = false)
     AND (course.instrument_id IN (sqlc.slice('instrument_ids')) OR sqlc.arg('use_instrument_filter') = false)
 GROUP BY year_with_month
 ORDER BY year_with_month ASC;
@@ -28,7 +29,7 @@ FROM teacher_payment AS tp
     JOIN course ON class.course_id = course.id
 WHERE
     (tp.added_at >= sqlc.arg('startDate') AND tp.added_at <= sqlc.arg('endDate'))
-    AND (class.teacher_id IN (sqlc.slice('teacher_ids')) OR sqlc.arg('use_teacher_filter') = false)
+    AND (attendance.teacher_id IN (sqlc.slice('teacher_ids')) OR sqlc.arg('use_teacher_filter') = false)
     AND (course.instrument_id IN (sqlc.slice('instrument_ids')) OR sqlc.arg('use_instrument_filter') = false)
 GROUP BY teacher.id
 ORDER BY total_paid_course_fee;
@@ -42,7 +43,7 @@ FROM teacher_payment AS tp
     JOIN instrument ON instrument_id = instrument.id
 WHERE
     (tp.added_at >= sqlc.arg('startDate') AND tp.added_at <= sqlc.arg('endDate'))
-    AND (class.teacher_id IN (sqlc.slice('teacher_ids')) OR sqlc.arg('use_teacher_filter') = false)
+    AND (attendance.teacher_id IN (sqlc.slice('teacher_ids')) OR sqlc.arg('use_teacher_filter') = false)
     AND (course.instrument_id IN (sqlc.slice('instrument_ids')) OR sqlc.arg('use_instrument_filter') = false)
 GROUP BY instrument.id
 ORDER BY total_paid_course_fee;
@@ -92,3 +93,87 @@ WHERE
     AND (course.instrument_id IN (sqlc.slice('instrument_ids')) OR sqlc.arg('use_instrument_filter') = false)
 GROUP BY instrument.id
 ORDER BY total_course_fee;
+
+/* ============================== DISCOUNT ============================== */
+-- name: GetDiscountMonthlySummaryGroupedByDiscountRule :many
+SELECT epd.discount_rule_name, CAST(sum(epd.value) AS SIGNED) AS total_discount_value
+FROM enrollment_payment_discount AS epd
+    JOIN enrollment_payment AS ep ON epd.enrollment_payment_id = ep.id
+WHERE ep.payment_date >= sqlc.arg('startDate') AND ep.payment_date <= sqlc.arg('endDate')
+GROUP BY epd.discount_rule_name
+ORDER BY total_discount_value;
+
+-- name: GetTotalDiscountFeeValue :one
+SELECT CAST(COALESCE(sum(ep.discount_fee_value), 0) AS SIGNED) AS total_discount_fee_value
+FROM enrollment_payment AS ep
+WHERE ep.payment_date >= sqlc.arg('startDate') AND ep.payment_date <= sqlc.arg('endDate');
+
+/* ============================== RECEIVABLE ============================== */
+-- name: GetNegativeQuotaSLTs :many
+SELECT slt.id, slt.quota, slt.course_fee_quarter_value, slt.transport_fee_quarter_value, slt.enrollment_id
+FROM student_learning_token AS slt
+    JOIN student_enrollment AS se ON slt.enrollment_id = se.id
+    -- we need this joins just for the filtering (teacher_id & instrument_id)
+    JOIN class ON se.class_id = class.id
+    JOIN course ON class.course_id = course.id
+WHERE slt.quota < 0
+    AND (class.teacher_id IN (sqlc.slice('teacher_ids')) OR sqlc.arg('use_teacher_filter') = false)
+    AND (course.instrument_id IN (sqlc.slice('instrument_ids')) OR sqlc.arg('use_instrument_filter') = false)
+ORDER BY slt.enrollment_id, slt.id;
+
+-- name: GetAttendanceQuotaUsagesByTokenIds :many
+-- GetAttendanceQuotaUsagesByTokenIds returns the attendances of the tokens in chronological order, for finding the ones which haven't been covered by any payment.
+SELECT id, date, used_student_token_quota, token_id
+FROM attendance
+WHERE token_id IN (sqlc.slice('token_ids'))
+ORDER BY token_id, date, id;
+
+/* ============================== REFUND ============================== */
+-- name: GetRefundOverview :many
+-- Refunds are subtracted from the income on the month of the refund, check GetIncomeOverview.
+SELECT DATE_FORMAT(epr.refund_date, '%Y-%m') AS year_with_month, CAST(sum(epr.refunded_value) AS SIGNED) AS total_refunded_value
+FROM enrollment_payment_refund AS epr
+    JOIN enrollment_payment AS ep ON epr.enrollment_payment_id = ep.id
+    -- we need this joins just for the filtering (student_id & instrument_id)
+    JOIN student_enrollment AS se ON ep.enrollment_id = se.id
+    JOIN class ON se.class_id = class.id
+    JOIN course ON class.course_id = course.id
+WHERE
+    (epr.refund_date >= sqlc.arg('startDate') AND epr.refund_date <= sqlc.arg('endDate'))
+    AND (se.student_id IN (sqlc.slice('student_ids')) OR sqlc.arg('use_student_filter') = false)
+    AND (course.instrument_id IN (sqlc.slice('instrument_ids')) OR sqlc.arg('use_instrument_filter') = false)
+GROUP BY year_with_month
+ORDER BY year_with_month ASC;
+
+-- name: GetRefundMonthlySummaryGroupedByStudent :many
+SELECT student.id AS student_id, user.id AS user_id, user_detail, CAST(sum(epr.refunded_value) AS SIGNED) AS total_refunded_value
+FROM enrollment_payment_refund AS epr
+    JOIN enrollment_payment AS ep ON epr.enrollment_payment_id = ep.id
+    JOIN student_enrollment AS se ON ep.enrollment_id = se.id
+    JOIN student ON se.student_id = student.id
+    JOIN user ON student.user_id = user.id
+
+    -- we need this joins just for the filtering (student_id & instrument_id)
+    JOIN class ON se.class_id = class.id
+    JOIN course ON class.course_id = course.id
+WHERE
+    (epr.refund_date >= sqlc.arg('startDate') AND epr.refund_date <= sqlc.arg('endDate'))
+    AND (se.student_id IN (sqlc.slice('student_ids')) OR sqlc.arg('use_student_filter') = false)
+    AND (course.instrument_id IN (sqlc.slice('instrument_ids')) OR sqlc.arg('use_instrument_filter') = false)
+GROUP BY student.id
+ORDER BY total_refunded_value;
+
+-- name: GetRefundMonthlySummaryGroupedByInstrument :many
+SELECT sqlc.embed(instrument), CAST(sum(epr.refunded_value) AS SIGNED) AS total_refunded_value
+FROM enrollment_payment_refund AS epr
+    JOIN enrollment_payment AS ep ON epr.enrollment_payment_id = ep.id
+    JOIN student_enrollment AS se ON ep.enrollment_id = se.id
+    JOIN class ON se.class_id = class.id
+    JOIN course ON class.course_id = course.id
+    JOIN instrument ON instrument_id = instrument.id
+WHERE
+    (epr.refund_date >= sqlc.arg('startDate') AND epr.refund_date <= sqlc.arg('endDate'))
+    AND (se.student_id IN (sqlc.slice('student_ids')) OR sqlc.arg('use_student_filter') = false)
+    AND (course.instrument_id IN (sqlc.slice('instrument_ids')) OR sqlc.arg('use_instrument_filter') = false)
+GROUP BY instrument.id
+ORDER BY total_refunded_value;
@@ -1,19 +1,23 @@
 /* ============================== ENROLLMENT_PAYMENT ============================== */
 -- name: GetEnrollmentPaymentById :one
-SELECT ep.id AS enrollment_payment_id, payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, se.id AS student_enrollment_id,
+SELECT ep.id AS enrollment_payment_id, payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, payment_method, receiving_account, reference_number, ep.enrollment_id AS student_enrollment_id,
     se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
-    sqlc.embed(class), tsf.fee AS teacher_special_fee, sqlc.embed(course), sqlc.embed(instrument), sqlc.embed(grade),
-    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail
+    -- we cannot use sqlc.embed(class|course|instrument|grade), due to `EnrollmentPayment` may have null `StudentEnrollment` (deleted enrollment).
+    -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
+    class.id AS class_id, class.transport_fee AS class_transport_fee, class.auto_owe_attendance_token AS class_auto_owe_attendance_token, class.is_deactivated AS class_is_deactivated, tsf.fee AS teacher_special_fee,
+    course.id AS course_id, course.default_fee AS course_default_fee, course.default_duration_minute AS course_default_duration_minute, instrument.id AS instrument_id, instrument.name AS instrument_name, grade.id AS grade_id, grade.name AS grade_name,
+    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    ep.snapshot_student_name, ep.snapshot_class_description, ep.snapshot_course_name, ep.snapshot_instrument_name, ep.snapshot_grade_name, ep.snapshot_teacher_name
 FROM enrollment_payment AS ep
-    JOIN student_enrollment AS se ON ep.enrollment_id = se.id
+    LEFT JOIN student_enrollment AS se ON ep.enrollment_id = se.id
 
-    JOIN student ON se.student_id = student.id
-    JOIN user AS user_student ON student.user_id = user_student.id
+    LEFT JOIN student ON se.student_id = student.id
+    LEFT JOIN user AS user_student ON student.user_id = user_student.id
     
-    JOIN class on se.class_id = class.id
-    JOIN course ON class.course_id = course.id
-    JOIN instrument ON course.instrument_id = instrument.id
-    JOIN grade ON course.grade_id = grade.id
+    LEFT JOIN class on se.class_id = class.id
+    LEFT JOIN course ON class.course_id = course.id
+    LEFT JOIN instrument ON course.instrument_id = instrument.id
+    LEFT JOIN grade ON course.grade_id = grade.id
     
     LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
     LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
@@ -21,20 +25,24 @@ FROM enrollment_payment AS ep
 WHERE ep.id = ? LIMIT 1;
 
 -- name: GetEnrollmentPaymentsByIds :many
-SELECT ep.id AS enrollment_payment_id, payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, se.id AS student_enrollment_id,
+SELECT ep.id AS enrollment_payment_id, payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, payment_method, receiving_account, reference_number, ep.enrollment_id AS student_enrollment_id,
     se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
-    sqlc.embed(class), tsf.fee AS teacher_special_fee, sqlc.embed(course), sqlc.embed(instrument), sqlc.embed(grade),
-    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail
+    -- we cannot use sqlc.embed(class|course|instrument|grade), due to `EnrollmentPayment` may have null `StudentEnrollment` (deleted enrollment).
+    -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
+    class.id AS class_id, class.transport_fee AS class_transport_fee, class.auto_owe_attendance_token AS class_auto_owe_attendance_token, class.is_deactivated AS class_is_deactivated, tsf.fee AS teacher_special_fee,
+    course.id AS course_id, course.default_fee AS course_default_fee, course.default_duration_minute AS course_default_duration_minute, instrument.id AS instrument_id, instrument.name AS instrument_name, grade.id AS grade_id, grade.name AS grade_name,
+    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    ep.snapshot_student_name, ep.snapshot_class_description, ep.snapshot_course_name, ep.snapshot_instrument_name, ep.snapshot_grade_name, ep.snapshot_teacher_name
 FROM enrollment_payment AS ep
-    JOIN student_enrollment AS se ON ep.enrollment_id = se.id
+    LEFT JOIN student_enrollment AS se ON ep.enrollment_id = se.id
 
-    JOIN student ON se.student_id = student.id
-    JOIN user AS user_student ON student.user_id = user_student.id
+    LEFT JOIN student ON se.student_id = student.id
+    LEFT JOIN user AS user_student ON student.user_id = user_student.id
     
-    JOIN class on se.class_id = class.id
-    JOIN course ON class.course_id = course.id
-    JOIN instrument ON course.instrument_id = instrument.id
-    JOIN grade ON course.grade_id = grade.id
+    LEFT JOIN class on se.class_id = class.id
+    LEFT JOIN course ON class.course_id = course.id
+    LEFT JOIN instrument ON course.instrument_id = instrument.id
+    LEFT JOIN grade ON course.grade_id = grade.id
     
     LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
     LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
@@ -42,20 +50,24 @@ FROM enrollment_payment AS ep
 WHERE ep.id IN (sqlc.slice('ids'));
 
 -- name: GetEnrollmentPayments :many
-SELECT ep.id AS enrollment_payment_id, payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, se.id AS student_enrollment_id,
+SELECT ep.id AS enrollment_payment_id, payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, payment_method, receiving_account, reference_number, ep.enrollment_id AS student_enrollment_id,
     se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
-    sqlc.embed(class), tsf.fee AS teacher_special_fee, sqlc.embed(course), sqlc.embed(instrument), sqlc.embed(grade),
-    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail
+    -- we cannot use sqlc.embed(class|course|instrument|grade), due to `EnrollmentPayment` may have null `StudentEnrollment` (deleted enrollment).
+    -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
+    class.id AS class_id, class.transport_fee AS class_transport_fee, class.auto_owe_attendance_token AS class_auto_owe_attendance_token, class.is_deactivated AS class_is_deactivated, tsf.fee AS teacher_special_fee,
+    course.id AS course_id, course.default_fee AS course_default_fee, course.default_duration_minute AS course_default_duration_minute, instrument.id AS instrument_id, instrument.name AS instrument_name, grade.id AS grade_id, grade.name AS grade_name,
+    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    ep.snapshot_student_name, ep.snapshot_class_description, ep.snapshot_course_name, ep.snapshot_instrument_name, ep.snapshot_grade_name, ep.snapshot_teacher_name
 FROM enrollment_payment AS ep
-    JOIN student_enrollment AS se ON ep.enrollment_id = se.id
+    LEFT JOIN student_enrollment AS se ON ep.enrollment_id = se.id
 
-    JOIN student ON se.student_id = student.id
-    JOIN user AS user_student ON student.user_id = user_student.id
+    LEFT JOIN student ON se.student_id = student.id
+    LEFT JOIN user AS user_student ON student.user_id = user_student.id
     
-    JOIN class on se.class_id = class.id
-    JOIN course ON class.course_id = course.id
-    JOIN instrument ON course.instrument_id = instrument.id
-    JOIN grade ON course.grade_id = grade.id
+    LEFT JOIN class on se.class_id = class.id
+    LEFT JOIN course ON class.course_id = course.id
+    LEFT JOIN instrument ON course.instrument_id = instrument.id
+    LEFT JOIN grade ON course.grade_id = grade.id
     
     LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
     LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
@@ -73,20 +85,24 @@ GROUP BY enrollment_id LIMIT 1;
 
 -- name: GetEnrollmentPaymentsDescendingDate :many
 -- GetEnrollmentPaymentsDescendingDate is a copy of GetEnrollmentPayments, with additional sort by date parameter. TODO: find alternative: sqlc's dynamic query which is mature enough, so that we need to do this.
-SELECT ep.id AS enrollment_payment_id, payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, se.id AS student_enrollment_id,
+SELECT ep.id AS enrollment_payment_id, payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, payment_method, receiving_account, reference_number, ep.enrollment_id AS student_enrollment_id,
     se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
-    sqlc.embed(class), tsf.fee AS teacher_special_fee, sqlc.embed(course), sqlc.embed(instrument), sqlc.embed(grade),
-    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail
+    -- we cannot use sqlc.embed(class|course|instrument|grade), due to `EnrollmentPayment` may have null `StudentEnrollment` (deleted enrollment).
+    -- SQLC has not yet had the capability to create pointer to struct, when the join result could be null.
+    class.id AS class_id, class.transport_fee AS class_transport_fee, class.auto_owe_attendance_token AS class_auto_owe_attendance_token, class.is_deactivated AS class_is_deactivated, tsf.fee AS teacher_special_fee,
+    course.id AS course_id, course.default_fee AS course_default_fee, course.default_duration_minute AS course_default_duration_minute, instrument.id AS instrument_id, instrument.name AS instrument_name, grade.id AS grade_id, grade.name AS grade_name,
+    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail,
+    ep.snapshot_student_name, ep.snapshot_class_description, ep.snapshot_course_name, ep.snapshot_instrument_name, ep.snapshot_grade_name, ep.snapshot_teacher_name
 FROM enrollment_payment AS ep
-    JOIN student_enrollment AS se ON ep.enrollment_id = se.id
+    LEFT JOIN student_enrollment AS se ON ep.enrollment_id = se.id
 
-    JOIN student ON se.student_id = student.id
-    JOIN user AS user_student ON student.user_id = user_student.id
+    LEFT JOIN student ON se.student_id = student.id
+    LEFT JOIN user AS user_student ON student.user_id = user_student.id
     
-    JOIN class on se.class_id = class.id
-    JOIN course ON class.course_id = course.id
-    JOIN instrument ON course.instrument_id = instrument.id
-    JOIN grade ON course.grade_id = grade.id
+    LEFT JOIN class on se.class_id = class.id
+    LEFT JOIN course ON class.course_id = course.id
+    LEFT JOIN instrument ON course.instrument_id = instrument.id
+    LEFT JOIN grade ON course.grade_id = grade.id
     
     LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
     LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
@@ -105,13 +121,15 @@ SELECT Count(id) AS total FROM enrollment_payment;
 
 -- name: InsertEnrollmentPayment :execlastid
 INSERT INTO enrollment_payment (
-    payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, enrollment_id
+    payment_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, payment_method, receiving_account, reference_number, enrollment_id,
+    snapshot_student_name, snapshot_class_description, snapshot_course_name, snapshot_instrument_name, snapshot_grade_name, snapshot_teacher_name
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+    ?, ?, ?, ?, ?, ?
 );
 
 -- name: UpdateEnrollmentPayment :exec
-UPDATE enrollment_payment SET payment_date = ?, balance_top_up = ?, balance_bonus = ?, course_fee_value = ?, transport_fee_value = ?, penalty_fee_value = ?, discount_fee_value = ?
+UPDATE enrollment_payment SET payment_date = ?, balance_top_up = ?, balance_bonus = ?, course_fee_value = ?, transport_fee_value = ?, penalty_fee_value = ?, discount_fee_value = ?, payment_method = ?, receiving_account = ?, reference_number = ?
 WHERE id = ?;
 
 -- name: UpdateEnrollmentPaymentOnSafeAttributes :exec
@@ -286,6 +304,7 @@ WHERE teacher_payment.id IN (sqlc.slice('teacher_payment_ids'));
 
 -- name: GetTeacherPaymentsByTeacherId :many
 SELECT tp.id AS teacher_payment_id, paid_course_fee_value, paid_transport_fee_value, added_at,
+    tp.snapshot_student_name, tp.snapshot_class_description, tp.snapshot_course_name, tp.snapshot_instrument_name, tp.snapshot_grade_name, tp.snapshot_teacher_name,
     sqlc.embed(attendance),
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
@@ -316,6 +335,7 @@ ORDER BY attendance.date DESC, tp.id ASC;
 
 -- name: GetTeacherPaymentById :one
 SELECT tp.id AS teacher_payment_id, paid_course_fee_value, paid_transport_fee_value, added_at,
+    tp.snapshot_student_name, tp.snapshot_class_description, tp.snapshot_course_name, tp.snapshot_instrument_name, tp.snapshot_grade_name, tp.snapshot_teacher_name,
     sqlc.embed(attendance),
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
@@ -343,6 +363,7 @@ WHERE tp.id = ? LIMIT 1;
 
 -- name: GetTeacherPaymentsByIds :many
 SELECT tp.id AS teacher_payment_id, paid_course_fee_value, paid_transport_fee_value, added_at,
+    tp.snapshot_student_name, tp.snapshot_class_description, tp.snapshot_course_name, tp.snapshot_instrument_name, tp.snapshot_grade_name, tp.snapshot_teacher_name,
     sqlc.embed(attendance),
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
@@ -370,6 +391,7 @@ WHERE tp.id IN (sqlc.slice('ids'));
 
 -- name: GetTeacherPayments :many
 SELECT tp.id AS teacher_payment_id, paid_course_fee_value, paid_transport_fee_value, added_at,
+    tp.snapshot_student_name, tp.snapshot_class_description, tp.snapshot_course_name, tp.snapshot_instrument_name, tp.snapshot_grade_name, tp.snapshot_teacher_name,
     sqlc.embed(attendance),
     attendance.teacher_id AS teacher_id, user_teacher.username AS teacher_username, user_teacher.user_detail AS teacher_detail,
     attendance.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
@@ -412,9 +434,11 @@ WHERE
 
 -- name: InsertTeacherPayment :execlastid
 INSERT INTO teacher_payment (
-    attendance_id, paid_course_fee_value, paid_transport_fee_value
+    attendance_id, paid_course_fee_value, paid_transport_fee_value,
+    snapshot_student_name, snapshot_class_description, snapshot_course_name, snapshot_instrument_name, snapshot_grade_name, snapshot_teacher_name
 ) VALUES (
-    ?, ?, ?
+    ?, ?, ?,
+    ?, ?, ?, ?, ?, ?
 );
 
 -- name: UpdateTeacherPayment :exec
@@ -432,3 +456,701 @@ WHERE id = ?;
 -- name: DeleteTeacherPaymentsByIds :exec
 DELETE FROM teacher_payment
 WHERE id IN (sqlc.slice('ids'));
+
+/* ============================== PENALTY_POLICY ============================== */
+-- name: GetPenaltyPolicyById :one
+SELECT * FROM penalty_policy
+WHERE id = ? LIMIT 1;
+
+-- name: GetPenaltyPoliciesByIds :many
+SELECT * FROM penalty_policy
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: GetPenaltyPolicies :many
+SELECT * FROM penalty_policy
+ORDER BY id
+LIMIT ? OFFSET ?;
+
+-- name: GetApplicablePenaltyPolicy :one
+-- GetApplicablePenaltyPolicy returns the most specific penalty_policy for a class: class policy > course policy > school-wide default.
+SELECT * FROM penalty_policy
+WHERE class_id = sqlc.arg('class_id') OR course_id = sqlc.arg('course_id') OR (class_id IS NULL AND course_id IS NULL)
+ORDER BY (class_id IS NOT NULL) DESC, (course_id IS NOT NULL) DESC, id
+LIMIT 1;
+
+-- name: CountPenaltyPoliciesByIds :one
+SELECT Count(id) AS total FROM penalty_policy
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: CountPenaltyPolicies :one
+SELECT Count(id) AS total FROM penalty_policy;
+
+-- name: InsertPenaltyPolicy :execlastid
+INSERT INTO penalty_policy (
+    name, trigger_day_of_month, grace_days, is_flat_fee, fee_value, max_fee_value, course_id, class_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: UpdatePenaltyPolicy :exec
+UPDATE penalty_policy SET name = ?, trigger_day_of_month = ?, grace_days = ?, is_flat_fee = ?, fee_value = ?, max_fee_value = ?, course_id = ?, class_id = ?
+WHERE id = ?;
+
+-- name: DeletePenaltyPoliciesByIds :exec
+DELETE FROM penalty_policy
+WHERE id IN (sqlc.slice('ids'));
+
+/* ============================== SLT_TRANSACTION ============================== */
+-- name: GetSLTTransactionsByTokenId :many
+SELECT slt_transaction.id AS slt_transaction_id, quota_change, reason, source_id, user_id, user.username AS username, slt_transaction.created_at AS created_at, token_id
+FROM slt_transaction
+    LEFT JOIN user ON user_id = user.id
+WHERE token_id = ?
+ORDER BY slt_transaction.id;
+
+-- name: InsertSLTTransaction :execlastid
+INSERT INTO slt_transaction (
+    quota_change, reason, source_id, user_id, created_at, token_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+);
+
+/* ============================== SLT_RECONCILIATION ============================== */
+-- name: GetSLTsForReconciliation :many
+SELECT * FROM student_learning_token
+ORDER BY id;
+
+-- name: GetEnrollmentPaymentsForSLTReconciliation :many
+SELECT * FROM enrollment_payment
+WHERE enrollment_id IS NOT NULL
+ORDER BY id;
+
+-- name: GetAttendancesUsedQuotaGroupedByTokenId :many
+-- CAST(.. AS DOUBLE) is to force SQLC to generate the field type as float64. Else, it will be interface{}.
+SELECT token_id, CAST(SUM(used_student_token_quota) AS DOUBLE) AS total_used_quota
+FROM attendance
+WHERE token_id IS NOT NULL
+GROUP BY token_id;
+
+/* ============================== SLT_DRY_RUN ============================== */
+-- name: GetLatestSLTTransactionId :one
+SELECT CAST(COALESCE(MAX(id), 0) AS SIGNED) AS latest_id FROM slt_transaction;
+
+-- name: GetLatestSLTId :one
+SELECT CAST(COALESCE(MAX(id), 0) AS SIGNED) AS latest_id FROM student_learning_token;
+
+-- name: GetSLTIdsAfterId :many
+SELECT id FROM student_learning_token
+WHERE id > ?
+ORDER BY id;
+
+-- name: GetSLTTransactionsAfterId :many
+SELECT slt_transaction.id AS slt_transaction_id, quota_change, reason, source_id, user_id, user.username AS username, slt_transaction.created_at AS created_at, token_id
+FROM slt_transaction
+    LEFT JOIN user ON user_id = user.id
+WHERE slt_transaction.id > ?
+ORDER BY slt_transaction.id;
+
+/* ============================== SLT_TRANSFER ============================== */
+-- name: InsertSLTTransfer :execlastid
+INSERT INTO slt_transfer (
+    transferred_quota, converted_quota, note, user_id, created_at, source_token_id, destination_token_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: GetSLTTransactionsQuotaChangeGroupedByTokenIdExcludingReasons :many
+SELECT token_id, CAST(SUM(quota_change) AS DOUBLE) AS total_quota_change
+FROM slt_transaction
+WHERE reason NOT IN (sqlc.slice('excluded_reasons'))
+GROUP BY token_id;
+
+/* ============================== SLT_REPRICING ============================== */
+-- name: GetPositiveSLTsForRepricing :many
+SELECT slt.id, slt.quota, slt.course_fee_quarter_value, slt.transport_fee_quarter_value, slt.enrollment_id, class.teacher_id AS class_teacher_id, class.course_id AS class_course_id
+FROM student_learning_token AS slt
+    JOIN student_enrollment AS se ON slt.enrollment_id = se.id
+    JOIN class ON se.class_id = class.id
+WHERE slt.quota > 0 AND class.course_id = COALESCE(sqlc.narg('course_id'), class.course_id)
+ORDER BY slt.id;
+
+/* ============================== TEACHER_FEE_SHARING ============================== */
+-- name: GetTeacherFeeSharingById :one
+SELECT * FROM teacher_fee_sharing
+WHERE id = ? LIMIT 1;
+
+-- name: GetTeacherFeeSharingsByIds :many
+SELECT * FROM teacher_fee_sharing
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: GetTeacherFeeSharings :many
+SELECT * FROM teacher_fee_sharing
+ORDER BY teacher_id, course_id, effective_from DESC, id DESC
+LIMIT ? OFFSET ?;
+
+-- name: GetTeacherFeeSharingsByTeacherId :many
+-- GetTeacherFeeSharingsByTeacherId returns all teacher_fee_sharings which may apply to the teacher's attendances, i.e. the teacher's ones, along with the course & school-wide ones.
+SELECT * FROM teacher_fee_sharing
+WHERE teacher_id = ? OR teacher_id IS NULL
+ORDER BY effective_from DESC, id DESC;
+
+-- name: CountTeacherFeeSharingsByIds :one
+SELECT Count(id) AS total FROM teacher_fee_sharing
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: CountTeacherFeeSharings :one
+SELECT Count(id) AS total FROM teacher_fee_sharing;
+
+-- name: InsertTeacherFeeSharing :execlastid
+INSERT INTO teacher_fee_sharing (
+    course_fee_sharing_percentage, transport_fee_sharing_percentage, effective_from, teacher_id, course_id
+) VALUES (
+    ?, ?, ?, ?, ?
+);
+
+-- name: UpdateTeacherFeeSharing :exec
+UPDATE teacher_fee_sharing SET course_fee_sharing_percentage = ?, transport_fee_sharing_percentage = ?, effective_from = ?, teacher_id = ?, course_id = ?
+WHERE id = ?;
+
+-- name: DeleteTeacherFeeSharingsByIds :exec
+DELETE FROM teacher_fee_sharing
+WHERE id IN (sqlc.slice('ids'));
+
+/* ============================== PAYROLL_RUN ============================== */
+-- name: GetPayrollRuns :many
+SELECT pr.id, pr.start_date, pr.end_date, pr.status, pr.note, pr.created_at, pr.approved_at, pr.paid_at,
+    pr.created_by_user_id, user_creator.username AS created_by_username,
+    pr.approved_by_user_id, user_approver.username AS approved_by_username
+FROM payroll_run AS pr
+    LEFT JOIN user AS user_creator ON pr.created_by_user_id = user_creator.id
+    LEFT JOIN user AS user_approver ON pr.approved_by_user_id = user_approver.id
+ORDER BY pr.id DESC
+LIMIT ? OFFSET ?;
+
+-- name: CountPayrollRuns :one
+SELECT Count(id) AS total FROM payroll_run;
+
+-- name: GetPayrollRunById :one
+SELECT pr.id, pr.start_date, pr.end_date, pr.status, pr.note, pr.created_at, pr.approved_at, pr.paid_at,
+    pr.created_by_user_id, user_creator.username AS created_by_username,
+    pr.approved_by_user_id, user_approver.username AS approved_by_username
+FROM payroll_run AS pr
+    LEFT JOIN user AS user_creator ON pr.created_by_user_id = user_creator.id
+    LEFT JOIN user AS user_approver ON pr.approved_by_user_id = user_approver.id
+WHERE pr.id = ? LIMIT 1;
+
+-- name: GetPayrollRunStatusByIdForUpdate :one
+-- GetPayrollRunStatusByIdForUpdate locks the payroll_run row until the end of the transaction, to serialize status transitions & teacher_payment submissions.
+SELECT status FROM payroll_run
+WHERE id = ? LIMIT 1
+FOR UPDATE;
+
+-- name: InsertPayrollRun :execlastid
+INSERT INTO payroll_run (
+    start_date, end_date, note, created_by_user_id
+) VALUES (
+    ?, ?, ?, ?
+);
+
+-- name: ApprovePayrollRun :exec
+UPDATE payroll_run SET status = 'APPROVED', approved_at = CURRENT_TIMESTAMP, approved_by_user_id = ?
+WHERE id = ?;
+
+-- name: MarkPayrollRunAsPaid :exec
+UPDATE payroll_run SET status = 'PAID', paid_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: InsertPayrollRunTeacher :exec
+INSERT INTO payroll_run_teacher (
+    payroll_run_id, teacher_id
+) VALUES (
+    ?, ?
+);
+
+-- name: InsertPayrollRunTeachersByAttendanceIds :exec
+-- InsertPayrollRunTeachersByAttendanceIds adds the teachers of the attendances into the payroll_run, skipping the already-added ones.
+INSERT IGNORE INTO payroll_run_teacher (payroll_run_id, teacher_id)
+SELECT DISTINCT ?, teacher_id FROM attendance
+WHERE id IN (sqlc.slice('attendance_ids')) AND teacher_id IS NOT NULL;
+
+-- name: GetAttendanceIdsOutsideDateRangeByIds :many
+-- GetAttendanceIdsOutsideDateRangeByIds returns the attendances which are dated outside of the given period, e.g. of a payroll_run.
+SELECT id FROM attendance
+WHERE id IN (sqlc.slice('ids')) AND (date < sqlc.arg('startDate') OR date > sqlc.arg('endDate'));
+
+-- name: SetTeacherPaymentsPayrollRunIdByIds :exec
+UPDATE teacher_payment SET payroll_run_id = ?
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: CountLockedTeacherPaymentsByIds :one
+-- CountLockedTeacherPaymentsByIds counts the teacher_payments which belong to a non-draft payroll_run, thus cannot be edited nor removed.
+SELECT Count(tp.id) AS total
+FROM teacher_payment AS tp
+    JOIN payroll_run AS pr ON tp.payroll_run_id = pr.id
+WHERE tp.id IN (sqlc.slice('ids')) AND pr.status <> 'DRAFT';
+
+-- name: GetPayrollRunTeacherTotals :many
+-- GetPayrollRunTeacherTotals sums up the teacher_payments of a payroll_run, grouped by teacher. Teachers without any teacher_payment yet are included with zero totals.
+SELECT teacher.id AS teacher_id, user.username, user.user_detail,
+    Count(tp.id) AS total_teacher_payments,
+    CAST(COALESCE(SUM(attendance.used_student_token_quota), 0) AS DOUBLE) AS total_attendances,
+    CAST(COALESCE(SUM(tp.paid_course_fee_value), 0) AS SIGNED) AS total_paid_course_fee_value,
+    CAST(COALESCE(SUM(tp.paid_transport_fee_value), 0) AS SIGNED) AS total_paid_transport_fee_value
+FROM payroll_run_teacher AS prt
+    JOIN teacher ON prt.teacher_id = teacher.id
+    JOIN user ON teacher.user_id = user.id
+    LEFT JOIN (teacher_payment AS tp JOIN attendance ON tp.attendance_id = attendance.id)
+        ON (tp.payroll_run_id = prt.payroll_run_id AND attendance.teacher_id = prt.teacher_id)
+WHERE prt.payroll_run_id = ?
+GROUP BY teacher.id, user.username, user.user_detail
+ORDER BY user.username;
+
+/* ============================== CLOSED_PERIOD ============================== */
+-- name: GetClosedPeriods :many
+SELECT cp.id, cp.closed_until, cp.note, cp.created_at, cp.created_by_user_id, user.username AS created_by_username
+FROM closed_period AS cp
+    LEFT JOIN user ON cp.created_by_user_id = user.id
+ORDER BY cp.closed_until DESC, cp.id DESC
+LIMIT ? OFFSET ?;
+
+-- name: CountClosedPeriods :one
+SELECT Count(id) AS total FROM closed_period;
+
+-- name: GetClosedPeriodById :one
+SELECT cp.id, cp.closed_until, cp.note, cp.created_at, cp.created_by_user_id, user.username AS created_by_username
+FROM closed_period AS cp
+    LEFT JOIN user ON cp.created_by_user_id = user.id
+WHERE cp.id = ? LIMIT 1;
+
+-- name: GetLatestClosedPeriodClosedUntil :one
+-- GetLatestClosedPeriodClosedUntil returns the end date of the closed accounting period, i.e. the latest `closed_until`.
+SELECT closed_until FROM closed_period
+ORDER BY closed_until DESC
+LIMIT 1;
+
+-- name: InsertClosedPeriod :execlastid
+INSERT INTO closed_period (
+    closed_until, note, created_by_user_id
+) VALUES (
+    ?, ?, ?
+);
+
+-- name: DeleteClosedPeriodsByIds :exec
+DELETE FROM closed_period
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: GetClosedPeriodOverrides :many
+SELECT cpo.id, cpo.operation, cpo.reason, cpo.closed_until, cpo.earliest_record_date, cpo.created_at, cpo.user_id, user.username
+FROM closed_period_override AS cpo
+    LEFT JOIN user ON cpo.user_id = user.id
+ORDER BY cpo.id DESC
+LIMIT ? OFFSET ?;
+
+-- name: CountClosedPeriodOverrides :one
+SELECT Count(id) AS total FROM closed_period_override;
+
+-- name: InsertClosedPeriodOverride :execlastid
+INSERT INTO closed_period_override (
+    operation, reason, closed_until, earliest_record_date, user_id
+) VALUES (
+    ?, ?, ?, ?, ?
+);
+
+-- name: GetAttendanceDatesByIds :many
+SELECT date FROM attendance
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: GetEnrollmentPaymentDatesByIds :many
+SELECT payment_date FROM enrollment_payment
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: GetTeacherPaymentAddedAtsByIds :many
+SELECT added_at FROM teacher_payment
+WHERE id IN (sqlc.slice('ids'));
+
+/* ============================== ENROLLMENT_PAYMENT_RECEIPT ============================== */
+-- name: GetEnrollmentPaymentReceiptByEnrollmentPaymentId :one
+SELECT * FROM enrollment_payment_receipt
+WHERE enrollment_payment_id = ? LIMIT 1;
+
+-- name: GetLastReceiptNumberForUpdate :one
+-- GetLastReceiptNumberForUpdate locks the receipt number counter until the transaction ends, which serializes the receipt number allocation.
+SELECT last_receipt_number FROM receipt_number_sequence
+WHERE id = 1
+FOR UPDATE;
+
+-- name: UpdateLastReceiptNumber :exec
+UPDATE receipt_number_sequence SET last_receipt_number = ?
+WHERE id = 1;
+
+-- name: InsertEnrollmentPaymentReceipt :execlastid
+INSERT INTO enrollment_payment_receipt (
+    receipt_number, enrollment_payment_id, issued_by_user_id
+) VALUES (
+    ?, ?, ?
+);
+
+/* ============================== CASH_UP_DAY ============================== */
+-- name: GetEnrollmentPaymentTotalsGroupedByPaymentMethod :many
+-- GetEnrollmentPaymentTotalsGroupedByPaymentMethod sums up the received money (i.e. after discount) of enrollment_payments within a date range, grouped by payment method & receiving account.
+SELECT payment_method, receiving_account,
+    Count(id) AS total_payments,
+    CAST(COALESCE(SUM(course_fee_value + transport_fee_value + penalty_fee_value - discount_fee_value), 0) AS SIGNED) AS total_value
+FROM enrollment_payment
+WHERE payment_date >= sqlc.arg('startDate') AND payment_date <= sqlc.arg('endDate')
+GROUP BY payment_method, receiving_account
+ORDER BY payment_method, receiving_account;
+
+-- name: GetEnrollmentPaymentRefundTotalsGroupedByPaymentMethod :many
+-- GetEnrollmentPaymentRefundTotalsGroupedByPaymentMethod sums up the refunded money within a date range, grouped by the refunded enrollment_payments' payment method & receiving account.
+SELECT ep.payment_method, ep.receiving_account,
+    Count(epr.id) AS total_refunds,
+    CAST(COALESCE(SUM(epr.refunded_value), 0) AS SIGNED) AS total_refunded_value
+FROM enrollment_payment_refund AS epr
+    JOIN enrollment_payment AS ep ON epr.enrollment_payment_id = ep.id
+WHERE epr.refund_date >= sqlc.arg('startDate') AND epr.refund_date <= sqlc.arg('endDate')
+GROUP BY ep.payment_method, ep.receiving_account
+ORDER BY ep.payment_method, ep.receiving_account;
+
+-- name: GetCashUpDayByDate :one
+SELECT cud.id, cud.date, cud.counted_cash_value, cud.recorded_cash_value, cud.difference_value, cud.note, cud.closed_at, cud.closed_by_user_id, user.username AS closed_by_username
+FROM cash_up_day AS cud
+    LEFT JOIN user ON cud.closed_by_user_id = user.id
+WHERE cud.date = ? LIMIT 1;
+
+-- name: GetClosedCashUpDatesByDates :many
+SELECT date FROM cash_up_day
+WHERE date IN (sqlc.slice('dates'));
+
+-- name: InsertCashUpDay :execlastid
+INSERT INTO cash_up_day (
+    date, counted_cash_value, recorded_cash_value, difference_value, note, closed_by_user_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+);
+
+-- name: DeleteCashUpDayByDate :execrows
+DELETE FROM cash_up_day
+WHERE date = ?;
+
+/* ============================== BANK_STATEMENT ============================== */
+-- name: GetBankStatementImports :many
+SELECT bsi.id, bsi.bank_format, bsi.file_name, bsi.imported_at, bsi.imported_by_user_id, user.username AS imported_by_username,
+    Count(bsl.id) AS total_lines,
+    CAST(COALESCE(SUM(bsl.enrollment_payment_id IS NULL), 0) AS SIGNED) AS total_unmatched_lines
+FROM bank_statement_import AS bsi
+    LEFT JOIN user ON bsi.imported_by_user_id = user.id
+    LEFT JOIN bank_statement_line AS bsl ON bsl.bank_statement_import_id = bsi.id
+GROUP BY bsi.id, user.username
+ORDER BY bsi.id DESC
+LIMIT ? OFFSET ?;
+
+-- name: CountBankStatementImports :one
+SELECT Count(id) AS total FROM bank_statement_import;
+
+-- name: GetBankStatementImportById :one
+SELECT bsi.id, bsi.bank_format, bsi.file_name, bsi.imported_at, bsi.imported_by_user_id, user.username AS imported_by_username,
+    Count(bsl.id) AS total_lines,
+    CAST(COALESCE(SUM(bsl.enrollment_payment_id IS NULL), 0) AS SIGNED) AS total_unmatched_lines
+FROM bank_statement_import AS bsi
+    LEFT JOIN user ON bsi.imported_by_user_id = user.id
+    LEFT JOIN bank_statement_line AS bsl ON bsl.bank_statement_import_id = bsi.id
+WHERE bsi.id = ?
+GROUP BY bsi.id, user.username;
+
+-- name: InsertBankStatementImport :execlastid
+INSERT INTO bank_statement_import (
+    bank_format, file_name, imported_by_user_id
+) VALUES (
+    ?, ?, ?
+);
+
+-- name: GetBankStatementLinesByImportId :many
+SELECT * FROM bank_statement_line
+WHERE bank_statement_import_id = ?
+ORDER BY transaction_date, id;
+
+-- name: GetBankStatementLineById :one
+SELECT * FROM bank_statement_line
+WHERE id = ? LIMIT 1;
+
+-- name: GetBankStatementLineByIdForUpdate :one
+-- GetBankStatementLineByIdForUpdate locks the bank_statement_line until the transaction ends, to prevent matching it twice concurrently.
+SELECT * FROM bank_statement_line
+WHERE id = ? LIMIT 1
+FOR UPDATE;
+
+-- name: InsertBankStatementLine :execlastid
+INSERT INTO bank_statement_line (
+    bank_statement_import_id, transaction_date, description, reference, amount, status, enrollment_payment_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: UpdateBankStatementLineMatch :exec
+UPDATE bank_statement_line SET status = ?, enrollment_payment_id = ?, confirmed_at = ?, confirmed_by_user_id = ?
+WHERE id = ?;
+
+-- name: GetEnrollmentPaymentsForBankStatementMatching :many
+-- GetEnrollmentPaymentsForBankStatementMatching returns the non-cash enrollment_payments which haven't been matched to any bank_statement_line, along with their received money (i.e. after discount).
+SELECT ep.id, ep.payment_date, ep.reference_number,
+    CAST(ep.course_fee_value + ep.transport_fee_value + ep.penalty_fee_value - ep.discount_fee_value AS SIGNED) AS total_value
+FROM enrollment_payment AS ep
+    LEFT JOIN bank_statement_line AS bsl ON bsl.enrollment_payment_id = ep.id
+WHERE ep.payment_date >= sqlc.arg('startDate') AND ep.payment_date <= sqlc.arg('endDate')
+    AND ep.payment_method <> 'CASH' AND bsl.id IS NULL
+ORDER BY ep.payment_date, ep.id;
+
+-- name: GetStudentEnrollmentsForBankStatementSuggestion :many
+-- GetStudentEnrollmentsForBankStatementSuggestion returns the active student_enrollments, along with their latest enrollment_payment's received money (i.e. after discount).
+SELECT se.id AS student_enrollment_id, se.student_id, user.username AS student_username, user.user_detail AS student_detail, se.class_id,
+    latest_ep.payment_date AS last_payment_date,
+    CAST(COALESCE(latest_ep.course_fee_value + latest_ep.transport_fee_value + latest_ep.penalty_fee_value - latest_ep.discount_fee_value, 0) AS SIGNED) AS last_payment_value
+FROM student_enrollment AS se
+    JOIN student ON se.student_id = student.id
+    JOIN user ON student.user_id = user.id
+    JOIN class ON se.class_id = class.id
+    LEFT JOIN enrollment_payment AS latest_ep ON latest_ep.id = (
+        SELECT MAX(id) FROM enrollment_payment WHERE enrollment_id = se.id
+    )
+WHERE se.is_deleted = 0 AND class.is_deactivated = 0
+ORDER BY se.id;
+
+/* ============================== DISCOUNT_RULE ============================== */
+-- name: GetDiscountRuleById :one
+SELECT * FROM discount_rule
+WHERE id = ? LIMIT 1;
+
+-- name: GetDiscountRuleByIdForUpdate :one
+-- GetDiscountRuleByIdForUpdate locks the discount_rule until the transaction ends, to prevent a voucher from being used beyond its usage_limit concurrently.
+SELECT * FROM discount_rule
+WHERE id = ? LIMIT 1
+FOR UPDATE;
+
+-- name: GetDiscountRulesByIds :many
+SELECT * FROM discount_rule
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: GetDiscountRules :many
+SELECT * FROM discount_rule
+ORDER BY id
+LIMIT ? OFFSET ?;
+
+-- name: GetDiscountRuleByVoucherCode :one
+SELECT * FROM discount_rule
+WHERE voucher_code = ? LIMIT 1;
+
+-- name: GetActiveAutomaticDiscountRules :many
+-- GetActiveAutomaticDiscountRules returns the non-voucher discount_rules, which are evaluated on every invoice.
+SELECT * FROM discount_rule
+WHERE type <> 'VOUCHER' AND is_deactivated = 0
+ORDER BY id;
+
+-- name: CountDiscountRulesByIds :one
+SELECT Count(id) AS total FROM discount_rule
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: CountDiscountRules :one
+SELECT Count(id) AS total FROM discount_rule;
+
+-- name: InsertDiscountRule :execlastid
+INSERT INTO discount_rule (
+    name, type, is_percentage, value, min_count, max_day_of_month, voucher_code, usage_limit, valid_from, valid_until, is_deactivated
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: UpdateDiscountRule :exec
+UPDATE discount_rule SET name = ?, type = ?, is_percentage = ?, value = ?, min_count = ?, max_day_of_month = ?, voucher_code = ?, usage_limit = ?, valid_from = ?, valid_until = ?, is_deactivated = ?
+WHERE id = ?;
+
+-- name: DeleteDiscountRulesByIds :exec
+DELETE FROM discount_rule
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: CountDiscountRuleUsages :one
+SELECT Count(id) AS total FROM enrollment_payment_discount
+WHERE discount_rule_id = ?;
+
+-- name: CountEnrollmentPaymentDiscountsByEnrollmentPaymentId :one
+SELECT Count(id) AS total FROM enrollment_payment_discount
+WHERE enrollment_payment_id = ?;
+
+-- name: CountEnrolledSiblingsByStudentId :one
+-- CountEnrolledSiblingsByStudentId counts the students (including the given student) which belong to the same family, and have any active student_enrollment.
+-- Returns 0 when the student doesn't belong to any family.
+SELECT Count(DISTINCT sibling.id) AS total
+FROM family_student AS fs
+    JOIN family_student AS sibling_fs ON sibling_fs.family_id = fs.family_id
+    JOIN student AS sibling ON sibling_fs.student_id = sibling.id
+    JOIN user AS sibling_user ON sibling.user_id = sibling_user.id
+    JOIN student_enrollment AS se ON se.student_id = sibling.id
+    JOIN class ON se.class_id = class.id
+WHERE fs.student_id = ?
+    AND sibling_user.is_deactivated = 0 AND se.is_deleted = 0 AND class.is_deactivated = 0;
+
+-- name: CountActiveStudentEnrollmentsByStudentId :one
+SELECT Count(se.id) AS total
+FROM student_enrollment AS se
+    JOIN class ON se.class_id = class.id
+WHERE se.student_id = ? AND se.is_deleted = 0 AND class.is_deactivated = 0;
+
+-- name: InsertEnrollmentPaymentDiscount :execlastid
+INSERT INTO enrollment_payment_discount (
+    enrollment_payment_id, discount_rule_id, discount_rule_name, value
+) VALUES (
+    ?, ?, ?, ?
+);
+
+/* ============================== PAYMENT_REMINDER ============================== */
+-- name: GetStudentEnrollmentsForPaymentReminder :many
+-- GetStudentEnrollmentsForPaymentReminder returns the active student_enrollments of the students who haven't opted out, along with their total negative quota (i.e. owed tokens).
+SELECT se.id AS student_enrollment_id, COALESCE(SUM(LEAST(slt.quota, 0)), 0) AS owed_quota
+FROM student_enrollment AS se
+    JOIN class ON se.class_id = class.id
+    LEFT JOIN student_learning_token AS slt ON slt.enrollment_id = se.id
+    LEFT JOIN payment_reminder_opt_out AS opt_out ON opt_out.student_id = se.student_id
+WHERE se.is_deleted = 0 AND class.is_deactivated = 0 AND opt_out.student_id IS NULL
+GROUP BY se.id
+ORDER BY se.id;
+
+-- name: GetLatestPaymentReminderSentAtByEnrollmentId :one
+SELECT sent_at FROM payment_reminder
+WHERE enrollment_id = ?
+ORDER BY sent_at DESC
+LIMIT 1;
+
+-- name: GetPaymentReminders :many
+SELECT pr.id, pr.reminder_type, pr.recipient_email, pr.owed_quota, pr.course_fee_value, pr.transport_fee_value, pr.penalty_fee_value, pr.discount_fee_value, pr.sent_at, pr.enrollment_id,
+    se.student_id, user.username AS student_username, user.user_detail AS student_detail
+FROM payment_reminder AS pr
+    JOIN student_enrollment AS se ON pr.enrollment_id = se.id
+    JOIN student ON se.student_id = student.id
+    JOIN user ON student.user_id = user.id
+ORDER BY pr.id DESC
+LIMIT ? OFFSET ?;
+
+-- name: CountPaymentReminders :one
+SELECT Count(id) AS total FROM payment_reminder;
+
+-- name: InsertPaymentReminder :execlastid
+INSERT INTO payment_reminder (
+    reminder_type, recipient_email, owed_quota, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, sent_at, enrollment_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: DeletePaymentReminderById :exec
+DELETE FROM payment_reminder
+WHERE id = ?;
+
+-- name: GetPaymentReminderOptOuts :many
+SELECT opt_out.student_id, user.username AS student_username, user.user_detail AS student_detail, opt_out.opted_out_at, opt_out.opted_out_by_user_id
+FROM payment_reminder_opt_out AS opt_out
+    JOIN student ON opt_out.student_id = student.id
+    JOIN user ON student.user_id = user.id
+ORDER BY opt_out.student_id;
+
+-- name: InsertPaymentReminderOptOut :exec
+-- InsertPaymentReminderOptOut keeps the existing record (and its opted_out_at) when the student has already opted out.
+INSERT INTO payment_reminder_opt_out (
+    student_id, opted_out_by_user_id
+) VALUES (
+    ?, ?
+)
+ON DUPLICATE KEY UPDATE student_id = student_id;
+
+-- name: DeletePaymentReminderOptOutsByStudentIds :exec
+DELETE FROM payment_reminder_opt_out
+WHERE student_id IN (sqlc.slice('ids'));
+
+/* ============================== ENROLLMENT_PAYMENT_REFUND ============================== */
+-- name: GetEnrollmentPaymentRefunds :many
+SELECT epr.id, epr.refund_date, epr.refunded_value, epr.withdrawn_quota, epr.reason, epr.created_at, epr.created_by_user_id, epr.enrollment_payment_id,
+    ep.payment_date, ep.enrollment_id, se.student_id, user.username AS student_username, user.user_detail AS student_detail
+FROM enrollment_payment_refund AS epr
+    JOIN enrollment_payment AS ep ON epr.enrollment_payment_id = ep.id
+    JOIN student_enrollment AS se ON ep.enrollment_id = se.id
+    JOIN student ON se.student_id = student.id
+    JOIN user ON student.user_id = user.id
+ORDER BY epr.refund_date DESC, epr.id DESC
+LIMIT ? OFFSET ?;
+
+-- name: CountEnrollmentPaymentRefunds :one
+SELECT Count(id) AS total FROM enrollment_payment_refund;
+
+-- name: GetEnrollmentPaymentDateByIdForUpdate :one
+-- GetEnrollmentPaymentDateByIdForUpdate locks the enrollment_payment row until the end of the transaction, to serialize the refunds of the enrollment_payment.
+SELECT payment_date FROM enrollment_payment
+WHERE id = ? LIMIT 1
+FOR UPDATE;
+
+-- name: GetEnrollmentPaymentRefundTotalByEnrollmentPaymentId :one
+SELECT Count(id) AS total_refunds, CAST(COALESCE(SUM(refunded_value), 0) AS SIGNED) AS total_refunded_value, CAST(COALESCE(SUM(withdrawn_quota), 0) AS DOUBLE) AS total_withdrawn_quota
+FROM enrollment_payment_refund
+WHERE enrollment_payment_id = ?;
+
+-- name: GetEnrollmentPaymentRefundTotalByEnrollmentPaymentIdForUpdate :one
+SELECT Count(id) AS total_refunds, CAST(COALESCE(SUM(refunded_value), 0) AS SIGNED) AS total_refunded_value, CAST(COALESCE(SUM(withdrawn_quota), 0) AS DOUBLE) AS total_withdrawn_quota
+FROM enrollment_payment_refund
+WHERE enrollment_payment_id = ?
+FOR UPDATE;
+
+-- name: InsertEnrollmentPaymentRefund :execlastid
+INSERT INTO enrollment_payment_refund (
+    refund_date, refunded_value, withdrawn_quota, reason, created_by_user_id, enrollment_payment_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+);
+
+/* ============================== INSTALLMENT_PLAN ============================== */
+-- name: GetInstallmentPlanById :one
+SELECT * FROM installment_plan
+WHERE id = ? LIMIT 1;
+
+-- name: InsertInstallmentPlan :execlastid
+INSERT INTO installment_plan (
+    balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, installment_count, created_by_user_id, enrollment_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: GetInstallmentsByInstallmentPlanId :many
+SELECT installment.id, installment.installment_number, installment.due_date, installment.balance_top_up, installment.balance_bonus, installment.course_fee_value, installment.transport_fee_value, installment.penalty_fee_value, installment.discount_fee_value, installment.installment_plan_id, installment.enrollment_payment_id, ep.payment_date
+FROM installment
+    LEFT JOIN enrollment_payment AS ep ON installment.enrollment_payment_id = ep.id
+WHERE installment.installment_plan_id = ?
+ORDER BY installment.installment_number;
+
+-- name: GetInstallmentById :one
+SELECT installment.id, installment.installment_number, installment.due_date, installment.balance_top_up, installment.balance_bonus, installment.course_fee_value, installment.transport_fee_value, installment.penalty_fee_value, installment.discount_fee_value, installment.installment_plan_id, installment.enrollment_payment_id, installment_plan.enrollment_id
+FROM installment
+    JOIN installment_plan ON installment.installment_plan_id = installment_plan.id
+WHERE installment.id = ? LIMIT 1;
+
+-- name: GetOverdueInstallments :many
+SELECT installment.id, installment.installment_number, installment.due_date, installment.balance_top_up, installment.balance_bonus, installment.course_fee_value, installment.transport_fee_value, installment.penalty_fee_value, installment.discount_fee_value, installment.installment_plan_id, installment.enrollment_payment_id, installment_plan.enrollment_id, se.student_id, user.username AS student_username, user.user_detail AS student_detail
+FROM installment
+    JOIN installment_plan ON installment.installment_plan_id = installment_plan.id
+    JOIN student_enrollment AS se ON installment_plan.enrollment_id = se.id
+    JOIN student ON se.student_id = student.id
+    JOIN user ON student.user_id = user.id
+WHERE installment.enrollment_payment_id IS NULL AND installment.due_date < sqlc.arg('date')
+ORDER BY installment.due_date, installment.id
+LIMIT ? OFFSET ?;
+
+-- name: CountOverdueInstallments :one
+SELECT Count(id) AS total FROM installment
+WHERE enrollment_payment_id IS NULL AND due_date < sqlc.arg('date');
+
+-- name: InsertInstallment :execlastid
+INSERT INTO installment (
+    installment_number, due_date, balance_top_up, balance_bonus, course_fee_value, transport_fee_value, penalty_fee_value, discount_fee_value, installment_plan_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: UpdateUnpaidInstallmentEnrollmentPayment :execrows
+-- UpdateUnpaidInstallmentEnrollmentPayment affects no row when the installment has been paid, which prevents paying an installment twice.
+UPDATE installment SET enrollment_payment_id = ?
+WHERE id = ? AND enrollment_payment_id IS NULL;
@@ -531,6 +531,49 @@ FROM student_enrollment AS se
     LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
 WHERE se.is_deleted = 0 AND class_id = ?;
 
+-- name: GetStudentEnrollmentsActiveAtDateByClassId :many
+-- GetStudentEnrollmentsActiveAtDateByClassId returns the enrollments having a period which covers the date, including the withdrawn (disabled) ones.
+SELECT se.id AS student_enrollment_id,
+    se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
+    sqlc.embed(class), tsf.fee AS teacher_special_fee, sqlc.embed(course), sqlc.embed(instrument), sqlc.embed(grade),
+    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail
+FROM student_enrollment AS se
+    JOIN student ON se.student_id = student.id
+    JOIN user AS user_student ON student.user_id = user_student.id
+    
+    JOIN class on se.class_id = class.id
+    JOIN course ON course_id = course.id
+    JOIN instrument ON course.instrument_id = instrument.id
+    JOIN grade ON course.grade_id = grade.id
+    
+    LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
+    LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
+    LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+WHERE se.class_id = sqlc.arg('class_id') AND EXISTS (
+    SELECT 1 FROM student_enrollment_period AS sep
+    WHERE sep.enrollment_id = se.id AND sep.start_date <= sqlc.arg('date') AND (sep.end_date IS NULL OR sep.end_date >= sqlc.arg('date'))
+);
+
+-- name: GetStudentEnrollmentsIncludingWithdrawnByStudentId :many
+SELECT se.id AS student_enrollment_id,
+    se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
+    sqlc.embed(class), tsf.fee AS teacher_special_fee, sqlc.embed(course), sqlc.embed(instrument), sqlc.embed(grade),
+    class.teacher_id AS class_teacher_id, user_class_teacher.username AS class_teacher_username, user_class_teacher.user_detail AS class_teacher_detail
+FROM student_enrollment AS se
+    JOIN student ON se.student_id = student.id
+    JOIN user AS user_student ON student.user_id = user_student.id
+    
+    JOIN class on se.class_id = class.id
+    JOIN course ON course_id = course.id
+    JOIN instrument ON course.instrument_id = instrument.id
+    JOIN grade ON course.grade_id = grade.id
+    
+    LEFT JOIN teacher AS class_teacher ON class.teacher_id = class_teacher.id
+    LEFT JOIN user AS user_class_teacher ON class_teacher.user_id = user_class_teacher.id
+    LEFT JOIN teacher_special_fee AS tsf ON (class_teacher.id = tsf.teacher_id AND course.id = tsf.course_id)
+WHERE se.student_id = ?
+ORDER BY se.id;
+
 -- name: GetStudentEnrollments :many
 SELECT se.id AS student_enrollment_id,
     se.student_id AS student_id, user_student.username AS student_username, user_student.user_detail AS student_detail,
@@ -552,11 +595,16 @@ WHERE se.is_deleted = 0
 ORDER BY se.id
 LIMIT ? OFFSET ?;
 
+-- name: GetStudentEnrollmentByStudentIdAndClassId :one
+-- GetStudentEnrollmentByStudentIdAndClassId also returns the withdrawn (disabled) enrollment.
+SELECT id, is_deleted FROM student_enrollment
+WHERE student_id = ? AND class_id = ?;
+
 -- name: CountStudentEnrollments :one
 SELECT COUNT(id) FROM student_enrollment
 WHERE is_deleted = 0;
 
--- name: InsertStudentEnrollment :exec
+-- name: InsertStudentEnrollment :execlastid
 INSERT INTO student_enrollment (
     student_id, class_id
 ) VALUES (
@@ -671,3 +719,273 @@ WHERE teacher_id = ?;
 -- name: DeleteTeacherSpecialFeeByCourseId :exec
 DELETE FROM teacher_special_fee
 WHERE course_id = ?;
+
+/* ============================== FEE_HISTORY ============================== */
+-- name: GetCourseFeeHistoriesByCourseId :many
+SELECT * FROM course_fee_history
+WHERE course_id = ?
+ORDER BY effective_from DESC, id DESC;
+
+-- name: GetCourseFeeAtDate :one
+SELECT fee FROM course_fee_history
+WHERE course_id = ? AND effective_from <= ?
+ORDER BY effective_from DESC, id DESC
+LIMIT 1;
+
+-- name: InsertCourseFeeHistory :execlastid
+INSERT INTO course_fee_history (
+    fee, effective_from, course_id
+) VALUES (
+    ?, ?, ?
+);
+
+-- name: SyncCourseDefaultFeesWithFeeHistories :execrows
+-- SyncCourseDefaultFeesWithFeeHistories sets `course`.`default_fee` to the price in force at the given time, i.e. when a scheduled price takes effect.
+UPDATE course
+    JOIN (
+        SELECT course_id, fee, ROW_NUMBER() OVER (PARTITION BY course_id ORDER BY effective_from DESC, id DESC) AS row_num
+        FROM course_fee_history
+        WHERE effective_from <= sqlc.arg('at')
+    ) AS cfh ON (course.id = cfh.course_id AND cfh.row_num = 1)
+SET course.default_fee = cfh.fee
+WHERE course.default_fee <> cfh.fee;
+
+-- name: GetTeacherSpecialFeeHistoriesByCourseId :many
+SELECT * FROM teacher_special_fee_history
+WHERE course_id = ?
+ORDER BY teacher_id, effective_from DESC, id DESC;
+
+-- name: GetTeacherSpecialFeeAtDate :one
+SELECT fee FROM teacher_special_fee_history
+WHERE teacher_id = ? AND course_id = ? AND effective_from <= ?
+ORDER BY effective_from DESC, id DESC
+LIMIT 1;
+
+-- name: InsertTeacherSpecialFeeHistory :execlastid
+INSERT INTO teacher_special_fee_history (
+    fee, effective_from, teacher_id, course_id
+) VALUES (
+    ?, ?, ?, ?
+);
+
+-- name: InsertTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds :exec
+-- InsertTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds records the current fee of the teacher_special_fees into the history.
+INSERT INTO teacher_special_fee_history (fee, effective_from, teacher_id, course_id)
+SELECT fee, ?, teacher_id, course_id FROM teacher_special_fee
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: InsertRemovedTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds :exec
+-- InsertRemovedTeacherSpecialFeeHistoriesByTeacherSpecialFeeIds records the removal (fee = 0) of the teacher_special_fees into the history.
+-- This must be executed before deleting the teacher_special_fees.
+INSERT INTO teacher_special_fee_history (fee, effective_from, teacher_id, course_id)
+SELECT 0, ?, teacher_id, course_id FROM teacher_special_fee
+WHERE id IN (sqlc.slice('ids'));
+
+/* ============================== FAMILY ============================== */
+-- name: GetFamilyById :one
+SELECT * FROM family
+WHERE id = ? LIMIT 1;
+
+-- name: GetFamiliesByIds :many
+SELECT * FROM family
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: GetFamilies :many
+SELECT * FROM family
+ORDER BY id
+LIMIT ? OFFSET ?;
+
+-- name: CountFamiliesByIds :one
+SELECT Count(id) AS total FROM family
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: CountFamilies :one
+SELECT Count(id) AS total FROM family;
+
+-- name: InsertFamily :execlastid
+INSERT INTO family (
+    name
+) VALUES (
+    ?
+);
+
+-- name: UpdateFamily :exec
+UPDATE family SET name = ?
+WHERE id = ?;
+
+-- name: DeleteFamiliesByIds :exec
+DELETE FROM family
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: GetFamilyStudentsByFamilyIds :many
+SELECT fs.family_id, student.id AS student_id, user.username AS student_username, user.user_detail AS student_detail
+FROM family_student AS fs
+    JOIN student ON fs.student_id = student.id
+    JOIN user ON student.user_id = user.id
+WHERE fs.family_id IN (sqlc.slice('ids'))
+ORDER BY fs.family_id, student.id;
+
+-- name: GetFamilyGuardiansByFamilyIds :many
+SELECT fg.family_id, user.id AS user_id, user.username, user.user_detail
+FROM family_guardian AS fg
+    JOIN user ON fg.user_id = user.id
+WHERE fg.family_id IN (sqlc.slice('ids'))
+ORDER BY fg.family_id, user.id;
+
+-- name: InsertFamilyStudent :exec
+INSERT INTO family_student (
+    family_id, student_id
+) VALUES (
+    ?, ?
+);
+
+-- name: DeleteFamilyStudentsByFamilyId :exec
+DELETE FROM family_student
+WHERE family_id = ?;
+
+-- name: InsertFamilyGuardian :exec
+INSERT INTO family_guardian (
+    family_id, user_id
+) VALUES (
+    ?, ?
+);
+
+-- name: DeleteFamilyGuardiansByFamilyId :exec
+DELETE FROM family_guardian
+WHERE family_id = ?;
+
+-- name: GetActiveStudentEnrollmentIdsByFamilyId :many
+-- GetActiveStudentEnrollmentIdsByFamilyId returns the active student_enrollments of all students in the family.
+SELECT se.id
+FROM student_enrollment AS se
+    JOIN family_student AS fs ON se.student_id = fs.student_id
+    JOIN class ON se.class_id = class.id
+WHERE fs.family_id = ? AND se.is_deleted = 0 AND class.is_deactivated = 0
+ORDER BY se.student_id, se.id;
+
+/* ============================== STUDENT_ENROLLMENT_HOLD ============================== */
+-- name: GetStudentEnrollmentHoldById :one
+SELECT seh.id, seh.start_date, seh.end_date, seh.reason, seh.enrollment_id, se.student_id
+FROM student_enrollment_hold AS seh
+    JOIN student_enrollment AS se ON seh.enrollment_id = se.id
+WHERE seh.id = ? LIMIT 1;
+
+-- name: GetStudentEnrollmentHoldsByIds :many
+SELECT seh.id, seh.start_date, seh.end_date, seh.reason, seh.enrollment_id, se.student_id
+FROM student_enrollment_hold AS seh
+    JOIN student_enrollment AS se ON seh.enrollment_id = se.id
+WHERE seh.id IN (sqlc.slice('ids'))
+ORDER BY seh.enrollment_id, seh.start_date;
+
+-- name: GetStudentEnrollmentHoldsByStudentEnrollmentIds :many
+SELECT seh.id, seh.start_date, seh.end_date, seh.reason, seh.enrollment_id, se.student_id
+FROM student_enrollment_hold AS seh
+    JOIN student_enrollment AS se ON seh.enrollment_id = se.id
+WHERE seh.enrollment_id IN (sqlc.slice('enrollmentIds'))
+ORDER BY seh.enrollment_id, seh.start_date;
+
+-- name: GetStudentEnrollmentHoldsByClassId :many
+SELECT seh.id, seh.start_date, seh.end_date, seh.reason, seh.enrollment_id, se.student_id
+FROM student_enrollment_hold AS seh
+    JOIN student_enrollment AS se ON seh.enrollment_id = se.id
+WHERE se.class_id = ? AND se.is_deleted = 0
+ORDER BY seh.enrollment_id, seh.start_date;
+
+-- name: CountStudentEnrollmentHoldsByIds :one
+SELECT Count(id) AS total FROM student_enrollment_hold
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: CountOverlappingStudentEnrollmentHolds :one
+-- CountOverlappingStudentEnrollmentHolds counts the other holds (excluding the given id) of the enrollment which overlap with [start_date, end_date].
+SELECT Count(id) AS total FROM student_enrollment_hold
+WHERE enrollment_id = ? AND id != ? AND start_date <= sqlc.arg('end_date') AND end_date >= sqlc.arg('start_date');
+
+-- name: InsertStudentEnrollmentHold :execlastid
+INSERT INTO student_enrollment_hold (
+    start_date, end_date, reason, enrollment_id
+) VALUES (
+    ?, ?, ?, ?
+);
+
+-- name: UpdateStudentEnrollmentHold :exec
+UPDATE student_enrollment_hold SET start_date = ?, end_date = ?, reason = ?
+WHERE id = ?;
+
+-- name: DeleteStudentEnrollmentHoldsByIds :exec
+DELETE FROM student_enrollment_hold
+WHERE id IN (sqlc.slice('ids'));
+
+/* ============================== STUDENT_ENROLLMENT_PERIOD ============================== */
+-- name: GetStudentEnrollmentPeriodsByStudentEnrollmentIds :many
+SELECT * FROM student_enrollment_period
+WHERE enrollment_id IN (sqlc.slice('enrollmentIds'))
+ORDER BY enrollment_id, start_date;
+
+-- name: GetLatestStudentEnrollmentPeriod :one
+SELECT * FROM student_enrollment_period
+WHERE enrollment_id = ?
+ORDER BY start_date DESC, id DESC
+LIMIT 1;
+
+-- name: InsertStudentEnrollmentPeriod :execlastid
+INSERT INTO student_enrollment_period (
+    start_date, enrollment_id
+) VALUES (
+    ?, ?
+);
+
+-- name: EndStudentEnrollmentPeriod :exec
+UPDATE student_enrollment_period SET end_date = ?, withdrawal_reason = ?
+WHERE id = ?;
+
+-- name: GetAttendanceIdsByStudentEnrollmentIdFromDate :many
+SELECT attendance.id FROM attendance
+    JOIN student_enrollment AS se ON (attendance.student_id = se.student_id AND attendance.class_id = se.class_id)
+WHERE se.id = sqlc.arg('enrollment_id') AND attendance.date >= sqlc.arg('date')
+ORDER BY attendance.date, attendance.id;
+
+-- name: DisableStudentEnrollmentsWithEndedPeriods :execrows
+-- DisableStudentEnrollmentsWithEndedPeriods disables the student_enrollments whose periods have all ended before today, i.e. the withdrawals which have taken effect.
+UPDATE student_enrollment AS se SET se.is_deleted = 1
+WHERE se.is_deleted = 0
+    AND EXISTS (SELECT 1 FROM student_enrollment_period AS sep WHERE sep.enrollment_id = se.id)
+    AND NOT EXISTS (
+        SELECT 1 FROM student_enrollment_period AS sep
+        WHERE sep.enrollment_id = se.id AND (sep.end_date IS NULL OR sep.end_date >= sqlc.arg('today'))
+    );
+
+/* ============================== ATTENDANCE_TOKEN_ASSIGNMENT ============================== */
+-- name: GetAttendancesWithoutTokenByClassId :many
+SELECT attendance.id, attendance.date, attendance.student_id, attendance.is_paid, se.id AS student_enrollment_id
+FROM attendance
+    LEFT JOIN student_enrollment AS se ON (attendance.student_id = se.student_id AND attendance.class_id = se.class_id)
+WHERE
+    attendance.token_id IS NULL
+    AND attendance.class_id = sqlc.arg('class_id')
+    AND attendance.student_id = COALESCE(sqlc.narg('student_id'), attendance.student_id)
+    AND (attendance.date >= sqlc.arg('startDate') AND attendance.date <= sqlc.arg('endDate'))
+ORDER BY attendance.date, attendance.id;
+
+/* ============================== TEACHER_SUBSTITUTION ============================== */
+-- name: GetTeacherSubstitutionSummaries :many
+-- GetTeacherSubstitutionSummaries returns, for each teacher, the substitutions given (teaching on behalf of the original teacher) & received (being substituted by another teacher).
+WITH substitution AS (
+    SELECT teacher_id, original_teacher_id, used_student_token_quota
+    FROM attendance
+    WHERE
+        is_substitute = 1
+        AND (attendance.date >= sqlc.arg('startDate') AND attendance.date <= sqlc.arg('endDate'))
+)
+SELECT teacher.id AS teacher_id, user.username AS teacher_username, user.user_detail AS teacher_detail,
+    COALESCE(given.total, 0) AS total_given, ROUND(COALESCE(given.total_quota, 0), 3) AS total_given_quota,
+    COALESCE(received.total, 0) AS total_received, ROUND(COALESCE(received.total_quota, 0), 3) AS total_received_quota
+FROM teacher
+    JOIN user ON teacher.user_id = user.id
+    LEFT JOIN (
+        SELECT teacher_id, Count(*) AS total, sum(used_student_token_quota) AS total_quota FROM substitution GROUP BY teacher_id
+    ) AS given ON teacher.id = given.teacher_id
+    LEFT JOIN (
+        SELECT original_teacher_id, Count(*) AS total, sum(used_student_token_quota) AS total_quota FROM substitution GROUP BY original_teacher_id
+    ) AS received ON teacher.id = received.original_teacher_id
+WHERE given.teacher_id IS NOT NULL OR received.original_teacher_id IS NOT NULL
+ORDER BY user.username, teacher.id;
@@ -16,10 +16,67 @@ var (
 
 	// Identity
 	ErrUserDeactivated = errors.New("user is deactivated")
+	ErrUserEmailNotSet = errors.New("user doesn't have an email")
 
 	// Teaching
 	ErrClassHaveNoStudent      = errors.New("class doesn't have any student")
 	ErrModifyingPaidAttendance = errors.New("attendance is already paid and cannot be updated/deleted")
+
+	// Teaching - StudentLearningToken transfer
+	ErrInsufficientSLTQuota        = errors.New("studentLearningToken doesn't have enough quota")
+	ErrSLTTransferToSameEnrollment = errors.New("studentLearningToken cannot be transferred to its own enrollment")
+
+	// Teaching - Class level-up
+	ErrClassAlreadyInCourse         = errors.New("class is already in the course")
+	ErrLevelUpEffectiveDateInFuture = errors.New("level-up effective date must not be in the future")
+
+	// Teaching - PayrollRun
+	ErrPayrollRunNotDraft                = errors.New("payrollRun is not a draft anymore")
+	ErrAttendanceOutsidePayrollRunPeriod = errors.New("attendance is dated outside of the payrollRun period")
+	ErrInvalidPayrollRunStatusTransition = errors.New("payrollRun status cannot be changed into the requested status")
+	ErrTeacherPaymentLocked              = errors.New("teacherPayment belongs to an approved payrollRun and cannot be updated/deleted")
+
+	// Closed accounting period
+	ErrDateInClosedPeriod = errors.New("record is dated within a closed accounting period")
+
+	// Cash-up
+	ErrCashUpDayClosed = errors.New("enrollmentPayment is dated on a closed cash-up day")
+
+	// Refund
+	ErrEnrollmentPaymentRefunded      = errors.New("enrollmentPayment has been refunded and cannot be removed")
+	ErrRefundExceedsEnrollmentPayment = errors.New("total refunds exceed the enrollmentPayment's received value or topped-up quota")
+	ErrRefundBeforeEnrollmentPayment  = errors.New("refundDate is before the enrollmentPayment's paymentDate")
+
+	// Installment
+	ErrInstallmentCountExceedsQuota = errors.New("installmentPlan has more installments than the invoice's balanceTopUp")
+	ErrInstallmentAlreadyPaid       = errors.New("installment has already been paid")
+	ErrInstallmentFeeNotSplittable  = errors.New("installmentPlan's fee cannot be split into the installments without changing the fee per quota")
+
+	// StudentEnrollment lifecycle
+	ErrStudentEnrollmentAlreadyActive  = errors.New("student is already actively enrolled in the class")
+	ErrStudentEnrollmentNotActive      = errors.New("studentEnrollment has been withdrawn")
+	ErrStudentEnrollmentPeriodOverlaps = errors.New("date overlaps with another period of the same studentEnrollment")
+	ErrAttendanceAfterWithdrawal       = errors.New("studentEnrollment has attendances after the withdrawal's end date")
+
+	// StudentEnrollmentHold
+	ErrStudentEnrollmentHoldOverlaps = errors.New("studentEnrollmentHold overlaps with another hold of the same studentEnrollment")
+	ErrAllStudentsOnHold             = errors.New("all students of the class are on hold")
+
+	// Family
+	ErrStudentEnrollmentNotInFamily = errors.New("studentEnrollment doesn't belong to any student of the family")
+
+	// Discount
+	ErrVoucherNotFound            = errors.New("voucher code doesn't exist")
+	ErrDiscountRuleNotApplicable  = errors.New("discountRule is not applicable to the enrollmentPayment")
+	ErrDiscountExceedsDiscountFee = errors.New("applied discounts exceed the enrollmentPayment's discountFeeValue")
+	ErrDiscountFeeLocked          = errors.New("enrollmentPayment has applied discountRules, thus its discountFeeValue cannot be edited")
+
+	// Bank statement
+	ErrUnsupportedBankFormat           = errors.New("bank statement format is not supported")
+	ErrInvalidBankStatementFile        = errors.New("bank statement file cannot be parsed")
+	ErrBankStatementLineAlreadyMatched = errors.New("bankStatementLine has already been matched to an enrollmentPayment")
+	ErrBankStatementLineNotMatched     = errors.New("bankStatementLine has not been matched to any enrollmentPayment")
+	ErrBankStatementAmountMismatch     = errors.New("enrollmentPayment's received value differs from the bankStatementLine's amount")
 )
 
 type Validatable interface {
@@ -34,7 +34,7 @@ func main() {
 		cors.Handler(cors.Options{
 			AllowedOrigins:   []string{"*"},
 			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Api-Version"},
+			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Api-Version", "X-Closed-Period-Override-Reason"},
 			ExposedHeaders:   []string{"Link"},
 			AllowCredentials: true,
 			MaxAge:           300, // Maximum value not ignored by any of major browsers
@@ -66,6 +66,8 @@ func main() {
 		authRouter.Use(backendService.UserActionLogMiddleware)
 
 		authRouter.Get("/user-action-logs/fetch", jsonSerdeWrapper.WrapFunc(backendService.FetchUserActionLogs))
+
+		authRouter.Post("/studentLearningTokens/reconcile", jsonSerdeWrapper.WrapFunc(backendService.ReconcileSLTQuotasHandler))
 	})
 
 	// Router group for admin-only endpoints
@@ -111,6 +113,8 @@ func main() {
 		authRouter.Post("/courses", jsonSerdeWrapper.WrapFunc(backendService.InsertCoursesHandler))
 		authRouter.Put("/courses", jsonSerdeWrapper.WrapFunc(backendService.UpdateCoursesHandler))
 		authRouter.Delete("/courses", jsonSerdeWrapper.WrapFunc(backendService.DeleteCoursesHandler))
+		authRouter.Get("/courses/{CourseID}/feeHistories", jsonSerdeWrapper.WrapFunc(backendService.GetCourseFeeHistoriesHandler, "CourseID"))
+		authRouter.Post("/courses/{CourseID}/feeHistories", jsonSerdeWrapper.WrapFunc(backendService.InsertCourseFeeHistoriesHandler, "CourseID"))
 
 		authRouter.Get("/classes", jsonSerdeWrapper.WrapFunc(backendService.GetClassesHandler))
 		authRouter.Get("/classes/{ClassID}", jsonSerdeWrapper.WrapFunc(backendService.GetClassByIdHandler, "ClassID"))
@@ -119,6 +123,21 @@ func main() {
 		authRouter.Delete("/classes", jsonSerdeWrapper.WrapFunc(backendService.DeleteClassesHandler))
 
 		authRouter.Get("/studentEnrollments", jsonSerdeWrapper.WrapFunc(backendService.GetStudentEnrollmentsHandler))
+		authRouter.Post("/studentEnrollments/enroll", jsonSerdeWrapper.WrapFunc(backendService.EnrollStudentHandler))
+		authRouter.Post("/studentEnrollments/withdraw", jsonSerdeWrapper.WrapFunc(backendService.WithdrawStudentEnrollmentHandler))
+		authRouter.Post("/studentEnrollments/reEnroll", jsonSerdeWrapper.WrapFunc(backendService.ReEnrollStudentEnrollmentHandler))
+		authRouter.Get("/students/{StudentID}/enrollmentHistories", jsonSerdeWrapper.WrapFunc(backendService.GetStudentEnrollmentHistoriesHandler, "StudentID"))
+
+		authRouter.Get("/studentEnrollments/{StudentEnrollmentID}/holds", jsonSerdeWrapper.WrapFunc(backendService.GetStudentEnrollmentHoldsHandler, "StudentEnrollmentID"))
+		authRouter.Post("/studentEnrollmentHolds", jsonSerdeWrapper.WrapFunc(backendService.InsertStudentEnrollmentHoldsHandler))
+		authRouter.Put("/studentEnrollmentHolds", jsonSerdeWrapper.WrapFunc(backendService.UpdateStudentEnrollmentHoldsHandler))
+		authRouter.Delete("/studentEnrollmentHolds", jsonSerdeWrapper.WrapFunc(backendService.DeleteStudentEnrollmentHoldsHandler))
+
+		authRouter.Get("/families", jsonSerdeWrapper.WrapFunc(backendService.GetFamiliesHandler))
+		authRouter.Get("/families/{FamilyID}", jsonSerdeWrapper.WrapFunc(backendService.GetFamilyByIdHandler, "FamilyID"))
+		authRouter.Post("/families", jsonSerdeWrapper.WrapFunc(backendService.InsertFamiliesHandler))
+		authRouter.Put("/families", jsonSerdeWrapper.WrapFunc(backendService.UpdateFamiliesHandler))
+		authRouter.Delete("/families", jsonSerdeWrapper.WrapFunc(backendService.DeleteFamiliesHandler))
 
 		authRouter.Get("/teacherSpecialFees", jsonSerdeWrapper.WrapFunc(backendService.GetTeacherSpecialFeesHandler))
 		authRouter.Get("/teacherSpecialFees/{TeacherSpecialFeeID}", jsonSerdeWrapper.WrapFunc(backendService.GetTeacherSpecialFeeByIdHandler, "TeacherSpecialFeeID"))
@@ -126,17 +145,43 @@ func main() {
 		authRouter.Put("/teacherSpecialFees", jsonSerdeWrapper.WrapFunc(backendService.UpdateTeacherSpecialFeesHandler))
 		authRouter.Delete("/teacherSpecialFees", jsonSerdeWrapper.WrapFunc(backendService.DeleteTeacherSpecialFeesHandler))
 
+		authRouter.Get("/penaltyPolicies", jsonSerdeWrapper.WrapFunc(backendService.GetPenaltyPoliciesHandler))
+		authRouter.Get("/penaltyPolicies/{PenaltyPolicyID}", jsonSerdeWrapper.WrapFunc(backendService.GetPenaltyPolicyByIdHandler, "PenaltyPolicyID"))
+		authRouter.Post("/penaltyPolicies", jsonSerdeWrapper.WrapFunc(backendService.InsertPenaltyPoliciesHandler))
+		authRouter.Put("/penaltyPolicies", jsonSerdeWrapper.WrapFunc(backendService.UpdatePenaltyPoliciesHandler))
+		authRouter.Delete("/penaltyPolicies", jsonSerdeWrapper.WrapFunc(backendService.DeletePenaltyPoliciesHandler))
+
+		authRouter.Get("/discountRules", jsonSerdeWrapper.WrapFunc(backendService.GetDiscountRulesHandler))
+		authRouter.Get("/discountRules/{DiscountRuleID}", jsonSerdeWrapper.WrapFunc(backendService.GetDiscountRuleByIdHandler, "DiscountRuleID"))
+		authRouter.Post("/discountRules", jsonSerdeWrapper.WrapFunc(backendService.InsertDiscountRulesHandler))
+		authRouter.Put("/discountRules", jsonSerdeWrapper.WrapFunc(backendService.UpdateDiscountRulesHandler))
+		authRouter.Delete("/discountRules", jsonSerdeWrapper.WrapFunc(backendService.DeleteDiscountRulesHandler))
+
+		authRouter.Get("/teacherFeeSharings", jsonSerdeWrapper.WrapFunc(backendService.GetTeacherFeeSharingsHandler))
+		authRouter.Get("/teacherFeeSharings/{TeacherFeeSharingID}", jsonSerdeWrapper.WrapFunc(backendService.GetTeacherFeeSharingByIdHandler, "TeacherFeeSharingID"))
+		authRouter.Post("/teacherFeeSharings", jsonSerdeWrapper.WrapFunc(backendService.InsertTeacherFeeSharingsHandler))
+		authRouter.Put("/teacherFeeSharings", jsonSerdeWrapper.WrapFunc(backendService.UpdateTeacherFeeSharingsHandler))
+		authRouter.Delete("/teacherFeeSharings", jsonSerdeWrapper.WrapFunc(backendService.DeleteTeacherFeeSharingsHandler))
+
 		authRouter.Get("/enrollmentPayments", jsonSerdeWrapper.WrapFunc(backendService.GetEnrollmentPaymentsHandler))
 		authRouter.Get("/enrollmentPayments/{EnrollmentPaymentID}", jsonSerdeWrapper.WrapFunc(backendService.GetEnrollmentPaymentByIdHandler, "EnrollmentPaymentID"))
 		authRouter.Post("/enrollmentPayments", jsonSerdeWrapper.WrapFunc(backendService.InsertEnrollmentPaymentsHandler))
 		authRouter.Put("/enrollmentPayments", jsonSerdeWrapper.WrapFunc(backendService.UpdateEnrollmentPaymentsHandler))
 		authRouter.Delete("/enrollmentPayments", jsonSerdeWrapper.WrapFunc(backendService.DeleteEnrollmentPaymentsHandler))
+		authRouter.Post("/enrollmentPayments/cashUp/reopen", jsonSerdeWrapper.WrapFunc(backendService.ReopenCashUpDayHandler))
+
+		authRouter.Post("/paymentReminders/send", jsonSerdeWrapper.WrapFunc(backendService.SendPaymentRemindersHandler))
+		authRouter.Get("/paymentReminders", jsonSerdeWrapper.WrapFunc(backendService.GetPaymentRemindersHandler))
+		authRouter.Get("/paymentReminders/optOuts", jsonSerdeWrapper.WrapFunc(backendService.GetPaymentReminderOptOutsHandler))
+		authRouter.Post("/paymentReminders/optOuts", jsonSerdeWrapper.WrapFunc(backendService.SetPaymentReminderOptOutHandler))
 
 		authRouter.Get("/studentLearningTokens", jsonSerdeWrapper.WrapFunc(backendService.GetStudentLearningTokensHandler))
 		authRouter.Get("/studentLearningTokens/{StudentLearningTokenID}", jsonSerdeWrapper.WrapFunc(backendService.GetStudentLearningTokenByIdHandler, "StudentLearningTokenID"))
 		authRouter.Post("/studentLearningTokens", jsonSerdeWrapper.WrapFunc(backendService.InsertStudentLearningTokensHandler))
 		authRouter.Put("/studentLearningTokens", jsonSerdeWrapper.WrapFunc(backendService.UpdateStudentLearningTokensHandler))
 		authRouter.Delete("/studentLearningTokens", jsonSerdeWrapper.WrapFunc(backendService.DeleteStudentLearningTokensHandler))
+		authRouter.Post("/studentLearningTokens/reprice", jsonSerdeWrapper.WrapFunc(backendService.RepriceStudentLearningTokensHandler))
+		authRouter.Post("/classes/levelUp", jsonSerdeWrapper.WrapFunc(backendService.LevelUpClassHandler))
 
 		authRouter.Get("/attendances", jsonSerdeWrapper.WrapFunc(backendService.GetAttendancesHandler))
 		authRouter.Get("/attendances/{AttendanceID}", jsonSerdeWrapper.WrapFunc(backendService.GetAttendanceByIdHandler, "AttendanceID"))
@@ -145,6 +190,24 @@ func main() {
 		authRouter.Delete("/attendances", jsonSerdeWrapper.WrapFunc(backendService.DeleteAttendancesHandler))
 
 		authRouter.Get("/teacherPayments", jsonSerdeWrapper.WrapFunc(backendService.GetTeacherPaymentsHandler))
+
+		// records dated within a closed period can only be changed by a super admin, by providing the "X-Closed-Period-Override-Reason" header
+		authRouter.Get("/closedPeriods", jsonSerdeWrapper.WrapFunc(backendService.GetClosedPeriodsHandler))
+		authRouter.Get("/closedPeriods/overrides", jsonSerdeWrapper.WrapFunc(backendService.GetClosedPeriodOverridesHandler))
+		authRouter.Get("/closedPeriods/{ClosedPeriodID}", jsonSerdeWrapper.WrapFunc(backendService.GetClosedPeriodByIdHandler, "ClosedPeriodID"))
+		authRouter.Post("/closedPeriods", jsonSerdeWrapper.WrapFunc(backendService.InsertClosedPeriodsHandler))
+		authRouter.Delete("/closedPeriods", jsonSerdeWrapper.WrapFunc(backendService.DeleteClosedPeriodsHandler))
+
+		authRouter.Post("/payrollRuns/{PayrollRunID}/approve", jsonSerdeWrapper.WrapFunc(backendService.ApprovePayrollRunHandler, "PayrollRunID"))
+		authRouter.Post("/payrollRuns/{PayrollRunID}/markAsPaid", jsonSerdeWrapper.WrapFunc(backendService.MarkPayrollRunAsPaidHandler, "PayrollRunID"))
+
+		// bank statement lines are auto-matched to enrollmentPayments on import, and must be confirmed (or matched manually) afterwards
+		authRouter.Get("/bankStatements", jsonSerdeWrapper.WrapFunc(backendService.GetBankStatementImportsHandler))
+		authRouter.Get("/bankStatements/{BankStatementImportID}", jsonSerdeWrapper.WrapFunc(backendService.GetBankStatementImportByIdHandler, "BankStatementImportID"))
+		authRouter.Post("/bankStatements/import", jsonSerdeWrapper.WrapFunc(backendService.ImportBankStatementHandler))
+		authRouter.Post("/bankStatements/lines/{BankStatementLineID}/confirm", jsonSerdeWrapper.WrapFunc(backendService.ConfirmBankStatementLineMatchHandler, "BankStatementLineID"))
+		authRouter.Post("/bankStatements/lines/{BankStatementLineID}/unmatch", jsonSerdeWrapper.WrapFunc(backendService.UnmatchBankStatementLineHandler, "BankStatementLineID"))
+		authRouter.Post("/bankStatements/lines/{BankStatementLineID}/createPayment", jsonSerdeWrapper.WrapFunc(backendService.CreateEnrollmentPaymentFromBankStatementLineHandler, "BankStatementLineID"))
 	})
 
 	// Router group for staff-only (and above) endpoints
@@ -161,6 +224,9 @@ func main() {
 			loggedRouter.Get("/courses", jsonSerdeWrapper.WrapFunc(backendService.GetCoursesHandler))
 			loggedRouter.Get("/classes", jsonSerdeWrapper.WrapFunc(backendService.GetClassesHandler))
 			loggedRouter.Get("/studentEnrollments", jsonSerdeWrapper.WrapFunc(backendService.GetStudentEnrollmentsHandler))
+			loggedRouter.Get("/students/{StudentID}/enrollmentHistories", jsonSerdeWrapper.WrapFunc(backendService.GetStudentEnrollmentHistoriesHandler, "StudentID"))
+			loggedRouter.Get("/studentEnrollments/{StudentEnrollmentID}/holds", jsonSerdeWrapper.WrapFunc(backendService.GetStudentEnrollmentHoldsHandler, "StudentEnrollmentID"))
+			loggedRouter.Get("/families", jsonSerdeWrapper.WrapFunc(backendService.GetFamiliesHandler))
 			loggedRouter.Get("/attendances", jsonSerdeWrapper.WrapFunc(backendService.GetAttendancesHandler))
 
 			loggedRouter.Post("/classes/edit/config", jsonSerdeWrapper.WrapFunc(backendService.EditClassesConfigsHandler))
@@ -169,15 +235,29 @@ func main() {
 			loggedRouter.Get("/enrollmentPayments/search", jsonSerdeWrapper.WrapFunc(backendService.SearchEnrollmentPaymentHandler))
 			loggedRouter.Get("/enrollmentPayments/invoice/studentEnrollment/{StudentEnrollmentID}", jsonSerdeWrapper.WrapFunc(backendService.GetEnrollmentPaymentInvoiceHandler, "StudentEnrollmentID"))
 			loggedRouter.Post("/enrollmentPayments/submit", jsonSerdeWrapper.WrapFunc(backendService.SubmitEnrollmentPaymentHandler))
+			loggedRouter.Get("/enrollmentPayments/invoice/family/{FamilyID}", jsonSerdeWrapper.WrapFunc(backendService.GetFamilyInvoiceHandler, "FamilyID"))
+			loggedRouter.Post("/enrollmentPayments/submit/family", jsonSerdeWrapper.WrapFunc(backendService.SubmitFamilyPaymentHandler))
 			loggedRouter.Post("/enrollmentPayments/edit", jsonSerdeWrapper.WrapFunc(backendService.EditEnrollmentPaymentHandler))
 			loggedRouter.Post("/enrollmentPayments/remove", jsonSerdeWrapper.WrapFunc(backendService.RemoveEnrollmentPaymentHandler))
+			loggedRouter.Post("/enrollmentPayments/refund", jsonSerdeWrapper.WrapFunc(backendService.RefundEnrollmentPaymentHandler))
+			loggedRouter.Get("/enrollmentPayments/refunds", jsonSerdeWrapper.WrapFunc(backendService.GetEnrollmentPaymentRefundsHandler))
+			loggedRouter.Post("/installmentPlans/create", jsonSerdeWrapper.WrapFunc(backendService.CreateInstallmentPlanHandler))
+			loggedRouter.Get("/installmentPlans/installments/overdue", jsonSerdeWrapper.WrapFunc(backendService.GetOverdueInstallmentsHandler))
+			loggedRouter.Post("/installmentPlans/installments/pay", jsonSerdeWrapper.WrapFunc(backendService.PayInstallmentHandler))
+			loggedRouter.Get("/installmentPlans/{InstallmentPlanID}", jsonSerdeWrapper.WrapFunc(backendService.GetInstallmentPlanHandler, "InstallmentPlanID"))
+			loggedRouter.Get("/enrollmentPayments/{EnrollmentPaymentID}/receipt.pdf", jsonSerdeWrapper.WrapFunc(backendService.GetEnrollmentPaymentReceiptHandler, "EnrollmentPaymentID"))
+			loggedRouter.Get("/enrollmentPayments/cashUp", jsonSerdeWrapper.WrapFunc(backendService.GetCashUpReportHandler))
+			loggedRouter.Post("/enrollmentPayments/cashUp/close", jsonSerdeWrapper.WrapFunc(backendService.CloseCashUpDayHandler))
 
 			loggedRouter.Get("/teacherPayments/unpaidTeachers", jsonSerdeWrapper.WrapFunc(backendService.GetUnpaidTeachersHandler))
 			loggedRouter.Get("/teacherPayments/paidTeachers", jsonSerdeWrapper.WrapFunc(backendService.GetPaidTeachersHandler))
+			loggedRouter.Get("/teacherPayments/substitutions", jsonSerdeWrapper.WrapFunc(backendService.GetTeacherSubstitutionSummariesHandler))
 			// TODO: improve naming for these 2 similar endpoints, as both return TeacherPaymentInvoiceItem.
 			// But, for getting existing TeacherPayment as TeacherPaymentInvoiceItem, the URL doesn't seem to represent it.
 			loggedRouter.Get("/teacherPayments/invoiceItems/teacher/{TeacherID}", jsonSerdeWrapper.WrapFunc(backendService.GetTeacherPaymentInvoiceItemsHandler, "TeacherID"))
 			loggedRouter.Get("/teacherPayments/teacher/{TeacherID}", jsonSerdeWrapper.WrapFunc(backendService.GetTeacherPaymentsAsInvoiceItemsHandler, "TeacherID"))
+			loggedRouter.Get("/teacherPayments/teacher/{TeacherID}/payslip", jsonSerdeWrapper.WrapFunc(backendService.GetTeacherPayslipHandler, "TeacherID"))
+			loggedRouter.Post("/teacherPayments/teacher/{TeacherID}/payslip/email", jsonSerdeWrapper.WrapFunc(backendService.EmailTeacherPayslipHandler, "TeacherID"))
 
 			loggedRouter.Post("/teacherPayments/submit", jsonSerdeWrapper.WrapFunc(backendService.SubmitTeacherPaymentsHandler))
 			loggedRouter.Post("/teacherPayments/modify", jsonSerdeWrapper.WrapFunc(backendService.ModifyTeacherPaymentsHandler))
@@ -185,6 +265,15 @@ func main() {
 			loggedRouter.Post("/teacherPayments/edit", jsonSerdeWrapper.WrapFunc(backendService.EditTeacherPaymentsHandler))
 			loggedRouter.Post("/teacherPayments/remove", jsonSerdeWrapper.WrapFunc(backendService.RemoveTeacherPaymentsHandler))
 
+			loggedRouter.Get("/payrollRuns", jsonSerdeWrapper.WrapFunc(backendService.GetPayrollRunsHandler))
+			loggedRouter.Get("/payrollRuns/{PayrollRunID}", jsonSerdeWrapper.WrapFunc(backendService.GetPayrollRunByIdHandler, "PayrollRunID"))
+			loggedRouter.Get("/payrollRuns/{PayrollRunID}/teacherTotals", jsonSerdeWrapper.WrapFunc(backendService.GetPayrollRunTeacherTotalsHandler, "PayrollRunID"))
+			loggedRouter.Post("/payrollRuns", jsonSerdeWrapper.WrapFunc(backendService.CreatePayrollRunHandler))
+
+			loggedRouter.Get("/studentLearningTokens/{StudentLearningTokenID}/history", jsonSerdeWrapper.WrapFunc(backendService.GetStudentLearningTokenHistoryHandler, "StudentLearningTokenID"))
+			loggedRouter.Post("/studentLearningTokens/{StudentLearningTokenID}/transfer", jsonSerdeWrapper.WrapFunc(backendService.TransferStudentLearningTokensHandler, "StudentLearningTokenID"))
+
+			loggedRouter.Post("/attendances/assignTokens", jsonSerdeWrapper.WrapFunc(backendService.AssignAttendancesTokensHandler))
 			loggedRouter.Post("/attendances/{AttendanceID}/assignToken", jsonSerdeWrapper.WrapFunc(backendService.AssignAttendanceTokenHandler, "AttendanceID"))
 			// This endpoint is more similar with "/classes/{ClassID}/attendances/add", where (1) SLTs are automatically updated, (2) class with n students will get n attendances.
 			// The goal is to simplify admin day-to-day work. Inputting in batch is simpler than navigating between pages and inserting the attendances one-by-one.
@@ -195,6 +284,9 @@ func main() {
 		authRouter.Post("/dashboard/expense/monthlySummary", jsonSerdeWrapper.WrapFunc(backendService.GetDashboardExpenseMonthlySummary))
 		authRouter.Post("/dashboard/income/overview", jsonSerdeWrapper.WrapFunc(backendService.GetDashboardIncomeOverview))
 		authRouter.Post("/dashboard/income/monthlySummary", jsonSerdeWrapper.WrapFunc(backendService.GetDashboardIncomeMonthlySummary))
+		authRouter.Post("/dashboard/discount/monthlySummary", jsonSerdeWrapper.WrapFunc(backendService.GetDashboardDiscountMonthlySummary))
+		authRouter.Post("/dashboard/receivable", jsonSerdeWrapper.WrapFunc(backendService.GetDashboardReceivableReport))
+		authRouter.Post("/dashboard/receivable/export", jsonSerdeWrapper.WrapFunc(backendService.ExportDashboardReceivableReport))
 		// TODO: properly implement this, as we're reusing admin endpoint?
 		authRouter.Get("/teachersForDashboard", jsonSerdeWrapper.WrapFunc(backendService.GetTeachersHandler))
 		authRouter.Get("/instrumentsForDashboard", jsonSerdeWrapper.WrapFunc(backendService.GetInstrumentsHandler))
@@ -267,6 +359,12 @@ func main() {
 		serverStopCtx()
 	}()
 
+	// the reconciliation job only reports the drifted SLTs, repairing must be triggered manually via "/maintenance/studentLearningTokens/reconcile"
+	go backendService.RunSLTReconciliationJob(serverCtx, configObject.SLTReconciliationInterval)
+	go backendService.RunPaymentReminderJob(serverCtx, configObject.PaymentReminderInterval)
+	go backendService.RunCourseFeeSyncJob(serverCtx, configObject.CourseFeeSyncInterval)
+	go backendService.RunStudentEnrollmentSyncJob(serverCtx, configObject.StudentEnrollmentSyncInterval)
+
 	logging.AppLogger.Info("Server is starting...")
 	logging.AppLogger.Info("Serving on %s", serverAddr)
 	err := server.ListenAndServe()
@@ -16,6 +16,9 @@ type RequestContext struct {
 	Origin    string
 	IPAddress string
 	RequestID string
+
+	// ClosedPeriodOverrideReason is only honored for super admins, see EntityService.EnsureDatesNotInClosedPeriod()
+	ClosedPeriodOverrideReason string
 }
 
 func NewContextWithRequestContext(ctx context.Context, reqCtx RequestContext) context.Context {
@@ -28,6 +31,8 @@ func CreateRequestContext(request *http.Request) RequestContext {
 		Origin:    GetOrigin(request),
 		IPAddress: GetIPAddress(request),
 		RequestID: GetRequestID(request),
+
+		ClosedPeriodOverrideReason: GetClosedPeriodOverrideReason(request),
 	}
 
 	return requestContext
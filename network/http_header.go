@@ -35,6 +35,9 @@ var (
 // 	Placeholder bool
 // }
 
+// xClosedPeriodOverrideReason lets a super admin write records dated within a closed accounting period. The reason is recorded for auditing.
+var xClosedPeriodOverrideReason = http.CanonicalHeaderKey("X-Closed-Period-Override-Reason")
+
 func GetOrigin(r *http.Request) string {
 	return r.Header.Get(origin)
 }
@@ -99,3 +102,7 @@ func GetIPAddress(r *http.Request) string {
 
 // 	return expFeatsOption
 // }
+
+func GetClosedPeriodOverrideReason(r *http.Request) string {
+	return strings.TrimSpace(r.Header.Get(xClosedPeriodOverrideReason))
+}
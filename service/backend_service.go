@@ -7,11 +7,13 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"database/sql"
 
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/matcornic/hermes/v2"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
@@ -25,6 +27,9 @@ import (
 	entityImpl "sonamusica-backend/app-service/entity/impl"
 	"sonamusica-backend/app-service/identity"
 	identityImpl "sonamusica-backend/app-service/identity/impl"
+	"sonamusica-backend/app-service/payment"
+	paymentImpl "sonamusica-backend/app-service/payment/impl"
+	"sonamusica-backend/app-service/pdf_composer"
 	"sonamusica-backend/app-service/teaching"
 	teachingImpl "sonamusica-backend/app-service/teaching/impl"
 	"sonamusica-backend/app-service/user_action_log"
@@ -43,12 +48,14 @@ var (
 )
 
 type BackendService struct {
-	jwtService auth.JWTService
+	jwtService    auth.JWTService
+	emailComposer *hermes.Hermes
 
 	identityService  identity.IdentityService
 	entityService    entity.EntityService
 	teachingService  teaching.TeachingService
 	dashboardService dashboard.DashboardService
+	paymentService   payment.PaymentService
 
 	userActionLogService user_action_log.UserActionLogService
 }
@@ -79,18 +86,22 @@ func NewBackendService() *BackendService {
 
 	entityService := entityImpl.NewEntityServiceImpl(mySqlQueries, identityService)
 
-	teachingService := teachingImpl.NewTeachingServiceImpl(mySqlQueries, entityService)
+	userActionLogService := userActionLogImpl.NewUserActionLogImpl(mySqlQueries)
+
+	teachingService := teachingImpl.NewTeachingServiceImpl(mySqlQueries, smtpAccessor, emailComposer, entityService, userActionLogService)
 
 	dashhboardService := dashboardImpl.NewDashboardServiceImpl(mySqlQueries, entityService)
 
-	userActionLogService := userActionLogImpl.NewUserActionLogImpl(mySqlQueries)
+	paymentService := paymentImpl.NewPaymentServiceImpl(mySqlQueries, entityService, teachingService)
 
 	return &BackendService{
 		jwtService:           jwtService,
+		emailComposer:        emailComposer,
 		identityService:      identityService,
 		entityService:        entityService,
 		teachingService:      teachingService,
 		dashboardService:     dashhboardService,
+		paymentService:       paymentService,
 		userActionLogService: userActionLogService,
 	}
 }
@@ -196,6 +207,29 @@ func (s *BackendService) FetchUserActionLogs(ctx context.Context, req *output.Fe
 	}, nil
 }
 
+func (s *BackendService) ReconcileSLTQuotasHandler(ctx context.Context, req *output.ReconcileSLTQuotasRequest) (*output.ReconcileSLTQuotasResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	report, err := s.teachingService.ReconcileSLTQuotas(ctx, teaching.ReconcileSLTQuotasSpec{
+		Repair: req.Repair,
+	})
+	if err != nil {
+		return nil, handleUpsertionError(err, "teachingService.ReconcileSLTQuotas()", "studentLearningToken")
+	}
+
+	message := fmt.Sprintf("Found %d drifted studentLearningToken(s) out of %d", len(report.Drifts), report.TotalCheckedTokens)
+	if report.IsRepaired {
+		message = fmt.Sprintf("Successfully repaired %d drifted studentLearningToken(s) out of %d", len(report.Drifts), report.TotalCheckedTokens)
+	}
+
+	return &output.ReconcileSLTQuotasResponse{
+		Data:    report,
+		Message: message,
+	}, nil
+}
+
 func (s *BackendService) SignUpHandler(ctx context.Context, req *output.SignUpRequest) (*output.SignUpResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
@@ -1056,6 +1090,82 @@ func (s *BackendService) DeleteCoursesHandler(ctx context.Context, req *output.D
 	}, nil
 }
 
+func (s *BackendService) GetCourseFeeHistoriesHandler(ctx context.Context, req *output.GetCourseFeeHistoriesRequest) (*output.GetCourseFeeHistoriesResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	result, err := s.getCourseFeeHistories(ctx, req.CourseID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output.GetCourseFeeHistoriesResponse{
+		Data: result,
+	}, nil
+}
+
+func (s *BackendService) InsertCourseFeeHistoriesHandler(ctx context.Context, req *output.InsertCourseFeeHistoriesRequest) (*output.InsertCourseFeeHistoriesResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	courseFeeHistorySpecs := make([]entity.InsertCourseFeeHistorySpec, 0)
+	teacherSpecialFeeHistorySpecs := make([]entity.InsertTeacherSpecialFeeHistorySpec, 0)
+	for _, param := range req.Data {
+		if param.TeacherID != entity.TeacherID_None {
+			teacherSpecialFeeHistorySpecs = append(teacherSpecialFeeHistorySpecs, entity.InsertTeacherSpecialFeeHistorySpec{
+				TeacherID:     param.TeacherID,
+				CourseID:      req.CourseID,
+				Fee:           param.Fee,
+				EffectiveFrom: param.EffectiveFrom,
+			})
+			continue
+		}
+		courseFeeHistorySpecs = append(courseFeeHistorySpecs, entity.InsertCourseFeeHistorySpec{
+			CourseID:      req.CourseID,
+			Fee:           param.Fee,
+			EffectiveFrom: param.EffectiveFrom,
+		})
+	}
+
+	courseFeeHistoryIDs, err := s.entityService.InsertCourseFeeHistories(ctx, courseFeeHistorySpecs)
+	if err != nil {
+		return nil, handleUpsertionError(err, "entityService.InsertCourseFeeHistories()", "courseFeeHistory")
+	}
+	teacherSpecialFeeHistoryIDs, err := s.entityService.InsertTeacherSpecialFeeHistories(ctx, teacherSpecialFeeHistorySpecs)
+	if err != nil {
+		return nil, handleUpsertionError(err, "entityService.InsertTeacherSpecialFeeHistories()", "teacherSpecialFeeHistory")
+	}
+	mainLog.Info("Fee histories created: courseFeeHistoryIDs='%v', teacherSpecialFeeHistoryIDs='%v'", courseFeeHistoryIDs, teacherSpecialFeeHistoryIDs)
+
+	result, errH := s.getCourseFeeHistories(ctx, req.CourseID)
+	if errH != nil {
+		return nil, errH
+	}
+
+	return &output.InsertCourseFeeHistoriesResponse{
+		Data:    result,
+		Message: "Successfully created fee histories",
+	}, nil
+}
+
+func (s *BackendService) getCourseFeeHistories(ctx context.Context, courseID entity.CourseID) (output.GetCourseFeeHistoriesResult, errs.HTTPError) {
+	courseFeeHistories, err := s.entityService.GetCourseFeeHistoriesByCourseId(ctx, courseID)
+	if err != nil {
+		return output.GetCourseFeeHistoriesResult{}, handleReadError(err, "entityService.GetCourseFeeHistoriesByCourseId()", "courseFeeHistory")
+	}
+	teacherSpecialFeeHistories, err := s.entityService.GetTeacherSpecialFeeHistoriesByCourseId(ctx, courseID)
+	if err != nil {
+		return output.GetCourseFeeHistoriesResult{}, handleReadError(err, "entityService.GetTeacherSpecialFeeHistoriesByCourseId()", "teacherSpecialFeeHistory")
+	}
+
+	return output.GetCourseFeeHistoriesResult{
+		CourseFeeHistories:         courseFeeHistories,
+		TeacherSpecialFeeHistories: teacherSpecialFeeHistories,
+	}, nil
+}
+
 func (s *BackendService) GetClassesHandler(ctx context.Context, req *output.GetClassesRequest) (*output.GetClassesResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
@@ -1207,685 +1317,1936 @@ func (s *BackendService) GetStudentEnrollmentsHandler(ctx context.Context, req *
 	}, nil
 }
 
-func (s *BackendService) GetTeacherSpecialFeesHandler(ctx context.Context, req *output.GetTeacherSpecialFeesRequest) (*output.GetTeacherSpecialFeesResponse, errs.HTTPError) {
+func (s *BackendService) GetStudentEnrollmentHistoriesHandler(ctx context.Context, req *output.GetStudentEnrollmentHistoriesRequest) (*output.GetStudentEnrollmentHistoriesResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	getTeacherSpecialFeesResult, err := s.entityService.GetTeacherSpecialFees(ctx, util.PaginationSpec((req.PaginationRequest)))
+	histories, err := s.entityService.GetStudentEnrollmentHistoriesByStudentId(ctx, req.StudentID)
 	if err != nil {
-		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetTeacherSpecialFees(): %w", err), nil, "Failed to get teacherSpecialFees")
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetStudentEnrollmentHistoriesByStudentId(): %w", err), nil, "Failed to get studentEnrollment histories")
 	}
 
-	paginationResponse := output.NewPaginationResponse(getTeacherSpecialFeesResult.PaginationResult)
-
-	return &output.GetTeacherSpecialFeesResponse{
-		Data: output.GetTeacherSpecialFeesResult{
-			Results:            getTeacherSpecialFeesResult.TeacherSpecialFees,
-			PaginationResponse: paginationResponse,
+	return &output.GetStudentEnrollmentHistoriesResponse{
+		Data: output.GetStudentEnrollmentHistoriesResult{
+			Results: histories,
 		},
 	}, nil
 }
 
-func (s *BackendService) GetTeacherSpecialFeeByIdHandler(ctx context.Context, req *output.GetTeacherSpecialFeeRequest) (*output.GetTeacherSpecialFeeResponse, errs.HTTPError) {
+func (s *BackendService) EnrollStudentHandler(ctx context.Context, req *output.EnrollStudentRequest) (*output.EnrollStudentResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	teacherSpecialFee, err := s.entityService.GetTeacherSpecialFeeById(ctx, req.TeacherSpecialFeeID)
+	studentEnrollmentID, err := s.entityService.EnrollStudent(ctx, entity.EnrollStudentSpec{
+		StudentID: req.StudentID,
+		ClassID:   req.ClassID,
+		StartDate: req.StartDate,
+	})
 	if err != nil {
-		return nil, handleReadError(err, "identityService.GetTeacherSpecialFeeById()", "teacherSpecialFee")
+		if errors.Is(err, errs.ErrStudentEnrollmentAlreadyActive) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("entityService.EnrollStudent(): %w", err), nil, "The student is already actively enrolled in the class")
+		}
+		if errors.Is(err, errs.ErrStudentEnrollmentPeriodOverlaps) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("entityService.EnrollStudent(): %w", err), nil, "startDate must be after the student's previous withdrawal date from the class")
+		}
+		return nil, handleUpsertionError(err, "entityService.EnrollStudent()", "studentEnrollment")
 	}
+	mainLog.Info("Student enrolled: studentID='%d', classID='%d', studentEnrollmentID='%d'", req.StudentID, req.ClassID, studentEnrollmentID)
 
-	return &output.GetTeacherSpecialFeeResponse{
-		Data: teacherSpecialFee,
+	studentEnrollment, err := s.entityService.GetStudentEnrollmentById(ctx, studentEnrollmentID)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetStudentEnrollmentById: %v", err), nil, "")
+	}
+
+	return &output.EnrollStudentResponse{
+		Data:    studentEnrollment,
+		Message: "Successfully enrolled student",
 	}, nil
 }
 
-func (s *BackendService) InsertTeacherSpecialFeesHandler(ctx context.Context, req *output.InsertTeacherSpecialFeesRequest) (*output.InsertTeacherSpecialFeesResponse, errs.HTTPError) {
+func (s *BackendService) WithdrawStudentEnrollmentHandler(ctx context.Context, req *output.WithdrawStudentEnrollmentRequest) (*output.WithdrawStudentEnrollmentResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	specs := make([]entity.InsertTeacherSpecialFeeSpec, 0, len(req.Data))
+	err := s.entityService.WithdrawStudentEnrollment(ctx, entity.WithdrawStudentEnrollmentSpec{
+		StudentEnrollmentID: req.StudentEnrollmentID,
+		EndDate:             req.EndDate,
+		Reason:              req.Reason,
+	})
+	if err != nil {
+		if errors.Is(err, errs.ErrStudentEnrollmentNotActive) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("entityService.WithdrawStudentEnrollment(): %w", err), nil, "The studentEnrollment has already been withdrawn")
+		}
+		if errors.Is(err, errs.ErrStudentEnrollmentPeriodOverlaps) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("entityService.WithdrawStudentEnrollment(): %w", err), nil, "endDate must not be before the studentEnrollment's start date")
+		}
+		if errors.Is(err, errs.ErrAttendanceAfterWithdrawal) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("entityService.WithdrawStudentEnrollment(): %w", err), map[string]string{"endDate": "endDate must not be before the student's latest attendance in the class"}, "The student has attendances after the endDate. Please remove them, or withdraw the student at a later date")
+		}
+		return nil, handleReadUpsertError(err, "entityService.WithdrawStudentEnrollment()", "studentEnrollment")
+	}
+	mainLog.Info("StudentEnrollment withdrawn: studentEnrollmentID='%d', endDate='%s'", req.StudentEnrollmentID, req.EndDate.Format("2006-01-02"))
+
+	return &output.WithdrawStudentEnrollmentResponse{
+		Message: "Successfully withdrew studentEnrollment",
+	}, nil
+}
+
+func (s *BackendService) ReEnrollStudentEnrollmentHandler(ctx context.Context, req *output.ReEnrollStudentEnrollmentRequest) (*output.ReEnrollStudentEnrollmentResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	err := s.entityService.ReEnrollStudentEnrollment(ctx, entity.ReEnrollStudentEnrollmentSpec{
+		StudentEnrollmentID: req.StudentEnrollmentID,
+		StartDate:           req.StartDate,
+	})
+	if err != nil {
+		if errors.Is(err, errs.ErrStudentEnrollmentAlreadyActive) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("entityService.ReEnrollStudentEnrollment(): %w", err), nil, "The studentEnrollment is still active")
+		}
+		if errors.Is(err, errs.ErrStudentEnrollmentPeriodOverlaps) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("entityService.ReEnrollStudentEnrollment(): %w", err), nil, "startDate must be after the studentEnrollment's previous withdrawal date")
+		}
+		return nil, handleReadUpsertError(err, "entityService.ReEnrollStudentEnrollment()", "studentEnrollment")
+	}
+	mainLog.Info("StudentEnrollment re-enrolled: studentEnrollmentID='%d', startDate='%s'", req.StudentEnrollmentID, req.StartDate.Format("2006-01-02"))
+
+	studentEnrollment, err := s.entityService.GetStudentEnrollmentById(ctx, req.StudentEnrollmentID)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetStudentEnrollmentById: %v", err), nil, "")
+	}
+
+	return &output.ReEnrollStudentEnrollmentResponse{
+		Data:    studentEnrollment,
+		Message: "Successfully re-enrolled studentEnrollment",
+	}, nil
+}
+
+func (s *BackendService) GetStudentEnrollmentHoldsHandler(ctx context.Context, req *output.GetStudentEnrollmentHoldsRequest) (*output.GetStudentEnrollmentHoldsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	holds, err := s.entityService.GetStudentEnrollmentHoldsByStudentEnrollmentIds(ctx, []entity.StudentEnrollmentID{req.StudentEnrollmentID})
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetStudentEnrollmentHoldsByStudentEnrollmentIds(): %w", err), nil, "Failed to get studentEnrollmentHolds")
+	}
+
+	return &output.GetStudentEnrollmentHoldsResponse{
+		Data: output.UpsertStudentEnrollmentHoldResult{
+			Results: holds,
+		},
+	}, nil
+}
+
+func (s *BackendService) InsertStudentEnrollmentHoldsHandler(ctx context.Context, req *output.InsertStudentEnrollmentHoldsRequest) (*output.InsertStudentEnrollmentHoldsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	specs := make([]entity.InsertStudentEnrollmentHoldSpec, 0, len(req.Data))
 	for _, param := range req.Data {
-		specs = append(specs, entity.InsertTeacherSpecialFeeSpec{
-			TeacherID: param.TeacherID,
-			CourseID:  param.CourseID,
-			Fee:       param.Fee,
+		specs = append(specs, entity.InsertStudentEnrollmentHoldSpec{
+			StudentEnrollmentID: param.StudentEnrollmentID,
+			StartDate:           param.StartDate,
+			EndDate:             param.EndDate,
+			Reason:              param.Reason,
 		})
 	}
 
-	teacherSpecialFeeIDs, err := s.entityService.InsertTeacherSpecialFees(ctx, specs)
+	holdIDs, err := s.entityService.InsertStudentEnrollmentHolds(ctx, specs)
 	if err != nil {
-		return nil, handleUpsertionError(err, "entityService.InsertTeacherSpecialFees()", "teacherSpecialFee")
+		if errors.Is(err, errs.ErrStudentEnrollmentHoldOverlaps) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("entityService.InsertStudentEnrollmentHolds(): %w", err), nil, "The hold overlaps with another hold of the same studentEnrollment")
+		}
+		return nil, handleUpsertionError(err, "entityService.InsertStudentEnrollmentHolds()", "studentEnrollmentHold")
 	}
-	mainLog.Info("TeacherSpecialFees created: teacherSpecialFeeIDs='%v'", teacherSpecialFeeIDs)
+	mainLog.Info("StudentEnrollmentHolds created: studentEnrollmentHoldIDs='%v'", holdIDs)
 
-	teacherSpecialFees, err := s.entityService.GetTeacherSpecialFeesByIds(ctx, teacherSpecialFeeIDs)
+	holds, err := s.entityService.GetStudentEnrollmentHoldsByIds(ctx, holdIDs)
 	if err != nil {
-		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetTeacherSpecialFeesByIds: %v", err), nil, "")
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetStudentEnrollmentHoldsByIds: %v", err), nil, "")
 	}
 
-	return &output.InsertTeacherSpecialFeesResponse{
-		Data: output.UpsertTeacherSpecialFeeResult{
-			Results: teacherSpecialFees,
+	return &output.InsertStudentEnrollmentHoldsResponse{
+		Data: output.UpsertStudentEnrollmentHoldResult{
+			Results: holds,
 		},
-		Message: "Successfully created teacherSpecialFees",
+		Message: "Successfully created studentEnrollmentHolds",
 	}, nil
 }
 
-func (s *BackendService) UpdateTeacherSpecialFeesHandler(ctx context.Context, req *output.UpdateTeacherSpecialFeesRequest) (*output.UpdateTeacherSpecialFeesResponse, errs.HTTPError) {
+func (s *BackendService) UpdateStudentEnrollmentHoldsHandler(ctx context.Context, req *output.UpdateStudentEnrollmentHoldsRequest) (*output.UpdateStudentEnrollmentHoldsResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	specs := make([]entity.UpdateTeacherSpecialFeeSpec, 0, len(req.Data))
+	specs := make([]entity.UpdateStudentEnrollmentHoldSpec, 0, len(req.Data))
 	for _, param := range req.Data {
-		specs = append(specs, entity.UpdateTeacherSpecialFeeSpec{
-			TeacherSpecialFeeID: param.TeacherSpecialFeeID,
-			Fee:                 param.Fee,
+		specs = append(specs, entity.UpdateStudentEnrollmentHoldSpec{
+			StudentEnrollmentHoldID: param.StudentEnrollmentHoldID,
+			StartDate:               param.StartDate,
+			EndDate:                 param.EndDate,
+			Reason:                  param.Reason,
 		})
 	}
 
-	teacherSpecialFeeIDs, err := s.entityService.UpdateTeacherSpecialFees(ctx, specs)
+	holdIDs, err := s.entityService.UpdateStudentEnrollmentHolds(ctx, specs)
 	if err != nil {
-		return nil, handleUpsertionError(err, "entityService.UpdateTeacherSpecialFees()", "teacherSpecialFee")
+		if errors.Is(err, errs.ErrStudentEnrollmentHoldOverlaps) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("entityService.UpdateStudentEnrollmentHolds(): %w", err), nil, "The hold overlaps with another hold of the same studentEnrollment")
+		}
+		return nil, handleUpsertionError(err, "entityService.UpdateStudentEnrollmentHolds()", "studentEnrollmentHold")
 	}
-	mainLog.Info("TeacherSpecialFees updated: teacherSpecialFeeIDs='%v'", teacherSpecialFeeIDs)
+	mainLog.Info("StudentEnrollmentHolds updated: studentEnrollmentHoldIDs='%v'", holdIDs)
 
-	teacherSpecialFees, err := s.entityService.GetTeacherSpecialFeesByIds(ctx, teacherSpecialFeeIDs)
+	holds, err := s.entityService.GetStudentEnrollmentHoldsByIds(ctx, holdIDs)
 	if err != nil {
-		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetTeacherSpecialFeesByIds: %v", err), nil, "")
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetStudentEnrollmentHoldsByIds: %v", err), nil, "")
 	}
 
-	return &output.UpdateTeacherSpecialFeesResponse{
-		Data: output.UpsertTeacherSpecialFeeResult{
-			Results: teacherSpecialFees,
+	return &output.UpdateStudentEnrollmentHoldsResponse{
+		Data: output.UpsertStudentEnrollmentHoldResult{
+			Results: holds,
 		},
-		Message: "Successfully updated teacherSpecialFees",
+		Message: "Successfully updated studentEnrollmentHolds",
 	}, nil
 }
 
-func (s *BackendService) DeleteTeacherSpecialFeesHandler(ctx context.Context, req *output.DeleteTeacherSpecialFeesRequest) (*output.DeleteTeacherSpecialFeesResponse, errs.HTTPError) {
+func (s *BackendService) DeleteStudentEnrollmentHoldsHandler(ctx context.Context, req *output.DeleteStudentEnrollmentHoldsRequest) (*output.DeleteStudentEnrollmentHoldsResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	ids := make([]entity.TeacherSpecialFeeID, 0, len(req.Data))
+	ids := make([]entity.StudentEnrollmentHoldID, 0, len(req.Data))
 	for _, param := range req.Data {
-		ids = append(ids, param.TeacherSpecialFeeID)
+		ids = append(ids, param.StudentEnrollmentHoldID)
 	}
 
-	err := s.entityService.DeleteTeacherSpecialFees(ctx, ids)
+	err := s.entityService.DeleteStudentEnrollmentHolds(ctx, ids)
 	if err != nil {
-		return nil, handleDeletionError(err, "identityService.DeleteTeacherSpecialFees()", "teacherSpecialFee")
+		return nil, handleDeletionError(err, "entityService.DeleteStudentEnrollmentHolds()", "studentEnrollmentHold")
 	}
 
-	return &output.DeleteTeacherSpecialFeesResponse{
-		Message: "Successfully deleted teacherSpecialFees",
+	return &output.DeleteStudentEnrollmentHoldsResponse{
+		Message: "Successfully deleted studentEnrollmentHolds",
 	}, nil
 }
 
-func (s *BackendService) GetEnrollmentPaymentsHandler(ctx context.Context, req *output.GetEnrollmentPaymentsRequest) (*output.GetEnrollmentPaymentsResponse, errs.HTTPError) {
+func (s *BackendService) GetFamiliesHandler(ctx context.Context, req *output.GetFamiliesRequest) (*output.GetFamiliesResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	paginationSpec := util.PaginationSpec(req.PaginationRequest)
-	timeFilter := util.TimeSpec(req.TimeFilter)
-	getEnrollmentPaymentsResult, err := s.entityService.GetEnrollmentPayments(ctx, paginationSpec, timeFilter, false)
+	getFamiliesResult, err := s.entityService.GetFamilies(ctx, util.PaginationSpec(req.PaginationRequest))
 	if err != nil {
-		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetEnrollmentPayments(): %w", err), nil, "Failed to get courses")
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetFamilies(): %w", err), nil, "Failed to get families")
 	}
 
-	paginationResponse := output.NewPaginationResponse(getEnrollmentPaymentsResult.PaginationResult)
+	paginationResponse := output.NewPaginationResponse(getFamiliesResult.PaginationResult)
 
-	return &output.GetEnrollmentPaymentsResponse{
-		Data: output.GetEnrollmentPaymentsResult{
-			Results:            getEnrollmentPaymentsResult.EnrollmentPayments,
+	return &output.GetFamiliesResponse{
+		Data: output.GetFamiliesResult{
+			Results:            getFamiliesResult.Families,
 			PaginationResponse: paginationResponse,
 		},
 	}, nil
 }
 
-func (s *BackendService) GetEnrollmentPaymentByIdHandler(ctx context.Context, req *output.GetEnrollmentPaymentRequest) (*output.GetEnrollmentPaymentResponse, errs.HTTPError) {
+func (s *BackendService) GetFamilyByIdHandler(ctx context.Context, req *output.GetFamilyRequest) (*output.GetFamilyResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	enrollmentPayment, err := s.entityService.GetEnrollmentPaymentById(ctx, req.EnrollmentPaymentID)
+	family, err := s.entityService.GetFamilyById(ctx, req.FamilyID)
 	if err != nil {
-		return nil, handleReadError(err, "identityService.GetEnrollmentPaymentById()", "enrollmentPayment")
+		return nil, handleReadError(err, "entityService.GetFamilyById()", "family")
 	}
 
-	return &output.GetEnrollmentPaymentResponse{
-		Data: enrollmentPayment,
+	return &output.GetFamilyResponse{
+		Data: family,
 	}, nil
 }
 
-func (s *BackendService) InsertEnrollmentPaymentsHandler(ctx context.Context, req *output.InsertEnrollmentPaymentsRequest) (*output.InsertEnrollmentPaymentsResponse, errs.HTTPError) {
+func (s *BackendService) InsertFamiliesHandler(ctx context.Context, req *output.InsertFamiliesRequest) (*output.InsertFamiliesResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	specs := make([]entity.InsertEnrollmentPaymentSpec, 0, len(req.Data))
+	specs := make([]entity.InsertFamilySpec, 0, len(req.Data))
 	for _, param := range req.Data {
-		specs = append(specs, entity.InsertEnrollmentPaymentSpec{
-			StudentEnrollmentID: param.StudentEnrollmentID,
-			PaymentDate:         param.PaymentDate,
-			BalanceTopUp:        param.BalanceTopUp,
-			BalanceBonus:        param.BalanceBonus,
-			CourseFeeValue:      param.CourseFeeValue,
-			TransportFeeValue:   param.TransportFeeValue,
-			PenaltyFeeValue:     param.PenaltyFeeValue,
-			DiscountFeeValue:    param.DiscountFeeValue,
+		specs = append(specs, entity.InsertFamilySpec{
+			Name:            param.Name,
+			StudentIDs:      param.StudentIDs,
+			GuardianUserIDs: param.GuardianUserIDs,
 		})
 	}
 
-	enrollmentPaymentIDs, err := s.entityService.InsertEnrollmentPayments(ctx, specs)
+	familyIDs, err := s.entityService.InsertFamilies(ctx, specs)
 	if err != nil {
-		return nil, handleUpsertionError(err, "entityService.InsertEnrollmentPayments()", "enrollmentPayment")
+		return nil, handleUpsertionError(err, "entityService.InsertFamilies()", "family")
 	}
-	mainLog.Info("EnrollmentPayments created: enrollmentPaymentIDs='%v'", enrollmentPaymentIDs)
+	mainLog.Info("Families created: familyIDs='%v'", familyIDs)
 
-	enrollmentPayments, err := s.entityService.GetEnrollmentPaymentsByIds(ctx, enrollmentPaymentIDs)
+	families, err := s.entityService.GetFamiliesByIds(ctx, familyIDs)
 	if err != nil {
-		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetEnrollmentPaymentsByIds: %v", err), nil, "")
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetFamiliesByIds: %v", err), nil, "")
 	}
 
-	return &output.InsertEnrollmentPaymentsResponse{
-		Data: output.UpsertEnrollmentPaymentResult{
-			Results: enrollmentPayments,
+	return &output.InsertFamiliesResponse{
+		Data: output.UpsertFamilyResult{
+			Results: families,
 		},
-		Message: "Successfully created enrollmentPayments",
+		Message: "Successfully created families",
 	}, nil
 }
 
-func (s *BackendService) UpdateEnrollmentPaymentsHandler(ctx context.Context, req *output.UpdateEnrollmentPaymentsRequest) (*output.UpdateEnrollmentPaymentsResponse, errs.HTTPError) {
+func (s *BackendService) UpdateFamiliesHandler(ctx context.Context, req *output.UpdateFamiliesRequest) (*output.UpdateFamiliesResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	specs := make([]entity.UpdateEnrollmentPaymentSpec, 0, len(req.Data))
+	specs := make([]entity.UpdateFamilySpec, 0, len(req.Data))
 	for _, param := range req.Data {
-		specs = append(specs, entity.UpdateEnrollmentPaymentSpec{
-			EnrollmentPaymentID: param.EnrollmentPaymentID,
-			PaymentDate:         param.PaymentDate,
-			BalanceTopUp:        param.BalanceTopUp,
-			BalanceBonus:        param.BalanceBonus,
-			CourseFeeValue:      param.CourseFeeValue,
-			TransportFeeValue:   param.TransportFeeValue,
-			PenaltyFeeValue:     param.PenaltyFeeValue,
-			DiscountFeeValue:    param.DiscountFeeValue,
+		specs = append(specs, entity.UpdateFamilySpec{
+			FamilyID:        param.FamilyID,
+			Name:            param.Name,
+			StudentIDs:      param.StudentIDs,
+			GuardianUserIDs: param.GuardianUserIDs,
 		})
 	}
 
-	enrollmentPaymentIDs, err := s.entityService.UpdateEnrollmentPayments(ctx, specs)
+	familyIDs, err := s.entityService.UpdateFamilies(ctx, specs)
 	if err != nil {
-		return nil, handleUpsertionError(err, "entityService.UpdateEnrollmentPayments()", "enrollmentPayment")
+		return nil, handleUpsertionError(err, "entityService.UpdateFamilies()", "family")
 	}
-	mainLog.Info("EnrollmentPayments updated: enrollmentPaymentIDs='%v'", enrollmentPaymentIDs)
+	mainLog.Info("Families updated: familyIDs='%v'", familyIDs)
 
-	enrollmentPayments, err := s.entityService.GetEnrollmentPaymentsByIds(ctx, enrollmentPaymentIDs)
+	families, err := s.entityService.GetFamiliesByIds(ctx, familyIDs)
 	if err != nil {
-		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetEnrollmentPaymentsByIds: %v", err), nil, "")
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetFamiliesByIds: %v", err), nil, "")
 	}
 
-	return &output.UpdateEnrollmentPaymentsResponse{
-		Data: output.UpsertEnrollmentPaymentResult{
-			Results: enrollmentPayments,
+	return &output.UpdateFamiliesResponse{
+		Data: output.UpsertFamilyResult{
+			Results: families,
 		},
-		Message: "Successfully updated enrollmentPayments",
+		Message: "Successfully updated families",
 	}, nil
 }
 
-func (s *BackendService) DeleteEnrollmentPaymentsHandler(ctx context.Context, req *output.DeleteEnrollmentPaymentsRequest) (*output.DeleteEnrollmentPaymentsResponse, errs.HTTPError) {
+func (s *BackendService) DeleteFamiliesHandler(ctx context.Context, req *output.DeleteFamiliesRequest) (*output.DeleteFamiliesResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	ids := make([]entity.EnrollmentPaymentID, 0, len(req.Data))
+	ids := make([]entity.FamilyID, 0, len(req.Data))
 	for _, param := range req.Data {
-		ids = append(ids, param.EnrollmentPaymentID)
+		ids = append(ids, param.FamilyID)
 	}
 
-	err := s.entityService.DeleteEnrollmentPayments(ctx, ids)
+	err := s.entityService.DeleteFamilies(ctx, ids)
 	if err != nil {
-		return nil, handleDeletionError(err, "identityService.DeleteEnrollmentPayments()", "enrollmentPayment")
+		return nil, handleDeletionError(err, "entityService.DeleteFamilies()", "family")
 	}
 
-	return &output.DeleteEnrollmentPaymentsResponse{
-		Message: "Successfully deleted enrollmentPayments",
+	return &output.DeleteFamiliesResponse{
+		Message: "Successfully deleted families",
 	}, nil
 }
 
-func (s *BackendService) GetStudentLearningTokensHandler(ctx context.Context, req *output.GetStudentLearningTokensRequest) (*output.GetStudentLearningTokensResponse, errs.HTTPError) {
+func (s *BackendService) GetTeacherSpecialFeesHandler(ctx context.Context, req *output.GetTeacherSpecialFeesRequest) (*output.GetTeacherSpecialFeesResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	getStudentLearningTokensResult, err := s.entityService.GetStudentLearningTokens(ctx, util.PaginationSpec(req.PaginationRequest))
+	getTeacherSpecialFeesResult, err := s.entityService.GetTeacherSpecialFees(ctx, util.PaginationSpec((req.PaginationRequest)))
 	if err != nil {
-		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetStudentLearningTokens(): %w", err), nil, "Failed to get courses")
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetTeacherSpecialFees(): %w", err), nil, "Failed to get teacherSpecialFees")
 	}
 
-	paginationResponse := output.NewPaginationResponse(getStudentLearningTokensResult.PaginationResult)
+	paginationResponse := output.NewPaginationResponse(getTeacherSpecialFeesResult.PaginationResult)
 
-	return &output.GetStudentLearningTokensResponse{
-		Data: output.GetStudentLearningTokensResult{
-			Results:            getStudentLearningTokensResult.StudentLearningTokens,
+	return &output.GetTeacherSpecialFeesResponse{
+		Data: output.GetTeacherSpecialFeesResult{
+			Results:            getTeacherSpecialFeesResult.TeacherSpecialFees,
 			PaginationResponse: paginationResponse,
 		},
 	}, nil
 }
 
-func (s *BackendService) GetStudentLearningTokenByIdHandler(ctx context.Context, req *output.GetStudentLearningTokenRequest) (*output.GetStudentLearningTokenResponse, errs.HTTPError) {
+func (s *BackendService) GetTeacherSpecialFeeByIdHandler(ctx context.Context, req *output.GetTeacherSpecialFeeRequest) (*output.GetTeacherSpecialFeeResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	studentLearningToken, err := s.entityService.GetStudentLearningTokenById(ctx, req.StudentLearningTokenID)
+	teacherSpecialFee, err := s.entityService.GetTeacherSpecialFeeById(ctx, req.TeacherSpecialFeeID)
 	if err != nil {
-		return nil, handleReadError(err, "identityService.GetStudentLearningTokenById()", "studentLearningToken")
+		return nil, handleReadError(err, "identityService.GetTeacherSpecialFeeById()", "teacherSpecialFee")
 	}
 
-	return &output.GetStudentLearningTokenResponse{
-		Data: studentLearningToken,
+	return &output.GetTeacherSpecialFeeResponse{
+		Data: teacherSpecialFee,
 	}, nil
 }
 
-func (s *BackendService) InsertStudentLearningTokensHandler(ctx context.Context, req *output.InsertStudentLearningTokensRequest) (*output.InsertStudentLearningTokensResponse, errs.HTTPError) {
+func (s *BackendService) InsertTeacherSpecialFeesHandler(ctx context.Context, req *output.InsertTeacherSpecialFeesRequest) (*output.InsertTeacherSpecialFeesResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	specs := make([]entity.InsertStudentLearningTokenSpec, 0, len(req.Data))
+	specs := make([]entity.InsertTeacherSpecialFeeSpec, 0, len(req.Data))
 	for _, param := range req.Data {
-		specs = append(specs, entity.InsertStudentLearningTokenSpec{
-			StudentEnrollmentID:      param.StudentEnrollmentID,
-			Quota:                    param.Quota,
-			CourseFeeQuarterValue:    param.CourseFeeQuarterValue,
-			TransportFeeQuarterValue: param.TransportFeeQuarterValue,
+		specs = append(specs, entity.InsertTeacherSpecialFeeSpec{
+			TeacherID: param.TeacherID,
+			CourseID:  param.CourseID,
+			Fee:       param.Fee,
 		})
 	}
 
-	studentLearningTokenIDs, err := s.entityService.InsertStudentLearningTokens(ctx, specs)
+	teacherSpecialFeeIDs, err := s.entityService.InsertTeacherSpecialFees(ctx, specs)
 	if err != nil {
-		return nil, handleUpsertionError(err, "entityService.InsertStudentLearningTokens()", "studentLearningToken")
+		return nil, handleUpsertionError(err, "entityService.InsertTeacherSpecialFees()", "teacherSpecialFee")
 	}
-	mainLog.Info("StudentLearningTokens created: studentLearningTokenIDs='%v'", studentLearningTokenIDs)
+	mainLog.Info("TeacherSpecialFees created: teacherSpecialFeeIDs='%v'", teacherSpecialFeeIDs)
 
-	studentLearningTokens, err := s.entityService.GetStudentLearningTokensByIds(ctx, studentLearningTokenIDs)
+	teacherSpecialFees, err := s.entityService.GetTeacherSpecialFeesByIds(ctx, teacherSpecialFeeIDs)
 	if err != nil {
-		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetStudentLearningTokensByIds: %v", err), nil, "")
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetTeacherSpecialFeesByIds: %v", err), nil, "")
 	}
 
-	return &output.InsertStudentLearningTokensResponse{
-		Data: output.UpsertStudentLearningTokenResult{
-			Results: studentLearningTokens,
+	return &output.InsertTeacherSpecialFeesResponse{
+		Data: output.UpsertTeacherSpecialFeeResult{
+			Results: teacherSpecialFees,
 		},
-		Message: "Successfully created studentLearningTokens",
+		Message: "Successfully created teacherSpecialFees",
 	}, nil
 }
 
-func (s *BackendService) UpdateStudentLearningTokensHandler(ctx context.Context, req *output.UpdateStudentLearningTokensRequest) (*output.UpdateStudentLearningTokensResponse, errs.HTTPError) {
+func (s *BackendService) UpdateTeacherSpecialFeesHandler(ctx context.Context, req *output.UpdateTeacherSpecialFeesRequest) (*output.UpdateTeacherSpecialFeesResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	specs := make([]entity.UpdateStudentLearningTokenSpec, 0, len(req.Data))
+	specs := make([]entity.UpdateTeacherSpecialFeeSpec, 0, len(req.Data))
 	for _, param := range req.Data {
-		specs = append(specs, entity.UpdateStudentLearningTokenSpec{
-			StudentLearningTokenID:   param.StudentLearningTokenID,
-			Quota:                    param.Quota,
-			CourseFeeQuarterValue:    param.CourseFeeQuarterValue,
-			TransportFeeQuarterValue: param.TransportFeeQuarterValue,
+		specs = append(specs, entity.UpdateTeacherSpecialFeeSpec{
+			TeacherSpecialFeeID: param.TeacherSpecialFeeID,
+			Fee:                 param.Fee,
 		})
 	}
 
-	studentLearningTokenIDs, err := s.entityService.UpdateStudentLearningTokens(ctx, specs)
+	teacherSpecialFeeIDs, err := s.entityService.UpdateTeacherSpecialFees(ctx, specs)
 	if err != nil {
-		return nil, handleUpsertionError(err, "entityService.UpdateStudentLearningTokens()", "studentLearningToken")
+		return nil, handleUpsertionError(err, "entityService.UpdateTeacherSpecialFees()", "teacherSpecialFee")
 	}
-	mainLog.Info("StudentLearningTokens updated: studentLearningTokenIDs='%v'", studentLearningTokenIDs)
+	mainLog.Info("TeacherSpecialFees updated: teacherSpecialFeeIDs='%v'", teacherSpecialFeeIDs)
 
-	studentLearningTokens, err := s.entityService.GetStudentLearningTokensByIds(ctx, studentLearningTokenIDs)
+	teacherSpecialFees, err := s.entityService.GetTeacherSpecialFeesByIds(ctx, teacherSpecialFeeIDs)
 	if err != nil {
-		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetStudentLearningTokensByIds: %v", err), nil, "")
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetTeacherSpecialFeesByIds: %v", err), nil, "")
 	}
 
-	return &output.UpdateStudentLearningTokensResponse{
-		Data: output.UpsertStudentLearningTokenResult{
-			Results: studentLearningTokens,
+	return &output.UpdateTeacherSpecialFeesResponse{
+		Data: output.UpsertTeacherSpecialFeeResult{
+			Results: teacherSpecialFees,
 		},
-		Message: "Successfully updated studentLearningTokens",
+		Message: "Successfully updated teacherSpecialFees",
 	}, nil
 }
 
-func (s *BackendService) DeleteStudentLearningTokensHandler(ctx context.Context, req *output.DeleteStudentLearningTokensRequest) (*output.DeleteStudentLearningTokensResponse, errs.HTTPError) {
+func (s *BackendService) DeleteTeacherSpecialFeesHandler(ctx context.Context, req *output.DeleteTeacherSpecialFeesRequest) (*output.DeleteTeacherSpecialFeesResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	ids := make([]entity.StudentLearningTokenID, 0, len(req.Data))
+	ids := make([]entity.TeacherSpecialFeeID, 0, len(req.Data))
 	for _, param := range req.Data {
-		ids = append(ids, param.StudentLearningTokenID)
+		ids = append(ids, param.TeacherSpecialFeeID)
 	}
 
-	err := s.entityService.DeleteStudentLearningTokens(ctx, ids)
+	err := s.entityService.DeleteTeacherSpecialFees(ctx, ids)
 	if err != nil {
-		return nil, handleDeletionError(err, "identityService.DeleteStudentLearningTokens()", "studentLearningToken")
+		return nil, handleDeletionError(err, "identityService.DeleteTeacherSpecialFees()", "teacherSpecialFee")
 	}
 
-	return &output.DeleteStudentLearningTokensResponse{
-		Message: "Successfully deleted studentLearningTokens",
+	return &output.DeleteTeacherSpecialFeesResponse{
+		Message: "Successfully deleted teacherSpecialFees",
 	}, nil
 }
 
-func (s *BackendService) GetAttendancesHandler(ctx context.Context, req *output.GetAttendancesRequest) (*output.GetAttendancesResponse, errs.HTTPError) {
+func (s *BackendService) GetPenaltyPoliciesHandler(ctx context.Context, req *output.GetPenaltyPoliciesRequest) (*output.GetPenaltyPoliciesResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	paginationSpec := util.PaginationSpec(req.PaginationRequest)
-	getAttendancesSpec := entity.GetAttendancesSpec{
-		TimeSpec: util.TimeSpec(req.TimeFilter),
+	getPenaltyPoliciesResult, err := s.entityService.GetPenaltyPolicies(ctx, util.PaginationSpec((req.PaginationRequest)))
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetPenaltyPolicies(): %w", err), nil, "Failed to get penaltyPolicies")
 	}
-	getAttendancesResult, err := s.entityService.GetAttendances(ctx, paginationSpec, getAttendancesSpec, false)
+
+	paginationResponse := output.NewPaginationResponse(getPenaltyPoliciesResult.PaginationResult)
+
+	return &output.GetPenaltyPoliciesResponse{
+		Data: output.GetPenaltyPoliciesResult{
+			Results:            getPenaltyPoliciesResult.PenaltyPolicies,
+			PaginationResponse: paginationResponse,
+		},
+	}, nil
+}
+
+func (s *BackendService) GetPenaltyPolicyByIdHandler(ctx context.Context, req *output.GetPenaltyPolicyRequest) (*output.GetPenaltyPolicyResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	penaltyPolicy, err := s.entityService.GetPenaltyPolicyById(ctx, req.PenaltyPolicyID)
 	if err != nil {
-		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetAttendances(): %w", err), nil, "Failed to get courses")
+		return nil, handleReadError(err, "entityService.GetPenaltyPolicyById()", "penaltyPolicy")
 	}
 
-	paginationResponse := output.NewPaginationResponse(getAttendancesResult.PaginationResult)
+	return &output.GetPenaltyPolicyResponse{
+		Data: penaltyPolicy,
+	}, nil
+}
 
-	return &output.GetAttendancesResponse{
-		Data: output.GetAttendancesResult{
-			Results:            getAttendancesResult.Attendances,
+func (s *BackendService) InsertPenaltyPoliciesHandler(ctx context.Context, req *output.InsertPenaltyPoliciesRequest) (*output.InsertPenaltyPoliciesResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	specs := make([]entity.InsertPenaltyPolicySpec, 0, len(req.Data))
+	for _, param := range req.Data {
+		specs = append(specs, entity.InsertPenaltyPolicySpec{
+			Name:              param.Name,
+			CourseID:          param.CourseID,
+			ClassID:           param.ClassID,
+			TriggerDayOfMonth: param.TriggerDayOfMonth,
+			GraceDays:         param.GraceDays,
+			IsFlatFee:         param.IsFlatFee,
+			FeeValue:          param.FeeValue,
+			MaxFeeValue:       param.MaxFeeValue,
+		})
+	}
+
+	penaltyPolicyIDs, err := s.entityService.InsertPenaltyPolicies(ctx, specs)
+	if err != nil {
+		return nil, handleUpsertionError(err, "entityService.InsertPenaltyPolicies()", "penaltyPolicy")
+	}
+	mainLog.Info("PenaltyPolicies created: penaltyPolicyIDs='%v'", penaltyPolicyIDs)
+
+	penaltyPolicies, err := s.entityService.GetPenaltyPoliciesByIds(ctx, penaltyPolicyIDs)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetPenaltyPoliciesByIds: %v", err), nil, "")
+	}
+
+	return &output.InsertPenaltyPoliciesResponse{
+		Data: output.UpsertPenaltyPolicyResult{
+			Results: penaltyPolicies,
+		},
+		Message: "Successfully created penaltyPolicies",
+	}, nil
+}
+
+func (s *BackendService) UpdatePenaltyPoliciesHandler(ctx context.Context, req *output.UpdatePenaltyPoliciesRequest) (*output.UpdatePenaltyPoliciesResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	specs := make([]entity.UpdatePenaltyPolicySpec, 0, len(req.Data))
+	for _, param := range req.Data {
+		specs = append(specs, entity.UpdatePenaltyPolicySpec{
+			PenaltyPolicyID:   param.PenaltyPolicyID,
+			Name:              param.Name,
+			CourseID:          param.CourseID,
+			ClassID:           param.ClassID,
+			TriggerDayOfMonth: param.TriggerDayOfMonth,
+			GraceDays:         param.GraceDays,
+			IsFlatFee:         param.IsFlatFee,
+			FeeValue:          param.FeeValue,
+			MaxFeeValue:       param.MaxFeeValue,
+		})
+	}
+
+	penaltyPolicyIDs, err := s.entityService.UpdatePenaltyPolicies(ctx, specs)
+	if err != nil {
+		return nil, handleUpsertionError(err, "entityService.UpdatePenaltyPolicies()", "penaltyPolicy")
+	}
+	mainLog.Info("PenaltyPolicies updated: penaltyPolicyIDs='%v'", penaltyPolicyIDs)
+
+	penaltyPolicies, err := s.entityService.GetPenaltyPoliciesByIds(ctx, penaltyPolicyIDs)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetPenaltyPoliciesByIds: %v", err), nil, "")
+	}
+
+	return &output.UpdatePenaltyPoliciesResponse{
+		Data: output.UpsertPenaltyPolicyResult{
+			Results: penaltyPolicies,
+		},
+		Message: "Successfully updated penaltyPolicies",
+	}, nil
+}
+
+func (s *BackendService) DeletePenaltyPoliciesHandler(ctx context.Context, req *output.DeletePenaltyPoliciesRequest) (*output.DeletePenaltyPoliciesResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	ids := make([]entity.PenaltyPolicyID, 0, len(req.Data))
+	for _, param := range req.Data {
+		ids = append(ids, param.PenaltyPolicyID)
+	}
+
+	err := s.entityService.DeletePenaltyPolicies(ctx, ids)
+	if err != nil {
+		return nil, handleDeletionError(err, "entityService.DeletePenaltyPolicies()", "penaltyPolicy")
+	}
+
+	return &output.DeletePenaltyPoliciesResponse{
+		Message: "Successfully deleted penaltyPolicies",
+	}, nil
+}
+
+func (s *BackendService) GetDiscountRulesHandler(ctx context.Context, req *output.GetDiscountRulesRequest) (*output.GetDiscountRulesResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	getDiscountRulesResult, err := s.entityService.GetDiscountRules(ctx, util.PaginationSpec((req.PaginationRequest)))
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetDiscountRules(): %w", err), nil, "Failed to get discountRules")
+	}
+
+	paginationResponse := output.NewPaginationResponse(getDiscountRulesResult.PaginationResult)
+
+	return &output.GetDiscountRulesResponse{
+		Data: output.GetDiscountRulesResult{
+			Results:            getDiscountRulesResult.DiscountRules,
 			PaginationResponse: paginationResponse,
 		},
 	}, nil
 }
 
-func (s *BackendService) GetAttendanceByIdHandler(ctx context.Context, req *output.GetAttendanceRequest) (*output.GetAttendanceResponse, errs.HTTPError) {
+func (s *BackendService) GetDiscountRuleByIdHandler(ctx context.Context, req *output.GetDiscountRuleRequest) (*output.GetDiscountRuleResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	attendance, err := s.entityService.GetAttendanceById(ctx, req.AttendanceID)
+	discountRule, err := s.entityService.GetDiscountRuleById(ctx, req.DiscountRuleID)
 	if err != nil {
-		return nil, handleReadError(err, "identityService.GetAttendanceById()", "attendance")
+		return nil, handleReadError(err, "entityService.GetDiscountRuleById()", "discountRule")
 	}
 
-	return &output.GetAttendanceResponse{
-		Data: attendance,
+	return &output.GetDiscountRuleResponse{
+		Data: discountRule,
 	}, nil
 }
 
-func (s *BackendService) InsertAttendancesHandler(ctx context.Context, req *output.InsertAttendancesRequest) (*output.InsertAttendancesResponse, errs.HTTPError) {
+func (s *BackendService) InsertDiscountRulesHandler(ctx context.Context, req *output.InsertDiscountRulesRequest) (*output.InsertDiscountRulesResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	specs := make([]entity.InsertAttendanceSpec, 0, len(req.Data))
+	specs := make([]entity.InsertDiscountRuleSpec, 0, len(req.Data))
 	for _, param := range req.Data {
-		specs = append(specs, entity.InsertAttendanceSpec{
-			ClassID:                param.ClassID,
-			TeacherID:              param.TeacherID,
-			StudentID:              param.StudentID,
-			StudentLearningTokenID: param.StudentLearningTokenID,
-			Date:                   param.Date,
-			UsedStudentTokenQuota:  param.UsedStudentTokenQuota,
-			Duration:               param.Duration,
-			Note:                   param.Note,
+		specs = append(specs, entity.InsertDiscountRuleSpec{
+			Name:          param.Name,
+			Type:          param.Type,
+			IsPercentage:  param.IsPercentage,
+			Value:         param.Value,
+			MinCount:      param.MinCount,
+			MaxDayOfMonth: param.MaxDayOfMonth,
+			VoucherCode:   param.VoucherCode,
+			UsageLimit:    param.UsageLimit,
+			ValidFrom:     param.ValidFrom,
+			ValidUntil:    param.ValidUntil,
+			IsDeactivated: param.IsDeactivated,
 		})
 	}
 
-	attendanceIDs, err := s.entityService.InsertAttendances(ctx, specs)
+	discountRuleIDs, err := s.entityService.InsertDiscountRules(ctx, specs)
 	if err != nil {
-		return nil, handleUpsertionError(err, "entityService.InsertAttendances()", "attendance")
+		return nil, handleUpsertionError(err, "entityService.InsertDiscountRules()", "discountRule")
 	}
-	mainLog.Info("Attendances created: attendanceIDs='%v'", attendanceIDs)
+	mainLog.Info("DiscountRules created: discountRuleIDs='%v'", discountRuleIDs)
 
-	attendances, err := s.entityService.GetAttendancesByIds(ctx, attendanceIDs)
+	discountRules, err := s.entityService.GetDiscountRulesByIds(ctx, discountRuleIDs)
 	if err != nil {
-		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetAttendancesByIds: %v", err), nil, "")
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetDiscountRulesByIds: %v", err), nil, "")
 	}
 
-	return &output.InsertAttendancesResponse{
-		Data: output.UpsertAttendanceResult{
-			Results: attendances,
+	return &output.InsertDiscountRulesResponse{
+		Data: output.UpsertDiscountRuleResult{
+			Results: discountRules,
 		},
-		Message: "Successfully created attendances",
+		Message: "Successfully created discountRules",
 	}, nil
 }
 
-func (s *BackendService) UpdateAttendancesHandler(ctx context.Context, req *output.UpdateAttendancesRequest) (*output.UpdateAttendancesResponse, errs.HTTPError) {
+func (s *BackendService) UpdateDiscountRulesHandler(ctx context.Context, req *output.UpdateDiscountRulesRequest) (*output.UpdateDiscountRulesResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	specs := make([]entity.UpdateAttendanceSpec, 0, len(req.Data))
+	specs := make([]entity.UpdateDiscountRuleSpec, 0, len(req.Data))
 	for _, param := range req.Data {
-		specs = append(specs, entity.UpdateAttendanceSpec{
-			AttendanceID:           param.AttendanceID,
-			ClassID:                param.ClassID,
-			TeacherID:              param.TeacherID,
-			StudentID:              param.StudentID,
-			StudentLearningTokenID: param.StudentLearningTokenID,
-			Date:                   param.Date,
-			UsedStudentTokenQuota:  param.UsedStudentTokenQuota,
-			Duration:               param.Duration,
-			Note:                   param.Note,
-			IsPaid:                 param.IsPaid,
+		specs = append(specs, entity.UpdateDiscountRuleSpec{
+			DiscountRuleID: param.DiscountRuleID,
+			Name:           param.Name,
+			Type:           param.Type,
+			IsPercentage:   param.IsPercentage,
+			Value:          param.Value,
+			MinCount:       param.MinCount,
+			MaxDayOfMonth:  param.MaxDayOfMonth,
+			VoucherCode:    param.VoucherCode,
+			UsageLimit:     param.UsageLimit,
+			ValidFrom:      param.ValidFrom,
+			ValidUntil:     param.ValidUntil,
+			IsDeactivated:  param.IsDeactivated,
 		})
 	}
 
-	attendanceIDs, err := s.entityService.UpdateAttendances(ctx, specs)
+	discountRuleIDs, err := s.entityService.UpdateDiscountRules(ctx, specs)
 	if err != nil {
-		return nil, handleUpsertionError(err, "entityService.UpdateAttendances()", "attendance")
+		return nil, handleUpsertionError(err, "entityService.UpdateDiscountRules()", "discountRule")
 	}
-	mainLog.Info("Attendances updated: attendanceIDs='%v'", attendanceIDs)
+	mainLog.Info("DiscountRules updated: discountRuleIDs='%v'", discountRuleIDs)
 
-	attendances, err := s.entityService.GetAttendancesByIds(ctx, attendanceIDs)
+	discountRules, err := s.entityService.GetDiscountRulesByIds(ctx, discountRuleIDs)
 	if err != nil {
-		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetAttendancesByIds: %v", err), nil, "")
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetDiscountRulesByIds: %v", err), nil, "")
 	}
 
-	return &output.UpdateAttendancesResponse{
-		Data: output.UpsertAttendanceResult{
-			Results: attendances,
+	return &output.UpdateDiscountRulesResponse{
+		Data: output.UpsertDiscountRuleResult{
+			Results: discountRules,
 		},
-		Message: "Successfully updated attendances",
+		Message: "Successfully updated discountRules",
 	}, nil
 }
 
-func (s *BackendService) DeleteAttendancesHandler(ctx context.Context, req *output.DeleteAttendancesRequest) (*output.DeleteAttendancesResponse, errs.HTTPError) {
+func (s *BackendService) DeleteDiscountRulesHandler(ctx context.Context, req *output.DeleteDiscountRulesRequest) (*output.DeleteDiscountRulesResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	ids := make([]entity.AttendanceID, 0, len(req.Data))
+	ids := make([]entity.DiscountRuleID, 0, len(req.Data))
 	for _, param := range req.Data {
-		ids = append(ids, param.AttendanceID)
+		ids = append(ids, param.DiscountRuleID)
 	}
 
-	err := s.entityService.DeleteAttendances(ctx, ids)
+	err := s.entityService.DeleteDiscountRules(ctx, ids)
+	if err != nil {
+		return nil, handleDeletionError(err, "entityService.DeleteDiscountRules()", "discountRule")
+	}
+
+	return &output.DeleteDiscountRulesResponse{
+		Message: "Successfully deleted discountRules",
+	}, nil
+}
+
+func (s *BackendService) GetTeacherFeeSharingsHandler(ctx context.Context, req *output.GetTeacherFeeSharingsRequest) (*output.GetTeacherFeeSharingsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	getTeacherFeeSharingsResult, err := s.entityService.GetTeacherFeeSharings(ctx, util.PaginationSpec((req.PaginationRequest)))
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetTeacherFeeSharings(): %w", err), nil, "Failed to get teacherFeeSharings")
+	}
+
+	paginationResponse := output.NewPaginationResponse(getTeacherFeeSharingsResult.PaginationResult)
+
+	return &output.GetTeacherFeeSharingsResponse{
+		Data: output.GetTeacherFeeSharingsResult{
+			Results:            getTeacherFeeSharingsResult.TeacherFeeSharings,
+			PaginationResponse: paginationResponse,
+		},
+	}, nil
+}
+
+func (s *BackendService) GetTeacherFeeSharingByIdHandler(ctx context.Context, req *output.GetTeacherFeeSharingRequest) (*output.GetTeacherFeeSharingResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	teacherFeeSharing, err := s.entityService.GetTeacherFeeSharingById(ctx, req.TeacherFeeSharingID)
+	if err != nil {
+		return nil, handleReadError(err, "entityService.GetTeacherFeeSharingById()", "teacherFeeSharing")
+	}
+
+	return &output.GetTeacherFeeSharingResponse{
+		Data: teacherFeeSharing,
+	}, nil
+}
+
+func (s *BackendService) InsertTeacherFeeSharingsHandler(ctx context.Context, req *output.InsertTeacherFeeSharingsRequest) (*output.InsertTeacherFeeSharingsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	specs := make([]entity.InsertTeacherFeeSharingSpec, 0, len(req.Data))
+	for _, param := range req.Data {
+		specs = append(specs, entity.InsertTeacherFeeSharingSpec{
+			TeacherID:                     param.TeacherID,
+			CourseID:                      param.CourseID,
+			CourseFeeSharingPercentage:    param.CourseFeeSharingPercentage,
+			TransportFeeSharingPercentage: param.TransportFeeSharingPercentage,
+			EffectiveFrom:                 param.EffectiveFrom,
+		})
+	}
+
+	teacherFeeSharingIDs, err := s.entityService.InsertTeacherFeeSharings(ctx, specs)
+	if err != nil {
+		return nil, handleUpsertionError(err, "entityService.InsertTeacherFeeSharings()", "teacherFeeSharing")
+	}
+	mainLog.Info("TeacherFeeSharings created: teacherFeeSharingIDs='%v'", teacherFeeSharingIDs)
+
+	teacherFeeSharings, err := s.entityService.GetTeacherFeeSharingsByIds(ctx, teacherFeeSharingIDs)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetTeacherFeeSharingsByIds: %v", err), nil, "")
+	}
+
+	return &output.InsertTeacherFeeSharingsResponse{
+		Data: output.UpsertTeacherFeeSharingResult{
+			Results: teacherFeeSharings,
+		},
+		Message: "Successfully created teacherFeeSharings",
+	}, nil
+}
+
+func (s *BackendService) UpdateTeacherFeeSharingsHandler(ctx context.Context, req *output.UpdateTeacherFeeSharingsRequest) (*output.UpdateTeacherFeeSharingsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	specs := make([]entity.UpdateTeacherFeeSharingSpec, 0, len(req.Data))
+	for _, param := range req.Data {
+		specs = append(specs, entity.UpdateTeacherFeeSharingSpec{
+			TeacherFeeSharingID:           param.TeacherFeeSharingID,
+			TeacherID:                     param.TeacherID,
+			CourseID:                      param.CourseID,
+			CourseFeeSharingPercentage:    param.CourseFeeSharingPercentage,
+			TransportFeeSharingPercentage: param.TransportFeeSharingPercentage,
+			EffectiveFrom:                 param.EffectiveFrom,
+		})
+	}
+
+	teacherFeeSharingIDs, err := s.entityService.UpdateTeacherFeeSharings(ctx, specs)
+	if err != nil {
+		return nil, handleUpsertionError(err, "entityService.UpdateTeacherFeeSharings()", "teacherFeeSharing")
+	}
+	mainLog.Info("TeacherFeeSharings updated: teacherFeeSharingIDs='%v'", teacherFeeSharingIDs)
+
+	teacherFeeSharings, err := s.entityService.GetTeacherFeeSharingsByIds(ctx, teacherFeeSharingIDs)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetTeacherFeeSharingsByIds: %v", err), nil, "")
+	}
+
+	return &output.UpdateTeacherFeeSharingsResponse{
+		Data: output.UpsertTeacherFeeSharingResult{
+			Results: teacherFeeSharings,
+		},
+		Message: "Successfully updated teacherFeeSharings",
+	}, nil
+}
+
+func (s *BackendService) DeleteTeacherFeeSharingsHandler(ctx context.Context, req *output.DeleteTeacherFeeSharingsRequest) (*output.DeleteTeacherFeeSharingsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	ids := make([]entity.TeacherFeeSharingID, 0, len(req.Data))
+	for _, param := range req.Data {
+		ids = append(ids, param.TeacherFeeSharingID)
+	}
+
+	err := s.entityService.DeleteTeacherFeeSharings(ctx, ids)
+	if err != nil {
+		return nil, handleDeletionError(err, "entityService.DeleteTeacherFeeSharings()", "teacherFeeSharing")
+	}
+
+	return &output.DeleteTeacherFeeSharingsResponse{
+		Message: "Successfully deleted teacherFeeSharings",
+	}, nil
+}
+
+func (s *BackendService) GetEnrollmentPaymentsHandler(ctx context.Context, req *output.GetEnrollmentPaymentsRequest) (*output.GetEnrollmentPaymentsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	paginationSpec := util.PaginationSpec(req.PaginationRequest)
+	timeFilter := util.TimeSpec(req.TimeFilter)
+	getEnrollmentPaymentsResult, err := s.entityService.GetEnrollmentPayments(ctx, paginationSpec, timeFilter, false)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetEnrollmentPayments(): %w", err), nil, "Failed to get courses")
+	}
+
+	paginationResponse := output.NewPaginationResponse(getEnrollmentPaymentsResult.PaginationResult)
+
+	return &output.GetEnrollmentPaymentsResponse{
+		Data: output.GetEnrollmentPaymentsResult{
+			Results:            getEnrollmentPaymentsResult.EnrollmentPayments,
+			PaginationResponse: paginationResponse,
+		},
+	}, nil
+}
+
+func (s *BackendService) GetEnrollmentPaymentByIdHandler(ctx context.Context, req *output.GetEnrollmentPaymentRequest) (*output.GetEnrollmentPaymentResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	enrollmentPayment, err := s.entityService.GetEnrollmentPaymentById(ctx, req.EnrollmentPaymentID)
+	if err != nil {
+		return nil, handleReadError(err, "identityService.GetEnrollmentPaymentById()", "enrollmentPayment")
+	}
+
+	return &output.GetEnrollmentPaymentResponse{
+		Data: enrollmentPayment,
+	}, nil
+}
+
+func (s *BackendService) InsertEnrollmentPaymentsHandler(ctx context.Context, req *output.InsertEnrollmentPaymentsRequest) (*output.InsertEnrollmentPaymentsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	specs := make([]entity.InsertEnrollmentPaymentSpec, 0, len(req.Data))
+	for _, param := range req.Data {
+		specs = append(specs, entity.InsertEnrollmentPaymentSpec{
+			StudentEnrollmentID: param.StudentEnrollmentID,
+			PaymentDate:         param.PaymentDate,
+			BalanceTopUp:        param.BalanceTopUp,
+			BalanceBonus:        param.BalanceBonus,
+			CourseFeeValue:      param.CourseFeeValue,
+			TransportFeeValue:   param.TransportFeeValue,
+			PenaltyFeeValue:     param.PenaltyFeeValue,
+			DiscountFeeValue:    param.DiscountFeeValue,
+			PaymentMethod:       param.PaymentMethod,
+			ReceivingAccount:    param.ReceivingAccount,
+			ReferenceNumber:     param.ReferenceNumber,
+		})
+	}
+
+	enrollmentPaymentIDs, err := s.entityService.InsertEnrollmentPayments(ctx, specs)
+	if err != nil {
+		return nil, handleUpsertionError(err, "entityService.InsertEnrollmentPayments()", "enrollmentPayment")
+	}
+	mainLog.Info("EnrollmentPayments created: enrollmentPaymentIDs='%v'", enrollmentPaymentIDs)
+
+	enrollmentPayments, err := s.entityService.GetEnrollmentPaymentsByIds(ctx, enrollmentPaymentIDs)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetEnrollmentPaymentsByIds: %v", err), nil, "")
+	}
+
+	return &output.InsertEnrollmentPaymentsResponse{
+		Data: output.UpsertEnrollmentPaymentResult{
+			Results: enrollmentPayments,
+		},
+		Message: "Successfully created enrollmentPayments",
+	}, nil
+}
+
+func (s *BackendService) UpdateEnrollmentPaymentsHandler(ctx context.Context, req *output.UpdateEnrollmentPaymentsRequest) (*output.UpdateEnrollmentPaymentsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	specs := make([]entity.UpdateEnrollmentPaymentSpec, 0, len(req.Data))
+	for _, param := range req.Data {
+		specs = append(specs, entity.UpdateEnrollmentPaymentSpec{
+			EnrollmentPaymentID: param.EnrollmentPaymentID,
+			PaymentDate:         param.PaymentDate,
+			BalanceTopUp:        param.BalanceTopUp,
+			BalanceBonus:        param.BalanceBonus,
+			CourseFeeValue:      param.CourseFeeValue,
+			TransportFeeValue:   param.TransportFeeValue,
+			PenaltyFeeValue:     param.PenaltyFeeValue,
+			DiscountFeeValue:    param.DiscountFeeValue,
+			PaymentMethod:       param.PaymentMethod,
+			ReceivingAccount:    param.ReceivingAccount,
+			ReferenceNumber:     param.ReferenceNumber,
+		})
+	}
+
+	enrollmentPaymentIDs, err := s.entityService.UpdateEnrollmentPayments(ctx, specs)
+	if err != nil {
+		return nil, handleUpsertionError(err, "entityService.UpdateEnrollmentPayments()", "enrollmentPayment")
+	}
+	mainLog.Info("EnrollmentPayments updated: enrollmentPaymentIDs='%v'", enrollmentPaymentIDs)
+
+	enrollmentPayments, err := s.entityService.GetEnrollmentPaymentsByIds(ctx, enrollmentPaymentIDs)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetEnrollmentPaymentsByIds: %v", err), nil, "")
+	}
+
+	return &output.UpdateEnrollmentPaymentsResponse{
+		Data: output.UpsertEnrollmentPaymentResult{
+			Results: enrollmentPayments,
+		},
+		Message: "Successfully updated enrollmentPayments",
+	}, nil
+}
+
+func (s *BackendService) DeleteEnrollmentPaymentsHandler(ctx context.Context, req *output.DeleteEnrollmentPaymentsRequest) (*output.DeleteEnrollmentPaymentsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	ids := make([]entity.EnrollmentPaymentID, 0, len(req.Data))
+	for _, param := range req.Data {
+		ids = append(ids, param.EnrollmentPaymentID)
+	}
+
+	err := s.entityService.DeleteEnrollmentPayments(ctx, ids)
+	if err != nil {
+		return nil, handleDeletionError(err, "identityService.DeleteEnrollmentPayments()", "enrollmentPayment")
+	}
+
+	return &output.DeleteEnrollmentPaymentsResponse{
+		Message: "Successfully deleted enrollmentPayments",
+	}, nil
+}
+
+func (s *BackendService) GetStudentLearningTokensHandler(ctx context.Context, req *output.GetStudentLearningTokensRequest) (*output.GetStudentLearningTokensResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	getStudentLearningTokensResult, err := s.entityService.GetStudentLearningTokens(ctx, util.PaginationSpec(req.PaginationRequest))
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetStudentLearningTokens(): %w", err), nil, "Failed to get courses")
+	}
+
+	paginationResponse := output.NewPaginationResponse(getStudentLearningTokensResult.PaginationResult)
+
+	return &output.GetStudentLearningTokensResponse{
+		Data: output.GetStudentLearningTokensResult{
+			Results:            getStudentLearningTokensResult.StudentLearningTokens,
+			PaginationResponse: paginationResponse,
+		},
+	}, nil
+}
+
+func (s *BackendService) GetStudentLearningTokenByIdHandler(ctx context.Context, req *output.GetStudentLearningTokenRequest) (*output.GetStudentLearningTokenResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	studentLearningToken, err := s.entityService.GetStudentLearningTokenById(ctx, req.StudentLearningTokenID)
+	if err != nil {
+		return nil, handleReadError(err, "identityService.GetStudentLearningTokenById()", "studentLearningToken")
+	}
+
+	return &output.GetStudentLearningTokenResponse{
+		Data: studentLearningToken,
+	}, nil
+}
+
+func (s *BackendService) InsertStudentLearningTokensHandler(ctx context.Context, req *output.InsertStudentLearningTokensRequest) (*output.InsertStudentLearningTokensResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	specs := make([]entity.InsertStudentLearningTokenSpec, 0, len(req.Data))
+	for _, param := range req.Data {
+		specs = append(specs, entity.InsertStudentLearningTokenSpec{
+			StudentEnrollmentID:      param.StudentEnrollmentID,
+			Quota:                    param.Quota,
+			CourseFeeQuarterValue:    param.CourseFeeQuarterValue,
+			TransportFeeQuarterValue: param.TransportFeeQuarterValue,
+		})
+	}
+
+	studentLearningTokenIDs, err := s.entityService.InsertStudentLearningTokens(ctx, specs)
+	if err != nil {
+		return nil, handleUpsertionError(err, "entityService.InsertStudentLearningTokens()", "studentLearningToken")
+	}
+	mainLog.Info("StudentLearningTokens created: studentLearningTokenIDs='%v'", studentLearningTokenIDs)
+
+	studentLearningTokens, err := s.entityService.GetStudentLearningTokensByIds(ctx, studentLearningTokenIDs)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetStudentLearningTokensByIds: %v", err), nil, "")
+	}
+
+	return &output.InsertStudentLearningTokensResponse{
+		Data: output.UpsertStudentLearningTokenResult{
+			Results: studentLearningTokens,
+		},
+		Message: "Successfully created studentLearningTokens",
+	}, nil
+}
+
+func (s *BackendService) UpdateStudentLearningTokensHandler(ctx context.Context, req *output.UpdateStudentLearningTokensRequest) (*output.UpdateStudentLearningTokensResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	specs := make([]entity.UpdateStudentLearningTokenSpec, 0, len(req.Data))
+	for _, param := range req.Data {
+		specs = append(specs, entity.UpdateStudentLearningTokenSpec{
+			StudentLearningTokenID:   param.StudentLearningTokenID,
+			Quota:                    param.Quota,
+			CourseFeeQuarterValue:    param.CourseFeeQuarterValue,
+			TransportFeeQuarterValue: param.TransportFeeQuarterValue,
+		})
+	}
+
+	studentLearningTokenIDs, err := s.entityService.UpdateStudentLearningTokens(ctx, specs)
+	if err != nil {
+		return nil, handleUpsertionError(err, "entityService.UpdateStudentLearningTokens()", "studentLearningToken")
+	}
+	mainLog.Info("StudentLearningTokens updated: studentLearningTokenIDs='%v'", studentLearningTokenIDs)
+
+	studentLearningTokens, err := s.entityService.GetStudentLearningTokensByIds(ctx, studentLearningTokenIDs)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetStudentLearningTokensByIds: %v", err), nil, "")
+	}
+
+	return &output.UpdateStudentLearningTokensResponse{
+		Data: output.UpsertStudentLearningTokenResult{
+			Results: studentLearningTokens,
+		},
+		Message: "Successfully updated studentLearningTokens",
+	}, nil
+}
+
+func (s *BackendService) DeleteStudentLearningTokensHandler(ctx context.Context, req *output.DeleteStudentLearningTokensRequest) (*output.DeleteStudentLearningTokensResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	ids := make([]entity.StudentLearningTokenID, 0, len(req.Data))
+	for _, param := range req.Data {
+		ids = append(ids, param.StudentLearningTokenID)
+	}
+
+	err := s.entityService.DeleteStudentLearningTokens(ctx, ids)
+	if err != nil {
+		return nil, handleDeletionError(err, "identityService.DeleteStudentLearningTokens()", "studentLearningToken")
+	}
+
+	return &output.DeleteStudentLearningTokensResponse{
+		Message: "Successfully deleted studentLearningTokens",
+	}, nil
+}
+
+func (s *BackendService) GetAttendancesHandler(ctx context.Context, req *output.GetAttendancesRequest) (*output.GetAttendancesResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	paginationSpec := util.PaginationSpec(req.PaginationRequest)
+	getAttendancesSpec := entity.GetAttendancesSpec{
+		TimeSpec: util.TimeSpec(req.TimeFilter),
+	}
+	getAttendancesResult, err := s.entityService.GetAttendances(ctx, paginationSpec, getAttendancesSpec, false)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetAttendances(): %w", err), nil, "Failed to get courses")
+	}
+
+	paginationResponse := output.NewPaginationResponse(getAttendancesResult.PaginationResult)
+
+	return &output.GetAttendancesResponse{
+		Data: output.GetAttendancesResult{
+			Results:            getAttendancesResult.Attendances,
+			PaginationResponse: paginationResponse,
+		},
+	}, nil
+}
+
+func (s *BackendService) GetAttendanceByIdHandler(ctx context.Context, req *output.GetAttendanceRequest) (*output.GetAttendanceResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	attendance, err := s.entityService.GetAttendanceById(ctx, req.AttendanceID)
+	if err != nil {
+		return nil, handleReadError(err, "identityService.GetAttendanceById()", "attendance")
+	}
+
+	return &output.GetAttendanceResponse{
+		Data: attendance,
+	}, nil
+}
+
+func (s *BackendService) InsertAttendancesHandler(ctx context.Context, req *output.InsertAttendancesRequest) (*output.InsertAttendancesResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	specs := make([]entity.InsertAttendanceSpec, 0, len(req.Data))
+	for _, param := range req.Data {
+		specs = append(specs, entity.InsertAttendanceSpec{
+			ClassID:                param.ClassID,
+			TeacherID:              param.TeacherID,
+			StudentID:              param.StudentID,
+			StudentLearningTokenID: param.StudentLearningTokenID,
+			Date:                   param.Date,
+			UsedStudentTokenQuota:  param.UsedStudentTokenQuota,
+			Duration:               param.Duration,
+			Note:                   param.Note,
+		})
+	}
+
+	attendanceIDs, err := s.entityService.InsertAttendances(ctx, specs)
+	if err != nil {
+		return nil, handleUpsertionError(err, "entityService.InsertAttendances()", "attendance")
+	}
+	mainLog.Info("Attendances created: attendanceIDs='%v'", attendanceIDs)
+
+	attendances, err := s.entityService.GetAttendancesByIds(ctx, attendanceIDs)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetAttendancesByIds: %v", err), nil, "")
+	}
+
+	return &output.InsertAttendancesResponse{
+		Data: output.UpsertAttendanceResult{
+			Results: attendances,
+		},
+		Message: "Successfully created attendances",
+	}, nil
+}
+
+func (s *BackendService) UpdateAttendancesHandler(ctx context.Context, req *output.UpdateAttendancesRequest) (*output.UpdateAttendancesResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	specs := make([]entity.UpdateAttendanceSpec, 0, len(req.Data))
+	for _, param := range req.Data {
+		specs = append(specs, entity.UpdateAttendanceSpec{
+			AttendanceID:           param.AttendanceID,
+			ClassID:                param.ClassID,
+			TeacherID:              param.TeacherID,
+			StudentID:              param.StudentID,
+			StudentLearningTokenID: param.StudentLearningTokenID,
+			Date:                   param.Date,
+			UsedStudentTokenQuota:  param.UsedStudentTokenQuota,
+			Duration:               param.Duration,
+			Note:                   param.Note,
+			IsPaid:                 param.IsPaid,
+		})
+	}
+
+	attendanceIDs, err := s.entityService.UpdateAttendances(ctx, specs)
+	if err != nil {
+		return nil, handleUpsertionError(err, "entityService.UpdateAttendances()", "attendance")
+	}
+	mainLog.Info("Attendances updated: attendanceIDs='%v'", attendanceIDs)
+
+	attendances, err := s.entityService.GetAttendancesByIds(ctx, attendanceIDs)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetAttendancesByIds: %v", err), nil, "")
+	}
+
+	return &output.UpdateAttendancesResponse{
+		Data: output.UpsertAttendanceResult{
+			Results: attendances,
+		},
+		Message: "Successfully updated attendances",
+	}, nil
+}
+
+func (s *BackendService) DeleteAttendancesHandler(ctx context.Context, req *output.DeleteAttendancesRequest) (*output.DeleteAttendancesResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	ids := make([]entity.AttendanceID, 0, len(req.Data))
+	for _, param := range req.Data {
+		ids = append(ids, param.AttendanceID)
+	}
+
+	err := s.entityService.DeleteAttendances(ctx, ids)
+	if err != nil {
+		return nil, handleDeletionError(err, "identityService.DeleteAttendances()", "attendance")
+	}
+
+	return &output.DeleteAttendancesResponse{
+		Message: "Successfully deleted attendances",
+	}, nil
+}
+
+func (s *BackendService) GetTeacherPaymentsHandler(ctx context.Context, req *output.GetTeacherPaymentsRequest) (*output.GetTeacherPaymentsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	paginationSpec := util.PaginationSpec(req.PaginationRequest)
+	getTeacherPaymentsSpec := entity.GetTeacherPaymentsSpec{
+		TimeSpec: util.TimeSpec(req.TimeFilter),
+	}
+	getTeacherPaymentsResult, err := s.entityService.GetTeacherPayments(ctx, paginationSpec, getTeacherPaymentsSpec)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetTeacherPayments(): %w", err), nil, "Failed to get teacherPayments")
+	}
+
+	paginationResponse := output.NewPaginationResponse(getTeacherPaymentsResult.PaginationResult)
+
+	return &output.GetTeacherPaymentsResponse{
+		Data: output.GetTeacherPaymentsResult{
+			Results:            getTeacherPaymentsResult.TeacherPayments,
+			PaginationResponse: paginationResponse,
+		},
+	}, nil
+}
+
+func (s *BackendService) GetClosedPeriodsHandler(ctx context.Context, req *output.GetClosedPeriodsRequest) (*output.GetClosedPeriodsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	getClosedPeriodsResult, err := s.entityService.GetClosedPeriods(ctx, util.PaginationSpec(req.PaginationRequest))
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetClosedPeriods(): %w", err), nil, "Failed to get closedPeriods")
+	}
+
+	paginationResponse := output.NewPaginationResponse(getClosedPeriodsResult.PaginationResult)
+
+	return &output.GetClosedPeriodsResponse{
+		Data: output.GetClosedPeriodsResult{
+			Results:            getClosedPeriodsResult.ClosedPeriods,
+			PaginationResponse: paginationResponse,
+		},
+	}, nil
+}
+
+func (s *BackendService) GetClosedPeriodByIdHandler(ctx context.Context, req *output.GetClosedPeriodRequest) (*output.GetClosedPeriodResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	closedPeriod, err := s.entityService.GetClosedPeriodById(ctx, req.ClosedPeriodID)
+	if err != nil {
+		return nil, handleReadError(err, "entityService.GetClosedPeriodById()", "closedPeriod")
+	}
+
+	return &output.GetClosedPeriodResponse{
+		Data: closedPeriod,
+	}, nil
+}
+
+func (s *BackendService) InsertClosedPeriodsHandler(ctx context.Context, req *output.InsertClosedPeriodsRequest) (*output.InsertClosedPeriodsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	specs := make([]entity.InsertClosedPeriodSpec, 0, len(req.Data))
+	for _, param := range req.Data {
+		specs = append(specs, entity.InsertClosedPeriodSpec{
+			ClosedUntil: param.ClosedUntil,
+			Note:        param.Note,
+		})
+	}
+
+	closedPeriodIDs, err := s.entityService.InsertClosedPeriods(ctx, specs)
+	if err != nil {
+		return nil, handleUpsertionError(err, "entityService.InsertClosedPeriods()", "closedPeriod")
+	}
+	mainLog.Info("ClosedPeriods created: closedPeriodIDs='%v'", closedPeriodIDs)
+
+	closedPeriods := make([]entity.ClosedPeriod, 0, len(closedPeriodIDs))
+	for _, closedPeriodID := range closedPeriodIDs {
+		closedPeriod, err := s.entityService.GetClosedPeriodById(ctx, closedPeriodID)
+		if err != nil {
+			return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetClosedPeriodById: %v", err), nil, "")
+		}
+		closedPeriods = append(closedPeriods, closedPeriod)
+	}
+
+	return &output.InsertClosedPeriodsResponse{
+		Data: output.UpsertClosedPeriodResult{
+			Results: closedPeriods,
+		},
+		Message: "Successfully created closedPeriods",
+	}, nil
+}
+
+func (s *BackendService) DeleteClosedPeriodsHandler(ctx context.Context, req *output.DeleteClosedPeriodsRequest) (*output.DeleteClosedPeriodsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	ids := make([]entity.ClosedPeriodID, 0, len(req.Data))
+	for _, param := range req.Data {
+		ids = append(ids, param.ClosedPeriodID)
+	}
+
+	err := s.entityService.DeleteClosedPeriods(ctx, ids)
+	if err != nil {
+		return nil, handleDeletionError(err, "entityService.DeleteClosedPeriods()", "closedPeriod")
+	}
+	mainLog.Info("ClosedPeriods deleted: closedPeriodIDs='%v'", ids)
+
+	return &output.DeleteClosedPeriodsResponse{
+		Message: "Successfully deleted closedPeriods",
+	}, nil
+}
+
+func (s *BackendService) GetClosedPeriodOverridesHandler(ctx context.Context, req *output.GetClosedPeriodOverridesRequest) (*output.GetClosedPeriodOverridesResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	getClosedPeriodOverridesResult, err := s.entityService.GetClosedPeriodOverrides(ctx, util.PaginationSpec(req.PaginationRequest))
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetClosedPeriodOverrides(): %w", err), nil, "Failed to get closedPeriodOverrides")
+	}
+
+	paginationResponse := output.NewPaginationResponse(getClosedPeriodOverridesResult.PaginationResult)
+
+	return &output.GetClosedPeriodOverridesResponse{
+		Data: output.GetClosedPeriodOverridesResult{
+			Results:            getClosedPeriodOverridesResult.ClosedPeriodOverrides,
+			PaginationResponse: paginationResponse,
+		},
+	}, nil
+}
+
+func (s *BackendService) EditClassesConfigsHandler(ctx context.Context, req *output.EditClassesConfigsRequest) (*output.EditClassesConfigsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	specs := make([]teaching.EditClassConfigSpec, 0, len(req.Data))
+	for _, param := range req.Data {
+		specs = append(specs, teaching.EditClassConfigSpec{
+			ClassID:                param.ClassID,
+			IsDeactivated:          param.IsDeactivated,
+			AutoOweAttendanceToken: param.AutoOweAttendanceToken,
+		})
+	}
+
+	err := s.teachingService.EditClassesConfigs(ctx, specs)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("teachingService.EditClassesConfigs(): %w", err), nil, "Failed to get courses")
+	}
+
+	return &output.EditClassesConfigsResponse{
+		Message: "Successfully edit classes configs",
+	}, nil
+}
+
+func (s *BackendService) EditClassesCoursesHandler(ctx context.Context, req *output.EditClassesCoursesRequest) (*output.EditClassesCoursesResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	specs := make([]teaching.EditClassCourseSpec, 0, len(req.Data))
+	for _, param := range req.Data {
+		specs = append(specs, teaching.EditClassCourseSpec{
+			ClassID:  param.ClassID,
+			CourseID: param.CourseID,
+		})
+	}
+
+	err := s.teachingService.EditClassesCourses(ctx, specs)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("teachingService.EditClassesCourses(): %w", err), nil, "Failed to get courses")
+	}
+
+	return &output.EditClassesCoursesResponse{
+		Message: "Successfully edit classes courses",
+	}, nil
+}
+
+func (s *BackendService) SearchEnrollmentPaymentHandler(ctx context.Context, req *output.SearchEnrollmentPaymentsRequest) (*output.SearchEnrollmentPaymentsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	timeFilter := util.TimeSpec(req.TimeFilter)
+	enrollmentPayments, err := s.teachingService.SearchEnrollmentPayment(ctx, timeFilter)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("teachingService.SearchEnrollmentPayments(): %w", err), nil, "Failed to get courses")
+	}
+
+	return &output.SearchEnrollmentPaymentsResponse{
+		Data: output.SearchEnrollmentPaymentsResult{
+			Results: enrollmentPayments,
+		},
+	}, nil
+}
+
+func (s *BackendService) GetEnrollmentPaymentInvoiceHandler(ctx context.Context, req *output.GetEnrollmentPaymentInvoiceRequest) (*output.GetEnrollmentPaymentInvoiceResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	paymentInvoice, err := s.teachingService.GetEnrollmentPaymentInvoice(ctx, req.StudentEnrollmentID, req.PaymentDate, req.VoucherCode)
+	if err != nil {
+		errContext := fmt.Errorf("teachingService.GetEnrollmentPaymentInvoice(): %w", err)
+		if errors.Is(err, errs.ErrVoucherNotFound) {
+			return nil, errs.NewHTTPError(http.StatusNotFound, errContext, map[string]string{"voucherCode": "voucherCode doesn't exist"}, "Voucher is not found")
+		}
+		if errors.Is(err, errs.ErrDiscountRuleNotApplicable) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, map[string]string{"voucherCode": "voucherCode is expired or has been used up"}, "The voucher cannot be applied to this payment")
+		}
+		return nil, handleReadError(err, "teachingService.GetEnrollmentPaymentInvoice()", "studentEnrollment")
+	}
+
+	return &output.GetEnrollmentPaymentInvoiceResponse{
+		Data: paymentInvoice,
+	}, nil
+}
+
+func (s *BackendService) SubmitEnrollmentPaymentHandler(ctx context.Context, req *output.SubmitEnrollmentPaymentRequest) (*output.SubmitEnrollmentPaymentResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	spec := teaching.SubmitStudentEnrollmentPaymentSpec{
+		StudentEnrollmentID: req.StudentEnrollmentID,
+		PaymentDate:         req.PaymentDate,
+		BalanceTopUp:        req.BalanceTopUp,
+		BalanceBonus:        req.BalanceBonus,
+		CourseFeeValue:      req.CourseFeeValue,
+		TransportFeeValue:   req.TransportFeeValue,
+		PenaltyFeeValue:     req.PenaltyFeeValue,
+		DiscountFeeValue:    req.DiscountFeeValue,
+		DiscountRuleIDs:     req.DiscountRuleIDs,
+		PaymentMethod:       req.PaymentMethod,
+		ReceivingAccount:    req.ReceivingAccount,
+		ReferenceNumber:     req.ReferenceNumber,
+	}
+
+	if req.DryRun {
+		preview, err := s.teachingService.PreviewSubmitEnrollmentPayment(ctx, spec)
+		if err != nil {
+			if errV := handleDiscountError(err, "teachingService.PreviewSubmitEnrollmentPayment()"); errV != nil {
+				return nil, errV
+			}
+			return nil, handleUpsertionError(err, "teachingService.PreviewSubmitEnrollmentPayment()", "enrollmentPayment")
+		}
+
+		return &output.SubmitEnrollmentPaymentResponse{
+			DryRunResult: &preview,
+			Message:      "Dry-run: enrollmentPayment is not submitted",
+		}, nil
+	}
+
+	enrollmentPaymentID, err := s.teachingService.SubmitEnrollmentPayment(ctx, spec)
+	if err != nil {
+		if errV := handleDiscountError(err, "teachingService.SubmitStudentEnrollmentPayment()"); errV != nil {
+			return nil, errV
+		}
+		return nil, handleUpsertionError(err, "teachingService.SubmitStudentEnrollmentPayment()", "enrollmentPayment")
+	}
+
+	return &output.SubmitEnrollmentPaymentResponse{
+		EnrollmentPaymentID: enrollmentPaymentID,
+		Message:             "Successfully submitted enrollmentPayment",
+	}, nil
+}
+
+func (s *BackendService) GetFamilyInvoiceHandler(ctx context.Context, req *output.GetFamilyInvoiceRequest) (*output.GetFamilyInvoiceResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	familyInvoice, err := s.teachingService.GetFamilyInvoice(ctx, req.FamilyID, req.PaymentDate)
+	if err != nil {
+		return nil, handleReadError(err, "teachingService.GetFamilyInvoice()", "family")
+	}
+
+	return &output.GetFamilyInvoiceResponse{
+		Data: familyInvoice,
+	}, nil
+}
+
+func (s *BackendService) SubmitFamilyPaymentHandler(ctx context.Context, req *output.SubmitFamilyPaymentRequest) (*output.SubmitFamilyPaymentResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	items := make([]teaching.SubmitFamilyPaymentItemSpec, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, teaching.SubmitFamilyPaymentItemSpec{
+			StudentEnrollmentID: item.StudentEnrollmentID,
+			BalanceTopUp:        item.BalanceTopUp,
+			BalanceBonus:        item.BalanceBonus,
+			CourseFeeValue:      item.CourseFeeValue,
+			TransportFeeValue:   item.TransportFeeValue,
+			PenaltyFeeValue:     item.PenaltyFeeValue,
+			DiscountFeeValue:    item.DiscountFeeValue,
+			DiscountRuleIDs:     item.DiscountRuleIDs,
+		})
+	}
+
+	enrollmentPaymentIDs, err := s.teachingService.SubmitFamilyPayment(ctx, teaching.SubmitFamilyPaymentSpec{
+		FamilyID:         req.FamilyID,
+		PaymentDate:      req.PaymentDate,
+		Items:            items,
+		PaymentMethod:    req.PaymentMethod,
+		ReceivingAccount: req.ReceivingAccount,
+		ReferenceNumber:  req.ReferenceNumber,
+	})
+	if err != nil {
+		errContext := fmt.Errorf("teachingService.SubmitFamilyPayment(): %w", err)
+		if errors.Is(err, errs.ErrStudentEnrollmentNotInFamily) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, map[string]string{"items": "items must only contain active studentEnrollments of the family"}, "Some studentEnrollments don't belong to the family")
+		}
+		if errV := handleDiscountError(err, "teachingService.SubmitFamilyPayment()"); errV != nil {
+			return nil, errV
+		}
+		return nil, handleUpsertionError(err, "teachingService.SubmitFamilyPayment()", "enrollmentPayment")
+	}
+	mainLog.Info("Family payment submitted: familyID='%d', enrollmentPaymentIDs='%v'", req.FamilyID, enrollmentPaymentIDs)
+
+	return &output.SubmitFamilyPaymentResponse{
+		EnrollmentPaymentIDs: enrollmentPaymentIDs,
+		Message:              "Successfully submitted family payment",
+	}, nil
+}
+
+func (s *BackendService) EditEnrollmentPaymentHandler(ctx context.Context, req *output.EditEnrollmentPaymentRequest) (*output.EditEnrollmentPaymentResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	enrollmentPaymentID, err := s.teachingService.EditEnrollmentPayment(ctx, teaching.EditStudentEnrollmentPaymentSpec{
+		EnrollmentPaymentID: req.EnrollmentPaymentID,
+		PaymentDate:         req.PaymentDate,
+		BalanceBonus:        req.BalanceBonus,
+		DiscountFeeValue:    req.DiscountFeeValue,
+	})
+	if err != nil {
+		if errV := handleDiscountError(err, "teachingService.EditEnrollmentPayment()"); errV != nil {
+			return nil, errV
+		}
+		return nil, handleReadUpsertError(err, "teachingService.EditEnrollmentPayment()", "enrollmentPayment")
+	}
+
+	enrollmentPayment, err := s.entityService.GetEnrollmentPaymentById(ctx, enrollmentPaymentID)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetEnrollmentPaymentsByIds: %v", err), nil, "")
+	}
+
+	return &output.EditEnrollmentPaymentResponse{
+		Data:    enrollmentPayment,
+		Message: "Successfully edited enrollmentPayment",
+	}, nil
+}
+
+func (s *BackendService) RemoveEnrollmentPaymentHandler(ctx context.Context, req *output.RemoveEnrollmentPaymentRequest) (*output.RemoveEnrollmentPaymentResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	err := s.teachingService.RemoveEnrollmentPayment(ctx, req.EnrollmentPaymentID)
+	if err != nil {
+		if errors.Is(err, errs.ErrEnrollmentPaymentRefunded) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("teachingService.RemoveStudentEnrollmentPayment(): %w", err), nil, "The enrollmentPayment has been refunded, and must be kept for history")
+		}
+		return nil, handleDeletionError(err, "teachingService.RemoveStudentEnrollmentPayment()", "enrollmentPayment")
+	}
+
+	return &output.RemoveEnrollmentPaymentResponse{
+		Message: "Successfully removed enrollmentPayment",
+	}, nil
+}
+
+func (s *BackendService) RefundEnrollmentPaymentHandler(ctx context.Context, req *output.RefundEnrollmentPaymentRequest) (*output.RefundEnrollmentPaymentResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	enrollmentPaymentRefundID, err := s.teachingService.RefundEnrollmentPayment(ctx, teaching.RefundEnrollmentPaymentSpec{
+		EnrollmentPaymentID: req.EnrollmentPaymentID,
+		RefundDate:          req.RefundDate,
+		RefundedValue:       req.RefundedValue,
+		WithdrawnQuota:      req.WithdrawnQuota,
+		Reason:              req.Reason,
+	})
+	if err != nil {
+		if errors.Is(err, errs.ErrRefundExceedsEnrollmentPayment) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("teachingService.RefundEnrollmentPayment(): %w", err), nil, "The total refunds exceed the enrollmentPayment's received value or topped-up quota")
+		}
+		if errors.Is(err, errs.ErrRefundBeforeEnrollmentPayment) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("teachingService.RefundEnrollmentPayment(): %w", err), map[string]string{"refundDate": "refundDate must not be before the enrollmentPayment's paymentDate"}, "The refund date is before the enrollmentPayment's payment date")
+		}
+		return nil, handleReadUpsertError(err, "teachingService.RefundEnrollmentPayment()", "enrollmentPayment")
+	}
+	mainLog.Info("EnrollmentPayment refunded: enrollmentPaymentID='%d', enrollmentPaymentRefundID='%d', refundedValue='%d', withdrawnQuota='%.2f'", req.EnrollmentPaymentID, enrollmentPaymentRefundID, req.RefundedValue, req.WithdrawnQuota)
+
+	return &output.RefundEnrollmentPaymentResponse{
+		EnrollmentPaymentRefundID: enrollmentPaymentRefundID,
+		Message:                   "Successfully refunded enrollmentPayment",
+	}, nil
+}
+
+func (s *BackendService) GetEnrollmentPaymentRefundsHandler(ctx context.Context, req *output.GetEnrollmentPaymentRefundsRequest) (*output.GetEnrollmentPaymentRefundsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	getEnrollmentPaymentRefundsResult, err := s.teachingService.GetEnrollmentPaymentRefunds(ctx, util.PaginationSpec(req.PaginationRequest))
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("teachingService.GetEnrollmentPaymentRefunds(): %w", err), nil, "Failed to get enrollmentPaymentRefunds")
+	}
+
+	paginationResponse := output.NewPaginationResponse(getEnrollmentPaymentRefundsResult.PaginationResult)
+
+	return &output.GetEnrollmentPaymentRefundsResponse{
+		Data: output.GetEnrollmentPaymentRefundsResult{
+			Results:            getEnrollmentPaymentRefundsResult.EnrollmentPaymentRefunds,
+			PaginationResponse: paginationResponse,
+		},
+	}, nil
+}
+
+func (s *BackendService) CreateInstallmentPlanHandler(ctx context.Context, req *output.CreateInstallmentPlanRequest) (*output.CreateInstallmentPlanResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	installmentPlanID, err := s.teachingService.CreateInstallmentPlan(ctx, teaching.CreateInstallmentPlanSpec{
+		StudentEnrollmentID: req.StudentEnrollmentID,
+		InvoiceDate:         req.InvoiceDate,
+		CourseCycles:        req.CourseCycles,
+		DueDates:            req.DueDates,
+	})
+	if err != nil {
+		if errors.Is(err, errs.ErrInstallmentCountExceedsQuota) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("teachingService.CreateInstallmentPlan(): %w", err), nil, "The number of installments must not exceed the invoice's balanceTopUp")
+		}
+		if errors.Is(err, errs.ErrInstallmentFeeNotSplittable) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("teachingService.CreateInstallmentPlan(): %w", err), nil, "The invoice's fees cannot be split into the installments without changing the fee per quota. Please choose another number of installments")
+		}
+		return nil, handleReadUpsertError(err, "teachingService.CreateInstallmentPlan()", "studentEnrollment")
+	}
+	mainLog.Info("InstallmentPlan created: studentEnrollmentID='%d', installmentPlanID='%d', installmentCount='%d'", req.StudentEnrollmentID, installmentPlanID, len(req.DueDates))
+
+	installmentPlan, err := s.teachingService.GetInstallmentPlanById(ctx, installmentPlanID)
+	if err != nil {
+		return nil, handleReadError(err, "teachingService.GetInstallmentPlanById()", "installmentPlan")
+	}
+
+	return &output.CreateInstallmentPlanResponse{
+		Data:    installmentPlan,
+		Message: "Successfully created installmentPlan",
+	}, nil
+}
+
+func (s *BackendService) GetInstallmentPlanHandler(ctx context.Context, req *output.GetInstallmentPlanRequest) (*output.GetInstallmentPlanResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	installmentPlan, err := s.teachingService.GetInstallmentPlanById(ctx, req.InstallmentPlanID)
+	if err != nil {
+		return nil, handleReadError(err, "teachingService.GetInstallmentPlanById()", "installmentPlan")
+	}
+
+	return &output.GetInstallmentPlanResponse{
+		Data: installmentPlan,
+	}, nil
+}
+
+func (s *BackendService) PayInstallmentHandler(ctx context.Context, req *output.PayInstallmentRequest) (*output.PayInstallmentResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	enrollmentPaymentID, err := s.teachingService.PayInstallment(ctx, teaching.PayInstallmentSpec{
+		InstallmentID:    req.InstallmentID,
+		PaymentDate:      req.PaymentDate,
+		PaymentMethod:    req.PaymentMethod,
+		ReceivingAccount: req.ReceivingAccount,
+		ReferenceNumber:  req.ReferenceNumber,
+	})
 	if err != nil {
-		return nil, handleDeletionError(err, "identityService.DeleteAttendances()", "attendance")
+		if errors.Is(err, errs.ErrInstallmentAlreadyPaid) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("teachingService.PayInstallment(): %w", err), nil, "The installment has already been paid")
+		}
+		return nil, handleReadUpsertError(err, "teachingService.PayInstallment()", "installment")
 	}
+	mainLog.Info("Installment paid: installmentID='%d', enrollmentPaymentID='%d'", req.InstallmentID, enrollmentPaymentID)
 
-	return &output.DeleteAttendancesResponse{
-		Message: "Successfully deleted attendances",
+	return &output.PayInstallmentResponse{
+		EnrollmentPaymentID: enrollmentPaymentID,
+		Message:             "Successfully paid installment",
 	}, nil
 }
 
-func (s *BackendService) GetTeacherPaymentsHandler(ctx context.Context, req *output.GetTeacherPaymentsRequest) (*output.GetTeacherPaymentsResponse, errs.HTTPError) {
+func (s *BackendService) GetOverdueInstallmentsHandler(ctx context.Context, req *output.GetOverdueInstallmentsRequest) (*output.GetOverdueInstallmentsResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	paginationSpec := util.PaginationSpec(req.PaginationRequest)
-	getTeacherPaymentsSpec := entity.GetTeacherPaymentsSpec{
-		TimeSpec: util.TimeSpec(req.TimeFilter),
-	}
-	getTeacherPaymentsResult, err := s.entityService.GetTeacherPayments(ctx, paginationSpec, getTeacherPaymentsSpec)
+	getOverdueInstallmentsResult, err := s.teachingService.GetOverdueInstallments(ctx, req.Date, util.PaginationSpec(req.PaginationRequest))
 	if err != nil {
-		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetTeacherPayments(): %w", err), nil, "Failed to get teacherPayments")
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("teachingService.GetOverdueInstallments(): %w", err), nil, "Failed to get overdue installments")
 	}
 
-	paginationResponse := output.NewPaginationResponse(getTeacherPaymentsResult.PaginationResult)
+	paginationResponse := output.NewPaginationResponse(getOverdueInstallmentsResult.PaginationResult)
 
-	return &output.GetTeacherPaymentsResponse{
-		Data: output.GetTeacherPaymentsResult{
-			Results:            getTeacherPaymentsResult.TeacherPayments,
+	return &output.GetOverdueInstallmentsResponse{
+		Data: output.GetOverdueInstallmentsResult{
+			Results:            getOverdueInstallmentsResult.OverdueInstallments,
 			PaginationResponse: paginationResponse,
 		},
 	}, nil
 }
 
-func (s *BackendService) EditClassesConfigsHandler(ctx context.Context, req *output.EditClassesConfigsRequest) (*output.EditClassesConfigsResponse, errs.HTTPError) {
+func (s *BackendService) GetEnrollmentPaymentReceiptHandler(ctx context.Context, req *output.GetEnrollmentPaymentReceiptRequest) (*output.FileResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	specs := make([]teaching.EditClassConfigSpec, 0, len(req.Data))
-	for _, param := range req.Data {
-		specs = append(specs, teaching.EditClassConfigSpec{
-			ClassID:                param.ClassID,
-			IsDeactivated:          param.IsDeactivated,
-			AutoOweAttendanceToken: param.AutoOweAttendanceToken,
-		})
+	receipt, err := s.teachingService.GetEnrollmentPaymentReceipt(ctx, req.EnrollmentPaymentID)
+	if err != nil {
+		return nil, handleReadError(err, "teachingService.GetEnrollmentPaymentReceipt()", "enrollmentPayment")
 	}
 
-	err := s.teachingService.EditClassesConfigs(ctx, specs)
+	receiptTemplate := pdf_composer.NewEnrollmentPaymentReceipt(receipt)
+	content, err := receiptTemplate.PDF()
 	if err != nil {
-		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("teachingService.EditClassesConfigs(): %w", err), nil, "Failed to get courses")
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("receiptTemplate.PDF(): %w", err), nil, "Failed to generate the receipt")
 	}
 
-	return &output.EditClassesConfigsResponse{
-		Message: "Successfully edit classes configs",
+	return &output.FileResponse{
+		FileName:    receiptTemplate.FileName(),
+		ContentType: "application/pdf",
+		Content:     content,
 	}, nil
 }
 
-func (s *BackendService) EditClassesCoursesHandler(ctx context.Context, req *output.EditClassesCoursesRequest) (*output.EditClassesCoursesResponse, errs.HTTPError) {
+func (s *BackendService) GetCashUpReportHandler(ctx context.Context, req *output.GetCashUpReportRequest) (*output.GetCashUpReportResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	specs := make([]teaching.EditClassCourseSpec, 0, len(req.Data))
-	for _, param := range req.Data {
-		specs = append(specs, teaching.EditClassCourseSpec{
-			ClassID:  param.ClassID,
-			CourseID: param.CourseID,
-		})
-	}
-
-	err := s.teachingService.EditClassesCourses(ctx, specs)
+	report, err := s.teachingService.GetCashUpReport(ctx, req.Date)
 	if err != nil {
-		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("teachingService.EditClassesCourses(): %w", err), nil, "Failed to get courses")
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("teachingService.GetCashUpReport(): %w", err), nil, "Failed to get cash-up report")
 	}
 
-	return &output.EditClassesCoursesResponse{
-		Message: "Successfully edit classes courses",
+	return &output.GetCashUpReportResponse{
+		Data: report,
 	}, nil
 }
 
-func (s *BackendService) SearchEnrollmentPaymentHandler(ctx context.Context, req *output.SearchEnrollmentPaymentsRequest) (*output.SearchEnrollmentPaymentsResponse, errs.HTTPError) {
+func (s *BackendService) CloseCashUpDayHandler(ctx context.Context, req *output.CloseCashUpDayRequest) (*output.CloseCashUpDayResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	timeFilter := util.TimeSpec(req.TimeFilter)
-	enrollmentPayments, err := s.teachingService.SearchEnrollmentPayment(ctx, timeFilter)
+	cashUpDay, err := s.teachingService.CloseCashUpDay(ctx, teaching.CloseCashUpDaySpec{
+		Date:             req.Date,
+		CountedCashValue: req.CountedCashValue,
+		Note:             req.Note,
+	})
 	if err != nil {
-		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("teachingService.SearchEnrollmentPayments(): %w", err), nil, "Failed to get courses")
+		return nil, handleUpsertionError(err, "teachingService.CloseCashUpDay()", "cashUpDay")
 	}
+	mainLog.Info("CashUpDay closed: date='%s', differenceValue='%d'", cashUpDay.Date.Format("2006-01-02"), cashUpDay.DifferenceValue)
 
-	return &output.SearchEnrollmentPaymentsResponse{
-		Data: output.SearchEnrollmentPaymentsResult{
-			Results: enrollmentPayments,
-		},
+	return &output.CloseCashUpDayResponse{
+		Data: cashUpDay,
 	}, nil
 }
 
-func (s *BackendService) GetEnrollmentPaymentInvoiceHandler(ctx context.Context, req *output.GetEnrollmentPaymentInvoiceRequest) (*output.GetEnrollmentPaymentInvoiceResponse, errs.HTTPError) {
+func (s *BackendService) ReopenCashUpDayHandler(ctx context.Context, req *output.ReopenCashUpDayRequest) (*output.ReopenCashUpDayResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	paymentInvoice, err := s.teachingService.GetEnrollmentPaymentInvoice(ctx, req.StudentEnrollmentID)
+	err := s.teachingService.ReopenCashUpDay(ctx, req.Date)
 	if err != nil {
-		return nil, handleReadError(err, "teachingService.GetEnrollmentPaymentInvoice()", "studentEnrollment")
+		return nil, handleReadError(err, "teachingService.ReopenCashUpDay()", "cashUpDay")
 	}
+	mainLog.Info("CashUpDay reopened: date='%s'", req.Date.Format("2006-01-02"))
 
-	return &output.GetEnrollmentPaymentInvoiceResponse{
-		Data: paymentInvoice,
+	return &output.ReopenCashUpDayResponse{
+		Message: "Successfully reopened the cash-up day",
 	}, nil
 }
 
-func (s *BackendService) SubmitEnrollmentPaymentHandler(ctx context.Context, req *output.SubmitEnrollmentPaymentRequest) (*output.SubmitEnrollmentPaymentResponse, errs.HTTPError) {
+func (s *BackendService) SendPaymentRemindersHandler(ctx context.Context, req *output.SendPaymentRemindersRequest) (*output.SendPaymentRemindersResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	err := s.teachingService.SubmitEnrollmentPayment(ctx, teaching.SubmitStudentEnrollmentPaymentSpec{
-		StudentEnrollmentID: req.StudentEnrollmentID,
-		PaymentDate:         req.PaymentDate,
-		BalanceTopUp:        req.BalanceTopUp,
-		BalanceBonus:        req.BalanceBonus,
-		CourseFeeValue:      req.CourseFeeValue,
-		TransportFeeValue:   req.TransportFeeValue,
-		PenaltyFeeValue:     req.PenaltyFeeValue,
-		DiscountFeeValue:    req.DiscountFeeValue,
+	result, err := s.teachingService.SendPaymentReminders(ctx, teaching.SendPaymentRemindersSpec{
+		Cadence:           configObject.PaymentReminderCadence,
+		DaysBeforePenalty: configObject.PaymentReminderDaysBeforePenalty,
+		DryRun:            req.DryRun,
 	})
 	if err != nil {
-		return nil, handleUpsertionError(err, "teachingService.SubmitStudentEnrollmentPayment()", "enrollmentPayment")
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("teachingService.SendPaymentReminders(): %w", err), nil, "Failed to send payment reminders")
 	}
 
-	return &output.SubmitEnrollmentPaymentResponse{
-		Message: "Successfully submitted enrollmentPayment",
+	message := fmt.Sprintf("Successfully sent %d payment reminder(s)", len(result.Reminders))
+	if req.DryRun {
+		message = "Dry-run: payment reminders are not sent"
+	} else {
+		mainLog.Info("Payment reminders sent: total='%d', failed='%d'", len(result.Reminders), len(result.Failures))
+	}
+
+	return &output.SendPaymentRemindersResponse{
+		Data:    result,
+		Message: message,
 	}, nil
 }
 
-func (s *BackendService) EditEnrollmentPaymentHandler(ctx context.Context, req *output.EditEnrollmentPaymentRequest) (*output.EditEnrollmentPaymentResponse, errs.HTTPError) {
+func (s *BackendService) GetPaymentRemindersHandler(ctx context.Context, req *output.GetPaymentRemindersRequest) (*output.GetPaymentRemindersResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	enrollmentPaymentID, err := s.teachingService.EditEnrollmentPayment(ctx, teaching.EditStudentEnrollmentPaymentSpec{
-		EnrollmentPaymentID: req.EnrollmentPaymentID,
-		PaymentDate:         req.PaymentDate,
-		BalanceBonus:        req.BalanceBonus,
-		DiscountFeeValue:    req.DiscountFeeValue,
-	})
+	getPaymentRemindersResult, err := s.teachingService.GetPaymentReminders(ctx, util.PaginationSpec(req.PaginationRequest))
 	if err != nil {
-		return nil, handleReadUpsertError(err, "teachingService.EditEnrollmentPayment()", "enrollmentPayment")
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("teachingService.GetPaymentReminders(): %w", err), nil, "Failed to get paymentReminders")
 	}
 
-	enrollmentPayment, err := s.entityService.GetEnrollmentPaymentById(ctx, enrollmentPaymentID)
+	paginationResponse := output.NewPaginationResponse(getPaymentRemindersResult.PaginationResult)
+
+	return &output.GetPaymentRemindersResponse{
+		Data: output.GetPaymentRemindersResult{
+			Results:            getPaymentRemindersResult.PaymentReminders,
+			PaginationResponse: paginationResponse,
+		},
+	}, nil
+}
+
+func (s *BackendService) GetPaymentReminderOptOutsHandler(ctx context.Context, req *output.GetPaymentReminderOptOutsRequest) (*output.GetPaymentReminderOptOutsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	optOuts, err := s.teachingService.GetPaymentReminderOptOuts(ctx)
 	if err != nil {
-		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetEnrollmentPaymentsByIds: %v", err), nil, "")
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("teachingService.GetPaymentReminderOptOuts(): %w", err), nil, "Failed to get paymentReminderOptOuts")
 	}
 
-	return &output.EditEnrollmentPaymentResponse{
-		Data:    enrollmentPayment,
-		Message: "Successfully edited enrollmentPayment",
+	return &output.GetPaymentReminderOptOutsResponse{
+		Data: output.GetPaymentReminderOptOutsResult{
+			Results: optOuts,
+		},
 	}, nil
 }
 
-func (s *BackendService) RemoveEnrollmentPaymentHandler(ctx context.Context, req *output.RemoveEnrollmentPaymentRequest) (*output.RemoveEnrollmentPaymentResponse, errs.HTTPError) {
+func (s *BackendService) SetPaymentReminderOptOutHandler(ctx context.Context, req *output.SetPaymentReminderOptOutRequest) (*output.SetPaymentReminderOptOutResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
-	err := s.teachingService.RemoveEnrollmentPayment(ctx, req.EnrollmentPaymentID)
+	err := s.teachingService.SetPaymentReminderOptOut(ctx, req.StudentIDs, req.IsOptedOut)
 	if err != nil {
-		return nil, handleDeletionError(err, "teachingService.RemoveStudentEnrollmentPayment()", "enrollmentPayment")
+		return nil, handleUpsertionError(err, "teachingService.SetPaymentReminderOptOut()", "paymentReminderOptOut")
 	}
+	mainLog.Info("Payment reminder opt-out updated: studentIDs='%v', isOptedOut='%v'", req.StudentIDs, req.IsOptedOut)
 
-	return &output.RemoveEnrollmentPaymentResponse{
-		Message: "Successfully removed enrollmentPayment",
+	return &output.SetPaymentReminderOptOutResponse{
+		Message: "Successfully updated paymentReminderOptOut",
 	}, nil
 }
 
@@ -1956,6 +3317,150 @@ func (s *BackendService) GetStudentLearningTokensByClassIDHandler(ctx context.Co
 	}, nil
 }
 
+func (s *BackendService) GetStudentLearningTokenHistoryHandler(ctx context.Context, req *output.GetStudentLearningTokenHistoryRequest) (*output.GetStudentLearningTokenHistoryResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	sltHistory, err := s.teachingService.GetSLTHistory(ctx, req.StudentLearningTokenID)
+	if err != nil {
+		return nil, handleReadError(err, "teachingService.GetSLTHistory()", "studentLearningToken")
+	}
+	if !sltHistory.IsConsistent {
+		mainLog.Warn("StudentLearningToken with ID='%d' has inconsistent quota: quota='%v', ledgerQuota='%v'", req.StudentLearningTokenID, sltHistory.StudentLearningToken.Quota, sltHistory.LedgerQuota)
+	}
+
+	return &output.GetStudentLearningTokenHistoryResponse{
+		Data: sltHistory,
+	}, nil
+}
+
+func (s *BackendService) TransferStudentLearningTokensHandler(ctx context.Context, req *output.TransferStudentLearningTokensRequest) (*output.TransferStudentLearningTokensResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	sltTransfer, err := s.teachingService.TransferStudentLearningTokens(ctx, teaching.TransferStudentLearningTokensSpec{
+		SourceStudentLearningTokenID:   req.StudentLearningTokenID,
+		DestinationStudentEnrollmentID: req.DestinationStudentEnrollmentID,
+		Quota:                          req.Quota,
+		Note:                           req.Note,
+	})
+	if err != nil {
+		errContext := fmt.Errorf("teachingService.TransferStudentLearningTokens(): %w", err)
+		if errors.Is(err, errs.ErrInsufficientSLTQuota) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "The studentLearningToken doesn't have enough quota to transfer")
+		}
+		if errors.Is(err, errs.ErrSLTTransferToSameEnrollment) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "The studentLearningToken already belongs to the destination enrollment, choose another enrollment")
+		}
+		if errors.Is(err, errs.ErrModifyingPaidAttendance) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "One of the attendances is already paid, try de-registering the attendance from teacher payment first")
+		}
+
+		return nil, handleUpsertionError(err, errContext.Error(), "studentLearningToken")
+	}
+	mainLog.Info("StudentLearningToken transferred: sltTransfer='%+v'", sltTransfer)
+
+	return &output.TransferStudentLearningTokensResponse{
+		Data:    sltTransfer,
+		Message: "Successfully transferred studentLearningToken",
+	}, nil
+}
+
+func (s *BackendService) RepriceStudentLearningTokensHandler(ctx context.Context, req *output.RepriceStudentLearningTokensRequest) (*output.RepriceStudentLearningTokensResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	spec := teaching.RepriceStudentLearningTokensSpec{
+		CutoverDate:      req.CutoverDate,
+		CourseID:         req.CourseID,
+		IsQuotaConverted: req.IsQuotaConverted,
+	}
+
+	var sltRepricings []teaching.SLTRepricing
+	var err error
+	if req.DryRun {
+		sltRepricings, err = s.teachingService.PreviewRepriceStudentLearningTokens(ctx, spec)
+	} else {
+		sltRepricings, err = s.teachingService.RepriceStudentLearningTokens(ctx, spec)
+	}
+	if err != nil {
+		errContext := fmt.Errorf("teachingService.RepriceStudentLearningTokens(): %w", err)
+		if errors.Is(err, errs.ErrModifyingPaidAttendance) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "One of the attendances after the cutover date is already paid, try de-registering the attendance from teacher payment first")
+		}
+
+		return nil, handleUpsertionError(err, errContext.Error(), "studentLearningToken")
+	}
+
+	if req.DryRun {
+		return &output.RepriceStudentLearningTokensResponse{
+			Data: output.RepriceStudentLearningTokensResult{
+				Results: sltRepricings,
+			},
+			Message: "Dry-run: studentLearningTokens are not repriced",
+		}, nil
+	}
+	mainLog.Info("StudentLearningTokens repriced: cutoverDate='%v', courseID='%d', count='%d'", req.CutoverDate, req.CourseID, len(sltRepricings))
+
+	return &output.RepriceStudentLearningTokensResponse{
+		Data: output.RepriceStudentLearningTokensResult{
+			Results: sltRepricings,
+		},
+		Message: "Successfully repriced studentLearningTokens",
+	}, nil
+}
+
+func (s *BackendService) LevelUpClassHandler(ctx context.Context, req *output.LevelUpClassRequest) (*output.LevelUpClassResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	spec := teaching.LevelUpClassSpec{
+		ClassID:          req.ClassID,
+		CourseID:         req.CourseID,
+		EffectiveDate:    req.EffectiveDate,
+		IsQuotaConverted: req.IsQuotaConverted,
+	}
+
+	var classLevelUp teaching.ClassLevelUp
+	var err error
+	if req.DryRun {
+		classLevelUp, err = s.teachingService.PreviewLevelUpClass(ctx, spec)
+	} else {
+		classLevelUp, err = s.teachingService.LevelUpClass(ctx, spec)
+	}
+	if err != nil {
+		errContext := fmt.Errorf("teachingService.LevelUpClass(): %w", err)
+		if errors.Is(err, errs.ErrClassAlreadyInCourse) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "The class is already in the requested course")
+		}
+		if errors.Is(err, errs.ErrLevelUpEffectiveDateInFuture) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, map[string]string{"effectiveDate": "effectiveDate must not be after today"}, "The level-up cannot be scheduled for a future date")
+		}
+		if errors.Is(err, errs.ErrModifyingPaidAttendance) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "One of the attendances after the effective date is already paid, try de-registering the attendance from teacher payment first")
+		}
+
+		return nil, handleReadUpsertError(err, errContext.Error(), "class")
+	}
+
+	if req.DryRun {
+		return &output.LevelUpClassResponse{
+			Data:    classLevelUp,
+			Message: "Dry-run: class is not leveled up",
+		}, nil
+	}
+	mainLog.Info("Class leveled up: classID='%d', prevCourseID='%d', newCourseID='%d', effectiveDate='%v'", req.ClassID, classLevelUp.PrevCourseID, req.CourseID, req.EffectiveDate)
+
+	return &output.LevelUpClassResponse{
+		Data:    classLevelUp,
+		Message: "Successfully leveled up class",
+	}, nil
+}
+
 func (s *BackendService) GetAttendancesByClassIDHandler(ctx context.Context, req *output.GetAttendancesByClassIDRequest) (*output.GetAttendancesByClassIDResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
@@ -2017,12 +3522,35 @@ func (s *BackendService) AddAttendancesBatchHandler(ctx context.Context, req *ou
 		})
 	}
 
+	if req.DryRun {
+		preview, err := s.teachingService.PreviewAddAttendancesBatch(ctx, specs)
+		if err != nil {
+			errContext := fmt.Errorf("teachingService.PreviewAddAttendancesBatch(): %w", err)
+			if errors.Is(err, errs.ErrClassHaveNoStudent) {
+				return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "One of the classes don't have any student, try registering a student first")
+			}
+			if errors.Is(err, errs.ErrAllStudentsOnHold) {
+				return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "All students of one of the classes are on hold at the attendance date")
+			}
+
+			return nil, handleUpsertionError(err, errContext.Error(), "attendance")
+		}
+
+		return &output.AddAttendancesBatchResponse{
+			DryRunResult: &preview,
+			Message:      "Dry-run: attendances are not added",
+		}, nil
+	}
+
 	attendanceIDs, err := s.teachingService.AddAttendancesBatch(ctx, specs)
 	if err != nil {
 		errContext := fmt.Errorf("teachingService.AddAttendancesBatch(): %w", err)
 		if errors.Is(err, errs.ErrClassHaveNoStudent) {
 			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "One of the classes don't have any student, try registering a student first")
 		}
+		if errors.Is(err, errs.ErrAllStudentsOnHold) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "All students of one of the classes are on hold at the attendance date")
+		}
 
 		return nil, handleUpsertionError(err, errContext.Error(), "attendance")
 	}
@@ -2069,6 +3597,29 @@ func (s *BackendService) AssignAttendanceTokenHandler(ctx context.Context, req *
 	}, nil
 }
 
+func (s *BackendService) AssignAttendancesTokensHandler(ctx context.Context, req *output.AssignAttendancesTokensRequest) (*output.AssignAttendancesTokensResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	assignments, err := s.teachingService.AssignAttendancesTokens(ctx, teaching.AssignAttendancesTokensSpec{
+		ClassID:                req.ClassID,
+		StudentID:              req.StudentID,
+		TimeSpec:               util.TimeSpec(req.TimeFilter),
+		Strategy:               req.Strategy,
+		StudentLearningTokenID: req.StudentLearningTokenID,
+	})
+	if err != nil {
+		return nil, handleReadUpsertError(err, "teachingService.AssignAttendancesTokens()", "studentLearningToken")
+	}
+	mainLog.Info("Attendances' tokens assigned: classID='%d', strategy='%s', assignedCount='%d', failedCount='%d'", req.ClassID, req.Strategy, len(assignments.Assignments), len(assignments.Failures))
+
+	return &output.AssignAttendancesTokensResponse{
+		Data:    assignments,
+		Message: fmt.Sprintf("Successfully assigned %d attendances' tokens, %d attendances cannot be assigned", len(assignments.Assignments), len(assignments.Failures)),
+	}, nil
+}
+
 func (s *BackendService) AddAttendanceHandler(ctx context.Context, req *output.AddAttendanceRequest) (*output.AddAttendanceResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
@@ -2086,19 +3637,47 @@ func (s *BackendService) AddAttendanceHandler(ctx context.Context, req *output.A
 		}
 	}
 
-	attendanceIDs, err := s.teachingService.AddAttendance(ctx, teaching.AddAttendanceSpec{
+	spec := teaching.AddAttendanceSpec{
 		ClassID:               req.ClassID,
 		TeacherID:             req.TeacherID,
 		Date:                  req.Date,
 		UsedStudentTokenQuota: req.UsedStudentTokenQuota,
 		Duration:              req.Duration,
 		Note:                  req.Note,
-	})
+	}
+
+	if req.DryRun {
+		preview, err := s.teachingService.PreviewAddAttendance(ctx, spec)
+		if err != nil {
+			errContext := fmt.Errorf("teachingService.PreviewAddAttendance(): %w", err)
+			if errors.Is(err, errs.ErrClassHaveNoStudent) {
+				return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "This class doesn't have any student, try registering a student first")
+			}
+			if errors.Is(err, errs.ErrAllStudentsOnHold) {
+				return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "All students of this class are on hold at the attendance date")
+			}
+
+			return nil, handleUpsertionError(err, errContext.Error(), "attendance")
+		}
+
+		return &output.AddAttendanceResponse{
+			Data: output.UpsertAttendanceResult{
+				Results: []entity.Attendance{},
+			},
+			DryRunResult: &preview,
+			Message:      "Dry-run: attendances are not added",
+		}, nil
+	}
+
+	attendanceIDs, err := s.teachingService.AddAttendance(ctx, spec)
 	if err != nil {
 		errContext := fmt.Errorf("teachingService.AddAttendance(): %w", err)
 		if errors.Is(err, errs.ErrClassHaveNoStudent) {
 			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "This class doesn't have any student, try registering a student first")
 		}
+		if errors.Is(err, errs.ErrAllStudentsOnHold) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "All students of this class are on hold at the attendance date")
+		}
 
 		return nil, handleUpsertionError(err, errContext.Error(), "attendance")
 	}
@@ -2250,6 +3829,28 @@ func (s *BackendService) GetPaidTeachersHandler(ctx context.Context, req *output
 	}, nil
 }
 
+// GetTeacherSubstitutionSummariesHandler reports the substitutions given & received by each teacher, using the same date range as GetUnpaidTeachersHandler.
+func (s *BackendService) GetTeacherSubstitutionSummariesHandler(ctx context.Context, req *output.GetTeacherSubstitutionSummariesRequest) (*output.GetTeacherSubstitutionSummariesResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	timeFilter := req.YearMonthFilter.ToTimeFilter(output.YearMonthFilterType_CalculatingSalary)
+
+	summaries, err := s.teachingService.GetTeacherSubstitutionSummaries(ctx, teaching.GetTeacherSubstitutionSummariesSpec{
+		TimeSpec: util.TimeSpec(timeFilter),
+	})
+	if err != nil {
+		return nil, handleReadError(err, "teachingService.GetTeacherSubstitutionSummaries()", "teacherSubstitution")
+	}
+
+	return &output.GetTeacherSubstitutionSummariesResponse{
+		Data: output.GetTeacherSubstitutionSummariesResult{
+			Results: summaries,
+		},
+	}, nil
+}
+
 // GetTeacherPaymentInvoiceItemsHandler gets all `Attendances`s within the range of specific rule (prev month's 28th to curr month's 27) for a selected TeacherID, then convert to `TeacherPaymentInvoiceItem`s.
 func (s *BackendService) GetTeacherPaymentInvoiceItemsHandler(ctx context.Context, req *output.GetTeacherPaymentInvoiceItemsRequest) (*output.GetTeacherPaymentInvoiceItemsResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
@@ -2263,36 +3864,104 @@ func (s *BackendService) GetTeacherPaymentInvoiceItemsHandler(ctx context.Contex
 		TimeSpec:  util.TimeSpec(timeFilter),
 	})
 	if err != nil {
-		return nil, handleReadError(err, "teachingService.GetTeacherPaymentInvoiceItems()", "teacherPayment")
+		return nil, handleReadError(err, "teachingService.GetTeacherPaymentInvoiceItems()", "teacherPayment")
+	}
+
+	return &output.GetTeacherPaymentInvoiceItemsResponse{
+		Data: output.GetTeacherPaymentInvoiceItemsResult{
+			Results: invoiceItems,
+		},
+	}, nil
+}
+
+// GetTeacherPaymentsAsInvoiceItemsHandler gets all `TeacherPayment`s within the range of a whole month (curr month's 1th to last day) for a selected TeacherID, then convert to `TeacherPaymentInvoiceItem`s.
+func (s *BackendService) GetTeacherPaymentsAsInvoiceItemsHandler(ctx context.Context, req *output.GetTeacherPaymentsAsInvoiceItemsRequest) (*output.GetTeacherPaymentsAsInvoiceItemsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	attendanceTimeFilter := req.YearMonthFilter.ToTimeFilter(output.YearMonthFilterType_Standard)
+
+	invoiceItems, err := s.teachingService.GetExistingTeacherPaymentInvoiceItems(ctx, teaching.GetExistingTeacherPaymentInvoiceItemsSpec{
+		TeacherID: req.TeacherID,
+		TimeSpec:  util.TimeSpec(attendanceTimeFilter),
+	})
+	if err != nil {
+		return nil, handleReadError(err, "teachingService.GetExistingTeacherPaymentInvoiceItems()", "teacherPayment")
+	}
+
+	return &output.GetTeacherPaymentsAsInvoiceItemsResponse{
+		Data: output.GetTeacherPaymentsAsInvoiceItemsResult{
+			Results: invoiceItems,
+		},
+	}, nil
+}
+
+func (s *BackendService) GetTeacherPayslipHandler(ctx context.Context, req *output.GetTeacherPayslipRequest) (*output.FileResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	attendanceTimeFilter := req.YearMonthFilter.ToTimeFilter(output.YearMonthFilterType_Standard)
+
+	payslip, err := s.teachingService.GetTeacherPayslip(ctx, teaching.GetExistingTeacherPaymentInvoiceItemsSpec{
+		TeacherID: req.TeacherID,
+		TimeSpec:  util.TimeSpec(attendanceTimeFilter),
+	})
+	if err != nil {
+		return nil, handleReadError(err, "teachingService.GetTeacherPayslip()", "teacher")
+	}
+
+	if req.Format == output.TeacherPayslipFormat_HTML {
+		payslipTemplate := email_composer.NewTeacherPayslip(payslip)
+		content, err := s.emailComposer.GenerateHTML(payslipTemplate.Email())
+		if err != nil {
+			return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("emailComposer.GenerateHTML(): %w", err), nil, "Failed to generate the payslip")
+		}
+
+		return &output.FileResponse{
+			FileName:    strings.TrimSuffix(pdf_composer.NewTeacherPayslip(payslip).FileName(), ".pdf") + ".html",
+			ContentType: "text/html; charset=utf-8",
+			Content:     []byte(content),
+		}, nil
+	}
+
+	payslipTemplate := pdf_composer.NewTeacherPayslip(payslip)
+	content, err := payslipTemplate.PDF()
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("payslipTemplate.PDF(): %w", err), nil, "Failed to generate the payslip")
 	}
 
-	return &output.GetTeacherPaymentInvoiceItemsResponse{
-		Data: output.GetTeacherPaymentInvoiceItemsResult{
-			Results: invoiceItems,
-		},
+	return &output.FileResponse{
+		FileName:    payslipTemplate.FileName(),
+		ContentType: "application/pdf",
+		Content:     content,
 	}, nil
 }
 
-// GetTeacherPaymentsAsInvoiceItemsHandler gets all `TeacherPayment`s within the range of a whole month (curr month's 1th to last day) for a selected TeacherID, then convert to `TeacherPaymentInvoiceItem`s.
-func (s *BackendService) GetTeacherPaymentsAsInvoiceItemsHandler(ctx context.Context, req *output.GetTeacherPaymentsAsInvoiceItemsRequest) (*output.GetTeacherPaymentsAsInvoiceItemsResponse, errs.HTTPError) {
+func (s *BackendService) EmailTeacherPayslipHandler(ctx context.Context, req *output.EmailTeacherPayslipRequest) (*output.EmailTeacherPayslipResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
 	}
 
 	attendanceTimeFilter := req.YearMonthFilter.ToTimeFilter(output.YearMonthFilterType_Standard)
 
-	invoiceItems, err := s.teachingService.GetExistingTeacherPaymentInvoiceItems(ctx, teaching.GetExistingTeacherPaymentInvoiceItemsSpec{
+	err := s.teachingService.EmailTeacherPayslip(ctx, teaching.GetExistingTeacherPaymentInvoiceItemsSpec{
 		TeacherID: req.TeacherID,
 		TimeSpec:  util.TimeSpec(attendanceTimeFilter),
 	})
 	if err != nil {
-		return nil, handleReadError(err, "teachingService.GetExistingTeacherPaymentInvoiceItems()", "teacherPayment")
+		errContext := fmt.Errorf("teachingService.EmailTeacherPayslip(): %w", err)
+		if errors.Is(err, errs.ErrUserEmailNotSet) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "The teacher doesn't have an email")
+		} else if errors.Is(err, sql.ErrNoRows) {
+			return nil, handleReadError(err, "teachingService.EmailTeacherPayslip()", "teacher")
+		}
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, errContext, nil, "Failed to send the payslip")
 	}
 
-	return &output.GetTeacherPaymentsAsInvoiceItemsResponse{
-		Data: output.GetTeacherPaymentsAsInvoiceItemsResult{
-			Results: invoiceItems,
-		},
+	return &output.EmailTeacherPayslipResponse{
+		Message: "Successfully sent the payslip",
 	}, nil
 }
 
@@ -2307,12 +3976,21 @@ func (s *BackendService) SubmitTeacherPaymentsHandler(ctx context.Context, req *
 			AttendanceID:          param.AttendanceID,
 			PaidCourseFeeValue:    param.PaidCourseFeeValue,
 			PaidTransportFeeValue: param.PaidTransportFeeValue,
+			PayrollRunID:          req.PayrollRunID,
 		})
 	}
 
 	err := s.teachingService.SubmitTeacherPayments(ctx, specs)
 	if err != nil {
-		return nil, handleUpsertionError(err, "teachingService.SubmitTeacherPayments()", "TeacherPayments")
+		errContext := fmt.Errorf("teachingService.SubmitTeacherPayments(): %w", err)
+		if errors.Is(err, errs.ErrPayrollRunNotDraft) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "The payroll run has been approved or paid, no more teacherPayment can be added into it")
+		}
+		if errors.Is(err, errs.ErrAttendanceOutsidePayrollRunPeriod) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "Some attendances are dated outside of the payroll run period")
+		}
+
+		return nil, handleUpsertionError(err, errContext.Error(), "TeacherPayments")
 	}
 
 	return &output.SubmitTeacherPaymentsResponse{
@@ -2337,7 +4015,12 @@ func (s *BackendService) ModifyTeacherPaymentsHandler(ctx context.Context, req *
 
 	modifyTeacherPaymentsResult, err := s.teachingService.ModifyTeacherPayments(ctx, specs)
 	if err != nil {
-		return nil, handleUpsertionError(err, "teachingService.ModifyTeacherPayments()", "teacherPayment")
+		errContext := fmt.Errorf("teachingService.ModifyTeacherPayments(): %w", err)
+		if errors.Is(err, errs.ErrTeacherPaymentLocked) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "Some of the teacherPayments belong to an approved or paid payroll run, and can no longer be changed")
+		}
+
+		return nil, handleUpsertionError(err, errContext.Error(), "teacherPayment")
 	}
 	mainLog.Info("TeacherPayments edited: teacherPaymentIDs='%v'; removed: teacherPaymentIDs='%v'", modifyTeacherPaymentsResult.EditedTeacherPaymentIDs, modifyTeacherPaymentsResult.DeletedTeacherPaymentIDs)
 
@@ -2370,7 +4053,12 @@ func (s *BackendService) EditTeacherPaymentsHandler(ctx context.Context, req *ou
 
 	teacherPaymentIDs, err := s.teachingService.EditTeacherPayments(ctx, specs)
 	if err != nil {
-		return nil, handleUpsertionError(err, "teachingService.EditTeacherPayments()", "teacherPayment")
+		errContext := fmt.Errorf("teachingService.EditTeacherPayments(): %w", err)
+		if errors.Is(err, errs.ErrTeacherPaymentLocked) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "Some of the teacherPayments belong to an approved or paid payroll run, and can no longer be changed")
+		}
+
+		return nil, handleUpsertionError(err, errContext.Error(), "teacherPayment")
 	}
 	mainLog.Info("TeacherPayments edited: teacherPaymentIDs='%v'", teacherPaymentIDs)
 
@@ -2399,7 +4087,12 @@ func (s *BackendService) RemoveTeacherPaymentsHandler(ctx context.Context, req *
 
 	err := s.teachingService.RemoveTeacherPayments(ctx, ids)
 	if err != nil {
-		return nil, handleUpsertionError(err, "teachingService.RemoveTeacherPayments():", "teacherPayment")
+		errContext := fmt.Errorf("teachingService.RemoveTeacherPayments(): %w", err)
+		if errors.Is(err, errs.ErrTeacherPaymentLocked) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "Some of the teacherPayments belong to an approved or paid payroll run, and can no longer be changed")
+		}
+
+		return nil, handleUpsertionError(err, errContext.Error(), "teacherPayment")
 	}
 	mainLog.Info("TeacherPaymentss removed: teacherPaymentIDs='%v'", ids)
 
@@ -2408,6 +4101,301 @@ func (s *BackendService) RemoveTeacherPaymentsHandler(ctx context.Context, req *
 	}, nil
 }
 
+func (s *BackendService) GetPayrollRunsHandler(ctx context.Context, req *output.GetPayrollRunsRequest) (*output.GetPayrollRunsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	getPayrollRunsResult, err := s.teachingService.GetPayrollRuns(ctx, util.PaginationSpec(req.PaginationRequest))
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("teachingService.GetPayrollRuns(): %w", err), nil, "Failed to get payrollRuns")
+	}
+
+	paginationResponse := output.NewPaginationResponse(getPayrollRunsResult.PaginationResult)
+
+	return &output.GetPayrollRunsResponse{
+		Data: output.GetPayrollRunsResult{
+			Results:            getPayrollRunsResult.PayrollRuns,
+			PaginationResponse: paginationResponse,
+		},
+	}, nil
+}
+
+func (s *BackendService) GetPayrollRunByIdHandler(ctx context.Context, req *output.GetPayrollRunRequest) (*output.GetPayrollRunResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	payrollRun, err := s.teachingService.GetPayrollRunById(ctx, req.PayrollRunID)
+	if err != nil {
+		return nil, handleReadError(err, "teachingService.GetPayrollRunById()", "payrollRun")
+	}
+
+	return &output.GetPayrollRunResponse{
+		Data: payrollRun,
+	}, nil
+}
+
+// CreatePayrollRunHandler creates a draft PayrollRun for the salary period of the requested month, along with all teachers who still have unpaid attendances in the period.
+func (s *BackendService) CreatePayrollRunHandler(ctx context.Context, req *output.CreatePayrollRunRequest) (*output.CreatePayrollRunResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	timeFilter := req.YearMonthFilter.ToTimeFilter(output.YearMonthFilterType_CalculatingSalary)
+
+	payrollRunID, err := s.teachingService.CreatePayrollRun(ctx, teaching.CreatePayrollRunSpec{
+		TimeSpec: util.TimeSpec(timeFilter),
+		Note:     req.Note,
+	})
+	if err != nil {
+		return nil, handleUpsertionError(err, "teachingService.CreatePayrollRun()", "payrollRun")
+	}
+	mainLog.Info("PayrollRun created: payrollRunID='%v'", payrollRunID)
+
+	payrollRun, err := s.teachingService.GetPayrollRunById(ctx, payrollRunID)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("teachingService.GetPayrollRunById: %v", err), nil, "")
+	}
+
+	return &output.CreatePayrollRunResponse{
+		Data:    payrollRun,
+		Message: "Successfully created payrollRun",
+	}, nil
+}
+
+func (s *BackendService) GetPayrollRunTeacherTotalsHandler(ctx context.Context, req *output.GetPayrollRunTeacherTotalsRequest) (*output.GetPayrollRunTeacherTotalsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	teacherTotals, err := s.teachingService.GetPayrollRunTeacherTotals(ctx, req.PayrollRunID)
+	if err != nil {
+		return nil, handleReadError(err, "teachingService.GetPayrollRunTeacherTotals()", "payrollRun")
+	}
+
+	return &output.GetPayrollRunTeacherTotalsResponse{
+		Data: output.GetPayrollRunTeacherTotalsResult{
+			Results: teacherTotals,
+		},
+	}, nil
+}
+
+func (s *BackendService) ApprovePayrollRunHandler(ctx context.Context, req *output.ApprovePayrollRunRequest) (*output.ApprovePayrollRunResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	err := s.teachingService.ApprovePayrollRun(ctx, req.PayrollRunID)
+	if err != nil {
+		errContext := fmt.Errorf("teachingService.ApprovePayrollRun(): %w", err)
+		if errors.Is(err, errs.ErrInvalidPayrollRunStatusTransition) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "Only a draft payroll run can be approved")
+		}
+
+		return nil, handleUpsertionError(err, errContext.Error(), "payrollRun")
+	}
+	mainLog.Info("PayrollRun approved: payrollRunID='%v'", req.PayrollRunID)
+
+	payrollRun, err := s.teachingService.GetPayrollRunById(ctx, req.PayrollRunID)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("teachingService.GetPayrollRunById: %v", err), nil, "")
+	}
+
+	return &output.ApprovePayrollRunResponse{
+		Data:    payrollRun,
+		Message: "Successfully approved payrollRun",
+	}, nil
+}
+
+func (s *BackendService) MarkPayrollRunAsPaidHandler(ctx context.Context, req *output.MarkPayrollRunAsPaidRequest) (*output.MarkPayrollRunAsPaidResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	err := s.teachingService.MarkPayrollRunAsPaid(ctx, req.PayrollRunID)
+	if err != nil {
+		errContext := fmt.Errorf("teachingService.MarkPayrollRunAsPaid(): %w", err)
+		if errors.Is(err, errs.ErrInvalidPayrollRunStatusTransition) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "Only an approved payroll run can be marked as paid")
+		}
+
+		return nil, handleUpsertionError(err, errContext.Error(), "payrollRun")
+	}
+	mainLog.Info("PayrollRun marked as paid: payrollRunID='%v'", req.PayrollRunID)
+
+	payrollRun, err := s.teachingService.GetPayrollRunById(ctx, req.PayrollRunID)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("teachingService.GetPayrollRunById: %v", err), nil, "")
+	}
+
+	return &output.MarkPayrollRunAsPaidResponse{
+		Data:    payrollRun,
+		Message: "Successfully marked payrollRun as paid",
+	}, nil
+}
+
+func (s *BackendService) ImportBankStatementHandler(ctx context.Context, req *output.ImportBankStatementRequest) (*output.ImportBankStatementResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	importID, err := s.paymentService.ImportBankStatement(ctx, payment.ImportBankStatementSpec{
+		BankFormat: req.BankFormat,
+		FileName:   req.FileName,
+		Content:    req.Content,
+	})
+	if err != nil {
+		errContext := fmt.Errorf("paymentService.ImportBankStatement(): %w", err)
+		if errors.Is(err, errs.ErrUnsupportedBankFormat) {
+			return nil, errs.NewHTTPError(http.StatusBadRequest, errContext, map[string]string{"bankFormat": err.Error()}, "Unsupported bank format")
+		}
+		if errors.Is(err, errs.ErrInvalidBankStatementFile) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, map[string]string{"content": err.Error()}, "Unable to read the bank statement file. Please check whether the file matches the bank format")
+		}
+
+		return nil, handleUpsertionError(err, errContext.Error(), "bankStatementImport")
+	}
+	mainLog.Info("BankStatementImport created: bankStatementImportID='%v'", importID)
+
+	bankStatementImport, err := s.paymentService.GetBankStatementImportById(ctx, importID)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("paymentService.GetBankStatementImportById: %v", err), nil, "")
+	}
+
+	return &output.ImportBankStatementResponse{
+		Data:    bankStatementImport,
+		Message: "Successfully imported bank statement",
+	}, nil
+}
+
+func (s *BackendService) GetBankStatementImportsHandler(ctx context.Context, req *output.GetBankStatementImportsRequest) (*output.GetBankStatementImportsResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	getBankStatementImportsResult, err := s.paymentService.GetBankStatementImports(ctx, util.PaginationSpec(req.PaginationRequest))
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("paymentService.GetBankStatementImports(): %w", err), nil, "Failed to get bankStatementImports")
+	}
+
+	paginationResponse := output.NewPaginationResponse(getBankStatementImportsResult.PaginationResult)
+
+	return &output.GetBankStatementImportsResponse{
+		Data: output.GetBankStatementImportsResult{
+			Results:            getBankStatementImportsResult.BankStatementImports,
+			PaginationResponse: paginationResponse,
+		},
+	}, nil
+}
+
+func (s *BackendService) GetBankStatementImportByIdHandler(ctx context.Context, req *output.GetBankStatementImportRequest) (*output.GetBankStatementImportResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	bankStatementImport, err := s.paymentService.GetBankStatementImportById(ctx, req.BankStatementImportID)
+	if err != nil {
+		return nil, handleReadError(err, "paymentService.GetBankStatementImportById()", "bankStatementImport")
+	}
+
+	return &output.GetBankStatementImportResponse{
+		Data: bankStatementImport,
+	}, nil
+}
+
+func (s *BackendService) ConfirmBankStatementLineMatchHandler(ctx context.Context, req *output.ConfirmBankStatementLineMatchRequest) (*output.ConfirmBankStatementLineMatchResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	err := s.paymentService.ConfirmBankStatementLineMatch(ctx, payment.ConfirmBankStatementLineMatchSpec{
+		BankStatementLineID: req.BankStatementLineID,
+		EnrollmentPaymentID: req.EnrollmentPaymentID,
+	})
+	if err != nil {
+		errContext := fmt.Errorf("paymentService.ConfirmBankStatementLineMatch(): %w", err)
+		if errors.Is(err, errs.ErrBankStatementLineAlreadyMatched) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "The bank statement line has already been confirmed. Unmatch it first to change its enrollment payment")
+		}
+		if errors.Is(err, errs.ErrBankStatementLineNotMatched) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, map[string]string{"enrollmentPaymentId": "enrollmentPaymentId is required"}, "The bank statement line is unmatched. Please choose the enrollment payment to match")
+		}
+		if errors.Is(err, errs.ErrBankStatementAmountMismatch) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "The enrollment payment's total (course + transport + penalty - discount) must equal the bank statement line's amount")
+		}
+
+		return nil, handleReadUpsertError(err, errContext.Error(), "bankStatementLine")
+	}
+	mainLog.Info("BankStatementLine confirmed: bankStatementLineID='%v'", req.BankStatementLineID)
+
+	return &output.ConfirmBankStatementLineMatchResponse{
+		Message: "Successfully confirmed bankStatementLine",
+	}, nil
+}
+
+func (s *BackendService) UnmatchBankStatementLineHandler(ctx context.Context, req *output.UnmatchBankStatementLineRequest) (*output.UnmatchBankStatementLineResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	err := s.paymentService.UnmatchBankStatementLine(ctx, req.BankStatementLineID)
+	if err != nil {
+		errContext := fmt.Errorf("paymentService.UnmatchBankStatementLine(): %w", err)
+		if errors.Is(err, errs.ErrBankStatementLineNotMatched) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "The bank statement line is already unmatched")
+		}
+
+		return nil, handleReadUpsertError(err, errContext.Error(), "bankStatementLine")
+	}
+	mainLog.Info("BankStatementLine unmatched: bankStatementLineID='%v'", req.BankStatementLineID)
+
+	return &output.UnmatchBankStatementLineResponse{
+		Message: "Successfully unmatched bankStatementLine",
+	}, nil
+}
+
+// CreateEnrollmentPaymentFromBankStatementLineHandler submits a bank transfer enrollmentPayment for an unmatched bank statement line, dated & referenced by the line.
+func (s *BackendService) CreateEnrollmentPaymentFromBankStatementLineHandler(ctx context.Context, req *output.CreateEnrollmentPaymentFromBankStatementLineRequest) (*output.CreateEnrollmentPaymentFromBankStatementLineResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	enrollmentPaymentID, err := s.paymentService.CreateEnrollmentPaymentFromBankStatementLine(ctx, payment.CreateEnrollmentPaymentFromBankStatementLineSpec{
+		BankStatementLineID: req.BankStatementLineID,
+		StudentEnrollmentID: req.StudentEnrollmentID,
+		BalanceTopUp:        req.BalanceTopUp,
+		BalanceBonus:        req.BalanceBonus,
+		CourseFeeValue:      req.CourseFeeValue,
+		TransportFeeValue:   req.TransportFeeValue,
+		PenaltyFeeValue:     req.PenaltyFeeValue,
+		DiscountFeeValue:    req.DiscountFeeValue,
+		ReceivingAccount:    req.ReceivingAccount,
+	})
+	if err != nil {
+		errContext := fmt.Errorf("paymentService.CreateEnrollmentPaymentFromBankStatementLine(): %w", err)
+		if errors.Is(err, errs.ErrBankStatementLineAlreadyMatched) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "The bank statement line has already been matched to an enrollment payment")
+		}
+		if errors.Is(err, errs.ErrBankStatementAmountMismatch) {
+			return nil, errs.NewHTTPError(http.StatusUnprocessableEntity, errContext, nil, "The enrollment payment's total (course + transport + penalty - discount) must equal the bank statement line's amount")
+		}
+
+		return nil, handleReadUpsertError(err, errContext.Error(), "enrollmentPayment")
+	}
+	mainLog.Info("EnrollmentPayment created from bankStatementLine: bankStatementLineID='%v', enrollmentPaymentID='%v'", req.BankStatementLineID, enrollmentPaymentID)
+
+	enrollmentPayment, err := s.entityService.GetEnrollmentPaymentById(ctx, enrollmentPaymentID)
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("entityService.GetEnrollmentPaymentById: %v", err), nil, "")
+	}
+
+	return &output.CreateEnrollmentPaymentFromBankStatementLineResponse{
+		Data:    enrollmentPayment,
+		Message: "Successfully created enrollmentPayment",
+	}, nil
+}
+
 func (s *BackendService) GetDashboardExpenseOverview(ctx context.Context, req *output.GetDashboardExpenseOverviewRequest) (*output.GetDashboardExpenseOverviewResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
@@ -2495,6 +4483,71 @@ func (s *BackendService) GetDashboardIncomeMonthlySummary(ctx context.Context, r
 	}, nil
 }
 
+func (s *BackendService) GetDashboardDiscountMonthlySummary(ctx context.Context, req *output.GetDashboardDiscountMonthlySummaryRequest) (*output.GetDashboardDiscountMonthlySummaryResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	monthlySummary, err := s.dashboardService.GetDiscountMonthlySummary(ctx, dashboard.GetDiscountMonthlySummarySpec{
+		TimeSpec: util.TimeSpec(req.SelectedDate.ToTimeFilter(output.YearMonthFilterType_Standard)),
+	})
+	if err != nil {
+		return &output.GetDashboardDiscountMonthlySummaryResponse{}, errs.NewHTTPError(http.StatusInternalServerError,
+			fmt.Errorf("dashboardService.GetDiscountMonthlySummary(): %v", err), nil, "Failed to get dashboardDiscountMonthlySummary data")
+	}
+
+	return &output.GetDashboardDiscountMonthlySummaryResponse{
+		Data: output.GetDashboardDiscountMonthlySummaryResult{
+			Results: monthlySummary.Data,
+		},
+	}, nil
+}
+
+func (s *BackendService) GetDashboardReceivableReport(ctx context.Context, req *output.GetDashboardReceivableReportRequest) (*output.GetDashboardReceivableReportResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	report, err := s.dashboardService.GetReceivableReport(ctx, dashboard.GetReceivableReportSpec{
+		TeacherIDs:    req.TeacherIDs,
+		InstrumentIDs: req.InstrumentIDs,
+	})
+	if err != nil {
+		return &output.GetDashboardReceivableReportResponse{}, errs.NewHTTPError(http.StatusInternalServerError,
+			fmt.Errorf("dashboardService.GetReceivableReport(): %v", err), nil, "Failed to get dashboardReceivableReport data")
+	}
+
+	return &output.GetDashboardReceivableReportResponse{
+		Data: report,
+	}, nil
+}
+
+func (s *BackendService) ExportDashboardReceivableReport(ctx context.Context, req *output.GetDashboardReceivableReportRequest) (*output.FileResponse, errs.HTTPError) {
+	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
+		return nil, errV
+	}
+
+	report, err := s.dashboardService.GetReceivableReport(ctx, dashboard.GetReceivableReportSpec{
+		TeacherIDs:    req.TeacherIDs,
+		InstrumentIDs: req.InstrumentIDs,
+	})
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError,
+			fmt.Errorf("dashboardService.GetReceivableReport(): %v", err), nil, "Failed to get dashboardReceivableReport data")
+	}
+
+	content, err := report.CSV()
+	if err != nil {
+		return nil, errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("report.CSV(): %w", err), nil, "Failed to export the receivable report")
+	}
+
+	return &output.FileResponse{
+		FileName:    report.FileName(time.Now()),
+		ContentType: "text/csv; charset=utf-8",
+		Content:     content,
+	}, nil
+}
+
 func (s *BackendService) GetUserProfile(ctx context.Context, req *output.GetUserProfileRequest) (*output.GetUserProfileResponse, errs.HTTPError) {
 	if errV := errs.ValidateHTTPRequest(req, false); errV != nil {
 		return nil, errV
@@ -2539,6 +4592,10 @@ func handleReadUpsertError(err error, methodName, entityName string) errs.HTTPEr
 	wrappedErr := fmt.Errorf("%s: %w", methodName, err)
 	if errors.Is(err, sql.ErrNoRows) {
 		return errs.NewHTTPError(http.StatusNotFound, wrappedErr, nil, fmt.Sprintf("%s is not found", cases.Title(language.English).String(entityName)))
+	} else if errors.Is(err, errs.ErrDateInClosedPeriod) {
+		return handleClosedPeriodError(err, methodName)
+	} else if errors.Is(err, errs.ErrCashUpDayClosed) {
+		return handleCashUpDayClosedError(err, methodName)
 	} else if errors.As(err, &validationErr) {
 		return errs.NewHTTPError(http.StatusConflict, wrappedErr, validationErr.GetErrorDetail(), fmt.Sprintf("Invalid %s properties. Please check whether the same %s already exists.", entityName, entityName))
 	}
@@ -2564,6 +4621,13 @@ func handleUpsertionError(err error, methodName, entityName string) errs.HTTPErr
 		return nil
 	}
 
+	if errors.Is(err, errs.ErrDateInClosedPeriod) {
+		return handleClosedPeriodError(err, methodName)
+	}
+	if errors.Is(err, errs.ErrCashUpDayClosed) {
+		return handleCashUpDayClosedError(err, methodName)
+	}
+
 	var validationErr errs.ValidationError
 	if errors.As(err, &validationErr) {
 		return errs.NewHTTPError(http.StatusConflict, fmt.Errorf("%s: %v", methodName, err), validationErr.GetErrorDetail(), fmt.Sprintf("Invalid %s properties. Please check whether the same %s already exists.", entityName, entityName))
@@ -2577,6 +4641,13 @@ func handleDeletionError(err error, methodName, entityName string) errs.HTTPErro
 		return nil
 	}
 
+	if errors.Is(err, errs.ErrDateInClosedPeriod) {
+		return handleClosedPeriodError(err, methodName)
+	}
+	if errors.Is(err, errs.ErrCashUpDayClosed) {
+		return handleCashUpDayClosedError(err, methodName)
+	}
+
 	var validationErr errs.ValidationError
 	if errors.As(err, &validationErr) {
 		return errs.NewHTTPError(
@@ -2588,3 +4659,37 @@ func handleDeletionError(err error, methodName, entityName string) errs.HTTPErro
 	}
 	return errs.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("%s: %v", methodName, err), nil, fmt.Sprintf("Failed to delete %s(s)", entityName))
 }
+
+// handleClosedPeriodError returns HTTP 422-UnprocessableEntity for write operations on records dated within a closed accounting period.
+func handleClosedPeriodError(err error, methodName string) errs.HTTPError {
+	return errs.NewHTTPError(
+		http.StatusUnprocessableEntity,
+		fmt.Errorf("%s: %v", methodName, err),
+		nil,
+		"The record is dated within a closed accounting period. Only a super admin can change it, by providing an override reason",
+	)
+}
+
+// handleCashUpDayClosedError returns HTTP 422-UnprocessableEntity for write operations on enrollmentPayments of a closed cash-up day.
+func handleCashUpDayClosedError(err error, methodName string) errs.HTTPError {
+	return errs.NewHTTPError(
+		http.StatusUnprocessableEntity,
+		fmt.Errorf("%s: %v", methodName, err),
+		nil,
+		"The enrollment payment is dated on a closed cash-up day. Ask an admin to reopen the day first",
+	)
+}
+
+// handleDiscountError returns HTTP 422-UnprocessableEntity when the submitted discountRules cannot be applied on the enrollmentPayment. Else, returns nil.
+func handleDiscountError(err error, methodName string) errs.HTTPError {
+	if errors.Is(err, errs.ErrDiscountRuleNotApplicable) {
+		return errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("%s: %v", methodName, err), map[string]string{"discountRuleIds": "discountRuleIds contain an expired, used up, or non-combinable discount"}, "Some discounts are no longer applicable. Please recheck the invoice")
+	}
+	if errors.Is(err, errs.ErrDiscountExceedsDiscountFee) {
+		return errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("%s: %v", methodName, err), map[string]string{"discountFeeValue": "discountFeeValue must be >= the total of the applied discounts"}, "Invalid discount fee value")
+	}
+	if errors.Is(err, errs.ErrDiscountFeeLocked) {
+		return errs.NewHTTPError(http.StatusUnprocessableEntity, fmt.Errorf("%s: %v", methodName, err), map[string]string{"discountFeeValue": "discountFeeValue cannot be changed as discountRules have been applied"}, "The discount fee of a payment with applied discounts cannot be edited. Remove & resubmit the payment instead")
+	}
+	return nil
+}
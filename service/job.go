@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"sonamusica-backend/app-service/teaching"
+)
+
+// RunSLTReconciliationJob periodically compares every StudentLearningToken quota against its EnrollmentPayments & Attendances,
+// and logs the drifted ones. It blocks until ctx is cancelled, so it should be run in a separate goroutine.
+func (s *BackendService) RunSLTReconciliationJob(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		mainLog.Info("SLT reconciliation job is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := s.teachingService.ReconcileSLTQuotas(ctx, teaching.ReconcileSLTQuotasSpec{Repair: false})
+			if err != nil {
+				mainLog.Error("teachingService.ReconcileSLTQuotas(): %v", err)
+				continue
+			}
+
+			for _, drift := range report.Drifts {
+				mainLog.Warn("StudentLearningToken with ID='%d' has drifted quota: quota='%v', expectedQuota='%v'", drift.StudentLearningTokenID, drift.StoredQuota, drift.ExpectedQuota)
+			}
+			if len(report.UnmatchedEnrollmentPaymentIDs) > 0 {
+				mainLog.Warn("EnrollmentPayments with no matching StudentLearningToken: %v", report.UnmatchedEnrollmentPaymentIDs)
+			}
+			mainLog.Info("SLT reconciliation job finished: %d drifted out of %d studentLearningToken(s)", len(report.Drifts), report.TotalCheckedTokens)
+		}
+	}
+}
+
+// RunCourseFeeSyncJob periodically applies the scheduled Course prices which have taken effect, into the Course's default fee.
+// It blocks until ctx is cancelled, so it should be run in a separate goroutine.
+func (s *BackendService) RunCourseFeeSyncJob(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		mainLog.Info("Course fee sync job is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			affectedCourses, err := s.entityService.SyncCourseDefaultFees(ctx)
+			if err != nil {
+				mainLog.Error("entityService.SyncCourseDefaultFees(): %v", err)
+				continue
+			}
+			mainLog.Info("Course fee sync job finished: %d course(s) updated", affectedCourses)
+		}
+	}
+}
+
+// RunStudentEnrollmentSyncJob periodically disables the StudentEnrollments whose withdrawal has taken effect.
+// It blocks until ctx is cancelled, so it should be run in a separate goroutine.
+func (s *BackendService) RunStudentEnrollmentSyncJob(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		mainLog.Info("Student enrollment sync job is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			affectedStudentEnrollments, err := s.entityService.DisableEndedStudentEnrollments(ctx)
+			if err != nil {
+				mainLog.Error("entityService.DisableEndedStudentEnrollments(): %v", err)
+				continue
+			}
+			mainLog.Info("Student enrollment sync job finished: %d studentEnrollment(s) disabled", affectedStudentEnrollments)
+		}
+	}
+}
+
+// RunPaymentReminderJob periodically emails the payment reminders, following the cadence & the days before penalty in config.
+// It blocks until ctx is cancelled, so it should be run in a separate goroutine.
+func (s *BackendService) RunPaymentReminderJob(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		mainLog.Info("Payment reminder job is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.teachingService.SendPaymentReminders(ctx, teaching.SendPaymentRemindersSpec{
+				Cadence:           configObject.PaymentReminderCadence,
+				DaysBeforePenalty: configObject.PaymentReminderDaysBeforePenalty,
+				DryRun:            false,
+			})
+			if err != nil {
+				mainLog.Error("teachingService.SendPaymentReminders(): %v", err)
+				continue
+			}
+
+			for _, failure := range result.Failures {
+				mainLog.Warn("Payment reminder of studentEnrollmentID='%d' is not sent: %s", failure.StudentEnrollmentID, failure.Reason)
+			}
+			mainLog.Info("Payment reminder job finished: %d sent, %d failed", len(result.Reminders), len(result.Failures))
+		}
+	}
+}
@@ -141,3 +141,52 @@ func (r GetDashboardIncomeMonthlySummaryRequest) Validate() errs.ValidationError
 	}
 	return nil
 }
+
+type GetDashboardDiscountMonthlySummaryRequest struct {
+	SelectedDate YearMonthFilter `json:"selectedDate"`
+}
+type GetDashboardDiscountMonthlySummaryResponse struct {
+	Data    GetDashboardDiscountMonthlySummaryResult `json:"data"`
+	Message string                                   `json:"message,omitempty"`
+}
+
+type GetDashboardDiscountMonthlySummaryResult struct {
+	Results []dashboard.MonthlySummaryResultItem `json:"results"`
+}
+
+func (r GetDashboardDiscountMonthlySummaryRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	// for dashboard monthly summary, it doesn't make sense to have empty selectedDate.
+	// user should pick a specific month of a year
+	if r.SelectedDate.Year == 0 || r.SelectedDate.Month == 0 {
+		errorDetail["selectedDate.year"] = "selectedDate.year must not be empty"
+		errorDetail["selectedDate.month"] = "selectedDate.month must not be empty"
+	} else {
+		if validationErr := r.SelectedDate.Validate(); validationErr != nil {
+			for key, value := range validationErr.GetErrorDetail() {
+				errorDetail[key] = value
+			}
+		}
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+// ============================== RECEIVABLE ==============================
+
+type GetDashboardReceivableReportRequest struct {
+	TeacherIDs    []entity.TeacherID    `json:"teacherIds"`
+	InstrumentIDs []entity.InstrumentID `json:"instrumentIds"`
+}
+type GetDashboardReceivableReportResponse struct {
+	Data    dashboard.ReceivableReport `json:"data"`
+	Message string                     `json:"message,omitempty"`
+}
+
+func (r GetDashboardReceivableReportRequest) Validate() errs.ValidationError {
+	return nil
+}
@@ -25,6 +25,13 @@ type ErrorResponse struct {
 	Message string            `json:"message,omitempty"`
 }
 
+// FileResponse is a non-JSON response, whose Content is written as-is into the HTTP response body by the serde_wrapper.
+type FileResponse struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
 type PaginationRequest struct {
 	Page           int `json:"page"`
 	ResultsPerPage int `json:"resultsPerPage"`
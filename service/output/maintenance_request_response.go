@@ -2,6 +2,7 @@ package output
 
 import (
 	"sonamusica-backend/app-service/identity"
+	"sonamusica-backend/app-service/teaching"
 	"sonamusica-backend/app-service/user_action_log"
 	"sonamusica-backend/errs"
 )
@@ -46,3 +47,16 @@ func (r FetchUserActionLogsRequest) Validate() errs.ValidationError {
 	}
 	return nil
 }
+
+type ReconcileSLTQuotasRequest struct {
+	// Repair=false only reports the drifted StudentLearningTokens, Repair=true also corrects their quota
+	Repair bool `json:"repair,omitempty"`
+}
+type ReconcileSLTQuotasResponse struct {
+	Data    teaching.SLTReconciliationReport `json:"data"`
+	Message string                           `json:"message,omitempty"`
+}
+
+func (r ReconcileSLTQuotasRequest) Validate() errs.ValidationError {
+	return nil
+}
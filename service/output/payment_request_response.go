@@ -0,0 +1,155 @@
+package output
+
+import (
+	"fmt"
+	"sonamusica-backend/app-service/entity"
+	"sonamusica-backend/app-service/payment"
+	"sonamusica-backend/errs"
+)
+
+const (
+	MaxPage_GetBankStatementImports           = Default_MaxPage
+	MaxResultsPerPage_GetBankStatementImports = Default_MaxResultsPerPage
+
+	MaxSize_BankStatementFile       = 1024 * 1024 // 1 MB, a monthly statement is usually only a few hundred KB
+	MaxLength_BankStatementFileName = 255
+)
+
+// ============================== BANK_STATEMENT ==============================
+
+type ImportBankStatementRequest struct {
+	BankFormat payment.BankFormat `json:"bankFormat"`
+	FileName   string             `json:"fileName"`
+	// Content is the base64-encoded CSV file
+	Content []byte `json:"content"`
+}
+type ImportBankStatementResponse struct {
+	Data    payment.GetBankStatementImportByIdResult `json:"data"`
+	Message string                                   `json:"message,omitempty"`
+}
+
+func (r ImportBankStatementRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if r.BankFormat == "" {
+		errorDetail["bankFormat"] = "bankFormat is required"
+	}
+	if len(r.FileName) > MaxLength_BankStatementFileName {
+		errorDetail["fileName"] = fmt.Sprintf("fileName must be <= %d characters", MaxLength_BankStatementFileName)
+	}
+	if len(r.Content) == 0 {
+		errorDetail["content"] = "content is required"
+	} else if len(r.Content) > MaxSize_BankStatementFile {
+		errorDetail["content"] = fmt.Sprintf("content must be <= %d bytes", MaxSize_BankStatementFile)
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type GetBankStatementImportsRequest struct {
+	PaginationRequest
+}
+type GetBankStatementImportsResponse struct {
+	Data    GetBankStatementImportsResult `json:"data"`
+	Message string                        `json:"message,omitempty"`
+}
+type GetBankStatementImportsResult struct {
+	Results []payment.BankStatementImport `json:"results"`
+	PaginationResponse
+}
+
+func (r GetBankStatementImportsRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+	if validationErr := r.PaginationRequest.Validate(MaxPage_GetBankStatementImports, MaxResultsPerPage_GetBankStatementImports); validationErr != nil {
+		errorDetail = validationErr.GetErrorDetail()
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type GetBankStatementImportRequest struct {
+	BankStatementImportID payment.BankStatementImportID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+}
+type GetBankStatementImportResponse struct {
+	Data    payment.GetBankStatementImportByIdResult `json:"data"`
+	Message string                                   `json:"message,omitempty"`
+}
+
+func (r GetBankStatementImportRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type ConfirmBankStatementLineMatchRequest struct {
+	BankStatementLineID payment.BankStatementLineID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+	// when provided, the line is matched to this enrollmentPayment instead of the auto-matched one
+	EnrollmentPaymentID entity.EnrollmentPaymentID `json:"enrollmentPaymentId,omitempty"`
+}
+type ConfirmBankStatementLineMatchResponse struct {
+	Message string `json:"message,omitempty"`
+}
+
+func (r ConfirmBankStatementLineMatchRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type UnmatchBankStatementLineRequest struct {
+	BankStatementLineID payment.BankStatementLineID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+}
+type UnmatchBankStatementLineResponse struct {
+	Message string `json:"message,omitempty"`
+}
+
+func (r UnmatchBankStatementLineRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type CreateEnrollmentPaymentFromBankStatementLineRequest struct {
+	BankStatementLineID payment.BankStatementLineID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+	StudentEnrollmentID entity.StudentEnrollmentID  `json:"studentEnrollmentId"`
+	BalanceTopUp        int32                       `json:"balanceTopUp"`
+	BalanceBonus        int32                       `json:"balanceBonus,omitempty"`
+	CourseFeeValue      int32                       `json:"courseFeeValue,omitempty"`
+	TransportFeeValue   int32                       `json:"transportFeeValue,omitempty"`
+	PenaltyFeeValue     int32                       `json:"penaltyFeeValue,omitempty"`
+	DiscountFeeValue    int32                       `json:"discountFeeValue,omitempty"`
+	ReceivingAccount    string                      `json:"receivingAccount,omitempty"`
+}
+type CreateEnrollmentPaymentFromBankStatementLineResponse struct {
+	Data    entity.EnrollmentPayment `json:"data"`
+	Message string                   `json:"message,omitempty"`
+}
+
+func (r CreateEnrollmentPaymentFromBankStatementLineRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if r.StudentEnrollmentID == entity.StudentEnrollmentID_None {
+		errorDetail["studentEnrollmentId"] = "studentEnrollmentId is required"
+	}
+	if r.BalanceTopUp < 0 {
+		errorDetail["balanceTopUp"] = "balanceTopUp must be >= 0"
+	}
+	if r.CourseFeeValue < 0 {
+		errorDetail["courseFeeValue"] = "courseFeeValue must be >= 0"
+	}
+	if r.TransportFeeValue < 0 {
+		errorDetail["transportFeeValue"] = "transportFeeValue must be >= 0"
+	}
+	if r.PenaltyFeeValue < 0 {
+		errorDetail["penaltyFeeValue"] = "penaltyFeeValue must be >= 0"
+	}
+	if r.DiscountFeeValue < 0 {
+		errorDetail["discountFeeValue"] = "discountFeeValue must be >= 0"
+	}
+	validatePaymentMethod(errorDetail, "", entity.PaymentMethod_BankTransfer, r.ReceivingAccount, "")
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package output
 import (
 	"fmt"
 	"sonamusica-backend/app-service/entity"
+	"sonamusica-backend/app-service/identity"
 	"sonamusica-backend/app-service/teaching"
 	"sonamusica-backend/errs"
 	"time"
@@ -24,9 +25,21 @@ const (
 	MaxPage_GetStudentEnrollments           = Default_MaxPage
 	MaxResultsPerPage_GetStudentEnrollments = Default_MaxResultsPerPage
 
+	MaxPage_GetFamilies           = Default_MaxPage
+	MaxResultsPerPage_GetFamilies = Default_MaxResultsPerPage
+
 	MaxPage_GetTeacherSpecialFees           = Default_MaxPage
 	MaxResultsPerPage_GetTeacherSpecialFees = Default_MaxResultsPerPage
 
+	MaxPage_GetPenaltyPolicies           = Default_MaxPage
+	MaxResultsPerPage_GetPenaltyPolicies = Default_MaxResultsPerPage
+
+	MaxPage_GetDiscountRules           = Default_MaxPage
+	MaxResultsPerPage_GetDiscountRules = Default_MaxResultsPerPage
+
+	MaxPage_GetTeacherFeeSharings           = Default_MaxPage
+	MaxResultsPerPage_GetTeacherFeeSharings = Default_MaxResultsPerPage
+
 	MaxPage_GetEnrollmentPayments           = Default_MaxPage
 	MaxResultsPerPage_GetEnrollmentPayments = Default_MaxResultsPerPage
 
@@ -38,6 +51,26 @@ const (
 
 	MaxPage_GetTeacherPayments           = Default_MaxPage
 	MaxResultsPerPage_GetTeacherPayments = Default_MaxResultsPerPage
+
+	MaxPage_GetClosedPeriods           = Default_MaxPage
+	MaxResultsPerPage_GetClosedPeriods = Default_MaxResultsPerPage
+
+	MaxPage_GetClosedPeriodOverrides           = Default_MaxPage
+	MaxResultsPerPage_GetClosedPeriodOverrides = Default_MaxResultsPerPage
+
+	// follows the column sizes of table "enrollment_payment"
+	MaxLength_PaymentReceivingAccount = 64
+	MaxLength_PaymentReferenceNumber  = 64
+
+	// follows the column sizes of table "family"
+	MaxLength_FamilyName = 128
+
+	MaxLength_StudentEnrollmentHoldReason       = 255
+	MaxLength_StudentEnrollmentWithdrawalReason = 255
+
+	// follows the column sizes of table "discount_rule"
+	MaxLength_DiscountRuleName = 64
+	MaxLength_VoucherCode      = 32
 )
 
 // ============================== INSTRUMENT ==============================
@@ -306,6 +339,55 @@ func (r DeleteCoursesRequest) Validate() errs.ValidationError {
 	return nil
 }
 
+type GetCourseFeeHistoriesRequest struct {
+	CourseID entity.CourseID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+}
+type GetCourseFeeHistoriesResponse struct {
+	Data    GetCourseFeeHistoriesResult `json:"data"`
+	Message string                      `json:"message,omitempty"`
+}
+type GetCourseFeeHistoriesResult struct {
+	CourseFeeHistories         []entity.CourseFeeHistory         `json:"courseFeeHistories"`
+	TeacherSpecialFeeHistories []entity.TeacherSpecialFeeHistory `json:"teacherSpecialFeeHistories"`
+}
+
+func (r GetCourseFeeHistoriesRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type InsertCourseFeeHistoriesRequest struct {
+	CourseID entity.CourseID                        `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+	Data     []InsertCourseFeeHistoriesRequestParam `json:"data"`
+}
+type InsertCourseFeeHistoriesRequestParam struct {
+	// TeacherID is set to insert a TeacherSpecialFee history, otherwise a Course fee history is inserted
+	TeacherID     entity.TeacherID `json:"teacherId,omitempty"`
+	Fee           int32            `json:"fee,omitempty"`
+	EffectiveFrom time.Time        `json:"effectiveFrom"`
+}
+type InsertCourseFeeHistoriesResponse struct {
+	Data    GetCourseFeeHistoriesResult `json:"data"`
+	Message string                      `json:"message,omitempty"`
+}
+
+func (r InsertCourseFeeHistoriesRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	for i, datum := range r.Data {
+		if datum.Fee < 0 {
+			errorDetail[fmt.Sprintf("data.%d.fee", i)] = "fee must be >= 0"
+		}
+		if datum.TeacherID == entity.TeacherID_None && datum.Fee == 0 {
+			errorDetail[fmt.Sprintf("data.%d.fee", i)] = "fee must be > 0 for course fee history"
+		}
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
 // ============================== CLASS ==============================
 
 type GetClassesRequest struct {
@@ -428,24 +510,42 @@ func (r GetStudentEnrollmentsRequest) Validate() errs.ValidationError {
 	return nil
 }
 
-// ============================== TEACHER_SPECIAL_FEE ==============================
+type GetStudentEnrollmentHistoriesRequest struct {
+	StudentID entity.StudentID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+}
+type GetStudentEnrollmentHistoriesResponse struct {
+	Data    GetStudentEnrollmentHistoriesResult `json:"data"`
+	Message string                              `json:"message,omitempty"`
+}
+type GetStudentEnrollmentHistoriesResult struct {
+	Results []entity.StudentEnrollmentHistory `json:"results"`
+}
 
-type GetTeacherSpecialFeesRequest struct {
-	PaginationRequest
+func (r GetStudentEnrollmentHistoriesRequest) Validate() errs.ValidationError {
+	return nil
 }
-type GetTeacherSpecialFeesResponse struct {
-	Data    GetTeacherSpecialFeesResult `json:"data"`
-	Message string                      `json:"message,omitempty"`
+
+type EnrollStudentRequest struct {
+	StudentID entity.StudentID `json:"studentId"`
+	ClassID   entity.ClassID   `json:"classId"`
+	StartDate time.Time        `json:"startDate"` // in RFC3339 format: "2023-12-30T00:00:00+07:00", only the date (in GMT+7) is used
 }
-type GetTeacherSpecialFeesResult struct {
-	Results []entity.TeacherSpecialFee `json:"results"`
-	PaginationResponse
+type EnrollStudentResponse struct {
+	Data    entity.StudentEnrollment `json:"data"`
+	Message string                   `json:"message,omitempty"`
 }
 
-func (r GetTeacherSpecialFeesRequest) Validate() errs.ValidationError {
+func (r EnrollStudentRequest) Validate() errs.ValidationError {
 	errorDetail := make(errs.ValidationErrorDetail, 0)
-	if validationErr := r.PaginationRequest.Validate(MaxPage_GetTeacherSpecialFees, MaxResultsPerPage_GetTeacherSpecialFees); validationErr != nil {
-		errorDetail = validationErr.GetErrorDetail()
+
+	if r.StudentID == entity.StudentID_None {
+		errorDetail["studentId"] = "studentId is required"
+	}
+	if r.ClassID == entity.ClassID_None {
+		errorDetail["classId"] = "classId is required"
+	}
+	if r.StartDate.IsZero() {
+		errorDetail["startDate"] = "startDate is required"
 	}
 
 	if len(errorDetail) > 0 {
@@ -454,150 +554,221 @@ func (r GetTeacherSpecialFeesRequest) Validate() errs.ValidationError {
 	return nil
 }
 
-type GetTeacherSpecialFeeRequest struct {
-	TeacherSpecialFeeID entity.TeacherSpecialFeeID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+type WithdrawStudentEnrollmentRequest struct {
+	StudentEnrollmentID entity.StudentEnrollmentID `json:"studentEnrollmentId"`
+	EndDate             time.Time                  `json:"endDate"` // inclusive (the student's last active date), in the same format as EnrollStudentRequest.StartDate
+	Reason              string                     `json:"reason,omitempty"`
 }
-type GetTeacherSpecialFeeResponse struct {
-	Data    entity.TeacherSpecialFee `json:"data"`
+type WithdrawStudentEnrollmentResponse struct {
+	Message string `json:"message,omitempty"`
+}
+
+func (r WithdrawStudentEnrollmentRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if r.StudentEnrollmentID == entity.StudentEnrollmentID_None {
+		errorDetail["studentEnrollmentId"] = "studentEnrollmentId is required"
+	}
+	if r.EndDate.IsZero() {
+		errorDetail["endDate"] = "endDate is required"
+	}
+	if len(r.Reason) > MaxLength_StudentEnrollmentWithdrawalReason {
+		errorDetail["reason"] = fmt.Sprintf("reason must be <= %d characters", MaxLength_StudentEnrollmentWithdrawalReason)
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type ReEnrollStudentEnrollmentRequest struct {
+	StudentEnrollmentID entity.StudentEnrollmentID `json:"studentEnrollmentId"`
+	StartDate           time.Time                  `json:"startDate"` // in the same format as EnrollStudentRequest.StartDate
+}
+type ReEnrollStudentEnrollmentResponse struct {
+	Data    entity.StudentEnrollment `json:"data"`
 	Message string                   `json:"message,omitempty"`
 }
 
-func (r GetTeacherSpecialFeeRequest) Validate() errs.ValidationError {
+func (r ReEnrollStudentEnrollmentRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if r.StudentEnrollmentID == entity.StudentEnrollmentID_None {
+		errorDetail["studentEnrollmentId"] = "studentEnrollmentId is required"
+	}
+	if r.StartDate.IsZero() {
+		errorDetail["startDate"] = "startDate is required"
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
 	return nil
 }
 
-type InsertTeacherSpecialFeesRequest struct {
-	Data []InsertTeacherSpecialFeesRequestParam `json:"data"`
+// ============================== STUDENT_ENROLLMENT_HOLD ==============================
+
+type GetStudentEnrollmentHoldsRequest struct {
+	StudentEnrollmentID entity.StudentEnrollmentID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
 }
-type InsertTeacherSpecialFeesRequestParam struct {
-	TeacherID entity.TeacherID `json:"teacherId"`
-	CourseID  entity.CourseID  `json:"courseId"`
-	Fee       int32            `json:"fee"`
+type GetStudentEnrollmentHoldsResponse struct {
+	Data    UpsertStudentEnrollmentHoldResult `json:"data"`
+	Message string                            `json:"message,omitempty"`
 }
-type InsertTeacherSpecialFeesResponse struct {
-	Data    UpsertTeacherSpecialFeeResult `json:"data"`
-	Message string                        `json:"message,omitempty"`
+
+func (r GetStudentEnrollmentHoldsRequest) Validate() errs.ValidationError {
+	return nil
 }
 
-func (r InsertTeacherSpecialFeesRequest) Validate() errs.ValidationError {
+type InsertStudentEnrollmentHoldsRequest struct {
+	Data []InsertStudentEnrollmentHoldsRequestParam `json:"data"`
+}
+type InsertStudentEnrollmentHoldsRequestParam struct {
+	StudentEnrollmentID entity.StudentEnrollmentID `json:"studentEnrollmentId"`
+	StartDate           time.Time                  `json:"startDate"` // in RFC3339 format: "2023-12-30T00:00:00+07:00", only the date (in GMT+7) is used
+	EndDate             time.Time                  `json:"endDate"`   // inclusive, in the same format as startDate
+	Reason              string                     `json:"reason"`
+}
+type InsertStudentEnrollmentHoldsResponse struct {
+	Data    UpsertStudentEnrollmentHoldResult `json:"data"`
+	Message string                            `json:"message,omitempty"`
+}
+
+func (r InsertStudentEnrollmentHoldsRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	for i, datum := range r.Data {
+		if datum.StudentEnrollmentID == entity.StudentEnrollmentID_None {
+			errorDetail[fmt.Sprintf("data.%d.studentEnrollmentId", i)] = "studentEnrollmentId is required"
+		}
+		validateStudentEnrollmentHold(errorDetail, fmt.Sprintf("data.%d.", i), datum.StartDate, datum.EndDate, datum.Reason)
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
 	return nil
 }
 
-type UpdateTeacherSpecialFeesRequest struct {
-	Data []UpdateTeacherSpecialFeesRequestParam `json:"data"`
+type UpdateStudentEnrollmentHoldsRequest struct {
+	Data []UpdateStudentEnrollmentHoldsRequestParam `json:"data"`
 }
-type UpdateTeacherSpecialFeesRequestParam struct {
-	TeacherSpecialFeeID entity.TeacherSpecialFeeID `json:"teacherSpecialFeeId"`
-	Fee                 int32                      `json:"fee"`
+type UpdateStudentEnrollmentHoldsRequestParam struct {
+	StudentEnrollmentHoldID entity.StudentEnrollmentHoldID `json:"studentEnrollmentHoldId"`
+	StartDate               time.Time                      `json:"startDate"`
+	EndDate                 time.Time                      `json:"endDate"`
+	Reason                  string                         `json:"reason"`
 }
-type UpdateTeacherSpecialFeesResponse struct {
-	Data    UpsertTeacherSpecialFeeResult `json:"data"`
-	Message string                        `json:"message,omitempty"`
+type UpdateStudentEnrollmentHoldsResponse struct {
+	Data    UpsertStudentEnrollmentHoldResult `json:"data"`
+	Message string                            `json:"message,omitempty"`
 }
 
-func (r UpdateTeacherSpecialFeesRequest) Validate() errs.ValidationError {
+func (r UpdateStudentEnrollmentHoldsRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	for i, datum := range r.Data {
+		validateStudentEnrollmentHold(errorDetail, fmt.Sprintf("data.%d.", i), datum.StartDate, datum.EndDate, datum.Reason)
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
 	return nil
 }
 
-type UpsertTeacherSpecialFeeResult struct {
-	Results []entity.TeacherSpecialFee `json:"results"`
+func validateStudentEnrollmentHold(errorDetail errs.ValidationErrorDetail, fieldPrefix string, startDate time.Time, endDate time.Time, reason string) {
+	if startDate.IsZero() {
+		errorDetail[fieldPrefix+"startDate"] = "startDate is required"
+	}
+	if endDate.IsZero() {
+		errorDetail[fieldPrefix+"endDate"] = "endDate is required"
+	} else if endDate.Before(startDate) {
+		errorDetail[fieldPrefix+"endDate"] = "endDate must be >= startDate"
+	}
+	if reason == "" {
+		errorDetail[fieldPrefix+"reason"] = "reason is required"
+	} else if len(reason) > MaxLength_StudentEnrollmentHoldReason {
+		errorDetail[fieldPrefix+"reason"] = fmt.Sprintf("reason must be <= %d characters", MaxLength_StudentEnrollmentHoldReason)
+	}
 }
 
-type DeleteTeacherSpecialFeesRequest struct {
-	Data []DeleteTeacherSpecialFeesRequestParam `json:"data"`
+type UpsertStudentEnrollmentHoldResult struct {
+	Results []entity.StudentEnrollmentHold `json:"results"`
 }
-type DeleteTeacherSpecialFeesRequestParam struct {
-	TeacherSpecialFeeID entity.TeacherSpecialFeeID `json:"teacherSpecialFeeId"`
+
+type DeleteStudentEnrollmentHoldsRequest struct {
+	Data []DeleteStudentEnrollmentHoldsRequestParam `json:"data"`
 }
-type DeleteTeacherSpecialFeesResponse struct {
+type DeleteStudentEnrollmentHoldsRequestParam struct {
+	StudentEnrollmentHoldID entity.StudentEnrollmentHoldID `json:"studentEnrollmentHoldId"`
+}
+type DeleteStudentEnrollmentHoldsResponse struct {
 	Message string `json:"message,omitempty"`
 }
 
-func (r DeleteTeacherSpecialFeesRequest) Validate() errs.ValidationError {
+func (r DeleteStudentEnrollmentHoldsRequest) Validate() errs.ValidationError {
 	return nil
 }
 
-// ============================== ENROLLMENT_PAYMENT ==============================
+// ============================== FAMILY ==============================
 
-type GetEnrollmentPaymentsRequest struct {
+type GetFamiliesRequest struct {
 	PaginationRequest
-	TimeFilter
 }
-type GetEnrollmentPaymentsResponse struct {
-	Data    GetEnrollmentPaymentsResult `json:"data"`
-	Message string                      `json:"message,omitempty"`
+type GetFamiliesResponse struct {
+	Data    GetFamiliesResult `json:"data"`
+	Message string            `json:"message,omitempty"`
 }
-type GetEnrollmentPaymentsResult struct {
-	Results []entity.EnrollmentPayment `json:"results"`
+type GetFamiliesResult struct {
+	Results []entity.Family `json:"results"`
 	PaginationResponse
 }
 
-func (r GetEnrollmentPaymentsRequest) Validate() errs.ValidationError {
+func (r GetFamiliesRequest) Validate() errs.ValidationError {
 	errorDetail := make(errs.ValidationErrorDetail, 0)
-	if validationErr := r.PaginationRequest.Validate(MaxPage_GetEnrollmentPayments, MaxResultsPerPage_GetEnrollmentPayments); validationErr != nil {
+	if validationErr := r.PaginationRequest.Validate(MaxPage_GetFamilies, MaxResultsPerPage_GetFamilies); validationErr != nil {
 		errorDetail = validationErr.GetErrorDetail()
 	}
 
-	if validationErr := r.TimeFilter.Validate(); validationErr != nil {
-		for key, value := range validationErr.GetErrorDetail() {
-			errorDetail[key] = value
-		}
-	}
-
 	if len(errorDetail) > 0 {
 		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
 	}
 	return nil
 }
 
-type GetEnrollmentPaymentRequest struct {
-	EnrollmentPaymentID entity.EnrollmentPaymentID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+type GetFamilyRequest struct {
+	FamilyID entity.FamilyID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
 }
-type GetEnrollmentPaymentResponse struct {
-	Data    entity.EnrollmentPayment `json:"data"`
-	Message string                   `json:"message,omitempty"`
+type GetFamilyResponse struct {
+	Data    entity.Family `json:"data"`
+	Message string        `json:"message,omitempty"`
 }
 
-func (r GetEnrollmentPaymentRequest) Validate() errs.ValidationError {
+func (r GetFamilyRequest) Validate() errs.ValidationError {
 	return nil
 }
 
-type InsertEnrollmentPaymentsRequest struct {
-	Data []InsertEnrollmentPaymentsRequestParam `json:"data"`
+type InsertFamiliesRequest struct {
+	Data []InsertFamiliesRequestParam `json:"data"`
 }
-type InsertEnrollmentPaymentsRequestParam struct {
-	StudentEnrollmentID entity.StudentEnrollmentID `json:"studentEnrollmentId"`
-	PaymentDate         time.Time                  `json:"paymentDate"` // in RFC3339 format: "2023-12-30T14:58:10+07:00"
-	BalanceTopUp        int32                      `json:"balanceTopUp"`
-	BalanceBonus        int32                      `json:"balanceBonus,omitempty"`
-	CourseFeeValue      int32                      `json:"courseFeeValue,omitempty"`
-	TransportFeeValue   int32                      `json:"transportFeeValue,omitempty"`
-	PenaltyFeeValue     int32                      `json:"penaltyFeeValue,omitempty"`
-	DiscountFeeValue    int32                      `json:"discountFeeValue,omitempty"`
+type InsertFamiliesRequestParam struct {
+	Name            string             `json:"name"`
+	StudentIDs      []entity.StudentID `json:"studentIds"`
+	GuardianUserIDs []identity.UserID  `json:"guardianUserIds,omitempty"`
 }
-type InsertEnrollmentPaymentsResponse struct {
-	Data    UpsertEnrollmentPaymentResult `json:"data"`
-	Message string                        `json:"message,omitempty"`
+type InsertFamiliesResponse struct {
+	Data    UpsertFamilyResult `json:"data"`
+	Message string             `json:"message,omitempty"`
 }
 
-func (r InsertEnrollmentPaymentsRequest) Validate() errs.ValidationError {
+func (r InsertFamiliesRequest) Validate() errs.ValidationError {
 	errorDetail := make(errs.ValidationErrorDetail, 0)
 
 	for i, datum := range r.Data {
-		if datum.BalanceTopUp < 0 {
-			errorDetail[fmt.Sprintf("data.%d.balanceTopUp", i)] = "balanceTopUp must be >= 0"
-		}
-		if datum.CourseFeeValue < 0 {
-			errorDetail[fmt.Sprintf("data.%d.courseFeeValue", i)] = "courseFeeValue must be >= 0"
-		}
-		if datum.TransportFeeValue < 0 {
-			errorDetail[fmt.Sprintf("data.%d.transportFeeValue", i)] = "transportFeeValue must be >= 0"
-		}
-		if datum.PenaltyFeeValue < 0 {
-			errorDetail[fmt.Sprintf("data.%d.penaltyFeeValue", i)] = "penaltyFeeValue must be >= 0"
-		}
-		if datum.DiscountFeeValue < 0 {
-			errorDetail[fmt.Sprintf("data.%d.discountFeeValue", i)] = "discountFeeValue must be >= 0"
+		if len(datum.Name) > MaxLength_FamilyName {
+			errorDetail[fmt.Sprintf("data.%d.name", i)] = fmt.Sprintf("name must be <= %d characters", MaxLength_FamilyName)
 		}
 	}
 
@@ -607,42 +778,26 @@ func (r InsertEnrollmentPaymentsRequest) Validate() errs.ValidationError {
 	return nil
 }
 
-type UpdateEnrollmentPaymentsRequest struct {
-	Data []UpdateEnrollmentPaymentsRequestParam `json:"data"`
+type UpdateFamiliesRequest struct {
+	Data []UpdateFamiliesRequestParam `json:"data"`
 }
-type UpdateEnrollmentPaymentsRequestParam struct {
-	EnrollmentPaymentID entity.EnrollmentPaymentID `json:"enrollmentPaymentId"`
-	PaymentDate         time.Time                  `json:"paymentDate"` // in RFC3339 format: "2023-12-30T14:58:10+07:00"
-	BalanceTopUp        int32                      `json:"balanceTopUp,omitempty"`
-	BalanceBonus        int32                      `json:"balanceBonus,omitempty"`
-	CourseFeeValue      int32                      `json:"courseFeeValue,omitempty"`
-	TransportFeeValue   int32                      `json:"transportFeeValue,omitempty"`
-	PenaltyFeeValue     int32                      `json:"penaltyFeeValue,omitempty"`
-	DiscountFeeValue    int32                      `json:"discountFeeValue,omitempty"`
+type UpdateFamiliesRequestParam struct {
+	FamilyID        entity.FamilyID    `json:"familyId"`
+	Name            string             `json:"name"`
+	StudentIDs      []entity.StudentID `json:"studentIds"`
+	GuardianUserIDs []identity.UserID  `json:"guardianUserIds,omitempty"`
 }
-type UpdateEnrollmentPaymentsResponse struct {
-	Data    UpsertEnrollmentPaymentResult `json:"data"`
-	Message string                        `json:"message,omitempty"`
+type UpdateFamiliesResponse struct {
+	Data    UpsertFamilyResult `json:"data"`
+	Message string             `json:"message,omitempty"`
 }
 
-func (r UpdateEnrollmentPaymentsRequest) Validate() errs.ValidationError {
+func (r UpdateFamiliesRequest) Validate() errs.ValidationError {
 	errorDetail := make(errs.ValidationErrorDetail, 0)
 
 	for i, datum := range r.Data {
-		if datum.BalanceTopUp < 0 {
-			errorDetail[fmt.Sprintf("data.%d.balanceTopUp", i)] = "balanceTopUp must be >= 0"
-		}
-		if datum.CourseFeeValue < 0 {
-			errorDetail[fmt.Sprintf("data.%d.courseFeeValue", i)] = "courseFeeValue must be >= 0"
-		}
-		if datum.TransportFeeValue < 0 {
-			errorDetail[fmt.Sprintf("data.%d.transportFeeValue", i)] = "transportFeeValue must be >= 0"
-		}
-		if datum.PenaltyFeeValue < 0 {
-			errorDetail[fmt.Sprintf("data.%d.penaltyFeeValue", i)] = "penaltyFeeValue must be >= 0"
-		}
-		if datum.DiscountFeeValue < 0 {
-			errorDetail[fmt.Sprintf("data.%d.discountFeeValue", i)] = "discountFeeValue must be >= 0"
+		if len(datum.Name) > MaxLength_FamilyName {
+			errorDetail[fmt.Sprintf("data.%d.name", i)] = fmt.Sprintf("name must be <= %d characters", MaxLength_FamilyName)
 		}
 	}
 
@@ -652,41 +807,752 @@ func (r UpdateEnrollmentPaymentsRequest) Validate() errs.ValidationError {
 	return nil
 }
 
-type UpsertEnrollmentPaymentResult struct {
-	Results []entity.EnrollmentPayment `json:"results"`
+type UpsertFamilyResult struct {
+	Results []entity.Family `json:"results"`
 }
 
-type DeleteEnrollmentPaymentsRequest struct {
-	Data []DeleteEnrollmentPaymentsRequestParam `json:"data"`
+type DeleteFamiliesRequest struct {
+	Data []DeleteFamiliesRequestParam `json:"data"`
 }
-type DeleteEnrollmentPaymentsRequestParam struct {
-	EnrollmentPaymentID entity.EnrollmentPaymentID `json:"enrollmentPaymentId"`
+type DeleteFamiliesRequestParam struct {
+	FamilyID entity.FamilyID `json:"familyId"`
 }
-type DeleteEnrollmentPaymentsResponse struct {
+type DeleteFamiliesResponse struct {
 	Message string `json:"message,omitempty"`
 }
 
-func (r DeleteEnrollmentPaymentsRequest) Validate() errs.ValidationError {
+func (r DeleteFamiliesRequest) Validate() errs.ValidationError {
 	return nil
 }
 
-// ============================== STUDENT_LEARNING_TOKEN ==============================
+// ============================== TEACHER_SPECIAL_FEE ==============================
 
-type GetStudentLearningTokensRequest struct {
+type GetTeacherSpecialFeesRequest struct {
 	PaginationRequest
 }
-type GetStudentLearningTokensResponse struct {
-	Data    GetStudentLearningTokensResult `json:"data"`
-	Message string                         `json:"message,omitempty"`
+type GetTeacherSpecialFeesResponse struct {
+	Data    GetTeacherSpecialFeesResult `json:"data"`
+	Message string                      `json:"message,omitempty"`
 }
-type GetStudentLearningTokensResult struct {
-	Results []entity.StudentLearningToken `json:"results"`
+type GetTeacherSpecialFeesResult struct {
+	Results []entity.TeacherSpecialFee `json:"results"`
 	PaginationResponse
 }
 
-func (r GetStudentLearningTokensRequest) Validate() errs.ValidationError {
+func (r GetTeacherSpecialFeesRequest) Validate() errs.ValidationError {
 	errorDetail := make(errs.ValidationErrorDetail, 0)
-	if validationErr := r.PaginationRequest.Validate(MaxPage_GetStudentLearningTokens, MaxResultsPerPage_GetStudentLearningTokens); validationErr != nil {
+	if validationErr := r.PaginationRequest.Validate(MaxPage_GetTeacherSpecialFees, MaxResultsPerPage_GetTeacherSpecialFees); validationErr != nil {
+		errorDetail = validationErr.GetErrorDetail()
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type GetTeacherSpecialFeeRequest struct {
+	TeacherSpecialFeeID entity.TeacherSpecialFeeID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+}
+type GetTeacherSpecialFeeResponse struct {
+	Data    entity.TeacherSpecialFee `json:"data"`
+	Message string                   `json:"message,omitempty"`
+}
+
+func (r GetTeacherSpecialFeeRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type InsertTeacherSpecialFeesRequest struct {
+	Data []InsertTeacherSpecialFeesRequestParam `json:"data"`
+}
+type InsertTeacherSpecialFeesRequestParam struct {
+	TeacherID entity.TeacherID `json:"teacherId"`
+	CourseID  entity.CourseID  `json:"courseId"`
+	Fee       int32            `json:"fee"`
+}
+type InsertTeacherSpecialFeesResponse struct {
+	Data    UpsertTeacherSpecialFeeResult `json:"data"`
+	Message string                        `json:"message,omitempty"`
+}
+
+func (r InsertTeacherSpecialFeesRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type UpdateTeacherSpecialFeesRequest struct {
+	Data []UpdateTeacherSpecialFeesRequestParam `json:"data"`
+}
+type UpdateTeacherSpecialFeesRequestParam struct {
+	TeacherSpecialFeeID entity.TeacherSpecialFeeID `json:"teacherSpecialFeeId"`
+	Fee                 int32                      `json:"fee"`
+}
+type UpdateTeacherSpecialFeesResponse struct {
+	Data    UpsertTeacherSpecialFeeResult `json:"data"`
+	Message string                        `json:"message,omitempty"`
+}
+
+func (r UpdateTeacherSpecialFeesRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type UpsertTeacherSpecialFeeResult struct {
+	Results []entity.TeacherSpecialFee `json:"results"`
+}
+
+type DeleteTeacherSpecialFeesRequest struct {
+	Data []DeleteTeacherSpecialFeesRequestParam `json:"data"`
+}
+type DeleteTeacherSpecialFeesRequestParam struct {
+	TeacherSpecialFeeID entity.TeacherSpecialFeeID `json:"teacherSpecialFeeId"`
+}
+type DeleteTeacherSpecialFeesResponse struct {
+	Message string `json:"message,omitempty"`
+}
+
+func (r DeleteTeacherSpecialFeesRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+// ============================== PENALTY_POLICY ==============================
+
+type GetPenaltyPoliciesRequest struct {
+	PaginationRequest
+}
+type GetPenaltyPoliciesResponse struct {
+	Data    GetPenaltyPoliciesResult `json:"data"`
+	Message string                   `json:"message,omitempty"`
+}
+type GetPenaltyPoliciesResult struct {
+	Results []entity.PenaltyPolicy `json:"results"`
+	PaginationResponse
+}
+
+func (r GetPenaltyPoliciesRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+	if validationErr := r.PaginationRequest.Validate(MaxPage_GetPenaltyPolicies, MaxResultsPerPage_GetPenaltyPolicies); validationErr != nil {
+		errorDetail = validationErr.GetErrorDetail()
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type GetPenaltyPolicyRequest struct {
+	PenaltyPolicyID entity.PenaltyPolicyID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+}
+type GetPenaltyPolicyResponse struct {
+	Data    entity.PenaltyPolicy `json:"data"`
+	Message string               `json:"message,omitempty"`
+}
+
+func (r GetPenaltyPolicyRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type InsertPenaltyPoliciesRequest struct {
+	Data []InsertPenaltyPoliciesRequestParam `json:"data"`
+}
+type InsertPenaltyPoliciesRequestParam struct {
+	Name string `json:"name"`
+	// leave both CourseID & ClassID empty to create the school-wide default policy, which is rejected when one already exists
+	CourseID          entity.CourseID `json:"courseId,omitempty"`
+	ClassID           entity.ClassID  `json:"classId,omitempty"`
+	TriggerDayOfMonth int32           `json:"triggerDayOfMonth"`
+	GraceDays         int32           `json:"graceDays,omitempty"`
+	IsFlatFee         bool            `json:"isFlatFee,omitempty"`
+	FeeValue          int32           `json:"feeValue"`
+	MaxFeeValue       int32           `json:"maxFeeValue,omitempty"`
+}
+type InsertPenaltyPoliciesResponse struct {
+	Data    UpsertPenaltyPolicyResult `json:"data"`
+	Message string                    `json:"message,omitempty"`
+}
+
+func (r InsertPenaltyPoliciesRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	defaultPolicyCount := 0
+	for i, datum := range r.Data {
+		if datum.CourseID != entity.CourseID_None && datum.ClassID != entity.ClassID_None {
+			errorDetail[fmt.Sprintf("data.%d.classId", i)] = "courseId and classId must not be set at the same time"
+		}
+		if datum.CourseID == entity.CourseID_None && datum.ClassID == entity.ClassID_None {
+			defaultPolicyCount++
+			if defaultPolicyCount > 1 {
+				errorDetail[fmt.Sprintf("data.%d.courseId", i)] = "only one school-wide default policy (without courseId and classId) may exist"
+			}
+		}
+		if datum.TriggerDayOfMonth < 1 || datum.TriggerDayOfMonth > 28 {
+			errorDetail[fmt.Sprintf("data.%d.triggerDayOfMonth", i)] = "triggerDayOfMonth must be between 1 and 28"
+		}
+		if datum.GraceDays < 0 {
+			errorDetail[fmt.Sprintf("data.%d.graceDays", i)] = "graceDays must be >= 0"
+		}
+		if datum.FeeValue < 0 {
+			errorDetail[fmt.Sprintf("data.%d.feeValue", i)] = "feeValue must be >= 0"
+		}
+		if datum.MaxFeeValue < 0 {
+			errorDetail[fmt.Sprintf("data.%d.maxFeeValue", i)] = "maxFeeValue must be >= 0"
+		}
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type UpdatePenaltyPoliciesRequest struct {
+	Data []UpdatePenaltyPoliciesRequestParam `json:"data"`
+}
+type UpdatePenaltyPoliciesRequestParam struct {
+	PenaltyPolicyID   entity.PenaltyPolicyID `json:"penaltyPolicyId"`
+	Name              string                 `json:"name"`
+	CourseID          entity.CourseID        `json:"courseId,omitempty"`
+	ClassID           entity.ClassID         `json:"classId,omitempty"`
+	TriggerDayOfMonth int32                  `json:"triggerDayOfMonth"`
+	GraceDays         int32                  `json:"graceDays,omitempty"`
+	IsFlatFee         bool                   `json:"isFlatFee,omitempty"`
+	FeeValue          int32                  `json:"feeValue"`
+	MaxFeeValue       int32                  `json:"maxFeeValue,omitempty"`
+}
+type UpdatePenaltyPoliciesResponse struct {
+	Data    UpsertPenaltyPolicyResult `json:"data"`
+	Message string                    `json:"message,omitempty"`
+}
+
+func (r UpdatePenaltyPoliciesRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	for i, datum := range r.Data {
+		if datum.CourseID != entity.CourseID_None && datum.ClassID != entity.ClassID_None {
+			errorDetail[fmt.Sprintf("data.%d.classId", i)] = "courseId and classId must not be set at the same time"
+		}
+		if datum.TriggerDayOfMonth < 1 || datum.TriggerDayOfMonth > 28 {
+			errorDetail[fmt.Sprintf("data.%d.triggerDayOfMonth", i)] = "triggerDayOfMonth must be between 1 and 28"
+		}
+		if datum.GraceDays < 0 {
+			errorDetail[fmt.Sprintf("data.%d.graceDays", i)] = "graceDays must be >= 0"
+		}
+		if datum.FeeValue < 0 {
+			errorDetail[fmt.Sprintf("data.%d.feeValue", i)] = "feeValue must be >= 0"
+		}
+		if datum.MaxFeeValue < 0 {
+			errorDetail[fmt.Sprintf("data.%d.maxFeeValue", i)] = "maxFeeValue must be >= 0"
+		}
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type UpsertPenaltyPolicyResult struct {
+	Results []entity.PenaltyPolicy `json:"results"`
+}
+
+type DeletePenaltyPoliciesRequest struct {
+	Data []DeletePenaltyPoliciesRequestParam `json:"data"`
+}
+type DeletePenaltyPoliciesRequestParam struct {
+	PenaltyPolicyID entity.PenaltyPolicyID `json:"penaltyPolicyId"`
+}
+type DeletePenaltyPoliciesResponse struct {
+	Message string `json:"message,omitempty"`
+}
+
+func (r DeletePenaltyPoliciesRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+// ============================== DISCOUNT_RULE ==============================
+
+type GetDiscountRulesRequest struct {
+	PaginationRequest
+}
+type GetDiscountRulesResponse struct {
+	Data    GetDiscountRulesResult `json:"data"`
+	Message string                 `json:"message,omitempty"`
+}
+type GetDiscountRulesResult struct {
+	Results []entity.DiscountRule `json:"results"`
+	PaginationResponse
+}
+
+func (r GetDiscountRulesRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+	if validationErr := r.PaginationRequest.Validate(MaxPage_GetDiscountRules, MaxResultsPerPage_GetDiscountRules); validationErr != nil {
+		errorDetail = validationErr.GetErrorDetail()
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type GetDiscountRuleRequest struct {
+	DiscountRuleID entity.DiscountRuleID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+}
+type GetDiscountRuleResponse struct {
+	Data    entity.DiscountRule `json:"data"`
+	Message string              `json:"message,omitempty"`
+}
+
+func (r GetDiscountRuleRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type InsertDiscountRulesRequest struct {
+	Data []InsertDiscountRulesRequestParam `json:"data"`
+}
+type InsertDiscountRulesRequestParam struct {
+	Name          string                  `json:"name"`
+	Type          entity.DiscountRuleType `json:"type"`
+	IsPercentage  bool                    `json:"isPercentage,omitempty"`
+	Value         int32                   `json:"value"`
+	MinCount      int32                   `json:"minCount,omitempty"`      // required for "SIBLING" & "MULTI_CLASS"
+	MaxDayOfMonth int32                   `json:"maxDayOfMonth,omitempty"` // required for "EARLY_PAYMENT"
+	VoucherCode   string                  `json:"voucherCode,omitempty"`   // required for "VOUCHER"
+	UsageLimit    int32                   `json:"usageLimit,omitempty"`    // leave empty for unlimited usages
+	ValidFrom     *time.Time              `json:"validFrom,omitempty"`
+	ValidUntil    *time.Time              `json:"validUntil,omitempty"`
+	IsDeactivated bool                    `json:"isDeactivated,omitempty"`
+}
+type InsertDiscountRulesResponse struct {
+	Data    UpsertDiscountRuleResult `json:"data"`
+	Message string                   `json:"message,omitempty"`
+}
+
+func (r InsertDiscountRulesRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	for i, datum := range r.Data {
+		validateDiscountRule(errorDetail, fmt.Sprintf("data.%d.", i), datum.Name, datum.Type, datum.IsPercentage, datum.Value, datum.MinCount, datum.MaxDayOfMonth, datum.VoucherCode, datum.UsageLimit, datum.ValidFrom, datum.ValidUntil)
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type UpdateDiscountRulesRequest struct {
+	Data []UpdateDiscountRulesRequestParam `json:"data"`
+}
+type UpdateDiscountRulesRequestParam struct {
+	DiscountRuleID entity.DiscountRuleID   `json:"discountRuleId"`
+	Name           string                  `json:"name"`
+	Type           entity.DiscountRuleType `json:"type"`
+	IsPercentage   bool                    `json:"isPercentage,omitempty"`
+	Value          int32                   `json:"value"`
+	MinCount       int32                   `json:"minCount,omitempty"`
+	MaxDayOfMonth  int32                   `json:"maxDayOfMonth,omitempty"`
+	VoucherCode    string                  `json:"voucherCode,omitempty"`
+	UsageLimit     int32                   `json:"usageLimit,omitempty"`
+	ValidFrom      *time.Time              `json:"validFrom,omitempty"`
+	ValidUntil     *time.Time              `json:"validUntil,omitempty"`
+	IsDeactivated  bool                    `json:"isDeactivated,omitempty"`
+}
+type UpdateDiscountRulesResponse struct {
+	Data    UpsertDiscountRuleResult `json:"data"`
+	Message string                   `json:"message,omitempty"`
+}
+
+func (r UpdateDiscountRulesRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	for i, datum := range r.Data {
+		validateDiscountRule(errorDetail, fmt.Sprintf("data.%d.", i), datum.Name, datum.Type, datum.IsPercentage, datum.Value, datum.MinCount, datum.MaxDayOfMonth, datum.VoucherCode, datum.UsageLimit, datum.ValidFrom, datum.ValidUntil)
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+func validateDiscountRule(errorDetail errs.ValidationErrorDetail, fieldPrefix string, name string, ruleType entity.DiscountRuleType, isPercentage bool, value int32, minCount int32, maxDayOfMonth int32, voucherCode string, usageLimit int32, validFrom *time.Time, validUntil *time.Time) {
+	if len(name) > MaxLength_DiscountRuleName {
+		errorDetail[fieldPrefix+"name"] = fmt.Sprintf("name must be <= %d characters", MaxLength_DiscountRuleName)
+	}
+
+	switch ruleType {
+	case entity.DiscountRuleType_Sibling, entity.DiscountRuleType_MultiClass:
+		if minCount < 2 {
+			errorDetail[fieldPrefix+"minCount"] = "minCount must be >= 2"
+		}
+	case entity.DiscountRuleType_EarlyPayment:
+		if maxDayOfMonth < 1 || maxDayOfMonth > 28 {
+			errorDetail[fieldPrefix+"maxDayOfMonth"] = "maxDayOfMonth must be between 1 and 28"
+		}
+	case entity.DiscountRuleType_Voucher:
+		if voucherCode == "" {
+			errorDetail[fieldPrefix+"voucherCode"] = "voucherCode is required for 'VOUCHER'"
+		}
+	default:
+		errorDetail[fieldPrefix+"type"] = fmt.Sprintf("type must be one of: '%s', '%s', '%s', '%s'",
+			entity.DiscountRuleType_Sibling, entity.DiscountRuleType_MultiClass, entity.DiscountRuleType_EarlyPayment, entity.DiscountRuleType_Voucher)
+	}
+	if ruleType != entity.DiscountRuleType_Voucher && voucherCode != "" {
+		errorDetail[fieldPrefix+"voucherCode"] = "voucherCode must only be set for 'VOUCHER'"
+	}
+	if len(voucherCode) > MaxLength_VoucherCode {
+		errorDetail[fieldPrefix+"voucherCode"] = fmt.Sprintf("voucherCode must be <= %d characters", MaxLength_VoucherCode)
+	}
+
+	if value <= 0 {
+		errorDetail[fieldPrefix+"value"] = "value must be > 0"
+	} else if isPercentage && value > 100 {
+		errorDetail[fieldPrefix+"value"] = "value must be <= 100 for a percentage discount"
+	}
+	if usageLimit < 0 {
+		errorDetail[fieldPrefix+"usageLimit"] = "usageLimit must be >= 0"
+	}
+	if validFrom != nil && validUntil != nil && validUntil.Before(*validFrom) {
+		errorDetail[fieldPrefix+"validUntil"] = "validUntil must be >= validFrom"
+	}
+}
+
+type UpsertDiscountRuleResult struct {
+	Results []entity.DiscountRule `json:"results"`
+}
+
+type DeleteDiscountRulesRequest struct {
+	Data []DeleteDiscountRulesRequestParam `json:"data"`
+}
+type DeleteDiscountRulesRequestParam struct {
+	DiscountRuleID entity.DiscountRuleID `json:"discountRuleId"`
+}
+type DeleteDiscountRulesResponse struct {
+	Message string `json:"message,omitempty"`
+}
+
+func (r DeleteDiscountRulesRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+// ============================== TEACHER_FEE_SHARING ==============================
+
+type GetTeacherFeeSharingsRequest struct {
+	PaginationRequest
+}
+type GetTeacherFeeSharingsResponse struct {
+	Data    GetTeacherFeeSharingsResult `json:"data"`
+	Message string                      `json:"message,omitempty"`
+}
+type GetTeacherFeeSharingsResult struct {
+	Results []entity.TeacherFeeSharing `json:"results"`
+	PaginationResponse
+}
+
+func (r GetTeacherFeeSharingsRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+	if validationErr := r.PaginationRequest.Validate(MaxPage_GetTeacherFeeSharings, MaxResultsPerPage_GetTeacherFeeSharings); validationErr != nil {
+		errorDetail = validationErr.GetErrorDetail()
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type GetTeacherFeeSharingRequest struct {
+	TeacherFeeSharingID entity.TeacherFeeSharingID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+}
+type GetTeacherFeeSharingResponse struct {
+	Data    entity.TeacherFeeSharing `json:"data"`
+	Message string                   `json:"message,omitempty"`
+}
+
+func (r GetTeacherFeeSharingRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type InsertTeacherFeeSharingsRequest struct {
+	Data []InsertTeacherFeeSharingsRequestParam `json:"data"`
+}
+type InsertTeacherFeeSharingsRequestParam struct {
+	// leave both TeacherID & CourseID empty to create the school-wide default rate
+	TeacherID                     entity.TeacherID `json:"teacherId,omitempty"`
+	CourseID                      entity.CourseID  `json:"courseId,omitempty"`
+	CourseFeeSharingPercentage    float64          `json:"courseFeeSharingPercentage,omitempty"`
+	TransportFeeSharingPercentage float64          `json:"transportFeeSharingPercentage,omitempty"`
+	EffectiveFrom                 time.Time        `json:"effectiveFrom"`
+}
+type InsertTeacherFeeSharingsResponse struct {
+	Data    UpsertTeacherFeeSharingResult `json:"data"`
+	Message string                        `json:"message,omitempty"`
+}
+
+func (r InsertTeacherFeeSharingsRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	for i, datum := range r.Data {
+		validateTeacherFeeSharingPercentages(errorDetail, i, datum.CourseFeeSharingPercentage, datum.TransportFeeSharingPercentage)
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type UpdateTeacherFeeSharingsRequest struct {
+	Data []UpdateTeacherFeeSharingsRequestParam `json:"data"`
+}
+type UpdateTeacherFeeSharingsRequestParam struct {
+	TeacherFeeSharingID           entity.TeacherFeeSharingID `json:"teacherFeeSharingId"`
+	TeacherID                     entity.TeacherID           `json:"teacherId,omitempty"`
+	CourseID                      entity.CourseID            `json:"courseId,omitempty"`
+	CourseFeeSharingPercentage    float64                    `json:"courseFeeSharingPercentage,omitempty"`
+	TransportFeeSharingPercentage float64                    `json:"transportFeeSharingPercentage,omitempty"`
+	EffectiveFrom                 time.Time                  `json:"effectiveFrom"`
+}
+type UpdateTeacherFeeSharingsResponse struct {
+	Data    UpsertTeacherFeeSharingResult `json:"data"`
+	Message string                        `json:"message,omitempty"`
+}
+
+func (r UpdateTeacherFeeSharingsRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	for i, datum := range r.Data {
+		validateTeacherFeeSharingPercentages(errorDetail, i, datum.CourseFeeSharingPercentage, datum.TransportFeeSharingPercentage)
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+func validateTeacherFeeSharingPercentages(errorDetail errs.ValidationErrorDetail, i int, courseFeeSharingPercentage, transportFeeSharingPercentage float64) {
+	if courseFeeSharingPercentage < 0 || courseFeeSharingPercentage > 1 {
+		errorDetail[fmt.Sprintf("data.%d.courseFeeSharingPercentage", i)] = "courseFeeSharingPercentage must be between 0 and 1"
+	}
+	if transportFeeSharingPercentage < 0 || transportFeeSharingPercentage > 1 {
+		errorDetail[fmt.Sprintf("data.%d.transportFeeSharingPercentage", i)] = "transportFeeSharingPercentage must be between 0 and 1"
+	}
+}
+
+type UpsertTeacherFeeSharingResult struct {
+	Results []entity.TeacherFeeSharing `json:"results"`
+}
+
+type DeleteTeacherFeeSharingsRequest struct {
+	Data []DeleteTeacherFeeSharingsRequestParam `json:"data"`
+}
+type DeleteTeacherFeeSharingsRequestParam struct {
+	TeacherFeeSharingID entity.TeacherFeeSharingID `json:"teacherFeeSharingId"`
+}
+type DeleteTeacherFeeSharingsResponse struct {
+	Message string `json:"message,omitempty"`
+}
+
+func (r DeleteTeacherFeeSharingsRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+// ============================== ENROLLMENT_PAYMENT ==============================
+
+type GetEnrollmentPaymentsRequest struct {
+	PaginationRequest
+	TimeFilter
+}
+type GetEnrollmentPaymentsResponse struct {
+	Data    GetEnrollmentPaymentsResult `json:"data"`
+	Message string                      `json:"message,omitempty"`
+}
+type GetEnrollmentPaymentsResult struct {
+	Results []entity.EnrollmentPayment `json:"results"`
+	PaginationResponse
+}
+
+func (r GetEnrollmentPaymentsRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+	if validationErr := r.PaginationRequest.Validate(MaxPage_GetEnrollmentPayments, MaxResultsPerPage_GetEnrollmentPayments); validationErr != nil {
+		errorDetail = validationErr.GetErrorDetail()
+	}
+
+	if validationErr := r.TimeFilter.Validate(); validationErr != nil {
+		for key, value := range validationErr.GetErrorDetail() {
+			errorDetail[key] = value
+		}
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type GetEnrollmentPaymentRequest struct {
+	EnrollmentPaymentID entity.EnrollmentPaymentID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+}
+type GetEnrollmentPaymentResponse struct {
+	Data    entity.EnrollmentPayment `json:"data"`
+	Message string                   `json:"message,omitempty"`
+}
+
+func (r GetEnrollmentPaymentRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type InsertEnrollmentPaymentsRequest struct {
+	Data []InsertEnrollmentPaymentsRequestParam `json:"data"`
+}
+type InsertEnrollmentPaymentsRequestParam struct {
+	StudentEnrollmentID entity.StudentEnrollmentID `json:"studentEnrollmentId"`
+	PaymentDate         time.Time                  `json:"paymentDate"` // in RFC3339 format: "2023-12-30T14:58:10+07:00"
+	BalanceTopUp        int32                      `json:"balanceTopUp"`
+	BalanceBonus        int32                      `json:"balanceBonus,omitempty"`
+	CourseFeeValue      int32                      `json:"courseFeeValue,omitempty"`
+	TransportFeeValue   int32                      `json:"transportFeeValue,omitempty"`
+	PenaltyFeeValue     int32                      `json:"penaltyFeeValue,omitempty"`
+	DiscountFeeValue    int32                      `json:"discountFeeValue,omitempty"`
+	PaymentMethod       entity.PaymentMethod       `json:"paymentMethod,omitempty"` // defaults to "CASH"
+	ReceivingAccount    string                     `json:"receivingAccount,omitempty"`
+	ReferenceNumber     string                     `json:"referenceNumber,omitempty"`
+}
+type InsertEnrollmentPaymentsResponse struct {
+	Data    UpsertEnrollmentPaymentResult `json:"data"`
+	Message string                        `json:"message,omitempty"`
+}
+
+func (r InsertEnrollmentPaymentsRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	for i, datum := range r.Data {
+		if datum.BalanceTopUp < 0 {
+			errorDetail[fmt.Sprintf("data.%d.balanceTopUp", i)] = "balanceTopUp must be >= 0"
+		}
+		if datum.CourseFeeValue < 0 {
+			errorDetail[fmt.Sprintf("data.%d.courseFeeValue", i)] = "courseFeeValue must be >= 0"
+		}
+		if datum.TransportFeeValue < 0 {
+			errorDetail[fmt.Sprintf("data.%d.transportFeeValue", i)] = "transportFeeValue must be >= 0"
+		}
+		if datum.PenaltyFeeValue < 0 {
+			errorDetail[fmt.Sprintf("data.%d.penaltyFeeValue", i)] = "penaltyFeeValue must be >= 0"
+		}
+		if datum.DiscountFeeValue < 0 {
+			errorDetail[fmt.Sprintf("data.%d.discountFeeValue", i)] = "discountFeeValue must be >= 0"
+		}
+		validatePaymentMethod(errorDetail, fmt.Sprintf("data.%d.", i), datum.PaymentMethod, datum.ReceivingAccount, datum.ReferenceNumber)
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type UpdateEnrollmentPaymentsRequest struct {
+	Data []UpdateEnrollmentPaymentsRequestParam `json:"data"`
+}
+type UpdateEnrollmentPaymentsRequestParam struct {
+	EnrollmentPaymentID entity.EnrollmentPaymentID `json:"enrollmentPaymentId"`
+	PaymentDate         time.Time                  `json:"paymentDate"` // in RFC3339 format: "2023-12-30T14:58:10+07:00"
+	BalanceTopUp        int32                      `json:"balanceTopUp,omitempty"`
+	BalanceBonus        int32                      `json:"balanceBonus,omitempty"`
+	CourseFeeValue      int32                      `json:"courseFeeValue,omitempty"`
+	TransportFeeValue   int32                      `json:"transportFeeValue,omitempty"`
+	PenaltyFeeValue     int32                      `json:"penaltyFeeValue,omitempty"`
+	DiscountFeeValue    int32                      `json:"discountFeeValue,omitempty"`
+	PaymentMethod       entity.PaymentMethod       `json:"paymentMethod,omitempty"` // defaults to "CASH"
+	ReceivingAccount    string                     `json:"receivingAccount,omitempty"`
+	ReferenceNumber     string                     `json:"referenceNumber,omitempty"`
+}
+type UpdateEnrollmentPaymentsResponse struct {
+	Data    UpsertEnrollmentPaymentResult `json:"data"`
+	Message string                        `json:"message,omitempty"`
+}
+
+func (r UpdateEnrollmentPaymentsRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	for i, datum := range r.Data {
+		if datum.BalanceTopUp < 0 {
+			errorDetail[fmt.Sprintf("data.%d.balanceTopUp", i)] = "balanceTopUp must be >= 0"
+		}
+		if datum.CourseFeeValue < 0 {
+			errorDetail[fmt.Sprintf("data.%d.courseFeeValue", i)] = "courseFeeValue must be >= 0"
+		}
+		if datum.TransportFeeValue < 0 {
+			errorDetail[fmt.Sprintf("data.%d.transportFeeValue", i)] = "transportFeeValue must be >= 0"
+		}
+		if datum.PenaltyFeeValue < 0 {
+			errorDetail[fmt.Sprintf("data.%d.penaltyFeeValue", i)] = "penaltyFeeValue must be >= 0"
+		}
+		if datum.DiscountFeeValue < 0 {
+			errorDetail[fmt.Sprintf("data.%d.discountFeeValue", i)] = "discountFeeValue must be >= 0"
+		}
+		validatePaymentMethod(errorDetail, fmt.Sprintf("data.%d.", i), datum.PaymentMethod, datum.ReceivingAccount, datum.ReferenceNumber)
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+// validatePaymentMethod validates the optional payment method fields of an enrollmentPayment. fieldPrefix is prepended to the errorDetail keys, e.g. "data.0.".
+func validatePaymentMethod(errorDetail errs.ValidationErrorDetail, fieldPrefix string, paymentMethod entity.PaymentMethod, receivingAccount string, referenceNumber string) {
+	switch paymentMethod {
+	case "", entity.PaymentMethod_Cash, entity.PaymentMethod_BankTransfer, entity.PaymentMethod_QRIS, entity.PaymentMethod_Other:
+	default:
+		errorDetail[fieldPrefix+"paymentMethod"] = fmt.Sprintf("paymentMethod must be one of: '%s', '%s', '%s', '%s'",
+			entity.PaymentMethod_Cash, entity.PaymentMethod_BankTransfer, entity.PaymentMethod_QRIS, entity.PaymentMethod_Other)
+	}
+	if len(receivingAccount) > MaxLength_PaymentReceivingAccount {
+		errorDetail[fieldPrefix+"receivingAccount"] = fmt.Sprintf("receivingAccount must be <= %d characters", MaxLength_PaymentReceivingAccount)
+	}
+	if len(referenceNumber) > MaxLength_PaymentReferenceNumber {
+		errorDetail[fieldPrefix+"referenceNumber"] = fmt.Sprintf("referenceNumber must be <= %d characters", MaxLength_PaymentReferenceNumber)
+	}
+}
+
+type UpsertEnrollmentPaymentResult struct {
+	Results []entity.EnrollmentPayment `json:"results"`
+}
+
+type DeleteEnrollmentPaymentsRequest struct {
+	Data []DeleteEnrollmentPaymentsRequestParam `json:"data"`
+}
+type DeleteEnrollmentPaymentsRequestParam struct {
+	EnrollmentPaymentID entity.EnrollmentPaymentID `json:"enrollmentPaymentId"`
+}
+type DeleteEnrollmentPaymentsResponse struct {
+	Message string `json:"message,omitempty"`
+}
+
+func (r DeleteEnrollmentPaymentsRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+// ============================== STUDENT_LEARNING_TOKEN ==============================
+
+type GetStudentLearningTokensRequest struct {
+	PaginationRequest
+}
+type GetStudentLearningTokensResponse struct {
+	Data    GetStudentLearningTokensResult `json:"data"`
+	Message string                         `json:"message,omitempty"`
+}
+type GetStudentLearningTokensResult struct {
+	Results []entity.StudentLearningToken `json:"results"`
+	PaginationResponse
+}
+
+func (r GetStudentLearningTokensRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+	if validationErr := r.PaginationRequest.Validate(MaxPage_GetStudentLearningTokens, MaxResultsPerPage_GetStudentLearningTokens); validationErr != nil {
 		errorDetail = validationErr.GetErrorDetail()
 	}
 
@@ -712,6 +1578,65 @@ func (r GetStudentLearningTokensByClassIDRequest) Validate() errs.ValidationErro
 	return nil
 }
 
+type GetStudentLearningTokenHistoryRequest struct {
+	StudentLearningTokenID entity.StudentLearningTokenID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+}
+type GetStudentLearningTokenHistoryResponse struct {
+	Data    teaching.StudentLearningTokenHistory `json:"data"`
+	Message string                               `json:"message,omitempty"`
+}
+
+func (r GetStudentLearningTokenHistoryRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type TransferStudentLearningTokensRequest struct {
+	StudentLearningTokenID         entity.StudentLearningTokenID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+	DestinationStudentEnrollmentID entity.StudentEnrollmentID    `json:"destinationStudentEnrollmentId"`
+	// Quota=0 transfers all of the remaining quota, along with relinking the unpaid attendances
+	Quota float64 `json:"quota,omitempty"`
+	Note  string  `json:"note,omitempty"`
+}
+type TransferStudentLearningTokensResponse struct {
+	Data    teaching.SLTTransfer `json:"data"`
+	Message string               `json:"message,omitempty"`
+}
+
+func (r TransferStudentLearningTokensRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if r.Quota < 0 {
+		errorDetail["quota"] = "quota must be >= 0"
+	}
+	if len(r.Note) > 255 {
+		errorDetail["note"] = "note must be <= 255 characters"
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type RepriceStudentLearningTokensRequest struct {
+	CutoverDate time.Time `json:"cutoverDate"`
+	// CourseID is left empty to reprice the StudentLearningTokens of all courses
+	CourseID         entity.CourseID `json:"courseId,omitempty"`
+	IsQuotaConverted bool            `json:"isQuotaConverted,omitempty"`
+	DryRun           bool            `json:"dryRun,omitempty"`
+}
+type RepriceStudentLearningTokensResponse struct {
+	Data    RepriceStudentLearningTokensResult `json:"data"`
+	Message string                             `json:"message,omitempty"`
+}
+type RepriceStudentLearningTokensResult struct {
+	Results []teaching.SLTRepricing `json:"results"`
+}
+
+func (r RepriceStudentLearningTokensRequest) Validate() errs.ValidationError {
+	return nil
+}
+
 type GetStudentLearningTokenRequest struct {
 	StudentLearningTokenID entity.StudentLearningTokenID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
 }
@@ -947,3 +1872,100 @@ func (r GetTeacherPaymentsRequest) Validate() errs.ValidationError {
 	}
 	return nil
 }
+
+// ============================== CLOSED_PERIOD ==============================
+
+type GetClosedPeriodsRequest struct {
+	PaginationRequest
+}
+type GetClosedPeriodsResponse struct {
+	Data    GetClosedPeriodsResult `json:"data"`
+	Message string                 `json:"message,omitempty"`
+}
+type GetClosedPeriodsResult struct {
+	Results []entity.ClosedPeriod `json:"results"`
+	PaginationResponse
+}
+
+func (r GetClosedPeriodsRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+	if validationErr := r.PaginationRequest.Validate(MaxPage_GetClosedPeriods, MaxResultsPerPage_GetClosedPeriods); validationErr != nil {
+		errorDetail = validationErr.GetErrorDetail()
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type GetClosedPeriodRequest struct {
+	ClosedPeriodID entity.ClosedPeriodID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+}
+type GetClosedPeriodResponse struct {
+	Data    entity.ClosedPeriod `json:"data"`
+	Message string              `json:"message,omitempty"`
+}
+
+func (r GetClosedPeriodRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type InsertClosedPeriodsRequest struct {
+	Data []InsertClosedPeriodsRequestParam `json:"data"`
+}
+type InsertClosedPeriodsRequestParam struct {
+	// all records dated up to (and including) this date are closed
+	ClosedUntil time.Time `json:"closedUntil"`
+	Note        string    `json:"note,omitempty"`
+}
+type InsertClosedPeriodsResponse struct {
+	Data    UpsertClosedPeriodResult `json:"data"`
+	Message string                   `json:"message,omitempty"`
+}
+
+func (r InsertClosedPeriodsRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type UpsertClosedPeriodResult struct {
+	Results []entity.ClosedPeriod `json:"results"`
+}
+
+type DeleteClosedPeriodsRequest struct {
+	Data []DeleteClosedPeriodsRequestParam `json:"data"`
+}
+type DeleteClosedPeriodsRequestParam struct {
+	ClosedPeriodID entity.ClosedPeriodID `json:"closedPeriodId"`
+}
+type DeleteClosedPeriodsResponse struct {
+	Message string `json:"message,omitempty"`
+}
+
+func (r DeleteClosedPeriodsRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type GetClosedPeriodOverridesRequest struct {
+	PaginationRequest
+}
+type GetClosedPeriodOverridesResponse struct {
+	Data    GetClosedPeriodOverridesResult `json:"data"`
+	Message string                         `json:"message,omitempty"`
+}
+type GetClosedPeriodOverridesResult struct {
+	Results []entity.ClosedPeriodOverride `json:"results"`
+	PaginationResponse
+}
+
+func (r GetClosedPeriodOverridesRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+	if validationErr := r.PaginationRequest.Validate(MaxPage_GetClosedPeriodOverrides, MaxResultsPerPage_GetClosedPeriodOverrides); validationErr != nil {
+		errorDetail = validationErr.GetErrorDetail()
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
@@ -9,6 +9,24 @@ import (
 	"time"
 )
 
+const (
+	MaxPage_GetPayrollRuns           = Default_MaxPage
+	MaxResultsPerPage_GetPayrollRuns = Default_MaxResultsPerPage
+
+	MaxPage_GetPaymentReminders           = Default_MaxPage
+	MaxResultsPerPage_GetPaymentReminders = Default_MaxResultsPerPage
+
+	MaxPage_GetEnrollmentPaymentRefunds           = Default_MaxPage
+	MaxResultsPerPage_GetEnrollmentPaymentRefunds = Default_MaxResultsPerPage
+
+	MaxLength_RefundReason = 255
+
+	MaxPage_GetOverdueInstallments           = Default_MaxPage
+	MaxResultsPerPage_GetOverdueInstallments = Default_MaxResultsPerPage
+
+	MaxCount_InstallmentDueDates = 24
+)
+
 type GetUserTeachingInfoRequest struct{}
 type GetUserTeachingInfoResponse struct {
 	Data    teaching.UserTeachingInfo `json:"data"`
@@ -50,6 +68,9 @@ func (r SearchEnrollmentPaymentsRequest) Validate() errs.ValidationError {
 
 type GetEnrollmentPaymentInvoiceRequest struct {
 	StudentEnrollmentID entity.StudentEnrollmentID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+	// the fees are calculated as of PaymentDate, defaults to now
+	PaymentDate time.Time `json:"paymentDate,omitempty"`
+	VoucherCode string    `json:"voucherCode,omitempty"`
 }
 type GetEnrollmentPaymentInvoiceResponse struct {
 	Data    teaching.StudentEnrollmentInvoice `json:"data"`
@@ -57,6 +78,15 @@ type GetEnrollmentPaymentInvoiceResponse struct {
 }
 
 func (r GetEnrollmentPaymentInvoiceRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if len(r.VoucherCode) > MaxLength_VoucherCode {
+		errorDetail["voucherCode"] = fmt.Sprintf("voucherCode must be <= %d characters", MaxLength_VoucherCode)
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
 	return nil
 }
 
@@ -69,9 +99,17 @@ type SubmitEnrollmentPaymentRequest struct {
 	TransportFeeValue   int32                      `json:"transportFeeValue,omitempty"`
 	PenaltyFeeValue     int32                      `json:"penaltyFeeValue,omitempty"`
 	DiscountFeeValue    int32                      `json:"discountFeeValue,omitempty"`
+	DiscountRuleIDs     []entity.DiscountRuleID    `json:"discountRuleIds,omitempty"` // taken from the invoice's discounts, the remaining discountFeeValue is a manual discount
+	PaymentMethod       entity.PaymentMethod       `json:"paymentMethod,omitempty"`   // defaults to "CASH"
+	ReceivingAccount    string                     `json:"receivingAccount,omitempty"`
+	ReferenceNumber     string                     `json:"referenceNumber,omitempty"`
+	// DryRun=true only previews the StudentLearningToken changes, without submitting the enrollmentPayment
+	DryRun bool `json:"dryRun,omitempty"`
 }
 type SubmitEnrollmentPaymentResponse struct {
-	Message string `json:"message,omitempty"`
+	EnrollmentPaymentID entity.EnrollmentPaymentID  `json:"enrollmentPaymentId,omitempty"`
+	DryRunResult        *teaching.SLTChangesPreview `json:"dryRunResult,omitempty"`
+	Message             string                      `json:"message,omitempty"`
 }
 
 func (r SubmitEnrollmentPaymentRequest) Validate() errs.ValidationError {
@@ -92,6 +130,7 @@ func (r SubmitEnrollmentPaymentRequest) Validate() errs.ValidationError {
 	if r.DiscountFeeValue < 0 {
 		errorDetail["discountFeeValue"] = "discountFeeValue must be >= 0"
 	}
+	validatePaymentMethod(errorDetail, "", r.PaymentMethod, r.ReceivingAccount, r.ReferenceNumber)
 
 	if len(errorDetail) > 0 {
 		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
@@ -132,6 +171,393 @@ func (r RemoveEnrollmentPaymentRequest) Validate() errs.ValidationError {
 	return nil
 }
 
+type RefundEnrollmentPaymentRequest struct {
+	EnrollmentPaymentID entity.EnrollmentPaymentID `json:"enrollmentPaymentId"`
+	RefundDate          time.Time                  `json:"refundDate"`
+	RefundedValue       int32                      `json:"refundedValue"`
+	WithdrawnQuota      float64                    `json:"withdrawnQuota,omitempty"`
+	Reason              string                     `json:"reason"`
+}
+type RefundEnrollmentPaymentResponse struct {
+	EnrollmentPaymentRefundID teaching.EnrollmentPaymentRefundID `json:"enrollmentPaymentRefundId"`
+	Message                   string                             `json:"message,omitempty"`
+}
+
+func (r RefundEnrollmentPaymentRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if r.EnrollmentPaymentID == entity.EnrollmentPaymentID_None {
+		errorDetail["enrollmentPaymentId"] = "enrollmentPaymentId is required"
+	}
+	if r.RefundDate.IsZero() {
+		errorDetail["refundDate"] = "refundDate is required"
+	}
+	if r.RefundedValue < 0 {
+		errorDetail["refundedValue"] = "refundedValue must be >= 0"
+	}
+	if r.WithdrawnQuota < 0 {
+		errorDetail["withdrawnQuota"] = "withdrawnQuota must be >= 0"
+	}
+	if r.RefundedValue == 0 && r.WithdrawnQuota == 0 {
+		errorDetail["refundedValue"] = "either refundedValue or withdrawnQuota must be > 0"
+	}
+	if r.Reason == "" {
+		errorDetail["reason"] = "reason is required"
+	} else if len(r.Reason) > MaxLength_RefundReason {
+		errorDetail["reason"] = fmt.Sprintf("reason must be <= %d characters", MaxLength_RefundReason)
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type GetEnrollmentPaymentRefundsRequest struct {
+	PaginationRequest
+}
+type GetEnrollmentPaymentRefundsResponse struct {
+	Data    GetEnrollmentPaymentRefundsResult `json:"data"`
+	Message string                            `json:"message,omitempty"`
+}
+type GetEnrollmentPaymentRefundsResult struct {
+	Results []teaching.EnrollmentPaymentRefund `json:"results"`
+	PaginationResponse
+}
+
+func (r GetEnrollmentPaymentRefundsRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+	if validationErr := r.PaginationRequest.Validate(MaxPage_GetEnrollmentPaymentRefunds, MaxResultsPerPage_GetEnrollmentPaymentRefunds); validationErr != nil {
+		errorDetail = validationErr.GetErrorDetail()
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type CreateInstallmentPlanRequest struct {
+	StudentEnrollmentID entity.StudentEnrollmentID `json:"studentEnrollmentId"`
+	// the invoice is calculated as of InvoiceDate, defaults to now
+	InvoiceDate  time.Time   `json:"invoiceDate,omitempty"`
+	CourseCycles int32       `json:"courseCycles,omitempty"` // defaults to 1
+	DueDates     []time.Time `json:"dueDates"`
+}
+type CreateInstallmentPlanResponse struct {
+	Data    teaching.InstallmentPlan `json:"data"`
+	Message string                   `json:"message,omitempty"`
+}
+
+func (r CreateInstallmentPlanRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if r.StudentEnrollmentID == entity.StudentEnrollmentID_None {
+		errorDetail["studentEnrollmentId"] = "studentEnrollmentId is required"
+	}
+	if r.CourseCycles < 0 {
+		errorDetail["courseCycles"] = "courseCycles must be >= 0"
+	}
+	if len(r.DueDates) == 0 {
+		errorDetail["dueDates"] = "dueDates is required"
+	} else if len(r.DueDates) > MaxCount_InstallmentDueDates {
+		errorDetail["dueDates"] = fmt.Sprintf("dueDates must have <= %d items", MaxCount_InstallmentDueDates)
+	}
+	for i := 1; i < len(r.DueDates); i++ {
+		if !r.DueDates[i].After(r.DueDates[i-1]) {
+			errorDetail[fmt.Sprintf("dueDates.%d", i)] = "dueDates must be sorted ascendingly, without duplicates"
+			break
+		}
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type GetInstallmentPlanRequest struct {
+	InstallmentPlanID teaching.InstallmentPlanID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+}
+type GetInstallmentPlanResponse struct {
+	Data    teaching.InstallmentPlan `json:"data"`
+	Message string                   `json:"message,omitempty"`
+}
+
+func (r GetInstallmentPlanRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type PayInstallmentRequest struct {
+	InstallmentID    teaching.InstallmentID `json:"installmentId"`
+	PaymentDate      time.Time              `json:"paymentDate"`
+	PaymentMethod    entity.PaymentMethod   `json:"paymentMethod,omitempty"` // defaults to "CASH"
+	ReceivingAccount string                 `json:"receivingAccount,omitempty"`
+	ReferenceNumber  string                 `json:"referenceNumber,omitempty"`
+}
+type PayInstallmentResponse struct {
+	EnrollmentPaymentID entity.EnrollmentPaymentID `json:"enrollmentPaymentId"`
+	Message             string                     `json:"message,omitempty"`
+}
+
+func (r PayInstallmentRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if r.InstallmentID == teaching.InstallmentID_None {
+		errorDetail["installmentId"] = "installmentId is required"
+	}
+	if r.PaymentDate.IsZero() {
+		errorDetail["paymentDate"] = "paymentDate is required"
+	}
+	validatePaymentMethod(errorDetail, "", r.PaymentMethod, r.ReceivingAccount, r.ReferenceNumber)
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type GetOverdueInstallmentsRequest struct {
+	PaginationRequest
+	// installments due before Date are overdue, defaults to now
+	Date time.Time `json:"date,omitempty"`
+}
+type GetOverdueInstallmentsResponse struct {
+	Data    GetOverdueInstallmentsResult `json:"data"`
+	Message string                       `json:"message,omitempty"`
+}
+type GetOverdueInstallmentsResult struct {
+	Results []teaching.OverdueInstallment `json:"results"`
+	PaginationResponse
+}
+
+func (r GetOverdueInstallmentsRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+	if validationErr := r.PaginationRequest.Validate(MaxPage_GetOverdueInstallments, MaxResultsPerPage_GetOverdueInstallments); validationErr != nil {
+		errorDetail = validationErr.GetErrorDetail()
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+// GetEnrollmentPaymentReceiptRequest is responded with a PDF file (FileResponse).
+type GetEnrollmentPaymentReceiptRequest struct {
+	EnrollmentPaymentID entity.EnrollmentPaymentID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+}
+
+func (r GetEnrollmentPaymentReceiptRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type GetCashUpReportRequest struct {
+	// only the date (in GMT+7) is used, defaults to today
+	Date time.Time `json:"date,omitempty"`
+}
+type GetCashUpReportResponse struct {
+	Data    teaching.CashUpReport `json:"data"`
+	Message string                `json:"message,omitempty"`
+}
+
+func (r GetCashUpReportRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type CloseCashUpDayRequest struct {
+	Date             time.Time `json:"date"` // in RFC3339 format: "2023-12-30T00:00:00+07:00", only the date (in GMT+7) is used
+	CountedCashValue int64     `json:"countedCashValue"`
+	Note             string    `json:"note,omitempty"`
+}
+type CloseCashUpDayResponse struct {
+	Data    entity.CashUpDay `json:"data"`
+	Message string           `json:"message,omitempty"`
+}
+
+func (r CloseCashUpDayRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if r.Date.IsZero() {
+		errorDetail["date"] = "date is required"
+	}
+	if r.CountedCashValue < 0 {
+		errorDetail["countedCashValue"] = "countedCashValue must be >= 0"
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+
+	return nil
+}
+
+type ReopenCashUpDayRequest struct {
+	Date time.Time `json:"date"` // in RFC3339 format: "2023-12-30T00:00:00+07:00", only the date (in GMT+7) is used
+}
+type ReopenCashUpDayResponse struct {
+	Message string `json:"message,omitempty"`
+}
+
+func (r ReopenCashUpDayRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if r.Date.IsZero() {
+		errorDetail["date"] = "date is required"
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+
+	return nil
+}
+
+type GetFamilyInvoiceRequest struct {
+	FamilyID entity.FamilyID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+	// the fees are calculated as of PaymentDate, defaults to now
+	PaymentDate time.Time `json:"paymentDate,omitempty"`
+}
+type GetFamilyInvoiceResponse struct {
+	Data    teaching.FamilyInvoice `json:"data"`
+	Message string                 `json:"message,omitempty"`
+}
+
+func (r GetFamilyInvoiceRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type SubmitFamilyPaymentRequest struct {
+	FamilyID         entity.FamilyID                  `json:"familyId"`
+	PaymentDate      time.Time                        `json:"paymentDate"`
+	Items            []SubmitFamilyPaymentRequestItem `json:"items"`
+	PaymentMethod    entity.PaymentMethod             `json:"paymentMethod,omitempty"` // defaults to "CASH"
+	ReceivingAccount string                           `json:"receivingAccount,omitempty"`
+	ReferenceNumber  string                           `json:"referenceNumber,omitempty"`
+}
+type SubmitFamilyPaymentRequestItem struct {
+	StudentEnrollmentID entity.StudentEnrollmentID `json:"studentEnrollmentId"`
+	BalanceTopUp        int32                      `json:"balanceTopUp"`
+	BalanceBonus        int32                      `json:"balanceBonus,omitempty"`
+	CourseFeeValue      int32                      `json:"courseFeeValue,omitempty"`
+	TransportFeeValue   int32                      `json:"transportFeeValue,omitempty"`
+	PenaltyFeeValue     int32                      `json:"penaltyFeeValue,omitempty"`
+	DiscountFeeValue    int32                      `json:"discountFeeValue,omitempty"`
+	DiscountRuleIDs     []entity.DiscountRuleID    `json:"discountRuleIds,omitempty"`
+}
+type SubmitFamilyPaymentResponse struct {
+	EnrollmentPaymentIDs []entity.EnrollmentPaymentID `json:"enrollmentPaymentIds"`
+	Message              string                       `json:"message,omitempty"`
+}
+
+func (r SubmitFamilyPaymentRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if r.FamilyID == entity.FamilyID_None {
+		errorDetail["familyId"] = "familyId is required"
+	}
+	if len(r.Items) == 0 {
+		errorDetail["items"] = "items must contain at least 1 studentEnrollment"
+	}
+	for i, item := range r.Items {
+		if item.StudentEnrollmentID == entity.StudentEnrollmentID_None {
+			errorDetail[fmt.Sprintf("items.%d.studentEnrollmentId", i)] = "studentEnrollmentId is required"
+		}
+		if item.BalanceTopUp < 0 {
+			errorDetail[fmt.Sprintf("items.%d.balanceTopUp", i)] = "balanceTopUp must be >= 0"
+		}
+		if item.CourseFeeValue < 0 {
+			errorDetail[fmt.Sprintf("items.%d.courseFeeValue", i)] = "courseFeeValue must be >= 0"
+		}
+		if item.TransportFeeValue < 0 {
+			errorDetail[fmt.Sprintf("items.%d.transportFeeValue", i)] = "transportFeeValue must be >= 0"
+		}
+		if item.PenaltyFeeValue < 0 {
+			errorDetail[fmt.Sprintf("items.%d.penaltyFeeValue", i)] = "penaltyFeeValue must be >= 0"
+		}
+		if item.DiscountFeeValue < 0 {
+			errorDetail[fmt.Sprintf("items.%d.discountFeeValue", i)] = "discountFeeValue must be >= 0"
+		}
+	}
+	validatePaymentMethod(errorDetail, "", r.PaymentMethod, r.ReceivingAccount, r.ReferenceNumber)
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+
+	return nil
+}
+
+type SendPaymentRemindersRequest struct {
+	DryRun bool `json:"dryRun,omitempty"`
+}
+type SendPaymentRemindersResponse struct {
+	Data    teaching.SendPaymentRemindersResult `json:"data"`
+	Message string                              `json:"message,omitempty"`
+}
+
+func (r SendPaymentRemindersRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type GetPaymentRemindersRequest struct {
+	PaginationRequest
+}
+type GetPaymentRemindersResponse struct {
+	Data    GetPaymentRemindersResult `json:"data"`
+	Message string                    `json:"message,omitempty"`
+}
+type GetPaymentRemindersResult struct {
+	Results []teaching.PaymentReminder `json:"results"`
+	PaginationResponse
+}
+
+func (r GetPaymentRemindersRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+	if validationErr := r.PaginationRequest.Validate(MaxPage_GetPaymentReminders, MaxResultsPerPage_GetPaymentReminders); validationErr != nil {
+		errorDetail = validationErr.GetErrorDetail()
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type GetPaymentReminderOptOutsRequest struct{}
+type GetPaymentReminderOptOutsResponse struct {
+	Data    GetPaymentReminderOptOutsResult `json:"data"`
+	Message string                          `json:"message,omitempty"`
+}
+type GetPaymentReminderOptOutsResult struct {
+	Results []teaching.PaymentReminderOptOut `json:"results"`
+}
+
+func (r GetPaymentReminderOptOutsRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type SetPaymentReminderOptOutRequest struct {
+	StudentIDs []entity.StudentID `json:"studentIds"`
+	IsOptedOut bool               `json:"isOptedOut"`
+}
+type SetPaymentReminderOptOutResponse struct {
+	Message string `json:"message,omitempty"`
+}
+
+func (r SetPaymentReminderOptOutRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if len(r.StudentIDs) == 0 {
+		errorDetail["studentIds"] = "studentIds must contain at least 1 student"
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
 // ============================== CLASS & ATTENDANCE ==============================
 
 type SearchClassRequest struct {
@@ -249,8 +675,44 @@ func (r EditClassesCoursesRequest) Validate() errs.ValidationError {
 	return nil
 }
 
+type LevelUpClassRequest struct {
+	ClassID  entity.ClassID  `json:"classId"`
+	CourseID entity.CourseID `json:"courseId"`
+	// EffectiveDate must be today or earlier, a level-up cannot be scheduled for a future date
+	EffectiveDate time.Time `json:"effectiveDate"` // in RFC3339 format: "2023-12-30T00:00:00+07:00"
+	// IsQuotaConverted=true converts the old-price remaining quota to preserve its value, else the quota is carried over as is
+	IsQuotaConverted bool `json:"isQuotaConverted,omitempty"`
+	// DryRun=true only previews the level-up, without persisting it
+	DryRun bool `json:"dryRun,omitempty"`
+}
+type LevelUpClassResponse struct {
+	Data    teaching.ClassLevelUp `json:"data"`
+	Message string                `json:"message,omitempty"`
+}
+
+func (r LevelUpClassRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if r.ClassID == entity.ClassID_None {
+		errorDetail["classId"] = "classId is required"
+	}
+	if r.CourseID == entity.CourseID_None {
+		errorDetail["courseId"] = "courseId is required"
+	}
+	if r.EffectiveDate.IsZero() {
+		errorDetail["effectiveDate"] = "effectiveDate is required"
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
 type AddAttendancesBatchRequest struct {
 	Data []AddAttendancesBatchParam `json:"data"`
+	// DryRun=true only previews the StudentLearningToken changes, without adding the attendances
+	DryRun bool `json:"dryRun,omitempty"`
 }
 type AddAttendancesBatchParam struct {
 	ClassID               entity.ClassID   `json:"classId"`
@@ -261,7 +723,8 @@ type AddAttendancesBatchParam struct {
 	Note                  string           `json:"note,omitempty"`
 }
 type AddAttendancesBatchResponse struct {
-	Message string `json:"message,omitempty"`
+	DryRunResult *teaching.SLTChangesPreview `json:"dryRunResult,omitempty"`
+	Message      string                      `json:"message,omitempty"`
 }
 
 func (r AddAttendancesBatchRequest) Validate() errs.ValidationError {
@@ -292,10 +755,13 @@ type AddAttendanceRequest struct {
 	UsedStudentTokenQuota float64          `json:"usedStudentTokenQuota,omitempty"`
 	Duration              int32            `json:"duration,omitempty"`
 	Note                  string           `json:"note,omitempty"`
+	// DryRun=true only previews the StudentLearningToken changes, without adding the attendances
+	DryRun bool `json:"dryRun,omitempty"`
 }
 type AddAttendanceResponse struct {
-	Data    UpsertAttendanceResult `json:"data"`
-	Message string                 `json:"message,omitempty"`
+	Data         UpsertAttendanceResult      `json:"data"`
+	DryRunResult *teaching.SLTChangesPreview `json:"dryRunResult,omitempty"`
+	Message      string                      `json:"message,omitempty"`
 }
 
 func (r AddAttendanceRequest) Validate() errs.ValidationError {
@@ -314,6 +780,46 @@ func (r AddAttendanceRequest) Validate() errs.ValidationError {
 	return nil
 }
 
+type AssignAttendancesTokensRequest struct {
+	ClassID   entity.ClassID   `json:"classId"`
+	StudentID entity.StudentID `json:"studentId,omitempty"`
+	TimeFilter
+	Strategy teaching.AttendanceTokenAssignmentStrategy `json:"strategy"`
+	// StudentLearningTokenID is required by the "EXPLICIT_SLT" strategy only
+	StudentLearningTokenID entity.StudentLearningTokenID `json:"studentLearningTokenId,omitempty"`
+}
+type AssignAttendancesTokensResponse struct {
+	Data    teaching.AttendanceTokenAssignments `json:"data"`
+	Message string                              `json:"message,omitempty"`
+}
+
+func (r AssignAttendancesTokensRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if validationErr := r.TimeFilter.Validate(); validationErr != nil {
+		for key, value := range validationErr.GetErrorDetail() {
+			errorDetail[key] = value
+		}
+	}
+	if r.ClassID == entity.ClassID_None {
+		errorDetail["classId"] = "classId is required"
+	}
+	switch r.Strategy {
+	case teaching.AttendanceTokenAssignmentStrategy_ExplicitSLT:
+		if r.StudentLearningTokenID == entity.StudentLearningTokenID_None {
+			errorDetail["studentLearningTokenId"] = fmt.Sprintf("studentLearningTokenId is required for strategy '%s'", r.Strategy)
+		}
+	case teaching.AttendanceTokenAssignmentStrategy_EarliestAvailable:
+	default:
+		errorDetail["strategy"] = fmt.Sprintf("strategy must be one of: '%s', '%s'", teaching.AttendanceTokenAssignmentStrategy_ExplicitSLT, teaching.AttendanceTokenAssignmentStrategy_EarliestAvailable)
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
 type AssignAttendanceTokenRequest struct {
 	AttendanceID           entity.AttendanceID           `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
 	StudentLearningTokenID entity.StudentLearningTokenID `json:"studentLearningTokenId"`
@@ -433,6 +939,32 @@ func (r GetPaidTeachersRequest) Validate() errs.ValidationError {
 	return nil
 }
 
+type GetTeacherSubstitutionSummariesRequest struct {
+	YearMonthFilter
+}
+type GetTeacherSubstitutionSummariesResponse struct {
+	Data GetTeacherSubstitutionSummariesResult `json:"data"`
+}
+type GetTeacherSubstitutionSummariesResult struct {
+	Results []teaching.TeacherSubstitutionSummary `json:"results"`
+}
+
+func (r GetTeacherSubstitutionSummariesRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if validationErr := r.YearMonthFilter.Validate(); validationErr != nil {
+		for key, value := range validationErr.GetErrorDetail() {
+			errorDetail[key] = value
+		}
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+
+	return nil
+}
+
 type GetTeacherPaymentInvoiceItemsRequest struct {
 	TeacherID entity.TeacherID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
 	YearMonthFilter
@@ -487,8 +1019,80 @@ func (r GetTeacherPaymentsAsInvoiceItemsRequest) Validate() errs.ValidationError
 	return nil
 }
 
+type TeacherPayslipFormat string
+
+const (
+	TeacherPayslipFormat_PDF  TeacherPayslipFormat = "pdf"
+	TeacherPayslipFormat_HTML TeacherPayslipFormat = "html"
+)
+
+// GetTeacherPayslipRequest is responded with a PDF or an HTML file (FileResponse), depending on Format.
+type GetTeacherPayslipRequest struct {
+	TeacherID entity.TeacherID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+	YearMonthFilter
+	// Format defaults to "pdf"
+	Format TeacherPayslipFormat `json:"format,omitempty"`
+}
+
+func (r GetTeacherPayslipRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if validationErr := r.YearMonthFilter.Validate(); validationErr != nil {
+		for key, value := range validationErr.GetErrorDetail() {
+			errorDetail[key] = value
+		}
+	}
+	if r.Year == 0 {
+		errorDetail["year"] = "year is required"
+	}
+	if r.Month == 0 {
+		errorDetail["month"] = "month is required"
+	}
+	if r.Format != "" && r.Format != TeacherPayslipFormat_PDF && r.Format != TeacherPayslipFormat_HTML {
+		errorDetail["format"] = fmt.Sprintf("format must be one of: [%s, %s]", TeacherPayslipFormat_PDF, TeacherPayslipFormat_HTML)
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+
+	return nil
+}
+
+type EmailTeacherPayslipRequest struct {
+	TeacherID entity.TeacherID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+	YearMonthFilter
+}
+type EmailTeacherPayslipResponse struct {
+	Message string `json:"message,omitempty"`
+}
+
+func (r EmailTeacherPayslipRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if validationErr := r.YearMonthFilter.Validate(); validationErr != nil {
+		for key, value := range validationErr.GetErrorDetail() {
+			errorDetail[key] = value
+		}
+	}
+	if r.Year == 0 {
+		errorDetail["year"] = "year is required"
+	}
+	if r.Month == 0 {
+		errorDetail["month"] = "month is required"
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+
+	return nil
+}
+
 type SubmitTeacherPaymentsRequest struct {
 	Data []SubmitTeacherPaymentsRequestParam `json:"data"`
+	// the submitted TeacherPayments are grouped into the PayrollRun, which must still be a draft, and whose period must cover the attendances
+	PayrollRunID entity.PayrollRunID `json:"payrollRunId"`
 }
 type SubmitTeacherPaymentsRequestParam struct {
 	AttendanceID          entity.AttendanceID `json:"attendanceId"`
@@ -502,6 +1106,9 @@ type SubmitTeacherPaymentsResponse struct {
 func (r SubmitTeacherPaymentsRequest) Validate() errs.ValidationError {
 	errorDetail := make(errs.ValidationErrorDetail, 0)
 
+	if r.PayrollRunID == entity.PayrollRunID_None {
+		errorDetail["payrollRunId"] = "payrollRunId is required"
+	}
 	for i, datum := range r.Data {
 		if datum.PaidCourseFeeValue < 0 {
 			errorDetail[fmt.Sprintf("data.%d.paidCourseFeeValue", i)] = "paidCourseFeeValue must be >= 0"
@@ -602,3 +1209,111 @@ type RemoveTeacherPaymentsResponse struct {
 func (r RemoveTeacherPaymentsRequest) Validate() errs.ValidationError {
 	return nil
 }
+
+// ============================== PAYROLL_RUN ==============================
+
+type GetPayrollRunsRequest struct {
+	PaginationRequest
+}
+type GetPayrollRunsResponse struct {
+	Data    GetPayrollRunsResult `json:"data"`
+	Message string               `json:"message,omitempty"`
+}
+type GetPayrollRunsResult struct {
+	Results []entity.PayrollRun `json:"results"`
+	PaginationResponse
+}
+
+func (r GetPayrollRunsRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+	if validationErr := r.PaginationRequest.Validate(MaxPage_GetPayrollRuns, MaxResultsPerPage_GetPayrollRuns); validationErr != nil {
+		errorDetail = validationErr.GetErrorDetail()
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+	return nil
+}
+
+type GetPayrollRunRequest struct {
+	PayrollRunID entity.PayrollRunID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+}
+type GetPayrollRunResponse struct {
+	Data    entity.PayrollRun `json:"data"`
+	Message string            `json:"message,omitempty"`
+}
+
+func (r GetPayrollRunRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type CreatePayrollRunRequest struct {
+	// the PayrollRun covers the salary period of the given year & month (26th previous month to 25th current month)
+	YearMonthFilter
+	Note string `json:"note,omitempty"`
+}
+type CreatePayrollRunResponse struct {
+	Data    entity.PayrollRun `json:"data"`
+	Message string            `json:"message,omitempty"`
+}
+
+func (r CreatePayrollRunRequest) Validate() errs.ValidationError {
+	errorDetail := make(errs.ValidationErrorDetail, 0)
+
+	if validationErr := r.YearMonthFilter.Validate(); validationErr != nil {
+		for key, value := range validationErr.GetErrorDetail() {
+			errorDetail[key] = value
+		}
+	}
+	if r.Year == 0 {
+		errorDetail["year"] = "year is required"
+	}
+	if r.Month == 0 {
+		errorDetail["month"] = "month is required"
+	}
+
+	if len(errorDetail) > 0 {
+		return errs.NewValidationError(errs.ErrInvalidRequest, errorDetail)
+	}
+
+	return nil
+}
+
+type GetPayrollRunTeacherTotalsRequest struct {
+	PayrollRunID entity.PayrollRunID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+}
+type GetPayrollRunTeacherTotalsResponse struct {
+	Data GetPayrollRunTeacherTotalsResult `json:"data"`
+}
+type GetPayrollRunTeacherTotalsResult struct {
+	Results []teaching.PayrollRunTeacherTotal `json:"results"`
+}
+
+func (r GetPayrollRunTeacherTotalsRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type ApprovePayrollRunRequest struct {
+	PayrollRunID entity.PayrollRunID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+}
+type ApprovePayrollRunResponse struct {
+	Data    entity.PayrollRun `json:"data"`
+	Message string            `json:"message,omitempty"`
+}
+
+func (r ApprovePayrollRunRequest) Validate() errs.ValidationError {
+	return nil
+}
+
+type MarkPayrollRunAsPaidRequest struct {
+	PayrollRunID entity.PayrollRunID `json:"-"` // we exclude the JSON tag as we'll populate the ID from URL param (not from JSON body or URL query param)
+}
+type MarkPayrollRunAsPaidResponse struct {
+	Data    entity.PayrollRun `json:"data"`
+	Message string            `json:"message,omitempty"`
+}
+
+func (r MarkPayrollRunAsPaidRequest) Validate() errs.ValidationError {
+	return nil
+}
@@ -256,6 +256,11 @@ func (wrapper JSONSerdeWrapper) handleSuccess(w http.ResponseWriter, response in
 		return
 	}
 
+	if fileResponse, ok := response.(*output.FileResponse); ok {
+		handleFileSuccess(w, fileResponse)
+		return
+	}
+
 	resBytes, err := json.Marshal(response)
 	if err != nil {
 		logging.HTTPServerLogger.Error("Error on json.Marshal(): %v", err)
@@ -272,6 +277,17 @@ func (wrapper JSONSerdeWrapper) handleSuccess(w http.ResponseWriter, response in
 	}
 }
 
+func handleFileSuccess(w http.ResponseWriter, fileResponse *output.FileResponse) {
+	w.Header().Set("Content-Type", fileResponse.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", fileResponse.FileName))
+	_, err := w.Write(fileResponse.Content)
+	if err != nil {
+		logging.HTTPServerLogger.Error("Error on http.ResponseWriter.Write(): %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
 func errorJSON(w http.ResponseWriter, jsonBody string, code int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("X-Content-Type-Options", "nosniff")